@@ -3,11 +3,23 @@ package main
 import (
 	"bms-go/config"
 	"bms-go/docs"
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/embed"
+	"bms-go/internal/infra/enrich"
 	"bms-go/internal/infra/handler"
+	"bms-go/internal/infra/httpclient"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/infra/notify"
+	"bms-go/internal/infra/redis"
 	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
 	"bms-go/internal/service"
 	"bms-go/util"
+	"context"
+	"expvar"
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -29,29 +41,423 @@ import (
 // @BasePath /
 func main() {
 	config.LoadEnv()
+	config.Load()
+	util.InitEncryption()
 
 	db := util.InitDB()
 
+	cacheConfig := config.LoadResponseCacheConfig()
+	responseCache := cache.NewResponseCache(cacheConfig.TTL, cacheConfig.MaxEntries)
+	redisConfig := config.LoadRedisConfig()
+	var redisClient *redis.Client
+	if redisConfig.Addr != "" {
+		redisClient = redis.New(redisConfig.Addr, redisConfig.Password, redisConfig.DB, redisConfig.Timeout)
+		responseCache = cache.NewSharedResponseCache(cacheConfig.TTL, redis.NewResponseStore(redisClient))
+	}
+	service.SetReadingSpeed(config.LoadReadingSpeed())
+
+	synonymRepo := repository.NewSynonymRepository(db)
+	synonymService := service.NewSynonymService(synonymRepo, responseCache)
+	synonymHandler := handler.NewSynonymHandler(synonymService)
+
+	bookViewRepo := repository.NewBookViewRepository(db)
+	privacyRepo := repository.NewPrivacyRepository(db)
+	recentlyViewedService := service.NewRecentlyViewedService(bookViewRepo, privacyRepo, config.RecentlyViewedLimit())
+	recentlyViewedHandler := handler.NewRecentlyViewedHandler(recentlyViewedService)
+	privacyService := service.NewPrivacyService(privacyRepo, bookViewRepo)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+
 	bookRepo := repository.NewBookRepository(db)
-	bookService := service.NewBookService(bookRepo)
-	bookHandler := handler.NewBookHandler(bookService)
+	categoryRepo := repository.NewCategoryRepository(db)
+	authorRepo := repository.NewAuthorRepository(db)
+	var semanticIndex *service.SemanticIndex
+	semanticConfig := config.LoadSemanticSearchConfig()
+	if semanticConfig.Endpoint != "" {
+		provider := embed.NewHTTP(semanticConfig.Endpoint, semanticConfig.Model, semanticConfig.APIKey, httpclient.New(config.LoadDependencyClientConfig(config.DependencyEmbeddings)))
+		semanticIndex = service.NewSemanticIndex(provider, repository.NewBookEmbeddingRepository(db), semanticConfig.BatchSize, semanticConfig.Candidates)
+		if semanticConfig.RefreshInterval > 0 {
+			go semanticIndex.Run(context.Background(), semanticConfig.RefreshInterval)
+		}
+	}
+	validationRuleService := service.NewValidationRuleService(repository.NewValidationRuleRepository(db))
+	validationRuleHandler := handler.NewValidationRuleHandler(validationRuleService)
+	bookService := service.NewBookService(bookRepo, categoryRepo, authorRepo, synonymService, validationRuleService, config.LoadRelevanceWeights(), responseCache, semanticIndex)
+	bookLockService := service.NewBookLockService(repository.NewBookLockRepository(db), config.EditLockTTL())
+	bookLockHandler := handler.NewBookLockHandler(bookLockService)
+	experimentService := service.NewExperimentService(repository.NewExperimentRepository(db))
+	experimentHandler := handler.NewExperimentHandler(experimentService)
+	bookHandler := handler.NewBookHandler(bookService, recentlyViewedService, bookLockService, responseCache)
+	affinityConfig := config.LoadAffinityConfig()
+	affinityService := service.NewAffinityService(repository.NewAffinityRepository(db), bookRepo, affinityConfig.MinUsers, affinityConfig.MaxRelated)
+	affinityHandler := handler.NewAffinityHandler(affinityService)
+	if affinityConfig.Interval > 0 {
+		go affinityService.Run(context.Background(), affinityConfig.Interval)
+	}
+	similarBooksHandler := handler.NewSimilarBooksHandler(service.NewSimilarBooksService(bookRepo, semanticIndex, config.LoadSimilarityConfig()), responseCache)
+	authorHandler := handler.NewAuthorHandler(service.NewAuthorService(authorRepo, bookRepo, bookService))
+	categoryHandler := handler.NewCategoryHandler(service.NewCategoryService(categoryRepo, bookRepo, bookService))
+	tagHandler := handler.NewTagHandler(service.NewTagService(repository.NewTagRepository(db), bookRepo, bookService))
+	copyHandler := handler.NewCopyHandler(service.NewCopyService(repository.NewCopyRepository(db), bookService, config.DamagePhotoMaxSize()))
+
+	linkService := service.NewLinkService(bookRepo, config.BaseURL())
+	linkHandler := handler.NewLinkHandler(linkService)
+
+	embedService := service.NewEmbedService(bookRepo, linkService, config.BaseURL())
+	embedHandler := handler.NewEmbedHandler(embedService)
+
+	sitemapConfig := config.LoadSitemapConfig()
+	sitemapService := service.NewSitemapService(bookRepo, linkService, config.BaseURL(), sitemapConfig.PageSize, sitemapConfig.CacheTTL)
+	sitemapHandler := handler.NewSitemapHandler(sitemapService)
+
+	opdsService := service.NewOPDSService(bookRepo, bookService, config.BaseURL())
+	opdsHandler := handler.NewOPDSHandler(opdsService)
 
 	favRepo := repository.NewFavoriteRepository(db)
-	favService := service.NewFavoriteService(favRepo, bookRepo)
+	favService := service.NewFavoriteService(favRepo, bookRepo, privacyRepo, responseCache)
 	favHandler := handler.NewFavoriteHandler(favService)
+	collectionHandler := handler.NewCollectionHandler(service.NewCollectionService(repository.NewCollectionRepository(db), bookRepo))
+	reviewRepo := repository.NewReviewRepository(db)
+	reviewHandler := handler.NewReviewHandler(service.NewReviewService(reviewRepo, bookRepo, responseCache))
+	readingLogHandler := handler.NewReadingLogHandler(service.NewReadingLogService(repository.NewReadingStatusRepository(db), reviewRepo, bookRepo, responseCache))
+	if interval := config.FavoriteReconcileInterval(); interval > 0 {
+		go favService.Run(context.Background(), interval)
+	}
+
+	guestConfig := config.LoadGuestConfig()
+	guestFavoriteService := service.NewGuestFavoriteService(repository.NewGuestFavoriteRepository(db), favRepo, bookRepo, responseCache, guestConfig.MaxFavorites, guestConfig.TTL)
+	guestFavoriteHandler := handler.NewGuestFavoriteHandler(guestFavoriteService, guestConfig.Secret, guestConfig.Cookie, guestConfig.TTL)
+	if guestConfig.CleanupInterval > 0 {
+		go guestFavoriteService.Run(context.Background(), guestConfig.CleanupInterval)
+	}
+
+	citationService := service.NewCitationService(bookRepo, favRepo)
+	citationHandler := handler.NewCitationHandler(citationService)
+
+	quotaConfig := config.LoadQuotaConfig()
+	quotaRepo := repository.NewQuotaRepository(db)
+	quotaService := service.NewQuotaService(quotaRepo, quotaConfig.DefaultMonthlyLimit)
+	quotaHandler := handler.NewQuotaHandler(quotaService)
+
+	apiConfig := config.LoadAPIConfig()
+	partnerRepo := repository.NewPartnerRepository(db)
+	partnerService := service.NewPartnerService(partnerRepo, apiConfig.SignatureTolerance)
+	partnerHandler := handler.NewPartnerHandler(partnerService)
+
+	retentionConfig := config.LoadRetentionConfig()
+	retentionRepo := repository.NewRetentionRepository(db)
+	retentionService := service.NewRetentionService(retentionRepo, retentionConfig.Period)
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+
+	accountRepo := repository.NewAccountRepository(db)
+	accountService := service.NewAccountService(accountRepo, privacyRepo, retentionConfig.AccountGrace)
+	userRepo := repository.NewUserRepository(db)
+	userService := service.NewUserService(userRepo, accountRepo, privacyRepo)
+	userHandler := handler.NewUserHandler(userService)
+	reservationConfig := config.LoadReservationConfig()
+	loanHistoryConfig := config.LoadLoanHistoryConfig()
+	loanService := service.NewLoanService(repository.NewLoanRepository(db), userService, bookService, privacyRepo, config.LoanPeriod(), reservationConfig.Hold, loanHistoryConfig.ForgetAfter)
+	loanHandler := handler.NewLoanHandler(loanService)
+	if loanHistoryConfig.Interval > 0 {
+		go loanService.Run(context.Background(), loanHistoryConfig.Interval)
+	}
+	reservationService := service.NewReservationService(repository.NewReservationRepository(db), userService, reservationConfig.Hold)
+	reservationHandler := handler.NewReservationHandler(reservationService)
+	if reservationConfig.Interval > 0 {
+		go reservationService.Run(context.Background(), reservationConfig.Interval)
+	}
+	authService := service.NewAuthService(userRepo, config.LoadAuthConfig())
+	loginGuard := service.NewLoginGuard(config.LoadLockoutConfig(), service.LogNotifier{}, nil)
+	authHandler := handler.NewAuthHandler(authService, loginGuard)
+	rbacConfig := config.LoadRBACConfig()
+	if err := userService.PromoteAdmins(rbacConfig.BootstrapAdmins); err != nil {
+		log.Printf("Failed to promote bootstrap admins: %v", err)
+	}
+	accountHandler := handler.NewAccountHandler(accountService)
+
+	if retentionConfig.Interval > 0 {
+		go retentionService.Run(context.Background(), retentionConfig.Interval)
+		go accountService.Run(context.Background(), retentionConfig.Interval)
+	}
+
+	impersonationRepo := repository.NewImpersonationRepository(db)
+	impersonationService := service.NewImpersonationService(impersonationRepo, userService, apiConfig.ImpersonationTTL)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService)
+
+	orgRepo := repository.NewOrganizationRepository(db)
+	orgService := service.NewOrganizationService(orgRepo, bookRepo)
+	orgHandler := handler.NewOrganizationHandler(orgService)
 
+	importHandler := handler.NewImportHandler(service.NewImportService(bookRepo, repository.NewImportBatchRepository(db), bookService))
+
+	notificationConfig := config.LoadNotificationConfig()
+	notifyClient := httpclient.New(config.LoadDependencyClientConfig(config.DependencyNotifications))
+	notificationChannels := map[model.NotificationChannel]notify.Channel{
+		model.ChannelSlack: notify.NewSlack(notifyClient),
+	}
+	if notificationConfig.TelegramBotToken != "" {
+		notificationChannels[model.ChannelTelegram] = notify.NewTelegram(notifyClient, notificationConfig.TelegramBotToken)
+	}
+	if notificationConfig.VAPIDPrivateKey != "" {
+		webPush, err := notify.NewWebPush(notifyClient, notificationConfig.VAPIDPrivateKey, notificationConfig.VAPIDSubject)
+		if err != nil {
+			log.Fatalf("Failed to set up Web Push: %v", err)
+		}
+		notificationChannels[model.ChannelWebPush] = webPush
+	}
+	pushProviders := map[model.PushProvider]notify.Channel{}
+	if notificationConfig.FCMCredentialsFile != "" {
+		credentials, err := os.ReadFile(notificationConfig.FCMCredentialsFile)
+		if err != nil {
+			log.Fatalf("Failed to read FCM credentials: %v", err)
+		}
+		fcm, err := notify.NewFCM(notifyClient, credentials)
+		if err != nil {
+			log.Fatalf("Failed to set up FCM: %v", err)
+		}
+		pushProviders[model.PushFCM] = fcm
+	}
+	if notificationConfig.APNsKeyFile != "" {
+		key, err := os.ReadFile(notificationConfig.APNsKeyFile)
+		if err != nil {
+			log.Fatalf("Failed to read APNs key: %v", err)
+		}
+		apns, err := notify.NewAPNs(notifyClient, key, notificationConfig.APNsKeyID, notificationConfig.APNsTeamID, notificationConfig.APNsTopic, notificationConfig.APNsSandbox)
+		if err != nil {
+			log.Fatalf("Failed to set up APNs: %v", err)
+		}
+		pushProviders[model.PushAPNs] = apns
+	}
+	inboxService := service.NewInboxService(repository.NewInboxRepository(db), notificationConfig.InboxRetention)
+	if notificationConfig.InboxCleanupInterval > 0 {
+		go inboxService.Run(context.Background(), notificationConfig.InboxCleanupInterval)
+	}
+	notificationService, err := service.NewNotificationService(repository.NewNotificationRepository(db), repository.NewDeviceRepository(db), inboxService, notificationChannels, pushProviders, notificationConfig.Templates, config.BaseURL())
+	if err != nil {
+		log.Fatalf("Invalid notification templates: %v", err)
+	}
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	reminderConfig := config.LoadReminderConfig()
+	reminderService := service.NewReminderService(repository.NewReminderRepository(db), notificationService, reminderConfig, config.BaseURL())
+	reminderHandler := handler.NewReminderHandler(reminderService)
+	if reminderConfig.Interval > 0 {
+		go reminderService.Run(context.Background(), reminderConfig.Interval)
+	}
+	inboxHandler := handler.NewInboxHandler(inboxService)
+	eventHandler := handler.NewEventHandler(service.NewEventService(repository.NewEventRepository(db), bookRepo, notificationService))
+	illHandler := handler.NewILLHandler(service.NewILLService(repository.NewILLRepository(db), notificationService))
+	sampleHandler := handler.NewSampleHandler(service.NewSampleService(repository.NewBookSampleRepository(db), bookRepo, responseCache, config.SampleMaxSize()))
+
+	changeRequestRepo := repository.NewChangeRequestRepository(db)
+	changeRequestService := service.NewChangeRequestService(changeRequestRepo, bookRepo, bookService, notificationService)
+	changeRequestHandler := handler.NewChangeRequestHandler(changeRequestService)
+
+	upstreamRepo := repository.NewUpstreamRepository(db)
+	refreshConfig := config.LoadCatalogRefreshConfig()
+	catalogClientConfig := config.LoadDependencyClientConfig(config.DependencyCatalog)
+	catalogClient := httpclient.New(catalogClientConfig)
+	refreshClientConfig := catalogClientConfig
+	refreshClientConfig.RateLimit = refreshConfig.RateLimit
+	catalogRefreshService := service.NewCatalogRefreshService(upstreamRepo, bookRepo, bookService, changeRequestRepo, httpclient.New(refreshClientConfig), refreshConfig.APIKey, refreshConfig.BatchSize)
+	if refreshConfig.Interval > 0 {
+		go catalogRefreshService.Run(context.Background(), refreshConfig.Interval)
+	}
+	catalogSyncHandler := handler.NewCatalogSyncHandler(service.NewLocalCatalog(bookRepo, upstreamRepo, bookService), catalogRefreshService, catalogClient)
+
+	enrichmentConfig := config.LoadEnrichmentConfig()
+	var enrichmentSteps []enrich.Enricher
+	if enrichmentConfig.Endpoint != "" {
+		enrichmentSteps = append(enrichmentSteps, enrich.NewHTTP(enrichmentConfig.Endpoint, enrichmentConfig.APIKey, enrichmentConfig.MaxWords, httpclient.New(config.LoadDependencyClientConfig(config.DependencyEnrichment))))
+	}
+	enrichmentService := service.NewEnrichmentService(enrichmentSteps, bookRepo, changeRequestRepo, enrichmentConfig.BatchSize)
+	enrichmentHandler := handler.NewEnrichmentHandler(enrichmentService)
+	if len(enrichmentSteps) > 0 && enrichmentConfig.Interval > 0 {
+		go enrichmentService.Run(context.Background(), enrichmentConfig.Interval)
+	}
+
+	reportService := service.NewReportService(repository.NewReportRepository(db), bookRepo)
+	reportHandler := handler.NewReportHandler(reportService)
+	if interval := config.ReportCheckInterval(); interval > 0 {
+		go reportService.Run(context.Background(), interval)
+	}
+
+	exportHandler := handler.NewExportHandler(service.NewExportService(bookRepo, httpclient.New(config.LoadDependencyClientConfig(config.DependencyCovers))))
+
+	changeEventService := service.NewChangeEventService(repository.NewChangeEventRepository(db), config.CDCSettleDelay())
+	changeEventHandler := handler.NewChangeEventHandler(changeEventService)
+
+	archiveConfig := config.LoadArchiveConfig()
+	archiveService := service.NewArchiveService(repository.NewArchiveRepository(db), archiveConfig.Age, archiveConfig.BatchSize)
+	archiveHandler := handler.NewArchiveHandler(archiveService)
+	if archiveConfig.Interval > 0 {
+		go archiveService.Run(context.Background(), archiveConfig.Interval)
+	}
+
+	maintenanceService := service.NewMaintenanceService(service.NewTaskService(), bookService, bookRepo, sitemapService)
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceService)
+
+	abuseConfig := config.LoadAbuseConfig()
+	abuseService := service.NewAbuseService(repository.NewClientBlockRepository(db), abuseConfig)
+	if err := abuseService.Reload(); err != nil {
+		log.Printf("Failed to load client blocks: %v", err)
+	}
+	if abuseConfig.RefreshInterval > 0 {
+		go abuseService.Run(context.Background(), abuseConfig.RefreshInterval)
+	}
+	abuseHandler := handler.NewAbuseHandler(abuseService)
+	crawlerConfig := config.LoadCrawlerConfig()
+	robotsHandler := handler.NewRobotsHandler(crawlerConfig.RobotsTxt, crawlerConfig.Disallow, config.BaseURL())
+
+	catalogStatsService := service.NewCatalogStatsService(repository.NewStatsRepository(db))
+	catalogStatsHandler := handler.NewCatalogStatsHandler(catalogStatsService)
+	if interval := config.CatalogStatsInterval(); interval > 0 {
+		go catalogStatsService.Run(context.Background(), interval)
+	}
+
+	gin.SetMode(config.GinMode())
 	r := gin.Default()
 
-	docs.SwaggerInfo.BasePath = "/"
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	proxyConfig := config.LoadProxyConfig()
+	if err := r.SetTrustedProxies(proxyConfig.TrustedProxies); err != nil {
+		log.Fatalf("Invalid proxy.trusted_proxies: %v", err)
+	}
+	r.RemoteIPHeaders = proxyConfig.Headers
+	r.Use(middleware.ResolveClientIP())
+
+	securityConfig := config.LoadSecurityConfig()
+	securityHeaders := middleware.SecurityHeaders(securityConfig.HSTSMaxAge, securityConfig.ContentSecurityPolicy)
+
+	quota := middleware.Quota(quotaService, quotaConfig.ExhaustedStatus)
+	profile := middleware.ResponseProfile()
+	experiments := middleware.Experiments(experimentService)
+	rateLimitConfig := config.LoadRateLimitConfig()
+	rateLimiter := service.NewRateLimiter(rateLimitConfig.Limit, rateLimitConfig.Window)
+	if redisClient != nil {
+		rateLimiter = service.NewSharedRateLimiter(rateLimitConfig.Limit, rateLimitConfig.Window, redis.NewRateCounter(redisClient))
+	}
+	rateLimit := middleware.RateLimit(rateLimiter, rateLimitConfig.Enforce)
+	abuse := middleware.Abuse(abuseService)
+	noIndex := middleware.OnRoutes(crawlerConfig.NoIndex, middleware.NoIndex())
+	users := middleware.Users(authService)
+	identifyKey := middleware.IdentifyAPIKey(middleware.APIKeys(apiConfig.APIKeys))
+	unbounded := middleware.AllowUnbounded(userService, middleware.APIKeys(apiConfig.APIKeys))
+	timeoutConfig := config.LoadTimeoutConfig()
+	timeout := middleware.Timeout(timeoutConfig.Default, timeoutConfig.Routes)
+	routes := handler.Routes{
+		Public:  r.Group("", securityHeaders, profile, timeout, noIndex, users, identifyKey, unbounded, abuse, rateLimit, quota, experiments),
+		Private: r.Group("", securityHeaders, profile, timeout),
+	}
+	if securityConfig.CSRFEnabled {
+		routes.Private.Use(middleware.CSRF(securityConfig.SessionCookie))
+	}
+	if apiConfig.PublicReadOnly {
+		routes.Private.Use(middleware.LoginGuard(loginGuard), middleware.RequireAuth(
+			middleware.UserTokens(authService),
+			middleware.APIKeys(apiConfig.APIKeys),
+			middleware.SignedRequests(partnerService),
+		))
+	}
+	if crawlerConfig.BulkRequiresAPIKey {
+		routes.Public.Use(middleware.OnRoutes(crawlerConfig.BulkRoutes, middleware.RequireAuth(
+			middleware.APIKeys(apiConfig.APIKeys),
+			middleware.SignedRequests(partnerService),
+		)))
+	}
+	routes.Private.Use(users, identifyKey, middleware.Impersonation(impersonationService), middleware.ActiveUser(userService), abuse, rateLimit, quota, experiments)
+	// Private routes no rule covers still need an authenticated caller,
+	// even with RBAC off
+	var privateRules []dto.AccessRule
+	if rbacConfig.Enabled {
+		routes.Public.Use(middleware.RequireRoles(userService, rbacConfig.Rules))
+		privateRules = rbacConfig.Rules
+	}
+	routes.Private.Use(middleware.RequirePrivateRoles(userService, privateRules))
+
+	swaggerConfig := config.LoadSwaggerConfig()
+	if swaggerConfig.Enabled {
+		var swaggerAccess []gin.HandlerFunc
+		switch swaggerConfig.Auth {
+		case config.SwaggerAuthNone:
+		case config.SwaggerAuthBasic:
+			if swaggerConfig.Username == "" || swaggerConfig.Password == "" {
+				log.Println("SWAGGER_USERNAME or SWAGGER_PASSWORD not set, Swagger docs are not served")
+				swaggerConfig.Enabled = false
+			} else {
+				swaggerAccess = append(swaggerAccess, gin.BasicAuth(gin.Accounts{swaggerConfig.Username: swaggerConfig.Password}))
+			}
+		case config.SwaggerAuthAdmin:
+			swaggerAccess = append(swaggerAccess, users, middleware.RequireRoles(userService, []dto.AccessRule{
+				{Prefix: "/swagger", Roles: []model.UserRole{model.RoleAdmin}},
+			}))
+		default:
+			log.Fatalf("swagger.auth must be one of none, basic, admin, got %q", swaggerConfig.Auth)
+		}
+		if swaggerConfig.Enabled {
+			docs.SwaggerInfo.BasePath = "/"
+			r.GET("/swagger/*any", append(swaggerAccess, ginSwagger.WrapHandler(swaggerFiles.Handler))...)
+		}
+	}
 
-	bookHandler.RegisterRoutes(r)
-	favHandler.RegisterRoutes(r)
+	bookHandler.RegisterRoutes(routes)
+	authorHandler.RegisterRoutes(routes)
+	categoryHandler.RegisterRoutes(routes)
+	tagHandler.RegisterRoutes(routes)
+	copyHandler.RegisterRoutes(routes)
+	loanHandler.RegisterRoutes(routes)
+	reservationHandler.RegisterRoutes(routes)
+	bookLockHandler.RegisterRoutes(routes)
+	favHandler.RegisterRoutes(routes)
+	collectionHandler.RegisterRoutes(routes)
+	readingLogHandler.RegisterRoutes(routes)
+	synonymHandler.RegisterRoutes(routes)
+	recentlyViewedHandler.RegisterRoutes(routes)
+	privacyHandler.RegisterRoutes(routes)
+	linkHandler.RegisterRoutes(routes)
+	citationHandler.RegisterRoutes(routes)
+	opdsHandler.RegisterRoutes(routes)
+	sitemapHandler.RegisterRoutes(routes)
+	embedHandler.RegisterRoutes(routes)
+	quotaHandler.RegisterRoutes(routes)
+	partnerHandler.RegisterRoutes(routes)
+	retentionHandler.RegisterRoutes(routes)
+	accountHandler.RegisterRoutes(routes)
+	impersonationHandler.RegisterRoutes(routes)
+	orgHandler.RegisterRoutes(routes)
+	catalogSyncHandler.RegisterRoutes(routes)
+	importHandler.RegisterRoutes(routes)
+	changeRequestHandler.RegisterRoutes(routes)
+	reportHandler.RegisterRoutes(routes)
+	exportHandler.RegisterRoutes(routes)
+	changeEventHandler.RegisterRoutes(routes)
+	archiveHandler.RegisterRoutes(routes)
+	maintenanceHandler.RegisterRoutes(routes)
+	notificationHandler.RegisterRoutes(routes)
+	reminderHandler.RegisterRoutes(routes)
+	eventHandler.RegisterRoutes(routes)
+	illHandler.RegisterRoutes(routes)
+	sampleHandler.RegisterRoutes(routes)
+	enrichmentHandler.RegisterRoutes(routes)
+	similarBooksHandler.RegisterRoutes(routes)
+	affinityHandler.RegisterRoutes(routes)
+	experimentHandler.RegisterRoutes(routes)
+	guestFavoriteHandler.RegisterRoutes(routes)
+	inboxHandler.RegisterRoutes(routes)
+	userHandler.RegisterRoutes(routes)
+	abuseHandler.RegisterRoutes(routes)
+	robotsHandler.RegisterRoutes(routes)
+	catalogStatsHandler.RegisterRoutes(routes)
+	authHandler.RegisterRoutes(routes)
+	validationRuleHandler.RegisterRoutes(routes)
+	reviewHandler.RegisterRoutes(routes)
+	// The /debug/ rule keeps the counters, memory stats and command line
+	// to admins
+	routes.Private.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 
 	r.NoRoute(handler.NotFoundHandler)
 
 	log.Println("Server running at http://localhost:8080")
-	log.Println("Swagger docs available at http://localhost:8080/swagger/index.html")
+	if swaggerConfig.Enabled {
+		log.Println("Swagger docs available at http://localhost:8080/swagger/index.html")
+	}
 
 	// Run server
 	r.Run(":8080")
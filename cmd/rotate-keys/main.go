@@ -0,0 +1,45 @@
+// Command rotate-keys re-encrypts every encrypted column with the current
+// key from ENCRYPTION_CURRENT_KEY. Keep the previous keys in ENCRYPTION_KEYS
+// while it runs; they can be removed once it has finished. It also
+// recomputes blind indexes, so run it after setting or changing
+// BLIND_INDEX_KEY.
+package main
+
+import (
+	"bms-go/config"
+	"bms-go/internal/infra/repository"
+	"bms-go/util"
+	"log"
+)
+
+func main() {
+	config.LoadEnv()
+	config.Load()
+	util.InitEncryption()
+
+	db := util.InitDB()
+
+	partners, err := repository.NewPartnerRepository(db).ReencryptSecrets()
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt partner secrets: %v", err)
+	}
+	log.Printf("Re-encrypted %d partner secrets", partners)
+
+	targets, err := repository.NewNotificationRepository(db).ReencryptTargets()
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt notification targets: %v", err)
+	}
+	log.Printf("Re-encrypted %d notification targets", targets)
+
+	tokens, err := repository.NewDeviceRepository(db).ReencryptTokens()
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt push tokens: %v", err)
+	}
+	log.Printf("Re-encrypted %d push tokens", tokens)
+
+	emails, err := repository.NewUserRepository(db).ReencryptEmails()
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt user emails: %v", err)
+	}
+	log.Printf("Re-encrypted %d user emails", emails)
+}
@@ -0,0 +1,50 @@
+// Command sync compares the catalogs of two deployments and pushes books
+// that are missing or outdated on the target:
+//
+//	go run ./cmd/sync --source https://staging.example --target https://prod.example --dry-run
+//
+// It prints the report as JSON.
+package main
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bms-go/internal/infra/remote"
+	"bms-go/internal/service"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+func main() {
+	source := flag.String("source", "", "base URL of the deployment to copy from")
+	target := flag.String("target", "", "base URL of the deployment to update")
+	sourceKey := flag.String("source-key", os.Getenv("SYNC_SOURCE_API_KEY"), "API key for the source")
+	targetKey := flag.String("target-key", os.Getenv("SYNC_TARGET_API_KEY"), "API key for the target")
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	flag.Parse()
+
+	if *source == "" || *target == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := httpclient.New(httpclient.DefaultConfig())
+	report, err := service.SyncCatalogs(
+		remote.NewCatalog(*source, *sourceKey, client),
+		remote.NewCatalog(*target, *targetKey, client),
+		*dryRun,
+	)
+	if err != nil {
+		log.Fatalf("Sync failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
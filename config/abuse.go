@@ -0,0 +1,57 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// RateLimitConfig is the short-term request allowance per client, on top of
+// the monthly quota. It is advertised in RateLimit-* headers on every
+// response and only enforced with 429 when Enforce is set. A limit of 0
+// disables it.
+type RateLimitConfig struct {
+	Limit   int
+	Window  time.Duration
+	Enforce bool
+}
+
+func LoadRateLimitConfig() RateLimitConfig {
+	viper.SetDefault("rate_limit.limit", 600)
+	viper.SetDefault("rate_limit.window", "1m")
+	viper.SetDefault("rate_limit.enforce", false)
+	return RateLimitConfig{
+		Limit:   viper.GetInt("rate_limit.limit"),
+		Window:  viper.GetDuration("rate_limit.window"),
+		Enforce: viper.GetBool("rate_limit.enforce"),
+	}
+}
+
+// AbuseConfig tunes the detector that flags clients with abnormal request
+// patterns. A client is flagged when within one Window it makes more than
+// MaxRequests requests, or opens more than MaxDistinctBooks different book
+// detail pages, as when walking the catalog by id. Flags are kept for
+// FlagTTL. Blocks set by administrators are reloaded every RefreshInterval
+// so all instances pick them up.
+type AbuseConfig struct {
+	Window           time.Duration
+	MaxRequests      int
+	MaxDistinctBooks int
+	FlagTTL          time.Duration
+	RefreshInterval  time.Duration
+}
+
+func LoadAbuseConfig() AbuseConfig {
+	viper.SetDefault("abuse.window", "10m")
+	viper.SetDefault("abuse.max_requests", 3000)
+	viper.SetDefault("abuse.max_distinct_books", 300)
+	viper.SetDefault("abuse.flag_ttl", "24h")
+	viper.SetDefault("abuse.refresh_interval", "1m")
+	return AbuseConfig{
+		Window:           viper.GetDuration("abuse.window"),
+		MaxRequests:      viper.GetInt("abuse.max_requests"),
+		MaxDistinctBooks: viper.GetInt("abuse.max_distinct_books"),
+		FlagTTL:          viper.GetDuration("abuse.flag_ttl"),
+		RefreshInterval:  viper.GetDuration("abuse.refresh_interval"),
+	}
+}
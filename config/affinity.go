@@ -0,0 +1,28 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// AffinityConfig controls the co-favorite graph behind related books and
+// recommendations. Pairs favorited together by fewer than MinUsers users
+// are left out, and each book keeps its MaxRelated strongest links. An
+// interval of 0 disables the scheduled rebuild.
+type AffinityConfig struct {
+	MinUsers   int
+	MaxRelated int
+	Interval   time.Duration
+}
+
+func LoadAffinityConfig() AffinityConfig {
+	viper.SetDefault("affinity.min_users", 2)
+	viper.SetDefault("affinity.max_related", 20)
+	viper.SetDefault("affinity.interval", "6h")
+	return AffinityConfig{
+		MinUsers:   viper.GetInt("affinity.min_users"),
+		MaxRelated: viper.GetInt("affinity.max_related"),
+		Interval:   viper.GetDuration("affinity.interval"),
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// APIConfig controls which parts of the API are exposed without credentials
+type APIConfig struct {
+	// PublicReadOnly exposes only the safe read endpoints anonymously and
+	// requires an API key for everything else
+	PublicReadOnly bool
+	APIKeys        []string
+	// SignatureTolerance is how far a signed request's timestamp may be from
+	// the server clock
+	SignatureTolerance time.Duration
+	// ImpersonationTTL is how long a support impersonation token stays valid
+	ImpersonationTTL time.Duration
+}
+
+func LoadAPIConfig() APIConfig {
+	viper.SetDefault("api.public_read_only", false)
+	viper.SetDefault("api.signature_tolerance", "5m")
+	viper.SetDefault("api.impersonation_ttl", "1h")
+	return APIConfig{
+		PublicReadOnly:     viper.GetBool("api.public_read_only"),
+		APIKeys:            viper.GetStringSlice("api.keys"),
+		SignatureTolerance: viper.GetDuration("api.signature_tolerance"),
+		ImpersonationTTL:   viper.GetDuration("api.impersonation_ttl"),
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// BaseURL is the public address of the catalog, used to build absolute links
+func BaseURL() string {
+	viper.SetDefault("app.base_url", "http://localhost:8080")
+	return strings.TrimRight(viper.GetString("app.base_url"), "/")
+}
+
+// EditLockTTL is how long a book edit lock lasts after its last heartbeat
+func EditLockTTL() time.Duration {
+	viper.SetDefault("app.edit_lock_ttl", "2m")
+	return viper.GetDuration("app.edit_lock_ttl")
+}
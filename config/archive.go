@@ -0,0 +1,28 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ArchiveConfig controls when change events and impersonation audit entries
+// are moved into their archive tables, how many rows are moved per
+// transaction and how often the job runs. An interval of 0 disables the
+// scheduled job.
+type ArchiveConfig struct {
+	Age       time.Duration
+	BatchSize int
+	Interval  time.Duration
+}
+
+func LoadArchiveConfig() ArchiveConfig {
+	viper.SetDefault("archive.age", "2160h")
+	viper.SetDefault("archive.batch_size", 1000)
+	viper.SetDefault("archive.interval", "24h")
+	return ArchiveConfig{
+		Age:       viper.GetDuration("archive.age"),
+		BatchSize: viper.GetInt("archive.batch_size"),
+		Interval:  viper.GetDuration("archive.interval"),
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// AuthConfig controls user sign-in. JWTSecret signs access tokens and comes
+// from the environment; without it registration and login are unavailable
+// and access tokens are never accepted.
+type AuthConfig struct {
+	JWTSecret         string
+	Issuer            string
+	TokenTTL          time.Duration
+	MinPasswordLength int
+}
+
+func LoadAuthConfig() AuthConfig {
+	viper.SetDefault("auth.issuer", "bms-go")
+	viper.SetDefault("auth.token_ttl", "24h")
+	viper.SetDefault("auth.min_password_length", 8)
+	return AuthConfig{
+		JWTSecret:         os.Getenv("JWT_SECRET"),
+		Issuer:            viper.GetString("auth.issuer"),
+		TokenTTL:          viper.GetDuration("auth.token_ttl"),
+		MinPasswordLength: viper.GetInt("auth.min_password_length"),
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ResponseCacheConfig controls the cache for expensive GETs. A TTL of 0
+// disables it. MaxEntries bounds the in-memory cache only.
+type ResponseCacheConfig struct {
+	TTL        time.Duration
+	MaxEntries int
+}
+
+func LoadResponseCacheConfig() ResponseCacheConfig {
+	viper.SetDefault("cache.response_ttl", "30s")
+	viper.SetDefault("cache.max_entries", 1000)
+	return ResponseCacheConfig{
+		TTL:        viper.GetDuration("cache.response_ttl"),
+		MaxEntries: viper.GetInt("cache.max_entries"),
+	}
+}
+
+// RedisConfig locates the Redis server that keeps the response cache and the
+// rate limit counts shared by every instance, so an invalidation on one
+// reaches them all and clients get one limit across replicas. With no
+// address both stay in memory. Password comes from the environment.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	Timeout  time.Duration
+}
+
+func LoadRedisConfig() RedisConfig {
+	viper.SetDefault("redis.addr", "")
+	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.timeout", "500ms")
+	return RedisConfig{
+		Addr:     viper.GetString("redis.addr"),
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       viper.GetInt("redis.db"),
+		Timeout:  viper.GetDuration("redis.timeout"),
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// CatalogRefreshConfig controls the job that keeps books pulled from other
+// deployments up to date. RateLimit is the most requests per second sent to
+// one deployment. APIKey is sent to every upstream deployment and comes from
+// the environment so it stays out of config.yaml. An interval of 0 disables
+// the scheduled job.
+type CatalogRefreshConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	RateLimit float64
+	APIKey    string
+}
+
+func LoadCatalogRefreshConfig() CatalogRefreshConfig {
+	viper.SetDefault("catalog_refresh.interval", "6h")
+	viper.SetDefault("catalog_refresh.batch_size", 100)
+	viper.SetDefault("catalog_refresh.rate_limit", 1)
+	return CatalogRefreshConfig{
+		Interval:  viper.GetDuration("catalog_refresh.interval"),
+		BatchSize: viper.GetInt("catalog_refresh.batch_size"),
+		RateLimit: viper.GetFloat64("catalog_refresh.rate_limit"),
+		APIKey:    os.Getenv("CATALOG_REFRESH_API_KEY"),
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// CDCSettleDelay is how old a change event must be before the change log
+// hands it out. Sequence numbers are assigned when a transaction writes its
+// event but become visible when it commits, so the newest events are held
+// back until any earlier, slower transaction has had time to commit.
+func CDCSettleDelay() time.Duration {
+	viper.SetDefault("cdc.settle_delay", "2s")
+	return viper.GetDuration("cdc.settle_delay")
+}
@@ -0,0 +1,10 @@
+package config
+
+import "github.com/spf13/viper"
+
+// DamagePhotoMaxSize is the largest photo that can be attached to a damage
+// report, in bytes
+func DamagePhotoMaxSize() int64 {
+	viper.SetDefault("copies.damage_photo_max_size", 5<<20)
+	return viper.GetInt64("copies.damage_photo_max_size")
+}
@@ -0,0 +1,32 @@
+package config
+
+import "github.com/spf13/viper"
+
+// CrawlerConfig controls how crawlers and scrapers see the public catalog.
+// RobotsTxt replaces the generated robots.txt when set; otherwise it is
+// built from Disallow and points at the sitemap. NoIndex lists route
+// patterns (e.g. "/books/:id/qr") answered with an X-Robots-Tag: noindex
+// header. With BulkRequiresAPIKey, BulkRoutes need an API key or partner
+// signature while every other public route stays open.
+type CrawlerConfig struct {
+	RobotsTxt          string
+	Disallow           []string
+	NoIndex            []string
+	BulkRequiresAPIKey bool
+	BulkRoutes         []string
+}
+
+func LoadCrawlerConfig() CrawlerConfig {
+	viper.SetDefault("crawlers.robots_txt", "")
+	viper.SetDefault("crawlers.disallow", []string{"/admin/", "/me/", "/guest/", "/reminders/", "/debug/", "/swagger/", "/books/export", "/books/stream"})
+	viper.SetDefault("crawlers.noindex", []string{"/books", "/books/compare", "/books/random", "/books/:id/qr", "/embed/books/:id", "/oembed", "/opds", "/opds/books"})
+	viper.SetDefault("crawlers.bulk_requires_api_key", false)
+	viper.SetDefault("crawlers.bulk_routes", []string{"/books", "/books/stream", "/books/export", "/opds/books"})
+	return CrawlerConfig{
+		RobotsTxt:          viper.GetString("crawlers.robots_txt"),
+		Disallow:           viper.GetStringSlice("crawlers.disallow"),
+		NoIndex:            viper.GetStringSlice("crawlers.noindex"),
+		BulkRequiresAPIKey: viper.GetBool("crawlers.bulk_requires_api_key"),
+		BulkRoutes:         viper.GetStringSlice("crawlers.bulk_routes"),
+	}
+}
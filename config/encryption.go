@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EncryptionKeys reads the column encryption keys from the environment so
+// they never live in config.yaml:
+//
+//	ENCRYPTION_KEYS=2024:<base64 key>,2025:<base64 key>
+//	ENCRYPTION_CURRENT_KEY=2025
+//
+// ok is false when no keys are configured.
+func EncryptionKeys() (current string, keys map[string][]byte, ok bool, err error) {
+	raw := strings.TrimSpace(os.Getenv("ENCRYPTION_KEYS"))
+	if raw == "" {
+		return "", nil, false, nil
+	}
+
+	keys = make(map[string][]byte)
+	for _, entry := range strings.Split(raw, ",") {
+		id, encoded, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || id == "" {
+			return "", nil, false, fmt.Errorf("ENCRYPTION_KEYS entry %q must be <id>:<base64 key>", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", nil, false, fmt.Errorf("ENCRYPTION_KEYS entry %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	current = os.Getenv("ENCRYPTION_CURRENT_KEY")
+	if current == "" && len(keys) == 1 {
+		for id := range keys {
+			current = id
+		}
+	}
+	return current, keys, true, nil
+}
+
+// BlindIndexKey reads the key that blind indexes of encrypted columns are
+// hashed with from BLIND_INDEX_KEY, as base64. It must be set whenever
+// ENCRYPTION_KEYS is, and must not change without running rotate-keys.
+// ok is false when it is not set.
+func BlindIndexKey() (key []byte, ok bool, err error) {
+	raw := strings.TrimSpace(os.Getenv("BLIND_INDEX_KEY"))
+	if raw == "" {
+		return nil, false, nil
+	}
+	key, err = base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("BLIND_INDEX_KEY: %w", err)
+	}
+	if len(key) < 32 {
+		return nil, false, fmt.Errorf("BLIND_INDEX_KEY must be at least 32 bytes, got %d", len(key))
+	}
+	return key, true, nil
+}
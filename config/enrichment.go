@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// EnrichmentConfig controls the pipeline that suggests descriptions for
+// books without one. Endpoint is the summarization API; when it is empty
+// the pipeline has no steps and is off. APIKey comes from the environment
+// so it stays out of config.yaml. An interval of 0 disables the scheduled
+// job.
+type EnrichmentConfig struct {
+	Endpoint  string
+	APIKey    string
+	MaxWords  int
+	BatchSize int
+	Interval  time.Duration
+}
+
+func LoadEnrichmentConfig() EnrichmentConfig {
+	viper.SetDefault("enrichment.endpoint", "")
+	viper.SetDefault("enrichment.max_words", 60)
+	viper.SetDefault("enrichment.batch_size", 20)
+	viper.SetDefault("enrichment.interval", "1h")
+	return EnrichmentConfig{
+		Endpoint:  viper.GetString("enrichment.endpoint"),
+		APIKey:    os.Getenv("ENRICHMENT_API_KEY"),
+		MaxWords:  viper.GetInt("enrichment.max_words"),
+		BatchSize: viper.GetInt("enrichment.batch_size"),
+		Interval:  viper.GetDuration("enrichment.interval"),
+	}
+}
@@ -0,0 +1,14 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// FavoriteReconcileInterval is how often books' favorite counts are checked
+// against their favorites and fixed; 0 disables the job
+func FavoriteReconcileInterval() time.Duration {
+	viper.SetDefault("favorites.reconcile_interval", "24h")
+	return viper.GetDuration("favorites.reconcile_interval")
+}
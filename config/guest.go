@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// GuestConfig controls favorites kept by visitors who have not signed in.
+// Secret signs the session cookie and comes from the environment; without
+// it no cookie is issued and guests must name their session with a
+// client-generated ID instead. Guest favorites untouched for TTL are purged
+// every CleanupInterval; an interval of 0 disables the job.
+type GuestConfig struct {
+	Secret          string
+	Cookie          string
+	TTL             time.Duration
+	MaxFavorites    int
+	CleanupInterval time.Duration
+}
+
+func LoadGuestConfig() GuestConfig {
+	viper.SetDefault("guest.cookie", "guest_session")
+	viper.SetDefault("guest.ttl", "720h")
+	viper.SetDefault("guest.max_favorites", 100)
+	viper.SetDefault("guest.cleanup_interval", "24h")
+	return GuestConfig{
+		Secret:          os.Getenv("GUEST_SESSION_SECRET"),
+		Cookie:          viper.GetString("guest.cookie"),
+		TTL:             viper.GetDuration("guest.ttl"),
+		MaxFavorites:    viper.GetInt("guest.max_favorites"),
+		CleanupInterval: viper.GetDuration("guest.cleanup_interval"),
+	}
+}
@@ -0,0 +1,9 @@
+package config
+
+import "github.com/spf13/viper"
+
+// RecentlyViewedLimit is how many book views are kept per user
+func RecentlyViewedLimit() int {
+	viper.SetDefault("recently_viewed.limit", 20)
+	return viper.GetInt("recently_viewed.limit")
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bms-go/internal/infra/httpclient"
+
+	"github.com/spf13/viper"
+)
+
+// LoadHTTPClientConfig reads the settings shared by all outbound HTTP calls
+func LoadHTTPClientConfig() httpclient.Config {
+	defaults := httpclient.DefaultConfig()
+	viper.SetDefault("http_client.timeout", defaults.Timeout)
+	viper.SetDefault("http_client.max_retries", defaults.MaxRetries)
+	viper.SetDefault("http_client.retry_backoff", defaults.RetryBackoff)
+	viper.SetDefault("http_client.rate_limit", defaults.RateLimit)
+	viper.SetDefault("http_client.breaker_threshold", defaults.BreakerThreshold)
+	viper.SetDefault("http_client.breaker_cooldown", defaults.BreakerCooldown)
+	return httpclient.Config{
+		Timeout:          viper.GetDuration("http_client.timeout"),
+		MaxRetries:       viper.GetInt("http_client.max_retries"),
+		RetryBackoff:     viper.GetDuration("http_client.retry_backoff"),
+		RateLimit:        viper.GetFloat64("http_client.rate_limit"),
+		BreakerThreshold: viper.GetInt("http_client.breaker_threshold"),
+		BreakerCooldown:  viper.GetDuration("http_client.breaker_cooldown"),
+	}
+}
+
+// Dependencies with a client of their own, whose settings can be tuned
+// apart from the shared ones
+const (
+	DependencyEmbeddings    = "embeddings"
+	DependencyEnrichment    = "enrichment"
+	DependencyCatalog       = "catalog"
+	DependencyNotifications = "notifications"
+	DependencyCovers        = "covers"
+)
+
+// LoadDependencyClientConfig reads the client settings of the dependency
+// called name: the shared ones, with any set under
+// http_client.dependencies.<name> in their place. Calls made while serving
+// a request still end by that request's deadline.
+func LoadDependencyClientConfig(name string) httpclient.Config {
+	cfg := LoadHTTPClientConfig()
+	prefix := "http_client.dependencies." + name + "."
+	if viper.IsSet(prefix + "timeout") {
+		cfg.Timeout = viper.GetDuration(prefix + "timeout")
+	}
+	if viper.IsSet(prefix + "max_retries") {
+		cfg.MaxRetries = viper.GetInt(prefix + "max_retries")
+	}
+	if viper.IsSet(prefix + "retry_backoff") {
+		cfg.RetryBackoff = viper.GetDuration(prefix + "retry_backoff")
+	}
+	if viper.IsSet(prefix + "rate_limit") {
+		cfg.RateLimit = viper.GetFloat64(prefix + "rate_limit")
+	}
+	if viper.IsSet(prefix + "breaker_threshold") {
+		cfg.BreakerThreshold = viper.GetInt(prefix + "breaker_threshold")
+	}
+	if viper.IsSet(prefix + "breaker_cooldown") {
+		cfg.BreakerCooldown = viper.GetDuration(prefix + "breaker_cooldown")
+	}
+	return cfg
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// LoanPeriod is how long a borrowed book may be kept before it is due back
+func LoanPeriod() time.Duration {
+	viper.SetDefault("loans.period", "336h")
+	period := viper.GetDuration("loans.period")
+	if period <= 0 {
+		log.Fatalf("loans.period must be positive")
+	}
+	return period
+}
+
+// LoanHistoryConfig controls how long returned loans of patrons who opted
+// out of keeping their loan history stay on record, and how often the ones
+// past ForgetAfter are deleted. An interval of 0 disables the job.
+type LoanHistoryConfig struct {
+	ForgetAfter time.Duration
+	Interval    time.Duration
+}
+
+func LoadLoanHistoryConfig() LoanHistoryConfig {
+	viper.SetDefault("loans.history.forget_after", "720h")
+	viper.SetDefault("loans.history.interval", "24h")
+	cfg := LoanHistoryConfig{
+		ForgetAfter: viper.GetDuration("loans.history.forget_after"),
+		Interval:    viper.GetDuration("loans.history.interval"),
+	}
+	if cfg.ForgetAfter < 0 {
+		log.Fatalf("loans.history.forget_after must not be negative")
+	}
+	return cfg
+}
+
+// ReminderConfig controls the reminders sent before loans are due.
+// DaysBefore are the days before the due date users are reminded on until
+// they choose their own. Secret signs the unsubscribe links in reminders and
+// comes from the environment; without it reminders carry no link. An
+// interval of 0 disables the job.
+type ReminderConfig struct {
+	DaysBefore []int
+	Interval   time.Duration
+	Secret     string
+}
+
+// MaxReminderDays is the earliest, in days before the due date, a reminder
+// may be sent
+const MaxReminderDays = 30
+
+func LoadReminderConfig() ReminderConfig {
+	viper.SetDefault("loans.reminders.days_before", []int{3, 1})
+	viper.SetDefault("loans.reminders.interval", "24h")
+	cfg := ReminderConfig{
+		DaysBefore: viper.GetIntSlice("loans.reminders.days_before"),
+		Interval:   viper.GetDuration("loans.reminders.interval"),
+		Secret:     os.Getenv("REMINDER_UNSUBSCRIBE_SECRET"),
+	}
+	for _, days := range cfg.DaysBefore {
+		if days < 1 || days > MaxReminderDays {
+			log.Fatalf("loans.reminders.days_before must be between 1 and %d, got %d", MaxReminderDays, days)
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// LockoutConfig controls how repeated authentication failures are slowed
+// down and locked out
+type LockoutConfig struct {
+	// MaxAttempts failures within Window lock the account or IP out
+	MaxAttempts int
+	Window      time.Duration
+	// BaseLockout doubles with every further failure up to MaxLockout
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	// CaptchaAfter failures require a CAPTCHA token; 0 disables it
+	CaptchaAfter int
+	// MaxTracked is how many accounts and IPs failures are kept for at
+	// once; 0 means no limit
+	MaxTracked int
+}
+
+func LoadLockoutConfig() LockoutConfig {
+	viper.SetDefault("auth.lockout.max_attempts", 5)
+	viper.SetDefault("auth.lockout.window", "15m")
+	viper.SetDefault("auth.lockout.base_lockout", "1m")
+	viper.SetDefault("auth.lockout.max_lockout", "1h")
+	viper.SetDefault("auth.lockout.captcha_after", 0)
+	viper.SetDefault("auth.lockout.max_tracked", 100000)
+	return LockoutConfig{
+		MaxAttempts:  viper.GetInt("auth.lockout.max_attempts"),
+		Window:       viper.GetDuration("auth.lockout.window"),
+		BaseLockout:  viper.GetDuration("auth.lockout.base_lockout"),
+		MaxLockout:   viper.GetDuration("auth.lockout.max_lockout"),
+		CaptchaAfter: viper.GetInt("auth.lockout.captcha_after"),
+		MaxTracked:   viper.GetInt("auth.lockout.max_tracked"),
+	}
+}
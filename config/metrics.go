@@ -0,0 +1,14 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// CatalogStatsInterval is how often the catalog statistics served at
+// /metrics/catalog are recounted; 0 disables the collector
+func CatalogStatsInterval() time.Duration {
+	viper.SetDefault("metrics.catalog_interval", "1m")
+	return viper.GetDuration("metrics.catalog_interval")
+}
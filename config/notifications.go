@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bms-go/internal/model/dto"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// NotificationConfig configures the notification channels and push
+// providers. Secrets come from the environment so they never live in
+// config.yaml; a channel whose secret is missing is unavailable.
+type NotificationConfig struct {
+	TelegramBotToken string
+	VAPIDPrivateKey  string
+	VAPIDSubject     string
+	// Templates override the built-in message templates by event name
+	Templates map[string]dto.NotificationTemplate
+
+	// FCMCredentialsFile is the Firebase service account JSON key
+	FCMCredentialsFile string
+	// APNsKeyFile is the .p8 token signing key; Topic is the app bundle id
+	APNsKeyFile string
+	APNsKeyID   string
+	APNsTeamID  string
+	APNsTopic   string
+	APNsSandbox bool
+
+	// InboxRetention is how long notifications stay in users' inboxes; 0
+	// keeps them forever. InboxCleanupInterval of 0 disables the cleanup job.
+	InboxRetention       time.Duration
+	InboxCleanupInterval time.Duration
+}
+
+func LoadNotificationConfig() NotificationConfig {
+	viper.SetDefault("notifications.vapid_subject", "mailto:support@bms-go.local")
+	viper.SetDefault("notifications.apns.sandbox", false)
+	viper.SetDefault("notifications.inbox.retention", "2160h")
+	viper.SetDefault("notifications.inbox.cleanup_interval", "24h")
+
+	cfg := NotificationConfig{
+		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
+		VAPIDPrivateKey:  os.Getenv("VAPID_PRIVATE_KEY"),
+		VAPIDSubject:     viper.GetString("notifications.vapid_subject"),
+
+		FCMCredentialsFile: os.Getenv("FCM_CREDENTIALS_FILE"),
+		APNsKeyFile:        os.Getenv("APNS_KEY_FILE"),
+		APNsKeyID:          viper.GetString("notifications.apns.key_id"),
+		APNsTeamID:         viper.GetString("notifications.apns.team_id"),
+		APNsTopic:          viper.GetString("notifications.apns.topic"),
+		APNsSandbox:        viper.GetBool("notifications.apns.sandbox"),
+
+		InboxRetention:       viper.GetDuration("notifications.inbox.retention"),
+		InboxCleanupInterval: viper.GetDuration("notifications.inbox.cleanup_interval"),
+	}
+	if err := viper.UnmarshalKey("notifications.templates", &cfg.Templates); err != nil {
+		log.Fatalf("Invalid notifications.templates configuration: %v", err)
+	}
+	return cfg
+}
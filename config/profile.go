@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"gorm.io/gorm/logger"
+)
+
+// Profile is the kind of deployment the service runs as, chosen by APP_ENV
+type Profile string
+
+const (
+	ProfileDev     Profile = "dev"
+	ProfileStaging Profile = "staging"
+	ProfileProd    Profile = "prod"
+)
+
+func (p Profile) Valid() bool {
+	return p == ProfileDev || p == ProfileStaging || p == ProfileProd
+}
+
+// profileDefaults are the settings a profile starts from, before
+// config.yaml and the profile's overlay are read
+var profileDefaults = map[Profile]map[string]interface{}{
+	ProfileDev: {
+		"server.gin_mode": gin.DebugMode,
+		"log.level":       "debug",
+		"swagger.enabled": true,
+		"swagger.auth":    string(SwaggerAuthNone),
+	},
+	ProfileStaging: {
+		"server.gin_mode": gin.ReleaseMode,
+		"log.level":       "info",
+		"swagger.enabled": true,
+		"swagger.auth":    string(SwaggerAuthBasic),
+	},
+	ProfileProd: {
+		"server.gin_mode": gin.ReleaseMode,
+		"log.level":       "warn",
+		"swagger.enabled": false,
+		"swagger.auth":    string(SwaggerAuthBasic),
+	},
+}
+
+// logLevels maps log.level to how much the database logger reports: debug
+// logs every statement, info and warn slow ones and errors
+var logLevels = map[string]logger.LogLevel{
+	"debug":  logger.Info,
+	"info":   logger.Warn,
+	"warn":   logger.Warn,
+	"error":  logger.Error,
+	"silent": logger.Silent,
+}
+
+// Load reads the configuration for the profile APP_ENV names, dev when it
+// is unset: the profile's defaults, then config.yaml, then the profile's
+// overlay such as config.prod.yaml, each overriding the one before.
+// Environment variables override them all. It must run before any other
+// configuration is read.
+func Load() Profile {
+	profile := Profile(os.Getenv("APP_ENV"))
+	if profile == "" {
+		profile = ProfileDev
+	}
+	if !profile.Valid() {
+		log.Fatalf("APP_ENV must be one of dev, staging, prod, got %q", profile)
+	}
+	for key, value := range profileDefaults[profile] {
+		viper.SetDefault(key, value)
+	}
+
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath(".")
+	viper.AddConfigPath("./config")
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		log.Printf("Config file not found, attempting to load from environment variables: %v", err)
+	}
+
+	viper.SetConfigName("config." + string(profile))
+	var notFound viper.ConfigFileNotFoundError
+	if err := viper.MergeInConfig(); errors.As(err, &notFound) {
+		log.Printf("No %s overlay found, using config.yaml as is", profile)
+	} else if err != nil {
+		log.Fatalf("Invalid %s configuration: %v", profile, err)
+	}
+
+	viper.AutomaticEnv()
+	log.Printf("Running with the %s profile", profile)
+	return profile
+}
+
+// GinMode is the mode gin runs in: debug, release or test
+func GinMode() string {
+	mode := viper.GetString("server.gin_mode")
+	if mode != gin.DebugMode && mode != gin.ReleaseMode && mode != gin.TestMode {
+		log.Fatalf("server.gin_mode must be one of debug, release, test, got %q", mode)
+	}
+	return mode
+}
+
+// DatabaseLogLevel is how much the database logger reports at log.level
+func DatabaseLogLevel() logger.LogLevel {
+	level, ok := logLevels[viper.GetString("log.level")]
+	if !ok {
+		log.Fatalf("log.level must be one of debug, info, warn, error, silent")
+	}
+	return level
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// ProxyConfig controls how the client address is found behind load
+// balancers. Forwarding headers are only believed from TrustedProxies, IPs
+// or CIDR ranges; with none the connection's address is used as is.
+type ProxyConfig struct {
+	TrustedProxies []string
+	// Headers carry the client address set by a trusted proxy, tried in order
+	Headers []string
+}
+
+func LoadProxyConfig() ProxyConfig {
+	viper.SetDefault("proxy.trusted_proxies", []string{})
+	viper.SetDefault("proxy.headers", []string{"X-Forwarded-For", "X-Real-IP"})
+	return ProxyConfig{
+		TrustedProxies: viper.GetStringSlice("proxy.trusted_proxies"),
+		Headers:        viper.GetStringSlice("proxy.headers"),
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"net/http"
+
+	"github.com/spf13/viper"
+)
+
+// QuotaConfig sets the monthly request allowance of subjects without an
+// override and the status returned once it is used up
+type QuotaConfig struct {
+	DefaultMonthlyLimit int64
+	ExhaustedStatus     int
+}
+
+func LoadQuotaConfig() QuotaConfig {
+	viper.SetDefault("quota.default_monthly_limit", 0)
+	viper.SetDefault("quota.exhausted_status", http.StatusTooManyRequests)
+
+	status := viper.GetInt("quota.exhausted_status")
+	if status != http.StatusPaymentRequired {
+		status = http.StatusTooManyRequests
+	}
+	return QuotaConfig{
+		DefaultMonthlyLimit: viper.GetInt64("quota.default_monthly_limit"),
+		ExhaustedStatus:     status,
+	}
+}
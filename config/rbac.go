@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bms-go/internal/model/dto"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// RBACConfig restricts routes to user roles. Rules are tried in order and
+// the first one matching a request decides; public requests no rule matches
+// are left alone, and private ones only need an authenticated caller.
+// BootstrapAdmins are the emails of accounts made admins at startup, so a
+// fresh deployment has someone to grant roles.
+//
+// Without RBAC the admin routes and catalog writes are open to any
+// authenticated caller, so it can only be turned off in debug mode.
+type RBACConfig struct {
+	Enabled         bool
+	Rules           []dto.AccessRule
+	BootstrapAdmins []string
+}
+
+func LoadRBACConfig() RBACConfig {
+	viper.SetDefault("rbac.enabled", true)
+	cfg := RBACConfig{
+		Enabled:         viper.GetBool("rbac.enabled"),
+		BootstrapAdmins: viper.GetStringSlice("rbac.bootstrap_admins"),
+	}
+	if err := viper.UnmarshalKey("rbac.rules", &cfg.Rules); err != nil {
+		log.Fatalf("Invalid rbac.rules configuration: %v", err)
+	}
+	for i, rule := range cfg.Rules {
+		for j, method := range rule.Methods {
+			cfg.Rules[i].Methods[j] = strings.ToUpper(method)
+		}
+		for _, role := range rule.Roles {
+			if !role.Valid() {
+				log.Fatalf("rbac.rules: role must be one of reader, librarian, admin, got %q", role)
+			}
+		}
+	}
+	if !cfg.Enabled {
+		if GinMode() != gin.DebugMode {
+			log.Fatalf("rbac.enabled is off, which leaves the admin routes open to any authenticated caller; it can only be turned off in debug mode")
+		}
+		log.Println("rbac.enabled is off: the admin routes and catalog writes are open to any authenticated caller")
+	}
+	return cfg
+}
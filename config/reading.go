@@ -0,0 +1,24 @@
+package config
+
+import (
+	"bms-go/internal/model/dto"
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+// LoadReadingSpeed reads the reading section used to estimate reading times
+// from page counts
+func LoadReadingSpeed() dto.ReadingSpeed {
+	viper.SetDefault("reading.words_per_page", dto.DefaultReadingSpeed.WordsPerPage)
+	viper.SetDefault("reading.words_per_minute", dto.DefaultReadingSpeed.WordsPerMinute)
+
+	var speed dto.ReadingSpeed
+	if err := viper.UnmarshalKey("reading", &speed); err != nil {
+		log.Fatalf("Invalid reading configuration: %v", err)
+	}
+	if speed.WordsPerPage <= 0 || speed.WordsPerMinute <= 0 {
+		log.Fatalf("reading.words_per_page and reading.words_per_minute must be positive")
+	}
+	return speed
+}
@@ -0,0 +1,14 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ReportCheckInterval is how often due report schedules are looked for; 0
+// disables scheduled reports
+func ReportCheckInterval() time.Duration {
+	viper.SetDefault("reports.check_interval", "15m")
+	return viper.GetDuration("reports.check_interval")
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ReservationConfig controls the queues for books that are out on loan.
+// A returned copy is held for the next reservation for Hold; holds that run
+// out are released, and copies added to stock offered, every Interval.
+type ReservationConfig struct {
+	Hold     time.Duration
+	Interval time.Duration
+}
+
+func LoadReservationConfig() ReservationConfig {
+	viper.SetDefault("reservations.hold", "72h")
+	viper.SetDefault("reservations.interval", "15m")
+	cfg := ReservationConfig{
+		Hold:     viper.GetDuration("reservations.hold"),
+		Interval: viper.GetDuration("reservations.interval"),
+	}
+	if cfg.Hold <= 0 {
+		log.Fatalf("reservations.hold must be positive")
+	}
+	return cfg
+}
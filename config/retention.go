@@ -0,0 +1,27 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// RetentionConfig controls how long soft-deleted books are kept, how long a
+// deleted account can still be restored, and how often the purge jobs run.
+// An interval of 0 disables the scheduled jobs.
+type RetentionConfig struct {
+	Period       time.Duration
+	AccountGrace time.Duration
+	Interval     time.Duration
+}
+
+func LoadRetentionConfig() RetentionConfig {
+	viper.SetDefault("retention.period", "720h")
+	viper.SetDefault("retention.account_grace", "168h")
+	viper.SetDefault("retention.interval", "24h")
+	return RetentionConfig{
+		Period:       viper.GetDuration("retention.period"),
+		AccountGrace: viper.GetDuration("retention.account_grace"),
+		Interval:     viper.GetDuration("retention.interval"),
+	}
+}
@@ -0,0 +1,9 @@
+package config
+
+import "github.com/spf13/viper"
+
+// SampleMaxSize is the largest book sample that can be uploaded, in bytes
+func SampleMaxSize() int64 {
+	viper.SetDefault("samples.max_size", 5<<20)
+	return viper.GetInt64("samples.max_size")
+}
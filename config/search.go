@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bms-go/internal/model/dto"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// LoadRelevanceWeights reads the search.weights section, falling back to the
+// defaults below for any weight that is not configured
+func LoadRelevanceWeights() dto.RelevanceWeights {
+	viper.SetDefault("search.weights.exact_title", 10)
+	viper.SetDefault("search.weights.title_prefix", 6)
+	viper.SetDefault("search.weights.title_contains", 4)
+	viper.SetDefault("search.weights.author", 3)
+	viper.SetDefault("search.weights.description", 2)
+	viper.SetDefault("search.weights.reviews", 1)
+	viper.SetDefault("search.weights.category", 1)
+	viper.SetDefault("search.weights.popularity", 0.1)
+	viper.SetDefault("search.weights.semantic", 0.7)
+
+	var weights dto.RelevanceWeights
+	if err := viper.UnmarshalKey("search.weights", &weights); err != nil {
+		log.Fatalf("Invalid search.weights configuration: %v", err)
+	}
+	return weights
+}
+
+// SemanticSearchConfig configures the embeddings behind semantic search.
+// Endpoint is an OpenAI-compatible embeddings API; when it is empty
+// semantic search is off. APIKey comes from the environment so it stays out
+// of config.yaml. A refresh interval of 0 disables the scheduled job that
+// embeds new and changed books.
+type SemanticSearchConfig struct {
+	Endpoint        string
+	Model           string
+	APIKey          string
+	Candidates      int
+	BatchSize       int
+	RefreshInterval time.Duration
+}
+
+func LoadSemanticSearchConfig() SemanticSearchConfig {
+	viper.SetDefault("search.semantic.endpoint", "")
+	viper.SetDefault("search.semantic.model", "text-embedding-3-small")
+	viper.SetDefault("search.semantic.candidates", 200)
+	viper.SetDefault("search.semantic.batch_size", 100)
+	viper.SetDefault("search.semantic.refresh_interval", "15m")
+	return SemanticSearchConfig{
+		Endpoint:        viper.GetString("search.semantic.endpoint"),
+		Model:           viper.GetString("search.semantic.model"),
+		APIKey:          os.Getenv("EMBEDDING_API_KEY"),
+		Candidates:      viper.GetInt("search.semantic.candidates"),
+		BatchSize:       viper.GetInt("search.semantic.batch_size"),
+		RefreshInterval: viper.GetDuration("search.semantic.refresh_interval"),
+	}
+}
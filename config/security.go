@@ -0,0 +1,33 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// SecurityConfig controls the security headers sent with API responses and
+// CSRF protection for browser sessions
+type SecurityConfig struct {
+	// HSTSMaxAge of 0 leaves out Strict-Transport-Security
+	HSTSMaxAge time.Duration
+	// ContentSecurityPolicy applies to every response that does not set its own
+	ContentSecurityPolicy string
+	CSRFEnabled           bool
+	// SessionCookie is the cookie that marks a browser session; only requests
+	// carrying it are subject to CSRF checks
+	SessionCookie string
+}
+
+func LoadSecurityConfig() SecurityConfig {
+	viper.SetDefault("security.hsts_max_age", "8760h")
+	viper.SetDefault("security.content_security_policy", "default-src 'none'; frame-ancestors 'none'")
+	viper.SetDefault("security.csrf.enabled", false)
+	viper.SetDefault("security.csrf.session_cookie", "session")
+	return SecurityConfig{
+		HSTSMaxAge:            viper.GetDuration("security.hsts_max_age"),
+		ContentSecurityPolicy: viper.GetString("security.content_security_policy"),
+		CSRFEnabled:           viper.GetBool("security.csrf.enabled"),
+		SessionCookie:         viper.GetString("security.csrf.session_cookie"),
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"bms-go/internal/model/dto"
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+// LoadSimilarityConfig reads the similar section behind "more like this"
+func LoadSimilarityConfig() dto.SimilarityConfig {
+	viper.SetDefault("similar.strategy", dto.SimilarityAuto)
+	viper.SetDefault("similar.candidates", 500)
+	viper.SetDefault("similar.category_weight", 1)
+	viper.SetDefault("similar.author_weight", 1.5)
+	viper.SetDefault("similar.text_weight", 3)
+
+	var cfg dto.SimilarityConfig
+	if err := viper.UnmarshalKey("similar", &cfg); err != nil {
+		log.Fatalf("Invalid similar configuration: %v", err)
+	}
+	if !cfg.Strategy.Valid() {
+		log.Fatalf("similar.strategy must be one of auto, content, embedding")
+	}
+	return cfg
+}
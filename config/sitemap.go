@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// SitemapConfig controls sitemap pagination and how long generated
+// documents are cached
+type SitemapConfig struct {
+	PageSize int
+	CacheTTL time.Duration
+}
+
+func LoadSitemapConfig() SitemapConfig {
+	// 50,000 URLs is the most a single sitemap file may hold
+	viper.SetDefault("sitemap.page_size", 50000)
+	viper.SetDefault("sitemap.cache_ttl", "1h")
+
+	pageSize := viper.GetInt("sitemap.page_size")
+	if pageSize < 1 || pageSize > 50000 {
+		pageSize = 50000
+	}
+	return SitemapConfig{
+		PageSize: pageSize,
+		CacheTTL: viper.GetDuration("sitemap.cache_ttl"),
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// SwaggerAuth is what it takes to see the API docs
+type SwaggerAuth string
+
+const (
+	// SwaggerAuthNone serves the docs to anyone
+	SwaggerAuthNone SwaggerAuth = "none"
+	// SwaggerAuthBasic asks for SWAGGER_USERNAME and SWAGGER_PASSWORD with
+	// HTTP basic auth, which browsers prompt for
+	SwaggerAuthBasic SwaggerAuth = "basic"
+	// SwaggerAuthAdmin requires the access token of a signed-in admin
+	SwaggerAuthAdmin SwaggerAuth = "admin"
+)
+
+// SwaggerConfig controls the API docs under /swagger; its defaults come
+// from the profile. The basic auth
+// credentials come from the environment; without them the docs are not
+// served when Auth is basic.
+type SwaggerConfig struct {
+	Enabled  bool
+	Auth     SwaggerAuth
+	Username string
+	Password string
+}
+
+func LoadSwaggerConfig() SwaggerConfig {
+	return SwaggerConfig{
+		Enabled:  viper.GetBool("swagger.enabled"),
+		Auth:     SwaggerAuth(viper.GetString("swagger.auth")),
+		Username: os.Getenv("SWAGGER_USERNAME"),
+		Password: os.Getenv("SWAGGER_PASSWORD"),
+	}
+}
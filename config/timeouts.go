@@ -0,0 +1,33 @@
+package config
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// TimeoutConfig bounds how long a request may take before it is answered
+// with 503. Routes maps route patterns, as registered, to their own limit;
+// a limit of 0 turns the timeout off, as streaming routes need.
+type TimeoutConfig struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+func LoadTimeoutConfig() TimeoutConfig {
+	viper.SetDefault("timeouts.default", "30s")
+	routes := make(map[string]time.Duration)
+	for route, raw := range viper.GetStringMapString("timeouts.routes") {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Printf("Ignoring timeout %q for route %s: %v", raw, route, err)
+			continue
+		}
+		routes[route] = d
+	}
+	return TimeoutConfig{
+		Default: viper.GetDuration("timeouts.default"),
+		Routes:  routes,
+	}
+}
@@ -0,0 +1,109 @@
+// Package apperror gives the errors the API reports a machine-readable
+// code, so clients can tell them apart without matching on messages.
+// Services declare their errors with New; handlers and middleware answer
+// with Response or Message.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Code names an error for clients. Codes are stable; messages may change.
+type Code string
+
+// Codes shared by several services, and the generic codes of errors
+// without one of their own
+const (
+	CodeBookNotFound     Code = "BOOK_NOT_FOUND"
+	CodeCaptchaRequired  Code = "CAPTCHA_REQUIRED"
+	CodeValidationFailed Code = "VALIDATION_FAILED"
+	CodeNotFound         Code = "NOT_FOUND"
+	CodeUnauthorized     Code = "UNAUTHORIZED"
+	CodeForbidden        Code = "FORBIDDEN"
+	CodeConflict         Code = "CONFLICT"
+	CodeTooLarge         Code = "TOO_LARGE"
+	CodeRateLimited      Code = "RATE_LIMITED"
+	CodeUnavailable      Code = "UNAVAILABLE"
+	CodeTimeout          Code = "TIMEOUT"
+	CodeInternal         Code = "INTERNAL"
+)
+
+// Error is an error with a code
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New returns an error with code and message. Each call returns a distinct
+// error, so the result can be compared with errors.Is like errors.New.
+func New(code Code, message string) error {
+	return &Error{Code: code, Message: message}
+}
+
+// CodeOf returns the code of the first *Error in err's chain,
+// CodeNotFound for a record the repository did not find, or "" when err has
+// no code
+func CodeOf(err error) Code {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return CodeNotFound
+	}
+	return ""
+}
+
+// ForStatus is the generic code of an error answered with HTTP status
+func ForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusNotAcceptable, http.StatusUnsupportedMediaType:
+		return CodeValidationFailed
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound, http.StatusGone:
+		return CodeNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusTooManyRequests, http.StatusPaymentRequired:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	return CodeInternal
+}
+
+// Body is the JSON body of an error response
+type Body struct {
+	Error string `json:"error"`
+	Code  Code   `json:"code"`
+}
+
+// Response is the body answering err with HTTP status. Errors without a
+// code of their own get the generic code of status.
+func Response(status int, err error) Body {
+	code := CodeOf(err)
+	if code == "" {
+		code = ForStatus(status)
+	}
+	return Body{Error: err.Error(), Code: code}
+}
+
+// Message is the body answering with message and the generic code of HTTP
+// status
+func Message(status int, message string) Body {
+	return Body{Error: message, Code: ForStatus(status)}
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry struct {
+	response Response
+	tags     []string
+	expires  time.Time
+}
+
+// MemoryStore keeps up to maxEntries responses in process memory
+type MemoryStore struct {
+	maxEntries int
+
+	mu         sync.Mutex
+	entries    map[string]entry
+	tagged     map[string]map[string]struct{}
+	generation uint64
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]entry),
+		tagged:     make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryStore) Get(key string) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return Response{}, false
+	}
+	return e.response, true
+}
+
+func (s *MemoryStore) Generation() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// Set stores resp unless generation is stale. When the store is full,
+// expired entries are dropped first and the response is not stored if that
+// frees no room.
+func (s *MemoryStore) Set(key string, resp Response, ttl time.Duration, generation uint64, tags []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if generation != s.generation {
+		return
+	}
+
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		now := time.Now()
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				s.remove(k)
+			}
+		}
+		if len(s.entries) >= s.maxEntries {
+			return
+		}
+	}
+
+	s.remove(key)
+	s.entries[key] = entry{response: resp, tags: tags, expires: time.Now().Add(ttl)}
+	for _, tag := range tags {
+		if s.tagged[tag] == nil {
+			s.tagged[tag] = make(map[string]struct{})
+		}
+		s.tagged[tag][key] = struct{}{}
+	}
+}
+
+func (s *MemoryStore) Invalidate(tags []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	for _, tag := range tags {
+		for key := range s.tagged[tag] {
+			s.remove(key)
+		}
+	}
+}
+
+// remove deletes key and its tag index entries. The caller holds s.mu.
+func (s *MemoryStore) remove(key string) {
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	for _, tag := range e.tags {
+		delete(s.tagged[tag], key)
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import "time"
+
+// Tags group cached responses by the data they were built from, so a write
+// can drop every response that may now be stale
+const (
+	TagBooks     = "books"
+	TagFavorites = "favorites"
+)
+
+// Response is a cached HTTP response body
+type Response struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Store keeps cached responses for a ResponseCache. Generation changes
+// whenever entries are invalidated, and Set stores nothing when it no
+// longer matches the generation passed in.
+type Store interface {
+	Get(key string) (Response, bool)
+	Generation() uint64
+	Set(key string, resp Response, ttl time.Duration, generation uint64, tags []string)
+	Invalidate(tags []string)
+}
+
+// ResponseCache keeps rendered responses for a TTL. Entries carry tags and
+// Invalidate drops every entry with a given tag. A nil *ResponseCache caches
+// nothing, so callers need not check whether caching is enabled.
+type ResponseCache struct {
+	ttl   time.Duration
+	store Store
+}
+
+// NewResponseCache returns a cache holding up to maxEntries responses in
+// memory for ttl each, or nil, which caches nothing, when ttl is not
+// positive. Each instance caches and invalidates on its own.
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	return NewSharedResponseCache(ttl, NewMemoryStore(maxEntries))
+}
+
+// NewSharedResponseCache returns a cache keeping responses in store for ttl
+// each, or nil when ttl is not positive. Instances sharing a store also
+// share invalidations.
+func NewSharedResponseCache(ttl time.Duration, store Store) *ResponseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &ResponseCache{ttl: ttl, store: store}
+}
+
+func (c *ResponseCache) Get(key string) (Response, bool) {
+	if c == nil {
+		return Response{}, false
+	}
+	return c.store.Get(key)
+}
+
+// Generation changes whenever entries are invalidated. Read it before
+// building a response and pass it to Set, so a response built from data
+// that changed in the meantime is not stored.
+func (c *ResponseCache) Generation() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.store.Generation()
+}
+
+// Set stores resp under key with tags unless an invalidation happened since
+// generation was read
+func (c *ResponseCache) Set(key string, resp Response, generation uint64, tags ...string) {
+	if c == nil {
+		return
+	}
+	c.store.Set(key, resp, c.ttl, generation, tags)
+}
+
+// Invalidate drops every entry carrying any of tags
+func (c *ResponseCache) Invalidate(tags ...string) {
+	if c == nil {
+		return
+	}
+	c.store.Invalidate(tags)
+}
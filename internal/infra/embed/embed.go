@@ -0,0 +1,45 @@
+// Package embed turns text into vector embeddings for semantic search.
+package embed
+
+import (
+	"context"
+	"math"
+)
+
+// Provider computes embeddings. Vectors from one provider and model are
+// comparable with each other only.
+type Provider interface {
+	// Model identifies the embedding model, so vectors from another model
+	// are recomputed instead of compared
+	Model() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Normalize scales v to unit length in place, so the dot product of two
+// normalized vectors is their cosine similarity
+func Normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// Dot is the dot product of a and b, 0 when their lengths differ
+func Dot(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
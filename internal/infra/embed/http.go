@@ -0,0 +1,91 @@
+package embed
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTP calls an OpenAI-compatible embeddings endpoint: a JSON POST of
+// {"model": ..., "input": [...]} answered with
+// {"data": [{"index": 0, "embedding": [...]}, ...]}. Most hosted providers
+// and local model servers speak it.
+type HTTP struct {
+	endpoint string
+	model    string
+	apiKey   string
+	client   *httpclient.Client
+}
+
+// NewHTTP returns a provider calling endpoint with model. apiKey is sent as
+// a bearer token when set.
+func NewHTTP(endpoint, model, apiKey string, client *httpclient.Client) *HTTP {
+	return &HTTP{endpoint: endpoint, model: model, apiKey: apiKey, client: client}
+}
+
+func (h *HTTP) Model() string {
+	return h.model
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (h *HTTP) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payload, err := json.Marshal(embeddingRequest{Model: h.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var out embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("embeddings: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embeddings: index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embeddings: no embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
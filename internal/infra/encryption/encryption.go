@@ -0,0 +1,209 @@
+// Package encryption encrypts sensitive columns at rest with AES-GCM.
+//
+// Fields tagged `gorm:"serializer:encrypted"` are stored as
+// "enc:<key id>:<base64 nonce+ciphertext>". Values without that prefix are
+// read as plaintext, so existing rows keep working until they are rewritten
+// by the rotate-keys command.
+//
+// Encrypted values cannot be compared in SQL, so columns that are looked up
+// by value keep a blind index next to them: a keyed hash of the value that
+// matches equal values without revealing them.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+const prefix = "enc:"
+
+var ErrUnknownKey = errors.New("value encrypted with an unknown key")
+
+// Keyring holds every key that may have encrypted stored values. New values
+// are always encrypted with the current key.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring builds a keyring from raw 16, 24 or 32 byte AES keys by id
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyring", current)
+	}
+
+	k := &Keyring{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, raw := range keys {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key id %q must not contain ':'", id)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// Encrypt seals plaintext with the current key
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.keys[k.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.current))
+	return prefix + k.current + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with whichever key sealed it.
+// Values that were never encrypted are returned unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+var (
+	mu       sync.RWMutex
+	keyring  *Keyring
+	indexKey []byte
+)
+
+// SetKeyring installs the keyring used by the "encrypted" serializer. Until
+// one is set, values are written in plaintext.
+func SetKeyring(k *Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	keyring = k
+}
+
+func currentKeyring() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return keyring
+}
+
+// SetBlindIndexKey installs the key BlindIndex hashes with. Unlike the
+// encryption keys it cannot be rotated in place: every blind index has to
+// be recomputed, as the rotate-keys command does, once it changes.
+func SetBlindIndexKey(key []byte) {
+	mu.Lock()
+	defer mu.Unlock()
+	indexKey = key
+}
+
+// BlindIndex returns the hex HMAC-SHA256 of value under the blind index key,
+// or its plain SHA-256 until a key is set, when the value itself is stored
+// in plaintext anyway. Empty values have an empty index.
+func BlindIndex(value string) string {
+	if value == "" {
+		return ""
+	}
+	mu.RLock()
+	key := indexKey
+	mu.RUnlock()
+
+	if key == nil {
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SerializerName is what fields encrypted at rest name in their tags
+const SerializerName = "encrypted"
+
+// RegisterSerializer makes Serializer available to GORM as SerializerName.
+// It must run before any model with encrypted fields is migrated or queried.
+func RegisterSerializer() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Serializer is the GORM serializer for string fields encrypted at rest
+type Serializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+		return field.Set(ctx, dst, "")
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("cannot decrypt %T into %s", dbValue, field.Name)
+	}
+
+	plaintext := stored
+	if k := currentKeyring(); k != nil {
+		var err error
+		if plaintext, err = k.Decrypt(stored); err != nil {
+			return fmt.Errorf("decrypt %s: %w", field.Name, err)
+		}
+	} else if strings.HasPrefix(stored, prefix) {
+		return fmt.Errorf("decrypt %s: no encryption keys configured", field.Name)
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+// Value implements schema.SerializerValuerInterface
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot encrypt %T field %s", fieldValue, field.Name)
+	}
+	if plaintext == "" {
+		return "", nil
+	}
+
+	k := currentKeyring()
+	if k == nil {
+		return plaintext, nil
+	}
+	return k.Encrypt(plaintext)
+}
@@ -0,0 +1,209 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func testKey(b byte) []byte { return bytes.Repeat([]byte{b}, 32) }
+
+func newTestKeyring(t *testing.T, current string, ids ...string) *Keyring {
+	t.Helper()
+	keys := make(map[string][]byte)
+	for i, id := range ids {
+		keys[id] = testKey(byte(i + 1))
+	}
+	k, err := NewKeyring(current, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	k := newTestKeyring(t, "2024", "2024")
+	for _, plaintext := range []string{"alice@example.com", "", "ünïcödé ✓", strings.Repeat("x", 4096)} {
+		sealed, err := k.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(sealed, "enc:2024:") {
+			t.Fatalf("Encrypt = %q, want the enc:2024: prefix", sealed)
+		}
+		if plaintext != "" && strings.Contains(sealed, plaintext) {
+			t.Fatalf("Encrypt = %q contains the plaintext", sealed)
+		}
+		got, err := k.Decrypt(sealed)
+		if err != nil || got != plaintext {
+			t.Fatalf("Decrypt(Encrypt(%q)) = %q, %v", plaintext, got, err)
+		}
+	}
+
+	// A fresh nonce each time: equal values do not look equal at rest
+	a, _ := k.Encrypt("alice@example.com")
+	b, _ := k.Encrypt("alice@example.com")
+	if a == b {
+		t.Error("encrypting the same value twice gave the same ciphertext")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	before := newTestKeyring(t, "2024", "2024")
+	old, err := before.Encrypt("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The new key is current; the old one stays to read what it sealed
+	after := newTestKeyring(t, "2025", "2024", "2025")
+	got, err := after.Decrypt(old)
+	if err != nil || got != "alice@example.com" {
+		t.Fatalf("Decrypt after rotation = %q, %v", got, err)
+	}
+	rewritten, err := after.Encrypt(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rewritten, "enc:2025:") {
+		t.Fatalf("re-encrypted value %q is not sealed with the new key", rewritten)
+	}
+
+	// Once the old key is retired, only rewritten values can be read
+	retired, err := NewKeyring("2025", map[string][]byte{"2025": testKey(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := retired.Decrypt(rewritten); err != nil || got != "alice@example.com" {
+		t.Fatalf("Decrypt of rewritten value = %q, %v", got, err)
+	}
+	if _, err := retired.Decrypt(old); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Decrypt with the retired key = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestKeyringReadsPlaintext(t *testing.T) {
+	k := newTestKeyring(t, "2024", "2024")
+	for _, stored := range []string{"alice@example.com", "", "https://hooks.slack.com/services/T0/B0/x", "encoded:not ours"} {
+		got, err := k.Decrypt(stored)
+		if err != nil || got != stored {
+			t.Errorf("Decrypt(%q) = %q, %v; want it unchanged", stored, got, err)
+		}
+	}
+}
+
+func TestKeyringRejectsTampering(t *testing.T) {
+	k := newTestKeyring(t, "2024", "2024", "2025")
+	sealed, err := k.Encrypt("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, "enc:2024:"))
+	raw[len(raw)-1] ^= 1
+
+	for name, stored := range map[string]string{
+		"flipped bit": "enc:2024:" + base64.StdEncoding.EncodeToString(raw),
+		// The key id is authenticated, so a value cannot be relabeled
+		"relabeled key": strings.Replace(sealed, "enc:2024:", "enc:2025:", 1),
+		"no key id":     "enc:" + strings.TrimPrefix(sealed, "enc:2024:"),
+		"truncated":     "enc:2024:AAAA",
+		"not base64":    "enc:2024:???",
+	} {
+		if got, err := k.Decrypt(stored); err == nil {
+			t.Errorf("%s: Decrypt = %q, want an error", name, got)
+		}
+	}
+}
+
+func TestNewKeyringRejectsBadKeys(t *testing.T) {
+	for name, tt := range map[string]struct {
+		current string
+		keys    map[string][]byte
+	}{
+		"current key missing": {"2025", map[string][]byte{"2024": testKey(1)}},
+		"short key":           {"2024", map[string][]byte{"2024": []byte("too short")}},
+		"colon in id":         {"20:24", map[string][]byte{"20:24": testKey(1)}},
+	} {
+		if _, err := NewKeyring(tt.current, tt.keys); err == nil {
+			t.Errorf("%s: NewKeyring succeeded", name)
+		}
+	}
+}
+
+type account struct {
+	Email string `gorm:"serializer:encrypted"`
+}
+
+// emailField is the encrypted field of account as GORM sees it
+func emailField(t *testing.T) *schema.Field {
+	t.Helper()
+	RegisterSerializer()
+	s, err := schema.Parse(&account{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.LookUpField("Email")
+}
+
+func TestSerializer(t *testing.T) {
+	field := emailField(t)
+	ctx := context.Background()
+	write := func(plaintext string) interface{} {
+		t.Helper()
+		stored, err := Serializer{}.Value(ctx, field, reflect.Value{}, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+	read := func(stored interface{}) (string, error) {
+		var row account
+		err := Serializer{}.Scan(ctx, field, reflect.ValueOf(&row).Elem(), stored)
+		return row.Email, err
+	}
+	t.Cleanup(func() { SetKeyring(nil) })
+
+	// Without keys, values are written and read as they are
+	SetKeyring(nil)
+	plain := write("alice@example.com")
+	if plain != "alice@example.com" {
+		t.Fatalf("stored without keys = %v", plain)
+	}
+
+	SetKeyring(newTestKeyring(t, "2024", "2024"))
+	sealed := write("alice@example.com")
+	if s, _ := sealed.(string); !strings.HasPrefix(s, "enc:2024:") {
+		t.Fatalf("stored with keys = %v, want it encrypted", sealed)
+	}
+	if write("") != "" {
+		t.Error("empty value was encrypted")
+	}
+	for name, stored := range map[string]interface{}{
+		"encrypted":        sealed,
+		"encrypted bytes":  []byte(sealed.(string)),
+		"plaintext row":    plain,
+		"plaintext bytes":  []byte("alice@example.com"),
+		"NULL reads empty": nil,
+	} {
+		want := "alice@example.com"
+		if stored == nil {
+			want = ""
+		}
+		if got, err := read(stored); err != nil || got != want {
+			t.Errorf("%s: read %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	// Encrypted rows cannot be read once the keys are gone
+	SetKeyring(nil)
+	if got, err := read(sealed); err == nil {
+		t.Errorf("read %q without keys, want an error", got)
+	}
+}
@@ -0,0 +1,22 @@
+// Package enrich suggests catalog data for books from outside sources, such
+// as a summary written by a language model.
+package enrich
+
+import (
+	"bms-go/internal/model"
+	"context"
+)
+
+// Suggestion is what an enricher proposes for a book. Either part may be
+// empty.
+type Suggestion struct {
+	Summary  string
+	Keywords []string
+}
+
+// Enricher is one step of the enrichment pipeline
+type Enricher interface {
+	// Name identifies the step in the review queue
+	Name() string
+	Enrich(ctx context.Context, book model.Book) (Suggestion, error)
+}
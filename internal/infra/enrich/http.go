@@ -0,0 +1,95 @@
+package enrich
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bms-go/internal/model"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTP asks a summarization API for a book summary and keywords. The
+// endpoint receives a JSON POST with the book's title, author, category,
+// published_year and the wanted max_words, and answers with
+// {"summary": "...", "keywords": ["..."]}. A small adapter in front of an
+// LLM provider is enough to serve it.
+type HTTP struct {
+	endpoint string
+	apiKey   string
+	maxWords int
+	client   *httpclient.Client
+}
+
+// NewHTTP returns an enricher calling endpoint. apiKey is sent as a bearer
+// token when set.
+func NewHTTP(endpoint, apiKey string, maxWords int, client *httpclient.Client) *HTTP {
+	return &HTTP{endpoint: endpoint, apiKey: apiKey, maxWords: maxWords, client: client}
+}
+
+func (h *HTTP) Name() string {
+	return "http"
+}
+
+type summaryRequest struct {
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	Category      string `json:"category"`
+	PublishedYear int    `json:"published_year,omitempty"`
+	MaxWords      int    `json:"max_words"`
+}
+
+type summaryResponse struct {
+	Summary  string   `json:"summary"`
+	Keywords []string `json:"keywords"`
+}
+
+func (h *HTTP) Enrich(ctx context.Context, book model.Book) (Suggestion, error) {
+	payload, err := json.Marshal(summaryRequest{
+		Title:         book.Title,
+		Author:        book.Author,
+		Category:      book.Category,
+		PublishedYear: book.PublishedYear,
+		MaxWords:      h.maxWords,
+	})
+	if err != nil {
+		return Suggestion{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return Suggestion{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Suggestion{}, fmt.Errorf("summarizer: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var out summaryResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return Suggestion{}, fmt.Errorf("summarizer: %w", err)
+	}
+
+	keywords := make([]string, 0, len(out.Keywords))
+	for _, k := range out.Keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+	return Suggestion{Summary: strings.TrimSpace(out.Summary), Keywords: keywords}, nil
+}
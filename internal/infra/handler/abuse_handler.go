@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AbuseHandler struct {
+	service *service.AbuseService
+}
+
+func NewAbuseHandler(s *service.AbuseService) *AbuseHandler {
+	return &AbuseHandler{service: s}
+}
+
+func (h *AbuseHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/abuse")
+	group.GET("/flags", h.GetFlags)
+	group.DELETE("/flags", h.ClearFlag)
+	group.GET("/blocks", h.GetBlocks)
+	group.POST("/blocks", h.BlockClient)
+	group.DELETE("/blocks/:id", h.UnblockClient)
+}
+
+func respondAbuseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrInvalidBlockDuration):
+		respondValidationError(c, []FieldError{{Field: "duration", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetFlags godoc
+// @Summary List flagged clients
+// @Description List clients this instance's abuse detector saw making too many requests or opening too many different books, most recently active first
+// @Tags Abuse
+// @Produce json
+// @Success 200 {array} dto.AbuseFlag
+// @Router /admin/abuse/flags [get]
+func (h *AbuseHandler) GetFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.GetFlags())
+}
+
+// ClearFlag godoc
+// @Summary Clear a flag
+// @Description Forget the flag of a client found to be legitimate
+// @Tags Abuse
+// @Param client query string true "Client identifier, e.g. ip:203.0.113.7"
+// @Success 204
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Router /admin/abuse/flags [delete]
+func (h *AbuseHandler) ClearFlag(c *gin.Context) {
+	client := c.Query("client")
+	if client == "" {
+		respondValidationError(c, []FieldError{{Field: "client", Message: "is required"}})
+		return
+	}
+	if !h.service.ClearFlag(client) {
+		respondMessage(c, http.StatusNotFound, "not found")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetBlocks godoc
+// @Summary List blocked clients
+// @Description List client blocks, including expired ones that have not been removed
+// @Tags Abuse
+// @Produce json
+// @Success 200 {array} model.ClientBlock
+// @Failure 500 {object} apperror.Body
+// @Router /admin/abuse/blocks [get]
+func (h *AbuseHandler) GetBlocks(c *gin.Context) {
+	blocks, err := h.service.GetBlocks()
+	if err != nil {
+		respondAbuseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, blocks)
+}
+
+// BlockClient godoc
+// @Summary Block a client
+// @Description Refuse every request from a client identifier (ip:<address>, key:<digest>, user:<id> or partner:<id>, as shown in flags) with 403, for the given duration or until unblocked. Blocking a client again replaces its block.
+// @Tags Abuse
+// @Accept json
+// @Produce json
+// @Param block body dto.BlockClientRequest true "Block"
+// @Success 201 {object} model.ClientBlock
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/abuse/blocks [post]
+func (h *AbuseHandler) BlockClient(c *gin.Context) {
+	var req dto.BlockClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	block, err := h.service.Block(req)
+	if err != nil {
+		respondAbuseError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, block)
+}
+
+// UnblockClient godoc
+// @Summary Unblock a client
+// @Description Remove a client block
+// @Tags Abuse
+// @Param id path int true "Block ID"
+// @Success 204
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/abuse/blocks/{id} [delete]
+func (h *AbuseHandler) UnblockClient(c *gin.Context) {
+	if err := h.service.Unblock(paramID(c, "id")); err != nil {
+		respondAbuseError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service *service.AccountService
+}
+
+func NewAccountHandler(s *service.AccountService) *AccountHandler {
+	return &AccountHandler{service: s}
+}
+
+func (h *AccountHandler) RegisterRoutes(routes Routes) {
+	routes.Private.GET("/me/data-export", h.ExportData)
+	routes.Private.DELETE("/me", h.RequestDeletion)
+	routes.Private.DELETE("/me/deletion", h.CancelDeletion)
+	routes.Private.GET("/admin/account-deletions", h.GetDeletions)
+}
+
+// ExportData godoc
+// @Summary Export my data
+// @Description Download everything stored about the user: their account, privacy settings, favorites, view history, reviews, reading statuses, loans, reservations, collections, notification channels and devices, inbox, RSVPs, inter-library loan requests, organizations, API usage and quota, and any pending deletion
+// @Tags Me
+// @Produce json
+// @Success 200 {object} dto.DataExport
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/data-export [get]
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.ExportData(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := "bms-data-export-" + strconv.FormatUint(uint64(userID), 10) + ".json"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// RequestDeletion godoc
+// @Summary Delete my account
+// @Description Schedule erasure of the user's personal data. The data is kept until the grace period ends and the deletion can be cancelled until then.
+// @Tags Me
+// @Produce json
+// @Success 202 {object} dto.AccountDeletionResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me [delete]
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.service.RequestDeletion(userID)
+	if errors.Is(err, service.ErrDeletionPending) {
+		respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// CancelDeletion godoc
+// @Summary Cancel account deletion
+// @Description Cancel a pending account deletion during its grace period
+// @Tags Me
+// @Produce json
+// @Success 200 {object} dto.AccountDeletionResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/deletion [delete]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.service.CancelDeletion(userID)
+	if errors.Is(err, service.ErrNoPendingDeletion) {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, deletion)
+}
+
+// GetDeletions godoc
+// @Summary List account deletions
+// @Description Audit trail of account deletion requests, newest first, with how many rows each erasure removed
+// @Tags Accounts
+// @Produce json
+// @Success 200 {array} dto.AccountDeletionResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/account-deletions [get]
+func (h *AccountHandler) GetDeletions(c *gin.Context) {
+	deletions, err := h.service.GetDeletions()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, deletions)
+}
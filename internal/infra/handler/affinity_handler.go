@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 20
+)
+
+type AffinityHandler struct {
+	service *service.AffinityService
+}
+
+func NewAffinityHandler(s *service.AffinityService) *AffinityHandler {
+	return &AffinityHandler{service: s}
+}
+
+func (h *AffinityHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/books/:id/related", h.GetRelatedBooks)
+	routes.Private.GET("/me/recommendations", h.GetRecommendations)
+	routes.Private.POST("/admin/affinity/rebuild", h.RebuildAffinity)
+}
+
+// parseRelatedLimit reads limit, restricted to all-ages books in shorter
+// lists under the kids profile
+func parseRelatedLimit(c *gin.Context) (int, model.ContentRating, []FieldError) {
+	var rating model.ContentRating
+	limit, limitCap := defaultRelatedLimit, maxRelatedLimit
+	if middleware.KidsProfile(c) {
+		rating = model.RatingAllAges
+		limitCap = min(limitCap, kidsMaxLimit)
+		middleware.ProfileFiltered(c)
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > limitCap {
+			return 0, "", []FieldError{{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(limitCap)}}
+		}
+		limit = n
+	}
+	return limit, rating, nil
+}
+
+// GetRelatedBooks godoc
+// @Summary Related books
+// @Description List the books most often favorited by the same users as this book ("users who favorited this also favorited"), strongest first. The links come from the co-favorite graph, which is rebuilt periodically from the favorites of users who allowed analytics.
+// @Tags Books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param limit query int false "Maximum number of books to return (1-20)" default(10)
+// @Success 200 {object} dto.RelatedBooksResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/related [get]
+func (h *AffinityHandler) GetRelatedBooks(c *gin.Context) {
+	limit, rating, errs := parseRelatedLimit(c)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	resp, err := h.service.GetRelatedBooks(c.Request.Context(), paramID(c, "id"), rating, limit)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "book not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRecommendations godoc
+// @Summary Recommended books
+// @Description List books the user has not favorited yet, ranked by how strongly the co-favorite graph relates them to the user's favorites
+// @Tags Me
+// @Produce json
+// @Param limit query int false "Maximum number of books to return (1-20)" default(10)
+// @Success 200 {array} dto.RecommendedBook
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/recommendations [get]
+func (h *AffinityHandler) GetRecommendations(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, rating, errs := parseRelatedLimit(c)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	recs, err := h.service.GetRecommendations(c.Request.Context(), userID, rating, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, recs)
+}
+
+// RebuildAffinity godoc
+// @Summary Rebuild co-favorite graph
+// @Description Recompute the related books of every book from the current favorites without waiting for the scheduled rebuild
+// @Tags Books
+// @Produce json
+// @Success 200 {object} dto.AffinityResult
+// @Failure 500 {object} apperror.Body
+// @Router /admin/affinity/rebuild [post]
+func (h *AffinityHandler) RebuildAffinity(c *gin.Context) {
+	result, err := h.service.Rebuild()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArchiveHandler struct {
+	service *service.ArchiveService
+}
+
+func NewArchiveHandler(s *service.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{service: s}
+}
+
+func (h *ArchiveHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/archive")
+	group.POST("/run", h.RunArchive)
+	group.GET("/change-events", h.GetChangeEvents)
+	group.GET("/impersonations/:id/actions", h.GetImpersonationActions)
+}
+
+// RunArchive godoc
+// @Summary Run archival
+// @Description Move change events and impersonation audit entries older than the archive age into the archive tables without waiting for the scheduled run
+// @Tags Archive
+// @Produce json
+// @Success 200 {object} dto.ArchiveResult
+// @Failure 500 {object} apperror.Body
+// @Router /admin/archive/run [post]
+func (h *ArchiveHandler) RunArchive(c *gin.Context) {
+	result, err := h.service.Archive()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetChangeEvents godoc
+// @Summary Query archived change events
+// @Description Archived change events in sequence order, optionally for one entity. Page with after_seq like the live change log.
+// @Tags Archive
+// @Produce json
+// @Param entity query string false "Entity filter" Enums(book, favorite)
+// @Param entity_id query int false "Entity ID filter"
+// @Param after_seq query int false "Return events after this sequence number" default(0)
+// @Param limit query int false "Maximum number of events to return (1-1000)" default(100)
+// @Success 200 {array} model.ArchivedChangeEvent
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/archive/change-events [get]
+func (h *ArchiveHandler) GetChangeEvents(c *gin.Context) {
+	var errs []FieldError
+
+	var entityID uint64
+	if raw, ok := c.GetQuery("entity_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "entity_id", Message: "must be a non-negative integer"})
+		}
+		entityID = id
+	}
+
+	var afterSeq uint64
+	if raw, ok := c.GetQuery("after_seq"); ok {
+		seq, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "after_seq", Message: "must be a non-negative integer"})
+		}
+		afterSeq = seq
+	}
+
+	limit := defaultChangeEventLimit
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxChangeEventLimit {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxChangeEventLimit)})
+		}
+		limit = n
+	}
+
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	events, err := h.service.GetChangeEvents(c.Query("entity"), uint(entityID), afterSeq, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// GetImpersonationActions godoc
+// @Summary Query archived impersonation actions
+// @Description Archived requests made under an impersonation session. Recent requests are listed by the live impersonation actions endpoint.
+// @Tags Archive
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {array} model.ArchivedImpersonationAction
+// @Failure 500 {object} apperror.Body
+// @Router /admin/archive/impersonations/{id}/actions [get]
+func (h *ArchiveHandler) GetImpersonationActions(c *gin.Context) {
+	actions, err := h.service.GetImpersonationActions(paramID(c, "id"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, actions)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	service *service.AuthService
+	guard   *service.LoginGuard
+}
+
+// NewAuthHandler counts failed logins against guard, per email and per
+// client IP, the same way failed API credentials are
+func NewAuthHandler(s *service.AuthService, guard *service.LoginGuard) *AuthHandler {
+	return &AuthHandler{service: s, guard: guard}
+}
+
+func (h *AuthHandler) RegisterRoutes(routes Routes) {
+	group := routes.Public.Group("/auth")
+	group.POST("/register", h.Register)
+	group.POST("/login", h.Login)
+
+	routes.Private.PUT("/me/password", h.ChangePassword)
+}
+
+func respondAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAuthUnavailable):
+		respondError(c, http.StatusServiceUnavailable, err)
+	case errors.Is(err, service.ErrInvalidCredentials):
+		respondError(c, http.StatusUnauthorized, err)
+	case errors.Is(err, service.ErrPasswordResetRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": apperror.CodeOf(err), "password_reset_required": true})
+	case errors.Is(err, service.ErrAccountDisabled):
+		respondError(c, http.StatusForbidden, err)
+	case errors.Is(err, service.ErrEmailTaken):
+		respondError(c, http.StatusConflict, err)
+	case errors.Is(err, service.ErrPasswordTooShort), errors.Is(err, service.ErrPasswordTooLong):
+		respondValidationError(c, []FieldError{{Field: "password", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// Register godoc
+// @Summary Register
+// @Description Create an account with a password and sign in to it
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param user body dto.RegisterRequest true "Account details"
+// @Success 201 {object} dto.TokenResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 409 {object} apperror.Body
+// @Failure 503 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req dto.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.service.Register(req)
+	if err != nil {
+		respondAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Exchange an email and password for an access token, sent as "Authorization: Bearer <token>". When an administrator required a password reset, new_password must be given as well. Repeated failures lock the email and client IP out for a while.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param credentials body dto.LoginRequest true "Credentials"
+// @Success 200 {object} dto.TokenResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 403 {object} apperror.Body
+// @Failure 429 {object} apperror.Body
+// @Failure 503 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req dto.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account := "email:" + strings.ToLower(strings.TrimSpace(req.Email))
+	ip := "ip:" + middleware.ClientIP(c)
+	if until := h.guard.LockedUntil(account, ip); !until.IsZero() {
+		retryAfter := int(math.Ceil(time.Until(until).Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		respondMessage(c, http.StatusTooManyRequests, "too many failed attempts, try again later")
+		return
+	}
+
+	resp, err := h.service.Login(req)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		h.guard.RecordFailure(account, ip)
+	}
+	if err != nil {
+		respondAuthError(c, err)
+		return
+	}
+	h.guard.RecordSuccess(account)
+	c.JSON(http.StatusOK, resp)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Replace the signed-in user's password. Existing access tokens stay valid until they expire.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param password body dto.ChangePasswordRequest true "Current and new password"
+// @Success 204
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.ChangePassword(userID, req); err != nil {
+		respondAuthError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuthorBookLimit = 20
+	maxAuthorBookLimit     = 100
+)
+
+type AuthorHandler struct {
+	service *service.AuthorService
+}
+
+func NewAuthorHandler(s *service.AuthorService) *AuthorHandler {
+	return &AuthorHandler{service: s}
+}
+
+func (h *AuthorHandler) RegisterRoutes(routes Routes) {
+	public := routes.Public.Group("/authors")
+	public.GET("", h.GetAuthors)
+	public.GET("/:id", h.GetAuthor)
+	public.GET("/:id/books", h.GetAuthorBooks)
+
+	private := routes.Private.Group("/authors")
+	private.POST("", h.CreateAuthor)
+	private.PUT("/:id", h.UpdateAuthor)
+	private.DELETE("/:id", h.DeleteAuthor)
+}
+
+func respondAuthorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAuthorNotFound):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrAuthorExists), errors.Is(err, service.ErrAuthorInUse):
+		respondError(c, http.StatusConflict, err)
+	case errors.Is(err, service.ErrBlankAuthor):
+		respondValidationError(c, []FieldError{{Field: "name", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetAuthors godoc
+// @Summary List authors
+// @Description Every author by name with their number of books, for filters and dropdowns
+// @Tags Authors
+// @Produce json
+// @Success 200 {array} dto.AuthorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /authors [get]
+func (h *AuthorHandler) GetAuthors(c *gin.Context) {
+	authors, err := h.service.GetAuthors()
+	if err != nil {
+		respondAuthorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, authors)
+}
+
+// GetAuthor godoc
+// @Summary Get author
+// @Tags Authors
+// @Produce json
+// @Param id path int true "Author ID"
+// @Success 200 {object} model.Author
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /authors/{id} [get]
+func (h *AuthorHandler) GetAuthor(c *gin.Context) {
+	author, err := h.service.GetAuthor(paramID(c, "id"))
+	if err != nil {
+		respondAuthorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, author)
+}
+
+// GetAuthorBooks godoc
+// @Summary List books by an author
+// @Description List an author's books, by title
+// @Tags Authors
+// @Produce json
+// @Param id path int true "Author ID"
+// @Param limit query int false "Maximum number of books to return (1-100)" default(20)
+// @Param offset query int false "Number of books to skip"
+// @Success 200 {object} dto.AuthorBookListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /authors/{id}/books [get]
+func (h *AuthorHandler) GetAuthorBooks(c *gin.Context) {
+	var errs []FieldError
+
+	// The kids profile only lists books rated for all ages, in short pages
+	var rating model.ContentRating
+	limit, limitCap := defaultAuthorBookLimit, maxAuthorBookLimit
+	if middleware.KidsProfile(c) {
+		rating = model.RatingAllAges
+		limitCap = kidsMaxLimit
+		middleware.ProfileFiltered(c)
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > limitCap {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(limitCap)})
+		}
+		limit = n
+	}
+	var offset int
+	if raw, ok := c.GetQuery("offset"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			errs = append(errs, FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		}
+		offset = n
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	books, err := h.service.GetBooks(c.Request.Context(), paramID(c, "id"), rating, limit, offset)
+	if err != nil {
+		respondAuthorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, books)
+}
+
+// CreateAuthor godoc
+// @Summary Create author
+// @Description Add an author. Names are unique ignoring case.
+// @Tags Authors
+// @Accept json
+// @Produce json
+// @Param author body dto.AuthorRequest true "Author"
+// @Success 201 {object} model.Author
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /authors [post]
+func (h *AuthorHandler) CreateAuthor(c *gin.Context) {
+	var req dto.AuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	author, err := h.service.CreateAuthor(req)
+	if err != nil {
+		respondAuthorError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, author)
+}
+
+// UpdateAuthor godoc
+// @Summary Rename author
+// @Description Rename an author. The author names of their books are updated in the same transaction, each with a change log entry.
+// @Tags Authors
+// @Accept json
+// @Produce json
+// @Param id path int true "Author ID"
+// @Param author body dto.AuthorRequest true "Author"
+// @Success 200 {object} model.Author
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /authors/{id} [put]
+func (h *AuthorHandler) UpdateAuthor(c *gin.Context) {
+	var req dto.AuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	author, err := h.service.UpdateAuthor(paramID(c, "id"), req)
+	if err != nil {
+		respondAuthorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, author)
+}
+
+// DeleteAuthor godoc
+// @Summary Delete author
+// @Description Delete an author without books
+// @Tags Authors
+// @Param id path int true "Author ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /authors/{id} [delete]
+func (h *AuthorHandler) DeleteAuthor(c *gin.Context) {
+	if err := h.service.DeleteAuthor(paramID(c, "id")); err != nil {
+		respondAuthorError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
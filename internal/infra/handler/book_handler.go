@@ -1,29 +1,74 @@
 package handler
 
 import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/middleware"
 	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
 	"bms-go/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxLimit = 100
+	// kidsMaxLimit caps list sizes, and is the default, in the kids profile
+	kidsMaxLimit = 20
+
+	minCompareBooks = 2
+	maxCompareBooks = 5
+
+	streamBatchSize = 500
+
+	// maxCopies caps the copies of a book, set on create or through
+	// PUT /books/:id/stock
+	maxCopies = 1000
+
+	// maxSearchLength caps the characters in a search, which is matched
+	// word by word against every searched column
+	maxSearchLength = 200
 )
 
 type BookHandler struct {
-	service *service.BookService
+	service        *service.BookService
+	recentlyViewed *service.RecentlyViewedService
+	locks          *service.BookLockService
+	responses      *cache.ResponseCache
 }
 
-func NewBookHandler(s *service.BookService) *BookHandler {
-	return &BookHandler{service: s}
+func NewBookHandler(s *service.BookService, recentlyViewed *service.RecentlyViewedService, locks *service.BookLockService, responses *cache.ResponseCache) *BookHandler {
+	return &BookHandler{service: s, recentlyViewed: recentlyViewed, locks: locks, responses: responses}
 }
 
-func (h *BookHandler) RegisterRoutes(r *gin.Engine) {
-	group := r.Group("/books")
-	group.GET("", h.GetBooks)
-	group.GET("/:id", h.GetBookByID)
-	group.POST("", h.CreateBook)
-	group.PUT("/:id", h.UpdateBook)
-	group.DELETE("/:id", h.DeleteBook)
+func (h *BookHandler) RegisterRoutes(routes Routes) {
+	public := routes.Public.Group("/books")
+	// Search results rank by favorite counts, so favorites invalidate them
+	// as well as book writes
+	cached := middleware.CacheResponses(h.responses, cache.TagBooks, cache.TagFavorites)
+	public.GET("", cached, h.GetBooks)
+	public.GET("/compare", middleware.CacheResponses(h.responses, cache.TagBooks), h.CompareBooks)
+	public.GET("/random", h.GetRandomBook)
+	public.GET("/stream", h.StreamBooks)
+	public.GET("/:id", h.GetBookByID)
+
+	private := routes.Private.Group("/books")
+	private.POST("", h.CreateBook)
+	private.POST("/bulk", h.CreateBooks)
+	private.DELETE("/bulk", h.DeleteBooks)
+	private.PUT("/:id", h.UpdateBook)
+	private.PATCH("/:id", h.PatchBook)
+	private.DELETE("/:id", h.DeleteBook)
 }
 
 // GetBooks godoc
@@ -32,21 +77,239 @@ func (h *BookHandler) RegisterRoutes(r *gin.Engine) {
 // @Tags Books
 // @Accept json
 // @Produce json
-// @Param search query string false "Search keyword"
+// @Param search query string false "Search keyword" maxlength(200)
+// @Param search_type query string false "keyword matches the search words; semantic also ranks books close in meaning to the search, blending embedding similarity with keyword relevance" Enums(keyword, semantic) default(keyword)
+// @Param include_fields query string false "Comma-separated long-form fields to search as well" Enums(description, reviews)
 // @Param category query string false "Category filter"
-// @Success 200 {array} model.Book
-// @Failure 500 {object} map[string]string
+// @Param tags query string false "Comma-separated tag names; only books with every tag are listed"
+// @Param media_type query string false "Media type filter" Enums(print, ebook, audiobook)
+// @Param accessibility query string false "Comma-separated accessibility features every book must have" Enums(large_print, braille, audiobook, dyslexic_font)
+// @Param source query string false "Only books added this way" Enums(manual, import, upstream)
+// @Param import_batch_id query int false "Only books added by this import"
+// @Param limit query int false "Maximum number of books to return (1-100); 0 returns every match to API key holders and admins"
+// @Param offset query int false "Number of books to skip"
+// @Param sort_by query string false "Sort field, defaults to relevance when searching" Enums(id, title, author, category, created_at, relevance, popularity)
+// @Param sort_order query string false "Sort direction" Enums(asc, desc)
+// @Param envelope query bool false "Wrap the books in {data, meta}, with paging details and did_you_mean spelling suggestions in meta"
+// @Param explain query bool false "Include relevance score breakdowns for each result; requires envelope"
+// @Success 200 {array} model.Book "Without envelope"
+// @Success 200 {object} dto.BookListResponse "With envelope=true"
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Failure 503 {object} apperror.Body
 // @Router /books [get]
 func (h *BookHandler) GetBooks(c *gin.Context) {
-	search := c.Query("search")
-	category := c.Query("category")
+	query, errs := parseBookQuery(c)
+	errs = append(errs, parseSearchType(c, &query)...)
+	envelope, envelopeErrs := parseEnvelope(c)
+	errs = append(errs, envelopeErrs...)
+	if query.Explain && !envelope {
+		errs = append(errs, FieldError{Field: "explain", Message: "requires envelope=true"})
+	}
+	if middleware.Unbounded(c) {
+		if query.SearchType == dto.SearchSemantic {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be positive for semantic search"})
+		}
+		if query.Explain {
+			errs = append(errs, FieldError{Field: "explain", Message: "is not supported with limit=0"})
+		}
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+	if variant, ok := middleware.ExperimentVariant(c, model.ExperimentSearchRanking); ok {
+		query.RankingOverrides = variant.Params
+	}
+	if middleware.Unbounded(c) {
+		h.streamBookList(c, query, envelope)
+		return
+	}
+
+	resp, err := h.service.GetBooks(c.Request.Context(), query)
+	if errors.Is(err, service.ErrSemanticSearchDisabled) {
+		respondError(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if !envelope {
+		c.JSON(http.StatusOK, resp.Data)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseEnvelope reads whether the list endpoint should wrap its books in
+// {data, meta}. Clients written before the envelope get the bare array.
+func parseEnvelope(c *gin.Context) (bool, []FieldError) {
+	raw, ok := c.GetQuery("envelope")
+	if !ok {
+		return false, nil
+	}
+	envelope, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, []FieldError{{Field: "envelope", Message: "must be a boolean"}}
+	}
+	return envelope, nil
+}
+
+// streamBookList writes an unbounded list in the list endpoint's usual
+// shape, flushing each page as it is read. The stream may outlast the
+// route's timeout and ends early only if the client goes away. A failure
+// part way closes an enveloped list with an error field in place of meta,
+// and leaves a bare array unterminated so it cannot be mistaken for the
+// whole list.
+func (h *BookHandler) streamBookList(c *gin.Context, query dto.BookQuery, envelope bool) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+	if envelope {
+		c.Writer.WriteString(`{"data":[`)
+	} else {
+		c.Writer.WriteString("[")
+	}
+
+	count := 0
+	ctx := context.WithoutCancel(c.Request.Context())
+	err := h.service.GetBooksInPages(ctx, query, streamBatchSize, func(books []model.Book) error {
+		for i := range books {
+			data, err := json.Marshal(books[i])
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				data = append([]byte{','}, data...)
+			}
+			if _, err := c.Writer.Write(data); err != nil {
+				return err
+			}
+			count++
+		}
+		c.Writer.Flush()
+		return nil
+	})
+
+	if err != nil && c.Request.Context().Err() == nil {
+		log.Printf("Failed to stream book list: %v", err)
+	}
+	if !envelope {
+		if err == nil {
+			c.Writer.WriteString("]")
+		}
+		return
+	}
 
-	books, err := h.service.GetBooks(search, category)
+	var tail interface{} = gin.H{"meta": dto.BookListMeta{Count: count, Offset: query.Offset}}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		tail = gin.H{"error": err.Error()}
+	}
+	data, _ := json.Marshal(tail)
+	// Continue the object opened above with the tail's fields
+	c.Writer.WriteString("],")
+	c.Writer.Write(data[1:])
+}
+
+// StreamBooks godoc
+// @Summary Stream books
+// @Description Stream every book matching the filters as newline-delimited JSON, one book per line in id order. The response is flushed after each batch so large catalogs can be consumed without paging. If the stream fails part way, the last line is an object with an error field.
+// @Tags Books
+// @Produce application/x-ndjson
+// @Param search query string false "Search keyword" maxlength(200)
+// @Param include_fields query string false "Comma-separated long-form fields to search as well" Enums(description, reviews)
+// @Param category query string false "Category filter"
+// @Param tags query string false "Comma-separated tag names; only books with every tag are listed"
+// @Param media_type query string false "Media type filter" Enums(print, ebook, audiobook)
+// @Param accessibility query string false "Comma-separated accessibility features every book must have" Enums(large_print, braille, audiobook, dyslexic_font)
+// @Param source query string false "Only books added this way" Enums(manual, import, upstream)
+// @Param import_batch_id query int false "Only books added by this import"
+// @Param author query string false "Author filter"
+// @Success 200 {string} string
+// @Failure 400 {object} ValidationErrorResponse
+// @Router /books/stream [get]
+func (h *BookHandler) StreamBooks(c *gin.Context) {
+	query, errs := parseBookQuery(c)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
 		return
 	}
-	c.JSON(http.StatusOK, books)
+	query.Author = c.Query("author")
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	ctx := c.Request.Context()
+
+	err := h.service.StreamBooks(ctx, query, streamBatchSize, func(books []model.Book) error {
+		// Stop reading batches once the client has gone away
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for i := range books {
+			if err := enc.Encode(books[i]); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Failed to stream books: %v", err)
+		enc.Encode(gin.H{"error": err.Error()})
+	}
+}
+
+// CompareBooks godoc
+// @Summary Compare books
+// @Description Get several books side by side with the fields that differ between them. Unknown IDs are reported in missing_ids.
+// @Tags Books
+// @Produce json
+// @Param ids query string true "Comma-separated book IDs (2-5)"
+// @Success 200 {object} dto.BookComparison
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /books/compare [get]
+func (h *BookHandler) CompareBooks(c *gin.Context) {
+	ids, errs := parseIDList(c.Query("ids"), "ids", minCompareBooks, maxCompareBooks)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	comparison, err := h.service.CompareBooks(c.Request.Context(), ids)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
+
+// GetRandomBook godoc
+// @Summary Get a random book
+// @Description Pick a random book for "surprise me", optionally within a category
+// @Tags Books
+// @Produce json
+// @Param category query string false "Category filter"
+// @Success 200 {object} model.Book
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/random [get]
+func (h *BookHandler) GetRandomBook(c *gin.Context) {
+	var rating model.ContentRating
+	if middleware.KidsProfile(c) {
+		rating = model.RatingAllAges
+	}
+	book, err := h.service.GetRandomBook(c.Request.Context(), c.Query("category"), rating)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "no books available")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, book)
 }
 
 // GetBookByID godoc
@@ -57,64 +320,252 @@ func (h *BookHandler) GetBooks(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Book ID"
 // @Success 200 {object} model.Book
-// @Failure 404 {object} map[string]string
+// @Failure 404 {object} apperror.Body
 // @Router /books/{id} [get]
 func (h *BookHandler) GetBookByID(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	book, err := h.service.GetBookByID(uint(id))
+	book, err := h.service.GetBookByID(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		respondMessage(c, http.StatusNotFound, "book not found")
 		return
 	}
+
+	if userID, ok := middleware.UserID(c); ok {
+		if err := h.recentlyViewed.RecordView(userID, book.ID); err != nil {
+			log.Printf("Failed to record view of book %d: %v", book.ID, err)
+		}
+	}
 	c.JSON(http.StatusOK, book)
 }
 
 // CreateBook godoc
 // @Summary Create new book
-// @Description Add a new book to the system
+// @Description Add a new book to the system. It must meet the validation rules configured under /admin/validation-rules. A category_id files it under that category; otherwise the category name is matched ignoring case and the category is created when new. Authors are listed in order, each by id or by name; an author name alone is one author. copies sets how many copies the library starts with, 1 when omitted.
 // @Tags Books
 // @Accept json
 // @Produce json
 // @Param book body model.Book true "Book object"
 // @Success 201 {object} model.Book
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
 // @Router /books [post]
 func (h *BookHandler) CreateBook(c *gin.Context) {
 	var book model.Book
 	if err := c.ShouldBindJSON(&book); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
-	if err := h.service.CreateBook(&book); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if errs := validateBook(&book); len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+	// Only imports mark books as imported
+	book.Source, book.ImportBatchID = model.SourceManual, nil
+	preferReferences(&book)
+	if err := h.service.CreateBook(c.Request.Context(), &book); err != nil {
+		respondBookWriteError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, book)
 }
 
+// BulkItemResult is the outcome of one book in a bulk request. Index is
+// its 0-based position in the request; Details lists the invalid fields of
+// a book that failed validation.
+type BulkItemResult struct {
+	Index   int           `json:"index"`
+	OK      bool          `json:"ok"`
+	BookID  uint          `json:"book_id,omitempty"`
+	Book    *model.Book   `json:"book,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Code    apperror.Code `json:"code,omitempty"`
+	Details []FieldError  `json:"details,omitempty"`
+}
+
+// BulkResponse is the outcome of every book in a bulk request, in the
+// order they were sent
+type BulkResponse struct {
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+func (r *BulkResponse) add(result BulkItemResult) {
+	if result.OK {
+		r.Succeeded++
+	} else {
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// bulkFailure describes why a book in a bulk request failed, the way
+// respondBookWriteError would for a single one
+func bulkFailure(index int, err error) BulkItemResult {
+	result := BulkItemResult{Index: index, Error: err.Error(), Code: apperror.CodeOf(err)}
+	if result.Code == "" {
+		result.Code = apperror.CodeInternal
+	}
+	var violation *service.RuleViolationError
+	switch {
+	case errors.As(err, &violation):
+		result.Error, result.Code = "invalid request parameters", apperror.CodeValidationFailed
+		for _, v := range violation.Violations {
+			result.Details = append(result.Details, FieldError{Field: v.Field, Message: v.Message})
+		}
+	case errors.Is(err, service.ErrUnknownCategory):
+		result.Error, result.Code = "invalid request parameters", apperror.CodeValidationFailed
+		result.Details = []FieldError{{Field: "category_id", Message: err.Error()}}
+	case errors.Is(err, service.ErrUnknownAuthor):
+		result.Error, result.Code = "invalid request parameters", apperror.CodeValidationFailed
+		result.Details = []FieldError{{Field: "authors", Message: err.Error()}}
+	}
+	return result
+}
+
+// CreateBooks godoc
+// @Summary Create books in bulk
+// @Description Add up to 100 books in one transaction. Each book is checked and written as by POST /books; books that fail are left out without undoing the others, and every book's outcome is reported in the order sent.
+// @Tags Books
+// @Accept json
+// @Produce json
+// @Param books body dto.BookBulkCreateRequest true "Books to create (1-100)"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/bulk [post]
+func (h *BookHandler) CreateBooks(c *gin.Context) {
+	var req dto.BookBulkCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	invalid := make(map[int][]FieldError)
+	var books []model.Book
+	var positions []int
+	for i, book := range req.Books {
+		if errs := validateBook(&book); len(errs) > 0 {
+			invalid[i] = errs
+			continue
+		}
+		book.Source, book.ImportBatchID = model.SourceManual, nil
+		preferReferences(&book)
+		books = append(books, book)
+		positions = append(positions, i)
+	}
+	errs, err := h.service.CreateBooks(c.Request.Context(), books)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := BulkResponse{Results: []BulkItemResult{}}
+	for i, j := 0, 0; i < len(req.Books); i++ {
+		if details, ok := invalid[i]; ok {
+			resp.add(BulkItemResult{Index: i, Error: "invalid request parameters", Code: apperror.CodeValidationFailed, Details: details})
+			continue
+		}
+		if errs[j] != nil {
+			resp.add(bulkFailure(i, errs[j]))
+		} else {
+			resp.add(BulkItemResult{Index: i, OK: true, BookID: books[j].ID, Book: &books[j]})
+		}
+		j++
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // UpdateBook godoc
 // @Summary Update book
-// @Description Update book information by ID
+// @Description Replace book information by ID; use PATCH to change only some fields. The result must meet the validation rules configured under /admin/validation-rules. A category_id takes precedence over the category name, and authors over the author name. Stock is not changed here; use PUT /books/{id}/stock.
 // @Tags Books
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
 // @Param book body model.Book true "Updated book data"
 // @Success 200 {object} model.Book
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apperror.Body
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} apperror.Body
 // @Router /books/{id} [put]
 func (h *BookHandler) UpdateBook(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	if !h.checkEditable(c, uint(id)) {
+		return
+	}
 	var book model.Book
 	if err := c.ShouldBindJSON(&book); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if errs := validateBook(&book); len(errs) > 0 {
+		respondValidationError(c, errs)
 		return
 	}
 	book.ID = uint(id)
-	if err := h.service.UpdateBook(&book); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	preferReferences(&book)
+	if err := h.service.UpdateBook(c.Request.Context(), &book); err != nil {
+		respondBookWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, book)
+}
+
+// PatchBook godoc
+// @Summary Partially update book
+// @Description Change only the fields sent, leaving the rest of the book as it is. Fields left out or null keep their value. A new category or category_id refiles the book, and a new author or authors replaces its authors, with category_id and authors taking precedence as in PUT. The result must meet the validation rules configured under /admin/validation-rules. A patch that changes nothing is not written, and the book is returned as it is. Stock is not changed here; use PUT /books/{id}/stock.
+// @Tags Books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param book body dto.BookPatchRequest true "Fields to change"
+// @Success 200 {object} model.Book
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id} [patch]
+func (h *BookHandler) PatchBook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		respondValidationError(c, []FieldError{{Field: "id", Message: "must be a positive integer"}})
+		return
+	}
+	if !h.checkEditable(c, uint(id)) {
+		return
+	}
+	var req dto.BookPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Authors != nil && len(req.Authors) == 0 {
+		respondValidationError(c, []FieldError{{Field: "authors", Message: "must list at least one author"}})
+		return
+	}
+
+	book, err := h.service.GetBookByID(c.Request.Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "book not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	// A patch that changes nothing is not written
+	if !req.Apply(book) {
+		c.JSON(http.StatusOK, book)
+		return
+	}
+	if errs := validateBook(book); len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+	preferReferences(book)
+	if err := h.service.UpdateBook(c.Request.Context(), book); err != nil {
+		respondBookWriteError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, book)
@@ -122,19 +573,350 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 
 // DeleteBook godoc
 // @Summary Delete book
-// @Description Delete a book by its ID
+// @Description Delete a book by its ID. A book with copies out on loan cannot be deleted until they are checked in.
 // @Tags Books
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
 // @Success 204 "No Content"
-// @Failure 500 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} apperror.Body
 // @Router /books/{id} [delete]
 func (h *BookHandler) DeleteBook(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.service.DeleteBook(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !h.checkEditable(c, uint(id)) {
+		return
+	}
+	err := h.service.DeleteBook(c.Request.Context(), uint(id))
+	if errors.Is(err, service.ErrBookHasLoans) {
+		respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// DeleteBooks godoc
+// @Summary Delete books in bulk
+// @Description Delete up to 100 books in one transaction. Books that do not exist, that have copies out on loan or that someone else holds the edit lock on fail without undoing the others, and every book's outcome is reported in the order sent.
+// @Tags Books
+// @Accept json
+// @Produce json
+// @Param books body dto.BookBulkDeleteRequest true "Book IDs (1-100)"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/bulk [delete]
+func (h *BookHandler) DeleteBooks(c *gin.Context) {
+	var req dto.BookBulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Anonymous callers hold no locks, so every locked book is locked for them
+	userID, _ := middleware.UserID(c)
+	locked := make(map[int]bool)
+	var ids []uint
+	for i, id := range req.IDs {
+		_, err := h.locks.CheckEditable(id, userID)
+		if errors.Is(err, service.ErrBookLocked) {
+			locked[i] = true
+			continue
+		}
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	var errs []error
+	if len(ids) > 0 {
+		var err error
+		if errs, err = h.service.DeleteBooks(c.Request.Context(), ids); err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	resp := BulkResponse{Results: []BulkItemResult{}}
+	for i, j := 0, 0; i < len(req.IDs); i++ {
+		failed := service.ErrBookLocked
+		if !locked[i] {
+			failed = errs[j]
+			j++
+		}
+		if failed != nil {
+			result := bulkFailure(i, failed)
+			result.BookID = req.IDs[i]
+			resp.add(result)
+		} else {
+			resp.add(BulkItemResult{Index: i, OK: true, BookID: req.IDs[i]})
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// checkEditable responds 409 and returns false when another user holds the
+// edit lock on the book. Anonymous callers hold no locks.
+func (h *BookHandler) checkEditable(c *gin.Context, id uint) bool {
+	userID, _ := middleware.UserID(c)
+	lock, err := h.locks.CheckEditable(id, userID)
+	if errors.Is(err, service.ErrBookLocked) {
+		respondBookLocked(c, lock)
+		return false
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return false
+	}
+	return true
+}
+
+// preferReferences lets the category_id and authors sent with a book decide
+// over its category and author names, which may be left over from an
+// earlier read
+func preferReferences(book *model.Book) {
+	if book.CategoryID != nil {
+		book.Category = ""
+	}
+	if len(book.Authors) > 0 {
+		book.Author = ""
+	}
+}
+
+// respondBookWriteError answers a book create or update the service refused
+func respondBookWriteError(c *gin.Context, err error) {
+	if respondRuleViolations(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrUnknownCategory) {
+		respondValidationError(c, []FieldError{{Field: "category_id", Message: err.Error()}})
+		return
+	}
+	if errors.Is(err, service.ErrUnknownAuthor) {
+		respondValidationError(c, []FieldError{{Field: "authors", Message: err.Error()}})
+		return
+	}
+	respondError(c, http.StatusInternalServerError, err)
+}
+
+// validateBook checks the enumerated and media-specific fields of a book
+// being written, defaulting its media type to print
+func validateBook(book *model.Book) []FieldError {
+	var errs []FieldError
+	for _, author := range book.Authors {
+		if author.ID == 0 && strings.TrimSpace(author.Name) == "" {
+			errs = append(errs, FieldError{Field: "authors", Message: "each author needs an id or a name"})
+			break
+		}
+	}
+	if book.ContentRating != "" && !book.ContentRating.Valid() {
+		errs = append(errs, FieldError{Field: "content_rating", Message: "must be one of all_ages, teen, adult"})
+	}
+
+	if book.MediaType == "" {
+		book.MediaType = model.MediaPrint
+	}
+	if !book.MediaType.Valid() {
+		errs = append(errs, FieldError{Field: "media_type", Message: "must be one of print, ebook, audiobook"})
+	} else if book.MediaType != model.MediaAudiobook {
+		if book.Narrator != "" {
+			errs = append(errs, FieldError{Field: "narrator", Message: "only applies to audiobooks"})
+		}
+		if book.DurationMinutes != 0 {
+			errs = append(errs, FieldError{Field: "duration_minutes", Message: "only applies to audiobooks"})
+		}
+	}
+	if book.DurationMinutes < 0 {
+		errs = append(errs, FieldError{Field: "duration_minutes", Message: "must not be negative"})
+	}
+	if book.Copies < 0 || book.Copies > maxCopies {
+		errs = append(errs, FieldError{Field: "copies", Message: "must be between 0 and " + strconv.Itoa(maxCopies)})
+	}
+	return errs
+}
+
+// parseSearchType reads search_type. Semantic searches need a search and
+// are always ordered by their blended relevance.
+func parseSearchType(c *gin.Context, query *dto.BookQuery) []FieldError {
+	raw, ok := c.GetQuery("search_type")
+	if !ok {
+		return nil
+	}
+	searchType := dto.SearchType(raw)
+	if !searchType.Valid() {
+		return []FieldError{{Field: "search_type", Message: "must be keyword or semantic"}}
+	}
+	query.SearchType = searchType
+	if searchType != dto.SearchSemantic {
+		return nil
+	}
+
+	var errs []FieldError
+	if query.Search == "" {
+		errs = append(errs, FieldError{Field: "search", Message: "is required for semantic search"})
+	}
+	if query.SortBy != dto.SortByRelevance {
+		errs = append(errs, FieldError{Field: "sort_by", Message: "must be relevance for semantic search"})
+	}
+	return errs
+}
+
+// parseBookQuery reads the list query parameters and reports every invalid one
+// instead of silently falling back to defaults
+func parseBookQuery(c *gin.Context) (dto.BookQuery, []FieldError) {
+	query := dto.BookQuery{
+		Search:    c.Query("search"),
+		Category:  c.Query("category"),
+		SortBy:    dto.SortByID,
+		SortOrder: dto.SortAsc,
+	}
+	var errs []FieldError
+	if msg := checkSearch(query.Search); msg != "" {
+		errs = append(errs, FieldError{Field: "search", Message: msg})
+	}
+
+	// The kids profile only lists books rated for all ages, in short pages
+	limitCap := maxLimit
+	if middleware.KidsProfile(c) {
+		query.ContentRating = model.RatingAllAges
+		middleware.ProfileFiltered(c)
+		query.Limit = kidsMaxLimit
+		limitCap = kidsMaxLimit
+	}
+
+	if raw, ok := c.GetQuery("include_fields"); ok {
+		for _, name := range strings.Split(raw, ",") {
+			field := dto.SearchField(strings.TrimSpace(name))
+			if !field.Valid() {
+				errs = append(errs, FieldError{Field: "include_fields", Message: "unsupported field " + strconv.Quote(string(field))})
+				continue
+			}
+			query.IncludeFields = append(query.IncludeFields, field)
+		}
+	}
+
+	if raw, ok := c.GetQuery("tags"); ok {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				errs = append(errs, FieldError{Field: "tags", Message: "must list tag names separated by commas"})
+				break
+			}
+			query.Tags = append(query.Tags, name)
+		}
+	}
+
+	if raw, ok := c.GetQuery("media_type"); ok {
+		if mediaType := model.MediaType(raw); !mediaType.Valid() {
+			errs = append(errs, FieldError{Field: "media_type", Message: "must be one of print, ebook, audiobook"})
+		} else {
+			query.MediaType = mediaType
+		}
+	}
+
+	if raw, ok := c.GetQuery("source"); ok {
+		if source := model.BookSource(raw); !source.Valid() {
+			errs = append(errs, FieldError{Field: "source", Message: "must be one of manual, import, upstream"})
+		} else {
+			query.Source = source
+		}
+	}
+
+	if raw, ok := c.GetQuery("import_batch_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil || id == 0 {
+			errs = append(errs, FieldError{Field: "import_batch_id", Message: "must be a positive integer"})
+		} else {
+			query.ImportBatchID = uint(id)
+		}
+	}
+
+	if raw, ok := c.GetQuery("accessibility"); ok {
+		for _, name := range strings.Split(raw, ",") {
+			feature := model.AccessibilityFeature(strings.TrimSpace(name))
+			if !feature.Valid() {
+				errs = append(errs, FieldError{Field: "accessibility", Message: "unsupported feature " + strconv.Quote(string(feature))})
+				continue
+			}
+			query.Accessibility = append(query.Accessibility, feature)
+		}
+	}
+
+	if raw, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(raw)
+		switch {
+		case middleware.Unbounded(c):
+			// limit=0 from a trusted caller leaves the list unbounded
+		case err != nil || limit < 1 || limit > limitCap:
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(limitCap)})
+		default:
+			query.Limit = limit
+		}
+	}
+
+	if raw, ok := c.GetQuery("offset"); ok {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			errs = append(errs, FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		} else {
+			query.Offset = offset
+		}
+	}
+
+	if raw, ok := c.GetQuery("sort_by"); ok {
+		if field := dto.BookSortField(raw); !field.Valid() {
+			errs = append(errs, FieldError{Field: "sort_by", Message: "must be one of " + joinSortFields()})
+		} else {
+			query.SortBy = field
+		}
+	} else if query.Search != "" {
+		query.SortBy = dto.SortByRelevance
+		query.SortOrder = dto.SortDesc
+	}
+
+	if raw, ok := c.GetQuery("sort_order"); ok {
+		if order := dto.SortOrder(raw); !order.Valid() {
+			errs = append(errs, FieldError{Field: "sort_order", Message: "must be asc or desc"})
+		} else {
+			query.SortOrder = order
+		}
+	}
+
+	if raw, ok := c.GetQuery("explain"); ok {
+		explain, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "explain", Message: "must be a boolean"})
+		} else {
+			query.Explain = explain
+		}
+	}
+
+	return query, errs
+}
+
+// checkSearch returns why search cannot be searched for, or "" when it can.
+// The columns only hold valid text, so a search with invalid UTF-8 or
+// control characters can match nothing and is refused rather than sent to
+// the database.
+func checkSearch(search string) string {
+	if !utf8.ValidString(search) || strings.IndexFunc(search, unicode.IsControl) >= 0 {
+		return "must be valid text without control characters"
+	}
+	if utf8.RuneCountInString(search) > maxSearchLength {
+		return "must be at most " + strconv.Itoa(maxSearchLength) + " characters"
+	}
+	return ""
+}
+
+func joinSortFields() string {
+	names := make([]string, len(dto.BookSortFields))
+	for i, f := range dto.BookSortFields {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bms-go/internal/model"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FuzzParseBookQuery checks that any list query either fails validation or
+// yields a query within bounds
+func FuzzParseBookQuery(f *testing.F) {
+	seeds := []struct{ search, limit, offset, sortBy, sortOrder string }{
+		{"dune", "10", "0", "title", "asc"},
+		{"", "", "", "", ""},
+		{"100%_\\", "-1", "-5", "title;DROP", "sideways"},
+		{"🐉 dragons", "999999999999999999999", "1e3", "relevance", "DESC"},
+		{"x\x00y", "0x10", " 1", "id", "desc"},
+		{"\xff\xfe", "abc", "", "", ""},
+		{strings.Repeat("é", 201), "100", "0", "published_year", "asc"},
+	}
+	for _, s := range seeds {
+		f.Add(s.search, s.limit, s.offset, s.sortBy, s.sortOrder)
+	}
+	gin.SetMode(gin.TestMode)
+
+	f.Fuzz(func(t *testing.T, search, limit, offset, sortBy, sortOrder string) {
+		values := url.Values{}
+		for name, value := range map[string]string{"search": search, "limit": limit, "offset": offset, "sort_by": sortBy, "sort_order": sortOrder} {
+			if value != "" {
+				values.Set(name, value)
+			}
+		}
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/books?"+values.Encode(), nil)
+
+		query, errs := parseBookQuery(c)
+		if len(errs) > 0 {
+			return
+		}
+		if !utf8.ValidString(query.Search) || strings.IndexFunc(query.Search, unicode.IsControl) >= 0 {
+			t.Fatalf("accepted search %q", query.Search)
+		}
+		if utf8.RuneCountInString(query.Search) > maxSearchLength {
+			t.Fatalf("accepted a search of %d characters", utf8.RuneCountInString(query.Search))
+		}
+		if query.Limit < 0 || query.Limit > maxLimit || (limit != "" && query.Limit == 0) {
+			t.Fatalf("limit %q gave %d", limit, query.Limit)
+		}
+		if query.Offset < 0 {
+			t.Fatalf("offset %q gave %d", offset, query.Offset)
+		}
+		if !query.SortBy.Valid() || !query.SortOrder.Valid() {
+			t.Fatalf("sort %q %q gave %q %q", sortBy, sortOrder, query.SortBy, query.SortOrder)
+		}
+	})
+}
+
+// FuzzValidateBook checks that validateBook only accepts books whose
+// enumerated fields hold known values
+func FuzzValidateBook(f *testing.F) {
+	f.Add("print", "all_ages", "", 0, 1)
+	f.Add("audiobook", "teen", "Scott Brick", 600, 0)
+	f.Add("", "", "", 0, 0)
+	f.Add("vinyl", "kids", "Narrator", -1, -1)
+	f.Add("ebook", "adult\x00", "\xff", 1<<31, 1<<20)
+
+	f.Fuzz(func(t *testing.T, mediaType, rating, narrator string, minutes, copies int) {
+		book := model.Book{
+			MediaType:       model.MediaType(mediaType),
+			ContentRating:   model.ContentRating(rating),
+			Narrator:        narrator,
+			DurationMinutes: minutes,
+			Copies:          copies,
+		}
+		if errs := validateBook(&book); len(errs) > 0 {
+			return
+		}
+		if !book.MediaType.Valid() || (book.ContentRating != "" && !book.ContentRating.Valid()) {
+			t.Fatalf("accepted media type %q and rating %q", book.MediaType, book.ContentRating)
+		}
+		if book.MediaType != model.MediaAudiobook && (book.Narrator != "" || book.DurationMinutes != 0) {
+			t.Fatalf("accepted narration for a %s book", book.MediaType)
+		}
+		if book.DurationMinutes < 0 || book.Copies < 0 || book.Copies > maxCopies {
+			t.Fatalf("accepted %d minutes and %d copies", book.DurationMinutes, book.Copies)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/testutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetBooksRejectsInvalidPaging(t *testing.T) {
+	// Invalid input is refused before the service is reached, so the
+	// handler needs no services
+	router := testutil.Router(nil, handler.NewBookHandler(nil, nil, nil, nil))
+
+	tests := []struct {
+		name   string
+		query  string
+		fields []string
+	}{
+		{"non-numeric limit", "limit=ten", []string{"limit"}},
+		{"zero limit", "limit=0", []string{"limit"}},
+		{"negative limit", "limit=-1", []string{"limit"}},
+		{"limit over the cap", "limit=101", []string{"limit"}},
+		{"non-numeric offset", "offset=first", []string{"offset"}},
+		{"negative offset", "offset=-5", []string{"offset"}},
+		{"unknown sort field", "sort_by=pages", []string{"sort_by"}},
+		{"unknown sort order", "sort_order=up", []string{"sort_order"}},
+		{"sort order in capitals", "sort_order=DESC", []string{"sort_order"}},
+		{"non-boolean envelope", "envelope=yes", []string{"envelope"}},
+		{"explain without envelope", "explain=true", []string{"explain"}},
+		{"explain with envelope off", "explain=true&envelope=false", []string{"explain"}},
+		{"every parameter invalid", "limit=x&offset=-1&sort_by=pages&sort_order=up", []string{"limit", "offset", "sort_by", "sort_order"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := testutil.Serve(router, testutil.NewRequest(t, http.MethodGet, "/books?"+tt.query, nil))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400; body %s", rec.Code, rec.Body.String())
+			}
+
+			var body handler.ValidationErrorResponse
+			testutil.DecodeJSON(t, rec, &body)
+			if body.Code != apperror.CodeValidationFailed {
+				t.Errorf("code = %q, want %q", body.Code, apperror.CodeValidationFailed)
+			}
+			var fields []string
+			for _, d := range body.Details {
+				fields = append(fields, d.Field)
+				if d.Message == "" {
+					t.Errorf("%s has no message", d.Field)
+				}
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("invalid fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestGetBooksValidationErrorBody(t *testing.T) {
+	router := testutil.Router(nil, handler.NewBookHandler(nil, nil, nil, nil))
+
+	rec := testutil.Serve(router, testutil.NewRequest(t, http.MethodGet, "/books?limit=500&offset=-1", nil))
+	testutil.AssertJSON(t, rec, http.StatusBadRequest, `{
+		"error": "invalid request parameters",
+		"code": "VALIDATION_FAILED",
+		"details": [
+			{"field": "limit", "message": "must be an integer between 1 and 100"},
+			{"field": "offset", "message": "must be a non-negative integer"}
+		]
+	}`)
+}
+
+func TestPatchBookRejectsInvalidID(t *testing.T) {
+	router := testutil.Router(nil, handler.NewBookHandler(nil, nil, nil, nil))
+
+	for _, id := range []string{"abc", "0", "-3"} {
+		t.Run(id, func(t *testing.T) {
+			rec := testutil.Serve(router, testutil.NewRequest(t, http.MethodPatch, "/books/"+id, map[string]string{"title": "Dune"}))
+			testutil.AssertJSON(t, rec, http.StatusBadRequest, `{
+				"error": "invalid request parameters",
+				"code": "VALIDATION_FAILED",
+				"details": [{"field": "id", "message": "must be a positive integer"}]
+			}`)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookLockHandler struct {
+	service *service.BookLockService
+}
+
+func NewBookLockHandler(s *service.BookLockService) *BookLockHandler {
+	return &BookLockHandler{service: s}
+}
+
+func (h *BookLockHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/books/:id/lock")
+	group.GET("", h.GetLock)
+	group.POST("", h.AcquireLock)
+	group.POST("/heartbeat", h.Heartbeat)
+	group.DELETE("", h.ReleaseLock)
+}
+
+// respondBookLocked reports who holds the lock on a book
+func respondBookLocked(c *gin.Context, lock interface{}) {
+	c.JSON(http.StatusConflict, gin.H{"error": service.ErrBookLocked.Error(), "code": apperror.CodeOf(service.ErrBookLocked), "lock": lock})
+}
+
+// GetLock godoc
+// @Summary Get edit lock
+// @Description Get who is currently editing the book, or 204 when nobody is
+// @Tags Books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} model.BookLock
+// @Success 204 "No Content"
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/lock [get]
+func (h *BookLockHandler) GetLock(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	lock, err := h.service.Current(uint(id))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if lock == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusOK, lock)
+}
+
+// AcquireLock godoc
+// @Summary Acquire edit lock
+// @Description Start editing a book. The lock expires unless renewed with heartbeats; while it is held, other users cannot update or delete the book.
+// @Tags Books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param lock body dto.BookLockRequest false "Name shown to other editors"
+// @Success 200 {object} model.BookLock
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/lock [post]
+func (h *BookLockHandler) AcquireLock(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req dto.BookLockRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	lock, err := h.service.Acquire(uint(id), userID, req.HolderName)
+	if errors.Is(err, service.ErrBookLocked) {
+		respondBookLocked(c, lock)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, lock)
+}
+
+// Heartbeat godoc
+// @Summary Renew edit lock
+// @Description Keep the caller's edit lock alive
+// @Tags Books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} model.BookLock
+// @Failure 401 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/lock/heartbeat [post]
+func (h *BookLockHandler) Heartbeat(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Param("id"))
+	lock, err := h.service.Heartbeat(uint(id), userID)
+	if errors.Is(err, service.ErrLockNotHeld) {
+		respondError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, lock)
+}
+
+// ReleaseLock godoc
+// @Summary Release edit lock
+// @Description Stop editing a book
+// @Tags Books
+// @Param id path int true "Book ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/lock [delete]
+func (h *BookLockHandler) ReleaseLock(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Release(uint(id), userID); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
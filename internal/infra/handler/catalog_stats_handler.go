@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogStatsHandler struct {
+	service *service.CatalogStatsService
+}
+
+func NewCatalogStatsHandler(s *service.CatalogStatsService) *CatalogStatsHandler {
+	return &CatalogStatsHandler{service: s}
+}
+
+func (h *CatalogStatsHandler) RegisterRoutes(routes Routes) {
+	routes.Private.GET("/metrics/catalog", h.GetCatalogMetrics)
+}
+
+// GetCatalogMetrics godoc
+// @Summary Catalog statistics metrics
+// @Description Business gauges (books, favorites, users, inter-library loan requests, RSVPs, pending change requests) in OpenMetrics text format, served from the last periodic collection. Admins only; scrapers send an admin access token as a bearer token.
+// @Tags Metrics
+// @Produce plain
+// @Success 200 {string} string
+// @Failure 401 {object} apperror.Body
+// @Failure 403 {object} apperror.Body
+// @Failure 503 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /metrics/catalog [get]
+func (h *CatalogStatsHandler) GetCatalogMetrics(c *gin.Context) {
+	var buf bytes.Buffer
+	if err := h.service.WriteOpenMetrics(&buf); err != nil {
+		if errors.Is(err, service.ErrStatsNotReady) {
+			respondError(c, http.StatusServiceUnavailable, err)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, service.OpenMetricsContentType, buf.Bytes())
+}
@@ -0,0 +1,32 @@
+package handler_test
+
+import (
+	"bms-go/config"
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/model"
+	"bms-go/internal/service"
+	"bms-go/internal/testutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCatalogMetricsAreAdminOnly(t *testing.T) {
+	auth := service.NewAuthService(nil, config.AuthConfig{JWTSecret: "test-secret", Issuer: "bms-go", TokenTTL: time.Hour})
+	users := testutil.Roles{1: model.RoleReader, 2: model.RoleLibrarian}
+	router := testutil.GuardedRouter(auth, users, testutil.ShippedRules(t), handler.NewCatalogStatsHandler(nil))
+
+	for user, want := range map[uint]int{
+		0: http.StatusUnauthorized,
+		1: http.StatusForbidden,
+		2: http.StatusForbidden,
+	} {
+		req := testutil.NewRequest(t, http.MethodGet, "/metrics/catalog", nil)
+		if user != 0 {
+			req = testutil.AuthenticatedRequest(t, auth, user, http.MethodGet, "/metrics/catalog", nil)
+		}
+		if rec := testutil.Serve(router, req); rec.Code != want {
+			t.Errorf("user %d: status = %d, want %d", user, rec.Code, want)
+		}
+	}
+}
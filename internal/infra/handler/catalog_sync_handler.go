@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bms-go/internal/infra/remote"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogSyncHandler struct {
+	local   *service.LocalCatalog
+	refresh *service.CatalogRefreshService
+	client  *httpclient.Client
+}
+
+func NewCatalogSyncHandler(local *service.LocalCatalog, refresh *service.CatalogRefreshService, client *httpclient.Client) *CatalogSyncHandler {
+	return &CatalogSyncHandler{local: local, refresh: refresh, client: client}
+}
+
+func (h *CatalogSyncHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/admin/catalog/sync", h.SyncCatalog)
+	routes.Private.POST("/admin/catalog/refresh", h.RefreshCatalog)
+}
+
+// SyncCatalog godoc
+// @Summary Sync catalog to another deployment
+// @Description Compare this catalog with the deployment at target_url by title and author and push missing or outdated books. With direction pull, books are copied from target_url into this catalog instead; books created that way have source upstream and are kept up to date by the catalog refresh. Defaults to a dry run; records on the receiving side changed more recently are reported as conflicts.
+// @Tags Catalog
+// @Accept json
+// @Produce json
+// @Param request body dto.CatalogSyncRequest true "Sync target"
+// @Success 200 {object} dto.CatalogSyncReport
+// @Failure 400 {object} apperror.Body
+// @Failure 502 {object} apperror.Body
+// @Router /admin/catalog/sync [post]
+func (h *CatalogSyncHandler) SyncCatalog(c *gin.Context) {
+	var req dto.CatalogSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	var source, target service.Catalog = h.local.WithContext(c.Request.Context()), remote.NewCatalog(req.TargetURL, req.TargetAPIKey, h.client).WithContext(c.Request.Context())
+	if req.Direction == dto.SyncPull {
+		source, target = target, source
+	}
+	report, err := service.SyncCatalogs(source, target, dryRun)
+	if err != nil {
+		respondError(c, http.StatusBadGateway, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// RefreshCatalog godoc
+// @Summary Refresh upstream books
+// @Description Fetch a batch of the books pulled from other deployments again, without waiting for the scheduled run. Fields changed upstream but not here are applied; fields changed on both sides are queued as a machine generated change request with source catalog_refresh.
+// @Tags Catalog
+// @Produce json
+// @Success 200 {object} dto.CatalogRefreshResult
+// @Failure 500 {object} apperror.Body
+// @Router /admin/catalog/refresh [post]
+func (h *CatalogSyncHandler) RefreshCatalog(c *gin.Context) {
+	result, err := h.refresh.Refresh(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
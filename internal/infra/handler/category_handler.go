@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCategoryBookLimit = 20
+	maxCategoryBookLimit     = 100
+)
+
+type CategoryHandler struct {
+	service *service.CategoryService
+}
+
+func NewCategoryHandler(s *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: s}
+}
+
+func (h *CategoryHandler) RegisterRoutes(routes Routes) {
+	public := routes.Public.Group("/categories")
+	public.GET("", h.GetCategories)
+	public.GET("/:id", h.GetCategory)
+	public.GET("/:id/books", h.GetCategoryBooks)
+
+	private := routes.Private.Group("/categories")
+	private.POST("", h.CreateCategory)
+	private.PUT("/:id", h.UpdateCategory)
+	private.DELETE("/:id", h.DeleteCategory)
+
+	admin := routes.Private.Group("/admin/categories")
+	admin.POST("/rename", h.RenameCategory)
+	admin.POST("/merge", h.MergeCategories)
+}
+
+// respondCategoryError reports errors about the category name against field
+func respondCategoryError(c *gin.Context, err error, field string) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrCategoryExists), errors.Is(err, service.ErrCategoryInUse):
+		respondError(c, http.StatusConflict, err)
+	case errors.Is(err, service.ErrSameCategory), errors.Is(err, service.ErrBlankCategory):
+		respondValidationError(c, []FieldError{{Field: field, Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetCategories godoc
+// @Summary List categories
+// @Description Every category by name with its number of books, for filters and dropdowns
+// @Tags Categories
+// @Produce json
+// @Success 200 {array} dto.CategoryResponse
+// @Failure 500 {object} apperror.Body
+// @Router /categories [get]
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	categories, err := h.service.GetCategories()
+	if err != nil {
+		respondCategoryError(c, err, "name")
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetCategory godoc
+// @Summary Get category
+// @Tags Categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} model.Category
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /categories/{id} [get]
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	category, err := h.service.GetCategory(paramID(c, "id"))
+	if err != nil {
+		respondCategoryError(c, err, "name")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// GetCategoryBooks godoc
+// @Summary List books in a category
+// @Description List the books filed under a category, by title
+// @Tags Categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param limit query int false "Maximum number of books to return (1-100)" default(20)
+// @Param offset query int false "Number of books to skip"
+// @Success 200 {object} dto.CategoryBookListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /categories/{id}/books [get]
+func (h *CategoryHandler) GetCategoryBooks(c *gin.Context) {
+	var errs []FieldError
+
+	// The kids profile only lists books rated for all ages, in short pages
+	var rating model.ContentRating
+	limit, limitCap := defaultCategoryBookLimit, maxCategoryBookLimit
+	if middleware.KidsProfile(c) {
+		rating = model.RatingAllAges
+		limitCap = kidsMaxLimit
+		middleware.ProfileFiltered(c)
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > limitCap {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(limitCap)})
+		}
+		limit = n
+	}
+	var offset int
+	if raw, ok := c.GetQuery("offset"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			errs = append(errs, FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		}
+		offset = n
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	books, err := h.service.GetBooks(c.Request.Context(), paramID(c, "id"), rating, limit, offset)
+	if err != nil {
+		respondCategoryError(c, err, "name")
+		return
+	}
+	c.JSON(http.StatusOK, books)
+}
+
+// CreateCategory godoc
+// @Summary Create category
+// @Description Add a category. Names are unique ignoring case.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param category body dto.CategoryRequest true "Category"
+// @Success 201 {object} model.Category
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req dto.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	category, err := h.service.CreateCategory(req)
+	if err != nil {
+		respondCategoryError(c, err, "name")
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory godoc
+// @Summary Rename category
+// @Description Rename a category. Its books are relabelled in the same transaction, each with a change log entry. Use /admin/categories/merge to fold it into another category.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param category body dto.CategoryRequest true "Category"
+// @Success 200 {object} model.Category
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req dto.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	category, err := h.service.UpdateCategory(paramID(c, "id"), req)
+	if err != nil {
+		respondCategoryError(c, err, "name")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete category
+// @Description Delete a category without books. Move its books elsewhere first.
+// @Tags Categories
+// @Param id path int true "Category ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	if err := h.service.DeleteCategory(paramID(c, "id")); err != nil {
+		respondCategoryError(c, err, "name")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RenameCategory godoc
+// @Summary Rename a category by name
+// @Description Rename the category called from. When another category is already called to, the books are merged into it. Each book's change is recorded in the change log and cached listings are dropped.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param rename body dto.CategoryRenameRequest true "Current and new name"
+// @Success 200 {object} dto.CategoryChangeResult
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/categories/rename [post]
+func (h *CategoryHandler) RenameCategory(c *gin.Context) {
+	var req dto.CategoryRenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	result, err := h.service.RenameCategory(req)
+	if err != nil {
+		respondCategoryError(c, err, "to")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// MergeCategories godoc
+// @Summary Merge categories
+// @Description Move every book of the source categories into the target category in one transaction and delete the sources. The target is created when it does not exist. Each book's change is recorded in the change log and cached listings are dropped.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param merge body dto.CategoryMergeRequest true "Source categories and target"
+// @Success 200 {object} dto.CategoryChangeResult
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/categories/merge [post]
+func (h *CategoryHandler) MergeCategories(c *gin.Context) {
+	var req dto.CategoryMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	result, err := h.service.MergeCategories(req)
+	if err != nil {
+		respondCategoryError(c, err, "target")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
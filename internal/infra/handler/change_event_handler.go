@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultChangeEventLimit = 100
+	maxChangeEventLimit     = 1000
+)
+
+type ChangeEventHandler struct {
+	service *service.ChangeEventService
+}
+
+func NewChangeEventHandler(s *service.ChangeEventService) *ChangeEventHandler {
+	return &ChangeEventHandler{service: s}
+}
+
+func (h *ChangeEventHandler) RegisterRoutes(routes Routes) {
+	routes.Private.GET("/admin/cdc", h.GetEvents)
+	routes.Private.GET("/admin/events", h.SearchEvents)
+}
+
+// GetEvents godoc
+// @Summary Tail the change log
+// @Description Change data capture events (entity, op, payload) for book and favorite writes, in sequence order. Pass the returned next_seq as after_seq to continue; an empty page means there is nothing new yet. Payloads carry user ids, so the log is for admins only.
+// @Tags CDC
+// @Produce json
+// @Param after_seq query int false "Return events after this sequence number" default(0)
+// @Param limit query int false "Maximum number of events to return (1-1000)" default(100)
+// @Success 200 {object} dto.ChangeEventPage
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/cdc [get]
+func (h *ChangeEventHandler) GetEvents(c *gin.Context) {
+	var errs []FieldError
+
+	var afterSeq uint64
+	if raw, ok := c.GetQuery("after_seq"); ok {
+		seq, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "after_seq", Message: "must be a non-negative integer"})
+		}
+		afterSeq = seq
+	}
+
+	limit := defaultChangeEventLimit
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxChangeEventLimit {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxChangeEventLimit)})
+		}
+		limit = n
+	}
+
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	page, err := h.service.GetEvents(afterSeq, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// SearchEvents godoc
+// @Summary Search the change log
+// @Description Find the recorded writes to an entity, of a kind, or within a time range, newest first, e.g. when a book was deleted and what it held. Events older than the archive age are served by /admin/archive/change-events instead. Pass the returned next_before_seq as before_seq to load older events.
+// @Tags CDC
+// @Produce json
+// @Param entity query string false "Entity" Enums(book, favorite)
+// @Param entity_id query int false "Entity ID"
+// @Param op query string false "Kind of write" Enums(create, update, delete)
+// @Param from query string false "Only events at or after this time (RFC 3339)"
+// @Param to query string false "Only events before this time (RFC 3339)"
+// @Param before_seq query int false "Return events older than this sequence number"
+// @Param limit query int false "Maximum number of events to return (1-1000)" default(100)
+// @Success 200 {object} dto.ChangeEventLog
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/events [get]
+func (h *ChangeEventHandler) SearchEvents(c *gin.Context) {
+	var query dto.ChangeEventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultChangeEventLimit
+	}
+
+	page, err := h.service.SearchEvents(query)
+	if errors.Is(err, service.ErrInvalidEventRange) {
+		respondValidationError(c, []FieldError{{Field: "to", Message: err.Error()}})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,44 @@
+package handler_test
+
+import (
+	"bms-go/config"
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/model"
+	"bms-go/internal/service"
+	"bms-go/internal/testutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestChangeLogIsAdminOnly(t *testing.T) {
+	auth := service.NewAuthService(nil, config.AuthConfig{JWTSecret: "test-secret", Issuer: "bms-go", TokenTTL: time.Hour})
+	users := testutil.Roles{1: model.RoleReader, 2: model.RoleLibrarian, 3: model.RoleAdmin}
+	router := testutil.GuardedRouter(auth, users, testutil.ShippedRules(t), handler.NewChangeEventHandler(nil))
+
+	tests := []struct {
+		name string
+		user uint
+		path string
+		want int
+	}{
+		{"anonymous tail", 0, "/admin/cdc", http.StatusUnauthorized},
+		{"reader tail", 1, "/admin/cdc", http.StatusForbidden},
+		{"librarian tail", 2, "/admin/cdc", http.StatusForbidden},
+		// Reaching validation shows the request got past the guard
+		{"admin tail", 3, "/admin/cdc?limit=0", http.StatusBadRequest},
+		{"anonymous search", 0, "/admin/events", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewRequest(t, http.MethodGet, tt.path, nil)
+			if tt.user != 0 {
+				req = testutil.AuthenticatedRequest(t, auth, tt.user, http.MethodGet, tt.path, nil)
+			}
+			rec := testutil.Serve(router, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ChangeRequestHandler struct {
+	service *service.ChangeRequestService
+}
+
+func NewChangeRequestHandler(s *service.ChangeRequestService) *ChangeRequestHandler {
+	return &ChangeRequestHandler{service: s}
+}
+
+func (h *ChangeRequestHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/books/:id/change-requests", h.SubmitChangeRequest)
+	routes.Private.GET("/me/change-requests", h.GetMyChangeRequests)
+
+	group := routes.Private.Group("/admin/change-requests")
+	group.GET("", h.GetChangeRequests)
+	group.GET("/:id", h.GetChangeRequest)
+	group.POST("/:id/approve", h.ApproveChangeRequest)
+	group.POST("/:id/reject", h.RejectChangeRequest)
+}
+
+func respondChangeRequestError(c *gin.Context, err error) {
+	if respondRuleViolations(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrNoChanges):
+		respondValidationError(c, []FieldError{{Field: "changes", Message: err.Error()}})
+	case errors.Is(err, service.ErrChangeRequestClosed):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// SubmitChangeRequest godoc
+// @Summary Propose a book edit
+// @Description Submit changes to a book for review. Only the fields present in changes are proposed; the book is unchanged until an admin approves.
+// @Tags Change Requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param request body dto.ChangeRequestSubmission true "Proposed changes"
+// @Success 201 {object} dto.ChangeRequestResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/change-requests [post]
+func (h *ChangeRequestHandler) SubmitChangeRequest(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req dto.ChangeRequestSubmission
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.service.Submit(c.Request.Context(), uint(id), userID, req)
+	if err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetMyChangeRequests godoc
+// @Summary List my change requests
+// @Description List the edits the user proposed and their review status
+// @Tags Me
+// @Produce json
+// @Success 200 {array} dto.ChangeRequestResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/change-requests [get]
+func (h *ChangeRequestHandler) GetMyChangeRequests(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	crs, err := h.service.GetChangeRequests(c.Request.Context(), "", userID, nil)
+	if err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, crs)
+}
+
+// GetChangeRequests godoc
+// @Summary List change requests
+// @Description List proposed book edits with their diff against the current book, newest first. Descriptions suggested by the enrichment pipeline are listed with machine_generated set.
+// @Tags Change Requests
+// @Produce json
+// @Param status query string false "Status filter" Enums(pending, approved, rejected)
+// @Param machine_generated query bool false "Only machine generated (true) or only contributed (false) requests"
+// @Success 200 {array} dto.ChangeRequestResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/change-requests [get]
+func (h *ChangeRequestHandler) GetChangeRequests(c *gin.Context) {
+	var errs []FieldError
+	status := model.ChangeRequestStatus(c.Query("status"))
+	if status != "" && !status.Valid() {
+		errs = append(errs, FieldError{Field: "status", Message: "must be one of pending, approved, rejected"})
+	}
+	var machineGenerated *bool
+	if raw, ok := c.GetQuery("machine_generated"); ok {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "machine_generated", Message: "must be a boolean"})
+		}
+		machineGenerated = &v
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	crs, err := h.service.GetChangeRequests(c.Request.Context(), status, 0, machineGenerated)
+	if err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, crs)
+}
+
+// GetChangeRequest godoc
+// @Summary Get change request
+// @Description Get a proposed edit with its diff against the current book
+// @Tags Change Requests
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Success 200 {object} dto.ChangeRequestResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/change-requests/{id} [get]
+func (h *ChangeRequestHandler) GetChangeRequest(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	cr, err := h.service.GetChangeRequest(c.Request.Context(), uint(id))
+	if err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cr)
+}
+
+// ApproveChangeRequest godoc
+// @Summary Approve change request
+// @Description Apply a pending edit to the book and record the reviewer
+// @Tags Change Requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Param review body dto.ChangeReview false "Review comment"
+// @Success 200 {object} dto.ChangeRequestResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/change-requests/{id}/approve [post]
+func (h *ChangeRequestHandler) ApproveChangeRequest(c *gin.Context) {
+	h.review(c, h.service.Approve)
+}
+
+// RejectChangeRequest godoc
+// @Summary Reject change request
+// @Description Close a pending edit without applying it
+// @Tags Change Requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Change request ID"
+// @Param review body dto.ChangeReview false "Review comment"
+// @Success 200 {object} dto.ChangeRequestResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/change-requests/{id}/reject [post]
+func (h *ChangeRequestHandler) RejectChangeRequest(c *gin.Context) {
+	h.review(c, h.service.Reject)
+}
+
+func (h *ChangeRequestHandler) review(c *gin.Context, decide func(ctx context.Context, id, reviewerID uint, review dto.ChangeReview) (*dto.ChangeRequestResponse, error)) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Param("id"))
+	var review dto.ChangeReview
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&review); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	cr, err := decide(c.Request.Context(), uint(id), userID, review)
+	if err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cr)
+}
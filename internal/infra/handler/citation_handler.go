@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CitationHandler struct {
+	service *service.CitationService
+}
+
+func NewCitationHandler(s *service.CitationService) *CitationHandler {
+	return &CitationHandler{service: s}
+}
+
+func (h *CitationHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/books/:id/citation", h.GetBookCitation)
+	routes.Private.GET("/favorites/citations", h.GetFavoriteCitations)
+}
+
+// GetBookCitation godoc
+// @Summary Get a book citation
+// @Description Render a book's citation in BibTeX, RIS, APA or MLA style
+// @Tags Citations
+// @Produce plain
+// @Param id path int true "Book ID"
+// @Param format query string false "Citation style (default apa)" Enums(bibtex, ris, apa, mla)
+// @Success 200 {string} string
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/citation [get]
+func (h *CitationHandler) GetBookCitation(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	format := service.CitationFormat(c.DefaultQuery("format", string(service.CitationAPA)))
+
+	citation, err := h.service.CiteBook(c.Request.Context(), uint(id), format)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "book not found")
+		return
+	}
+	h.respond(c, format, citation, err)
+}
+
+// GetFavoriteCitations godoc
+// @Summary Get citations for favorites
+// @Description Render the citations of every book in the user's favorites in one document
+// @Tags Citations
+// @Produce plain
+// @Param format query string false "Citation style (default apa)" Enums(bibtex, ris, apa, mla)
+// @Success 200 {string} string
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /favorites/citations [get]
+func (h *CitationHandler) GetFavoriteCitations(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	format := service.CitationFormat(c.DefaultQuery("format", string(service.CitationAPA)))
+
+	citations, err := h.service.CiteFavorites(c.Request.Context(), userID, format)
+	h.respond(c, format, citations, err)
+}
+
+func (h *CitationHandler) respond(c *gin.Context, format service.CitationFormat, body string, err error) {
+	if errors.Is(err, service.ErrUnsupportedCitationFormat) {
+		respondValidationError(c, []FieldError{{Field: "format", Message: "must be one of bibtex, ris, apa, mla"}})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, format.ContentType(), []byte(body))
+}
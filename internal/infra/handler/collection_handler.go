@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CollectionHandler struct {
+	service *service.CollectionService
+}
+
+func NewCollectionHandler(s *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: s}
+}
+
+func (h *CollectionHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/collections")
+	group.GET("", h.GetCollections)
+	group.POST("", h.CreateCollection)
+	group.GET("/:id", h.GetCollection)
+	group.PUT("/:id", h.UpdateCollection)
+	group.DELETE("/:id", h.DeleteCollection)
+	group.POST("/:id/books", h.AddBook)
+	group.DELETE("/:id/books/:bookId", h.RemoveBook)
+}
+
+func respondCollectionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCollectionNotFound), errors.Is(err, service.ErrCollectionBookNotFound), errors.Is(err, service.ErrNotInCollection):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrCollectionExists):
+		respondError(c, http.StatusConflict, err)
+	case errors.Is(err, service.ErrBlankCollectionName):
+		respondValidationError(c, []FieldError{{Field: "name", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetCollections godoc
+// @Summary List my collections
+// @Description List the signed-in user's reading lists by name with their number of books
+// @Tags Collections
+// @Produce json
+// @Success 200 {array} dto.CollectionResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /collections [get]
+func (h *CollectionHandler) GetCollections(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	collections, err := h.service.GetCollections(userID)
+	if err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, collections)
+}
+
+// CreateCollection godoc
+// @Summary Create a collection
+// @Description Start an empty reading list. Names are unique among the user's collections, ignoring case.
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Param collection body dto.CollectionRequest true "Collection"
+// @Success 201 {object} dto.CollectionResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /collections [post]
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var req dto.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	collection, err := h.service.CreateCollection(userID, req)
+	if err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, collection)
+}
+
+// GetCollection godoc
+// @Summary Get a collection
+// @Description Get one of the signed-in user's reading lists with its books in the order they were added
+// @Tags Collections
+// @Produce json
+// @Param id path int true "Collection ID"
+// @Success 200 {object} dto.CollectionDetailResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /collections/{id} [get]
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	collection, err := h.service.GetCollection(c.Request.Context(), userID, paramID(c, "id"))
+	if err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// UpdateCollection godoc
+// @Summary Update a collection
+// @Description Rename a reading list or change its description
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection ID"
+// @Param collection body dto.CollectionRequest true "Collection"
+// @Success 200 {object} dto.CollectionResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /collections/{id} [put]
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var req dto.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	collection, err := h.service.UpdateCollection(userID, paramID(c, "id"), req)
+	if err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection godoc
+// @Summary Delete a collection
+// @Description Delete a reading list. Its books stay in the catalog.
+// @Tags Collections
+// @Param id path int true "Collection ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /collections/{id} [delete]
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteCollection(userID, paramID(c, "id")); err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddBook godoc
+// @Summary Add a book to a collection
+// @Description Put a book on a reading list. Adding a book the list already has changes nothing.
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection ID"
+// @Param book body dto.CollectionBookRequest true "Book"
+// @Success 200 {object} dto.CollectionResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /collections/{id}/books [post]
+func (h *CollectionHandler) AddBook(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var req dto.CollectionBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	collection, err := h.service.AddBook(c.Request.Context(), userID, paramID(c, "id"), req.BookID)
+	if err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// RemoveBook godoc
+// @Summary Remove a book from a collection
+// @Description Take a book off a reading list
+// @Tags Collections
+// @Param id path int true "Collection ID"
+// @Param bookId path int true "Book ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /collections/{id}/books/{bookId} [delete]
+func (h *CollectionHandler) RemoveBook(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveBook(userID, paramID(c, "id"), paramID(c, "bookId")); err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CopyHandler struct {
+	service *service.CopyService
+}
+
+func NewCopyHandler(s *service.CopyService) *CopyHandler {
+	return &CopyHandler{service: s}
+}
+
+func (h *CopyHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/books/availability", h.GetAvailability)
+	routes.Private.GET("/copies/replacements", h.GetReplacements)
+
+	private := routes.Private.Group("/books/:id")
+	private.GET("/copies", h.GetCopies)
+	private.DELETE("/copies/:copyId", h.WithdrawCopy)
+	private.PUT("/copies/:copyId/condition", h.SetCondition)
+	private.GET("/copies/:copyId/damage-reports", h.GetDamageReports)
+	private.POST("/copies/:copyId/damage-reports", h.ReportDamage)
+	private.GET("/copies/:copyId/damage-reports/:reportId/photos/:photoId", h.GetDamagePhoto)
+	private.GET("/stock", h.GetStock)
+	private.PUT("/stock", h.SetStock)
+}
+
+func respondCopyError(c *gin.Context, err error) {
+	var tooLarge *service.PhotoTooLargeError
+	switch {
+	case errors.Is(err, service.ErrCopyBookNotFound), errors.Is(err, service.ErrCopyNotFound):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrCopyOnLoan), errors.Is(err, service.ErrStockOnLoan):
+		respondError(c, http.StatusConflict, err)
+	case errors.As(err, &tooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, err)
+	case errors.Is(err, service.ErrInvalidCondition):
+		respondValidationError(c, []FieldError{{Field: "condition", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidPhoto), errors.Is(err, service.ErrTooManyPhotos):
+		respondValidationError(c, []FieldError{{Field: "photos", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetCopies godoc
+// @Summary List copies of a book
+// @Description List the physical copies of a book in the order they were added, with their condition and status and the loan each one is out on
+// @Tags Inventory
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} dto.CopyResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/copies [get]
+func (h *CopyHandler) GetCopies(c *gin.Context) {
+	copies, err := h.service.GetCopies(paramID(c, "id"))
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, copies)
+}
+
+// WithdrawCopy godoc
+// @Summary Withdraw a copy
+// @Description Remove one copy of a book from stock, such as a lost or damaged one. Copies out on loan cannot be withdrawn.
+// @Tags Inventory
+// @Param id path int true "Book ID"
+// @Param copyId path int true "Copy ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/copies/{copyId} [delete]
+func (h *CopyHandler) WithdrawCopy(c *gin.Context) {
+	if err := h.service.WithdrawCopy(paramID(c, "id"), paramID(c, "copyId")); err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetCondition godoc
+// @Summary Set a copy's condition
+// @Description Record the condition a copy was assessed in. Damaged copies are taken out of circulation for repair and unusable ones are withdrawn; setting good, fair or poor puts a repaired copy back in circulation. Copies out on loan cannot be taken out of circulation.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copyId path int true "Copy ID"
+// @Param condition body dto.CopyConditionRequest true "Condition"
+// @Success 200 {object} model.Copy
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/copies/{copyId}/condition [put]
+func (h *CopyHandler) SetCondition(c *gin.Context) {
+	var req dto.CopyConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	updated, err := h.service.SetCondition(paramID(c, "id"), paramID(c, "copyId"), req.Condition)
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// GetDamageReports godoc
+// @Summary List damage reports of a copy
+// @Description List the damage reported on a copy, newest first, with the photos attached to each. Reports of withdrawn copies are kept.
+// @Tags Inventory
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copyId path int true "Copy ID"
+// @Success 200 {array} model.DamageReport
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/copies/{copyId}/damage-reports [get]
+func (h *CopyHandler) GetDamageReports(c *gin.Context) {
+	reports, err := h.service.GetDamageReports(paramID(c, "id"), paramID(c, "copyId"))
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// ReportDamage godoc
+// @Summary Report damage to a copy
+// @Description Record damage found on a copy and the condition it was assessed in, which the copy is put in: damaged copies are taken out of circulation for repair and unusable ones are withdrawn. Send a form with up to 5 JPEG, PNG or WebP photos as photos fields, or JSON without photos. Copies out on loan cannot be taken out of circulation.
+// @Tags Inventory
+// @Accept json,mpfd
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copyId path int true "Copy ID"
+// @Param report body dto.DamageReportRequest false "Damage report"
+// @Param condition formData string false "Condition" Enums(good, fair, poor, damaged, unusable)
+// @Param description formData string false "What is damaged"
+// @Param photos formData file false "Photos of the damage"
+// @Success 201 {object} dto.DamageReportResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 413 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/copies/{copyId}/damage-reports [post]
+func (h *CopyHandler) ReportDamage(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.DamageReportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var photos []dto.DamagePhotoUpload
+	if c.ContentType() == "multipart/form-data" {
+		form, err := c.MultipartForm()
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		headers := form.File["photos"]
+		if len(headers) > service.MaxDamagePhotos {
+			respondCopyError(c, service.ErrTooManyPhotos)
+			return
+		}
+		for _, header := range headers {
+			file, err := header.Open()
+			if err != nil {
+				respondValidationError(c, []FieldError{{Field: "photos", Message: err.Error()}})
+				return
+			}
+			// One byte over the limit is enough to reject the photo
+			content, err := io.ReadAll(io.LimitReader(file, h.service.PhotoMaxSize()+1))
+			file.Close()
+			if err != nil {
+				respondError(c, http.StatusBadRequest, err)
+				return
+			}
+			photos = append(photos, dto.DamagePhotoUpload{Filename: header.Filename, Content: content})
+		}
+	}
+
+	resp, err := h.service.ReportDamage(paramID(c, "id"), paramID(c, "copyId"), userID, req, photos)
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetDamagePhoto godoc
+// @Summary Get a damage photo
+// @Description Download a photo attached to a damage report
+// @Tags Inventory
+// @Produce image/jpeg,image/png,image/webp
+// @Param id path int true "Book ID"
+// @Param copyId path int true "Copy ID"
+// @Param reportId path int true "Damage report ID"
+// @Param photoId path int true "Photo ID"
+// @Success 200 {file} file
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/copies/{copyId}/damage-reports/{reportId}/photos/{photoId} [get]
+func (h *CopyHandler) GetDamagePhoto(c *gin.Context) {
+	photo, err := h.service.GetDamagePhoto(paramID(c, "id"), paramID(c, "copyId"), paramID(c, "reportId"), paramID(c, "photoId"))
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	if photo.Filename != "" {
+		c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": photo.Filename}))
+	}
+	c.Data(http.StatusOK, photo.ContentType, photo.Content)
+}
+
+// GetReplacements godoc
+// @Summary List copies needing replacement
+// @Description List the copies to replace, by book title: copies in poor condition, copies in for repair, and copies withdrawn as unusable, since the given day when set. Each comes with when damage was last reported on it and how many copies its book has left.
+// @Tags Inventory
+// @Produce json
+// @Param since query string false "Only copies withdrawn on or after this day (YYYY-MM-DD)"
+// @Success 200 {array} dto.ReplacementCandidate
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /copies/replacements [get]
+func (h *CopyHandler) GetReplacements(c *gin.Context) {
+	var since time.Time
+	if raw, ok := c.GetQuery("since"); ok {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			respondValidationError(c, []FieldError{{Field: "since", Message: "must be a date formatted as YYYY-MM-DD"}})
+			return
+		}
+		since = parsed
+	}
+	candidates, err := h.service.GetReplacements(since)
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, candidates)
+}
+
+// GetAvailability godoc
+// @Summary Check availability of books
+// @Description Get, for up to 100 books at once, how many copies are free to borrow, when the next copy out on loan is due back and how many reservations are waiting. Copies held for a reservation do not count as free. Unknown IDs are reported in missing_ids.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param books body dto.AvailabilityRequest true "Book IDs (1-100)"
+// @Success 200 {object} dto.AvailabilityResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/availability [post]
+func (h *CopyHandler) GetAvailability(c *gin.Context) {
+	var req dto.AvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	availability, err := h.service.GetAvailability(req.IDs)
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, availability)
+}
+
+// GetStock godoc
+// @Summary Get stock
+// @Description Count a book's copies, how many are available, how many are out on loan and how many are in for repair
+// @Tags Inventory
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} dto.StockResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/stock [get]
+func (h *CopyHandler) GetStock(c *gin.Context) {
+	stock, err := h.service.GetStock(paramID(c, "id"))
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stock)
+}
+
+// SetStock godoc
+// @Summary Set stock
+// @Description Set how many copies of a book the library holds. Copies are added, or withdrawn newest first; copies out on loan are never withdrawn.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param stock body dto.StockRequest true "Number of copies (0-1000)"
+// @Success 200 {object} dto.StockResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/stock [put]
+func (h *CopyHandler) SetStock(c *gin.Context) {
+	var req dto.StockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	stock, err := h.service.SetStock(paramID(c, "id"), req)
+	if err != nil {
+		respondCopyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stock)
+}
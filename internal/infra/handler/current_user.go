@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireUserID returns the signed-in user, or answers 401 and reports false
+// when the request carries no user
+func requireUserID(c *gin.Context) (uint, bool) {
+	id, ok := middleware.UserID(c)
+	if !ok {
+		respondMessage(c, http.StatusUnauthorized, "sign in required")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// embedCSP lets any site frame the widget while still allowing only its
+// inline stylesheet and the cover image
+const embedCSP = "default-src 'none'; style-src 'unsafe-inline'; img-src * data:; frame-ancestors *"
+
+type EmbedHandler struct {
+	service *service.EmbedService
+}
+
+func NewEmbedHandler(s *service.EmbedService) *EmbedHandler {
+	return &EmbedHandler{service: s}
+}
+
+func (h *EmbedHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/embed/books/:id", middleware.AllowFraming(embedCSP), h.GetBookEmbed)
+	routes.Public.GET("/oembed", h.GetOEmbed)
+}
+
+// GetBookEmbed godoc
+// @Summary Embeddable book widget
+// @Description Small HTML card with the book's cover, title, author and a link back to the catalog, meant for iframes
+// @Tags Embed
+// @Produce html
+// @Param id path int true "Book ID"
+// @Success 200 {string} string
+// @Success 304 "Not Modified"
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /embed/books/{id} [get]
+func (h *EmbedHandler) GetBookEmbed(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	body, updatedAt, err := h.service.RenderBook(c.Request.Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "book not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	etag := `"` + strconv.Itoa(id) + "-" + strconv.FormatInt(updatedAt.UnixNano(), 36) + `"`
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(service.EmbedCacheAge))
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", body)
+}
+
+// GetOEmbed godoc
+// @Summary oEmbed endpoint
+// @Description oEmbed provider endpoint describing how to embed a book URL
+// @Tags Embed
+// @Produce json
+// @Param url query string true "Canonical book URL"
+// @Param maxwidth query int false "Maximum embed width"
+// @Param maxheight query int false "Maximum embed height"
+// @Param format query string false "Response format, only json is supported"
+// @Success 200 {object} dto.OEmbedResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 501 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /oembed [get]
+func (h *EmbedHandler) GetOEmbed(c *gin.Context) {
+	if format := c.DefaultQuery("format", "json"); format != "json" {
+		respondMessage(c, http.StatusNotImplemented, "only json format is supported")
+		return
+	}
+
+	maxWidth, _ := strconv.Atoi(c.Query("maxwidth"))
+	maxHeight, _ := strconv.Atoi(c.Query("maxheight"))
+
+	resp, err := h.service.OEmbed(c.Request.Context(), c.Query("url"), maxWidth, maxHeight)
+	if errors.Is(err, service.ErrUnknownEmbedURL) || errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "book not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(service.EmbedCacheAge))
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EnrichmentHandler struct {
+	service *service.EnrichmentService
+}
+
+func NewEnrichmentHandler(s *service.EnrichmentService) *EnrichmentHandler {
+	return &EnrichmentHandler{service: s}
+}
+
+func (h *EnrichmentHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/admin/enrichment/run", h.RunEnrichment)
+}
+
+// RunEnrichment godoc
+// @Summary Run enrichment
+// @Description Ask the summarization API for descriptions and keywords of a batch of books that have no description, without waiting for the scheduled run. Suggestions are queued as machine generated change requests for review.
+// @Tags Change Requests
+// @Produce json
+// @Success 200 {object} dto.EnrichmentResult
+// @Failure 500 {object} apperror.Body
+// @Failure 503 {object} apperror.Body
+// @Router /admin/enrichment/run [post]
+func (h *EnrichmentHandler) RunEnrichment(c *gin.Context) {
+	queued, err := h.service.Enrich(c.Request.Context())
+	switch {
+	case errors.Is(err, service.ErrEnrichmentDisabled):
+		respondError(c, http.StatusServiceUnavailable, err)
+	case err != nil:
+		respondError(c, http.StatusInternalServerError, err)
+	default:
+		c.JSON(http.StatusOK, dto.EnrichmentResult{Queued: queued})
+	}
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EventHandler struct {
+	service *service.EventService
+}
+
+func NewEventHandler(s *service.EventService) *EventHandler {
+	return &EventHandler{service: s}
+}
+
+func (h *EventHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/events", h.GetEvents)
+	routes.Public.GET("/events/:id", h.GetEvent)
+
+	routes.Private.POST("/events/:id/rsvp", h.RSVP)
+	routes.Private.DELETE("/events/:id/rsvp", h.CancelRSVP)
+	routes.Private.GET("/me/events", h.GetMyEvents)
+
+	group := routes.Private.Group("/admin/events")
+	group.POST("", h.CreateEvent)
+	group.PUT("/:id", h.UpdateEvent)
+	group.DELETE("/:id", h.DeleteEvent)
+	group.GET("/:id/rsvps", h.GetRSVPs)
+}
+
+func respondEventError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrEventEndsBeforeStart):
+		respondValidationError(c, []FieldError{{Field: "ends_at", Message: err.Error()}})
+	case errors.Is(err, service.ErrEventBookNotFound):
+		respondValidationError(c, []FieldError{{Field: "book_id", Message: err.Error()}})
+	case errors.Is(err, service.ErrRoomBooked), errors.Is(err, service.ErrEventEnded):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetEvents godoc
+// @Summary List events
+// @Description List events that have not ended by from (default now), soonest first, with attendance
+// @Tags Events
+// @Produce json
+// @Param from query string false "RFC 3339 time; events ending after it are listed"
+// @Param book_id query int false "Only events about this book"
+// @Param room query string false "Only events in this room"
+// @Param limit query int false "Maximum events (default 50, max 200)"
+// @Success 200 {array} dto.EventResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /events [get]
+func (h *EventHandler) GetEvents(c *gin.Context) {
+	var query dto.EventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := h.service.GetEvents(query)
+	if err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// GetEvent godoc
+// @Summary Get event
+// @Description Get an event with its attendance
+// @Tags Events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} dto.EventResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /events/{id} [get]
+func (h *EventHandler) GetEvent(c *gin.Context) {
+	event, err := h.service.GetEvent(paramID(c, "id"))
+	if err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// RSVP godoc
+// @Summary RSVP to event
+// @Description Reserve a seat, or a waitlist place when the event is full. Repeating the request returns the existing RSVP.
+// @Tags Events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} model.EventRSVP
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /events/{id}/rsvp [post]
+func (h *EventHandler) RSVP(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	rsvp, err := h.service.RSVP(paramID(c, "id"), userID)
+	if err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rsvp)
+}
+
+// CancelRSVP godoc
+// @Summary Cancel RSVP
+// @Description Give up a seat or waitlist place. A freed seat goes to the first user on the waitlist.
+// @Tags Events
+// @Param id path int true "Event ID"
+// @Success 204
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /events/{id}/rsvp [delete]
+func (h *EventHandler) CancelRSVP(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelRSVP(paramID(c, "id"), userID); err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetMyEvents godoc
+// @Summary List my events
+// @Description List the events the user has an RSVP for and whether they have a seat
+// @Tags Me
+// @Produce json
+// @Success 200 {array} dto.MyEventResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/events [get]
+func (h *EventHandler) GetMyEvents(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.service.GetMyEvents(userID)
+	if err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// CreateEvent godoc
+// @Summary Create event
+// @Description Schedule an event in a room. A capacity of 0 means no limit. Overlapping events in the same room are rejected.
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param event body dto.EventRequest true "Event"
+// @Success 201 {object} dto.EventResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/events [post]
+func (h *EventHandler) CreateEvent(c *gin.Context) {
+	var req dto.EventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	event, err := h.service.CreateEvent(c.Request.Context(), req)
+	if err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, event)
+}
+
+// UpdateEvent godoc
+// @Summary Update event
+// @Description Replace an event's details. Raising the capacity gives seats to waitlisted users.
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param event body dto.EventRequest true "Event"
+// @Success 200 {object} dto.EventResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/events/{id} [put]
+func (h *EventHandler) UpdateEvent(c *gin.Context) {
+	var req dto.EventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	event, err := h.service.UpdateEvent(c.Request.Context(), paramID(c, "id"), req)
+	if err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// DeleteEvent godoc
+// @Summary Delete event
+// @Description Cancel an event along with its RSVPs
+// @Tags Events
+// @Param id path int true "Event ID"
+// @Success 204
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/events/{id} [delete]
+func (h *EventHandler) DeleteEvent(c *gin.Context) {
+	if err := h.service.DeleteEvent(paramID(c, "id")); err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetRSVPs godoc
+// @Summary List event attendees
+// @Description List an event's RSVPs, confirmed first, each in sign-up order
+// @Tags Events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {array} model.EventRSVP
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/events/{id}/rsvps [get]
+func (h *EventHandler) GetRSVPs(c *gin.Context) {
+	rsvps, err := h.service.GetRSVPs(paramID(c, "id"))
+	if err != nil {
+		respondEventError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rsvps)
+}
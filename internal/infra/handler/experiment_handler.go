@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ExperimentHandler struct {
+	service *service.ExperimentService
+}
+
+func NewExperimentHandler(s *service.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{service: s}
+}
+
+func (h *ExperimentHandler) RegisterRoutes(routes Routes) {
+	routes.Private.GET("/me/experiments", h.GetMyVariants)
+
+	group := routes.Private.Group("/admin/experiments")
+	group.GET("", h.GetExperiments)
+	group.POST("", h.CreateExperiment)
+	group.GET("/:id", h.GetExperiment)
+	group.PUT("/:id", h.UpdateExperiment)
+	group.DELETE("/:id", h.DeleteExperiment)
+}
+
+func respondExperimentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrInvalidExperiment):
+		respondValidationError(c, []FieldError{{Field: "variants", Message: err.Error()}})
+	case errors.Is(err, service.ErrExperimentKeyTaken):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetMyVariants godoc
+// @Summary List my experiment variants
+// @Description List the variant of each running experiment the caller is bucketed into. The same variants are named in the X-Experiments header of every response.
+// @Tags Me
+// @Produce json
+// @Success 200 {array} dto.ExperimentAssignment
+// @Router /me/experiments [get]
+func (h *ExperimentHandler) GetMyVariants(c *gin.Context) {
+	assignments := []dto.ExperimentAssignment{}
+	for _, key := range []string{model.ExperimentSearchRanking, model.ExperimentRecommendations} {
+		if a, ok := middleware.ExperimentVariant(c, key); ok {
+			assignments = append(assignments, a)
+		}
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
+// GetExperiments godoc
+// @Summary List experiments
+// @Description List every A/B experiment, running or not
+// @Tags Experiments
+// @Produce json
+// @Success 200 {array} model.Experiment
+// @Failure 500 {object} apperror.Body
+// @Router /admin/experiments [get]
+func (h *ExperimentHandler) GetExperiments(c *gin.Context) {
+	experiments, err := h.service.GetExperiments()
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, experiments)
+}
+
+// CreateExperiment godoc
+// @Summary Create experiment
+// @Description Set up an A/B experiment. Callers are split between the variants by weight. The application varies search_ranking (params override search weights by name) and recommendations (a strategy param picks the "more like this" strategy); other keys only tag responses.
+// @Tags Experiments
+// @Accept json
+// @Produce json
+// @Param experiment body dto.ExperimentRequest true "Experiment"
+// @Success 201 {object} model.Experiment
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/experiments [post]
+func (h *ExperimentHandler) CreateExperiment(c *gin.Context) {
+	var req dto.ExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	experiment, err := h.service.CreateExperiment(req)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, experiment)
+}
+
+// GetExperiment godoc
+// @Summary Get experiment
+// @Description Get an A/B experiment
+// @Tags Experiments
+// @Produce json
+// @Param id path int true "Experiment ID"
+// @Success 200 {object} model.Experiment
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/experiments/{id} [get]
+func (h *ExperimentHandler) GetExperiment(c *gin.Context) {
+	experiment, err := h.service.GetExperiment(paramID(c, "id"))
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, experiment)
+}
+
+// UpdateExperiment godoc
+// @Summary Update experiment
+// @Description Replace an A/B experiment. Changing the variants or their weights moves some callers to another variant; disabling it stops bucketing.
+// @Tags Experiments
+// @Accept json
+// @Produce json
+// @Param id path int true "Experiment ID"
+// @Param experiment body dto.ExperimentRequest true "Experiment"
+// @Success 200 {object} model.Experiment
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/experiments/{id} [put]
+func (h *ExperimentHandler) UpdateExperiment(c *gin.Context) {
+	var req dto.ExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	experiment, err := h.service.UpdateExperiment(paramID(c, "id"), req)
+	if err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, experiment)
+}
+
+// DeleteExperiment godoc
+// @Summary Delete experiment
+// @Description Delete an A/B experiment
+// @Tags Experiments
+// @Param id path int true "Experiment ID"
+// @Success 204
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/experiments/{id} [delete]
+func (h *ExperimentHandler) DeleteExperiment(c *gin.Context) {
+	if err := h.service.DeleteExperiment(paramID(c, "id")); err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var exportContentTypes = map[dto.ExportFormat]string{
+	dto.ExportPDF:  "application/pdf",
+	dto.ExportXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	dto.ExportCSV:  "text/csv; charset=utf-8",
+	dto.ExportJSON: "application/json; charset=utf-8",
+}
+
+type ExportHandler struct {
+	service *service.ExportService
+}
+
+func NewExportHandler(s *service.ExportService) *ExportHandler {
+	return &ExportHandler{service: s}
+}
+
+func (h *ExportHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/books/export", h.ExportBooks)
+}
+
+// ExportBooks godoc
+// @Summary Export books
+// @Description Download the books matching the same filters as the list endpoint as a printable, paginated PDF, an Excel workbook, CSV or a JSON array. Without a limit every matching book is exported. CSV and JSON are streamed while the catalog is read, so they suit backups of large catalogs; CSV and Excel files use the import column names and can be imported again.
+// @Tags Books
+// @Produce application/pdf,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv,application/json
+// @Param format query string true "Export format" Enums(pdf, xlsx, csv, json)
+// @Param title query string false "Heading printed on the first page (pdf only)"
+// @Param covers query bool false "Include cover thumbnails (pdf only)"
+// @Param search query string false "Search keyword" maxlength(200)
+// @Param category query string false "Category filter"
+// @Param tags query string false "Comma-separated tag names; only books with every tag are listed"
+// @Param media_type query string false "Media type filter" Enums(print, ebook, audiobook)
+// @Param accessibility query string false "Comma-separated accessibility features every book must have" Enums(large_print, braille, audiobook, dyslexic_font)
+// @Param source query string false "Only books added this way" Enums(manual, import, upstream)
+// @Param import_batch_id query int false "Only books added by this import"
+// @Param limit query int false "Maximum number of books to export (1-100)"
+// @Param offset query int false "Number of books to skip"
+// @Param sort_by query string false "Sort field" Enums(id, title, author, category, created_at, relevance, popularity)
+// @Param sort_order query string false "Sort direction" Enums(asc, desc)
+// @Success 200 {file} file
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /books/export [get]
+func (h *ExportHandler) ExportBooks(c *gin.Context) {
+	query, errs := parseBookQuery(c)
+
+	format := dto.ExportFormat(c.Query("format"))
+	if !format.Valid() {
+		errs = append(errs, FieldError{Field: "format", Message: "must be one of " + joinExportFormats()})
+	}
+
+	opts := dto.ExportOptions{Title: c.Query("title")}
+	if raw, ok := c.GetQuery("covers"); ok {
+		covers, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "covers", Message: "must be a boolean"})
+		}
+		opts.Covers = covers
+	}
+
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	filename := "books-" + time.Now().Format("20060102") + "." + string(format)
+	if format.Streamed() {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Header("Content-Type", exportContentTypes[format])
+		c.Status(http.StatusOK)
+		// The status is already sent, so a failure part way can only be
+		// logged; the client is left with a truncated file
+		err := h.service.WriteBooks(c.Request.Context(), query, format, c.Writer, c.Writer.Flush)
+		if err != nil && c.Request.Context().Err() == nil {
+			log.Printf("Failed to export books as %s: %v", format, err)
+		}
+		return
+	}
+
+	body, err := h.service.ExportBooks(c.Request.Context(), query, format, opts)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, exportContentTypes[format], body)
+}
+
+func joinExportFormats() string {
+	names := make([]string, len(dto.ExportFormats))
+	for i, f := range dto.ExportFormats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
@@ -3,7 +3,9 @@ package handler
 import (
 	"bms-go/internal/model/dto"
 	"bms-go/internal/service"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,25 +18,62 @@ func NewFavoriteHandler(s *service.FavoriteService) *FavoriteHandler {
 	return &FavoriteHandler{service: s}
 }
 
-func (h *FavoriteHandler) RegisterRoutes(r *gin.Engine) {
-	group := r.Group("/favorites")
+func (h *FavoriteHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/favorites")
 	group.GET("", h.GetFavorites)
 	group.POST("", h.AddFavorite)
+
+	routes.Public.GET("/users/:id/favorites", h.GetUserFavorites)
+	routes.Private.POST("/admin/favorites/reconcile", h.ReconcileCounts)
 }
 
 // GetFavorites godoc
 // @Summary Get all favorites
-// @Description Get list of user's favorite books
+// @Description Get list of the signed-in user's favorite books
 // @Tags Favorites
 // @Produce json
 // @Success 200 {array} dto.FavoriteResponse
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
 // @Router /favorites [get]
 func (h *FavoriteHandler) GetFavorites(c *gin.Context) {
-	userID := uint(1)
-	favs, err := h.service.GetFavorites(userID)
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	favs, err := h.service.GetFavorites(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, favs)
+}
+
+// GetUserFavorites godoc
+// @Summary Get a user's favorites
+// @Description Get another user's favorite books. Only available when the user has a public profile with favorites shown.
+// @Tags Favorites
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} dto.FavoriteResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /users/{id}/favorites [get]
+func (h *FavoriteHandler) GetUserFavorites(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		respondValidationError(c, []FieldError{{Field: "id", Message: "must be a positive integer"}})
+		return
+	}
+
+	favs, err := h.service.GetPublicFavorites(c.Request.Context(), uint(id))
+	if errors.Is(err, service.ErrFavoritesPrivate) {
+		// Private favorites are indistinguishable from an unknown user
+		respondMessage(c, http.StatusNotFound, "user not found")
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.JSON(http.StatusOK, favs)
@@ -42,28 +81,49 @@ func (h *FavoriteHandler) GetFavorites(c *gin.Context) {
 
 // AddFavorite godoc
 // @Summary Add a favorite
-// @Description Add a book to user's favorites
+// @Description Add a book to the signed-in user's favorites
 // @Tags Favorites
 // @Accept json
 // @Produce json
 // @Param favorite body dto.FavoriteRequest true "Favorite request"
 // @Success 201 {object} dto.FavoriteResponse
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
 // @Router /favorites [post]
 func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	var req dto.FavoriteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	userID := uint(1)
-	resp, err := h.service.AddFavorite(userID, req)
+	resp, err := h.service.AddFavorite(c.Request.Context(), userID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, resp)
 }
+
+// ReconcileCounts godoc
+// @Summary Reconcile favorite counts
+// @Description Recount each book's favorites and fix stored counts that have drifted, without waiting for the scheduled run
+// @Tags Favorites
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Failure 500 {object} apperror.Body
+// @Router /admin/favorites/reconcile [post]
+func (h *FavoriteHandler) ReconcileCounts(c *gin.Context) {
+	fixed, err := h.service.ReconcileCounts(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"fixed": fixed})
+}
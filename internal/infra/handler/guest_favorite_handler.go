@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GuestFavoriteHandler struct {
+	service *service.GuestFavoriteService
+	secret  string
+	cookie  string
+	ttl     time.Duration
+}
+
+// NewGuestFavoriteHandler serves favorites for visitors who have not signed
+// in. secret signs the guest session cookie; see middleware.GuestSession.
+func NewGuestFavoriteHandler(s *service.GuestFavoriteService, secret, cookie string, ttl time.Duration) *GuestFavoriteHandler {
+	return &GuestFavoriteHandler{service: s, secret: secret, cookie: cookie, ttl: ttl}
+}
+
+func (h *GuestFavoriteHandler) RegisterRoutes(routes Routes) {
+	group := routes.Public.Group("/guest/favorites", middleware.GuestSession(h.secret, h.cookie, h.ttl, true))
+	group.GET("", h.GetFavorites)
+	group.POST("", h.AddFavorite)
+	group.DELETE("/:book_id", h.RemoveFavorite)
+
+	routes.Private.POST("/me/favorites/claim", middleware.GuestSession(h.secret, h.cookie, h.ttl, false), h.ClaimFavorites)
+}
+
+func respondGuestFavoriteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "book not found")
+	case errors.Is(err, service.ErrGuestFavoritesFull):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetFavorites godoc
+// @Summary Get guest favorites
+// @Description Get the favorites of a visitor who has not signed in, identified by the guest session cookie or the X-Guest-Session header
+// @Tags Favorites
+// @Produce json
+// @Param X-Guest-Session header string false "Client-generated session ID, 16 to 64 letters, digits, - or _"
+// @Success 200 {array} dto.GuestFavoriteResponse
+// @Failure 500 {object} apperror.Body
+// @Router /guest/favorites [get]
+func (h *GuestFavoriteHandler) GetFavorites(c *gin.Context) {
+	sessionID, ok := middleware.GuestSessionID(c)
+	if !ok {
+		c.JSON(http.StatusOK, []dto.GuestFavoriteResponse{})
+		return
+	}
+	favs, err := h.service.GetFavorites(c.Request.Context(), sessionID)
+	if err != nil {
+		respondGuestFavoriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, favs)
+}
+
+// AddFavorite godoc
+// @Summary Add a guest favorite
+// @Description Add a book to the favorites of a visitor who has not signed in. A signed guest session cookie is issued when the request has no session; clients that cannot keep cookies send their own ID in X-Guest-Session. Guest favorites expire when the session is idle and can be claimed into an account with POST /me/favorites/claim.
+// @Tags Favorites
+// @Accept json
+// @Produce json
+// @Param X-Guest-Session header string false "Client-generated session ID, 16 to 64 letters, digits, - or _"
+// @Param favorite body dto.FavoriteRequest true "Favorite request"
+// @Success 201 {object} dto.GuestFavoriteResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /guest/favorites [post]
+func (h *GuestFavoriteHandler) AddFavorite(c *gin.Context) {
+	sessionID, ok := middleware.GuestSessionID(c)
+	if !ok {
+		respondMessage(c, http.StatusBadRequest, "guest session required: send "+middleware.GuestSessionHeader)
+		return
+	}
+	var req dto.FavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	fav, err := h.service.AddFavorite(c.Request.Context(), sessionID, req)
+	if err != nil {
+		respondGuestFavoriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, fav)
+}
+
+// RemoveFavorite godoc
+// @Summary Remove a guest favorite
+// @Description Remove a book from the favorites of a visitor who has not signed in
+// @Tags Favorites
+// @Param X-Guest-Session header string false "Client-generated session ID, 16 to 64 letters, digits, - or _"
+// @Param book_id path int true "Book ID"
+// @Success 204
+// @Failure 500 {object} apperror.Body
+// @Router /guest/favorites/{book_id} [delete]
+func (h *GuestFavoriteHandler) RemoveFavorite(c *gin.Context) {
+	sessionID, ok := middleware.GuestSessionID(c)
+	if !ok {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	if err := h.service.RemoveFavorite(sessionID, paramID(c, "book_id")); err != nil {
+		respondGuestFavoriteError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ClaimFavorites godoc
+// @Summary Claim guest favorites
+// @Description Move the favorites collected before signing in to the account and end the guest session. Books already among the account's favorites are skipped, and a request without a guest session claims nothing.
+// @Tags Favorites
+// @Produce json
+// @Param X-Guest-Session header string false "Client-generated session ID, 16 to 64 letters, digits, - or _"
+// @Success 200 {object} dto.ClaimFavoritesResponse
+// @Failure 500 {object} apperror.Body
+// @Router /me/favorites/claim [post]
+func (h *GuestFavoriteHandler) ClaimFavorites(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	sessionID, ok := middleware.GuestSessionID(c)
+	if !ok {
+		c.JSON(http.StatusOK, dto.ClaimFavoritesResponse{})
+		return
+	}
+	resp, err := h.service.Claim(c.Request.Context(), sessionID, userID)
+	if err != nil {
+		respondGuestFavoriteError(c, err)
+		return
+	}
+	middleware.ClearGuestSession(c, h.cookie)
+	c.JSON(http.StatusOK, resp)
+}
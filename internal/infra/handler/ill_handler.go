@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ILLHandler struct {
+	service *service.ILLService
+}
+
+func NewILLHandler(s *service.ILLService) *ILLHandler {
+	return &ILLHandler{service: s}
+}
+
+func (h *ILLHandler) RegisterRoutes(routes Routes) {
+	mine := routes.Private.Group("/me/ill-requests")
+	mine.GET("", h.GetMyRequests)
+	mine.POST("", h.SubmitRequest)
+	mine.GET("/:id", h.GetMyRequest)
+	mine.POST("/:id/cancel", h.CancelRequest)
+
+	group := routes.Private.Group("/admin/ill-requests")
+	group.GET("", h.GetRequests)
+	group.GET("/:id", h.GetRequest)
+	group.POST("/:id/status", h.UpdateStatus)
+}
+
+func respondILLError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrInvalidILLStatus):
+		respondValidationError(c, []FieldError{{Field: "status", Message: err.Error()}})
+	case errors.Is(err, service.ErrILLStatusTransition):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetMyRequests godoc
+// @Summary List my inter-library loan requests
+// @Description List the user's inter-library loan requests with their status timelines, newest first
+// @Tags Me
+// @Produce json
+// @Success 200 {array} model.ILLRequest
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/ill-requests [get]
+func (h *ILLHandler) GetMyRequests(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	reqs, err := h.service.GetRequests("", userID)
+	if err != nil {
+		respondILLError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reqs)
+}
+
+// SubmitRequest godoc
+// @Summary Request an inter-library loan
+// @Description Ask the library to borrow a book it does not hold from another library
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param request body dto.ILLRequestSubmission true "Requested book"
+// @Success 201 {object} model.ILLRequest
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/ill-requests [post]
+func (h *ILLHandler) SubmitRequest(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var sub dto.ILLRequestSubmission
+	if err := c.ShouldBindJSON(&sub); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	req, err := h.service.Submit(userID, sub)
+	if err != nil {
+		respondILLError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// GetMyRequest godoc
+// @Summary Track an inter-library loan request
+// @Description Get one of the user's requests with its status timeline
+// @Tags Me
+// @Produce json
+// @Param id path int true "Request ID"
+// @Success 200 {object} model.ILLRequest
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/ill-requests/{id} [get]
+func (h *ILLHandler) GetMyRequest(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	req, err := h.service.GetRequest(paramID(c, "id"), userID)
+	if err != nil {
+		respondILLError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// CancelRequest godoc
+// @Summary Cancel an inter-library loan request
+// @Description Withdraw one of the user's requests. Only possible until the book has arrived.
+// @Tags Me
+// @Produce json
+// @Param id path int true "Request ID"
+// @Success 200 {object} model.ILLRequest
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/ill-requests/{id}/cancel [post]
+func (h *ILLHandler) CancelRequest(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	req, err := h.service.Cancel(paramID(c, "id"), userID)
+	if err != nil {
+		respondILLError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// GetRequests godoc
+// @Summary List inter-library loan requests
+// @Description List all patrons' requests with their status timelines, newest first
+// @Tags Inter-Library Loans
+// @Produce json
+// @Param status query string false "Status filter" Enums(requested, ordered, received, returned, cancelled)
+// @Success 200 {array} model.ILLRequest
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/ill-requests [get]
+func (h *ILLHandler) GetRequests(c *gin.Context) {
+	reqs, err := h.service.GetRequests(model.ILLStatus(c.Query("status")), 0)
+	if err != nil {
+		respondILLError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reqs)
+}
+
+// GetRequest godoc
+// @Summary Get inter-library loan request
+// @Description Get any patron's request with its status timeline
+// @Tags Inter-Library Loans
+// @Produce json
+// @Param id path int true "Request ID"
+// @Success 200 {object} model.ILLRequest
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/ill-requests/{id} [get]
+func (h *ILLHandler) GetRequest(c *gin.Context) {
+	req, err := h.service.GetRequest(paramID(c, "id"), 0)
+	if err != nil {
+		respondILLError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// UpdateStatus godoc
+// @Summary Update inter-library loan status
+// @Description Record the next step of a request: requested, then ordered, then received, then returned. A request can be cancelled until it is received. The patron is notified.
+// @Tags Inter-Library Loans
+// @Accept json
+// @Produce json
+// @Param id path int true "Request ID"
+// @Param update body dto.ILLStatusUpdate true "New status"
+// @Success 200 {object} model.ILLRequest
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/ill-requests/{id}/status [post]
+func (h *ILLHandler) UpdateStatus(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var update dto.ILLStatusUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	req, err := h.service.UpdateStatus(paramID(c, "id"), userID, update)
+	if err != nil {
+		respondILLError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ImpersonationHandler struct {
+	service *service.ImpersonationService
+}
+
+func NewImpersonationHandler(s *service.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{service: s}
+}
+
+func (h *ImpersonationHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/admin/impersonate/:userID", h.StartImpersonation)
+
+	group := routes.Private.Group("/admin/impersonations")
+	group.GET("", h.GetImpersonations)
+	group.GET("/:id/actions", h.GetImpersonationActions)
+	group.DELETE("/:id", h.RevokeImpersonation)
+}
+
+// StartImpersonation godoc
+// @Summary Impersonate a user
+// @Description Issue a short-lived token that acts as the user when sent in X-Impersonation-Token. Only admins may impersonate. Every request made with it is audited.
+// @Tags Impersonation
+// @Accept json
+// @Produce json
+// @Param userID path int true "User ID"
+// @Param request body dto.ImpersonationRequest true "Why the user is being impersonated"
+// @Success 201 {object} dto.ImpersonationResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 403 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/impersonate/{userID} [post]
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	if _, impersonating := middleware.ImpersonationID(c); impersonating {
+		respondMessage(c, http.StatusForbidden, "cannot impersonate while impersonating")
+		return
+	}
+
+	adminID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 0)
+	if err != nil || userID == 0 {
+		respondValidationError(c, []FieldError{{Field: "userID", Message: "must be a positive integer"}})
+		return
+	}
+
+	var req dto.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.service.Start(adminID, uint(userID), req)
+	if errors.Is(err, service.ErrImpersonationForbidden) {
+		respondError(c, http.StatusForbidden, err)
+		return
+	}
+	if errors.Is(err, service.ErrImpersonationTarget) {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetImpersonations godoc
+// @Summary List impersonation sessions
+// @Description List impersonation sessions, newest first
+// @Tags Impersonation
+// @Produce json
+// @Success 200 {array} dto.ImpersonationResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/impersonations [get]
+func (h *ImpersonationHandler) GetImpersonations(c *gin.Context) {
+	sessions, err := h.service.GetSessions()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// GetImpersonationActions godoc
+// @Summary Impersonation audit log
+// @Description List every request made under an impersonation session
+// @Tags Impersonation
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {array} model.ImpersonationAction
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/impersonations/{id}/actions [get]
+func (h *ImpersonationHandler) GetImpersonationActions(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	actions, err := h.service.GetActions(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "impersonation session not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, actions)
+}
+
+// RevokeImpersonation godoc
+// @Summary Revoke impersonation
+// @Description End an impersonation session immediately
+// @Tags Impersonation
+// @Param id path int true "Session ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/impersonations/{id} [delete]
+func (h *ImpersonationHandler) RevokeImpersonation(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	err := h.service.Revoke(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "impersonation session not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
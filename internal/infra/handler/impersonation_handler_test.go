@@ -0,0 +1,137 @@
+package handler_test
+
+import (
+	"bms-go/config"
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"bms-go/internal/testutil"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// memoryImpersonations keeps impersonation sessions in memory
+type memoryImpersonations struct {
+	service.ImpersonationRepository
+	sessions []model.ImpersonationSession
+	actions  []model.ImpersonationAction
+}
+
+func (m *memoryImpersonations) Create(session *model.ImpersonationSession) error {
+	session.ID = uint(len(m.sessions) + 1)
+	m.sessions = append(m.sessions, *session)
+	return nil
+}
+
+func (m *memoryImpersonations) FindByTokenHash(hash string) (*model.ImpersonationSession, error) {
+	for _, session := range m.sessions {
+		if session.TokenHash == hash {
+			return &session, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memoryImpersonations) CreateAction(action *model.ImpersonationAction) error {
+	m.actions = append(m.actions, *action)
+	return nil
+}
+
+// accounts are the users with an account row, by role
+type accounts testutil.Roles
+
+func (a accounts) GetRole(id uint) (model.UserRole, error) {
+	return testutil.Roles(a).GetRole(id)
+}
+
+func (a accounts) GetAccount(id uint) (*model.User, error) {
+	role, ok := a[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.User{ID: id, Role: role}, nil
+}
+
+func TestStartImpersonation(t *testing.T) {
+	auth := service.NewAuthService(nil, config.AuthConfig{JWTSecret: "test-secret", Issuer: "bms-go", TokenTTL: time.Hour})
+	users := accounts{1: model.RoleReader, 2: model.RoleLibrarian, 3: model.RoleAdmin, 4: model.RoleAdmin}
+	sessions := &memoryImpersonations{}
+	impersonation := service.NewImpersonationService(sessions, users, time.Hour)
+
+	// The private middleware of the server, in its order
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.NewImpersonationHandler(impersonation).RegisterRoutes(handler.Routes{
+		Public: router.Group(""),
+		Private: router.Group("",
+			middleware.Users(auth),
+			middleware.Impersonation(impersonation),
+			middleware.RequirePrivateRoles(users, testutil.ShippedRules(t))),
+	})
+
+	start := func(t *testing.T, by, target uint, impersonationToken string) *http.Request {
+		req := testutil.AuthenticatedRequest(t, auth, by, http.MethodPost, "/admin/impersonate/"+strconv.FormatUint(uint64(target), 10), dto.ImpersonationRequest{Reason: "ticket 42"})
+		if impersonationToken != "" {
+			req.Header.Set(middleware.ImpersonationHeader, impersonationToken)
+		}
+		return req
+	}
+
+	// An admin impersonating another admin, whose token would pass the
+	// admin-only route guard
+	var resp dto.ImpersonationResponse
+	rec := testutil.Serve(router, start(t, 3, 4, ""))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("admin impersonating: status = %d, want 201; body %s", rec.Code, rec.Body)
+	}
+	testutil.DecodeJSON(t, rec, &resp)
+	if resp.UserID != 4 || resp.IssuedBy != "user:3" || resp.Token == "" {
+		t.Fatalf("session = %+v", resp)
+	}
+
+	tests := []struct {
+		name   string
+		by     uint
+		token  string
+		target uint
+		want   int
+	}{
+		{"reader", 1, "", 1, http.StatusForbidden},
+		{"librarian", 2, "", 1, http.StatusForbidden},
+		{"nonexistent user", 3, "", 99, http.StatusNotFound},
+		{"while impersonating", 3, resp.Token, 1, http.StatusForbidden},
+		{"with a made-up impersonation token", 3, service.ImpersonationTokenPrefix + "made-up", 1, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(sessions.sessions)
+			rec := testutil.Serve(router, start(t, tt.by, tt.target, tt.token))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.want, rec.Body)
+			}
+			if len(sessions.sessions) != before {
+				t.Fatalf("rejected request opened a session")
+			}
+		})
+	}
+
+	// The service checks the role too, for routes without the guard
+	for _, by := range []uint{1, 2, 99} {
+		if _, err := impersonation.Start(by, 4, dto.ImpersonationRequest{}); !errors.Is(err, service.ErrImpersonationForbidden) {
+			t.Errorf("Start by user %d = %v, want ErrImpersonationForbidden", by, err)
+		}
+	}
+
+	// The nested attempt is on the first session's audit log
+	if len(sessions.actions) != 1 || sessions.actions[0].Status != http.StatusForbidden {
+		t.Errorf("audit log = %+v, want the rejected nested attempt", sessions.actions)
+	}
+}
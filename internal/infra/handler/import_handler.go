@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ImportHandler struct {
+	service *service.ImportService
+}
+
+func NewImportHandler(s *service.ImportService) *ImportHandler {
+	return &ImportHandler{service: s}
+}
+
+func (h *ImportHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/admin/books/import", h.ImportBooks)
+
+	group := routes.Private.Group("/admin/imports")
+	group.GET("", h.GetBatches)
+	group.GET("/:id", h.GetBatch)
+	group.POST("/:id/rollback", h.RollbackBatch)
+}
+
+func respondImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "import batch not found")
+	case errors.Is(err, service.ErrImportRolledBack):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// parseDryRun reads the dry_run query parameter, false when absent
+func parseDryRun(c *gin.Context) (bool, []FieldError) {
+	raw, ok := c.GetQuery("dry_run")
+	if !ok {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, []FieldError{{Field: "dry_run", Message: "must be true or false"}}
+	}
+	return dryRun, nil
+}
+
+// parseDuplicatePolicy reads the on_duplicate query parameter, skip when
+// absent
+func parseDuplicatePolicy(c *gin.Context) (dto.DuplicatePolicy, []FieldError) {
+	policy := dto.DuplicatePolicy(c.DefaultQuery("on_duplicate", string(dto.DuplicateSkip)))
+	if !policy.Valid() {
+		return "", []FieldError{{Field: "on_duplicate", Message: "must be one of skip, update, create"}}
+	}
+	return policy, nil
+}
+
+// parseColumnMapping reads the columns query parameter, a comma-separated
+// list of field=header pairs such as "title=Judul,author=Penulis"
+func parseColumnMapping(c *gin.Context) (map[string]string, []FieldError) {
+	raw := c.Query("columns")
+	if raw == "" {
+		return nil, nil
+	}
+	known := make(map[string]bool)
+	for _, name := range service.BookColumnNames() {
+		known[name] = true
+	}
+
+	mapping := make(map[string]string)
+	var errs []FieldError
+	for _, pair := range strings.Split(raw, ",") {
+		field, header, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if !ok || strings.TrimSpace(header) == "" {
+			errs = append(errs, FieldError{Field: "columns", Message: "expected field=header, got " + strconv.Quote(pair)})
+			continue
+		}
+		if !known[field] {
+			errs = append(errs, FieldError{Field: "columns", Message: "unknown field " + strconv.Quote(field) + ", must be one of " + strings.Join(service.BookColumnNames(), ", ")})
+			continue
+		}
+		mapping[field] = header
+	}
+	return mapping, errs
+}
+
+// readImportFile reads the book rows from the uploaded file field and notes
+// the file in origin. The format comes from the file extension.
+func (h *ImportHandler) readImportFile(c *gin.Context, origin *dto.ImportOrigin) ([]dto.BookRequest, []FieldError) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, []FieldError{{Field: "file", Message: "is required"}}
+	}
+	format := dto.ImportFileFormat(strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), ".")))
+	if !format.Valid() {
+		return nil, []FieldError{{Field: "file", Message: "must be a .csv or .xlsx file"}}
+	}
+
+	columns, errs := parseColumnMapping(c)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, []FieldError{{Field: "file", Message: err.Error()}}
+	}
+	defer file.Close()
+
+	rows, err := h.service.ParseImportFile(file, format, dto.ImportFileOptions{Sheet: c.Query("sheet"), Columns: columns})
+	if err != nil {
+		return nil, []FieldError{{Field: "file", Message: err.Error()}}
+	}
+	origin.Format, origin.Filename = string(format), header.Filename
+	return rows, nil
+}
+
+// ImportBooks godoc
+// @Summary Import books
+// @Description Add a batch of books, sent as JSON or uploaded as a .csv or .xlsx file whose first row holds the column headers (title, author, category, description, published_year, pages, cover_url unless renamed with columns). Rows that duplicate an existing book or an earlier row (same title and author) are skipped, merged into the existing book (non-empty fields only) or created anyway depending on on_duplicate. With dry_run=true nothing is written and the report shows what would happen to each row. Otherwise the import is recorded as a batch, whose id is returned as batch_id; books it creates have source import and its import_batch_id.
+// @Tags Import
+// @Accept json,mpfd
+// @Produce json
+// @Param dry_run query bool false "Report without writing"
+// @Param on_duplicate query string false "Duplicate policy" Enums(skip, update, create) default(skip)
+// @Param sheet query string false "Worksheet to read from an .xlsx upload, defaults to the first"
+// @Param columns query string false "Header names for fields that differ, e.g. title=Judul,author=Penulis"
+// @Param books body dto.BookImportRequest false "Books to import"
+// @Param file formData file false "Spreadsheet to import"
+// @Success 200 {object} dto.ImportReport
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/books/import [post]
+func (h *ImportHandler) ImportBooks(c *gin.Context) {
+	dryRun, errs := parseDryRun(c)
+	policy, policyErrs := parseDuplicatePolicy(c)
+	errs = append(errs, policyErrs...)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	var rows []dto.BookRequest
+	userID, _ := middleware.UserID(c)
+	origin := dto.ImportOrigin{UserID: userID, Format: "json"}
+	if c.ContentType() == "multipart/form-data" {
+		rows, errs = h.readImportFile(c, &origin)
+		if len(errs) > 0 {
+			respondValidationError(c, errs)
+			return
+		}
+	} else {
+		var req dto.BookImportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		rows = req.Books
+	}
+
+	report, err := h.service.ImportBooks(c.Request.Context(), rows, policy, dryRun, origin)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetBatches godoc
+// @Summary List imports
+// @Description List the recorded imports, newest first, with who ran them, what they were read from and how many rows were created, updated and skipped
+// @Tags Import
+// @Produce json
+// @Success 200 {array} model.ImportBatch
+// @Failure 500 {object} apperror.Body
+// @Router /admin/imports [get]
+func (h *ImportHandler) GetBatches(c *gin.Context) {
+	batches, err := h.service.GetBatches()
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, batches)
+}
+
+// GetBatch godoc
+// @Summary Get an import
+// @Description Get one recorded import. Its books can be listed with GET /books?import_batch_id=
+// @Tags Import
+// @Produce json
+// @Param id path int true "Import batch ID"
+// @Success 200 {object} model.ImportBatch
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/imports/{id} [get]
+func (h *ImportHandler) GetBatch(c *gin.Context) {
+	batch, err := h.service.GetBatch(paramID(c, "id"))
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, batch)
+}
+
+// RollbackBatch godoc
+// @Summary Roll back an import
+// @Description Undo an import: books it created are deleted and books it updated get back the fields they had before. Books edited since the import, or whose earlier fields break the current validation rules, are left alone and listed as skipped. A batch can be rolled back once.
+// @Tags Import
+// @Produce json
+// @Param id path int true "Import batch ID"
+// @Success 200 {object} dto.ImportRollbackReport
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/imports/{id}/rollback [post]
+func (h *ImportHandler) RollbackBatch(c *gin.Context) {
+	report, err := h.service.RollbackBatch(c.Request.Context(), paramID(c, "id"))
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
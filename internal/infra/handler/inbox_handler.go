@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+)
+
+type InboxHandler struct {
+	service *service.InboxService
+}
+
+func NewInboxHandler(s *service.InboxService) *InboxHandler {
+	return &InboxHandler{service: s}
+}
+
+func (h *InboxHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/me/notifications")
+	group.GET("", h.GetNotifications)
+	group.GET("/unread-count", h.GetUnreadCount)
+	group.POST("/read-all", h.MarkAllRead)
+	group.POST("/:id/read", h.MarkRead)
+	group.DELETE("/:id", h.DeleteNotification)
+}
+
+func respondInboxError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetNotifications godoc
+// @Summary List my notifications
+// @Description List the user's notification history, newest first, whatever channels they were delivered through. Pass the returned next_before as before to load older notifications.
+// @Tags Notifications
+// @Produce json
+// @Param before query int false "Return notifications older than this id"
+// @Param limit query int false "Maximum number of notifications to return (1-100)" default(20)
+// @Param unread query bool false "Only return unread notifications"
+// @Success 200 {object} dto.InboxPage
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications [get]
+func (h *InboxHandler) GetNotifications(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var errs []FieldError
+
+	var before uint64
+	if raw, ok := c.GetQuery("before"); ok {
+		n, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "before", Message: "must be a non-negative integer"})
+		}
+		before = n
+	}
+
+	limit := defaultInboxLimit
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxInboxLimit {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxInboxLimit)})
+		}
+		limit = n
+	}
+
+	unreadOnly := false
+	if raw, ok := c.GetQuery("unread"); ok {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "unread", Message: "must be true or false"})
+		}
+		unreadOnly = b
+	}
+
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	page, err := h.service.GetPage(userID, uint(before), unreadOnly, limit)
+	if err != nil {
+		respondInboxError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetUnreadCount godoc
+// @Summary Count unread notifications
+// @Description Count the user's unread notifications, e.g. for a badge
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} dto.UnreadCountResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications/unread-count [get]
+func (h *InboxHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	unread, err := h.service.UnreadCount(userID)
+	if err != nil {
+		respondInboxError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.UnreadCountResponse{Unread: unread})
+}
+
+// MarkRead godoc
+// @Summary Mark notification read
+// @Description Mark one notification read. Marking it again keeps the first read time.
+// @Tags Notifications
+// @Param id path int true "Notification ID"
+// @Success 204
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications/{id}/read [post]
+func (h *InboxHandler) MarkRead(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarkRead(userID, paramID(c, "id")); err != nil {
+		respondInboxError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications read
+// @Description Mark every unread notification read
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications/read-all [post]
+func (h *InboxHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	marked, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		respondInboxError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// DeleteNotification godoc
+// @Summary Delete notification
+// @Description Remove a notification from the inbox
+// @Tags Notifications
+// @Param id path int true "Notification ID"
+// @Success 204
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications/{id} [delete]
+func (h *InboxHandler) DeleteNotification(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(userID, paramID(c, "id")); err != nil {
+		respondInboxError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
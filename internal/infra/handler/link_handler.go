@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultQRSize = 256
+	minQRSize     = 64
+	maxQRSize     = 1024
+)
+
+type LinkHandler struct {
+	service *service.LinkService
+}
+
+func NewLinkHandler(s *service.LinkService) *LinkHandler {
+	return &LinkHandler{service: s}
+}
+
+func (h *LinkHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/r/:code", h.ResolveShortLink)
+	routes.Public.GET("/books/:id/qr", h.GetBookQRCode)
+}
+
+// ResolveShortLink godoc
+// @Summary Resolve a book short link
+// @Description Redirect a short code to the book's detail page, or describe the target as JSON when requested with format=json or Accept: application/json
+// @Tags Links
+// @Produce json
+// @Param code path string true "Short code"
+// @Param format query string false "Response format" Enums(json)
+// @Success 200 {object} dto.ShortLinkResponse
+// @Success 302 "Redirect to the book detail page"
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /r/{code} [get]
+func (h *LinkHandler) ResolveShortLink(c *gin.Context) {
+	link, err := h.service.Resolve(c.Request.Context(), c.Param("code"))
+	if errors.Is(err, service.ErrInvalidShortCode) || errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "link not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, link)
+		return
+	}
+	c.Redirect(http.StatusFound, link.URL)
+}
+
+// GetBookQRCode godoc
+// @Summary Get a book's QR code
+// @Description Render a PNG QR code of the book's short link for printing on shelf labels
+// @Tags Links
+// @Produce png
+// @Param id path int true "Book ID"
+// @Param size query int false "Image size in pixels (64-1024)"
+// @Success 200 {file} binary
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/qr [get]
+func (h *LinkHandler) GetBookQRCode(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	size := defaultQRSize
+	if raw, ok := c.GetQuery("size"); ok {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < minQRSize || parsed > maxQRSize {
+			respondValidationError(c, []FieldError{{Field: "size", Message: "must be an integer between 64 and 1024"}})
+			return
+		}
+		size = parsed
+	}
+
+	png, err := h.service.QRCode(c.Request.Context(), uint(id), size)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "book not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
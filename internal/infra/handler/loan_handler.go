@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLoanLimit = 20
+	maxLoanLimit     = 100
+)
+
+type LoanHandler struct {
+	service *service.LoanService
+}
+
+func NewLoanHandler(s *service.LoanService) *LoanHandler {
+	return &LoanHandler{service: s}
+}
+
+func (h *LoanHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/books/:id/borrow", h.Borrow)
+	routes.Private.POST("/kiosk/checkout", h.KioskCheckout)
+	routes.Private.GET("/loans", h.GetMyLoans)
+	routes.Private.GET("/me/loans/history", h.GetMyLoanHistory)
+	routes.Private.POST("/loans/:id/return", h.Return)
+	routes.Private.GET("/admin/loans", h.GetLoans)
+}
+
+func respondLoanError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrLoanNotFound), errors.Is(err, service.ErrLoanBookNotFound), errors.Is(err, service.ErrCardNotFound):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrBookOnLoan), errors.Is(err, service.ErrLoanReturned):
+		respondError(c, http.StatusConflict, err)
+	case errors.Is(err, service.ErrAccountDisabled):
+		respondError(c, http.StatusForbidden, err)
+	case errors.Is(err, service.ErrInvalidCardNumber):
+		respondValidationError(c, []FieldError{{Field: "card_number", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidLoanRange):
+		respondValidationError(c, []FieldError{{Field: "to", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// parseLoanQuery reads the status and paging parameters shared by the loan
+// lists
+func parseLoanQuery(c *gin.Context) (dto.LoanQuery, []FieldError) {
+	query := dto.LoanQuery{Limit: defaultLoanLimit}
+	var errs []FieldError
+	if raw, ok := c.GetQuery("status"); ok {
+		if status := dto.LoanStatus(raw); !status.Valid() {
+			errs = append(errs, FieldError{Field: "status", Message: "must be one of active, overdue, returned"})
+		} else {
+			query.Status = status
+		}
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxLoanLimit {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxLoanLimit)})
+		} else {
+			query.Limit = limit
+		}
+	}
+	if raw, ok := c.GetQuery("offset"); ok {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			errs = append(errs, FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		} else {
+			query.Offset = offset
+		}
+	}
+	return query, errs
+}
+
+// Borrow godoc
+// @Summary Borrow a book
+// @Description Borrow a copy of a book for the loan period, fulfilling your reservation of it. Copies held for other users' reservations cannot be borrowed; the book is unavailable once every copy is out.
+// @Tags Loans
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 201 {object} model.Loan
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/borrow [post]
+func (h *LoanHandler) Borrow(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	loan, err := h.service.Borrow(c.Request.Context(), userID, paramID(c, "id"))
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, loan)
+}
+
+// KioskCheckout godoc
+// @Summary Self-checkout by library card
+// @Description Lend a book to the patron whose library card was scanned at a self-checkout kiosk, as POST /books/{id}/borrow would for them. The kiosk signs in with a staff account. Cards with a wrong check digit are rejected without a lookup, and disabled accounts cannot borrow.
+// @Tags Loans
+// @Accept json
+// @Produce json
+// @Param checkout body dto.KioskCheckoutRequest true "Card number and book"
+// @Success 201 {object} model.Loan
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 403 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /kiosk/checkout [post]
+func (h *LoanHandler) KioskCheckout(c *gin.Context) {
+	var req dto.KioskCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	loan, err := h.service.CheckoutByCard(c.Request.Context(), req.CardNumber, req.BookID)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, loan)
+}
+
+// GetMyLoans godoc
+// @Summary List my loans
+// @Description List the signed-in user's loans, newest first
+// @Tags Loans
+// @Produce json
+// @Param status query string false "Loan status" Enums(active, overdue, returned)
+// @Param limit query int false "Maximum number of loans to return (1-100)" default(20)
+// @Param offset query int false "Number of loans to skip"
+// @Success 200 {object} dto.LoanListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /loans [get]
+func (h *LoanHandler) GetMyLoans(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	query, errs := parseLoanQuery(c)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+	query.UserID = userID
+
+	loans, err := h.service.GetLoans(c.Request.Context(), query)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, loans)
+}
+
+// GetMyLoanHistory godoc
+// @Summary Export my loan history
+// @Description Download the signed-in user's loans, newest first, with how many books they borrowed and the share of returned loans that came back on time. from and to are days and both are included. Users who opted out of keeping their loan history only have their recent returned loans on record, which is flagged by history_limited. As CSV the totals are sent in the X-Total-Loans, X-Books-Borrowed and X-On-Time-Rate headers.
+// @Tags Me
+// @Produce json,text/csv
+// @Param format query string false "File format" Enums(json, csv) default(json)
+// @Param from query string false "First day borrowed (YYYY-MM-DD)"
+// @Param to query string false "Last day borrowed (YYYY-MM-DD)"
+// @Success 200 {object} dto.LoanHistoryResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/loans/history [get]
+func (h *LoanHandler) GetMyLoanHistory(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var query dto.LoanHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	history, err := h.service.GetHistory(c.Request.Context(), userID, query)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+	filename := "loan-history-" + time.Now().Format("20060102")
+	if query.Format != dto.ExportCSV {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+		c.JSON(http.StatusOK, history)
+		return
+	}
+
+	body, err := service.LoanHistoryCSV(history)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header("X-Total-Loans", strconv.Itoa(history.Totals.Loans))
+	c.Header("X-Books-Borrowed", strconv.Itoa(history.Totals.BooksBorrowed))
+	c.Header("X-On-Time-Rate", strconv.FormatFloat(history.Totals.OnTimeRate, 'f', 2, 64))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", body)
+}
+
+// Return godoc
+// @Summary Return a book
+// @Description Close a loan and put its copy back in stock, held for the next reservation of the book if there is one. Borrowers return their own loans; librarians and admins can check in anyone's.
+// @Tags Loans
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} model.Loan
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /loans/{id}/return [post]
+func (h *LoanHandler) Return(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	loan, err := h.service.Return(c.Request.Context(), paramID(c, "id"), userID)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, loan)
+}
+
+// GetLoans godoc
+// @Summary List loans
+// @Description List every user's loans, newest first, optionally for one user or book
+// @Tags Loans
+// @Produce json
+// @Param user_id query int false "Borrower"
+// @Param book_id query int false "Book"
+// @Param status query string false "Loan status" Enums(active, overdue, returned)
+// @Param limit query int false "Maximum number of loans to return (1-100)" default(20)
+// @Param offset query int false "Number of loans to skip"
+// @Success 200 {object} dto.LoanListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/loans [get]
+func (h *LoanHandler) GetLoans(c *gin.Context) {
+	query, errs := parseLoanQuery(c)
+	if raw, ok := c.GetQuery("user_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil || id == 0 {
+			errs = append(errs, FieldError{Field: "user_id", Message: "must be a positive integer"})
+		} else {
+			query.UserID = uint(id)
+		}
+	}
+	if raw, ok := c.GetQuery("book_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil || id == 0 {
+			errs = append(errs, FieldError{Field: "book_id", Message: "must be a positive integer"})
+		} else {
+			query.BookID = uint(id)
+		}
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	loans, err := h.service.GetLoans(c.Request.Context(), query)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, loans)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceHandler struct {
+	service *service.MaintenanceService
+}
+
+func NewMaintenanceHandler(s *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{service: s}
+}
+
+func (h *MaintenanceHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin")
+	group.POST("/cache/warm", h.WarmCaches)
+	group.POST("/search/reindex", h.ReindexSearch)
+	group.GET("/tasks/:id", h.GetTask)
+}
+
+// respondTaskStarted answers with the new task, or with the task already
+// running when one of the same kind has not finished yet
+func respondTaskStarted(c *gin.Context, task dto.Task, err error) {
+	switch {
+	case errors.Is(err, service.ErrTaskRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperror.CodeOf(err), "task": task})
+	case err != nil:
+		respondError(c, http.StatusInternalServerError, err)
+	default:
+		c.JSON(http.StatusAccepted, task)
+	}
+}
+
+// WarmCaches godoc
+// @Summary Warm caches
+// @Description Rebuild the search spelling vocabulary, the category and author lists and every sitemap document in the background. Poll the returned task for progress.
+// @Tags Maintenance
+// @Produce json
+// @Success 202 {object} dto.Task
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/cache/warm [post]
+func (h *MaintenanceHandler) WarmCaches(c *gin.Context) {
+	task, err := h.service.WarmCaches()
+	respondTaskStarted(c, task, err)
+}
+
+// ReindexSearch godoc
+// @Summary Rebuild search indexes
+// @Description Rebuild the book full-text indexes and the spelling vocabulary in the background. Poll the returned task for progress.
+// @Tags Maintenance
+// @Produce json
+// @Success 202 {object} dto.Task
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/search/reindex [post]
+func (h *MaintenanceHandler) ReindexSearch(c *gin.Context) {
+	task, err := h.service.ReindexSearch()
+	respondTaskStarted(c, task, err)
+}
+
+// GetTask godoc
+// @Summary Get task progress
+// @Description Status and progress of a background admin task
+// @Tags Maintenance
+// @Produce json
+// @Param id path int true "Task ID"
+// @Success 200 {object} dto.Task
+// @Failure 404 {object} apperror.Body
+// @Router /admin/tasks/{id} [get]
+func (h *MaintenanceHandler) GetTask(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	task, err := h.service.GetTask(id)
+	if errors.Is(err, service.ErrTaskNotFound) {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
@@ -7,5 +7,5 @@ import (
 )
 
 func NotFoundHandler(c *gin.Context) {
-	c.JSON(http.StatusNotFound, gin.H{"error": "endpoint not found"})
+	respondMessage(c, http.StatusNotFound, "endpoint not found")
 }
\ No newline at end of file
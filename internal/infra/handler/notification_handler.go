@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"bms-go/internal/infra/notify"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationHandler struct {
+	service *service.NotificationService
+}
+
+func NewNotificationHandler(s *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: s}
+}
+
+func (h *NotificationHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/notifications/webpush/key", h.GetWebPushKey)
+
+	group := routes.Private.Group("/me/notifications/channels")
+	group.GET("", h.GetChannels)
+	group.PUT("/:channel", h.SetChannel)
+	group.DELETE("/:channel", h.DeleteChannel)
+	group.POST("/:channel/test", h.TestChannel)
+
+	devices := routes.Private.Group("/me/devices")
+	devices.GET("", h.GetDevices)
+	devices.PUT("", h.RegisterDevice)
+	devices.DELETE("/:id", h.UnregisterDevice)
+}
+
+func respondNotificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrInvalidChannel), errors.Is(err, service.ErrChannelUnavailable):
+		respondValidationError(c, []FieldError{{Field: "channel", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidNotificationEvent):
+		respondValidationError(c, []FieldError{{Field: "events", Message: err.Error()}})
+	case errors.Is(err, notify.ErrInvalidTarget), errors.Is(err, service.ErrNotificationTargetNeeded):
+		respondValidationError(c, []FieldError{{Field: "target", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+func respondDeviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPushProvider), errors.Is(err, service.ErrChannelUnavailable):
+		respondValidationError(c, []FieldError{{Field: "provider", Message: err.Error()}})
+	case errors.Is(err, notify.ErrInvalidTarget):
+		respondValidationError(c, []FieldError{{Field: "token", Message: err.Error()}})
+	default:
+		respondNotificationError(c, err)
+	}
+}
+
+// GetWebPushKey godoc
+// @Summary Get Web Push key
+// @Description Get the VAPID public key to pass as applicationServerKey when subscribing a browser to push messages
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} dto.WebPushKeyResponse
+// @Failure 404 {object} apperror.Body
+// @Router /notifications/webpush/key [get]
+func (h *NotificationHandler) GetWebPushKey(c *gin.Context) {
+	key, err := h.service.WebPushKey()
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.WebPushKeyResponse{PublicKey: key})
+}
+
+// GetChannels godoc
+// @Summary List notification channels
+// @Description List the user's notification channels. Targets are not returned.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {array} model.NotificationPreference
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications/channels [get]
+func (h *NotificationHandler) GetChannels(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(userID)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// SetChannel godoc
+// @Summary Set up notification channel
+// @Description Set up or update a channel. The target is a Slack incoming webhook URL, a Telegram chat id, or a Web Push subscription as JSON whose endpoint is on a browser push service (Google, Mozilla, Apple or Microsoft). Events limits which events are sent; an empty list receives all.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel" Enums(slack, telegram, webpush)
+// @Param preference body dto.NotificationPreferenceRequest true "Channel setup"
+// @Success 200 {object} model.NotificationPreference
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications/channels/{channel} [put]
+func (h *NotificationHandler) SetChannel(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.NotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	pref, err := h.service.SetPreference(userID, model.NotificationChannel(c.Param("channel")), req)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
+
+// DeleteChannel godoc
+// @Summary Remove notification channel
+// @Description Stop notifications through a channel and forget its target
+// @Tags Notifications
+// @Param channel path string true "Channel" Enums(slack, telegram, webpush)
+// @Success 204
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/notifications/channels/{channel} [delete]
+func (h *NotificationHandler) DeleteChannel(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePreference(userID, model.NotificationChannel(c.Param("channel"))); err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// TestChannel godoc
+// @Summary Send test notification
+// @Description Send a test message through the channel right away
+// @Tags Notifications
+// @Param channel path string true "Channel" Enums(slack, telegram, webpush)
+// @Success 204
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 502 {object} apperror.Body
+// @Router /me/notifications/channels/{channel}/test [post]
+func (h *NotificationHandler) TestChannel(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.service.SendTest(c.Request.Context(), userID, model.NotificationChannel(c.Param("channel")))
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrInvalidChannel), errors.Is(err, service.ErrChannelUnavailable):
+		respondNotificationError(c, err)
+	default:
+		respondError(c, http.StatusBadGateway, err)
+	}
+}
+
+// GetDevices godoc
+// @Summary List my devices
+// @Description List the app installations registered for push notifications, most recently seen first. Push tokens are not returned.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {array} model.Device
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/devices [get]
+func (h *NotificationHandler) GetDevices(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	devices, err := h.service.GetDevices(userID)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+// RegisterDevice godoc
+// @Summary Register device
+// @Description Register an app installation's push token (FCM registration token or APNs device token). Apps should call this on every start; a known token only has its details refreshed. Devices whose token the provider rejects as unregistered are removed automatically.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param device body dto.DeviceRequest true "Device"
+// @Success 200 {object} model.Device
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/devices [put]
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.DeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	device, err := h.service.RegisterDevice(userID, req)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, device)
+}
+
+// UnregisterDevice godoc
+// @Summary Unregister device
+// @Description Stop push notifications to a device and forget its token, e.g. when signing out of the app
+// @Tags Notifications
+// @Param id path int true "Device ID"
+// @Success 204
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/devices/{id} [delete]
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnregisterDevice(userID, paramID(c, "id")); err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
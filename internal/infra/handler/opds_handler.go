@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OPDSHandler struct {
+	service *service.OPDSService
+}
+
+func NewOPDSHandler(s *service.OPDSService) *OPDSHandler {
+	return &OPDSHandler{service: s}
+}
+
+func (h *OPDSHandler) RegisterRoutes(routes Routes) {
+	group := routes.Public.Group("/opds")
+	group.GET("", h.GetRoot)
+	group.GET("/categories", h.GetCategories)
+	group.GET("/authors", h.GetAuthors)
+	group.GET("/books", h.GetBooks)
+	group.GET("/opensearch.xml", h.GetSearchDescription)
+}
+
+// GetRoot godoc
+// @Summary OPDS root catalog
+// @Description OPDS 1.2 navigation feed for e-reader apps such as KOReader and Calibre
+// @Tags OPDS
+// @Produce xml
+// @Success 200 {string} string
+// @Router /opds [get]
+func (h *OPDSHandler) GetRoot(c *gin.Context) {
+	writeXML(c, dto.OPDSNavigationType, h.service.Root())
+}
+
+// GetCategories godoc
+// @Summary OPDS categories
+// @Description OPDS navigation feed with one entry per category
+// @Tags OPDS
+// @Produce xml
+// @Success 200 {string} string
+// @Failure 500 {object} apperror.Body
+// @Router /opds/categories [get]
+func (h *OPDSHandler) GetCategories(c *gin.Context) {
+	feed, err := h.service.Categories(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeXML(c, dto.OPDSNavigationType, feed)
+}
+
+// GetAuthors godoc
+// @Summary OPDS authors
+// @Description OPDS navigation feed with one entry per author
+// @Tags OPDS
+// @Produce xml
+// @Success 200 {string} string
+// @Failure 500 {object} apperror.Body
+// @Router /opds/authors [get]
+func (h *OPDSHandler) GetAuthors(c *gin.Context) {
+	feed, err := h.service.Authors(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeXML(c, dto.OPDSNavigationType, feed)
+}
+
+// GetBooks godoc
+// @Summary OPDS books
+// @Description Paginated OPDS acquisition feed of books, filtered by search, category or author
+// @Tags OPDS
+// @Produce xml
+// @Param q query string false "Search keyword" maxlength(200)
+// @Param category query string false "Category filter"
+// @Param author query string false "Author filter"
+// @Param page query int false "Page number, starting at 1"
+// @Success 200 {string} string
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /opds/books [get]
+func (h *OPDSHandler) GetBooks(c *gin.Context) {
+	filter := service.OPDSFilter{
+		Search:   c.Query("q"),
+		Category: c.Query("category"),
+		Author:   c.Query("author"),
+		Page:     1,
+	}
+	if msg := checkSearch(filter.Search); msg != "" {
+		respondValidationError(c, []FieldError{{Field: "q", Message: msg}})
+		return
+	}
+	if raw, ok := c.GetQuery("page"); ok {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			respondValidationError(c, []FieldError{{Field: "page", Message: "must be a positive integer"}})
+			return
+		}
+		filter.Page = page
+	}
+
+	feed, err := h.service.Books(filter)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeXML(c, dto.OPDSAcquisitionType, feed)
+}
+
+// GetSearchDescription godoc
+// @Summary OPDS search description
+// @Description OpenSearch description document used by OPDS clients to search the catalog
+// @Tags OPDS
+// @Produce xml
+// @Success 200 {string} string
+// @Router /opds/opensearch.xml [get]
+func (h *OPDSHandler) GetSearchDescription(c *gin.Context) {
+	writeXML(c, dto.OpenSearchType, h.service.SearchDescription())
+}
+
+func writeXML(c *gin.Context, contentType string, v interface{}) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", append([]byte(xml.Header), body...))
+}
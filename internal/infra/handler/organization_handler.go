@@ -0,0 +1,418 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OrganizationHandler struct {
+	service *service.OrganizationService
+}
+
+func NewOrganizationHandler(s *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{service: s}
+}
+
+func (h *OrganizationHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/organizations")
+	group.GET("", h.GetOrganizations)
+	group.POST("", h.CreateOrganization)
+	group.GET("/:id", h.GetOrganization)
+	group.DELETE("/:id", h.DeleteOrganization)
+	group.POST("/:id/invitations", h.Invite)
+	group.PUT("/:id/members/:userID", h.ChangeRole)
+	group.DELETE("/:id/members/:userID", h.RemoveMember)
+	group.GET("/:id/favorites", h.GetFavorites)
+	group.POST("/:id/favorites", h.AddFavorite)
+	group.DELETE("/:id/favorites/:favoriteID", h.RemoveFavorite)
+
+	invitations := routes.Private.Group("/me/invitations")
+	invitations.GET("", h.GetInvitations)
+	invitations.POST("/:id/accept", h.AcceptInvitation)
+	invitations.POST("/:id/decline", h.DeclineInvitation)
+}
+
+// respondOrganizationError maps organization service errors to responses
+func respondOrganizationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotOrgMember):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrInvitationNotOurs), errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrOrgForbidden):
+		respondError(c, http.StatusForbidden, err)
+	case errors.Is(err, service.ErrInvalidOrgRole):
+		respondValidationError(c, []FieldError{{Field: "role", Message: err.Error()}})
+	case errors.Is(err, service.ErrAlreadyOrgMember), errors.Is(err, service.ErrLastOrgOwner), errors.Is(err, service.ErrInvitationClosed):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+func paramID(c *gin.Context, name string) uint {
+	id, _ := strconv.Atoi(c.Param(name))
+	return uint(id)
+}
+
+// GetOrganizations godoc
+// @Summary List my organizations
+// @Description List the organizations the user belongs to and their role in each
+// @Tags Organizations
+// @Produce json
+// @Success 200 {array} dto.OrganizationResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations [get]
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	orgs, err := h.service.GetOrganizations(userID)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+// CreateOrganization godoc
+// @Summary Create organization
+// @Description Create an organization with the user as its owner
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param organization body dto.OrganizationRequest true "Organization"
+// @Success 201 {object} dto.OrganizationResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	org, err := h.service.CreateOrganization(userID, req)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+// GetOrganization godoc
+// @Summary Get organization
+// @Description Get an organization and its members. Only visible to members.
+// @Tags Organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} dto.OrganizationResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.service.GetOrganization(userID, paramID(c, "id"))
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// DeleteOrganization godoc
+// @Summary Delete organization
+// @Description Delete an organization with its members, invitations and shared favorites. Owners only.
+// @Tags Organizations
+// @Param id path int true "Organization ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 403 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id} [delete]
+func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteOrganization(userID, paramID(c, "id")); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Invite godoc
+// @Summary Invite a member
+// @Description Invite a user to the organization with a role. Admins and owners only; only owners can invite owners.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param invitation body dto.InvitationRequest true "Invitation"
+// @Success 201 {object} model.OrganizationInvitation
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 403 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id}/invitations [post]
+func (h *OrganizationHandler) Invite(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	inv, err := h.service.Invite(userID, paramID(c, "id"), req)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, inv)
+}
+
+// ChangeRole godoc
+// @Summary Change a member's role
+// @Description Change a member's role. Admins manage members and admins, owners manage everyone. The last owner cannot be demoted.
+// @Tags Organizations
+// @Accept json
+// @Param id path int true "Organization ID"
+// @Param userID path int true "Member user ID"
+// @Param role body dto.MemberRoleRequest true "New role"
+// @Success 204 "No Content"
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 403 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id}/members/{userID} [put]
+func (h *OrganizationHandler) ChangeRole(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := h.service.ChangeRole(userID, paramID(c, "id"), paramID(c, "userID"), req.Role)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Remove a member from the organization, or leave it when removing yourself
+// @Tags Organizations
+// @Param id path int true "Organization ID"
+// @Param userID path int true "Member user ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 403 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id}/members/{userID} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(userID, paramID(c, "id"), paramID(c, "userID")); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetFavorites godoc
+// @Summary Get team library
+// @Description Get the organization's shared favorite books
+// @Tags Organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {array} dto.OrgFavoriteResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id}/favorites [get]
+func (h *OrganizationHandler) GetFavorites(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	favs, err := h.service.GetFavorites(c.Request.Context(), userID, paramID(c, "id"))
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, favs)
+}
+
+// AddFavorite godoc
+// @Summary Add to team library
+// @Description Add a book to the organization's shared favorites
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param favorite body dto.OrgFavoriteRequest true "Book"
+// @Success 201 {object} dto.OrgFavoriteResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id}/favorites [post]
+func (h *OrganizationHandler) AddFavorite(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.OrgFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	fav, err := h.service.AddFavorite(c.Request.Context(), userID, paramID(c, "id"), req)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, fav)
+}
+
+// RemoveFavorite godoc
+// @Summary Remove from team library
+// @Description Remove a book from the organization's shared favorites
+// @Tags Organizations
+// @Param id path int true "Organization ID"
+// @Param favoriteID path int true "Shared favorite ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /organizations/{id}/favorites/{favoriteID} [delete]
+func (h *OrganizationHandler) RemoveFavorite(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveFavorite(userID, paramID(c, "id"), paramID(c, "favoriteID")); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetInvitations godoc
+// @Summary List my invitations
+// @Description List organization invitations waiting for the user's answer
+// @Tags Me
+// @Produce json
+// @Success 200 {array} model.OrganizationInvitation
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/invitations [get]
+func (h *OrganizationHandler) GetInvitations(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	invs, err := h.service.GetInvitations(userID)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invs)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Join the organization with the invited role
+// @Tags Me
+// @Param id path int true "Invitation ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/invitations/{id}/accept [post]
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RespondToInvitation(userID, paramID(c, "id"), true); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeclineInvitation godoc
+// @Summary Decline invitation
+// @Description Decline an organization invitation
+// @Tags Me
+// @Param id path int true "Invitation ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/invitations/{id}/decline [post]
+func (h *OrganizationHandler) DeclineInvitation(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RespondToInvitation(userID, paramID(c, "id"), false); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PartnerHandler struct {
+	service *service.PartnerService
+}
+
+func NewPartnerHandler(s *service.PartnerService) *PartnerHandler {
+	return &PartnerHandler{service: s}
+}
+
+func (h *PartnerHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/partners")
+	group.GET("", h.GetPartners)
+	group.POST("", h.CreatePartner)
+	group.POST("/:id/rotate-secret", h.RotateSecret)
+	group.DELETE("/:id", h.DeletePartner)
+}
+
+// GetPartners godoc
+// @Summary Get partners
+// @Description List the partners allowed to send signed requests
+// @Tags Partners
+// @Produce json
+// @Success 200 {array} dto.PartnerResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/partners [get]
+func (h *PartnerHandler) GetPartners(c *gin.Context) {
+	partners, err := h.service.GetPartners()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, partners)
+}
+
+// CreatePartner godoc
+// @Summary Create partner
+// @Description Register a partner and issue its key id and shared secret. The secret is only shown once.
+// @Tags Partners
+// @Accept json
+// @Produce json
+// @Param partner body dto.PartnerRequest true "Partner"
+// @Success 201 {object} dto.PartnerResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/partners [post]
+func (h *PartnerHandler) CreatePartner(c *gin.Context) {
+	var req dto.PartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	partner, err := h.service.CreatePartner(req)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, partner)
+}
+
+// RotateSecret godoc
+// @Summary Rotate partner secret
+// @Description Replace a partner's shared secret. The new secret is only shown once.
+// @Tags Partners
+// @Produce json
+// @Param id path int true "Partner ID"
+// @Success 200 {object} dto.PartnerResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/partners/{id}/rotate-secret [post]
+func (h *PartnerHandler) RotateSecret(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	partner, err := h.service.RotateSecret(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "partner not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, partner)
+}
+
+// DeletePartner godoc
+// @Summary Delete partner
+// @Description Revoke a partner's access
+// @Tags Partners
+// @Param id path int true "Partner ID"
+// @Success 204 "No Content"
+// @Failure 500 {object} apperror.Body
+// @Router /admin/partners/{id} [delete]
+func (h *PartnerHandler) DeletePartner(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.DeletePartner(uint(id)); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
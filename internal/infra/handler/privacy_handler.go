@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyHandler struct {
+	service *service.PrivacyService
+}
+
+func NewPrivacyHandler(s *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: s}
+}
+
+func (h *PrivacyHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/me/privacy")
+	group.GET("", h.GetSettings)
+	group.PUT("", h.UpdateSettings)
+}
+
+// GetSettings godoc
+// @Summary Get privacy settings
+// @Description Get the user's privacy settings
+// @Tags Me
+// @Produce json
+// @Success 200 {object} dto.PrivacySettingsResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/privacy [get]
+func (h *PrivacyHandler) GetSettings(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update privacy settings
+// @Description Update the user's privacy settings. Only the settings present in the body change. Disabling recently viewed tracking clears the stored history. With forget_loan_history, returned loans are deleted once they are older than the configured loans.history.forget_after.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param settings body dto.PrivacySettingsRequest true "Privacy settings"
+// @Success 200 {object} dto.PrivacySettingsResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/privacy [put]
+func (h *PrivacyHandler) UpdateSettings(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PrivacySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(userID, req)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaHandler struct {
+	service *service.QuotaService
+}
+
+func NewQuotaHandler(s *service.QuotaService) *QuotaHandler {
+	return &QuotaHandler{service: s}
+}
+
+func (h *QuotaHandler) RegisterRoutes(routes Routes) {
+	routes.Private.GET("/me/usage", h.GetUsage)
+
+	group := routes.Private.Group("/admin/quotas")
+	group.GET("", h.GetQuotas)
+	group.PUT("/:subject", h.SetQuota)
+}
+
+// GetUsage godoc
+// @Summary Get API usage
+// @Description Get the caller's request count and quota for the current month
+// @Tags Me
+// @Produce json
+// @Success 200 {object} dto.UsageResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/usage [get]
+func (h *QuotaHandler) GetUsage(c *gin.Context) {
+	subject := middleware.QuotaSubject(c)
+	if subject == "" {
+		respondMessage(c, http.StatusUnauthorized, "usage is tracked per api key or user")
+		return
+	}
+
+	usage, err := h.service.GetUsage(subject)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// GetQuotas godoc
+// @Summary Get quota overrides
+// @Description List every subject whose monthly quota differs from the default
+// @Tags Quotas
+// @Produce json
+// @Success 200 {array} dto.QuotaResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/quotas [get]
+func (h *QuotaHandler) GetQuotas(c *gin.Context) {
+	quotas, err := h.service.GetQuotas()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, quotas)
+}
+
+// SetQuota godoc
+// @Summary Set a quota
+// @Description Set the monthly request quota of a partner (partner:<id>), API key (key:<id>) or user (user:<id>); 0 means unlimited
+// @Tags Quotas
+// @Accept json
+// @Produce json
+// @Param subject path string true "Quota subject, e.g. key:1a2b3c4d5e6f7a8b or user:42"
+// @Param quota body dto.QuotaRequest true "Quota"
+// @Success 200 {object} dto.QuotaResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/quotas/{subject} [put]
+func (h *QuotaHandler) SetQuota(c *gin.Context) {
+	var req dto.QuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	quota, err := h.service.SetQuota(c.Param("subject"), req)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, quota)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/service"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReadingLogHandler struct {
+	service *service.ReadingLogService
+}
+
+func NewReadingLogHandler(s *service.ReadingLogService) *ReadingLogHandler {
+	return &ReadingLogHandler{service: s}
+}
+
+func (h *ReadingLogHandler) RegisterRoutes(routes Routes) {
+	routes.Private.GET("/me/reading-statuses", h.GetMyStatuses)
+	routes.Private.POST("/me/reading-log/import", h.ImportReadingLog)
+}
+
+func respondReadingLogError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidReadingState):
+		respondValidationError(c, []FieldError{{Field: "status", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidReadingLog):
+		respondValidationError(c, []FieldError{{Field: "file", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetMyStatuses godoc
+// @Summary List my reading statuses
+// @Description List the books the signed-in user wants to read, is reading or has read, most recently changed first
+// @Tags Reading Log
+// @Produce json
+// @Param status query string false "Reading status" Enums(want_to_read, reading, read)
+// @Success 200 {array} model.ReadingStatus
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/reading-statuses [get]
+func (h *ReadingLogHandler) GetMyStatuses(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	statuses, err := h.service.GetStatuses(userID, model.ReadingState(c.Query("status")))
+	if err != nil {
+		respondReadingLogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, statuses)
+}
+
+// ImportReadingLog godoc
+// @Summary Import my reading log
+// @Description Import the signed-in user's reading history from a CSV file, uploaded as the file field or sent as the request body. The first row holds the column headers: title or isbn, and optionally author, date_finished (YYYY-MM-DD), rating (1-5, 0 or empty for none) and notes. Each row's book is marked as read with its finish date and notes, replacing any earlier status of the book, and its rating is added as the user's review unless they already reviewed the book. Books are matched by title, narrowed down by author when given; the catalog has no ISBNs, so rows with only an ISBN cannot be matched. Rows matching no book or several are listed as unmatched. With dry_run=true nothing is written.
+// @Tags Reading Log
+// @Accept mpfd,text/csv
+// @Produce json
+// @Param dry_run query bool false "Report without writing"
+// @Param file formData file false "Reading log to import"
+// @Success 200 {object} dto.ReadingLogImportReport
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/reading-log/import [post]
+func (h *ReadingLogHandler) ImportReadingLog(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	dryRun, errs := parseDryRun(c)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			respondValidationError(c, []FieldError{{Field: "file", Message: "is required"}})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			respondValidationError(c, []FieldError{{Field: "file", Message: err.Error()}})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	rows, err := service.ParseReadingLog(body)
+	if err != nil {
+		respondReadingLogError(c, err)
+		return
+	}
+	report, err := h.service.ImportReadingLog(c.Request.Context(), userID, rows, dryRun)
+	if err != nil {
+		respondReadingLogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecentlyViewedHandler struct {
+	service *service.RecentlyViewedService
+}
+
+func NewRecentlyViewedHandler(s *service.RecentlyViewedService) *RecentlyViewedHandler {
+	return &RecentlyViewedHandler{service: s}
+}
+
+func (h *RecentlyViewedHandler) RegisterRoutes(routes Routes) {
+	routes.Private.GET("/me/recently-viewed", h.GetRecentlyViewed)
+}
+
+// GetRecentlyViewed godoc
+// @Summary Get recently viewed books
+// @Description Get the books the user opened most recently, newest first
+// @Tags Me
+// @Produce json
+// @Success 200 {array} dto.RecentlyViewedResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/recently-viewed [get]
+func (h *RecentlyViewedHandler) GetRecentlyViewed(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	books, err := h.service.GetRecentlyViewed(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, books)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReminderHandler struct {
+	service *service.ReminderService
+}
+
+func NewReminderHandler(s *service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{service: s}
+}
+
+func (h *ReminderHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/me/reminders")
+	group.GET("", h.GetSettings)
+	group.PUT("", h.UpdateSettings)
+
+	// Links in reminders are opened in a browser; mail clients offering
+	// one-click unsubscribe POST to the same link
+	routes.Public.GET("/reminders/unsubscribe", h.Unsubscribe)
+	routes.Public.POST("/reminders/unsubscribe", h.Unsubscribe)
+}
+
+// GetSettings godoc
+// @Summary Get due-date reminder settings
+// @Description Get whether the user is reminded of loans coming due, and how many days before the due date
+// @Tags Me
+// @Produce json
+// @Success 200 {object} dto.ReminderSettingsResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/reminders [get]
+func (h *ReminderHandler) GetSettings(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update due-date reminder settings
+// @Description Update the user's reminders. Only the settings present in the body change. days_before lists up to 5 days, each 1 to 30, before the due date to be reminded on; an empty list goes back to the server's days. Reminders are delivered through the user's notification channels and devices as the loan_due_soon event.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param settings body dto.ReminderSettingsRequest true "Reminder settings"
+// @Success 200 {object} dto.ReminderSettingsResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me/reminders [put]
+func (h *ReminderHandler) UpdateSettings(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReminderSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(userID, req)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// Unsubscribe godoc
+// @Summary Turn off due-date reminders
+// @Description Turn off the reminders of the user a reminder's unsubscribe link was made for, without signing in
+// @Tags Me
+// @Produce json
+// @Param user query int true "User ID from the link"
+// @Param token query string true "Token from the link"
+// @Success 204 "No Content"
+// @Failure 403 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /reminders/unsubscribe [get]
+func (h *ReminderHandler) Unsubscribe(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Query("user"), 10, 0)
+	if err != nil {
+		respondError(c, http.StatusForbidden, service.ErrInvalidUnsubscribeLink)
+		return
+	}
+
+	err = h.service.Unsubscribe(uint(userID), c.Query("token"))
+	if errors.Is(err, service.ErrInvalidUnsubscribeLink) {
+		respondError(c, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
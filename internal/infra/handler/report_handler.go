@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReportHandler struct {
+	service *service.ReportService
+}
+
+func NewReportHandler(s *service.ReportService) *ReportHandler {
+	return &ReportHandler{service: s}
+}
+
+func (h *ReportHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/reports")
+	group.GET("/schedules", h.GetSchedules)
+	group.POST("/schedules", h.CreateSchedule)
+	group.PUT("/schedules/:id", h.UpdateSchedule)
+	group.DELETE("/schedules/:id", h.DeleteSchedule)
+	group.GET("/schedules/:id/runs", h.GetRuns)
+	group.POST("/schedules/:id/run", h.RunNow)
+	group.GET("/runs/:id/download", h.DownloadRun)
+}
+
+func respondReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrInvalidReportKind):
+		respondValidationError(c, []FieldError{{Field: "kind", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidReportFrequency):
+		respondValidationError(c, []FieldError{{Field: "frequency", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetSchedules godoc
+// @Summary List report schedules
+// @Description List scheduled reports
+// @Tags Reports
+// @Produce json
+// @Success 200 {array} model.ReportSchedule
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reports/schedules [get]
+func (h *ReportHandler) GetSchedules(c *gin.Context) {
+	schedules, err := h.service.GetSchedules()
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, schedules)
+}
+
+// CreateSchedule godoc
+// @Summary Create report schedule
+// @Description Schedule a report (new_books or category_summary) to be generated daily, weekly or monthly
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param schedule body dto.ReportScheduleRequest true "Schedule"
+// @Success 201 {object} model.ReportSchedule
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reports/schedules [post]
+func (h *ReportHandler) CreateSchedule(c *gin.Context) {
+	var req dto.ReportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := h.service.CreateSchedule(req)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateSchedule godoc
+// @Summary Update report schedule
+// @Description Change a report schedule, or pause it with active=false
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param schedule body dto.ReportScheduleRequest true "Schedule"
+// @Success 200 {object} model.ReportSchedule
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reports/schedules/{id} [put]
+func (h *ReportHandler) UpdateSchedule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req dto.ReportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := h.service.UpdateSchedule(uint(id), req)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule godoc
+// @Summary Delete report schedule
+// @Description Stop and remove a report schedule; reports already generated are kept
+// @Tags Reports
+// @Param id path int true "Schedule ID"
+// @Success 204 "No Content"
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reports/schedules/{id} [delete]
+func (h *ReportHandler) DeleteSchedule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.DeleteSchedule(uint(id)); err != nil {
+		respondReportError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetRuns godoc
+// @Summary List generated reports
+// @Description List the reports a schedule has generated, newest first
+// @Tags Reports
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {array} model.ReportRun
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reports/schedules/{id}/runs [get]
+func (h *ReportHandler) GetRuns(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	runs, err := h.service.GetRuns(uint(id))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// RunNow godoc
+// @Summary Generate report now
+// @Description Generate the schedule's report for the period since its last run
+// @Tags Reports
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 201 {object} model.ReportRun
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reports/schedules/{id}/run [post]
+func (h *ReportHandler) RunNow(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	run, err := h.service.RunNow(c.Request.Context(), uint(id))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, run)
+}
+
+// DownloadRun godoc
+// @Summary Download report
+// @Description Download a generated report
+// @Tags Reports
+// @Produce text/csv
+// @Param id path int true "Report ID"
+// @Success 200 {file} file
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reports/runs/{id}/download [get]
+func (h *ReportHandler) DownloadRun(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	run, err := h.service.GetRun(uint(id))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.csv", run.Kind, run.PeriodEnd.Format("2006-01-02"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, run.ContentType, run.Content)
+}
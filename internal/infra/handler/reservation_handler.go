@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultReservationLimit = 20
+	maxReservationLimit     = 100
+)
+
+type ReservationHandler struct {
+	service *service.ReservationService
+}
+
+func NewReservationHandler(s *service.ReservationService) *ReservationHandler {
+	return &ReservationHandler{service: s}
+}
+
+func (h *ReservationHandler) RegisterRoutes(routes Routes) {
+	routes.Private.POST("/books/:id/reserve", h.Reserve)
+	routes.Private.GET("/reservations", h.GetMyReservations)
+	routes.Private.POST("/reservations/:id/cancel", h.Cancel)
+	routes.Private.GET("/admin/reservations", h.GetReservations)
+}
+
+func respondReservationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrReservationNotFound), errors.Is(err, service.ErrReservationBookNotFound):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrAlreadyReserved), errors.Is(err, service.ErrBookAvailable), errors.Is(err, service.ErrReservationClosed):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// parseReservationQuery reads the status and paging parameters shared by
+// the reservation lists
+func parseReservationQuery(c *gin.Context) (dto.ReservationQuery, []FieldError) {
+	query := dto.ReservationQuery{Limit: defaultReservationLimit}
+	var errs []FieldError
+	if raw, ok := c.GetQuery("status"); ok {
+		if status := model.ReservationStatus(raw); !status.Valid() {
+			errs = append(errs, FieldError{Field: "status", Message: "must be one of waiting, ready, fulfilled, cancelled, expired"})
+		} else {
+			query.Status = status
+		}
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxReservationLimit {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxReservationLimit)})
+		} else {
+			query.Limit = limit
+		}
+	}
+	if raw, ok := c.GetQuery("offset"); ok {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			errs = append(errs, FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		} else {
+			query.Offset = offset
+		}
+	}
+	return query, errs
+}
+
+// Reserve godoc
+// @Summary Reserve a book
+// @Description Join the queue for a book whose copies are all out on loan. Reservations are served first come, first served: when a copy is returned it is held for the next reservation, which becomes ready and can be borrowed until it expires.
+// @Tags Reservations
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 201 {object} model.Reservation
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/reserve [post]
+func (h *ReservationHandler) Reserve(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.service.Reserve(userID, paramID(c, "id"))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// GetMyReservations godoc
+// @Summary List my reservations
+// @Description List the signed-in user's reservations, newest first, with each waiting reservation's place in its queue
+// @Tags Reservations
+// @Produce json
+// @Param status query string false "Reservation status" Enums(waiting, ready, fulfilled, cancelled, expired)
+// @Param limit query int false "Maximum number of reservations to return (1-100)" default(20)
+// @Param offset query int false "Number of reservations to skip"
+// @Success 200 {object} dto.ReservationListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /reservations [get]
+func (h *ReservationHandler) GetMyReservations(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	query, errs := parseReservationQuery(c)
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+	query.UserID = userID
+
+	reservations, err := h.service.GetReservations(query)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reservations)
+}
+
+// Cancel godoc
+// @Summary Cancel a reservation
+// @Description Leave a book's queue. A copy held for the reservation passes to the next in the queue. Users cancel their own reservations; librarians and admins can cancel anyone's.
+// @Tags Reservations
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} model.Reservation
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /reservations/{id}/cancel [post]
+func (h *ReservationHandler) Cancel(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.service.Cancel(paramID(c, "id"), userID)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reservation)
+}
+
+// GetReservations godoc
+// @Summary List reservations
+// @Description List every user's reservations, newest first, optionally for one user or book
+// @Tags Reservations
+// @Produce json
+// @Param user_id query int false "User"
+// @Param book_id query int false "Book"
+// @Param status query string false "Reservation status" Enums(waiting, ready, fulfilled, cancelled, expired)
+// @Param limit query int false "Maximum number of reservations to return (1-100)" default(20)
+// @Param offset query int false "Number of reservations to skip"
+// @Success 200 {object} dto.ReservationListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/reservations [get]
+func (h *ReservationHandler) GetReservations(c *gin.Context) {
+	query, errs := parseReservationQuery(c)
+	if raw, ok := c.GetQuery("user_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil || id == 0 {
+			errs = append(errs, FieldError{Field: "user_id", Message: "must be a positive integer"})
+		} else {
+			query.UserID = uint(id)
+		}
+	}
+	if raw, ok := c.GetQuery("book_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil || id == 0 {
+			errs = append(errs, FieldError{Field: "book_id", Message: "must be a positive integer"})
+		} else {
+			query.BookID = uint(id)
+		}
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	reservations, err := h.service.GetReservations(query)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reservations)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	service *service.RetentionService
+}
+
+func NewRetentionHandler(s *service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: s}
+}
+
+func (h *RetentionHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/retention")
+	group.GET("/preview", h.PreviewPurge)
+	group.POST("/purge", h.Purge)
+}
+
+// PreviewPurge godoc
+// @Summary Preview retention purge
+// @Description Dry run listing the soft-deleted books, and how many dependent rows, the next purge would permanently remove
+// @Tags Retention
+// @Produce json
+// @Success 200 {object} dto.RetentionPreview
+// @Failure 500 {object} apperror.Body
+// @Router /admin/retention/preview [get]
+func (h *RetentionHandler) PreviewPurge(c *gin.Context) {
+	preview, err := h.service.Preview()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// Purge godoc
+// @Summary Run retention purge
+// @Description Permanently remove books soft-deleted longer than the retention period, and their dependents, without waiting for the scheduled run
+// @Tags Retention
+// @Produce json
+// @Success 200 {object} dto.PurgeResult
+// @Failure 500 {object} apperror.Body
+// @Router /admin/retention/purge [post]
+func (h *RetentionHandler) Purge(c *gin.Context) {
+	result, err := h.service.Purge()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
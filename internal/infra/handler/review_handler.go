@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReviewLimit = 20
+	maxReviewLimit     = 100
+)
+
+type ReviewHandler struct {
+	service *service.ReviewService
+}
+
+func NewReviewHandler(s *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{service: s}
+}
+
+func (h *ReviewHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/books/:id/reviews", h.GetReviews)
+	routes.Private.POST("/books/:id/reviews", h.AddReview)
+}
+
+func respondReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "book not found")
+	case errors.Is(err, service.ErrAlreadyReviewed):
+		respondError(c, http.StatusConflict, err)
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetReviews godoc
+// @Summary List book reviews
+// @Description List a book's reviews, newest first. The book's average rating and rating count are part of the book itself.
+// @Tags Reviews
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param limit query int false "Maximum number of reviews to return (1-100)" default(20)
+// @Param offset query int false "Number of reviews to skip"
+// @Success 200 {object} dto.ReviewListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/reviews [get]
+func (h *ReviewHandler) GetReviews(c *gin.Context) {
+	var errs []FieldError
+	bookID := paramID(c, "id")
+	if bookID == 0 {
+		errs = append(errs, FieldError{Field: "id", Message: "must be a positive integer"})
+	}
+	limit := defaultReviewLimit
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxReviewLimit {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxReviewLimit)})
+		}
+		limit = n
+	}
+	var offset int
+	if raw, ok := c.GetQuery("offset"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			errs = append(errs, FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		}
+		offset = n
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	reviews, err := h.service.GetReviews(c.Request.Context(), bookID, limit, offset)
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// AddReview godoc
+// @Summary Review a book
+// @Description Rate a book from 1 to 5 with optional text. Each user reviews a book once; the book's average rating is updated straight away.
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param review body dto.ReviewRequest true "Review"
+// @Success 201 {object} model.Review
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/reviews [post]
+func (h *ReviewHandler) AddReview(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	bookID := paramID(c, "id")
+	if bookID == 0 {
+		respondValidationError(c, []FieldError{{Field: "id", Message: "must be a positive integer"}})
+		return
+	}
+	var req dto.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	review, err := h.service.AddReview(c.Request.Context(), bookID, userID, req)
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, review)
+}
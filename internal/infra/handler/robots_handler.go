@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RobotsHandler struct {
+	body string
+}
+
+// NewRobotsHandler serves robotsTxt as is when set, or else a robots.txt
+// disallowing the given path prefixes for every crawler and pointing at
+// the sitemap under baseURL
+func NewRobotsHandler(robotsTxt string, disallow []string, baseURL string) *RobotsHandler {
+	if robotsTxt != "" {
+		return &RobotsHandler{body: robotsTxt}
+	}
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	for _, path := range disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\nSitemap: " + baseURL + "/sitemap.xml\n")
+	return &RobotsHandler{body: b.String()}
+}
+
+func (h *RobotsHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/robots.txt", h.GetRobots)
+}
+
+// GetRobots godoc
+// @Summary robots.txt
+// @Description Crawling rules for search engines, configured under crawlers
+// @Tags Sitemap
+// @Produce plain
+// @Success 200 {string} string
+// @Router /robots.txt [get]
+func (h *RobotsHandler) GetRobots(c *gin.Context) {
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(h.body))
+}
@@ -0,0 +1,11 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+// Routes splits the API by exposure. Public holds the safe read endpoints
+// (listing, detail, search and link resolution); everything else goes on
+// Private, which requires an authenticated caller in public read-only mode.
+type Routes struct {
+	Public  *gin.RouterGroup
+	Private *gin.RouterGroup
+}
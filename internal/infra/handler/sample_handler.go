@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/service"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SampleHandler struct {
+	service *service.SampleService
+}
+
+func NewSampleHandler(s *service.SampleService) *SampleHandler {
+	return &SampleHandler{service: s}
+}
+
+func (h *SampleHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/books/:id/sample", h.GetSample)
+
+	group := routes.Private.Group("/books/:id/sample")
+	group.PUT("", h.SetSample)
+	group.DELETE("", h.DeleteSample)
+}
+
+func respondSampleError(c *gin.Context, err error) {
+	var tooLarge *service.SampleTooLargeError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "not found")
+	case errors.As(err, &tooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, err)
+	case errors.Is(err, service.ErrInvalidSampleAccess):
+		respondValidationError(c, []FieldError{{Field: "access", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidSample), errors.Is(err, service.ErrEmptySample):
+		respondValidationError(c, []FieldError{{Field: "file", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetSample godoc
+// @Summary Get a book sample
+// @Description Read the first-chapter sample of a book as plain text or PDF. Samples are open to anyone once published, independently of how the full book can be obtained; books with one have has_sample set in search results.
+// @Tags Books
+// @Produce plain,application/pdf
+// @Param id path int true "Book ID"
+// @Success 200 {file} file
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/sample [get]
+func (h *SampleHandler) GetSample(c *gin.Context) {
+	sample, err := h.service.GetPublicSample(c.Request.Context(), paramID(c, "id"))
+	if err != nil {
+		respondSampleError(c, err)
+		return
+	}
+
+	filename := sample.Filename
+	if filename == "" {
+		filename = "sample-" + strconv.Itoa(int(sample.BookID)) + sample.Format.Extension()
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, sample.Format.ContentType(), sample.Content)
+}
+
+// SetSample godoc
+// @Summary Attach a book sample
+// @Description Upload a first-chapter sample for a book, replacing any earlier one. Send it as the file field of a form or as a text/plain or application/pdf request body. Hidden samples are stored but not served.
+// @Tags Books
+// @Accept mpfd,plain,application/pdf
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param access query string false "Who may read the sample (default public)" Enums(public, hidden)
+// @Param file formData file false "Sample file"
+// @Success 200 {object} model.BookSample
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 413 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/sample [put]
+func (h *SampleHandler) SetSample(c *gin.Context) {
+	body := c.Request.Body
+	filename := ""
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			respondValidationError(c, []FieldError{{Field: "file", Message: "is required"}})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			respondValidationError(c, []FieldError{{Field: "file", Message: err.Error()}})
+			return
+		}
+		defer file.Close()
+		body = file
+		filename = filepath.Base(header.Filename)
+	}
+
+	// One byte over the limit is enough to reject the sample
+	content, err := io.ReadAll(io.LimitReader(body, h.service.MaxSize()+1))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	sample, err := h.service.SetSample(c.Request.Context(), paramID(c, "id"), filename, content, model.SampleAccess(c.Query("access")))
+	if err != nil {
+		respondSampleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sample)
+}
+
+// DeleteSample godoc
+// @Summary Remove a book sample
+// @Description Delete a book's sample
+// @Tags Books
+// @Param id path int true "Book ID"
+// @Success 204
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/sample [delete]
+func (h *SampleHandler) DeleteSample(c *gin.Context) {
+	if err := h.service.DeleteSample(paramID(c, "id")); err != nil {
+		respondSampleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+)
+
+type SimilarBooksHandler struct {
+	service   *service.SimilarBooksService
+	responses *cache.ResponseCache
+}
+
+func NewSimilarBooksHandler(s *service.SimilarBooksService, responses *cache.ResponseCache) *SimilarBooksHandler {
+	return &SimilarBooksHandler{service: s, responses: responses}
+}
+
+func (h *SimilarBooksHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/books/:id/similar", middleware.CacheResponses(h.responses, cache.TagBooks), h.GetSimilarBooks)
+}
+
+// GetSimilarBooks godoc
+// @Summary More like this
+// @Description List the books most like a book by content, most similar first. The content strategy compares category, author and the words of the title and description; the embedding strategy compares embeddings and needs semantic search to be configured. auto, the default unless configured otherwise or set by a recommendations experiment, uses embeddings when the book has one. The response names the strategy used.
+// @Tags Books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param strategy query string false "Similarity strategy" Enums(auto, content, embedding)
+// @Param limit query int false "Maximum number of books to return (1-50)" default(10)
+// @Success 200 {object} dto.SimilarBooksResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Failure 503 {object} apperror.Body
+// @Router /books/{id}/similar [get]
+func (h *SimilarBooksHandler) GetSimilarBooks(c *gin.Context) {
+	var errs []FieldError
+	strategy := dto.SimilarityStrategy(c.Query("strategy"))
+	if strategy != "" && !strategy.Valid() {
+		errs = append(errs, FieldError{Field: "strategy", Message: "must be one of auto, content, embedding"})
+	}
+	if variant, ok := middleware.ExperimentVariant(c, model.ExperimentRecommendations); ok && strategy == "" {
+		strategy = dto.SimilarityStrategy(variant.Params["strategy"])
+	}
+
+	// The kids profile only lists books rated for all ages, in short pages
+	var rating model.ContentRating
+	limit, limitCap := defaultSimilarLimit, maxSimilarLimit
+	if middleware.KidsProfile(c) {
+		rating = model.RatingAllAges
+		limitCap = kidsMaxLimit
+		middleware.ProfileFiltered(c)
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > limitCap {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(limitCap)})
+		}
+		limit = n
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	resp, err := h.service.GetSimilarBooks(c.Request.Context(), paramID(c, "id"), strategy, rating, limit)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "book not found")
+	case errors.Is(err, service.ErrBookNotEmbedded):
+		respondError(c, http.StatusConflict, err)
+	case errors.Is(err, service.ErrSemanticSearchDisabled):
+		respondError(c, http.StatusServiceUnavailable, err)
+	case err != nil:
+		respondError(c, http.StatusInternalServerError, err)
+	default:
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SitemapHandler struct {
+	service *service.SitemapService
+}
+
+func NewSitemapHandler(s *service.SitemapService) *SitemapHandler {
+	return &SitemapHandler{service: s}
+}
+
+func (h *SitemapHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/sitemap.xml", h.GetSitemapIndex)
+	routes.Public.GET("/sitemaps/books/:page", h.GetSitemapPage)
+}
+
+// GetSitemapIndex godoc
+// @Summary Sitemap index
+// @Description Sitemap index pointing at the paginated sitemaps of book detail URLs
+// @Tags Sitemap
+// @Produce xml
+// @Success 200 {string} string
+// @Failure 500 {object} apperror.Body
+// @Router /sitemap.xml [get]
+func (h *SitemapHandler) GetSitemapIndex(c *gin.Context) {
+	body, err := h.service.Index(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// GetSitemapPage godoc
+// @Summary Sitemap page
+// @Description One page of book detail URLs with their last modification time
+// @Tags Sitemap
+// @Produce xml
+// @Param page path string true "Page file, e.g. 1.xml"
+// @Success 200 {string} string
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /sitemaps/books/{page} [get]
+func (h *SitemapHandler) GetSitemapPage(c *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(c.Param("page"), ".xml"))
+	if err != nil {
+		respondMessage(c, http.StatusNotFound, "sitemap page not found")
+		return
+	}
+
+	body, err := h.service.Page(c.Request.Context(), page)
+	if errors.Is(err, service.ErrSitemapPageNotFound) {
+		respondMessage(c, http.StatusNotFound, "sitemap page not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
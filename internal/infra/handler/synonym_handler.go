@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SynonymHandler struct {
+	service *service.SynonymService
+}
+
+func NewSynonymHandler(s *service.SynonymService) *SynonymHandler {
+	return &SynonymHandler{service: s}
+}
+
+func (h *SynonymHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/synonyms")
+	group.GET("", h.GetSynonyms)
+	group.POST("", h.CreateSynonym)
+	group.PUT("/:id", h.UpdateSynonym)
+	group.DELETE("/:id", h.DeleteSynonym)
+}
+
+// GetSynonyms godoc
+// @Summary Get all synonyms
+// @Description Get the synonym dictionary applied to book searches
+// @Tags Synonyms
+// @Produce json
+// @Success 200 {array} dto.SynonymResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/synonyms [get]
+func (h *SynonymHandler) GetSynonyms(c *gin.Context) {
+	synonyms, err := h.service.GetSynonyms()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, synonyms)
+}
+
+// CreateSynonym godoc
+// @Summary Create synonym
+// @Description Add a synonym pair, matched in both directions by book search
+// @Tags Synonyms
+// @Accept json
+// @Produce json
+// @Param synonym body dto.SynonymRequest true "Synonym pair"
+// @Success 201 {object} dto.SynonymResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/synonyms [post]
+func (h *SynonymHandler) CreateSynonym(c *gin.Context) {
+	var req dto.SynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.service.CreateSynonym(req)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// UpdateSynonym godoc
+// @Summary Update synonym
+// @Description Replace a synonym pair by ID
+// @Tags Synonyms
+// @Accept json
+// @Produce json
+// @Param id path int true "Synonym ID"
+// @Param synonym body dto.SynonymRequest true "Synonym pair"
+// @Success 200 {object} dto.SynonymResponse
+// @Failure 400 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/synonyms/{id} [put]
+func (h *SynonymHandler) UpdateSynonym(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req dto.SynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.service.UpdateSynonym(uint(id), req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondMessage(c, http.StatusNotFound, "synonym not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteSynonym godoc
+// @Summary Delete synonym
+// @Description Delete a synonym pair by ID
+// @Tags Synonyms
+// @Produce json
+// @Param id path int true "Synonym ID"
+// @Success 204 "No Content"
+// @Failure 500 {object} apperror.Body
+// @Router /admin/synonyms/{id} [delete]
+func (h *SynonymHandler) DeleteSynonym(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.DeleteSynonym(uint(id)); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
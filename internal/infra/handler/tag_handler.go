@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service *service.TagService
+}
+
+func NewTagHandler(s *service.TagService) *TagHandler {
+	return &TagHandler{service: s}
+}
+
+func (h *TagHandler) RegisterRoutes(routes Routes) {
+	routes.Public.GET("/tags", h.GetTags)
+
+	private := routes.Private.Group("/books/:id/tags")
+	private.POST("", h.AddTag)
+	private.DELETE("/:tagId", h.RemoveTag)
+}
+
+func respondTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTagBookNotFound), errors.Is(err, service.ErrTagNotOnBook):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrBlankTag):
+		respondValidationError(c, []FieldError{{Field: "name", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetTags godoc
+// @Summary List tags
+// @Description Every tag by name with its number of books, for the tags filter of the book list
+// @Tags Tags
+// @Produce json
+// @Success 200 {array} dto.TagResponse
+// @Failure 500 {object} apperror.Body
+// @Router /tags [get]
+func (h *TagHandler) GetTags(c *gin.Context) {
+	tags, err := h.service.GetTags()
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// AddTag godoc
+// @Summary Tag a book
+// @Description Put a tag on a book by name. A tag that does not exist yet is created; names are unique ignoring case. Tagging a book with a tag it already has changes nothing.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param tag body dto.TagRequest true "Tag"
+// @Success 200 {array} model.Tag
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/tags [post]
+func (h *TagHandler) AddTag(c *gin.Context) {
+	var req dto.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	tags, err := h.service.AddTag(c.Request.Context(), paramID(c, "id"), req)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// RemoveTag godoc
+// @Summary Untag a book
+// @Description Take a tag off a book. The tag itself is kept.
+// @Tags Tags
+// @Param id path int true "Book ID"
+// @Param tagId path int true "Tag ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /books/{id}/tags/{tagId} [delete]
+func (h *TagHandler) RemoveTag(c *gin.Context) {
+	if err := h.service.RemoveTag(c.Request.Context(), paramID(c, "id"), paramID(c, "tagId")); err != nil {
+		respondTagError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,431 @@
+package handler
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUserLimit = 50
+	maxUserLimit     = 200
+)
+
+type UserHandler struct {
+	service *service.UserService
+}
+
+func NewUserHandler(s *service.UserService) *UserHandler {
+	return &UserHandler{service: s}
+}
+
+func (h *UserHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/users")
+	group.GET("", h.GetUsers)
+	group.POST("", h.CreateUser)
+	group.GET("/:id", h.GetUser)
+	group.PUT("/:id", h.UpdateUser)
+	group.DELETE("/:id", h.DeleteUser)
+	group.POST("/:id/disable", h.DisableUser)
+	group.POST("/:id/enable", h.EnableUser)
+	group.POST("/:id/password-reset", h.RequirePasswordReset)
+	group.PUT("/:id/role", h.SetRole)
+	group.PUT("/:id/card", h.SetCard)
+	routes.Private.GET("/admin/patrons/card/:number", h.GetPatronByCard)
+
+	routes.Public.GET("/users/:id", h.GetProfile)
+	routes.Private.GET("/me", h.GetAccount)
+	routes.Private.PUT("/me", h.UpdateAccount)
+}
+
+func respondUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "user not found")
+	case errors.Is(err, service.ErrCardNotFound):
+		respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrEmailTaken), errors.Is(err, service.ErrCardTaken):
+		respondError(c, http.StatusConflict, err)
+	case errors.Is(err, service.ErrProfilePrivate):
+		// Private profiles are indistinguishable from an unknown user
+		respondMessage(c, http.StatusNotFound, "user not found")
+	case errors.Is(err, service.ErrInvalidUserStatus):
+		respondValidationError(c, []FieldError{{Field: "status", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidCardNumber):
+		respondValidationError(c, []FieldError{{Field: "card_number", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetUsers godoc
+// @Summary List users
+// @Description List accounts, oldest first, optionally searching by whole email, or part of a name or card number, or filtering by status
+// @Tags Users
+// @Produce json
+// @Param q query string false "Whole email, or part of a name or card number" maxlength(200)
+// @Param status query string false "Account status" Enums(active, disabled)
+// @Param limit query int false "Maximum number of users to return (1-200)" default(50)
+// @Param offset query int false "Number of users to skip"
+// @Success 200 {object} dto.UserListResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users [get]
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	query := dto.UserQuery{
+		Search: c.Query("q"),
+		Status: model.UserStatus(c.Query("status")),
+		Limit:  defaultUserLimit,
+	}
+	var errs []FieldError
+	if msg := checkSearch(query.Search); msg != "" {
+		errs = append(errs, FieldError{Field: "q", Message: msg})
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxUserLimit {
+			errs = append(errs, FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxUserLimit)})
+		} else {
+			query.Limit = limit
+		}
+	}
+	if raw, ok := c.GetQuery("offset"); ok {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			errs = append(errs, FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		} else {
+			query.Offset = offset
+		}
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	users, err := h.service.GetUsers(query)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// CreateUser godoc
+// @Summary Create user
+// @Description Create an active account
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param user body dto.UserRequest true "User"
+// @Success 201 {object} model.User
+// @Failure 400 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req dto.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := h.service.CreateUser(req)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+// GetUser godoc
+// @Summary Get user
+// @Description Get an account with counts of its favorites, views, RSVPs, inter-library loan requests, organizations and devices, and any pending deletion
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserSummary
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	summary, err := h.service.GetUser(paramID(c, "id"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// UpdateUser godoc
+// @Summary Update user
+// @Description Change an account's email and name
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param user body dto.UserRequest true "User"
+// @Success 200 {object} model.User
+// @Failure 400 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	var req dto.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := h.service.UpdateUser(paramID(c, "id"), req)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser godoc
+// @Summary Delete user
+// @Description Erase the user's personal data right away, without the grace period of a self-service deletion, and remove the account. Favorite counts are corrected, RSVP seats are released to the waitlist, reservations are cancelled, returned loans are deleted and loans still out are kept without the user, and the erasure is recorded in the account deletion audit trail.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.AccountDeletionResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	deletion, err := h.service.DeleteUser(paramID(c, "id"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deletion)
+}
+
+// DisableUser godoc
+// @Summary Disable user
+// @Description Block the account; requests acting as the user are refused with 403 until it is enabled again. Its data is kept.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body dto.DisableUserRequest false "Reason"
+// @Success 200 {object} model.User
+// @Failure 400 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id}/disable [post]
+func (h *UserHandler) DisableUser(c *gin.Context) {
+	var req dto.DisableUserRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	user, err := h.service.DisableUser(paramID(c, "id"), req.Reason)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// EnableUser godoc
+// @Summary Enable user
+// @Description Let a disabled account be used again
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} model.User
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id}/enable [post]
+func (h *UserHandler) EnableUser(c *gin.Context) {
+	user, err := h.service.EnableUser(paramID(c, "id"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// RequirePasswordReset godoc
+// @Summary Force password reset
+// @Description Make the user choose a new password the next time they sign in
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} model.User
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id}/password-reset [post]
+func (h *UserHandler) RequirePasswordReset(c *gin.Context) {
+	user, err := h.service.RequirePasswordReset(paramID(c, "id"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// GetProfile godoc
+// @Summary Get a user's profile
+// @Description Get another user's name and join date. Only available when the user has a public profile.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserProfile
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /users/{id} [get]
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		respondValidationError(c, []FieldError{{Field: "id", Message: "must be a positive integer"}})
+		return
+	}
+	profile, err := h.service.GetProfile(uint(id))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetAccount godoc
+// @Summary Get my account
+// @Description Get the signed-in user's account
+// @Tags Users
+// @Produce json
+// @Success 200 {object} model.User
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me [get]
+func (h *UserHandler) GetAccount(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	user, err := h.service.GetAccount(userID)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateAccount godoc
+// @Summary Update my account
+// @Description Change the signed-in user's email and name
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param user body dto.UserRequest true "User"
+// @Success 200 {object} model.User
+// @Failure 400 {object} apperror.Body
+// @Failure 401 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /me [put]
+func (h *UserHandler) UpdateAccount(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var req dto.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := h.service.UpdateUser(userID, req)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// SetRole godoc
+// @Summary Set user role
+// @Description Change which write endpoints the user may call: readers manage their own data, librarians also maintain the catalog, admins may do everything
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role body dto.UserRoleRequest true "Role"
+// @Success 200 {object} model.User
+// @Failure 400 {object} apperror.Body
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) SetRole(c *gin.Context) {
+	var req dto.UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := h.service.SetRole(paramID(c, "id"), req.Role)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// SetCard godoc
+// @Summary Issue library card
+// @Description Issue a library card number to the user, replacing any card they had, or take their card away with an empty number. Numbers are 8 to 19 digits ending in a Luhn check digit; spaces and hyphens are ignored.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param card body dto.CardRequest true "Card number"
+// @Success 200 {object} model.User
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 409 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/users/{id}/card [put]
+func (h *UserHandler) SetCard(c *gin.Context) {
+	var req dto.CardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := h.service.SetCard(paramID(c, "id"), req.CardNumber)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// GetPatronByCard godoc
+// @Summary Look up patron by card
+// @Description Get the account a library card number is issued to, with the same overview as GET /admin/users/{id}. Numbers with a wrong check digit are rejected without a lookup.
+// @Tags Users
+// @Produce json
+// @Param number path string true "Card number"
+// @Success 200 {object} dto.UserSummary
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/patrons/card/{number} [get]
+func (h *UserHandler) GetPatronByCard(c *gin.Context) {
+	summary, err := h.service.GetPatronByCard(c.Param("number"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"bms-go/internal/apperror"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldError describes a single invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is the body returned when request input fails validation
+type ValidationErrorResponse struct {
+	Error   string        `json:"error"`
+	Code    apperror.Code `json:"code"`
+	Details []FieldError  `json:"details"`
+}
+
+func respondValidationError(c *gin.Context, errs []FieldError) {
+	c.JSON(http.StatusBadRequest, ValidationErrorResponse{
+		Error:   "invalid request parameters",
+		Code:    apperror.CodeValidationFailed,
+		Details: errs,
+	})
+}
+
+// respondError answers with status, err's message and err's code, or the
+// generic code of status when err has none
+func respondError(c *gin.Context, status int, err error) {
+	c.JSON(status, apperror.Response(status, err))
+}
+
+// respondMessage answers with status, message and the generic code of
+// status
+func respondMessage(c *gin.Context, status int, message string) {
+	c.JSON(status, apperror.Message(status, message))
+}
+
+// parseIDList parses a comma-separated list of positive IDs, dropping
+// duplicates, and requires between minCount and maxCount of them
+func parseIDList(raw, field string, minCount, maxCount int) ([]uint, []FieldError) {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || id == 0 {
+			return nil, []FieldError{{Field: field, Message: "invalid id " + strconv.Quote(part)}}
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+
+	if len(ids) < minCount || len(ids) > maxCount {
+		return nil, []FieldError{{Field: field, Message: "must list between " + strconv.Itoa(minCount) + " and " + strconv.Itoa(maxCount) + " distinct ids"}}
+	}
+	return ids, nil
+}
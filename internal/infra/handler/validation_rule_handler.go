@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ValidationRuleHandler struct {
+	service *service.ValidationRuleService
+}
+
+func NewValidationRuleHandler(s *service.ValidationRuleService) *ValidationRuleHandler {
+	return &ValidationRuleHandler{service: s}
+}
+
+func (h *ValidationRuleHandler) RegisterRoutes(routes Routes) {
+	group := routes.Private.Group("/admin/validation-rules")
+	group.GET("", h.GetRules)
+	group.POST("", h.CreateRule)
+	group.PUT("/:id", h.UpdateRule)
+	group.DELETE("/:id", h.DeleteRule)
+}
+
+func respondValidationRuleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondMessage(c, http.StatusNotFound, "validation rule not found")
+	case errors.Is(err, service.ErrUnknownRuleField):
+		respondValidationError(c, []FieldError{{Field: "field", Message: err.Error()}})
+	case errors.Is(err, service.ErrInvalidRule):
+		respondValidationError(c, []FieldError{{Field: "kind", Message: err.Error()}})
+	default:
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// respondRuleViolations answers 400 listing the validation rules a book
+// breaks, and reports whether err was such a violation
+func respondRuleViolations(c *gin.Context, err error) bool {
+	var violation *service.RuleViolationError
+	if !errors.As(err, &violation) {
+		return false
+	}
+	errs := make([]FieldError, len(violation.Violations))
+	for i, v := range violation.Violations {
+		errs[i] = FieldError{Field: v.Field, Message: v.Message}
+	}
+	respondValidationError(c, errs)
+	return true
+}
+
+// GetRules godoc
+// @Summary List validation rules
+// @Description List the cataloging rules books must meet when created, updated or imported, including disabled ones
+// @Tags Validation Rules
+// @Produce json
+// @Success 200 {array} model.ValidationRule
+// @Failure 500 {object} apperror.Body
+// @Router /admin/validation-rules [get]
+func (h *ValidationRuleHandler) GetRules(c *gin.Context) {
+	rules, err := h.service.GetRules()
+	if err != nil {
+		respondValidationRuleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule godoc
+// @Summary Create validation rule
+// @Description Add a rule: pattern (a text field must match a regular expression), required (a field must be set) or range (a number such as published_year must lie between min and max). A media_type limits the rule to books of that type.
+// @Tags Validation Rules
+// @Accept json
+// @Produce json
+// @Param rule body dto.ValidationRuleRequest true "Rule"
+// @Success 201 {object} model.ValidationRule
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 500 {object} apperror.Body
+// @Router /admin/validation-rules [post]
+func (h *ValidationRuleHandler) CreateRule(c *gin.Context) {
+	var req dto.ValidationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	rule, err := h.service.CreateRule(req)
+	if err != nil {
+		respondValidationRuleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule godoc
+// @Summary Update validation rule
+// @Description Replace a rule, e.g. to disable it. Books already in the catalog are not rechecked.
+// @Tags Validation Rules
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param rule body dto.ValidationRuleRequest true "Rule"
+// @Success 200 {object} model.ValidationRule
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/validation-rules/{id} [put]
+func (h *ValidationRuleHandler) UpdateRule(c *gin.Context) {
+	var req dto.ValidationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	rule, err := h.service.UpdateRule(paramID(c, "id"), req)
+	if err != nil {
+		respondValidationRuleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary Delete validation rule
+// @Tags Validation Rules
+// @Param id path int true "Rule ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} apperror.Body
+// @Failure 500 {object} apperror.Body
+// @Router /admin/validation-rules/{id} [delete]
+func (h *ValidationRuleHandler) DeleteRule(c *gin.Context) {
+	if err := h.service.DeleteRule(paramID(c, "id")); err != nil {
+		respondValidationRuleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
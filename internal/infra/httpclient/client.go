@@ -0,0 +1,275 @@
+// Package httpclient is the shared client for outbound HTTP calls to other
+// services. It adds per-attempt timeouts, retries with exponential backoff,
+// a circuit breaker and a rate limit per host, and request counters exposed
+// through expvar.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRetryAfter caps how long a Retry-After header can make a retry wait
+const maxRetryAfter = 30 * time.Second
+
+// ErrCircuitOpen is returned without calling a host whose recent requests
+// kept failing, until its cooldown has passed
+var ErrCircuitOpen = errors.New("circuit open: host is failing")
+
+// metrics counts outbound requests per host as "<host>.<counter>" at
+// /debug/vars
+var metrics = expvar.NewMap("outbound_http")
+
+// Config tunes a Client. Zero values disable the matching feature.
+type Config struct {
+	// Timeout bounds each attempt, including reading the response body
+	Timeout time.Duration
+	// MaxRetries is how many times a failed attempt is repeated
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry; it doubles after
+	// each retry and has jitter added
+	RetryBackoff time.Duration
+	// RateLimit is the most requests per second sent to a single host
+	RateLimit float64
+	// BreakerThreshold is how many consecutive failures open a host's
+	// circuit, and BreakerCooldown how long it stays open
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Client sends requests like http.Client, adding retries, circuit breaking
+// and rate limiting per host. It is safe for concurrent use.
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// host is the breaker and limiter state of one destination
+type host struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+	nextSlot  time.Time
+}
+
+// DefaultConfig is used by commands that do not read the app config
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          30 * time.Second,
+		MaxRetries:       2,
+		RetryBackoff:     500 * time.Millisecond,
+		RateLimit:        10,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+func New(cfg Config) *Client {
+	return &Client{
+		cfg:   cfg,
+		http:  &http.Client{Timeout: cfg.Timeout},
+		hosts: make(map[string]*host),
+	}
+}
+
+// Get is a convenience for a GET of url bound to ctx
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends req, retrying network errors and 429, 502, 503 and 504
+// responses when the request can be replayed: it is idempotent, or its body
+// can be rewound through GetBody. The last response or error is returned.
+// Every attempt ends by the deadline of req's context, so a request bound
+// to an incoming request's context never outlives what is left of that
+// request's timeout, and no retry is made that could not start before it.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	name := req.URL.Host
+	ctx := req.Context()
+
+	retries := 0
+	if replayable(req) {
+		retries = c.cfg.MaxRetries
+	}
+	backoff := c.cfg.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		if err := c.acquire(ctx, name); err != nil {
+			return nil, err
+		}
+
+		metrics.Add(name+".requests", 1)
+		resp, err := c.http.Do(req)
+		failed := err != nil || resp.StatusCode >= 500
+		c.record(name, failed)
+
+		if attempt >= retries || !retryable(resp, err) || ctx.Err() != nil {
+			if failed {
+				metrics.Add(name+".failures", 1)
+			}
+			return resp, err
+		}
+
+		wait := jitter(backoff)
+		if after := retryAfter(resp); after > 0 {
+			wait = after
+		}
+		// A retry that cannot start before the caller's deadline would only
+		// fail with it, so the caller gets this attempt's outcome instead
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			metrics.Add(name+".out_of_budget", 1)
+			if failed {
+				metrics.Add(name+".failures", 1)
+			}
+			return resp, err
+		}
+		if resp != nil {
+			// Drain so the connection can be reused for the retry
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		metrics.Add(name+".retries", 1)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// acquire waits for the host's next rate limit slot and fails fast while
+// its circuit is open. Once the cooldown has passed a single probe request
+// is let through; its outcome closes or reopens the circuit.
+func (c *Client) acquire(ctx context.Context, name string) error {
+	c.mu.Lock()
+	h := c.hosts[name]
+	if h == nil {
+		h = &host{}
+		c.hosts[name] = h
+	}
+
+	now := time.Now()
+	if c.cfg.BreakerThreshold > 0 && h.failures >= c.cfg.BreakerThreshold {
+		if now.Before(h.openUntil) || h.probing {
+			c.mu.Unlock()
+			metrics.Add(name+".rejected", 1)
+			return ErrCircuitOpen
+		}
+		h.probing = true
+	}
+
+	var wait time.Duration
+	if c.cfg.RateLimit > 0 {
+		slot := h.nextSlot
+		if slot.Before(now) {
+			slot = now
+		}
+		wait = slot.Sub(now)
+		h.nextSlot = slot.Add(time.Duration(float64(time.Second) / c.cfg.RateLimit))
+	}
+	c.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		// Give up the probe so a later request can take it
+		c.mu.Lock()
+		h.probing = false
+		c.mu.Unlock()
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// record updates the host's breaker with the outcome of an attempt
+func (c *Client) record(name string, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := c.hosts[name]
+	h.probing = false
+	if !failed {
+		h.failures = 0
+		return
+	}
+	h.failures++
+	if c.cfg.BreakerThreshold > 0 && h.failures >= c.cfg.BreakerThreshold {
+		h.openUntil = time.Now().Add(c.cfg.BreakerCooldown)
+	}
+}
+
+func replayable(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	wait := time.Duration(seconds) * time.Second
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
+
+// jitter spreads d by up to half again so clients retrying together do not
+// hit the host at the same moment
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
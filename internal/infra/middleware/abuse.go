@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bookDetailRoute is the route whose id the abuse detector tracks to spot
+// clients walking the catalog
+const bookDetailRoute = "/books/:id"
+
+// Abuse refuses blocked clients with 403 and reports every other request to
+// the abuse detector
+func Abuse(abuse *service.AbuseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := ClientID(c)
+		if abuse.IsBlocked(client) {
+			c.AbortWithStatusJSON(http.StatusForbidden, apperror.Message(http.StatusForbidden, "client is blocked"))
+			return
+		}
+
+		var bookID uint
+		if c.FullPath() == bookDetailRoute {
+			if id, err := strconv.ParseUint(c.Param("id"), 10, 0); err == nil {
+				bookID = uint(id)
+			}
+		}
+		abuse.Observe(client, bookID)
+		c.Next()
+	}
+}
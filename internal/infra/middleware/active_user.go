@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActiveUser rejects requests acting as a user whose account an
+// administrator disabled. It must run after whatever sets the user.
+func ActiveUser(users *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := UserID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		disabled, err := users.IsDisabled(id)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, apperror.Response(http.StatusInternalServerError, err))
+			return
+		}
+		if disabled {
+			c.AbortWithStatusJSON(http.StatusForbidden, apperror.Message(http.StatusForbidden, "account is disabled"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticator checks one kind of credential. It reports present=false when
+// the request does not carry that kind of credential, and an error when it
+// carries one that is not valid.
+type Authenticator func(c *gin.Context) (present bool, err error)
+
+// RequireAuth lets a request through once one of authenticators accepts its
+// credentials. Invalid credentials are rejected even if another kind could
+// have been tried, so a bad signature never falls back to anonymous access.
+func RequireAuth(authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, authenticate := range authenticators {
+			present, err := authenticate(c)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, apperror.Response(http.StatusUnauthorized, err))
+				return
+			}
+			if present {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, apperror.Message(http.StatusUnauthorized, "authentication required"))
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+)
+
+const clientIPKey = "client_ip"
+
+// ResolveClientIP works out the address of the client once per request,
+// for ClientIP to return. It should run first, so every later middleware
+// sees the same address.
+func ResolveClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(clientIPKey, resolveClientIP(c))
+		c.Next()
+	}
+}
+
+// ClientIP returns the address of the client making the request. Gin takes
+// it from the forwarding headers only when the request came through one of
+// the trusted proxies, walking X-Forwarded-For from the right past them;
+// otherwise it is the address of the connection. IPv4 addresses written as
+// IPv6 are unmapped, so one client always has one address.
+func ClientIP(c *gin.Context) string {
+	if ip := c.GetString(clientIPKey); ip != "" {
+		return ip
+	}
+	return resolveClientIP(c)
+}
+
+func resolveClientIP(c *gin.Context) string {
+	raw := c.ClientIP()
+	addr, err := netip.ParseAddr(raw)
+	if err != nil {
+		return raw
+	}
+	return addr.Unmap().WithZone("").String()
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// OnRoutes runs handler only for requests matching one of the route
+// patterns, as registered (e.g. "/books/:id"), and passes others through
+func OnRoutes(routes []string, handler gin.HandlerFunc) gin.HandlerFunc {
+	match := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		match[r] = true
+	}
+	return func(c *gin.Context) {
+		if match[c.FullPath()] {
+			handler(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// NoIndex asks search engines not to index the response
+func NoIndex() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("X-Robots-Tag", "noindex")
+		c.Next()
+	}
+}
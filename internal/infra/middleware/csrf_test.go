@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/testutil"
+	"net/http"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	const token = "token-from-the-cookie"
+
+	tests := []struct {
+		name    string
+		method  string
+		session bool
+		cookie  string // CSRF cookie sent; empty sends none
+		header  string
+		want    int
+	}{
+		{"no session cookie", http.MethodPost, false, "", "", http.StatusOK},
+		{"safe method", http.MethodGet, true, "", "", http.StatusOK},
+		{"no csrf cookie yet", http.MethodPost, true, "", token, http.StatusForbidden},
+		{"missing header", http.MethodPost, true, token, "", http.StatusForbidden},
+		{"token mismatch", http.MethodPost, true, token, "token-from-another-site", http.StatusForbidden},
+		{"token mismatch on delete", http.MethodDelete, true, token, token + "x", http.StatusForbidden},
+		{"matching token", http.MethodPost, true, token, token, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testutil.Router(newAuth(), routes(func(r handler.Routes) {
+				r.Public.Use(middleware.CSRF("session"))
+				r.Public.Handle(tt.method, "/me/favorites", ok)
+			}))
+			req := testutil.NewRequest(t, tt.method, "/me/favorites", nil)
+			if tt.session {
+				req.AddCookie(&http.Cookie{Name: "session", Value: "session-id"})
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(middleware.CSRFHeader, tt.header)
+			}
+
+			rec := testutil.Serve(router, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusForbidden {
+				testutil.AssertJSON(t, rec, http.StatusForbidden, `{"code": "FORBIDDEN", "error": "missing or invalid csrf token"}`)
+			}
+
+			// Sessions without a token are issued one to echo back
+			issued := false
+			for _, c := range rec.Result().Cookies() {
+				issued = issued || (c.Name == middleware.CSRFCookie && c.Value != "")
+			}
+			if want := tt.session && tt.cookie == ""; issued != want {
+				t.Errorf("csrf cookie issued = %v, want %v", issued, want)
+			}
+		})
+	}
+}
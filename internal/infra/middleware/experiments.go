@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExperimentsHeader lists the variants a response was built with, e.g.
+// "search_ranking=v2, recommendations=content", so clients and log
+// pipelines can attribute what they see to an experiment arm
+const ExperimentsHeader = "X-Experiments"
+
+const (
+	experimentsKey       = "experiments"
+	experimentsHeaderKey = "experiments_header"
+)
+
+// Experiments buckets every request into a variant of each running
+// experiment and tags the response with them. It must run after
+// authentication so signed-in users keep their variant across devices.
+// Failing to load the experiments serves the request without any.
+func Experiments(experiments *service.ExperimentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assignments, err := experiments.Assign(ClientID(c))
+		if err != nil {
+			log.Printf("Failed to assign experiments: %v", err)
+		}
+		if len(assignments) > 0 {
+			tags := make([]string, len(assignments))
+			for i, a := range assignments {
+				tags[i] = a.Experiment + "=" + a.Variant
+			}
+			header := strings.Join(tags, ", ")
+			c.Set(experimentsKey, assignments)
+			c.Set(experimentsHeaderKey, header)
+			c.Header(ExperimentsHeader, header)
+		}
+		c.Next()
+	}
+}
+
+// ExperimentVariant returns the variant of experiment the request was
+// bucketed into, if that experiment is running
+func ExperimentVariant(c *gin.Context, experiment string) (dto.ExperimentAssignment, bool) {
+	v, ok := c.Get(experimentsKey)
+	if !ok {
+		return dto.ExperimentAssignment{}, false
+	}
+	for _, a := range v.([]dto.ExperimentAssignment) {
+		if a.Experiment == experiment {
+			return a, true
+		}
+	}
+	return dto.ExperimentAssignment{}, false
+}
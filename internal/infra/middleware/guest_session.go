@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GuestSessionHeader lets clients that keep their own state, such as apps,
+// name their guest session with an ID they generated instead of a cookie
+const GuestSessionHeader = "X-Guest-Session"
+
+const guestSessionKey = "guest_session"
+
+// guestSessionPattern is what a client-generated session ID may look like;
+// long enough that it cannot be guessed when generated randomly
+var guestSessionPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,64}$`)
+
+// GuestSession identifies visitors who have not signed in, from a session
+// cookie signed with secret or from GuestSessionHeader. The two are kept
+// apart so a client-chosen ID can never name a cookie session. When issue
+// is set, unsafe requests without a session get a new cookie, and those
+// with one have it extended to ttl. Without a secret, cookies are neither
+// issued nor accepted.
+func GuestSession(secret, cookie string, ttl time.Duration, issue bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id := c.GetHeader(GuestSessionHeader); guestSessionPattern.MatchString(id) {
+			c.Set(guestSessionKey, "client:"+id)
+			c.Next()
+			return
+		}
+		if secret == "" {
+			c.Next()
+			return
+		}
+
+		id := ""
+		if value, err := c.Cookie(cookie); err == nil {
+			id = verifyGuestSession(secret, value)
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if issue {
+				if id == "" {
+					var err error
+					if id, err = newGuestSessionID(); err != nil {
+						c.AbortWithStatusJSON(http.StatusInternalServerError, apperror.Response(http.StatusInternalServerError, err))
+						return
+					}
+				}
+				http.SetCookie(c.Writer, &http.Cookie{
+					Name:     cookie,
+					Value:    id + "." + signGuestSession(secret, id),
+					Path:     "/",
+					MaxAge:   int(ttl.Seconds()),
+					HttpOnly: true,
+					Secure:   c.Request.TLS != nil,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+		}
+		if id != "" {
+			c.Set(guestSessionKey, "cookie:"+id)
+		}
+		c.Next()
+	}
+}
+
+// GuestSessionID returns the request's guest session, if it has one
+func GuestSessionID(c *gin.Context) (string, bool) {
+	id := c.GetString(guestSessionKey)
+	return id, id != ""
+}
+
+// ClearGuestSession expires the guest session cookie, once its favorites
+// belong to an account
+func ClearGuestSession(c *gin.Context, cookie string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     cookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func newGuestSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func signGuestSession(secret, id string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyGuestSession returns the session ID of a signed cookie value, or ""
+// when the signature does not match
+func verifyGuestSession(secret, value string) string {
+	id, sig, ok := strings.Cut(value, ".")
+	if !ok || id == "" || !hmac.Equal([]byte(sig), []byte(signGuestSession(secret, id))) {
+		return ""
+	}
+	return id
+}
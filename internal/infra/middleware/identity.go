@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const userIDKey = "user_id"
+
+// SetUserID records the authenticated user for the rest of the request
+func SetUserID(c *gin.Context, id uint) {
+	c.Set(userIDKey, id)
+}
+
+// UserID returns the authenticated user, if any
+func UserID(c *gin.Context) (uint, bool) {
+	v, ok := c.Get(userIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := v.(uint)
+	return id, ok
+}
+
+// APIKeyID identifies the caller's API key without exposing it once
+// APIKeys has accepted it, or returns "" when the request carries no valid
+// key
+func APIKeyID(c *gin.Context) string {
+	return c.GetString(apiKeyIDKey)
+}
+
+// presentedAPIKeyID identifies the API key the request carries, valid or
+// not, or returns "" when it carries none
+func presentedAPIKeyID(c *gin.Context) string {
+	key := requestAPIKey(c)
+	if key == "" {
+		return ""
+	}
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:8])
+}
+
+// ClientID identifies who is calling for per-client bookkeeping: the same
+// caller as for quotas, or the client address for anonymous requests, so
+// they cannot pick a fresh identity per request
+func ClientID(c *gin.Context) string {
+	if subject := QuotaSubject(c); subject != "" {
+		return subject
+	}
+	return "ip:" + ClientIP(c)
+}
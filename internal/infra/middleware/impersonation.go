@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/service"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationHeader carries a support operator's impersonation token
+const ImpersonationHeader = "X-Impersonation-Token"
+
+const impersonationIDKey = "impersonation_id"
+
+// Impersonation lets a request act as the user of a valid impersonation
+// token. Responses are marked with X-Impersonating and every such request is
+// written to the session's audit log.
+func Impersonation(sessions *service.ImpersonationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(ImpersonationHeader)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		session, err := sessions.Authenticate(token)
+		if errors.Is(err, service.ErrInvalidImpersonation) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, apperror.Response(http.StatusUnauthorized, err))
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, apperror.Response(http.StatusInternalServerError, err))
+			return
+		}
+
+		SetUserID(c, session.UserID)
+		c.Set(impersonationIDKey, session.ID)
+		c.Header("X-Impersonating", strconv.FormatUint(uint64(session.UserID), 10))
+
+		c.Next()
+
+		if err := sessions.RecordAction(session.ID, c.Request.Method, c.Request.URL.RequestURI(), c.Writer.Status(), ClientIP(c)); err != nil {
+			log.Printf("Failed to audit impersonated request %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+	}
+}
+
+// ImpersonationID returns the impersonation session the request runs under,
+// if any
+func ImpersonationID(c *gin.Context) (uint, bool) {
+	v, ok := c.Get(impersonationIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := v.(uint)
+	return id, ok
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/service"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CaptchaHeader carries the CAPTCHA token once a client has failed too often
+const CaptchaHeader = "X-Captcha-Token"
+
+// LoginGuard slows down credential guessing on the routes it wraps. It must
+// run before the authentication middleware: it rejects locked out callers up
+// front and counts every 401 the rest of the chain answers as a failure.
+func LoginGuard(guard *service.LoginGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		account := credentialAccount(c)
+		ip := "ip:" + ClientIP(c)
+
+		if until := guard.LockedUntil(account, ip); !until.IsZero() {
+			retryAfter := int(math.Ceil(time.Until(until).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, apperror.Message(http.StatusTooManyRequests, "too many failed attempts, try again later"))
+			return
+		}
+
+		if guard.CaptchaRequired(account, ip) {
+			ok, err := guard.VerifyCaptcha(c.GetHeader(CaptchaHeader), ClientIP(c))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, apperror.Response(http.StatusInternalServerError, err))
+				return
+			}
+			if !ok {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "captcha required", "code": apperror.CodeCaptchaRequired, "captcha_required": true})
+				return
+			}
+		}
+
+		c.Next()
+
+		if account == "" {
+			return
+		}
+		if c.Writer.Status() == http.StatusUnauthorized {
+			guard.RecordFailure(account, ip)
+		} else {
+			guard.RecordSuccess(account)
+		}
+	}
+}
+
+// credentialAccount names the account a request is trying to authenticate
+// as, or "" when it carries no credentials
+func credentialAccount(c *gin.Context) string {
+	if keyID := c.GetHeader(PartnerKeyHeader); keyID != "" {
+		return "partner:" + keyID
+	}
+	if key := presentedAPIKeyID(c); key != "" {
+		return "key:" + key
+	}
+	return ""
+}
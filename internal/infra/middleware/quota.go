@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/service"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaSubject identifies who a request is billed to: the signing partner,
+// the API key once it is validated, otherwise the authenticated user.
+// Anonymous requests, and requests with an invalid key, return "" and are
+// not counted, so made-up keys cannot add usage rows.
+func QuotaSubject(c *gin.Context) string {
+	if id, ok := PartnerID(c); ok {
+		return "partner:" + strconv.FormatUint(uint64(id), 10)
+	}
+	if key := APIKeyID(c); key != "" {
+		return "key:" + key
+	}
+	if id, ok := UserID(c); ok {
+		return "user:" + strconv.FormatUint(uint64(id), 10)
+	}
+	return ""
+}
+
+// Quota counts requests against the caller's monthly quota and rejects them
+// with exhaustedStatus (402 or 429) once it is used up. Quota headers are set
+// on every counted response.
+func Quota(quotas *service.QuotaService, exhaustedStatus int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject := QuotaSubject(c)
+		if subject == "" {
+			c.Next()
+			return
+		}
+
+		usage, allowed, err := quotas.Consume(subject)
+		if err != nil {
+			// Quota accounting must not take the API down with it
+			log.Printf("Failed to account quota for %s: %v", subject, err)
+			c.Next()
+			return
+		}
+
+		if usage.Limit > 0 {
+			c.Header("X-Quota-Limit", strconv.FormatInt(usage.Limit, 10))
+			c.Header("X-Quota-Remaining", strconv.FormatInt(usage.Remaining, 10))
+			c.Header("X-Quota-Reset", strconv.FormatInt(usage.ResetsAt.Unix(), 10))
+		}
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(usage.ResetsAt).Seconds())+1))
+			c.AbortWithStatusJSON(exhaustedStatus, apperror.Message(exhaustedStatus, "monthly request quota exhausted"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/service"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit advertises the client's short-term allowance in the RateLimit
+// headers of the IETF httpapi draft on every response, and rejects
+// requests over it with 429 when enforce is set. A limit of 0 turns it off.
+func RateLimit(limiter *service.RateLimiter, enforce bool) gin.HandlerFunc {
+	limit, window := limiter.Policy()
+	if limit <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := fmt.Sprintf("%d;w=%d", limit, int(window.Seconds()))
+
+	return func(c *gin.Context) {
+		status := limiter.Take(c.Request.Context(), ClientID(c))
+		reset := strconv.Itoa(int(math.Ceil(time.Until(status.Reset).Seconds())))
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(status.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+		c.Header("RateLimit-Reset", reset)
+
+		if enforce && status.Exceeded {
+			c.Header("Retry-After", reset)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, apperror.Message(http.StatusTooManyRequests, "rate limit exceeded"))
+			return
+		}
+		c.Next()
+	}
+}
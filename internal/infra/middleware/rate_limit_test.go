@@ -0,0 +1,68 @@
+package middleware_test
+
+import (
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/service"
+	"bms-go/internal/testutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimitExhaustion(t *testing.T) {
+	const limit = 3
+	validKey := "valid-key"
+
+	tests := []struct {
+		name string
+		// request builds the i-th request of the client
+		request func(t *testing.T, auth *service.AuthService, i int) *http.Request
+	}{
+		{"anonymous client", func(t *testing.T, _ *service.AuthService, _ int) *http.Request {
+			return testutil.NewRequest(t, http.MethodGet, "/books", nil)
+		}},
+		{"signed-in user", func(t *testing.T, auth *service.AuthService, _ int) *http.Request {
+			return testutil.AuthenticatedRequest(t, auth, 1, http.MethodGet, "/books", nil)
+		}},
+		{"valid API key", func(t *testing.T, _ *service.AuthService, _ int) *http.Request {
+			return testutil.WithAPIKey(testutil.NewRequest(t, http.MethodGet, "/books", nil), validKey)
+		}},
+		{"fresh invalid API key per request", func(t *testing.T, _ *service.AuthService, i int) *http.Request {
+			return testutil.WithAPIKey(testutil.NewRequest(t, http.MethodGet, "/books", nil), "made-up-"+strconv.Itoa(i))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := newAuth()
+			// An hour-long window keeps the test clear of window resets
+			rateLimit := middleware.RateLimit(service.NewRateLimiter(limit, time.Hour), true)
+			identifyKey := middleware.IdentifyAPIKey(middleware.APIKeys([]string{validKey}))
+			router := testutil.Router(auth, routes(func(r handler.Routes) {
+				r.Public.GET("/books", identifyKey, rateLimit, ok)
+			}))
+
+			for i := 1; i <= limit; i++ {
+				rec := testutil.Serve(router, tt.request(t, auth, i))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("request %d: status = %d, want 200", i, rec.Code)
+				}
+				if got, want := rec.Header().Get("RateLimit-Remaining"), strconv.Itoa(limit-i); got != want {
+					t.Errorf("request %d: RateLimit-Remaining = %s, want %s", i, got, want)
+				}
+			}
+
+			rec := testutil.Serve(router, tt.request(t, auth, limit+1))
+			if rec.Code != http.StatusTooManyRequests {
+				t.Fatalf("request over the limit: status = %d, want 429", rec.Code)
+			}
+			if rec.Header().Get("Retry-After") == "" {
+				t.Error("429 without Retry-After")
+			}
+			if got := rec.Header().Get("RateLimit-Remaining"); got != "0" {
+				t.Errorf("RateLimit-Remaining = %s, want 0", got)
+			}
+		})
+	}
+}
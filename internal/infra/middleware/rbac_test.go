@@ -0,0 +1,156 @@
+package middleware_test
+
+import (
+	"bms-go/config"
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"bms-go/internal/testutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routes registers routes for a test without a handler type
+type routes func(handler.Routes)
+
+func (f routes) RegisterRoutes(r handler.Routes) { f(r) }
+
+func ok(c *gin.Context) { c.Status(http.StatusOK) }
+
+// roles looks up roles in memory
+type roles map[uint]model.UserRole
+
+func (r roles) GetRole(id uint) (model.UserRole, error) {
+	return r[id], nil
+}
+
+func newAuth() *service.AuthService {
+	return service.NewAuthService(nil, config.AuthConfig{JWTSecret: "test-secret", Issuer: "bms-go", TokenTTL: time.Hour})
+}
+
+func TestRequireRoles(t *testing.T) {
+	users := make(roles)
+	for _, u := range []model.User{
+		testutil.User(1).ID(1).Role(model.RoleReader).Build(),
+		testutil.User(2).ID(2).Role(model.RoleLibrarian).Build(),
+		testutil.User(3).ID(3).Role(model.RoleAdmin).Build(),
+	} {
+		users[u.ID] = u.Role
+	}
+	rbac := middleware.RequireRoles(users, []dto.AccessRule{
+		{Prefix: "/admin", Roles: []model.UserRole{model.RoleAdmin}},
+		{Prefix: "/books", Methods: []string{http.MethodPost, http.MethodDelete}, Roles: []model.UserRole{model.RoleLibrarian, model.RoleAdmin}},
+	})
+	auth := newAuth()
+	router := testutil.Router(auth, routes(func(r handler.Routes) {
+		r.Public.GET("/admin/users", rbac, ok)
+		r.Public.GET("/books", rbac, ok)
+		r.Public.POST("/books", rbac, ok)
+		r.Public.DELETE("/books/:id", rbac, ok)
+		r.Public.GET("/bookshelves", rbac, ok)
+	}))
+
+	tests := []struct {
+		name   string
+		user   uint
+		method string
+		path   string
+		want   int
+	}{
+		{"anonymous on a guarded route", 0, http.MethodGet, "/admin/users", http.StatusUnauthorized},
+		{"reader on an admin route", 1, http.MethodGet, "/admin/users", http.StatusForbidden},
+		{"librarian on an admin route", 2, http.MethodGet, "/admin/users", http.StatusForbidden},
+		{"admin on an admin route", 3, http.MethodGet, "/admin/users", http.StatusOK},
+		{"anonymous read of an unguarded method", 0, http.MethodGet, "/books", http.StatusOK},
+		{"anonymous write", 0, http.MethodPost, "/books", http.StatusUnauthorized},
+		{"reader write", 1, http.MethodPost, "/books", http.StatusForbidden},
+		{"reader delete below the prefix", 1, http.MethodDelete, "/books/7", http.StatusForbidden},
+		{"librarian write", 2, http.MethodPost, "/books", http.StatusOK},
+		{"admin delete", 3, http.MethodDelete, "/books/7", http.StatusOK},
+		{"route sharing only part of a segment", 0, http.MethodGet, "/bookshelves", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewRequest(t, tt.method, tt.path, nil)
+			if tt.user != 0 {
+				req = testutil.AuthenticatedRequest(t, auth, tt.user, tt.method, tt.path, nil)
+			}
+			rec := testutil.Serve(router, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequirePrivateRoles(t *testing.T) {
+	users := roles{1: model.RoleReader, 3: model.RoleAdmin}
+	rbac := middleware.RequirePrivateRoles(users, []dto.AccessRule{
+		{Prefix: "/admin", Roles: []model.UserRole{model.RoleAdmin}},
+	})
+	auth := newAuth()
+	router := testutil.Router(auth, routes(func(r handler.Routes) {
+		r.Private.Use(middleware.IdentifyAPIKey(middleware.APIKeys([]string{"valid-key"})), rbac)
+		r.Private.GET("/admin/users", ok)
+		r.Private.GET("/me/usage", ok)
+	}))
+
+	tests := []struct {
+		name string
+		user uint
+		key  string
+		path string
+		want int
+	}{
+		{"anonymous on an unmatched route", 0, "", "/me/usage", http.StatusUnauthorized},
+		{"invalid key on an unmatched route", 0, "made-up", "/me/usage", http.StatusUnauthorized},
+		{"reader on an unmatched route", 1, "", "/me/usage", http.StatusOK},
+		{"api key on an unmatched route", 0, "valid-key", "/me/usage", http.StatusOK},
+		{"anonymous on a guarded route", 0, "", "/admin/users", http.StatusUnauthorized},
+		{"reader on a guarded route", 1, "", "/admin/users", http.StatusForbidden},
+		{"admin on a guarded route", 3, "", "/admin/users", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewRequest(t, http.MethodGet, tt.path, nil)
+			if tt.user != 0 {
+				req = testutil.AuthenticatedRequest(t, auth, tt.user, http.MethodGet, tt.path, nil)
+			}
+			if tt.key != "" {
+				req = testutil.WithAPIKey(req, tt.key)
+			}
+			rec := testutil.Serve(router, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d; body %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestShippedRulesGuardDebugVars(t *testing.T) {
+	users := testutil.Roles{1: model.RoleReader, 2: model.RoleLibrarian, 3: model.RoleAdmin}
+	auth := newAuth()
+	router := testutil.GuardedRouter(auth, users, testutil.ShippedRules(t), routes(func(r handler.Routes) {
+		r.Private.GET("/debug/vars", ok)
+	}))
+
+	for user, want := range map[uint]int{
+		0: http.StatusUnauthorized,
+		1: http.StatusForbidden,
+		2: http.StatusForbidden,
+		3: http.StatusOK,
+	} {
+		req := testutil.NewRequest(t, http.MethodGet, "/debug/vars", nil)
+		if user != 0 {
+			req = testutil.AuthenticatedRequest(t, auth, user, http.MethodGet, "/debug/vars", nil)
+		}
+		if rec := testutil.Serve(router, req); rec.Code != want {
+			t.Errorf("user %d: status = %d, want %d", user, rec.Code, want)
+		}
+	}
+}
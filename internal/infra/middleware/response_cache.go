@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bms-go/internal/infra/cache"
+	"bytes"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// capturingWriter passes the response through while keeping a copy of the
+// body for the cache
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// CacheResponses serves repeated GETs of the route from responses, keyed by
+// path and normalized query, and stores successful responses under tags.
+// X-Cache reports HIT or MISS. A nil cache disables it, and unbounded
+// requests are passed through so their streamed body is not held in memory.
+func CacheResponses(responses *cache.ResponseCache, tags ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if responses == nil || c.Request.Method != http.MethodGet || Unbounded(c) {
+			c.Next()
+			return
+		}
+
+		key := cacheKey(c)
+		if resp, ok := responses.Get(key); ok {
+			c.Header("X-Cache", "HIT")
+			c.Data(resp.Status, resp.ContentType, resp.Body)
+			c.Abort()
+			return
+		}
+
+		generation := responses.Generation()
+		w := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Header("X-Cache", "MISS")
+		c.Next()
+
+		if w.Status() == http.StatusOK {
+			responses.Set(key, cache.Response{
+				Status:      w.Status(),
+				ContentType: w.Header().Get("Content-Type"),
+				Body:        w.body.Bytes(),
+			}, generation, tags...)
+		}
+	}
+}
+
+// cacheKey is the request path followed by its query parameters sorted by
+// name and value, so equivalent queries share an entry. Responses built for
+// a response profile or for experiment variants are kept apart from the
+// others.
+func cacheKey(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	key := c.Request.URL.Path + "?" + query.Encode()
+	if profile := c.GetString(profileKey); profile != "" {
+		key += "#" + profile
+	}
+	if experiments := c.GetString(experimentsHeaderKey); experiments != "" {
+		key += "#" + experiments
+	}
+	return key
+}
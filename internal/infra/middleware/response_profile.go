@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	profileKey         = "response_profile"
+	profileFilteredKey = "response_profile_filtered"
+	profileHeader      = "X-Response-Profile"
+
+	// ProfileKids is the reduced, age-appropriate view used by school
+	// kiosks: only books rated all_ages, with a few simple fields
+	ProfileKids = "kids"
+)
+
+// kidsBookFields are the book fields kept in the kids profile
+var kidsBookFields = map[string]bool{
+	// Books served straight from the model carry their id as ID
+	"ID":                    true,
+	"id":                    true,
+	"title":                 true,
+	"author":                true,
+	"category":              true,
+	"description":           true,
+	"pages":                 true,
+	"cover_url":             true,
+	"content_rating":        true,
+	"accessibility":         true,
+	"media_type":            true,
+	"narrator":              true,
+	"duration_minutes":      true,
+	"reading_minutes":       true,
+	"has_sample":            true,
+	"average_rating":        true,
+	"rating_count":          true,
+	"description_generated": true,
+}
+
+// kidsHiddenKeys are dropped from every object in the kids profile
+var kidsHiddenKeys = map[string]bool{
+	"scores": true,
+	// Book comparisons list values by book id, including books that were
+	// filtered out
+	"differences": true,
+}
+
+// ResponseProfile selects the response profile from the X-Response-Profile
+// header or the profile query parameter; "full" or nothing is the normal
+// view. Under the kids profile JSON responses are rewritten on the way out:
+// book objects keep only kidsBookFields and non-JSON formats are refused
+// with 406.
+//
+// Lists and streams of books must be limited to all_ages books in their
+// query, so pages and counts stay right, and say so with ProfileFiltered.
+// Any other response is checked as a whole as a safety net: books not
+// rated all_ages are removed from arrays, and a response that would show
+// one otherwise becomes 404. NDJSON is rewritten line by line as it is
+// written, so streams are never held back.
+func ResponseProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", profileHeader)
+
+		profile := c.GetHeader(profileHeader)
+		if profile == "" {
+			profile = c.Query("profile")
+		}
+		switch profile {
+		case "", "full":
+			c.Next()
+			return
+		case ProfileKids:
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, apperror.Message(http.StatusBadRequest, "profile must be full or kids"))
+			return
+		}
+
+		c.Set(profileKey, profile)
+		w := &profileWriter{ResponseWriter: c.Writer, filtered: func() bool { return c.GetBool(profileFilteredKey) }}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.streaming {
+			w.writeLines(true)
+			return
+		}
+		status, body := kidsResponse(w.Status(), w.Header().Get("Content-Type"), w.body.Bytes(), !w.filtered())
+		w.Header().Del("Content-Length")
+		if status != w.Status() {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+		c.Status(status)
+		c.Writer.Write(body)
+	}
+}
+
+// KidsProfile reports whether the request asked for the kids profile
+func KidsProfile(c *gin.Context) bool {
+	return c.GetString(profileKey) == ProfileKids
+}
+
+// ProfileFiltered records that the handler's list or stream only holds
+// books the response profile allows, having filtered them in its query.
+// The books are then only trimmed, never dropped.
+func ProfileFiltered(c *gin.Context) {
+	c.Set(profileFilteredKey, true)
+}
+
+// profileWriter holds a JSON body back so it can be rewritten once the
+// handler is done. An NDJSON body is instead rewritten and written through
+// a line at a time, flushing when the handler flushes. The status is
+// recorded on the wrapped writer as usual.
+type profileWriter struct {
+	gin.ResponseWriter
+	filtered func() bool
+	body     bytes.Buffer
+
+	// decided is set on the first write, which tells whether the body is
+	// streamed
+	decided   bool
+	streaming bool
+}
+
+func (w *profileWriter) Write(b []byte) (int, error) {
+	w.decide()
+	w.body.Write(b)
+	if w.streaming {
+		if err := w.writeLines(false); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *profileWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *profileWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	w.streaming = mediaType == "application/x-ndjson"
+}
+
+// writeLines writes out the complete lines held back, and with last the
+// rest of the body too
+func (w *profileWriter) writeLines(last bool) error {
+	for {
+		line, err := w.body.ReadBytes('\n')
+		if err != nil {
+			// An incomplete line waits for the rest of it
+			if last {
+				line = append(line, '\n')
+			} else {
+				w.body.Write(line)
+				return nil
+			}
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			if rewritten, ok := kidsJSON(line, !w.filtered()); ok {
+				if _, err := w.ResponseWriter.Write(append(rewritten, '\n')); err != nil {
+					return err
+				}
+			}
+		}
+		if w.body.Len() == 0 {
+			return nil
+		}
+	}
+}
+
+func (w *profileWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *profileWriter) Flush() {
+	if w.streaming {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// kidsResponse rewrites a buffered response for the kids profile, dropping
+// books it may not show when filter is set
+func kidsResponse(status int, contentType string, body []byte, filter bool) (int, []byte) {
+	if len(body) == 0 {
+		return status, body
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		rewritten, ok := kidsJSON(body, filter)
+		if !ok {
+			return http.StatusNotFound, []byte(`{"error":"not found","code":"NOT_FOUND"}`)
+		}
+		return status, rewritten
+	case status >= http.StatusBadRequest:
+		return status, body
+	}
+	return http.StatusNotAcceptable, []byte(`{"error":"this format is not available in the kids profile","code":"VALIDATION_FAILED"}`)
+}
+
+// kidsJSON rewrites one JSON document, reporting false when nothing of it
+// may be shown
+func kidsJSON(doc []byte, filter bool) ([]byte, bool) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	v, ok := kidsView(v, filter)
+	if !ok {
+		return nil, false
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// kidsView filters a decoded JSON value. With filter, a book that is not
+// rated all_ages is removed from the array holding it; anywhere else it
+// takes its parent object down with it.
+func kidsView(v interface{}, filter bool) (interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if isBookObject(v) {
+			if filter && v["content_rating"] != string(model.RatingAllAges) {
+				return nil, false
+			}
+			for key := range v {
+				if !kidsBookFields[key] {
+					delete(v, key)
+				}
+			}
+			return v, true
+		}
+		for key, child := range v {
+			if kidsHiddenKeys[key] {
+				delete(v, key)
+				continue
+			}
+			filtered, ok := kidsView(child, filter)
+			if !ok {
+				return nil, false
+			}
+			v[key] = filtered
+		}
+		return v, true
+	case []interface{}:
+		kept := make([]interface{}, 0, len(v))
+		for _, child := range v {
+			if filtered, ok := kidsView(child, filter); ok {
+				kept = append(kept, filtered)
+			}
+		}
+		return kept, true
+	}
+	return v, true
+}
+
+// isBookObject recognises books and book responses by the fields every
+// serialized book carries
+func isBookObject(v map[string]interface{}) bool {
+	_, title := v["title"]
+	_, author := v["author"]
+	_, rating := v["content_rating"]
+	return title && author && rating
+}
@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model"
+	"bms-go/internal/testutil"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	allAgesBook = testutil.Book(1).ID(1).Title("Matilda").Build()
+	teenBook    = testutil.Book(2).ID(2).Title("Dune").ContentRating(model.RatingTeen).Build()
+)
+
+func kidsRouter(register func(r handler.Routes)) *gin.Engine {
+	return testutil.Router(newAuth(), routes(func(r handler.Routes) {
+		r.Public.Use(middleware.ResponseProfile())
+		register(r)
+	}))
+}
+
+func kidsRequest(t *testing.T, path string) *http.Request {
+	req := testutil.NewRequest(t, http.MethodGet, path, nil)
+	req.Header.Set("X-Response-Profile", middleware.ProfileKids)
+	return req
+}
+
+func TestResponseProfileKids(t *testing.T) {
+	trimmed := []map[string]interface{}{{
+		"ID": 1, "title": "Matilda", "author": allAgesBook.Author, "category": allAgesBook.Category,
+		"description": "", "pages": allAgesBook.Pages, "cover_url": "", "content_rating": "all_ages",
+		"media_type": "print", "has_sample": false, "average_rating": 0, "rating_count": 0,
+		"description_generated": false, "accessibility": allAgesBook.Accessibility,
+	}}
+
+	tests := []struct {
+		name   string
+		handle gin.HandlerFunc
+		want   int
+		body   interface{}
+	}{
+		{"book fields are trimmed", func(c *gin.Context) { c.JSON(http.StatusOK, allAgesBook) }, http.StatusOK, trimmed[0]},
+		{"a book not for all ages is not found", func(c *gin.Context) { c.JSON(http.StatusOK, teenBook) }, http.StatusNotFound, `{"code": "NOT_FOUND", "error": "not found"}`},
+		{"unfiltered lists drop books not for all ages", func(c *gin.Context) {
+			c.JSON(http.StatusOK, []model.Book{allAgesBook, teenBook})
+		}, http.StatusOK, trimmed},
+		{"lists filtered in their query are only trimmed", func(c *gin.Context) {
+			middleware.ProfileFiltered(c)
+			c.JSON(http.StatusOK, gin.H{"data": []model.Book{allAgesBook}, "meta": gin.H{"count": 1}})
+		}, http.StatusOK, gin.H{"data": trimmed, "meta": gin.H{"count": 1}}},
+		{"other formats are refused", func(c *gin.Context) { c.String(http.StatusOK, "id,title\n1,Matilda\n") }, http.StatusNotAcceptable, `{"code": "VALIDATION_FAILED", "error": "this format is not available in the kids profile"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := kidsRouter(func(r handler.Routes) { r.Public.GET("/books", tt.handle) })
+			rec := testutil.Serve(router, kidsRequest(t, "/books"))
+			testutil.AssertJSON(t, rec, tt.want, tt.body)
+		})
+	}
+}
+
+func TestResponseProfileKidsStreams(t *testing.T) {
+	rec := httptest.NewRecorder()
+	lines := func() []string { return strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n") }
+
+	router := kidsRouter(func(r handler.Routes) {
+		r.Public.GET("/books/stream", func(c *gin.Context) {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(http.StatusOK)
+			enc := json.NewEncoder(c.Writer)
+
+			enc.Encode(allAgesBook)
+			// Half a line waits for the rest of it
+			c.Writer.WriteString(`{"title": "Dune", "author": "Frank Herbert", `)
+			c.Writer.Flush()
+			if !rec.Flushed || len(lines()) != 1 {
+				t.Errorf("after the first flush the client has %q, want the first book", rec.Body)
+			}
+			if strings.Contains(rec.Body.String(), "favorite_count") {
+				t.Errorf("streamed book was not trimmed: %s", rec.Body)
+			}
+
+			c.Writer.WriteString(`"content_rating": "teen"}` + "\n")
+			enc.Encode(gin.H{"error": "connection reset"})
+		})
+	})
+	router.ServeHTTP(rec, kidsRequest(t, "/books/stream"))
+
+	got := lines()
+	if len(got) != 2 || !strings.Contains(got[0], `"title":"Matilda"`) || got[1] != `{"error":"connection reset"}` {
+		t.Fatalf("stream = %q, want the all-ages book and the error", got)
+	}
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"context"
+	"errors"
+	"expvar"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeouts counts requests answered with 503 for taking too long, by route
+var timeouts = expvar.NewMap("request_timeouts")
+
+// timeoutWriter drops whatever the handler writes once the request's
+// deadline has passed, so the timeout response can be written instead
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	status   int
+	timedOut bool
+}
+
+func (w *timeoutWriter) expired() bool {
+	if !w.timedOut && !w.ResponseWriter.Written() && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.status = code
+	if !w.expired() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if w.expired() {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Status reports the status the handler chose even when it was dropped, so
+// middleware such as the response cache does not mistake it for a 200
+func (w *timeoutWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+// Timeout gives each request a deadline: routes' own limit for its route
+// pattern, or defaultTimeout. The request context is cancelled when it
+// passes, which aborts database queries run with that context, and unless
+// the handler already started its response the client gets a 503 with
+// Retry-After instead of whatever the handler writes late.
+func Timeout(defaultTimeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := defaultTimeout
+		if d, ok := routes[c.FullPath()]; ok {
+			timeout = d
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		original := c.Writer
+		w := &timeoutWriter{ResponseWriter: original, ctx: ctx}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		if !w.expired() {
+			return
+		}
+		timeouts.Add(c.FullPath(), 1)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(timeout.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":      "request timed out",
+			"code":       apperror.CodeTimeout,
+			"timeout_ms": timeout.Milliseconds(),
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const unboundedKey = "unbounded"
+
+// AllowUnbounded lets trusted callers ask list endpoints for every result
+// with limit=0: requests carrying a valid API key and signed-in admins. It
+// never applies to the kids profile, and must run after whatever sets the
+// user. Other callers keep the usual limits.
+func AllowUnbounded(users *service.UserService, apiKeys Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("limit") == "0" && !KidsProfile(c) && trustedCaller(c, users, apiKeys) {
+			c.Set(unboundedKey, true)
+		}
+		c.Next()
+	}
+}
+
+func trustedCaller(c *gin.Context, users *service.UserService, apiKeys Authenticator) bool {
+	if present, err := apiKeys(c); present && err == nil {
+		return true
+	}
+	id, ok := UserID(c)
+	if !ok {
+		return false
+	}
+	role, err := users.GetRole(id)
+	return err == nil && role == model.RoleAdmin
+}
+
+// Unbounded reports whether the request asked for every result and may
+// have it. Such responses are streamed and never cached.
+func Unbounded(c *gin.Context) bool {
+	return c.GetBool(unboundedKey)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserTokens authenticates requests carrying a user's access token in the
+// Authorization header and acts as that user
+func UserTokens(auth *service.AuthService) Authenticator {
+	return func(c *gin.Context) (bool, error) {
+		token := bearerToken(c)
+		if token == "" || !isAccessToken(token) {
+			return false, nil
+		}
+		id, err := auth.Authenticate(token)
+		if err != nil {
+			return true, err
+		}
+		SetUserID(c, id)
+		return true, nil
+	}
+}
+
+// Users acts as the user of requests carrying a valid access token and
+// rejects invalid ones. Requests without a token pass through anonymously.
+func Users(auth *service.AuthService) gin.HandlerFunc {
+	authenticate := UserTokens(auth)
+	return func(c *gin.Context) {
+		if _, err := authenticate(c); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, apperror.Response(http.StatusUnauthorized, err))
+			return
+		}
+		c.Next()
+	}
+}
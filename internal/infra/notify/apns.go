@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apnsProduction = "https://api.push.apple.com/3/device/"
+	apnsSandbox    = "https://api.sandbox.push.apple.com/3/device/"
+	// apnsTokenLifetime renews the provider token well inside the hour
+	// Apple accepts it for, and no more than once every 20 minutes
+	apnsTokenLifetime = 40 * time.Minute
+)
+
+// apnsDeviceToken is the hex device token iOS hands the app
+var apnsDeviceToken = regexp.MustCompile(`^[0-9a-fA-F]{64,200}$`)
+
+// APNs sends to iOS apps through the Apple Push Notification service with
+// token-based authentication; the target is the device token
+type APNs struct {
+	client   *httpclient.Client
+	endpoint string
+	key      *ecdsa.PrivateKey
+	keyID    string
+	teamID   string
+	topic    string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewAPNs takes the .p8 signing key from the Apple developer account with
+// its key id and team id. topic is the app's bundle id; sandbox sends
+// through the development environment.
+func NewAPNs(client *httpclient.Client, privateKey []byte, keyID, teamID, topic string, sandbox bool) (*APNs, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("APNs key: not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("APNs key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("APNs key: not an EC key")
+	}
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, errors.New("APNs: key id, team id and topic are required")
+	}
+	endpoint := apnsProduction
+	if sandbox {
+		endpoint = apnsSandbox
+	}
+	return &APNs{client: client, endpoint: endpoint, key: key, keyID: keyID, teamID: teamID, topic: topic}, nil
+}
+
+func (a *APNs) Validate(target string) error {
+	if !apnsDeviceToken.MatchString(target) {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+func (a *APNs) Send(ctx context.Context, target string, msg Message) error {
+	if err := a.Validate(target); err != nil {
+		return err
+	}
+	token, err := a.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": msg.Title,
+				"body":  msg.Body,
+			},
+		},
+	}
+	if msg.URL != "" {
+		payload["url"] = msg.URL
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", a.topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	err = send(a.client, req)
+	var status *StatusError
+	if errors.As(err, &status) && (status.StatusCode == http.StatusGone ||
+		strings.Contains(status.Body, "BadDeviceToken") || strings.Contains(status.Body, "Unregistered")) {
+		return fmt.Errorf("%w: %v", ErrTargetGone, err)
+	}
+	return err
+}
+
+// providerToken returns the cached JWT that authenticates the server,
+// signing a new one when it is due
+func (a *APNs) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && time.Now().Before(a.expires) {
+		return a.token, nil
+	}
+	now := time.Now()
+	token, err := signES256(a.key, map[string]interface{}{"alg": "ES256", "kid": a.keyID}, map[string]interface{}{
+		"iss": a.teamID,
+		"iat": now.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	a.token = token
+	a.expires = now.Add(apnsTokenLifetime)
+	return token, nil
+}
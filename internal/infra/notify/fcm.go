@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fcmAPI   = "https://fcm.googleapis.com/v1/projects/"
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+	// fcmTokenMargin renews the access token before it actually expires
+	fcmTokenMargin = 5 * time.Minute
+)
+
+// FCM sends to Android and iOS apps through Firebase Cloud Messaging (HTTP
+// v1 API); the target is the app's registration token
+type FCM struct {
+	client    *httpclient.Client
+	projectID string
+	email     string
+	tokenURI  string
+	key       *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expires     time.Time
+}
+
+// fcmCredentials is the part of a Google service account JSON key FCM needs
+type fcmCredentials struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// NewFCM takes the service account JSON key downloaded from the Firebase
+// console
+func NewFCM(client *httpclient.Client, credentials []byte) (*FCM, error) {
+	var creds fcmCredentials
+	if err := json.Unmarshal(credentials, &creds); err != nil {
+		return nil, fmt.Errorf("FCM credentials: %w", err)
+	}
+	if creds.ProjectID == "" || creds.ClientEmail == "" || creds.TokenURI == "" {
+		return nil, errors.New("FCM credentials: project_id, client_email and token_uri are required")
+	}
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return nil, errors.New("FCM credentials: private_key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("FCM credentials: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("FCM credentials: private_key is not an RSA key")
+	}
+	return &FCM{client: client, projectID: creds.ProjectID, email: creds.ClientEmail, tokenURI: creds.TokenURI, key: key}, nil
+}
+
+func (f *FCM) Validate(target string) error {
+	if target == "" || len(target) > 4096 || strings.ContainsAny(target, " \t\r\n") {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+func (f *FCM) Send(ctx context.Context, target string, msg Message) error {
+	if err := f.Validate(target); err != nil {
+		return err
+	}
+	token, err := f.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	message := map[string]interface{}{
+		"token": target,
+		"notification": map[string]string{
+			"title": msg.Title,
+			"body":  msg.Body,
+		},
+	}
+	if msg.URL != "" {
+		message["data"] = map[string]string{"url": msg.URL}
+	}
+	body, err := json.Marshal(map[string]interface{}{"message": message})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fcmAPI+f.projectID+"/messages:send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	err = send(f.client, req)
+	var status *StatusError
+	if errors.As(err, &status) && (status.StatusCode == http.StatusNotFound || strings.Contains(status.Body, "UNREGISTERED")) {
+		return fmt.Errorf("%w: %v", ErrTargetGone, err)
+	}
+	return err
+}
+
+// token returns a cached OAuth access token, exchanging a freshly signed
+// service account assertion for a new one when it is about to expire
+func (f *FCM) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.accessToken != "" && time.Now().Before(f.expires) {
+		return f.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := signRS256(f.key, map[string]interface{}{"typ": "JWT", "alg": "RS256"}, map[string]interface{}{
+		"iss":   f.email,
+		"scope": fcmScope,
+		"aud":   f.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("FCM access token: %s", resp.Status)
+	}
+	var grant struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&grant); err != nil {
+		return "", fmt.Errorf("FCM access token: %w", err)
+	}
+	f.accessToken = grant.AccessToken
+	f.expires = now.Add(time.Duration(grant.ExpiresIn)*time.Second - fcmTokenMargin)
+	return f.accessToken, nil
+}
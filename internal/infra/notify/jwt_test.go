@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// splitJWT decodes a compact JWT's header and claims into header and claims
+// and returns its signing input and signature
+func splitJWT(t *testing.T, token string, header, claims interface{}) (string, []byte) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+	for i, v := range []interface{}{header, claims} {
+		if err := json.Unmarshal(decodeTestKey(t, parts[i]), v); err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+	}
+	return parts[0] + "." + parts[1], decodeTestKey(t, parts[2])
+}
+
+func TestVAPIDToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := key.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWebPush(nil, base64.RawURLEncoding.EncodeToString(raw), "mailto:ops@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := w.vapidToken("https://fcm.googleapis.com/fcm/send/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header map[string]string
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	signed, sig := splitJWT(t, token, &header, &claims)
+
+	if header["alg"] != "ES256" || header["typ"] != "JWT" {
+		t.Errorf("header = %v", header)
+	}
+	if claims.Aud != "https://fcm.googleapis.com" {
+		t.Errorf("aud = %q, want the push service origin", claims.Aud)
+	}
+	if claims.Sub != "mailto:ops@example.com" {
+		t.Errorf("sub = %q", claims.Sub)
+	}
+	if exp := time.Unix(claims.Exp, 0); exp.Before(time.Now()) || exp.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("exp = %v, want within the next 24 hours", exp)
+	}
+
+	// ES256 signatures are r and s as two 32-byte big-endian integers
+	if len(sig) != 64 {
+		t.Fatalf("signature is %d bytes, want 64", len(sig))
+	}
+	digest := sha256.Sum256([]byte(signed))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Error("signature does not verify against the VAPID public key")
+	}
+	pub, _ := key.PublicKey.Bytes()
+	if w.PublicKey() != base64.RawURLEncoding.EncodeToString(pub) {
+		t.Error("PublicKey is not the uncompressed point of the VAPID key")
+	}
+}
+
+func TestSignRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signRS256(key, map[string]string{"alg": "RS256", "typ": "JWT"}, map[string]string{"iss": "svc@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header, claims map[string]string
+	signed, sig := splitJWT(t, token, &header, &claims)
+	if header["alg"] != "RS256" || claims["iss"] != "svc@example.com" {
+		t.Errorf("header = %v, claims = %v", header, claims)
+	}
+	digest := sha256.Sum256([]byte(signed))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Package notify delivers short messages to users outside the app. Each
+// Channel sends to a per-user target such as a webhook URL or chat id.
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidTarget is returned when a target cannot be used with a channel
+var ErrInvalidTarget = errors.New("invalid notification target")
+
+// ErrTargetGone is returned when the provider reports that a target no
+// longer exists, such as an uninstalled app's push token; it should be
+// forgotten rather than retried
+var ErrTargetGone = errors.New("notification target is gone")
+
+// Message is a rendered notification
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+// Channel is one way of reaching a user
+type Channel interface {
+	// Validate checks that target can be sent to before it is stored
+	Validate(target string) error
+	Send(ctx context.Context, target string, msg Message) error
+}
+
+// text joins the message into the plain text used by chat channels
+func text(msg Message) string {
+	s := msg.Title
+	if msg.Body != "" {
+		s += "\n" + msg.Body
+	}
+	if msg.URL != "" {
+		s += "\n" + msg.URL
+	}
+	return s
+}
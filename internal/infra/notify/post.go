@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// postJSON sends payload to url and fails on any non-2xx response
+func postJSON(ctx context.Context, client *httpclient.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return send(client, req)
+}
+
+func send(client *httpclient.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		// url.Error repeats the full URL, which can hold a bot token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("%s %s: %w", req.Method, req.URL.Host, urlErr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{Host: req.URL.Host, StatusCode: resp.StatusCode, Status: resp.Status, Body: string(bytes.TrimSpace(msg))}
+	}
+	return nil
+}
+
+// StatusError is a non-2xx response from a delivery endpoint. Body holds
+// the start of the response, which is where providers explain rejections.
+type StatusError struct {
+	Host       string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Host, e.Status, e.Body)
+}
@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"context"
+	"strings"
+)
+
+// slackWebhookPrefix keeps targets to Slack's incoming webhooks so a stored
+// target cannot point the server at arbitrary URLs
+const slackWebhookPrefix = "https://hooks.slack.com/"
+
+// Slack posts to an incoming webhook; the target is the webhook URL
+type Slack struct {
+	client *httpclient.Client
+}
+
+func NewSlack(client *httpclient.Client) *Slack {
+	return &Slack{client: client}
+}
+
+func (s *Slack) Validate(target string) error {
+	if !strings.HasPrefix(target, slackWebhookPrefix) {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+func (s *Slack) Send(ctx context.Context, target string, msg Message) error {
+	if err := s.Validate(target); err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, target, map[string]string{"text": text(msg)})
+}
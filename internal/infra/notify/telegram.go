@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"context"
+	"strconv"
+)
+
+const telegramAPI = "https://api.telegram.org"
+
+// Telegram sends through a bot; the target is the chat id the user got by
+// messaging the bot
+type Telegram struct {
+	client *httpclient.Client
+	token  string
+}
+
+func NewTelegram(client *httpclient.Client, token string) *Telegram {
+	return &Telegram{client: client, token: token}
+}
+
+func (t *Telegram) Validate(target string) error {
+	if _, err := strconv.ParseInt(target, 10, 64); err != nil {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+func (t *Telegram) Send(ctx context.Context, target string, msg Message) error {
+	if err := t.Validate(target); err != nil {
+		return err
+	}
+	return postJSON(ctx, t.client, telegramAPI+"/bot"+t.token+"/sendMessage", map[string]interface{}{
+		"chat_id":                  target,
+		"text":                     text(msg),
+		"disable_web_page_preview": true,
+	})
+}
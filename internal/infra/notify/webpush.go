@@ -0,0 +1,246 @@
+package notify
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	webPushTTL         = 24 * time.Hour
+	webPushTokenExpiry = 12 * time.Hour
+	webPushRecordSize  = 4096
+)
+
+// webPushHosts are the push services of the browsers we support, matched as
+// the endpoint host or its parent domain, so a stored subscription cannot
+// point the server at arbitrary hosts
+var webPushHosts = []string{
+	// Chrome, Edge and Opera
+	"fcm.googleapis.com",
+	"android.googleapis.com",
+	// Firefox
+	"push.services.mozilla.com",
+	// Safari
+	"push.apple.com",
+	// Edge on Windows
+	"notify.windows.com",
+}
+
+// WebPushSubscription is the PushSubscription a browser returns from
+// pushManager.subscribe, stored as JSON as the channel target
+type WebPushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// WebPush sends encrypted push messages (RFC 8291) to browser push services,
+// identifying the server with a VAPID key (RFC 8292)
+type WebPush struct {
+	client  *httpclient.Client
+	key     *ecdsa.PrivateKey
+	subject string
+}
+
+// NewWebPush takes the VAPID private key as the base64url encoded P-256
+// scalar produced by common web-push key generators. subject is a mailto: or
+// https: contact for push services.
+func NewWebPush(client *httpclient.Client, privateKey, subject string) (*WebPush, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("VAPID private key: %w", err)
+	}
+	return &WebPush{client: client, key: key, subject: subject}, nil
+}
+
+// PublicKey is the applicationServerKey browsers subscribe with
+func (w *WebPush) PublicKey() string {
+	pub, _ := w.key.PublicKey.Bytes()
+	return base64.RawURLEncoding.EncodeToString(pub)
+}
+
+func (w *WebPush) Validate(target string) error {
+	_, err := parseSubscription(target)
+	return err
+}
+
+func (w *WebPush) Send(ctx context.Context, target string, msg Message) error {
+	sub, err := parseSubscription(target)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	body, err := encryptWebPush(sub, payload)
+	if err != nil {
+		return err
+	}
+	token, err := w.vapidToken(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+w.PublicKey())
+	return send(w.client, req)
+}
+
+func parseSubscription(target string) (*WebPushSubscription, error) {
+	var sub WebPushSubscription
+	if err := json.Unmarshal([]byte(target), &sub); err != nil {
+		return nil, ErrInvalidTarget
+	}
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" || !knownPushHost(u.Hostname()) {
+		return nil, ErrInvalidTarget
+	}
+	if _, err := decodeKey(sub.Keys.P256dh); err != nil {
+		return nil, ErrInvalidTarget
+	}
+	if auth, err := decodeKey(sub.Keys.Auth); err != nil || len(auth) != 16 {
+		return nil, ErrInvalidTarget
+	}
+	return &sub, nil
+}
+
+func knownPushHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, known := range webPushHosts {
+		if host == known || strings.HasSuffix(host, "."+known) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeKey accepts subscription keys with or without base64 padding
+func decodeKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(string(bytes.TrimRight([]byte(s), "=")))
+}
+
+// vapidToken signs the ES256 JWT that push services check against the
+// public key sent alongside it
+func (w *WebPush) vapidToken(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	return signES256(w.key, map[string]interface{}{"typ": "JWT", "alg": "ES256"}, map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(webPushTokenExpiry).Unix(),
+		"sub": w.subject,
+	})
+}
+
+// encryptWebPush seals payload for the subscription as a single aes128gcm
+// record (RFC 8188) keyed as described in RFC 8291, with a fresh key pair
+// and salt
+func encryptWebPush(sub *WebPushSubscription, payload []byte) ([]byte, error) {
+	uaRaw, err := decodeKey(sub.Keys.P256dh)
+	if err != nil {
+		return nil, err
+	}
+	authSecret, err := decodeKey(sub.Keys.Auth)
+	if err != nil {
+		return nil, err
+	}
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return sealWebPush(uaRaw, authSecret, asPrivate, salt, payload)
+}
+
+// sealWebPush does the work of encryptWebPush with the application server
+// key pair and salt given, so it can be checked against the RFC's example
+func sealWebPush(uaRaw, authSecret []byte, asPrivate *ecdh.PrivateKey, salt, payload []byte) ([]byte, error) {
+	uaPublic, err := ecdh.P256().NewPublicKey(uaRaw)
+	if err != nil {
+		return nil, ErrInvalidTarget
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	prkKey, err := hkdf.Extract(sha256.New, shared, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaRaw) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record
+	record := append(append([]byte{}, payload...), 0x02)
+	if len(record)+gcm.Overhead() > webPushRecordSize {
+		return nil, fmt.Errorf("web push payload of %d bytes is too large", len(payload))
+	}
+
+	var body bytes.Buffer
+	body.Write(salt)
+	binary.Write(&body, binary.BigEndian, uint32(webPushRecordSize))
+	body.WriteByte(byte(len(asPublic)))
+	body.Write(asPublic)
+	body.Write(gcm.Seal(nil, nonce, record, nil))
+	return body.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// The example of RFC 8291 appendix A
+const (
+	rfc8291Plaintext = "When I grow up, I want to be a watermelon"
+	rfc8291ASPrivate = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfc8291ASPublic  = "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"
+	rfc8291UAPublic  = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfc8291Auth      = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfc8291Salt      = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfc8291Body      = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func decodeTestKey(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+func TestSealWebPushRFC8291Example(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(decodeTestKey(t, rfc8291ASPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(asPrivate.PublicKey().Bytes()); got != rfc8291ASPublic {
+		t.Fatalf("application server public key = %s, want %s", got, rfc8291ASPublic)
+	}
+
+	body, err := sealWebPush(
+		decodeTestKey(t, rfc8291UAPublic),
+		decodeTestKey(t, rfc8291Auth),
+		asPrivate,
+		decodeTestKey(t, rfc8291Salt),
+		[]byte(rfc8291Plaintext),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(body); got != rfc8291Body {
+		t.Errorf("body = %s\nwant %s", got, rfc8291Body)
+	}
+}
+
+func TestEncryptWebPushUsesFreshKeys(t *testing.T) {
+	sub := &WebPushSubscription{}
+	sub.Keys.P256dh = rfc8291UAPublic
+	sub.Keys.Auth = rfc8291Auth
+
+	first, err := encryptWebPush(sub, []byte(rfc8291Plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := encryptWebPush(sub, []byte(rfc8291Plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// salt (16), record size (4), key length (1), key (65), then the record
+	if len(first) != 86+len(rfc8291Plaintext)+1+16 {
+		t.Errorf("body is %d bytes", len(first))
+	}
+	if string(first[:16]) == string(second[:16]) || string(first[21:86]) == string(second[21:86]) {
+		t.Error("two messages share a salt or server key")
+	}
+}
+
+func TestParseSubscription(t *testing.T) {
+	target := func(endpoint string) string {
+		sub := WebPushSubscription{Endpoint: endpoint}
+		sub.Keys.P256dh = rfc8291UAPublic
+		sub.Keys.Auth = rfc8291Auth
+		b, _ := json.Marshal(sub)
+		return string(b)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		valid  bool
+	}{
+		{"firebase", target("https://fcm.googleapis.com/fcm/send/abc"), true},
+		{"mozilla", target("https://updates.push.services.mozilla.com/wpush/v2/abc"), true},
+		{"apple", target("https://web.push.apple.com/abc"), true},
+		{"windows", target("https://wns2-par02p.notify.windows.com/w/?token=abc"), true},
+		{"host in capitals", target("https://FCM.googleapis.com/fcm/send/abc"), true},
+		{"plain http", target("http://fcm.googleapis.com/fcm/send/abc"), false},
+		{"unknown host", target("https://push.example.com/abc"), false},
+		{"internal address", target("https://169.254.169.254/latest/meta-data"), false},
+		{"localhost", target("https://localhost/abc"), false},
+		{"known host as a suffix only", target("https://evilfcm.googleapis.com.example.com/abc"), false},
+		{"known host without a dot before it", target("https://evilpush.apple.com/abc"), false},
+		{"explicit port", target("https://fcm.googleapis.com:8443/fcm/send/abc"), false},
+		{"credentials", target("https://user@fcm.googleapis.com/fcm/send/abc"), false},
+		{"missing keys", `{"endpoint":"https://fcm.googleapis.com/fcm/send/abc"}`, false},
+		{"not json", "https://fcm.googleapis.com/fcm/send/abc", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSubscription(tt.target)
+			if tt.valid && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidTarget) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidTarget)
+			}
+		})
+	}
+}
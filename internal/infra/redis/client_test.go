@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer answers each command with the reply scripted for it and
+// records the commands and how many connections were opened
+type fakeServer struct {
+	replies map[string]string
+
+	mu       sync.Mutex
+	commands []string
+	conns    int
+}
+
+func (s *fakeServer) start(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(c)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func (s *fakeServer) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		command := strings.Join(args, " ")
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+		reply, ok := s.replies[command]
+		if !ok {
+			reply = "-ERR unknown command\r\n"
+		}
+		io.WriteString(c, reply)
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+func TestClientDo(t *testing.T) {
+	server := &fakeServer{replies: map[string]string{
+		"AUTH secret":           "+OK\r\n",
+		"SELECT 2":              "+OK\r\n",
+		"GET missing":           "$-1\r\n",
+		"GET key":               "$5\r\nvalue\r\n",
+		"INCR counter":          ":7\r\n",
+		"HMGET entry a b":       "*2\r\n$1\r\n1\r\n$-1\r\n",
+		"EVAL return bad 0":     "-ERR bad script\r\n",
+		"SET key with\r\nbreak": "+OK\r\n",
+	}}
+	client := New(server.start(t), "secret", 2, time.Second)
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		args    []string
+		want    interface{}
+		wantErr error
+	}{
+		{[]string{"GET", "missing"}, nil, nil},
+		{[]string{"GET", "key"}, "value", nil},
+		{[]string{"INCR", "counter"}, int64(7), nil},
+		{[]string{"HMGET", "entry", "a", "b"}, []interface{}{"1", nil}, nil},
+		{[]string{"EVAL", "return bad", "0"}, nil, Error("ERR bad script")},
+		{[]string{"SET", "key", "with\r\nbreak"}, "OK", nil},
+	}
+	for _, tt := range tests {
+		got, err := client.Do(ctx, tt.args...)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("Do(%q) error = %v, want %v", tt.args, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Do(%q) = %#v, want %#v", tt.args, got, tt.want)
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.conns != 1 {
+		t.Errorf("opened %d connections, want one reused after the error reply", server.conns)
+	}
+	if len(server.commands) < 2 || server.commands[0] != "AUTH secret" || server.commands[1] != "SELECT 2" {
+		t.Errorf("commands = %q, want AUTH and SELECT first", server.commands)
+	}
+}
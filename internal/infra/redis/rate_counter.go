@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// incrScript counts a request and lets the window's key expire with the
+// window
+const incrScript = `
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+return n`
+
+// RateCounter counts requests per client and window in Redis, so every
+// instance using the same server enforces one limit. It satisfies
+// service.RateCounter.
+type RateCounter struct {
+	client *Client
+}
+
+func NewRateCounter(client *Client) *RateCounter {
+	return &RateCounter{client: client}
+}
+
+func (c *RateCounter) Add(ctx context.Context, client string, start time.Time, window time.Duration) (int, error) {
+	key := "bms:ratelimit:" + strconv.FormatInt(start.Unix(), 10) + ":" + client
+	reply, err := c.client.Do(ctx, "EVAL", incrScript, "1", key, strconv.FormatInt(window.Milliseconds(), 10))
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, errProtocol
+	}
+	return int(n), nil
+}
@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"bms-go/internal/infra/cache"
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	cachePrefix        = "bms:cache:"
+	cacheGenerationKey = cachePrefix + "generation"
+)
+
+// staleGeneration is returned when the generation cannot be read, so the
+// response built meanwhile is not stored
+const staleGeneration = math.MaxUint64
+
+// setScript stores a response as a hash and adds it to its tag sets, unless
+// the generation moved on. KEYS are the generation, the entry and the tag
+// sets; ARGV the expected generation, the TTL in milliseconds and the
+// response fields.
+const setScript = `
+if (redis.call('GET', KEYS[1]) or '0') ~= ARGV[1] then return 0 end
+redis.call('DEL', KEYS[2])
+redis.call('HSET', KEYS[2], 'status', ARGV[3], 'content_type', ARGV[4], 'body', ARGV[5])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+for i = 3, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[2])
+	redis.call('PEXPIRE', KEYS[i], ARGV[2])
+end
+return 1`
+
+// invalidateScript moves the generation on and deletes every entry in the
+// tag sets given as KEYS[2:], then the sets themselves
+const invalidateScript = `
+redis.call('INCR', KEYS[1])
+for i = 2, #KEYS do
+	for _, key in ipairs(redis.call('SMEMBERS', KEYS[i])) do
+		redis.call('DEL', key)
+	end
+	redis.call('DEL', KEYS[i])
+end
+return 1`
+
+// ResponseStore is a cache.Store shared by every instance using the same
+// Redis server. Entries expire with Redis TTLs, so the server's maxmemory
+// policy rather than an entry count bounds its size. Redis errors are logged
+// and treated as misses.
+type ResponseStore struct {
+	client *Client
+}
+
+var _ cache.Store = (*ResponseStore)(nil)
+
+func NewResponseStore(client *Client) *ResponseStore {
+	return &ResponseStore{client: client}
+}
+
+func (s *ResponseStore) Get(key string) (cache.Response, bool) {
+	reply, err := s.client.Do(context.Background(), "HMGET", cachePrefix+"entry:"+key, "status", "content_type", "body")
+	if err != nil {
+		log.Printf("cache: reading %s: %v", key, err)
+		return cache.Response{}, false
+	}
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields) != 3 {
+		return cache.Response{}, false
+	}
+	status, _ := fields[0].(string)
+	contentType, _ := fields[1].(string)
+	body, _ := fields[2].(string)
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return cache.Response{}, false
+	}
+	return cache.Response{Status: code, ContentType: contentType, Body: []byte(body)}, true
+}
+
+func (s *ResponseStore) Generation() uint64 {
+	reply, err := s.client.Do(context.Background(), "GET", cacheGenerationKey)
+	if err != nil {
+		log.Printf("cache: reading generation: %v", err)
+		return staleGeneration
+	}
+	if reply == nil {
+		return 0
+	}
+	value, _ := reply.(string)
+	generation, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return staleGeneration
+	}
+	return generation
+}
+
+func (s *ResponseStore) Set(key string, resp cache.Response, ttl time.Duration, generation uint64, tags []string) {
+	if generation == staleGeneration {
+		return
+	}
+	args := []string{"EVAL", setScript, strconv.Itoa(2 + len(tags)), cacheGenerationKey, cachePrefix + "entry:" + key}
+	for _, tag := range tags {
+		args = append(args, cachePrefix+"tag:"+tag)
+	}
+	args = append(args,
+		strconv.FormatUint(generation, 10),
+		strconv.FormatInt(ttl.Milliseconds(), 10),
+		strconv.Itoa(resp.Status),
+		resp.ContentType,
+		string(resp.Body),
+	)
+	if _, err := s.client.Do(context.Background(), args...); err != nil {
+		log.Printf("cache: storing %s: %v", key, err)
+	}
+}
+
+func (s *ResponseStore) Invalidate(tags []string) {
+	args := []string{"EVAL", invalidateScript, strconv.Itoa(1 + len(tags)), cacheGenerationKey}
+	for _, tag := range tags {
+		args = append(args, cachePrefix+"tag:"+tag)
+	}
+	if _, err := s.client.Do(context.Background(), args...); err != nil {
+		log.Printf("cache: invalidating %v: %v", tags, err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package remote talks to other bms-go deployments over their REST API.
+package remote
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bms-go/internal/model"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const pageSize = 100
+
+// Catalog reads and writes the book catalog of another deployment
+type Catalog struct {
+	ctx     context.Context
+	baseURL string
+	apiKey  string
+	client  *httpclient.Client
+}
+
+// NewCatalog returns a client for the deployment at baseURL. apiKey is sent
+// as X-API-Key when set.
+func NewCatalog(baseURL, apiKey string, client *httpclient.Client) *Catalog {
+	return &Catalog{
+		ctx:     context.Background(),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  client,
+	}
+}
+
+// WithContext returns a copy of the catalog whose calls are bound to ctx,
+// so they end with the request or job they are made for
+func (c *Catalog) WithContext(ctx context.Context) *Catalog {
+	bound := *c
+	bound.ctx = ctx
+	return &bound
+}
+
+// BaseURL is the deployment this catalog reads and writes
+func (c *Catalog) BaseURL() string {
+	return c.baseURL
+}
+
+// Book fetches one book by its id on the deployment
+func (c *Catalog) Book(id uint) (*model.Book, error) {
+	var book model.Book
+	if err := c.do(http.MethodGet, "/books/"+strconv.FormatUint(uint64(id), 10), nil, &book); err != nil {
+		return nil, err
+	}
+	book = portable(book)
+	return &book, nil
+}
+
+// Books pages through GET /books in id order
+func (c *Catalog) Books() ([]model.Book, error) {
+	var all []model.Book
+	for offset := 0; ; offset += pageSize {
+		q := url.Values{}
+		q.Set("sort_by", "id")
+		q.Set("limit", strconv.Itoa(pageSize))
+		q.Set("offset", strconv.Itoa(offset))
+
+		var page []model.Book
+		if err := c.do(http.MethodGet, "/books?"+q.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		for _, book := range page {
+			all = append(all, portable(book))
+		}
+		if len(page) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// CreateBook adds book to the deployment. The other deployment records it
+// as added by hand, so upstream is not used.
+func (c *Catalog) CreateBook(book *model.Book, upstream *model.UpstreamRecord) error {
+	return c.do(http.MethodPost, "/books", portable(*book), book)
+}
+
+func (c *Catalog) UpdateBook(book *model.Book) error {
+	return c.do(http.MethodPut, "/books/"+strconv.FormatUint(uint64(book.ID), 10), portable(*book), book)
+}
+
+// portable drops the category and author ids of a book passed between
+// deployments, which number their categories and authors separately, so it
+// is filed by their names instead. Its stock is the deployment's own, so
+// the book starts with the default copies.
+func portable(book model.Book) model.Book {
+	book.CategoryID = nil
+	book.Copies = 0
+	authors := make([]model.Author, len(book.Authors))
+	for i, author := range book.Authors {
+		authors[i] = model.Author{Name: author.Name}
+	}
+	book.Authors = authors
+	return book
+}
+
+func (c *Catalog) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+func userSubject(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func (r *AccountRepository) FindFavorites(userID uint) ([]model.Favorite, error) {
+	var favorites []model.Favorite
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&favorites).Error; err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+func (r *AccountRepository) FindViews(userID uint) ([]model.BookView, error) {
+	var views []model.BookView
+	if err := r.db.Where("user_id = ?", userID).Order("viewed_at DESC").Find(&views).Error; err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+func (r *AccountRepository) FindUsage(userID uint) ([]model.APIUsage, error) {
+	var usage []model.APIUsage
+	if err := r.db.Where("subject = ?", userSubject(userID)).Order("period").Find(&usage).Error; err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// UserRecords are the rows about a user that are exported and erased with
+// their account, besides favorites, views and API usage
+type UserRecords struct {
+	// Account is nil when the user has no account row
+	Account              *model.User
+	Reviews              []model.Review
+	ReadingStatuses      []model.ReadingStatus
+	Loans                []model.Loan
+	Reservations         []model.Reservation
+	NotificationChannels []model.NotificationPreference
+	Devices              []model.Device
+	Notifications        []model.InboxNotification
+	RSVPs                []model.EventRSVP
+	ILLRequests          []model.ILLRequest
+	Collections          []model.Collection
+	CollectionBooks      []model.CollectionBook
+	Memberships          []model.OrganizationMember
+	Invitations          []model.OrganizationInvitation
+	// Quota is nil when the user has the default quota
+	Quota *model.APIQuota
+}
+
+// FindRecords loads the user's records, each kind oldest first. It reads
+// the same tables EraseUser deletes from.
+func (r *AccountRepository) FindRecords(userID uint) (*UserRecords, error) {
+	var rec UserRecords
+	var user model.User
+	err := r.db.First(&user, userID).Error
+	if err == nil {
+		rec.Account = &user
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	owned := []struct {
+		dest  interface{}
+		order string
+	}{
+		{&rec.Reviews, "id"},
+		{&rec.ReadingStatuses, "id"},
+		{&rec.Loans, "id"},
+		{&rec.Reservations, "id"},
+		{&rec.NotificationChannels, "channel"},
+		{&rec.Devices, "id"},
+		{&rec.Notifications, "id"},
+		{&rec.RSVPs, "event_id"},
+		{&rec.Collections, "id"},
+		{&rec.Memberships, "organization_id"},
+		{&rec.Invitations, "id"},
+	}
+	for _, o := range owned {
+		if err := r.db.Where("user_id = ?", userID).Order(o.order).Find(o.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	err = r.db.Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("user_id = ?", userID).Order("id").Find(&rec.ILLRequests).Error
+	if err != nil {
+		return nil, err
+	}
+	collections := r.db.Model(&model.Collection{}).Select("id").Where("user_id = ?", userID)
+	if err := r.db.Where("collection_id IN (?)", collections).Order("collection_id, created_at").Find(&rec.CollectionBooks).Error; err != nil {
+		return nil, err
+	}
+
+	var quota model.APIQuota
+	err = r.db.Where("subject = ?", userSubject(userID)).First(&quota).Error
+	if err == nil {
+		rec.Quota = &quota
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *AccountRepository) CreateDeletion(deletion *model.AccountDeletion) error {
+	return r.db.Create(deletion).Error
+}
+
+func (r *AccountRepository) SaveDeletion(deletion *model.AccountDeletion) error {
+	return r.db.Save(deletion).Error
+}
+
+// FindPendingDeletion returns the user's deletion request that is neither
+// cancelled nor completed, or nil
+func (r *AccountRepository) FindPendingDeletion(userID uint) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	err := r.db.Where("user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL", userID).
+		First(&deletion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// FindDueDeletions returns pending deletions whose grace period has ended
+func (r *AccountRepository) FindDueDeletions(now time.Time) ([]model.AccountDeletion, error) {
+	var deletions []model.AccountDeletion
+	err := r.db.Where("cancelled_at IS NULL AND completed_at IS NULL AND scheduled_for <= ?", now).
+		Order("scheduled_for").
+		Find(&deletions).Error
+	if err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+func (r *AccountRepository) FindDeletions() ([]model.AccountDeletion, error) {
+	var deletions []model.AccountDeletion
+	if err := r.db.Order("id DESC").Find(&deletions).Error; err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+// EraseUser permanently removes the user's personal data and marks deletion
+// completed with the removed row counts, in one transaction. Tables added
+// here are also read by FindRecords, so the data export covers them.
+func (r *AccountRepository) EraseUser(deletion *model.AccountDeletion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Take the user's live favorites off the books' counts before they
+		// go; soft-deleted ones were already taken off when removed
+		err := tx.Exec(`UPDATE books SET favorite_count = favorite_count -
+			(SELECT COUNT(*) FROM favorites WHERE favorites.book_id = books.id AND favorites.user_id = ? AND favorites.deleted_at IS NULL)
+			WHERE id IN (SELECT book_id FROM favorites WHERE user_id = ? AND deleted_at IS NULL)`,
+			deletion.UserID, deletion.UserID).Error
+		if err != nil {
+			return err
+		}
+
+		res := tx.Unscoped().Where("user_id = ?", deletion.UserID).Delete(&model.Favorite{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deletion.Favorites = res.RowsAffected
+
+		// The books the user reviewed are summarised again without them
+		var reviewed []uint
+		if err := tx.Model(&model.Review{}).Where("user_id = ?", deletion.UserID).Pluck("book_id", &reviewed).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.Review{}).Error; err != nil {
+			return err
+		}
+		if err := refreshRatings(tx, reviewed); err != nil {
+			return err
+		}
+
+		res = tx.Where("user_id = ?", deletion.UserID).Delete(&model.BookView{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deletion.BookViews = res.RowsAffected
+
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.ReadingStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.PrivacySetting{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.ReminderSetting{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.Device{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.InboxNotification{}).Error; err != nil {
+			return err
+		}
+		if err := eraseLoans(tx, deletion.UserID); err != nil {
+			return err
+		}
+		if err := eraseReservations(tx, deletion.UserID); err != nil {
+			return err
+		}
+		if err := eraseRSVPs(tx, deletion.UserID); err != nil {
+			return err
+		}
+		if err := eraseILLRequests(tx, deletion.UserID); err != nil {
+			return err
+		}
+		if err := eraseCollections(tx, deletion.UserID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", deletion.UserID).Delete(&model.OrganizationInvitation{}).Error; err != nil {
+			return err
+		}
+		subject := userSubject(deletion.UserID)
+		if err := tx.Where("subject = ?", subject).Delete(&model.APIUsage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subject = ?", subject).Delete(&model.APIQuota{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&model.User{}, deletion.UserID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		deletion.CompletedAt = &now
+		return tx.Save(deletion).Error
+	})
+}
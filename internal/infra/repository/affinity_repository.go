@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+
+	"gorm.io/gorm"
+)
+
+// affinityInsertBatch is how many affinities are inserted per statement
+const affinityInsertBatch = 1000
+
+// consentingFavorites are the live favorites of users who allowed their
+// activity to be used for analytics
+const consentingFavorites = "SELECT favorites.user_id, favorites.book_id FROM favorites " +
+	"JOIN privacy_settings ON privacy_settings.user_id = favorites.user_id AND privacy_settings.allow_analytics = true " +
+	"WHERE favorites.deleted_at IS NULL"
+
+type AffinityRepository struct {
+	db *gorm.DB
+}
+
+func NewAffinityRepository(db *gorm.DB) *AffinityRepository {
+	return &AffinityRepository{db: db}
+}
+
+// CoFavorites counts, for every ordered pair of books, the consenting users
+// who favorited both, keeping pairs with at least minUsers
+func (r *AffinityRepository) CoFavorites(minUsers int) ([]dto.CoFavoriteCount, error) {
+	var counts []dto.CoFavoriteCount
+	err := r.db.Raw("SELECT a.book_id, b.book_id AS related_book_id, COUNT(DISTINCT a.user_id) AS users "+
+		"FROM ("+consentingFavorites+") a JOIN ("+consentingFavorites+") b ON b.user_id = a.user_id AND b.book_id <> a.book_id "+
+		"GROUP BY a.book_id, b.book_id HAVING COUNT(DISTINCT a.user_id) >= ?", minUsers).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// FavoriteCounts counts the consenting users who favorited each book
+func (r *AffinityRepository) FavoriteCounts() (map[uint]int, error) {
+	var rows []struct {
+		BookID uint
+		Users  int
+	}
+	err := r.db.Raw("SELECT f.book_id, COUNT(DISTINCT f.user_id) AS users FROM (" + consentingFavorites + ") f GROUP BY f.book_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.BookID] = row.Users
+	}
+	return counts, nil
+}
+
+// Replace swaps the whole graph for affinities in one transaction, so
+// lookups never see a half-built graph
+func (r *AffinityRepository) Replace(affinities []model.BookAffinity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.BookAffinity{}).Error; err != nil {
+			return err
+		}
+		if len(affinities) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(affinities, affinityInsertBatch).Error
+	})
+}
+
+// FindRelated returns up to limit of bookID's affinities with books that
+// have not been deleted, strongest first
+func (r *AffinityRepository) FindRelated(bookID uint, limit int) ([]model.BookAffinity, error) {
+	var affinities []model.BookAffinity
+	err := r.db.Select("book_affinities.*").
+		Joins("JOIN books ON books.id = book_affinities.related_book_id AND books.deleted_at IS NULL").
+		Where("book_affinities.book_id = ?", bookID).
+		Order("book_affinities.score DESC").
+		Order("book_affinities.related_book_id").
+		Limit(limit).
+		Find(&affinities).Error
+	return affinities, err
+}
+
+// Recommend sums the affinities of userID's favorites with the books they
+// have not favorited yet and returns the limit strongest
+func (r *AffinityRepository) Recommend(userID uint, limit int) ([]dto.Recommendation, error) {
+	favorited := r.db.Model(&model.Favorite{}).Select("book_id").Where("user_id = ?", userID)
+
+	var recs []dto.Recommendation
+	err := r.db.Model(&model.BookAffinity{}).
+		Select("book_affinities.related_book_id AS book_id, SUM(book_affinities.score) AS score").
+		Joins("JOIN books ON books.id = book_affinities.related_book_id AND books.deleted_at IS NULL").
+		Where("book_affinities.book_id IN (?)", favorited).
+		Where("book_affinities.related_book_id NOT IN (?)", favorited).
+		Group("book_affinities.related_book_id").
+		Order("score DESC").
+		Order("book_affinities.related_book_id").
+		Limit(limit).
+		Scan(&recs).Error
+	return recs, err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ArchiveRepository struct {
+	db *gorm.DB
+}
+
+func NewArchiveRepository(db *gorm.DB) *ArchiveRepository {
+	return &ArchiveRepository{db: db}
+}
+
+// ArchiveChangeEvents moves up to limit change events written before cutoff,
+// oldest first, into the archive table in one transaction and returns how
+// many were moved
+func (r *ArchiveRepository) ArchiveChangeEvents(cutoff time.Time, limit int) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var events []model.ChangeEvent
+		if err := tx.Where("created_at < ?", cutoff).Order("seq").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		archived := make([]model.ArchivedChangeEvent, len(events))
+		seqs := make([]uint64, len(events))
+		for i, e := range events {
+			archived[i] = model.ArchivedChangeEvent(e)
+			seqs[i] = e.Seq
+		}
+		if err := tx.Create(&archived).Error; err != nil {
+			return err
+		}
+
+		res := tx.Where("seq IN ?", seqs).Delete(&model.ChangeEvent{})
+		moved = res.RowsAffected
+		return res.Error
+	})
+	return moved, err
+}
+
+// ArchiveImpersonationActions moves up to limit impersonation audit entries
+// recorded before cutoff, oldest first, into the archive table in one
+// transaction and returns how many were moved
+func (r *ArchiveRepository) ArchiveImpersonationActions(cutoff time.Time, limit int) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var actions []model.ImpersonationAction
+		if err := tx.Where("created_at < ?", cutoff).Order("id").Limit(limit).Find(&actions).Error; err != nil {
+			return err
+		}
+		if len(actions) == 0 {
+			return nil
+		}
+
+		archived := make([]model.ArchivedImpersonationAction, len(actions))
+		ids := make([]uint, len(actions))
+		for i, a := range actions {
+			archived[i] = model.ArchivedImpersonationAction(a)
+			ids[i] = a.ID
+		}
+		if err := tx.Create(&archived).Error; err != nil {
+			return err
+		}
+
+		res := tx.Where("id IN ?", ids).Delete(&model.ImpersonationAction{})
+		moved = res.RowsAffected
+		return res.Error
+	})
+	return moved, err
+}
+
+// FindChangeEvents returns up to limit archived change events after afterSeq
+// in sequence order, optionally narrowed to one entity
+func (r *ArchiveRepository) FindChangeEvents(entity string, entityID uint, afterSeq uint64, limit int) ([]model.ArchivedChangeEvent, error) {
+	query := r.db.Where("seq > ?", afterSeq)
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if entityID != 0 {
+		query = query.Where("entity_id = ?", entityID)
+	}
+
+	var events []model.ArchivedChangeEvent
+	if err := query.Order("seq").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// FindImpersonationActions returns the archived audit entries of an
+// impersonation session in the order they were recorded
+func (r *ArchiveRepository) FindImpersonationActions(sessionID uint) ([]model.ArchivedImpersonationAction, error) {
+	var actions []model.ArchivedImpersonationAction
+	if err := r.db.Where("session_id = ?", sessionID).Order("id").Find(&actions).Error; err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// authorSeparator joins a book's author names into Book.Author
+const authorSeparator = ", "
+
+type AuthorRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthorRepository(db *gorm.DB) *AuthorRepository {
+	return &AuthorRepository{db: db}
+}
+
+// FindAll lists every author by name with their number of books
+func (r *AuthorRepository) FindAll() ([]dto.AuthorResponse, error) {
+	var authors []dto.AuthorResponse
+	err := r.db.Model(&model.Author{}).
+		Select("authors.id, authors.name, COUNT(books.id) AS books").
+		Joins("LEFT JOIN book_authors ON book_authors.author_id = authors.id").
+		Joins("LEFT JOIN books ON books.id = book_authors.book_id AND books.deleted_at IS NULL").
+		Group("authors.id, authors.name").
+		Order("authors.name").
+		Scan(&authors).Error
+	if err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+func (r *AuthorRepository) FindByID(id uint) (*model.Author, error) {
+	var author model.Author
+	if err := r.db.First(&author, id).Error; err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// FindByIDs returns the authors with the given ids that exist
+func (r *AuthorRepository) FindByIDs(ids []uint) ([]model.Author, error) {
+	var authors []model.Author
+	if len(ids) == 0 {
+		return authors, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&authors).Error; err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+// FindByName returns the author called name, ignoring case and surrounding
+// spaces
+func (r *AuthorRepository) FindByName(name string) (*model.Author, error) {
+	var author model.Author
+	if err := byName(r.db, name).First(&author).Error; err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// CountBooks returns the number of live books by author id
+func (r *AuthorRepository) CountBooks(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Book{}).
+		Joins("JOIN book_authors ON book_authors.book_id = books.id").
+		Where("book_authors.author_id = ?", id).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *AuthorRepository) Create(author *model.Author) error {
+	return r.db.Create(author).Error
+}
+
+// Rename renames author to name and relabels their books in one
+// transaction, recording a change event for each book. It returns the
+// number of books relabelled.
+func (r *AuthorRepository) Rename(author *model.Author, name string) (int64, error) {
+	var relabelled int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		author.Name = name
+		if err := tx.Save(author).Error; err != nil {
+			return err
+		}
+
+		var books []model.Book
+		if err := tx.Select("books.*").
+			Joins("JOIN book_authors ON book_authors.book_id = books.id").
+			Where("book_authors.author_id = ?", author.ID).
+			Order("books.id").
+			Find(&books).Error; err != nil {
+			return err
+		}
+		if err := loadDetails(tx, books); err != nil {
+			return err
+		}
+		now := time.Now()
+		for i := range books {
+			books[i].Author = joinAuthorNames(books[i].Authors)
+			books[i].UpdatedAt = now
+			if err := tx.Model(&model.Book{}).Where("id = ?", books[i].ID).Updates(map[string]interface{}{"author": books[i].Author, "updated_at": now}).Error; err != nil {
+				return err
+			}
+			if err := recordChange(tx, model.EntityBook, books[i].ID, model.ChangeOpUpdate, books[i]); err != nil {
+				return err
+			}
+		}
+		relabelled = int64(len(books))
+		return nil
+	})
+	return relabelled, err
+}
+
+// Delete removes author id along with their links to deleted books; the
+// caller checks that no live book is left by them
+func (r *AuthorRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("author_id = ?", id).Delete(&model.BookAuthor{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&model.Author{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// BackfillAuthors creates an author for every distinct author name the
+// books carry and links the books to them. Each name becomes one author;
+// names listing several people are left for an editor to split.
+func BackfillAuthors(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var names []string
+		if err := tx.Unscoped().Model(&model.Book{}).
+			Where("TRIM(author) <> ''").
+			Order("MIN(id)").
+			Group("TRIM(author)").
+			Pluck("TRIM(author)", &names).Error; err != nil {
+			return err
+		}
+		for _, name := range names {
+			var author model.Author
+			if err := byName(tx, name).Attrs(model.Author{Name: name}).FirstOrCreate(&author).Error; err != nil {
+				return err
+			}
+			err := tx.Exec("INSERT INTO book_authors (book_id, author_id, position) "+
+				"SELECT id, ?, 0 FROM books WHERE LOWER(TRIM(author)) = ? "+
+				"AND NOT EXISTS (SELECT 1 FROM book_authors WHERE book_authors.book_id = books.id)",
+				author.ID, strings.ToLower(name)).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&model.Book{}).
+				Where("LOWER(TRIM(author)) = ?", strings.ToLower(name)).
+				Update("author", author.Name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// assignAuthors settles which authors book is written with. Its Authors
+// decide, found by id or else by name ignoring case and created when new,
+// unless Author has been changed away from their names: then the book has
+// the one author Author names, unless that is what it already has.
+// Either way Author ends up as the authors' names joined.
+func assignAuthors(tx *gorm.DB, book *model.Book) error {
+	authors, err := resolveAuthors(tx, book.Authors)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSpace(book.Author)
+	if len(authors) > 0 && (name == "" || strings.EqualFold(name, joinAuthorNames(authors))) {
+		book.Authors, book.Author = authors, joinAuthorNames(authors)
+		return nil
+	}
+
+	switch {
+	case name == "":
+		book.Authors = []model.Author{}
+	case book.ID != 0:
+		current := []model.Book{{Model: gorm.Model{ID: book.ID}}}
+		if err := loadAuthors(tx, current); err != nil {
+			return err
+		}
+		if strings.EqualFold(joinAuthorNames(current[0].Authors), name) {
+			book.Authors = current[0].Authors
+			break
+		}
+		fallthrough
+	default:
+		if book.Authors, err = resolveAuthors(tx, []model.Author{{Name: name}}); err != nil {
+			return err
+		}
+	}
+	book.Author = joinAuthorNames(book.Authors)
+	return nil
+}
+
+// resolveAuthors looks up authors by id, or by name when the id is unset,
+// creating the ones named for the first time. Entries without either and
+// repeats are dropped.
+func resolveAuthors(tx *gorm.DB, authors []model.Author) ([]model.Author, error) {
+	resolved := make([]model.Author, 0, len(authors))
+	seen := make(map[uint]bool, len(authors))
+	for _, a := range authors {
+		var author model.Author
+		name := strings.TrimSpace(a.Name)
+		switch {
+		case a.ID != 0:
+			if err := tx.First(&author, a.ID).Error; err != nil {
+				return nil, err
+			}
+		case name != "":
+			if err := byName(tx, name).Attrs(model.Author{Name: name}).FirstOrCreate(&author).Error; err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		if !seen[author.ID] {
+			seen[author.ID] = true
+			resolved = append(resolved, author)
+		}
+	}
+	return resolved, nil
+}
+
+// linkAuthors replaces the book's author links with its Authors, in order.
+// It runs after the book is written so a new book has its id.
+func linkAuthors(tx *gorm.DB, book *model.Book) error {
+	if err := tx.Where("book_id = ?", book.ID).Delete(&model.BookAuthor{}).Error; err != nil {
+		return err
+	}
+	if len(book.Authors) == 0 {
+		return nil
+	}
+	links := make([]model.BookAuthor, len(book.Authors))
+	for i, author := range book.Authors {
+		links[i] = model.BookAuthor{BookID: book.ID, AuthorID: author.ID, Position: i}
+	}
+	return tx.Omit("Author").Create(&links).Error
+}
+
+// loadAuthors fills in each book's Authors in order
+func loadAuthors(db *gorm.DB, books []model.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	var rows []struct {
+		BookID uint
+		model.Author
+	}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table("book_authors").
+		Select("book_authors.book_id, authors.*").
+		Joins("JOIN authors ON authors.id = book_authors.author_id").
+		Where("book_authors.book_id IN ?", ids).
+		Order("book_authors.book_id").
+		Order("book_authors.position").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	byBook := make(map[uint][]model.Author, len(books))
+	for _, row := range rows {
+		byBook[row.BookID] = append(byBook[row.BookID], row.Author)
+	}
+	for i := range books {
+		books[i].Authors = byBook[books[i].ID]
+		if books[i].Authors == nil {
+			books[i].Authors = []model.Author{}
+		}
+	}
+	return nil
+}
+
+// authorExists wraps a condition on authors.name into one matching books
+// with such an author
+func authorExists(condition string) string {
+	return "EXISTS (SELECT 1 FROM book_authors JOIN authors ON authors.id = book_authors.author_id " +
+		"WHERE book_authors.book_id = books.id AND " + condition + ")"
+}
+
+func joinAuthorNames(authors []model.Author) string {
+	names := make([]string, len(authors))
+	for i, author := range authors {
+		names[i] = author.Name
+	}
+	return strings.Join(names, authorSeparator)
+}
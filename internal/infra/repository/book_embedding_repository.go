@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookEmbeddingRepository struct {
+	db *gorm.DB
+}
+
+func NewBookEmbeddingRepository(db *gorm.DB) *BookEmbeddingRepository {
+	return &BookEmbeddingRepository{db: db}
+}
+
+// FindAll returns the embeddings made with embeddingModel of every book that
+// has not been deleted
+func (r *BookEmbeddingRepository) FindAll(embeddingModel string) ([]model.BookEmbedding, error) {
+	var embeddings []model.BookEmbedding
+	err := r.db.Select("book_embeddings.*").
+		Joins("JOIN books ON books.id = book_embeddings.book_id AND books.deleted_at IS NULL").
+		Where("book_embeddings.model = ?", embeddingModel).
+		Find(&embeddings).Error
+	return embeddings, err
+}
+
+// FindStale returns up to limit books after afterID, in id order, whose
+// embedding is missing, was made with another model or predates the book's
+// last change
+func (r *BookEmbeddingRepository) FindStale(embeddingModel string, afterID uint, limit int) ([]model.Book, error) {
+	var books []model.Book
+	err := r.db.Model(&model.Book{}).
+		Select("books.*").
+		Joins("LEFT JOIN book_embeddings ON book_embeddings.book_id = books.id").
+		Where("books.id > ?", afterID).
+		Where("book_embeddings.book_id IS NULL OR book_embeddings.model <> ? OR book_embeddings.updated_at < books.updated_at", embeddingModel).
+		Order("books.id").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
+
+// Save stores embeddings, replacing the books' earlier ones
+func (r *BookEmbeddingRepository) Save(embeddings []model.BookEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&embeddings).Error
+}
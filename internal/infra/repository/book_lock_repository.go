@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookLockRepository struct {
+	db *gorm.DB
+}
+
+func NewBookLockRepository(db *gorm.DB) *BookLockRepository {
+	return &BookLockRepository{db: db}
+}
+
+// Find returns the lock on bookID, or nil when there is none
+func (r *BookLockRepository) Find(bookID uint) (*model.BookLock, error) {
+	var lock model.BookLock
+	err := r.db.First(&lock, "book_id = ?", bookID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// Acquire takes the lock on lock.BookID for lock.HolderID when it is free,
+// expired or already held by the same holder. It reports false when another
+// holder has it.
+func (r *BookLockRepository) Acquire(lock *model.BookLock) (bool, error) {
+	res := r.db.Model(&model.BookLock{}).
+		Where("book_id = ? AND (holder_id = ? OR expires_at < ?)", lock.BookID, lock.HolderID, time.Now()).
+		Updates(map[string]interface{}{
+			"holder_id":   lock.HolderID,
+			"holder_name": lock.HolderName,
+			"acquired_at": lock.AcquiredAt,
+			"expires_at":  lock.ExpiresAt,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+
+	res = r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// Extend pushes the expiry of holderID's unexpired lock on bookID to
+// expiresAt and reports whether holderID still held it
+func (r *BookLockRepository) Extend(bookID, holderID uint, expiresAt time.Time) (bool, error) {
+	res := r.db.Model(&model.BookLock{}).
+		Where("book_id = ? AND holder_id = ? AND expires_at >= ?", bookID, holderID, time.Now()).
+		Update("expires_at", expiresAt)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *BookLockRepository) Release(bookID, holderID uint) error {
+	return r.db.Where("book_id = ? AND holder_id = ?", bookID, holderID).Delete(&model.BookLock{}).Error
+}
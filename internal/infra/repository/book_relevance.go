@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// relevanceComponent is one weighted term of the relevance score. Its SQL
+// yields the raw signal, which is multiplied by the configured weight.
+type relevanceComponent struct {
+	alias  string
+	sql    string
+	vars   []interface{}
+	weight float64
+}
+
+// scoredBook is a book row with the weighted score of each relevance component
+type scoredBook struct {
+	model.Book
+	ScoreExactTitle    float64
+	ScoreTitlePrefix   float64
+	ScoreTitleContains float64
+	ScoreAuthor        float64
+	ScoreDescription   float64
+	ScoreReviews       float64
+	ScoreCategory      float64
+	ScorePopularity    float64
+	RelevanceScore     float64
+}
+
+func (b scoredBook) score() dto.BookScore {
+	return dto.BookScore{
+		BookID: b.ID,
+		Total:  b.RelevanceScore,
+		Components: map[string]float64{
+			"exact_title":    b.ScoreExactTitle,
+			"title_prefix":   b.ScoreTitlePrefix,
+			"title_contains": b.ScoreTitleContains,
+			"author":         b.ScoreAuthor,
+			"description":    b.ScoreDescription,
+			"reviews":        b.ScoreReviews,
+			"category":       b.ScoreCategory,
+			"popularity":     b.ScorePopularity,
+		},
+	}
+}
+
+// relevanceComponents builds the scoring terms for params.Search. Description
+// and reviews only score when they were requested through include_fields.
+func relevanceComponents(params dto.BookQuery) []relevanceComponent {
+	w := params.Weights
+	search := strings.ToLower(params.Search)
+	like := containsPattern(search)
+
+	description := relevanceComponent{alias: "score_description", sql: "0"}
+	if params.Includes(dto.SearchFieldDescription) {
+		description = relevanceComponent{alias: "score_description", sql: "CASE WHEN LOWER(description) LIKE ? THEN 1 ELSE 0 END", vars: []interface{}{like}, weight: w.Description}
+	}
+	reviews := relevanceComponent{alias: "score_reviews", sql: "0"}
+	if params.Includes(dto.SearchFieldReviews) {
+		reviews = relevanceComponent{alias: "score_reviews", sql: "CASE WHEN " + reviewExists("LOWER(reviews.body) LIKE ?") + " THEN 1 ELSE 0 END", vars: []interface{}{like}, weight: w.Reviews}
+	}
+
+	return []relevanceComponent{
+		{alias: "score_exact_title", sql: "CASE WHEN LOWER(title) = ? THEN 1 ELSE 0 END", vars: []interface{}{search}, weight: w.ExactTitle},
+		{alias: "score_title_prefix", sql: "CASE WHEN LOWER(title) LIKE ? THEN 1 ELSE 0 END", vars: []interface{}{prefixPattern(search)}, weight: w.TitlePrefix},
+		{alias: "score_title_contains", sql: "CASE WHEN LOWER(title) LIKE ? THEN 1 ELSE 0 END", vars: []interface{}{like}, weight: w.TitleContains},
+		{alias: "score_author", sql: "CASE WHEN " + authorExists("LOWER(authors.name) LIKE ?") + " THEN 1 ELSE 0 END", vars: []interface{}{like}, weight: w.Author},
+		description,
+		reviews,
+		{alias: "score_category", sql: "CASE WHEN LOWER(category) LIKE ? THEN 1 ELSE 0 END", vars: []interface{}{like}, weight: w.Category},
+		{alias: "score_popularity", sql: "books.favorite_count", weight: w.Popularity},
+	}
+}
+
+// selectRelevance adds the weighted components and their total to the
+// selected columns and orders by the total, breaking ties by id
+func selectRelevance(query *gorm.DB, params dto.BookQuery) *gorm.DB {
+	components := relevanceComponents(params)
+
+	columns := []string{"books.*"}
+	var totals []string
+	var vars []interface{}
+	for _, c := range components {
+		columns = append(columns, "? * ("+c.sql+") AS "+c.alias)
+		vars = append(vars, c.weight)
+		vars = append(vars, c.vars...)
+	}
+	for _, c := range components {
+		totals = append(totals, "? * ("+c.sql+")")
+		vars = append(vars, c.weight)
+		vars = append(vars, c.vars...)
+	}
+	columns = append(columns, "("+strings.Join(totals, " + ")+") AS relevance_score")
+
+	direction := "relevance_score DESC"
+	if params.SortOrder == dto.SortAsc {
+		direction = "relevance_score ASC"
+	}
+
+	return query.Select(strings.Join(columns, ", "), vars...).Order(direction).Order("id")
+}
@@ -2,10 +2,56 @@ package repository
 
 import (
 	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrBookHasLoans is returned when deleting a book some of whose copies are
+// still out on loan
+var ErrBookHasLoans = errors.New("book has copies out on loan")
+
+// bulkSavepoint marks the start of the book being written by CreateMany
+// or DeleteMany, so its changes alone can be rolled back
+const bulkSavepoint = "bulk_book"
+
+// accessibilityColumns maps accessibility features to their flag columns
+var accessibilityColumns = map[model.AccessibilityFeature]string{
+	model.FeatureLargePrint:   "access_large_print",
+	model.FeatureBraille:      "access_braille",
+	model.FeatureAudiobook:    "access_audiobook",
+	model.FeatureDyslexicFont: "access_dyslexic_font",
+}
+
+// bookSortColumns maps validated sort fields to their columns so request
+// input is never concatenated into an ORDER BY clause
+var bookSortColumns = map[dto.BookSortField]string{
+	dto.SortByID:         "id",
+	dto.SortByTitle:      "title",
+	dto.SortByAuthor:     "author",
+	dto.SortByCategory:   "category",
+	dto.SortByCreatedAt:  "created_at",
+	dto.SortByPopularity: "favorite_count",
+}
+
+// bookOrderBy builds the ORDER BY expression for a sort field, falling back to
+// id ascending for anything not in bookSortColumns
+func bookOrderBy(field dto.BookSortField, order dto.SortOrder) clause.OrderByColumn {
+	column, ok := bookSortColumns[field]
+	if !ok {
+		column = bookSortColumns[dto.SortByID]
+	}
+	return clause.OrderByColumn{
+		Column: clause.Column{Name: column},
+		Desc:   order == dto.SortDesc,
+	}
+}
+
 type BookRepository struct {
 	db *gorm.DB
 }
@@ -14,40 +60,539 @@ func NewBookRepository(db *gorm.DB) *BookRepository {
 	return &BookRepository{db: db}
 }
 
-func (r *BookRepository) FindAll(search, category string) ([]model.Book, error) {
-	var books []model.Book
-	query := r.db
+// FindAll lists books matching params. Relevance-ordered searches also return
+// each book's score breakdown when params.Explain is set. The query is
+// cancelled with ctx.
+func (r *BookRepository) FindAll(ctx context.Context, params dto.BookQuery) ([]model.Book, []dto.BookScore, error) {
+	query := r.filterBooks(params).WithContext(ctx)
 
-	if search != "" {
-		query = query.Where("title LIKE ? OR author LIKE ?", "%"+search+"%", "%"+search+"%")
+	if params.Limit > 0 {
+		query = query.Limit(params.Limit)
 	}
 
-	if category != "" {
-		query = query.Where("category = ?", category)
+	if params.Offset > 0 {
+		query = query.Offset(params.Offset)
 	}
 
-	if err := query.Find(&books).Error; err != nil {
+	if params.SortBy != dto.SortByRelevance || params.Search == "" {
+		var books []model.Book
+		if err := query.Order(bookOrderBy(params.SortBy, params.SortOrder)).Find(&books).Error; err != nil {
+			return nil, nil, err
+		}
+		if err := loadDetails(r.db.WithContext(ctx), books); err != nil {
+			return nil, nil, err
+		}
+		return books, nil, nil
+	}
+
+	var rows []scoredBook
+	if err := selectRelevance(query, params).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	books := make([]model.Book, len(rows))
+	var scores []dto.BookScore
+	for i, row := range rows {
+		books[i] = row.Book
+		if params.Explain {
+			scores = append(scores, row.score())
+		}
+	}
+	if err := loadDetails(r.db.WithContext(ctx), books); err != nil {
+		return nil, nil, err
+	}
+	return books, scores, nil
+}
+
+// filterBooks applies the search, category, author, content rating, media
+// type, accessibility and id filters of params
+func (r *BookRepository) filterBooks(params dto.BookQuery) *gorm.DB {
+	query := r.db.Model(&model.Book{})
+
+	if len(params.IDs) > 0 {
+		query = query.Where("id IN ?", params.IDs)
+	}
+
+	if params.Search != "" {
+		query = r.applyBookSearch(query, params)
+	}
+
+	if params.Category != "" {
+		query = query.Where("category = ?", params.Category)
+	}
+
+	if params.Author != "" {
+		query = query.Where(authorExists("authors.name = ?"), params.Author)
+	}
+
+	for _, tag := range params.Tags {
+		query = query.Where(taggedCondition, strings.ToLower(tag))
+	}
+
+	if params.ContentRating != "" {
+		query = query.Where("content_rating = ?", params.ContentRating)
+	}
+
+	if params.MediaType != "" {
+		query = query.Where("media_type = ?", params.MediaType)
+	}
+
+	if params.Source != "" {
+		query = query.Where("source = ?", params.Source)
+	}
+
+	if params.ImportBatchID != 0 {
+		query = query.Where("import_batch_id = ?", params.ImportBatchID)
+	}
+
+	for _, feature := range params.Accessibility {
+		if column, ok := accessibilityColumns[feature]; ok {
+			query = query.Where(column+" = ?", true)
+		}
+	}
+	return query
+}
+
+// FindInBatches passes the books matching params' filters to fn in id
+// order, batchSize books at a time. Limit, offset and sorting are ignored.
+func (r *BookRepository) FindInBatches(ctx context.Context, params dto.BookQuery, batchSize int, fn func([]model.Book) error) error {
+	var batch []model.Book
+	return r.filterBooks(params).WithContext(ctx).FindInBatches(&batch, batchSize, func(_ *gorm.DB, _ int) error {
+		if err := loadDetails(r.db.WithContext(ctx), batch); err != nil {
+			return err
+		}
+		return fn(batch)
+	}).Error
+}
+
+// ReconcileFavoriteCounts recounts each book's live favorites and fixes the
+// books whose stored count has drifted, returning how many were fixed
+func (r *BookRepository) ReconcileFavoriteCounts(ctx context.Context) (int64, error) {
+	count := "(SELECT COUNT(*) FROM favorites WHERE favorites.book_id = books.id AND favorites.deleted_at IS NULL)"
+	res := r.db.WithContext(ctx).Exec("UPDATE books SET favorite_count = " + count + " WHERE favorite_count <> " + count)
+	return res.RowsAffected, res.Error
+}
+
+// FindTitlesAndAuthors returns every book title and author as plain text
+func (r *BookRepository) FindTitlesAndAuthors(ctx context.Context) ([]string, error) {
+	var rows []struct {
+		Title  string
+		Author string
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Book{}).Select("title", "author").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, 0, len(rows)*2)
+	for _, row := range rows {
+		texts = append(texts, row.Title, row.Author)
+	}
+	return texts, nil
+}
+
+// CountByCategory returns each category with its number of books
+func (r *BookRepository) CountByCategory(ctx context.Context) ([]dto.FacetCount, error) {
+	return r.countBy(ctx, "category")
+}
+
+// CountByAuthor returns each author with their number of books
+func (r *BookRepository) CountByAuthor(ctx context.Context) ([]dto.FacetCount, error) {
+	var counts []dto.FacetCount
+	err := r.db.WithContext(ctx).Model(&model.Author{}).
+		Select("authors.name AS value, COUNT(*) AS count").
+		Joins("JOIN book_authors ON book_authors.author_id = authors.id").
+		Joins("JOIN books ON books.id = book_authors.book_id AND books.deleted_at IS NULL").
+		Group("authors.id, authors.name").
+		Order("authors.name").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *BookRepository) countBy(ctx context.Context, column string) ([]dto.FacetCount, error) {
+	var counts []dto.FacetCount
+	err := r.db.WithContext(ctx).Model(&model.Book{}).
+		Select(column + " AS value, COUNT(*) AS count").
+		Where(column + " <> ''").
+		Group(column).
+		Order(column).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *BookRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Book{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindPageByID returns up to limit books ordered by id, skipping offset
+func (r *BookRepository) FindPageByID(ctx context.Context, offset, limit int) ([]model.Book, error) {
+	db := r.db.WithContext(ctx)
+	var books []model.Book
+	if err := db.Order("id").Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	if err := loadDetails(db, books); err != nil {
 		return nil, err
 	}
 	return books, nil
 }
 
-func (r *BookRepository) FindByID(id uint) (*model.Book, error) {
+func (r *BookRepository) FindByID(ctx context.Context, id uint) (*model.Book, error) {
 	var book model.Book
-	if err := r.db.First(&book, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&book, id).Error; err != nil {
 		return nil, err
 	}
-	return &book, nil
+	return r.withDetails(ctx, book)
 }
 
-func (r *BookRepository) Create(book *model.Book) error {
-	return r.db.Create(book).Error
+// FindRandom picks a random book, optionally within category. It jumps to a
+// random point in the id range and takes the next book from there, which
+// stays on the primary key index instead of sorting the table like
+// ORDER BY RAND(). Books after large id gaps are picked slightly more often.
+// A non-empty rating only picks books with that content rating.
+func (r *BookRepository) FindRandom(ctx context.Context, category string, rating model.ContentRating) (*model.Book, error) {
+	scoped := func() *gorm.DB {
+		query := r.db.WithContext(ctx).Model(&model.Book{})
+		if category != "" {
+			query = query.Where("category = ?", category)
+		}
+		if rating != "" {
+			query = query.Where("content_rating = ?", rating)
+		}
+		return query
+	}
+
+	var bounds struct {
+		MinID *uint
+		MaxID *uint
+	}
+	if err := scoped().Select("MIN(id) AS min_id, MAX(id) AS max_id").Scan(&bounds).Error; err != nil {
+		return nil, err
+	}
+	if bounds.MinID == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	pivot := *bounds.MinID + uint(rand.UintN(*bounds.MaxID-*bounds.MinID+1))
+
+	var book model.Book
+	if err := scoped().Where("id >= ?", pivot).Order("id").Take(&book).Error; err != nil {
+		return nil, err
+	}
+	return r.withDetails(ctx, book)
 }
 
-func (r *BookRepository) Update(book *model.Book) error {
-	return r.db.Save(book).Error
+// FindMissingDescriptions returns up to limit books without a description
+// that have no machine generated description pending review or rejected
+func (r *BookRepository) FindMissingDescriptions(ctx context.Context, limit int) ([]model.Book, error) {
+	db := r.db.WithContext(ctx)
+	suggested := db.Model(&model.ChangeRequest{}).
+		Select("1").
+		Where("change_requests.book_id = books.id AND change_requests.machine_generated = ? AND change_requests.status IN ?", true, []model.ChangeRequestStatus{model.ChangePending, model.ChangeRejected})
+
+	var books []model.Book
+	err := db.Where("description = ''").
+		Where("NOT EXISTS (?)", suggested).
+		Order("id").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
 }
 
-func (r *BookRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Book{}, id).Error
+// FindSimilarCandidates returns up to limit other books sharing book's
+// category or one of its authors, most favorited first, optionally only
+// those with rating
+func (r *BookRepository) FindSimilarCandidates(ctx context.Context, book model.Book, rating model.ContentRating, limit int) ([]model.Book, error) {
+	db := r.db.WithContext(ctx)
+	authorIDs := make([]uint, len(book.Authors))
+	for i, author := range book.Authors {
+		authorIDs[i] = author.ID
+	}
+	query := db.Where("id <> ?", book.ID)
+	if len(authorIDs) > 0 {
+		query = query.Where("category = ? OR EXISTS (SELECT 1 FROM book_authors WHERE book_authors.book_id = books.id AND book_authors.author_id IN ?)", book.Category, authorIDs)
+	} else {
+		query = query.Where("category = ?", book.Category)
+	}
+	if rating != "" {
+		query = query.Where("content_rating = ?", rating)
+	}
+
+	var books []model.Book
+	if err := query.Order("favorite_count DESC").Order("id").Limit(limit).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	if err := loadDetails(db, books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// loadDetails fills in each book's authors and tags
+func loadDetails(db *gorm.DB, books []model.Book) error {
+	if err := loadAuthors(db, books); err != nil {
+		return err
+	}
+	return loadTags(db, books)
+}
+
+// withDetails loads book's authors and tags and returns it
+func (r *BookRepository) withDetails(ctx context.Context, book model.Book) (*model.Book, error) {
+	books := []model.Book{book}
+	if err := loadDetails(r.db.WithContext(ctx), books); err != nil {
+		return nil, err
+	}
+	return &books[0], nil
+}
+
+func (r *BookRepository) FindByIDs(ctx context.Context, ids []uint) ([]model.Book, error) {
+	db := r.db.WithContext(ctx)
+	var books []model.Book
+	if err := db.Where("id IN ?", ids).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	if err := loadDetails(db, books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// FindByTitles returns the books whose title matches one of titles, ignoring
+// case
+func (r *BookRepository) FindByTitles(ctx context.Context, titles []string) ([]model.Book, error) {
+	db := r.db.WithContext(ctx)
+	var books []model.Book
+	if len(titles) == 0 {
+		return books, nil
+	}
+	lowered := make([]string, len(titles))
+	for i, t := range titles {
+		lowered[i] = strings.ToLower(t)
+	}
+	if err := db.Where("LOWER(title) IN ?", lowered).Order("id").Find(&books).Error; err != nil {
+		return nil, err
+	}
+	if err := loadDetails(db, books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// FindByCategory returns a page of the books in category id by title, with
+// the number of books in it. A rating limits both to books with that rating.
+func (r *BookRepository) FindByCategory(ctx context.Context, id uint, rating model.ContentRating, limit, offset int) ([]model.Book, int64, error) {
+	db := r.db.WithContext(ctx)
+	inCategory := func() *gorm.DB {
+		query := db.Model(&model.Book{}).Where("category_id = ?", id)
+		if rating != "" {
+			query = query.Where("content_rating = ?", rating)
+		}
+		return query
+	}
+	var total int64
+	if err := inCategory().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var books []model.Book
+	err := inCategory().
+		Order("title").
+		Order("id").
+		Limit(limit).
+		Offset(offset).
+		Find(&books).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := loadDetails(db, books); err != nil {
+		return nil, 0, err
+	}
+	return books, total, nil
+}
+
+// FindByAuthor returns a page of author id's books by title, with the
+// number of books by them. A rating limits both to books with that rating.
+func (r *BookRepository) FindByAuthor(ctx context.Context, id uint, rating model.ContentRating, limit, offset int) ([]model.Book, int64, error) {
+	db := r.db.WithContext(ctx)
+	byAuthor := func() *gorm.DB {
+		query := db.Model(&model.Book{}).
+			Joins("JOIN book_authors ON book_authors.book_id = books.id").
+			Where("book_authors.author_id = ?", id)
+		if rating != "" {
+			query = query.Where("books.content_rating = ?", rating)
+		}
+		return query
+	}
+	var total int64
+	if err := byAuthor().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var books []model.Book
+	err := byAuthor().
+		Select("books.*").
+		Order("books.title").
+		Order("books.id").
+		Limit(limit).
+		Offset(offset).
+		Find(&books).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := loadDetails(db, books); err != nil {
+		return nil, 0, err
+	}
+	return books, total, nil
+}
+
+func (r *BookRepository) Create(ctx context.Context, book *model.Book) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createBook(tx, book)
+	})
+}
+
+// CreateMany creates books in one transaction. Each book is written behind
+// a savepoint, so one that fails is rolled back alone and the others are
+// still committed; errs[i] is why books[i] failed, or nil.
+func (r *BookRepository) CreateMany(ctx context.Context, books []model.Book) (errs []error, err error) {
+	errs = make([]error, len(books))
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range books {
+			failed, err := withSavepoint(tx, func() error {
+				return createBook(tx, &books[i])
+			})
+			if err != nil {
+				return err
+			}
+			errs[i] = failed
+		}
+		return nil
+	})
+	return errs, err
+}
+
+func createBook(tx *gorm.DB, book *model.Book) error {
+	if err := assignCategory(tx, book); err != nil {
+		return err
+	}
+	if err := assignAuthors(tx, book); err != nil {
+		return err
+	}
+	if err := tx.Create(book).Error; err != nil {
+		return err
+	}
+	if err := linkAuthors(tx, book); err != nil {
+		return err
+	}
+	// A new book starts with the copies it was written with, or one
+	if book.Copies <= 0 {
+		book.Copies = 1
+	}
+	if err := addCopies(tx, book.ID, book.Copies); err != nil {
+		return err
+	}
+	if err := refreshStock(tx, book.ID); err != nil {
+		return err
+	}
+	book.Tags = []model.Tag{}
+	book.AvailableCopies, book.Available = book.Copies, true
+	return recordChange(tx, model.EntityBook, book.ID, model.ChangeOpCreate, book)
+}
+
+func (r *BookRepository) Update(ctx context.Context, book *model.Book) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := assignCategory(tx, book); err != nil {
+			return err
+		}
+		if err := assignAuthors(tx, book); err != nil {
+			return err
+		}
+		if err := tx.Save(book).Error; err != nil {
+			return err
+		}
+		if err := linkAuthors(tx, book); err != nil {
+			return err
+		}
+		if err := reloadTags(tx, book); err != nil {
+			return err
+		}
+		return recordChange(tx, model.EntityBook, book.ID, model.ChangeOpUpdate, book)
+	})
+}
+
+func (r *BookRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := deleteBook(tx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// DeleteMany deletes books ids in one transaction. Each book is deleted
+// behind a savepoint, so one that fails is rolled back alone and the others
+// are still committed; errs[i] is why ids[i] failed, or nil. A book that
+// does not exist fails with gorm.ErrRecordNotFound, one with copies out on
+// loan with ErrBookHasLoans.
+func (r *BookRepository) DeleteMany(ctx context.Context, ids []uint) (errs []error, err error) {
+	errs = make([]error, len(ids))
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			failed, err := withSavepoint(tx, func() error {
+				return deleteBook(tx, id)
+			})
+			if err != nil {
+				return err
+			}
+			errs[i] = failed
+		}
+		return nil
+	})
+	return errs, err
+}
+
+// deleteBook deletes book id, returning gorm.ErrRecordNotFound when there
+// is none and ErrBookHasLoans when a copy of it is still out on loan. The
+// book row is locked first, as Borrow does, so no loan can start between
+// the check and the delete.
+func deleteBook(tx *gorm.DB, id uint) error {
+	var book model.Book
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&book, id).Error; err != nil {
+		return err
+	}
+	var open int64
+	if err := tx.Model(&model.Loan{}).Where("book_id = ? AND returned_at IS NULL", id).Count(&open).Error; err != nil {
+		return err
+	}
+	if open > 0 {
+		return ErrBookHasLoans
+	}
+	if err := tx.Delete(&model.Book{}, id).Error; err != nil {
+		return err
+	}
+	return recordChange(tx, model.EntityBook, id, model.ChangeOpDelete, map[string]uint{"id": id})
+}
+
+// withSavepoint runs fn behind a savepoint of tx, rolling back to it when
+// fn fails. fn's error is returned as failed; err is set when the savepoint
+// itself could not be set or rolled back to, and the transaction must end.
+func withSavepoint(tx *gorm.DB, fn func() error) (failed, err error) {
+	if err := tx.SavePoint(bulkSavepoint).Error; err != nil {
+		return nil, err
+	}
+	if failed := fn(); failed != nil {
+		if err := tx.RollbackTo(bulkSavepoint).Error; err != nil {
+			return nil, err
+		}
+		return failed, nil
+	}
+	return nil, nil
 }
\ No newline at end of file
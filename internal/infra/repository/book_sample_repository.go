@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookSampleRepository struct {
+	db *gorm.DB
+}
+
+func NewBookSampleRepository(db *gorm.DB) *BookSampleRepository {
+	return &BookSampleRepository{db: db}
+}
+
+// Find returns the book's sample with its content
+func (r *BookSampleRepository) Find(bookID uint) (*model.BookSample, error) {
+	var sample model.BookSample
+	if err := r.db.First(&sample, bookID).Error; err != nil {
+		return nil, err
+	}
+	return &sample, nil
+}
+
+// Save stores the book's sample, replacing any earlier one, and updates the
+// book's has_sample flag to match its access
+func (r *BookSampleRepository) Save(sample *model.BookSample) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(sample).Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE books SET has_sample = ? WHERE id = ?", sample.Access == model.SamplePublic, sample.BookID).Error
+	})
+}
+
+// Delete removes the book's sample, returning gorm.ErrRecordNotFound when it
+// has none
+func (r *BookSampleRepository) Delete(bookID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&model.BookSample{}, bookID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Exec("UPDATE books SET has_sample = false WHERE id = ?", bookID).Error
+	})
+}
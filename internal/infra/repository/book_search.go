@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// minFullTextTermLength mirrors InnoDB's default innodb_ft_min_token_size;
+// shorter searches are not indexed so they go through LIKE instead
+const minFullTextTermLength = 3
+
+// legacyBookFullTextIndexes are no longer used: idx_books_fulltext covered
+// title, author and description together, which cannot serve searches that
+// leave description out, and idx_books_fulltext_title_author searched the
+// author name column that authors now replace
+var legacyBookFullTextIndexes = []string{"idx_books_fulltext", "idx_books_fulltext_title_author"}
+
+// bookSearchColumn is a searchable column. Columns with a field are only
+// searched when that field is requested. authors.name and reviews.body are
+// searched through the book's authors and reviews.
+type bookSearchColumn struct {
+	name  string
+	field dto.SearchField
+}
+
+var bookSearchColumns = []bookSearchColumn{
+	{name: "title"},
+	{name: "authors.name"},
+	{name: "description", field: dto.SearchFieldDescription},
+	{name: "reviews.body", field: dto.SearchFieldReviews},
+}
+
+// condition returns the search condition on the column for a book
+func (col bookSearchColumn) condition(sql string) string {
+	table, _, _ := strings.Cut(col.name, ".")
+	return relatedCondition(table, sql)
+}
+
+// relatedCondition returns the condition sql on rows of table as a
+// condition on books: books' own columns directly, and the tables a book
+// has many rows in through EXISTS
+func relatedCondition(table, sql string) string {
+	switch table {
+	case "authors":
+		return authorExists(sql)
+	case "reviews":
+		return reviewExists(sql)
+	}
+	return sql
+}
+
+// bookFullTextIndex is a full-text index over columns of table that are
+// always searched together. MySQL only uses a FULLTEXT index when the MATCH
+// column list is identical to the index's, so each optional field gets its
+// own.
+type bookFullTextIndex struct {
+	name    string
+	table   string
+	columns []string
+	field   dto.SearchField
+}
+
+var bookFullTextIndexes = []bookFullTextIndex{
+	{name: "idx_books_fulltext_title", table: "books", columns: []string{"title"}},
+	{name: "idx_authors_fulltext_name", table: "authors", columns: []string{"name"}},
+	{name: "idx_books_fulltext_description", table: "books", columns: []string{"description"}, field: dto.SearchFieldDescription},
+	{name: "idx_reviews_fulltext_body", table: "reviews", columns: []string{"body"}, field: dto.SearchFieldReviews},
+}
+
+// condition returns the search condition on the index for a book, given
+// the condition on its own columns
+func (idx bookFullTextIndex) condition(sql string) string {
+	return relatedCondition(idx.table, sql)
+}
+
+// qualifiedColumns lists the columns by table, as subqueries need
+func (idx bookFullTextIndex) qualifiedColumns() string {
+	cols := make([]string, len(idx.columns))
+	for i, col := range idx.columns {
+		cols[i] = idx.table + "." + col
+	}
+	return strings.Join(cols, ", ")
+}
+
+// EnsureBookSearchIndex creates the MySQL FULLTEXT indexes used by book
+// search. The service only runs on MySQL; on any other dialect, such as a
+// test database, search keeps using LIKE matching.
+func EnsureBookSearchIndex(db *gorm.DB) error {
+	if db.Dialector.Name() != "mysql" {
+		return nil
+	}
+	for _, legacy := range legacyBookFullTextIndexes {
+		if db.Migrator().HasIndex(&model.Book{}, legacy) {
+			if err := db.Migrator().DropIndex(&model.Book{}, legacy); err != nil {
+				return err
+			}
+		}
+	}
+	for _, idx := range bookFullTextIndexes {
+		if db.Migrator().HasIndex(idx.table, idx.name) {
+			continue
+		}
+		if err := db.Exec("CREATE FULLTEXT INDEX " + idx.name + " ON " + idx.table + " (" + strings.Join(idx.columns, ", ") + ")").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildSearchIndex rebuilds each full-text index in turn, calling
+// progress after each one. Each index is dropped and re-added in a single
+// ALTER so searches never run without it.
+func (r *BookRepository) RebuildSearchIndex(ctx context.Context, progress func(done, total int)) error {
+	for i, idx := range bookFullTextIndexes {
+		if r.db.Dialector.Name() == "mysql" {
+			err := r.db.WithContext(ctx).Exec("ALTER TABLE " + idx.table + " DROP INDEX " + idx.name + ", ADD FULLTEXT INDEX " + idx.name + " (" + strings.Join(idx.columns, ", ") + ")").Error
+			if err != nil {
+				return err
+			}
+		}
+		progress(i+1, len(bookFullTextIndexes))
+	}
+	return nil
+}
+
+// applyBookSearch narrows query to books matching params.Search, or any of
+// its synonym variants, in the requested columns. The full-text indexes are
+// used on MySQL when the terms are long enough.
+func (r *BookRepository) applyBookSearch(query *gorm.DB, params dto.BookQuery) *gorm.DB {
+	var conditions []string
+	var vars []interface{}
+
+	for _, search := range searchVariants(params) {
+		c, v := r.bookSearchCondition(search, params)
+		conditions = append(conditions, c...)
+		vars = append(vars, v...)
+	}
+
+	return query.Where(strings.Join(conditions, " OR "), vars...)
+}
+
+func (r *BookRepository) bookSearchCondition(search string, params dto.BookQuery) ([]string, []interface{}) {
+	var conditions []string
+	var vars []interface{}
+
+	if r.db.Dialector.Name() == "mysql" {
+		if terms := mysqlBooleanTerms(search); terms != "" {
+			for _, idx := range bookFullTextIndexes {
+				if idx.field == "" || params.Includes(idx.field) {
+					conditions = append(conditions, idx.condition("MATCH ("+idx.qualifiedColumns()+") AGAINST (? IN BOOLEAN MODE)"))
+					vars = append(vars, terms)
+				}
+			}
+		}
+	}
+
+	if len(conditions) == 0 {
+		like := containsPattern(search)
+		for _, col := range searchedColumns(params) {
+			conditions = append(conditions, col.condition(col.name+" LIKE ?"))
+			vars = append(vars, like)
+		}
+	}
+
+	return conditions, vars
+}
+
+func searchVariants(params dto.BookQuery) []string {
+	if len(params.SearchVariants) == 0 {
+		return []string{params.Search}
+	}
+	return params.SearchVariants
+}
+
+func searchedColumns(params dto.BookQuery) []bookSearchColumn {
+	var cols []bookSearchColumn
+	for _, col := range bookSearchColumns {
+		if col.field == "" || params.Includes(col.field) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// fullTextEligible reports whether every word in search is long enough to be
+// present in the full-text index
+func fullTextEligible(search string) bool {
+	words := strings.Fields(search)
+	if len(words) == 0 {
+		return false
+	}
+	for _, w := range words {
+		if utf8.RuneCountInString(w) < minFullTextTermLength {
+			return false
+		}
+	}
+	return true
+}
+
+// mysqlBooleanTerms turns free text into a BOOLEAN MODE expression requiring
+// every word as a prefix match. Operator characters are stripped so user input
+// cannot change the meaning of the expression. It returns "" when the search
+// is not eligible for the full-text index.
+func mysqlBooleanTerms(search string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`+-<>()~*"@`, r) {
+			return ' '
+		}
+		return r
+	}, search)
+
+	if !fullTextEligible(cleaned) {
+		return ""
+	}
+
+	words := strings.Fields(cleaned)
+	for i, w := range words {
+		words[i] = "+" + w + "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// likeEscaper escapes LIKE's wildcards and its escape character, the
+// backslash, so searched text matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern is a LIKE pattern matching values that contain text
+func containsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
+
+// prefixPattern is a LIKE pattern matching values that start with text
+func prefixPattern(text string) string {
+	return likeEscaper.Replace(text) + "%"
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// hostileSearches seed the fuzz targets with input that has broken search
+// before or could: operators, wildcards, quotes, emoji, null bytes and
+// very long text
+var hostileSearches = []string{
+	"",
+	"dune",
+	"the hobbit",
+	"ab",
+	"+dune -herbert",
+	`"exact phrase" @distance`,
+	"dun* ~tolkien <ring> (hobbit)",
+	"100%",
+	"snake_case",
+	`back\slash\`,
+	"' OR 1=1 --",
+	"?; DROP TABLE books",
+	"🐉 dragons",
+	"x\x00y",
+	"\xff\xfe",
+	strings.Repeat("long ", 200),
+}
+
+// dryRunDB returns a MySQL database that builds statements without
+// connecting
+func dryRunDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		tb.Fatalf("open dry-run database: %v", err)
+	}
+	return db
+}
+
+func FuzzMysqlBooleanTerms(f *testing.F) {
+	for _, search := range hostileSearches {
+		f.Add(search)
+	}
+	f.Fuzz(func(t *testing.T, search string) {
+		terms := mysqlBooleanTerms(search)
+		if terms == "" {
+			return
+		}
+		for _, term := range strings.Split(terms, " ") {
+			word, required := strings.CutPrefix(term, "+")
+			word, prefix := strings.CutSuffix(word, "*")
+			if !required || !prefix || word == "" {
+				t.Fatalf("mysqlBooleanTerms(%q) = %q: term %q is not +word*", search, terms, term)
+			}
+			if strings.ContainsAny(word, `+-<>()~*"@`) {
+				t.Fatalf("mysqlBooleanTerms(%q) = %q: term %q keeps an operator", search, terms, term)
+			}
+			if utf8.RuneCountInString(word) < minFullTextTermLength {
+				t.Fatalf("mysqlBooleanTerms(%q) = %q: term %q is too short for the index", search, terms, term)
+			}
+		}
+	})
+}
+
+// unescapeLike returns the text a LIKE pattern matches literally, and
+// whether it has an unescaped wildcard
+func unescapeLike(pattern string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+			if i < len(pattern) {
+				b.WriteByte(pattern[i])
+			}
+		case '%', '_':
+			return b.String(), true
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String(), false
+}
+
+func FuzzLikePatterns(f *testing.F) {
+	for _, search := range hostileSearches {
+		f.Add(search)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		contains := containsPattern(text)
+		inner, ok := strings.CutPrefix(contains, "%")
+		if inner, ok = strings.CutSuffix(inner, "%"); !ok || len(contains) < 2 {
+			t.Fatalf("containsPattern(%q) = %q: not wrapped in %%", text, contains)
+		}
+		if literal, wild := unescapeLike(inner); wild || literal != text {
+			t.Fatalf("containsPattern(%q) = %q: matches %q literally, wildcard %v", text, contains, literal, wild)
+		}
+
+		prefix := prefixPattern(text)
+		inner, ok = strings.CutSuffix(prefix, "%")
+		if !ok {
+			t.Fatalf("prefixPattern(%q) = %q: does not end in %%", text, prefix)
+		}
+		if literal, wild := unescapeLike(inner); wild || literal != text {
+			t.Fatalf("prefixPattern(%q) = %q: matches %q literally, wildcard %v", text, prefix, literal, wild)
+		}
+	})
+}
+
+// FuzzBookSearchSQL checks that searches only ever reach the database as
+// bound values: the SQL of any search is the SQL of a plain search taking
+// the same path.
+func FuzzBookSearchSQL(f *testing.F) {
+	db := dryRunDB(f)
+	repo := &BookRepository{db: db}
+	params := dto.BookQuery{IncludeFields: dto.SearchFields}
+	statement := func(search string) (string, int) {
+		params := params
+		params.Search = search
+		stmt := repo.applyBookSearch(db.Model(&model.Book{}), params).Find(&[]model.Book{}).Statement
+		return stmt.SQL.String(), len(stmt.Vars)
+	}
+	// "ab" is too short for the full-text index and goes through LIKE
+	likeSQL, _ := statement("ab")
+	fullTextSQL, _ := statement("abc")
+
+	for _, search := range hostileSearches {
+		f.Add(search)
+	}
+	f.Fuzz(func(t *testing.T, search string) {
+		sql, vars := statement(search)
+		if sql != likeSQL && sql != fullTextSQL {
+			t.Fatalf("search %q changed the SQL:\n%s", search, sql)
+		}
+		if placeholders := strings.Count(sql, "?"); placeholders != vars {
+			t.Fatalf("search %q: %d placeholders for %d values", search, placeholders, vars)
+		}
+	})
+}
+
+func TestBookSearchIncludeFields(t *testing.T) {
+	db := dryRunDB(t)
+	repo := &BookRepository{db: db}
+
+	tests := []struct {
+		name    string
+		search  string
+		fields  []dto.SearchField
+		want    []string
+		notWant []string
+	}{
+		{"full-text without fields", "dune", nil, []string{"MATCH (books.title)", "MATCH (authors.name)"}, []string{"description", "reviews"}},
+		{"full-text with descriptions", "dune", []dto.SearchField{dto.SearchFieldDescription}, []string{"MATCH (books.description)"}, []string{"reviews"}},
+		{"full-text with reviews", "dune", []dto.SearchField{dto.SearchFieldReviews}, []string{"EXISTS (SELECT 1 FROM reviews WHERE reviews.book_id = books.id AND MATCH (reviews.body)"}, []string{"description"}},
+		{"LIKE with reviews", "ab", []dto.SearchField{dto.SearchFieldReviews}, []string{"EXISTS (SELECT 1 FROM reviews WHERE reviews.book_id = books.id AND reviews.body LIKE ?)"}, []string{"MATCH"}},
+		{"relevance scores reviews", "dune", []dto.SearchField{dto.SearchFieldReviews}, []string{"LOWER(reviews.body) LIKE ?) THEN 1 ELSE 0 END) AS score_reviews"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := dto.BookQuery{Search: tt.search, IncludeFields: tt.fields, SortBy: dto.SortByRelevance}
+			query := repo.applyBookSearch(db.Model(&model.Book{}), params)
+			sql := selectRelevance(query, params).Find(&[]scoredBook{}).Statement.SQL.String()
+			for _, want := range tt.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("SQL lacks %q:\n%s", want, sql)
+				}
+			}
+			where := sql[strings.LastIndex(sql, "FROM `books` WHERE"):]
+			for _, notWant := range tt.notWant {
+				if strings.Contains(where, notWant) {
+					t.Errorf("search condition has %q:\n%s", notWant, where)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ViewedBook is a book together with when the user last viewed it
+type ViewedBook struct {
+	model.Book
+	ViewedAt time.Time
+}
+
+type BookViewRepository struct {
+	db *gorm.DB
+}
+
+func NewBookViewRepository(db *gorm.DB) *BookViewRepository {
+	return &BookViewRepository{db: db}
+}
+
+// Record stores a view of bookID by userID and drops the user's views beyond
+// the newest keep, so each user's history behaves as a ring buffer
+func (r *BookViewRepository) Record(userID, bookID uint, keep int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		view := model.BookView{UserID: userID, BookID: bookID, ViewedAt: time.Now()}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"viewed_at"}),
+		}).Create(&view).Error
+		if err != nil {
+			return err
+		}
+
+		var cutoff model.BookView
+		err = tx.Where("user_id = ?", userID).
+			Order("viewed_at DESC").
+			Offset(keep).
+			Limit(1).
+			Take(&cutoff).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ? AND viewed_at <= ?", userID, cutoff.ViewedAt).Delete(&model.BookView{}).Error
+	})
+}
+
+// FindRecent returns the user's most recently viewed books, newest first,
+// skipping books that have since been deleted
+func (r *BookViewRepository) FindRecent(userID uint, limit int) ([]ViewedBook, error) {
+	var books []ViewedBook
+	err := r.db.Model(&model.Book{}).
+		Select("books.*, book_views.viewed_at").
+		Joins("JOIN book_views ON book_views.book_id = books.id").
+		Where("book_views.user_id = ?", userID).
+		Order("book_views.viewed_at DESC").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (r *BookViewRepository) DeleteByUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.BookView{}).Error
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// FindAll lists every category by name with its number of books
+func (r *CategoryRepository) FindAll() ([]dto.CategoryResponse, error) {
+	var categories []dto.CategoryResponse
+	err := r.db.Model(&model.Category{}).
+		Select("categories.id, categories.name, COUNT(books.id) AS books").
+		Joins("LEFT JOIN books ON books.category_id = categories.id AND books.deleted_at IS NULL").
+		Group("categories.id, categories.name").
+		Order("categories.name").
+		Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepository) FindByID(id uint) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindByName returns the category called name, ignoring case and
+// surrounding spaces
+func (r *CategoryRepository) FindByName(name string) (*model.Category, error) {
+	var category model.Category
+	if err := byName(r.db, name).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindByNames returns the categories called one of names, ignoring case
+func (r *CategoryRepository) FindByNames(names []string) ([]model.Category, error) {
+	var categories []model.Category
+	if len(names) == 0 {
+		return categories, nil
+	}
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	if err := r.db.Where("LOWER(name) IN ?", lowered).Order("id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CountBooks returns the number of books in category id
+func (r *CategoryRepository) CountBooks(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Book{}).Where("category_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (r *CategoryRepository) Create(category *model.Category) error {
+	return r.db.Create(category).Error
+}
+
+// Rename renames category to name and relabels its books in one
+// transaction, recording a change event for each book. It returns the
+// number of books relabelled.
+func (r *CategoryRepository) Rename(category *model.Category, name string) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		category.Name = name
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		var err error
+		moved, err = fileBooks(tx, []uint{category.ID}, category)
+		return err
+	})
+	return moved, err
+}
+
+// Merge moves every book of sources into target and deletes sources, in
+// one transaction, recording a change event for each book moved. target is
+// created first when it is new. It returns the number of books moved.
+func (r *CategoryRepository) Merge(sources []model.Category, target *model.Category) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if target.ID == 0 {
+			if err := tx.Create(target).Error; err != nil {
+				return err
+			}
+		}
+		ids := make([]uint, len(sources))
+		for i, source := range sources {
+			ids[i] = source.ID
+		}
+		var err error
+		if moved, err = fileBooks(tx, ids, target); err != nil {
+			return err
+		}
+		// Deleted books keep pointing at their category until it goes
+		if err := tx.Unscoped().Model(&model.Book{}).Where("category_id IN ?", ids).Updates(map[string]interface{}{"category_id": target.ID, "category": target.Name}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Category{}, ids).Error
+	})
+	return moved, err
+}
+
+// Delete removes category id. Deleted books in it lose their category; the
+// caller checks that no live book is left in it.
+func (r *CategoryRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Book{}).Where("category_id = ?", id).Updates(map[string]interface{}{"category_id": nil, "category": ""}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&model.Category{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// BackfillCategories creates a category for every distinct category name
+// the books carry and points the books at them. Names differing only in
+// case end up in one category, spelled as the first one created.
+func BackfillCategories(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var names []string
+		if err := tx.Unscoped().Model(&model.Book{}).
+			Where("TRIM(category) <> ''").
+			Order("MIN(id)").
+			Group("TRIM(category)").
+			Pluck("TRIM(category)", &names).Error; err != nil {
+			return err
+		}
+		for _, name := range names {
+			var category model.Category
+			if err := byName(tx, name).Attrs(model.Category{Name: name}).FirstOrCreate(&category).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&model.Book{}).
+				Where("category_id IS NULL AND LOWER(TRIM(category)) = ?", strings.ToLower(name)).
+				Updates(map[string]interface{}{"category_id": category.ID, "category": category.Name}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// assignCategory files book under a category before it is written: the
+// one Category names, ignoring case and created when there is none, or
+// CategoryID's when the name is blank. Either way Category takes the
+// category's own spelling. A book with neither has no category.
+func assignCategory(tx *gorm.DB, book *model.Book) error {
+	var category model.Category
+	name := strings.TrimSpace(book.Category)
+	switch {
+	case name != "":
+		if err := byName(tx, name).Attrs(model.Category{Name: name}).FirstOrCreate(&category).Error; err != nil {
+			return err
+		}
+	case book.CategoryID != nil:
+		if err := tx.First(&category, *book.CategoryID).Error; err != nil {
+			return err
+		}
+	default:
+		book.Category, book.CategoryID = "", nil
+		return nil
+	}
+	book.CategoryID, book.Category = &category.ID, category.Name
+	return nil
+}
+
+// fileBooks points the live books of categories ids at category and
+// records a change event for each, returning how many there were
+func fileBooks(tx *gorm.DB, ids []uint, category *model.Category) (int64, error) {
+	var books []model.Book
+	if err := tx.Where("category_id IN ?", ids).Order("id").Find(&books).Error; err != nil {
+		return 0, err
+	}
+	if len(books) == 0 {
+		return 0, nil
+	}
+
+	bookIDs := make([]uint, len(books))
+	for i, book := range books {
+		bookIDs[i] = book.ID
+	}
+	now := time.Now()
+	res := tx.Model(&model.Book{}).Where("id IN ?", bookIDs).Updates(map[string]interface{}{"category_id": category.ID, "category": category.Name, "updated_at": now})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	for i := range books {
+		books[i].CategoryID = &category.ID
+		books[i].Category = category.Name
+		books[i].UpdatedAt = now
+		if err := recordChange(tx, model.EntityBook, books[i].ID, model.ChangeOpUpdate, books[i]); err != nil {
+			return 0, err
+		}
+	}
+	return res.RowsAffected, nil
+}
+
+func byName(db *gorm.DB, name string) *gorm.DB {
+	return db.Where("LOWER(name) = ?", strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordChange appends an event to the change log through tx, so it commits
+// or rolls back together with the write it describes
+func recordChange(tx *gorm.DB, entity string, id uint, op model.ChangeOp, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&model.ChangeEvent{
+		Entity:   entity,
+		EntityID: id,
+		Op:       op,
+		Payload:  data,
+	}).Error
+}
+
+type ChangeEventRepository struct {
+	db *gorm.DB
+}
+
+func NewChangeEventRepository(db *gorm.DB) *ChangeEventRepository {
+	return &ChangeEventRepository{db: db}
+}
+
+// FindAfter returns up to limit events with a sequence number above afterSeq
+// that were written before the given time, in sequence order
+func (r *ChangeEventRepository) FindAfter(afterSeq uint64, before time.Time, limit int) ([]model.ChangeEvent, error) {
+	var events []model.ChangeEvent
+	err := r.db.Where("seq > ? AND created_at < ?", afterSeq, before).
+		Order("seq").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// FindMatching returns up to query.Limit events matching the query, newest
+// first
+func (r *ChangeEventRepository) FindMatching(query dto.ChangeEventQuery) ([]model.ChangeEvent, error) {
+	db := r.db
+	if query.Entity != "" {
+		db = db.Where("entity = ?", query.Entity)
+	}
+	if query.EntityID > 0 {
+		db = db.Where("entity_id = ?", query.EntityID)
+	}
+	if query.Op != "" {
+		db = db.Where("op = ?", query.Op)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+	if query.BeforeSeq > 0 {
+		db = db.Where("seq < ?", query.BeforeSeq)
+	}
+
+	var events []model.ChangeEvent
+	if err := db.Order("seq DESC").Limit(query.Limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChangeRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewChangeRequestRepository(db *gorm.DB) *ChangeRequestRepository {
+	return &ChangeRequestRepository{db: db}
+}
+
+func (r *ChangeRequestRepository) Create(cr *model.ChangeRequest) error {
+	return r.db.Create(cr).Error
+}
+
+func (r *ChangeRequestRepository) FindByID(id uint) (*model.ChangeRequest, error) {
+	var cr model.ChangeRequest
+	if err := r.db.First(&cr, id).Error; err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
+
+// FindAll lists change requests, newest first, optionally only those with
+// status, submitted by submittedBy or with the given machineGenerated
+func (r *ChangeRequestRepository) FindAll(status model.ChangeRequestStatus, submittedBy uint, machineGenerated *bool) ([]model.ChangeRequest, error) {
+	db := r.db.Order("id DESC")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if submittedBy != 0 {
+		db = db.Where("submitted_by = ?", submittedBy)
+	}
+	if machineGenerated != nil {
+		db = db.Where("machine_generated = ?", *machineGenerated)
+	}
+
+	var crs []model.ChangeRequest
+	if err := db.Find(&crs).Error; err != nil {
+		return nil, err
+	}
+	return crs, nil
+}
+
+// Review closes a pending change request. When apply is not nil it is run
+// on the locked book and the result saved in the same transaction, so an
+// approval and its edit land together. It reports false when the request
+// was no longer pending.
+func (r *ChangeRequestRepository) Review(cr *model.ChangeRequest, status model.ChangeRequestStatus, reviewerID uint, comment string, apply func(*model.Book)) (bool, error) {
+	reviewed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&model.ChangeRequest{}).
+			Where("id = ? AND status = ?", cr.ID, model.ChangePending).
+			Updates(map[string]interface{}{
+				"status":         status,
+				"reviewed_by":    reviewerID,
+				"reviewed_at":    now,
+				"review_comment": comment,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if apply != nil {
+			var book model.Book
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, cr.BookID).Error; err != nil {
+				return err
+			}
+			apply(&book)
+			if err := assignCategory(tx, &book); err != nil {
+				return err
+			}
+			if err := assignAuthors(tx, &book); err != nil {
+				return err
+			}
+			if err := tx.Save(&book).Error; err != nil {
+				return err
+			}
+			if err := linkAuthors(tx, &book); err != nil {
+				return err
+			}
+			if err := recordChange(tx, model.EntityBook, book.ID, model.ChangeOpUpdate, book); err != nil {
+				return err
+			}
+		}
+
+		cr.Status = status
+		cr.ReviewedBy = &reviewerID
+		cr.ReviewedAt = &now
+		cr.ReviewComment = comment
+		reviewed = true
+		return nil
+	})
+	return reviewed, err
+}
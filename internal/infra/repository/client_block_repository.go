@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ClientBlockRepository struct {
+	db *gorm.DB
+}
+
+func NewClientBlockRepository(db *gorm.DB) *ClientBlockRepository {
+	return &ClientBlockRepository{db: db}
+}
+
+func (r *ClientBlockRepository) FindAll() ([]model.ClientBlock, error) {
+	var blocks []model.ClientBlock
+	if err := r.db.Order("id").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Save blocks a client, replacing any earlier block of it
+func (r *ClientBlockRepository) Save(block *model.ClientBlock) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "created_at", "expires_at"}),
+	}).Create(block).Error
+}
+
+// Delete removes a block, reporting whether it existed
+func (r *ClientBlockRepository) Delete(id uint) (bool, error) {
+	res := r.db.Delete(&model.ClientBlock{}, id)
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// collectionColumns selects a collection with its number of books
+const collectionColumns = "collections.id, collections.name, collections.description, collections.created_at, collections.updated_at, " +
+	"(SELECT COUNT(*) FROM collection_books JOIN books ON books.id = collection_books.book_id AND books.deleted_at IS NULL " +
+	"WHERE collection_books.collection_id = collections.id) AS books"
+
+type CollectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+// FindByUser lists userID's collections by name with their number of books
+func (r *CollectionRepository) FindByUser(userID uint) ([]dto.CollectionResponse, error) {
+	var collections []dto.CollectionResponse
+	err := r.db.Model(&model.Collection{}).
+		Select(collectionColumns).
+		Where("user_id = ?", userID).
+		Order("name").
+		Scan(&collections).Error
+	if err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+func (r *CollectionRepository) FindByID(id uint) (*model.Collection, error) {
+	var collection model.Collection
+	if err := r.db.First(&collection, id).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// Summary returns collection id with its number of books
+func (r *CollectionRepository) Summary(id uint) (*dto.CollectionResponse, error) {
+	var collection dto.CollectionResponse
+	res := r.db.Model(&model.Collection{}).Select(collectionColumns).Where("id = ?", id).Scan(&collection)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &collection, nil
+}
+
+// NameTaken reports whether userID has a collection other than exceptID
+// called name, ignoring case
+func (r *CollectionRepository) NameTaken(userID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := byName(r.db.Model(&model.Collection{}), name).
+		Where("user_id = ? AND id <> ?", userID, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *CollectionRepository) Create(collection *model.Collection) error {
+	return r.db.Create(collection).Error
+}
+
+func (r *CollectionRepository) Update(collection *model.Collection) error {
+	return r.db.Model(collection).Select("name", "description").Updates(collection).Error
+}
+
+// Delete removes collection id with its list of books
+func (r *CollectionRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&model.CollectionBook{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Collection{}, id).Error
+	})
+}
+
+// FindBooks lists the books on collection id in the order they were added
+func (r *CollectionRepository) FindBooks(id uint) ([]model.CollectionBook, error) {
+	var entries []model.CollectionBook
+	err := r.db.Where("collection_id = ?", id).
+		Order("created_at").
+		Order("book_id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddBook puts book bookID on collection id. Adding a book the collection
+// already has changes nothing.
+func (r *CollectionRepository) AddBook(id, bookID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.CollectionBook{CollectionID: id, BookID: bookID}).Error
+}
+
+// RemoveBook takes book bookID off collection id. It returns
+// gorm.ErrRecordNotFound when the collection does not have the book.
+func (r *CollectionRepository) RemoveBook(id, bookID uint) error {
+	res := r.db.Where("collection_id = ? AND book_id = ?", id, bookID).Delete(&model.CollectionBook{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// eraseCollections removes userID's collections with their lists of books
+func eraseCollections(tx *gorm.DB, userID uint) error {
+	owned := tx.Model(&model.Collection{}).Select("id").Where("user_id = ?", userID)
+	if err := tx.Where("collection_id IN (?)", owned).Delete(&model.CollectionBook{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&model.Collection{}).Error
+}
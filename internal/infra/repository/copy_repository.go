@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// stockColumns recounts each book's copies and the ones that can be lent
+const stockColumns = "copies = (SELECT COUNT(*) FROM copies WHERE copies.book_id = books.id AND copies.deleted_at IS NULL), " +
+	"available_copies = (SELECT COUNT(*) FROM copies WHERE copies.book_id = books.id AND copies.deleted_at IS NULL AND " + lendableCondition + ")"
+
+// onLoanCondition matches copies out on a loan
+const onLoanCondition = "EXISTS (SELECT 1 FROM loans WHERE loans.copy_id = copies.id AND loans.returned_at IS NULL)"
+
+// lendableCondition matches copies in circulation and not out on a loan
+const lendableCondition = "copies.status = '" + string(model.CopyInCirculation) + "' AND NOT " + onLoanCondition
+
+type CopyRepository struct {
+	db *gorm.DB
+}
+
+func NewCopyRepository(db *gorm.DB) *CopyRepository {
+	return &CopyRepository{db: db}
+}
+
+// FindByBook lists book id's copies in the order they were added, with the
+// loan each is out on
+func (r *CopyRepository) FindByBook(id uint) ([]dto.CopyResponse, error) {
+	var copies []dto.CopyResponse
+	err := r.db.Model(&model.Copy{}).
+		Select("copies.id, copies.condition, copies.status, loans.id AS loan_id, loans.due_at, copies.created_at").
+		Joins("LEFT JOIN loans ON loans.copy_id = copies.id AND loans.returned_at IS NULL").
+		Where("copies.book_id = ?", id).
+		Order("copies.id").
+		Scan(&copies).Error
+	if err != nil {
+		return nil, err
+	}
+	return copies, nil
+}
+
+// Stock counts book id's copies
+func (r *CopyRepository) Stock(id uint) (*dto.StockResponse, error) {
+	var book model.Book
+	if err := r.db.Select("id", "copies", "available_copies").First(&book, id).Error; err != nil {
+		return nil, err
+	}
+	return stockOf(r.db, book)
+}
+
+// Availability returns the availability of books ids. Holds that ran out
+// but were not yet released count as released.
+func (r *CopyRepository) Availability(ids []uint, now time.Time) ([]dto.BookAvailability, error) {
+	var availability []dto.BookAvailability
+	err := r.db.Model(&model.Book{}).
+		Select("books.id AS book_id, books.copies, "+
+			"GREATEST(books.available_copies - (SELECT COUNT(*) FROM reservations WHERE reservations.book_id = books.id AND reservations.status = ? AND reservations.expires_at > ?), 0) AS available, "+
+			"(SELECT MIN(loans.due_at) FROM loans WHERE loans.book_id = books.id AND loans.returned_at IS NULL) AS next_due_at, "+
+			"(SELECT COUNT(*) FROM reservations WHERE reservations.book_id = books.id AND reservations.status = ?) AS queue_length",
+			model.ReservationReady, now, model.ReservationWaiting).
+		Where("books.id IN ?", ids).
+		Scan(&availability).Error
+	if err != nil {
+		return nil, err
+	}
+	return availability, nil
+}
+
+// SetStock adds or withdraws copies of book id until it has count. Only
+// copies not out on loan are withdrawn, newest first; false is returned
+// when too few of them are left to get down to count.
+func (r *CopyRepository) SetStock(id uint, count int) (*dto.StockResponse, bool, error) {
+	var stock *dto.StockResponse
+	ok := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var book model.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, id).Error; err != nil {
+			return err
+		}
+
+		var held int64
+		if err := tx.Model(&model.Copy{}).Where("book_id = ?", id).Count(&held).Error; err != nil {
+			return err
+		}
+		switch {
+		case int(held) < count:
+			if err := addCopies(tx, id, count-int(held)); err != nil {
+				return err
+			}
+		case int(held) > count:
+			var spare []uint
+			if err := tx.Model(&model.Copy{}).
+				Where("book_id = ? AND NOT "+onLoanCondition, id).
+				Order("id DESC").
+				Limit(int(held)-count).
+				Pluck("id", &spare).Error; err != nil {
+				return err
+			}
+			if len(spare) < int(held)-count {
+				return nil
+			}
+			if err := withdrawCopies(tx, spare...); err != nil {
+				return err
+			}
+		}
+
+		if err := refreshStock(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Select("id", "copies", "available_copies").First(&book, id).Error; err != nil {
+			return err
+		}
+		var err error
+		stock, err = stockOf(tx, book)
+		ok = err == nil
+		return err
+	})
+	return stock, ok, err
+}
+
+// Withdraw removes copy copyID of book id from stock. It returns
+// gorm.ErrRecordNotFound when the book has no such copy and false when the
+// copy is out on loan.
+func (r *CopyRepository) Withdraw(id, copyID uint) (bool, error) {
+	withdrawn := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var book model.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, id).Error; err != nil {
+			return err
+		}
+		var target model.Copy
+		if err := tx.Where("book_id = ?", id).First(&target, copyID).Error; err != nil {
+			return err
+		}
+		var onLoan int64
+		if err := tx.Model(&model.Copy{}).Where("id = ? AND "+onLoanCondition, target.ID).Count(&onLoan).Error; err != nil {
+			return err
+		}
+		if onLoan > 0 {
+			return nil
+		}
+		if err := withdrawCopies(tx, target.ID); err != nil {
+			return err
+		}
+		withdrawn = true
+		return refreshStock(tx, id)
+	})
+	return withdrawn, err
+}
+
+// SetCondition puts copy copyID of book id in condition, and in the status
+// that goes with it, and stores report, when not nil, on the copy. A copy
+// out on loan cannot be taken out of circulation; false is returned then
+// and nothing is written. It returns gorm.ErrRecordNotFound when the book
+// has no such copy in stock.
+func (r *CopyRepository) SetCondition(id, copyID uint, condition model.CopyCondition, report *model.DamageReport) (*model.Copy, bool, error) {
+	var target model.Copy
+	ok := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Book{}, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("book_id = ?", id).First(&target, copyID).Error; err != nil {
+			return err
+		}
+		status := condition.Status()
+		if status != model.CopyInCirculation {
+			var onLoan int64
+			if err := tx.Model(&model.Copy{}).Where("id = ? AND "+onLoanCondition, target.ID).Count(&onLoan).Error; err != nil {
+				return err
+			}
+			if onLoan > 0 {
+				return nil
+			}
+		}
+
+		if report != nil {
+			report.CopyID, report.BookID = target.ID, id
+			if err := tx.Create(report).Error; err != nil {
+				return err
+			}
+		}
+		target.Condition, target.Status = condition, status
+		if err := tx.Model(&target).Select("condition", "status").Updates(&target).Error; err != nil {
+			return err
+		}
+		if status == model.CopyWithdrawn {
+			if err := tx.Delete(&target).Error; err != nil {
+				return err
+			}
+		}
+		ok = true
+		return refreshStock(tx, id)
+	})
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	return &target, true, nil
+}
+
+// FindDamageReports lists the damage reported on copy copyID of book id,
+// newest first, with their photos but not the photo content. Withdrawn
+// copies keep their reports.
+func (r *CopyRepository) FindDamageReports(id, copyID uint) ([]model.DamageReport, error) {
+	if err := r.db.Unscoped().Where("book_id = ?", id).First(&model.Copy{}, copyID).Error; err != nil {
+		return nil, err
+	}
+	var reports []model.DamageReport
+	err := r.db.Preload("Photos", func(db *gorm.DB) *gorm.DB {
+		return db.Omit("content").Order("id")
+	}).Where("copy_id = ?", copyID).Order("created_at DESC, id DESC").Find(&reports).Error
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// FindDamagePhoto returns photo photoID of damage report reportID on copy
+// copyID of book id
+func (r *CopyRepository) FindDamagePhoto(id, copyID, reportID, photoID uint) (*model.DamagePhoto, error) {
+	var photo model.DamagePhoto
+	err := r.db.Joins("JOIN damage_reports ON damage_reports.id = damage_photos.report_id").
+		Where("damage_reports.book_id = ? AND damage_reports.copy_id = ? AND damage_reports.id = ?", id, copyID, reportID).
+		First(&photo, photoID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// ReplacementCandidates lists the copies in poor condition or in for
+// repair, and those withdrawn as unusable since since, or ever when it is
+// zero, by book
+func (r *CopyRepository) ReplacementCandidates(since time.Time) ([]dto.ReplacementCandidate, error) {
+	unusable := r.db.Where("copies.condition = ? AND copies.deleted_at IS NOT NULL", model.ConditionUnusable)
+	if !since.IsZero() {
+		unusable = unusable.Where("copies.deleted_at >= ?", since)
+	}
+	var candidates []dto.ReplacementCandidate
+	err := r.db.Unscoped().Model(&model.Copy{}).
+		Select("copies.id AS copy_id, copies.book_id, books.title, copies.condition, copies.status, " +
+			"(SELECT MAX(damage_reports.created_at) FROM damage_reports WHERE damage_reports.copy_id = copies.id) AS last_reported_at, " +
+			"books.copies AS book_copies").
+		Joins("JOIN books ON books.id = copies.book_id AND books.deleted_at IS NULL").
+		Where(r.db.Where("copies.condition IN ? AND copies.deleted_at IS NULL", []model.CopyCondition{model.ConditionPoor, model.ConditionDamaged}).
+			Or(unusable)).
+		Order("books.title, copies.book_id, copies.id").
+		Scan(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// BackfillCopyStatus marks the copies withdrawn before copies had a status
+// as withdrawn
+func BackfillCopyStatus(db *gorm.DB) error {
+	return db.Unscoped().Model(&model.Copy{}).Where("deleted_at IS NOT NULL").Update("status", model.CopyWithdrawn).Error
+}
+
+// BackfillCopies gives every book one copy, lending it to the book's open
+// loans, and counts the books' stock
+func BackfillCopies(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Exec("INSERT INTO copies (book_id, created_at, updated_at) "+
+			"SELECT id, ?, ? FROM books WHERE NOT EXISTS (SELECT 1 FROM copies WHERE copies.book_id = books.id)", now, now).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE loans SET copy_id = (SELECT MIN(copies.id) FROM copies WHERE copies.book_id = loans.book_id) " +
+			"WHERE copy_id = 0").Error; err != nil {
+			return err
+		}
+		return refreshStock(tx)
+	})
+}
+
+// addCopies adds count copies of book id
+func addCopies(tx *gorm.DB, id uint, count int) error {
+	if count <= 0 {
+		return nil
+	}
+	copies := make([]model.Copy, count)
+	for i := range copies {
+		copies[i].BookID = id
+	}
+	return tx.Create(&copies).Error
+}
+
+// refreshStock recounts the stock of books ids, or of every book when ids
+// is empty
+func refreshStock(tx *gorm.DB, ids ...uint) error {
+	where, vars := "", []interface{}{}
+	if len(ids) > 0 {
+		where, vars = " WHERE id IN ?", []interface{}{ids}
+	}
+	if err := tx.Exec("UPDATE books SET "+stockColumns+where, vars...).Error; err != nil {
+		return err
+	}
+	return tx.Exec("UPDATE books SET available = available_copies > 0"+where, vars...).Error
+}
+
+// stockOf counts book's copies, from its stored counts and the copies in
+// for repair
+func stockOf(db *gorm.DB, book model.Book) (*dto.StockResponse, error) {
+	var inRepair int64
+	if err := db.Model(&model.Copy{}).Where("book_id = ? AND status = ?", book.ID, model.CopyInRepair).Count(&inRepair).Error; err != nil {
+		return nil, err
+	}
+	return &dto.StockResponse{
+		BookID:    book.ID,
+		Copies:    book.Copies,
+		Available: book.AvailableCopies,
+		OnLoan:    book.Copies - book.AvailableCopies - int(inRepair),
+		InRepair:  int(inRepair),
+	}, nil
+}
+
+// withdrawCopies marks copies ids as withdrawn and soft-deletes them
+func withdrawCopies(tx *gorm.DB, ids ...uint) error {
+	if err := tx.Model(&model.Copy{}).Where("id IN ?", ids).Update("status", model.CopyWithdrawn).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.Copy{}, ids).Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type DeviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+func (r *DeviceRepository) FindByUser(userID uint) ([]model.Device, error) {
+	var devices []model.Device
+	if err := r.db.Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (r *DeviceRepository) FindByTokenHash(hash string) (*model.Device, error) {
+	var device model.Device
+	if err := r.db.First(&device, "token_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *DeviceRepository) Save(device *model.Device) error {
+	return r.db.Save(device).Error
+}
+
+// Delete removes one of the user's devices, reporting whether it existed
+func (r *DeviceRepository) Delete(userID, id uint) (bool, error) {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Device{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// DeleteByID removes a device whose token the push provider rejected
+func (r *DeviceRepository) DeleteByID(id uint) error {
+	return r.db.Delete(&model.Device{}, id).Error
+}
+
+// ReencryptTokens rewrites every stored push token so it is sealed with the
+// current encryption key
+func (r *DeviceRepository) ReencryptTokens() (int64, error) {
+	var count int64
+	var batch []model.Device
+	err := r.db.FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			err := r.db.Model(&batch[i]).
+				Select("token").
+				UpdateColumns(&batch[i]).Error
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}).Error
+	return count, err
+}
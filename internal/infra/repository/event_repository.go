@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EventRepository struct {
+	db *gorm.DB
+}
+
+func NewEventRepository(db *gorm.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// RSVPCounts is how many seats of an event are taken and how many users wait
+type RSVPCounts struct {
+	Confirmed  int64
+	Waitlisted int64
+}
+
+// FindEvents returns events that have not ended by from, soonest first.
+// bookID and room narrow the list when set.
+func (r *EventRepository) FindEvents(from time.Time, bookID uint, room string, limit int) ([]model.Event, error) {
+	query := r.db.Where("ends_at > ?", from)
+	if bookID != 0 {
+		query = query.Where("book_id = ?", bookID)
+	}
+	if room != "" {
+		query = query.Where("room = ?", room)
+	}
+
+	var events []model.Event
+	if err := query.Order("starts_at, id").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *EventRepository) FindByID(id uint) (*model.Event, error) {
+	var event model.Event
+	if err := r.db.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// FindByUser returns the events userID has an RSVP for, soonest first, and
+// the user's RSVPs
+func (r *EventRepository) FindByUser(userID uint) ([]model.Event, []model.EventRSVP, error) {
+	var rsvps []model.EventRSVP
+	if err := r.db.Where("user_id = ?", userID).Find(&rsvps).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(rsvps) == 0 {
+		return nil, nil, nil
+	}
+
+	ids := make([]uint, len(rsvps))
+	for i, rsvp := range rsvps {
+		ids[i] = rsvp.EventID
+	}
+	var events []model.Event
+	if err := r.db.Where("id IN ?", ids).Order("starts_at").Find(&events).Error; err != nil {
+		return nil, nil, err
+	}
+	return events, rsvps, nil
+}
+
+// RoomTaken reports whether another event in room overlaps start to end
+func (r *EventRepository) RoomTaken(room string, start, end time.Time, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Event{}).
+		Where("room = ? AND starts_at < ? AND ends_at > ? AND id <> ?", room, end, start, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *EventRepository) Create(event *model.Event) error {
+	return r.db.Create(event).Error
+}
+
+// Update saves the event and, if it has room for more attendees now, moves
+// waitlisted users up. It returns the RSVPs that got a seat.
+func (r *EventRepository) Update(event *model.Event) ([]model.EventRSVP, error) {
+	var promoted []model.EventRSVP
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var locked model.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, event.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(event).Error; err != nil {
+			return err
+		}
+		var err error
+		promoted, err = promoteWaitlist(tx, event)
+		return err
+	})
+	return promoted, err
+}
+
+func (r *EventRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", id).Delete(&model.EventRSVP{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&model.Event{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CountRSVPs returns the RSVP counts of each of the given events
+func (r *EventRepository) CountRSVPs(eventIDs []uint) (map[uint]RSVPCounts, error) {
+	counts := make(map[uint]RSVPCounts, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		EventID uint
+		Status  model.RSVPStatus
+		Total   int64
+	}
+	err := r.db.Model(&model.EventRSVP{}).
+		Select("event_id, status, COUNT(*) AS total").
+		Where("event_id IN ?", eventIDs).
+		Group("event_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		c := counts[row.EventID]
+		if row.Status == model.RSVPConfirmed {
+			c.Confirmed = row.Total
+		} else {
+			c.Waitlisted = row.Total
+		}
+		counts[row.EventID] = c
+	}
+	return counts, nil
+}
+
+// FindRSVPs lists an event's attendees, confirmed first, each group in the
+// order they signed up
+func (r *EventRepository) FindRSVPs(eventID uint) ([]model.EventRSVP, error) {
+	var rsvps []model.EventRSVP
+	err := r.db.Where("event_id = ?", eventID).
+		Order("status = 'confirmed' DESC, created_at").
+		Find(&rsvps).Error
+	if err != nil {
+		return nil, err
+	}
+	return rsvps, nil
+}
+
+// RSVP gives userID a seat at the event, or a waitlist place when it is
+// full. Asking again returns the existing RSVP unchanged. The event row is
+// locked so concurrent RSVPs cannot overbook it.
+func (r *EventRepository) RSVP(eventID, userID uint) (*model.EventRSVP, error) {
+	var rsvp model.EventRSVP
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var event model.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			return err
+		}
+
+		err := tx.First(&rsvp, "event_id = ? AND user_id = ?", eventID, userID).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		rsvp = model.EventRSVP{EventID: eventID, UserID: userID, Status: model.RSVPConfirmed}
+		if event.Capacity > 0 {
+			var confirmed int64
+			err := tx.Model(&model.EventRSVP{}).
+				Where("event_id = ? AND status = ?", eventID, model.RSVPConfirmed).
+				Count(&confirmed).Error
+			if err != nil {
+				return err
+			}
+			if confirmed >= int64(event.Capacity) {
+				rsvp.Status = model.RSVPWaitlisted
+			}
+		}
+		return tx.Create(&rsvp).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rsvp, nil
+}
+
+// CancelRSVP removes userID from the event and gives any freed seat to the
+// waitlist, returning the RSVPs that got a seat
+func (r *EventRepository) CancelRSVP(eventID, userID uint) ([]model.EventRSVP, error) {
+	var promoted []model.EventRSVP
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var event model.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			return err
+		}
+		res := tx.Where("event_id = ? AND user_id = ?", eventID, userID).Delete(&model.EventRSVP{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		var err error
+		promoted, err = promoteWaitlist(tx, &event)
+		return err
+	})
+	return promoted, err
+}
+
+// eraseRSVPs removes the user from every event, passing freed seats to the
+// waitlist without notifying anyone
+func eraseRSVPs(tx *gorm.DB, userID uint) error {
+	var eventIDs []uint
+	if err := tx.Model(&model.EventRSVP{}).Where("user_id = ?", userID).Pluck("event_id", &eventIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&model.EventRSVP{}).Error; err != nil {
+		return err
+	}
+	for _, id := range eventIDs {
+		var event model.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, id).Error; err != nil {
+			return err
+		}
+		if _, err := promoteWaitlist(tx, &event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promoteWaitlist confirms waitlisted RSVPs while the event has free seats.
+// Callers hold the lock on the event row.
+func promoteWaitlist(tx *gorm.DB, event *model.Event) ([]model.EventRSVP, error) {
+	query := tx.Where("event_id = ? AND status = ?", event.ID, model.RSVPWaitlisted).Order("created_at")
+	if event.Capacity > 0 {
+		var confirmed int64
+		err := tx.Model(&model.EventRSVP{}).
+			Where("event_id = ? AND status = ?", event.ID, model.RSVPConfirmed).
+			Count(&confirmed).Error
+		if err != nil {
+			return nil, err
+		}
+		free := int64(event.Capacity) - confirmed
+		if free <= 0 {
+			return nil, nil
+		}
+		query = query.Limit(int(free))
+	}
+
+	var promoted []model.EventRSVP
+	if err := query.Find(&promoted).Error; err != nil {
+		return nil, err
+	}
+	for i := range promoted {
+		promoted[i].Status = model.RSVPConfirmed
+		if err := tx.Save(&promoted[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return promoted, nil
+}
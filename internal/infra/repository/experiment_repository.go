@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ExperimentRepository struct {
+	db *gorm.DB
+}
+
+func NewExperimentRepository(db *gorm.DB) *ExperimentRepository {
+	return &ExperimentRepository{db: db}
+}
+
+func (r *ExperimentRepository) FindAll() ([]model.Experiment, error) {
+	var experiments []model.Experiment
+	err := r.db.Order("id").Find(&experiments).Error
+	return experiments, err
+}
+
+// FindEnabled returns the experiments currently running
+func (r *ExperimentRepository) FindEnabled() ([]model.Experiment, error) {
+	var experiments []model.Experiment
+	err := r.db.Where("enabled = ?", true).Order("id").Find(&experiments).Error
+	return experiments, err
+}
+
+func (r *ExperimentRepository) FindByID(id uint) (*model.Experiment, error) {
+	var experiment model.Experiment
+	if err := r.db.First(&experiment, id).Error; err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+func (r *ExperimentRepository) Create(experiment *model.Experiment) error {
+	return r.db.Create(experiment).Error
+}
+
+func (r *ExperimentRepository) Update(experiment *model.Experiment) error {
+	return r.db.Save(experiment).Error
+}
+
+// Delete removes the experiment, returning gorm.ErrRecordNotFound when it
+// does not exist
+func (r *ExperimentRepository) Delete(id uint) error {
+	res := r.db.Delete(&model.Experiment{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"bms-go/internal/model"
+	"context"
+	"errors"
 
 	"gorm.io/gorm"
 )
@@ -14,18 +16,43 @@ func NewFavoriteRepository(db *gorm.DB) *FavoriteRepository {
 	return &FavoriteRepository{db: db}
 }
 
-func (r *FavoriteRepository) FindAll(userID uint) ([]model.Favorite, error) {
+func (r *FavoriteRepository) FindAll(ctx context.Context, userID uint) ([]model.Favorite, error) {
 	var favs []model.Favorite
-	if err := r.db.Preload("Book").Where("user_id = ?", userID).Find(&favs).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&favs).Error; err != nil {
 		return nil, err
 	}
 	return favs, nil
 }
 
-func (r *FavoriteRepository) Create(fav *model.Favorite) error {
-	return r.db.Create(fav).Error
+func (r *FavoriteRepository) Create(ctx context.Context, fav *model.Favorite) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(fav).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE books SET favorite_count = favorite_count + 1 WHERE id = ?", fav.BookID).Error; err != nil {
+			return err
+		}
+		return recordChange(tx, model.EntityFavorite, fav.ID, model.ChangeOpCreate, fav)
+	})
 }
 
-func (r *FavoriteRepository) Delete(userID, favoriteID uint) error {
-	return r.db.Where("id = ? AND user_id = ?", favoriteID, userID).Delete(&model.Favorite{}).Error
+func (r *FavoriteRepository) Delete(ctx context.Context, userID, favoriteID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var fav model.Favorite
+		err := tx.Where("id = ? AND user_id = ?", favoriteID, userID).Take(&fav).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&fav).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE books SET favorite_count = favorite_count - 1 WHERE id = ? AND favorite_count > 0", fav.BookID).Error; err != nil {
+			return err
+		}
+		return recordChange(tx, model.EntityFavorite, favoriteID, model.ChangeOpDelete, map[string]uint{"id": favoriteID, "user_id": userID})
+	})
 }
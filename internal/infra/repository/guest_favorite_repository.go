@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GuestFavoriteRepository struct {
+	db *gorm.DB
+}
+
+func NewGuestFavoriteRepository(db *gorm.DB) *GuestFavoriteRepository {
+	return &GuestFavoriteRepository{db: db}
+}
+
+// FindAll returns a guest session's favorites, oldest first
+func (r *GuestFavoriteRepository) FindAll(sessionID string) ([]model.GuestFavorite, error) {
+	var favs []model.GuestFavorite
+	if err := r.db.Where("session_id = ?", sessionID).Order("created_at, id").Find(&favs).Error; err != nil {
+		return nil, err
+	}
+	return favs, nil
+}
+
+func (r *GuestFavoriteRepository) Count(sessionID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.GuestFavorite{}).Where("session_id = ?", sessionID).Count(&count).Error
+	return count, err
+}
+
+// Create adds a favorite, doing nothing if the session already has the book
+func (r *GuestFavoriteRepository) Create(fav *model.GuestFavorite) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(fav).Error
+}
+
+func (r *GuestFavoriteRepository) Delete(sessionID string, bookID uint) error {
+	return r.db.Where("session_id = ? AND book_id = ?", sessionID, bookID).Delete(&model.GuestFavorite{}).Error
+}
+
+// DeleteSession drops every favorite of a guest session
+func (r *GuestFavoriteRepository) DeleteSession(sessionID string) error {
+	return r.db.Where("session_id = ?", sessionID).Delete(&model.GuestFavorite{}).Error
+}
+
+// DeleteStale drops the favorites of guest sessions that have not added
+// anything since before, and returns how many rows were removed
+func (r *GuestFavoriteRepository) DeleteStale(before time.Time) (int64, error) {
+	// MySQL cannot select from the table a DELETE targets, except through a
+	// derived table
+	result := r.db.Exec(`DELETE FROM guest_favorites WHERE session_id IN (
+		SELECT session_id FROM (
+			SELECT session_id FROM guest_favorites GROUP BY session_id HAVING MAX(created_at) < ?
+		) AS stale
+	)`, before)
+	return result.RowsAffected, result.Error
+}
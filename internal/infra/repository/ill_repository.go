@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ILLRepository struct {
+	db *gorm.DB
+}
+
+func NewILLRepository(db *gorm.DB) *ILLRepository {
+	return &ILLRepository{db: db}
+}
+
+// Create stores the request with the first entry of its timeline
+func (r *ILLRepository) Create(req *model.ILLRequest) error {
+	return r.db.Create(req).Error
+}
+
+// FindByID returns the request with its timeline in order
+func (r *ILLRepository) FindByID(id uint) (*model.ILLRequest, error) {
+	var req model.ILLRequest
+	if err := r.withHistory().First(&req, id).Error; err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// FindAll lists requests with their timelines, newest first, optionally only
+// those with status or made by userID
+func (r *ILLRepository) FindAll(status model.ILLStatus, userID uint) ([]model.ILLRequest, error) {
+	db := r.withHistory().Order("id DESC")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if userID != 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+
+	var reqs []model.ILLRequest
+	if err := db.Find(&reqs).Error; err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// ChangeStatus moves the request to change.Status and appends change to its
+// timeline. The request row is locked and re-read so the transition is
+// checked against its current status; false is returned when it is not
+// allowed. externalLibrary replaces the stored one when not empty.
+func (r *ILLRepository) ChangeStatus(req *model.ILLRequest, change model.ILLStatusChange, externalLibrary string) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current model.ILLRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, req.ID).Error; err != nil {
+			return err
+		}
+		if !current.Status.CanMoveTo(change.Status) {
+			return nil
+		}
+
+		updates := map[string]interface{}{"status": change.Status}
+		if externalLibrary != "" {
+			updates["external_library"] = externalLibrary
+		}
+		if err := tx.Model(&current).Updates(updates).Error; err != nil {
+			return err
+		}
+		change.RequestID = req.ID
+		if err := tx.Create(&change).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+func (r *ILLRepository) withHistory() *gorm.DB {
+	return r.db.Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
+
+// eraseILLRequests removes the user's requests and their timelines
+func eraseILLRequests(tx *gorm.DB, userID uint) error {
+	var ids []uint
+	if err := tx.Model(&model.ILLRequest{}).Where("user_id = ?", userID).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("request_id IN ?", ids).Delete(&model.ILLStatusChange{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN ?", ids).Delete(&model.ILLRequest{}).Error
+}
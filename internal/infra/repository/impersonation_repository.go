@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImpersonationRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{db: db}
+}
+
+func (r *ImpersonationRepository) Create(session *model.ImpersonationSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *ImpersonationRepository) FindByID(id uint) (*model.ImpersonationSession, error) {
+	var session model.ImpersonationSession
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *ImpersonationRepository) FindByTokenHash(hash string) (*model.ImpersonationSession, error) {
+	var session model.ImpersonationSession
+	if err := r.db.First(&session, "token_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *ImpersonationRepository) FindAll() ([]model.ImpersonationSession, error) {
+	var sessions []model.ImpersonationSession
+	if err := r.db.Order("id DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Revoke ends the session now unless it was already revoked
+func (r *ImpersonationRepository) Revoke(id uint) error {
+	return r.db.Model(&model.ImpersonationSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *ImpersonationRepository) CreateAction(action *model.ImpersonationAction) error {
+	return r.db.Create(action).Error
+}
+
+func (r *ImpersonationRepository) FindActions(sessionID uint) ([]model.ImpersonationAction, error) {
+	var actions []model.ImpersonationAction
+	if err := r.db.Where("session_id = ?", sessionID).Order("id").Find(&actions).Error; err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ImportBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewImportBatchRepository(db *gorm.DB) *ImportBatchRepository {
+	return &ImportBatchRepository{db: db}
+}
+
+// FindAll lists import batches, newest first
+func (r *ImportBatchRepository) FindAll() ([]model.ImportBatch, error) {
+	var batches []model.ImportBatch
+	if err := r.db.Order("id DESC").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *ImportBatchRepository) FindByID(id uint) (*model.ImportBatch, error) {
+	var batch model.ImportBatch
+	if err := r.db.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *ImportBatchRepository) Create(batch *model.ImportBatch) error {
+	return r.db.Create(batch).Error
+}
+
+func (r *ImportBatchRepository) Save(batch *model.ImportBatch) error {
+	return r.db.Save(batch).Error
+}
+
+func (r *ImportBatchRepository) AddItem(item *model.ImportBatchItem) error {
+	return r.db.Create(item).Error
+}
+
+// FindItems returns the batch's items, most recent first, the order in
+// which a rollback undoes them
+func (r *ImportBatchRepository) FindItems(batchID uint) ([]model.ImportBatchItem, error) {
+	var items []model.ImportBatchItem
+	if err := r.db.Where("batch_id = ?", batchID).Order("id DESC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
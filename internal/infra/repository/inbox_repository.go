@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InboxRepository struct {
+	db *gorm.DB
+}
+
+func NewInboxRepository(db *gorm.DB) *InboxRepository {
+	return &InboxRepository{db: db}
+}
+
+func (r *InboxRepository) Create(n *model.InboxNotification) error {
+	return r.db.Create(n).Error
+}
+
+// FindPage returns up to limit of the user's notifications older than
+// before, newest first. A before of 0 starts from the newest.
+func (r *InboxRepository) FindPage(userID, before uint, unreadOnly bool, limit int) ([]model.InboxNotification, error) {
+	query := r.db.Where("user_id = ?", userID)
+	if before > 0 {
+		query = query.Where("id < ?", before)
+	}
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	var notifications []model.InboxNotification
+	if err := query.Order("id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *InboxRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.InboxNotification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications read, reporting whether
+// it exists. Marking it again keeps the first read time.
+func (r *InboxRepository) MarkRead(userID, id uint, at time.Time) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.InboxNotification{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+	err := r.db.Model(&model.InboxNotification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", at).Error
+	return true, err
+}
+
+// MarkAllRead marks every unread notification of the user read and returns
+// how many there were
+func (r *InboxRepository) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	res := r.db.Model(&model.InboxNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return res.RowsAffected, res.Error
+}
+
+// Delete removes one of the user's notifications, reporting whether it
+// existed
+func (r *InboxRepository) Delete(userID, id uint) (bool, error) {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.InboxNotification{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// DeleteBefore removes notifications created before cutoff and returns how
+// many were removed
+func (r *InboxRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	res := r.db.Where("created_at < ?", cutoff).Delete(&model.InboxNotification{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoanRepository struct {
+	db *gorm.DB
+}
+
+func NewLoanRepository(db *gorm.DB) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+// FindAll returns a page of loans matching query, newest first, and how
+// many match in total
+func (r *LoanRepository) FindAll(ctx context.Context, query dto.LoanQuery) ([]model.Loan, int64, error) {
+	db := r.db.WithContext(ctx).Model(&model.Loan{})
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.BookID != 0 {
+		db = db.Where("book_id = ?", query.BookID)
+	}
+	switch query.Status {
+	case dto.LoanActive:
+		db = db.Where("returned_at IS NULL")
+	case dto.LoanOverdue:
+		db = db.Where("returned_at IS NULL AND due_at < ?", time.Now())
+	case dto.LoanReturned:
+		db = db.Where("returned_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var loans []model.Loan
+	if err := db.Order("id DESC").Limit(query.Limit).Offset(query.Offset).Find(&loans).Error; err != nil {
+		return nil, 0, err
+	}
+	return loans, total, nil
+}
+
+// History returns userID's loans borrowed from from until before to,
+// newest first, with the title and author of each book. Deleted books are
+// included; a zero from or to leaves that end open.
+func (r *LoanRepository) History(ctx context.Context, userID uint, from, to time.Time) ([]dto.LoanHistoryEntry, error) {
+	db := r.db.WithContext(ctx).Model(&model.Loan{}).
+		Select("loans.id AS loan_id, loans.book_id, books.title, books.author, loans.borrowed_at, loans.due_at, loans.returned_at").
+		Joins("JOIN books ON books.id = loans.book_id").
+		Where("loans.user_id = ?", userID)
+	if !from.IsZero() {
+		db = db.Where("loans.borrowed_at >= ?", from)
+	}
+	if !to.IsZero() {
+		db = db.Where("loans.borrowed_at < ?", to)
+	}
+	var entries []dto.LoanHistoryEntry
+	if err := db.Order("loans.borrowed_at DESC").Order("loans.id DESC").Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ForgetReturned deletes the loans returned before cutoff by users who
+// opted out of keeping their loan history, returning how many were deleted
+func (r *LoanRepository) ForgetReturned(ctx context.Context, cutoff time.Time) (int64, error) {
+	db := r.db.WithContext(ctx)
+	optedOut := db.Model(&model.PrivacySetting{}).Select("user_id").Where("forget_loan_history = ?", true)
+	res := db.Where("returned_at < ? AND user_id IN (?)", cutoff, optedOut).Delete(&model.Loan{})
+	return res.RowsAffected, res.Error
+}
+
+// eraseLoans deletes the user's returned loans. Loans still out are kept so
+// their copies stay accounted for, but no longer name the user; a librarian
+// checks them in.
+func eraseLoans(tx *gorm.DB, userID uint) error {
+	if err := tx.Where("user_id = ? AND returned_at IS NOT NULL", userID).Delete(&model.Loan{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.Loan{}).Where("user_id = ?", userID).Update("user_id", 0).Error
+}
+
+func (r *LoanRepository) FindByID(ctx context.Context, id uint) (*model.Loan, error) {
+	var loan model.Loan
+	if err := r.db.WithContext(ctx).First(&loan, id).Error; err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// Borrow lends loan's book by storing loan on a copy in circulation, not
+// out on loan and not held for another user's reservation, fulfilling the
+// borrower's own reservation of the book. The book row is locked while a
+// copy is picked, so two users cannot borrow the same copy; false is
+// returned when no copy is free.
+func (r *LoanRepository) Borrow(ctx context.Context, loan *model.Loan, hold time.Duration) (bool, error) {
+	borrowed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var book model.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, loan.BookID).Error; err != nil {
+			return err
+		}
+		if err := promoteReservations(tx, book.ID, loan.BorrowedAt, hold); err != nil {
+			return err
+		}
+		spare, err := spareCopies(tx, book.ID, loan.UserID)
+		if err != nil {
+			return err
+		}
+		if spare <= 0 {
+			return nil
+		}
+
+		var free model.Copy
+		if err := tx.Where("book_id = ? AND "+lendableCondition, book.ID).Order("id").First(&free).Error; err != nil {
+			return err
+		}
+		loan.CopyID = free.ID
+		if err := tx.Create(loan).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Reservation{}).
+			Where("book_id = ? AND user_id = ? AND status IN ?", book.ID, loan.UserID,
+				[]model.ReservationStatus{model.ReservationWaiting, model.ReservationReady}).
+			Update("status", model.ReservationFulfilled).Error; err != nil {
+			return err
+		}
+		borrowed = true
+		return refreshStock(tx, book.ID)
+	})
+	return borrowed, err
+}
+
+// Return closes loan id at now, putting its copy back in stock and holding
+// it for the next reservation of the book. The book row is locked and the
+// loan re-read; false is returned when it was already returned. loan is
+// filled in either way.
+func (r *LoanRepository) Return(ctx context.Context, id uint, now time.Time, hold time.Duration, loan *model.Loan) (bool, error) {
+	returned := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(loan, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Book{}, loan.BookID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(loan, id).Error; err != nil {
+			return err
+		}
+		if loan.ReturnedAt != nil {
+			return nil
+		}
+		if err := tx.Model(loan).Update("returned_at", now).Error; err != nil {
+			return err
+		}
+		loan.ReturnedAt = &now
+		returned = true
+		if err := refreshStock(tx, loan.BookID); err != nil {
+			return err
+		}
+		return promoteReservations(tx, loan.BookID, now, hold)
+	})
+	return returned, err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) FindByUser(userID uint) ([]model.NotificationPreference, error) {
+	var prefs []model.NotificationPreference
+	if err := r.db.Where("user_id = ?", userID).Order("channel").Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (r *NotificationRepository) Find(userID uint, channel model.NotificationChannel) (*model.NotificationPreference, error) {
+	var pref model.NotificationPreference
+	if err := r.db.First(&pref, "user_id = ? AND channel = ?", userID, channel).Error; err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *NotificationRepository) Save(pref *model.NotificationPreference) error {
+	return r.db.Save(pref).Error
+}
+
+// Delete removes the user's setup for channel, reporting whether there was one
+func (r *NotificationRepository) Delete(userID uint, channel model.NotificationChannel) (bool, error) {
+	res := r.db.Where("user_id = ? AND channel = ?", userID, channel).Delete(&model.NotificationPreference{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ReencryptTargets rewrites every stored target so it is sealed with the
+// current encryption key
+func (r *NotificationRepository) ReencryptTargets() (int64, error) {
+	var count int64
+	var batch []model.NotificationPreference
+	err := r.db.FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			err := r.db.Model(&batch[i]).
+				Select("target").
+				UpdateColumns(&batch[i]).Error
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}).Error
+	return count, err
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create stores org and makes ownerID its owner
+func (r *OrganizationRepository) Create(org *model.Organization, ownerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         ownerID,
+			Role:           model.OrgRoleOwner,
+		}).Error
+	})
+}
+
+func (r *OrganizationRepository) FindByID(id uint) (*model.Organization, error) {
+	var org model.Organization
+	if err := r.db.First(&org, id).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// FindByMember returns the organizations userID belongs to with their role
+func (r *OrganizationRepository) FindByMember(userID uint) ([]model.Organization, []model.OrgRole, error) {
+	var rows []struct {
+		model.Organization
+		Role model.OrgRole
+	}
+	err := r.db.Model(&model.Organization{}).
+		Select("organizations.*, organization_members.role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.name").
+		Find(&rows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orgs := make([]model.Organization, len(rows))
+	roles := make([]model.OrgRole, len(rows))
+	for i, row := range rows {
+		orgs[i] = row.Organization
+		roles[i] = row.Role
+	}
+	return orgs, roles, nil
+}
+
+func (r *OrganizationRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ?", id).Delete(&model.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&model.OrganizationInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&model.OrganizationFavorite{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Organization{}, id).Error
+	})
+}
+
+// FindMember returns userID's membership of orgID, or nil
+func (r *OrganizationRepository) FindMember(orgID, userID uint) (*model.OrganizationMember, error) {
+	var member model.OrganizationMember
+	err := r.db.First(&member, "organization_id = ? AND user_id = ?", orgID, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *OrganizationRepository) FindMembers(orgID uint) ([]model.OrganizationMember, error) {
+	var members []model.OrganizationMember
+	if err := r.db.Where("organization_id = ?", orgID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *OrganizationRepository) CountOwners(orgID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", orgID, model.OrgRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *OrganizationRepository) SaveMember(member *model.OrganizationMember) error {
+	return r.db.Save(member).Error
+}
+
+func (r *OrganizationRepository) DeleteMember(orgID, userID uint) error {
+	return r.db.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&model.OrganizationMember{}).Error
+}
+
+func (r *OrganizationRepository) CreateInvitation(inv *model.OrganizationInvitation) error {
+	return r.db.Create(inv).Error
+}
+
+func (r *OrganizationRepository) FindInvitation(id uint) (*model.OrganizationInvitation, error) {
+	var inv model.OrganizationInvitation
+	if err := r.db.First(&inv, id).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// FindOpenInvitations returns userID's invitations that are still waiting
+// for an answer
+func (r *OrganizationRepository) FindOpenInvitations(userID uint) ([]model.OrganizationInvitation, error) {
+	var invs []model.OrganizationInvitation
+	err := r.db.Where("user_id = ? AND accepted_at IS NULL AND declined_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("id DESC").
+		Find(&invs).Error
+	if err != nil {
+		return nil, err
+	}
+	return invs, nil
+}
+
+// AcceptInvitation marks inv accepted and adds its user as a member
+func (r *OrganizationRepository) AcceptInvitation(inv *model.OrganizationInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		inv.AcceptedAt = &now
+		if err := tx.Save(inv).Error; err != nil {
+			return err
+		}
+		return tx.Save(&model.OrganizationMember{
+			OrganizationID: inv.OrganizationID,
+			UserID:         inv.UserID,
+			Role:           inv.Role,
+		}).Error
+	})
+}
+
+func (r *OrganizationRepository) SaveInvitation(inv *model.OrganizationInvitation) error {
+	return r.db.Save(inv).Error
+}
+
+func (r *OrganizationRepository) FindFavorites(orgID uint) ([]model.OrganizationFavorite, error) {
+	var favs []model.OrganizationFavorite
+	if err := r.db.Where("organization_id = ?", orgID).Order("id DESC").Find(&favs).Error; err != nil {
+		return nil, err
+	}
+	return favs, nil
+}
+
+func (r *OrganizationRepository) CreateFavorite(fav *model.OrganizationFavorite) error {
+	return r.db.Create(fav).Error
+}
+
+func (r *OrganizationRepository) DeleteFavorite(orgID, favoriteID uint) error {
+	return r.db.Where("id = ? AND organization_id = ?", favoriteID, orgID).Delete(&model.OrganizationFavorite{}).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PartnerRepository struct {
+	db *gorm.DB
+}
+
+func NewPartnerRepository(db *gorm.DB) *PartnerRepository {
+	return &PartnerRepository{db: db}
+}
+
+func (r *PartnerRepository) FindAll() ([]model.Partner, error) {
+	var partners []model.Partner
+	if err := r.db.Order("name").Find(&partners).Error; err != nil {
+		return nil, err
+	}
+	return partners, nil
+}
+
+func (r *PartnerRepository) FindByID(id uint) (*model.Partner, error) {
+	var partner model.Partner
+	if err := r.db.First(&partner, id).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+func (r *PartnerRepository) FindByKeyID(keyID string) (*model.Partner, error) {
+	var partner model.Partner
+	if err := r.db.First(&partner, "key_id = ?", keyID).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+func (r *PartnerRepository) Create(partner *model.Partner) error {
+	return r.db.Create(partner).Error
+}
+
+func (r *PartnerRepository) Update(partner *model.Partner) error {
+	return r.db.Save(partner).Error
+}
+
+func (r *PartnerRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Partner{}, id).Error
+}
+
+// ReencryptSecrets rewrites every stored secret, including those of deleted
+// partners, so they are sealed with the current encryption key
+func (r *PartnerRepository) ReencryptSecrets() (int64, error) {
+	var count int64
+	var batch []model.Partner
+	err := r.db.Unscoped().FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			// Updating from the struct runs the field's serializer, a
+			// column/value pair would be written as is
+			err := r.db.Unscoped().Model(&batch[i]).
+				Select("secret").
+				UpdateColumns(&batch[i]).Error
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}).Error
+	return count, err
+}
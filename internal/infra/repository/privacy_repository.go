@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type PrivacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) *PrivacyRepository {
+	return &PrivacyRepository{db: db}
+}
+
+// FindByUserID returns the user's stored settings, or the defaults when the
+// user has never changed them
+func (r *PrivacyRepository) FindByUserID(userID uint) (*model.PrivacySetting, error) {
+	var setting model.PrivacySetting
+	err := r.db.First(&setting, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		setting = model.DefaultPrivacySetting(userID)
+		return &setting, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *PrivacyRepository) Save(setting *model.PrivacySetting) error {
+	return r.db.Save(setting).Error
+}
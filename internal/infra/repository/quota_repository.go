@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type QuotaRepository struct {
+	db *gorm.DB
+}
+
+func NewQuotaRepository(db *gorm.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// FindQuota returns the subject's quota override, or nil when it has none
+func (r *QuotaRepository) FindQuota(subject string) (*model.APIQuota, error) {
+	var quota model.APIQuota
+	err := r.db.First(&quota, "subject = ?", subject).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+func (r *QuotaRepository) FindAllQuotas() ([]model.APIQuota, error) {
+	var quotas []model.APIQuota
+	if err := r.db.Order("subject").Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+func (r *QuotaRepository) SaveQuota(quota *model.APIQuota) error {
+	return r.db.Save(quota).Error
+}
+
+func (r *QuotaRepository) FindUsage(subject, period string) (int64, error) {
+	var usage model.APIUsage
+	err := r.db.First(&usage, "subject = ? AND period = ?", subject, period).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return usage.Count, nil
+}
+
+// IncrementUsage adds one request to the subject's counter for period
+func (r *QuotaRepository) IncrementUsage(subject, period string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subject"}, {Name: "period"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("api_usages.count + 1")}),
+	}).Create(&model.APIUsage{Subject: subject, Period: period, Count: 1}).Error
+}
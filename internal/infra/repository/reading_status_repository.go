@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// readingStatusBatchSize is how many statuses an import writes per statement
+const readingStatusBatchSize = 500
+
+type ReadingStatusRepository struct {
+	db *gorm.DB
+}
+
+func NewReadingStatusRepository(db *gorm.DB) *ReadingStatusRepository {
+	return &ReadingStatusRepository{db: db}
+}
+
+// FindByUser returns userID's reading statuses, most recently changed
+// first, optionally only those with status
+func (r *ReadingStatusRepository) FindByUser(userID uint, status model.ReadingState) ([]model.ReadingStatus, error) {
+	db := r.db.Where("user_id = ?", userID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	var statuses []model.ReadingStatus
+	if err := db.Order("updated_at DESC, id DESC").Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// Import stores statuses, replacing the user's existing status of each
+// book, and creates reviews, refreshing the rating summary of the reviewed
+// books, in one transaction
+func (r *ReadingStatusRepository) Import(statuses []model.ReadingStatus, reviews []model.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(statuses) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"status", "finished_at", "notes", "updated_at"}),
+			}).CreateInBatches(&statuses, readingStatusBatchSize).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(reviews) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(&reviews, readingStatusBatchSize).Error; err != nil {
+			return err
+		}
+		bookIDs := make([]uint, len(reviews))
+		for i, review := range reviews {
+			bookIDs[i] = review.BookID
+		}
+		return refreshRatings(tx, bookIDs)
+	})
+}
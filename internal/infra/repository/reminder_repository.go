@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) *ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+func (r *ReminderRepository) FindSetting(userID uint) (*model.ReminderSetting, error) {
+	var setting model.ReminderSetting
+	if err := r.db.First(&setting, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *ReminderRepository) SaveSetting(setting *model.ReminderSetting) error {
+	return r.db.Save(setting).Error
+}
+
+// FindDue returns the loans still out that are due from from until before
+// until, soonest first. Loans of erased accounts, which name no user, are
+// left out.
+func (r *ReminderRepository) FindDue(from, until time.Time) ([]dto.DueLoan, error) {
+	var loans []dto.DueLoan
+	err := r.db.Model(&model.Loan{}).
+		Select("loans.id AS loan_id, loans.user_id, loans.book_id, books.title, loans.borrowed_at, loans.due_at").
+		Joins("JOIN books ON books.id = loans.book_id").
+		Where("loans.returned_at IS NULL AND loans.user_id <> 0").
+		Where("loans.due_at >= ? AND loans.due_at < ?", from, until).
+		Order("loans.due_at").
+		Scan(&loans).Error
+	if err != nil {
+		return nil, err
+	}
+	return loans, nil
+}
+
+// MarkSent records that the reminder daysBefore loanID is due was sent,
+// reporting false when it already had been
+func (r *ReminderRepository) MarkSent(loanID uint, daysBefore int, at time.Time) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.LoanReminder{
+		LoanID:     loanID,
+		DaysBefore: daysBefore,
+		SentAt:     at,
+	})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ForgetSent deletes the records of reminders for loans no longer out,
+// which will not be reminded of again, returning how many were deleted
+func (r *ReminderRepository) ForgetSent() (int64, error) {
+	out := r.db.Model(&model.Loan{}).Select("id").Where("returned_at IS NULL")
+	res := r.db.Where("loan_id NOT IN (?)", out).Delete(&model.LoanReminder{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+func (r *ReportRepository) FindSchedules() ([]model.ReportSchedule, error) {
+	var schedules []model.ReportSchedule
+	if err := r.db.Order("id").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *ReportRepository) FindSchedule(id uint) (*model.ReportSchedule, error) {
+	var schedule model.ReportSchedule
+	if err := r.db.First(&schedule, id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindDueSchedules returns active schedules whose next run is not after now
+func (r *ReportRepository) FindDueSchedules(now time.Time) ([]model.ReportSchedule, error) {
+	var schedules []model.ReportSchedule
+	if err := r.db.Where("active = ? AND next_run_at <= ?", true, now).Order("next_run_at").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *ReportRepository) SaveSchedule(schedule *model.ReportSchedule) error {
+	return r.db.Save(schedule).Error
+}
+
+func (r *ReportRepository) DeleteSchedule(id uint) error {
+	return r.db.Delete(&model.ReportSchedule{}, id).Error
+}
+
+// SaveRun stores run and advances its schedule in one transaction
+func (r *ReportRepository) SaveRun(run *model.ReportRun, schedule *model.ReportSchedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		return tx.Save(schedule).Error
+	})
+}
+
+// FindRuns lists a schedule's runs, newest first, without their content
+func (r *ReportRepository) FindRuns(scheduleID uint) ([]model.ReportRun, error) {
+	var runs []model.ReportRun
+	err := r.db.Omit("content").Where("schedule_id = ?", scheduleID).Order("id DESC").Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *ReportRepository) FindRun(id uint) (*model.ReportRun, error) {
+	var run model.ReportRun
+	if err := r.db.First(&run, id).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindBooksCreatedBetween returns books added in [from, to)
+func (r *ReportRepository) FindBooksCreatedBetween(from, to time.Time) ([]model.Book, error) {
+	var books []model.Book
+	err := r.db.Where("created_at >= ? AND created_at < ?", from, to).Order("created_at").Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
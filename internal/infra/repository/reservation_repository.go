@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// positionColumn numbers each waiting reservation within its book's queue
+const positionColumn = "CASE WHEN reservations.status = 'waiting' THEN " +
+	"(SELECT COUNT(*) FROM reservations AS ahead WHERE ahead.book_id = reservations.book_id AND ahead.status = 'waiting' AND ahead.id <= reservations.id) " +
+	"ELSE 0 END AS position"
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// FindAll returns a page of reservations matching query, newest first, and
+// how many match in total
+func (r *ReservationRepository) FindAll(query dto.ReservationQuery) ([]model.Reservation, int64, error) {
+	db := r.db.Model(&model.Reservation{})
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.BookID != 0 {
+		db = db.Where("book_id = ?", query.BookID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var reservations []model.Reservation
+	err := db.Select("reservations.*, " + positionColumn).
+		Order("id DESC").
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reservations, total, nil
+}
+
+func (r *ReservationRepository) FindByID(id uint) (*model.Reservation, error) {
+	var reservation model.Reservation
+	if err := r.db.Select("reservations.*, "+positionColumn).First(&reservation, id).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// FindActive returns userID's reservation of book bookID that is waiting or
+// ready, or gorm.ErrRecordNotFound when there is none
+func (r *ReservationRepository) FindActive(userID, bookID uint) (*model.Reservation, error) {
+	var reservation model.Reservation
+	err := r.db.Where("user_id = ? AND book_id = ? AND status IN ?", userID, bookID,
+		[]model.ReservationStatus{model.ReservationWaiting, model.ReservationReady}).
+		First(&reservation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// Reserve queues reservation at the back of its book's queue. The book row
+// is locked while the queue is checked; false is returned when a copy is
+// free for the user to borrow instead.
+func (r *ReservationRepository) Reserve(reservation *model.Reservation, hold time.Duration) (bool, error) {
+	reserved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var book model.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, reservation.BookID).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := promoteReservations(tx, book.ID, now, hold); err != nil {
+			return err
+		}
+		spare, err := spareCopies(tx, book.ID, reservation.UserID)
+		if err != nil {
+			return err
+		}
+		if spare > 0 {
+			return nil
+		}
+
+		reservation.Status = model.ReservationWaiting
+		if err := tx.Create(reservation).Error; err != nil {
+			return err
+		}
+		var position int64
+		if err := tx.Model(&model.Reservation{}).
+			Where("book_id = ? AND status = ? AND id <= ?", book.ID, model.ReservationWaiting, reservation.ID).
+			Count(&position).Error; err != nil {
+			return err
+		}
+		reservation.Position = int(position)
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+// Cancel cancels reservation id, passing a copy held for it on to the next
+// in the queue. The book row is locked and the reservation re-read; false
+// is returned when it was no longer waiting or ready. reservation is filled
+// in either way.
+func (r *ReservationRepository) Cancel(id uint, hold time.Duration, reservation *model.Reservation) (bool, error) {
+	cancelled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(reservation, id).Error; err != nil {
+			return err
+		}
+		var book model.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, reservation.BookID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(reservation, id).Error; err != nil {
+			return err
+		}
+		if reservation.Status != model.ReservationWaiting && reservation.Status != model.ReservationReady {
+			return nil
+		}
+		if err := tx.Model(reservation).Update("status", model.ReservationCancelled).Error; err != nil {
+			return err
+		}
+		reservation.Status = model.ReservationCancelled
+		cancelled = true
+		return promoteReservations(tx, book.ID, time.Now(), hold)
+	})
+	return cancelled, err
+}
+
+// Settle releases the holds that ran out by now and offers free copies to
+// the books' queues, returning how many books were settled
+func (r *ReservationRepository) Settle(now time.Time, hold time.Duration) (int, error) {
+	var ids []uint
+	err := r.db.Model(&model.Reservation{}).
+		Where("status = ? OR (status = ? AND expires_at <= ?)", model.ReservationWaiting, model.ReservationReady, now).
+		Distinct().
+		Pluck("book_id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Book{}, id).Error; err != nil {
+				return err
+			}
+			return promoteReservations(tx, id, now, hold)
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// eraseReservations deletes the user's reservations. Copies held for them
+// go to the next in the queue when reservations are next settled.
+func eraseReservations(tx *gorm.DB, userID uint) error {
+	return tx.Where("user_id = ?", userID).Delete(&model.Reservation{}).Error
+}
+
+// promoteReservations expires the holds on book id that ran out by now and
+// holds each copy nobody else is holding for the oldest waiting
+// reservations, for hold. The book row must be locked.
+func promoteReservations(tx *gorm.DB, id uint, now time.Time, hold time.Duration) error {
+	if err := tx.Model(&model.Reservation{}).
+		Where("book_id = ? AND status = ? AND expires_at <= ?", id, model.ReservationReady, now).
+		Update("status", model.ReservationExpired).Error; err != nil {
+		return err
+	}
+	spare, err := spareCopies(tx, id, 0)
+	if err != nil {
+		return err
+	}
+	if spare <= 0 {
+		return nil
+	}
+
+	var next []uint
+	if err := tx.Model(&model.Reservation{}).
+		Where("book_id = ? AND status = ?", id, model.ReservationWaiting).
+		Order("id").
+		Limit(spare).
+		Pluck("id", &next).Error; err != nil {
+		return err
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	return tx.Model(&model.Reservation{}).Where("id IN ?", next).Updates(map[string]interface{}{
+		"status":     model.ReservationReady,
+		"ready_at":   now,
+		"expires_at": now.Add(hold),
+	}).Error
+}
+
+// spareCopies counts the copies of book id in circulation, not out on loan
+// and not held for a ready reservation of anyone but userID
+func spareCopies(tx *gorm.DB, id, userID uint) (int, error) {
+	var free, held int64
+	if err := tx.Model(&model.Copy{}).Where("book_id = ? AND "+lendableCondition, id).Count(&free).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&model.Reservation{}).
+		Where("book_id = ? AND status = ? AND user_id <> ?", id, model.ReservationReady, userID).
+		Count(&held).Error; err != nil {
+		return 0, err
+	}
+	return int(free - held), nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeCounts is how many rows a purge removed, or would remove, per table
+type PurgeCounts struct {
+	Books     int64 `json:"books"`
+	Favorites int64 `json:"favorites"`
+	BookViews int64 `json:"book_views"`
+}
+
+type RetentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// FindBooksDeletedBefore returns soft-deleted books whose deletion is older
+// than cutoff
+func (r *RetentionRepository) FindBooksDeletedBefore(cutoff time.Time) ([]model.Book, error) {
+	var books []model.Book
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Order("deleted_at").
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// CountDependents counts the rows that reference bookIDs and would be removed
+// along with them
+func (r *RetentionRepository) CountDependents(bookIDs []uint) (PurgeCounts, error) {
+	counts := PurgeCounts{Books: int64(len(bookIDs))}
+	if len(bookIDs) == 0 {
+		return counts, nil
+	}
+	if err := r.db.Unscoped().Model(&model.Favorite{}).Where("book_id IN ?", bookIDs).Count(&counts.Favorites).Error; err != nil {
+		return counts, err
+	}
+	if err := r.db.Model(&model.BookView{}).Where("book_id IN ?", bookIDs).Count(&counts.BookViews).Error; err != nil {
+		return counts, err
+	}
+	return counts, nil
+}
+
+// PurgeBooks permanently deletes bookIDs and every row referencing them in a
+// single transaction
+func (r *RetentionRepository) PurgeBooks(bookIDs []uint) (PurgeCounts, error) {
+	var counts PurgeCounts
+	if len(bookIDs) == 0 {
+		return counts, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("book_id IN ?", bookIDs).Delete(&model.Favorite{})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts.Favorites = res.RowsAffected
+
+		res = tx.Where("book_id IN ?", bookIDs).Delete(&model.BookView{})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts.BookViews = res.RowsAffected
+
+		if err := tx.Where("book_id IN ?", bookIDs).Delete(&model.CollectionBook{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("book_id IN ?", bookIDs).Delete(&model.ReadingStatus{}).Error; err != nil {
+			return err
+		}
+
+		res = tx.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", bookIDs).Delete(&model.Book{})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts.Books = res.RowsAffected
+		return nil
+	})
+	return counts, err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// FindByBook returns a page of the book's reviews, newest first, and how
+// many it has in total
+func (r *ReviewRepository) FindByBook(bookID uint, limit, offset int) ([]model.Review, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.Review{}).Where("book_id = ?", bookID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []model.Review
+	err := r.db.Where("book_id = ?", bookID).Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// Exists reports whether userID has already reviewed bookID
+func (r *ReviewRepository) Exists(bookID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Review{}).Where("book_id = ? AND user_id = ?", bookID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// FindReviewedBooks returns which of bookIDs userID has already reviewed
+func (r *ReviewRepository) FindReviewedBooks(userID uint, bookIDs []uint) ([]uint, error) {
+	var reviewed []uint
+	if len(bookIDs) == 0 {
+		return reviewed, nil
+	}
+	err := r.db.Model(&model.Review{}).Where("user_id = ? AND book_id IN ?", userID, bookIDs).Pluck("book_id", &reviewed).Error
+	return reviewed, err
+}
+
+// Create stores review and refreshes its book's rating summary in one
+// transaction
+func (r *ReviewRepository) Create(review *model.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		return refreshRatings(tx, []uint{review.BookID})
+	})
+}
+
+// refreshRatings recomputes average_rating and rating_count of the books in
+// bookIDs from their reviews
+func refreshRatings(tx *gorm.DB, bookIDs []uint) error {
+	if len(bookIDs) == 0 {
+		return nil
+	}
+	return tx.Exec(`UPDATE books SET
+		rating_count = (SELECT COUNT(*) FROM reviews WHERE reviews.book_id = books.id),
+		average_rating = COALESCE((SELECT AVG(rating) FROM reviews WHERE reviews.book_id = books.id), 0)
+		WHERE id IN ?`, bookIDs).Error
+}
+
+// reviewExists wraps a condition on reviews into one matching books with
+// such a review
+func reviewExists(condition string) string {
+	return "EXISTS (SELECT 1 FROM reviews WHERE reviews.book_id = books.id AND " + condition + ")"
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+
+	"gorm.io/gorm"
+)
+
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+type groupCount struct {
+	Key   string
+	Count int64
+}
+
+// CountCatalog counts the figures of a stats snapshot. Soft-deleted books
+// and favorites are left out.
+func (r *StatsRepository) CountCatalog() (*dto.CatalogStats, error) {
+	stats := &dto.CatalogStats{}
+	var err error
+
+	if stats.BooksByMediaType, err = r.countBy(&model.Book{}, "media_type"); err != nil {
+		return nil, err
+	}
+	if stats.ILLRequestsByStatus, err = r.countBy(&model.ILLRequest{}, "status"); err != nil {
+		return nil, err
+	}
+	if stats.RSVPsByStatus, err = r.countBy(&model.EventRSVP{}, "status"); err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&model.Favorite{}).Count(&stats.Favorites).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&model.User{}).Count(&stats.Users).Error; err != nil {
+		return nil, err
+	}
+	err = r.db.Model(&model.ChangeRequest{}).
+		Where("status = ?", model.ChangePending).
+		Count(&stats.PendingChangeRequests).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) countBy(table interface{}, column string) (map[string]int64, error) {
+	var rows []groupCount
+	err := r.db.Model(table).
+		Select(column + " AS `key`, COUNT(*) AS count").
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts, nil
+}
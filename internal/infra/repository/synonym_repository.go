@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type SynonymRepository struct {
+	db *gorm.DB
+}
+
+func NewSynonymRepository(db *gorm.DB) *SynonymRepository {
+	return &SynonymRepository{db: db}
+}
+
+func (r *SynonymRepository) FindAll() ([]model.Synonym, error) {
+	var synonyms []model.Synonym
+	if err := r.db.Order("term, synonym").Find(&synonyms).Error; err != nil {
+		return nil, err
+	}
+	return synonyms, nil
+}
+
+func (r *SynonymRepository) FindByID(id uint) (*model.Synonym, error) {
+	var synonym model.Synonym
+	if err := r.db.First(&synonym, id).Error; err != nil {
+		return nil, err
+	}
+	return &synonym, nil
+}
+
+func (r *SynonymRepository) Create(synonym *model.Synonym) error {
+	return r.db.Create(synonym).Error
+}
+
+func (r *SynonymRepository) Update(synonym *model.Synonym) error {
+	return r.db.Save(synonym).Error
+}
+
+func (r *SynonymRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Synonym{}, id).Error
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// FindAll lists every tag by name with its number of books
+func (r *TagRepository) FindAll() ([]dto.TagResponse, error) {
+	var tags []dto.TagResponse
+	err := r.db.Model(&model.Tag{}).
+		Select("tags.id, tags.name, COUNT(books.id) AS books").
+		Joins("LEFT JOIN book_tags ON book_tags.tag_id = tags.id").
+		Joins("LEFT JOIN books ON books.id = book_tags.book_id AND books.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Order("tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) FindByID(id uint) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Add puts the tag called name on book, creating the tag when there is
+// none of that name ignoring case, and records a change event when the
+// book did not have it yet. book's Tags are reloaded either way.
+func (r *TagRepository) Add(book *model.Book, name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tag model.Tag
+		if err := byName(tx, name).Attrs(model.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return err
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.BookTag{BookID: book.ID, TagID: tag.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		return retagged(tx, book, res.RowsAffected > 0)
+	})
+}
+
+// Remove takes tag id off book, recording a change event when the book had
+// it, and reloads book's Tags. It returns gorm.ErrRecordNotFound when the
+// book does not have the tag.
+func (r *TagRepository) Remove(book *model.Book, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("book_id = ? AND tag_id = ?", book.ID, id).Delete(&model.BookTag{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return retagged(tx, book, true)
+	})
+}
+
+// retagged reloads book's tags and, when they changed, touches the book
+// and records a change event for it
+func retagged(tx *gorm.DB, book *model.Book, changed bool) error {
+	if err := reloadTags(tx, book); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	book.UpdatedAt = time.Now()
+	if err := tx.Model(&model.Book{}).Where("id = ?", book.ID).Update("updated_at", book.UpdatedAt).Error; err != nil {
+		return err
+	}
+	return recordChange(tx, model.EntityBook, book.ID, model.ChangeOpUpdate, book)
+}
+
+// reloadTags replaces book's Tags with the ones it has in db
+func reloadTags(db *gorm.DB, book *model.Book) error {
+	books := []model.Book{*book}
+	if err := loadTags(db, books); err != nil {
+		return err
+	}
+	book.Tags = books[0].Tags
+	return nil
+}
+
+// loadTags fills in each book's Tags by name
+func loadTags(db *gorm.DB, books []model.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	var rows []struct {
+		BookID uint
+		model.Tag
+	}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table("book_tags").
+		Select("book_tags.book_id, tags.*").
+		Joins("JOIN tags ON tags.id = book_tags.tag_id").
+		Where("book_tags.book_id IN ?", ids).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	byBook := make(map[uint][]model.Tag, len(books))
+	for _, row := range rows {
+		byBook[row.BookID] = append(byBook[row.BookID], row.Tag)
+	}
+	for i := range books {
+		books[i].Tags = byBook[books[i].ID]
+		if books[i].Tags == nil {
+			books[i].Tags = []model.Tag{}
+		}
+	}
+	return nil
+}
+
+// taggedCondition matches books with the tag of the lowercase name bound
+// to it
+const taggedCondition = "EXISTS (SELECT 1 FROM book_tags JOIN tags ON tags.id = book_tags.tag_id " +
+	"WHERE book_tags.book_id = books.id AND LOWER(tags.name) = ?)"
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type UpstreamRepository struct {
+	db *gorm.DB
+}
+
+func NewUpstreamRepository(db *gorm.DB) *UpstreamRepository {
+	return &UpstreamRepository{db: db}
+}
+
+func (r *UpstreamRepository) Create(record *model.UpstreamRecord) error {
+	return r.db.Create(record).Error
+}
+
+func (r *UpstreamRepository) Save(record *model.UpstreamRecord) error {
+	return r.db.Save(record).Error
+}
+
+// FindStale returns up to limit records of books still in the catalog,
+// least recently fetched first
+func (r *UpstreamRepository) FindStale(limit int) ([]model.UpstreamRecord, error) {
+	var records []model.UpstreamRecord
+	err := r.db.Joins("JOIN books ON books.id = upstream_records.book_id AND books.deleted_at IS NULL").
+		Order("upstream_records.fetched_at").Limit(limit).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"bms-go/internal/infra/encryption"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// FindAll returns a page of users matching query, oldest first, and how
+// many match in total. Emails are encrypted, so a search only finds an
+// account by its whole email.
+func (r *UserRepository) FindAll(query dto.UserQuery) ([]model.User, int64, error) {
+	db := r.db.Model(&model.User{})
+	if query.Search != "" {
+		like := containsPattern(query.Search)
+		emailHash := encryption.BlindIndex(strings.ToLower(strings.TrimSpace(query.Search)))
+		db = db.Where("email_hash = ? OR name LIKE ? OR card_number LIKE ?", emailHash, like, like)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []model.User
+	if err := db.Order("id").Limit(query.Limit).Offset(query.Offset).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *UserRepository) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByEmail finds the account with email, which must already be
+// normalized, through its blind index
+func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, "email_hash = ?", encryption.BlindIndex(email)).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) FindByCardNumber(number string) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, "card_number = ?", number).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Create and Save keep the blind index in step with the email
+func (r *UserRepository) Create(user *model.User) error {
+	user.EmailHash = encryption.BlindIndex(user.Email)
+	return r.db.Create(user).Error
+}
+
+func (r *UserRepository) Save(user *model.User) error {
+	user.EmailHash = encryption.BlindIndex(user.Email)
+	return r.db.Save(user).Error
+}
+
+// CountActivity counts the user's rows in the tables that reference them
+func (r *UserRepository) CountActivity(userID uint) (*dto.UserActivity, error) {
+	var activity dto.UserActivity
+	counts := []struct {
+		model interface{}
+		dest  *int64
+	}{
+		{&model.Favorite{}, &activity.Favorites},
+		{&model.BookView{}, &activity.BookViews},
+		{&model.EventRSVP{}, &activity.EventRSVPs},
+		{&model.ILLRequest{}, &activity.ILLRequests},
+		{&model.OrganizationMember{}, &activity.Organizations},
+		{&model.Device{}, &activity.Devices},
+		{&model.Review{}, &activity.Reviews},
+	}
+	for _, c := range counts {
+		if err := r.db.Model(c.model).Where("user_id = ?", userID).Count(c.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &activity, nil
+}
+
+// ReencryptEmails rewrites every stored email so it is sealed with the
+// current encryption key, and recomputes its blind index with the current
+// blind index key
+func (r *UserRepository) ReencryptEmails() (int64, error) {
+	var count int64
+	var batch []model.User
+	err := r.db.FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			batch[i].EmailHash = encryption.BlindIndex(batch[i].Email)
+			err := r.db.Model(&batch[i]).
+				Select("email", "email_hash").
+				UpdateColumns(&batch[i]).Error
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}).Error
+	return count, err
+}
+
+// EncryptUserEmails moves accounts from plaintext emails under a unique
+// index to encrypted emails kept unique by their blind index. It has to run
+// before AutoMigrate, which would fail adding the unique email_hash index
+// to rows that have no hash yet; it does nothing once email_hash exists.
+func EncryptUserEmails(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&model.User{}) || m.HasColumn(&model.User{}, "EmailHash") {
+		return nil
+	}
+	// Ciphertext is random, so the old index would only get in the way
+	if m.HasIndex(&model.User{}, "idx_users_email") {
+		if err := m.DropIndex(&model.User{}, "idx_users_email"); err != nil {
+			return err
+		}
+	}
+	if err := m.AlterColumn(&model.User{}, "Email"); err != nil {
+		return err
+	}
+	if err := m.AddColumn(&model.User{}, "EmailHash"); err != nil {
+		return err
+	}
+	_, err := NewUserRepository(db).ReencryptEmails()
+	return err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"bms-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ValidationRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewValidationRuleRepository(db *gorm.DB) *ValidationRuleRepository {
+	return &ValidationRuleRepository{db: db}
+}
+
+func (r *ValidationRuleRepository) FindAll() ([]model.ValidationRule, error) {
+	var rules []model.ValidationRule
+	if err := r.db.Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *ValidationRuleRepository) FindByID(id uint) (*model.ValidationRule, error) {
+	var rule model.ValidationRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *ValidationRuleRepository) Create(rule *model.ValidationRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *ValidationRuleRepository) Update(rule *model.ValidationRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *ValidationRuleRepository) Delete(id uint) error {
+	res := r.db.Delete(&model.ValidationRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// AccountDeletion is a user's request to erase their personal data. Rows are
+// kept after the erasure as the audit trail of what was removed and when.
+type AccountDeletion struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	UserID       uint       `gorm:"index" json:"user_id"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ScheduledFor time.Time  `gorm:"index" json:"scheduled_for"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	Favorites    int64      `json:"favorites"`
+	BookViews    int64      `json:"book_views"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// APIQuota overrides the default monthly request allowance of a subject:
+// "partner:<partner id>", "key:<key id>" or "user:<user id>"
+type APIQuota struct {
+	Subject      string    `gorm:"primarykey;size:64" json:"subject"`
+	MonthlyLimit int64     `json:"monthly_limit"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// APIUsage counts a subject's requests in one calendar month (YYYY-MM, UTC)
+type APIUsage struct {
+	Subject string `gorm:"primarykey;size:64" json:"subject"`
+	Period  string `gorm:"primarykey;size:7" json:"period"`
+	Count   int64  `json:"count"`
+}
@@ -0,0 +1,17 @@
+package model
+
+// ArchivedChangeEvent is a change event moved out of the live change log by
+// the archival job
+type ArchivedChangeEvent ChangeEvent
+
+func (ArchivedChangeEvent) TableName() string {
+	return "change_events_archive"
+}
+
+// ArchivedImpersonationAction is an impersonation audit entry moved out of
+// the live table by the archival job
+type ArchivedImpersonationAction ImpersonationAction
+
+func (ArchivedImpersonationAction) TableName() string {
+	return "impersonation_actions_archive"
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Author writes books. Names are unique ignoring case; a book lists its
+// authors in order through BookAuthor.
+type Author struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:255;not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookAuthor links a book to one of its authors. Position orders a book's
+// authors, first author first.
+type BookAuthor struct {
+	BookID   uint    `gorm:"primaryKey" json:"book_id"`
+	AuthorID uint    `gorm:"primaryKey;index" json:"author_id"`
+	Position int     `gorm:"not null;default:0" json:"position"`
+	Author   *Author `gorm:"constraint:OnDelete:RESTRICT" json:"-"`
+}
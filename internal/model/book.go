@@ -2,9 +2,143 @@ package model
 
 import "gorm.io/gorm"
 
+// ContentRating is the audience a book is suitable for. Books without a
+// rating are treated as unrated, not as suitable for everyone.
+type ContentRating string
+
+const (
+	RatingAllAges ContentRating = "all_ages"
+	RatingTeen    ContentRating = "teen"
+	RatingAdult   ContentRating = "adult"
+)
+
+func (r ContentRating) Valid() bool {
+	switch r {
+	case RatingAllAges, RatingTeen, RatingAdult:
+		return true
+	}
+	return false
+}
+
+// MediaType is the form a book is published in
+type MediaType string
+
+const (
+	MediaPrint     MediaType = "print"
+	MediaEbook     MediaType = "ebook"
+	MediaAudiobook MediaType = "audiobook"
+)
+
+func (m MediaType) Valid() bool {
+	switch m {
+	case MediaPrint, MediaEbook, MediaAudiobook:
+		return true
+	}
+	return false
+}
+
+// BookSource is how a book entered the catalog
+type BookSource string
+
+const (
+	// SourceManual books were added one at a time through the API
+	SourceManual BookSource = "manual"
+	// SourceImport books were added by an import batch
+	SourceImport BookSource = "import"
+	// SourceUpstream books were pulled from another deployment and are
+	// kept up to date with it, see UpstreamRecord
+	SourceUpstream BookSource = "upstream"
+)
+
+func (s BookSource) Valid() bool {
+	switch s {
+	case SourceManual, SourceImport, SourceUpstream:
+		return true
+	}
+	return false
+}
+
+// AccessibilityFeature is an accessible format a book can be found in
+type AccessibilityFeature string
+
+const (
+	FeatureLargePrint   AccessibilityFeature = "large_print"
+	FeatureBraille      AccessibilityFeature = "braille"
+	FeatureAudiobook    AccessibilityFeature = "audiobook"
+	FeatureDyslexicFont AccessibilityFeature = "dyslexic_font"
+)
+
+// AccessibilityFeatures lists every feature in display order
+var AccessibilityFeatures = []AccessibilityFeature{FeatureLargePrint, FeatureBraille, FeatureAudiobook, FeatureDyslexicFont}
+
+func (f AccessibilityFeature) Valid() bool {
+	for _, feature := range AccessibilityFeatures {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Accessibility records which accessible formats of a book the library
+// holds, so patrons with accessibility needs can find a suitable edition
+type Accessibility struct {
+	LargePrint   bool `json:"large_print"`
+	Braille      bool `json:"braille"`
+	Audiobook    bool `json:"audiobook"`
+	DyslexicFont bool `json:"dyslexic_font"`
+}
+
 type Book struct {
 	gorm.Model
-	Title    string `json:"title"`
-	Author   string `json:"author"`
-	Category string `json:"category"`
+	Title string `json:"title"`
+	// Author is the names of Authors joined with ", ", kept in step by the
+	// book and author writes. A book written with an author name that no
+	// longer matches its Authors has that one author instead.
+	Author string `json:"author"`
+	// Authors are the book's authors in order, loaded by BookRepository
+	Authors []Author `json:"authors" gorm:"-"`
+	// Tags are the book's tags by name, loaded by BookRepository. They are
+	// changed through the book's tag routes, not by writing the book.
+	Tags []Tag `json:"tags" gorm:"-"`
+	// Category is the name of the category CategoryID points at, kept in
+	// step with it by the book and category writes. A book written with
+	// only a name is filed under the category of that name.
+	Category       string        `json:"category"`
+	CategoryID     *uint         `json:"category_id,omitempty" gorm:"index"`
+	CategoryRecord *Category     `json:"-" gorm:"foreignKey:CategoryID;constraint:OnDelete:RESTRICT"`
+	Description    string        `json:"description" gorm:"type:text"`
+	PublishedYear  int           `json:"published_year"`
+	Pages          int           `json:"pages"`
+	CoverURL       string        `json:"cover_url"`
+	ContentRating  ContentRating `json:"content_rating" gorm:"size:16;index"`
+	MediaType      MediaType     `json:"media_type" gorm:"size:16;not null;default:print;index"`
+	// Narrator and DurationMinutes only apply to audiobooks
+	Narrator        string        `json:"narrator,omitempty" gorm:"size:255"`
+	DurationMinutes int           `json:"duration_minutes,omitempty"`
+	Accessibility   Accessibility `json:"accessibility" gorm:"embedded;embeddedPrefix:access_"`
+	// FavoriteCount is maintained by the favorite writes themselves and is
+	// never written when a book is saved
+	FavoriteCount int64 `json:"favorite_count" gorm:"<-:false;not null;default:0;index"`
+	// Copies counts the book's copies and AvailableCopies the ones not out
+	// on loan; Available is whether there is any. They are maintained by
+	// the copy and loan writes and never written when a book is saved,
+	// except that Copies sets how many copies a new book starts with.
+	Copies          int  `json:"copies" gorm:"<-:false;not null;default:0"`
+	AvailableCopies int  `json:"available_copies" gorm:"<-:false;not null;default:0"`
+	Available       bool `json:"available" gorm:"<-:false;not null;default:true;index"`
+	// HasSample is maintained by the sample writes and tells clients a
+	// public excerpt can be fetched from /books/:id/sample
+	HasSample bool `json:"has_sample" gorm:"<-:false;not null;default:false"`
+	// Source and ImportBatchID record where the book came from. They are
+	// set when the book is created and never changed afterwards.
+	Source        BookSource `json:"source" gorm:"<-:create;size:16;not null;default:manual;index"`
+	ImportBatchID *uint      `json:"import_batch_id,omitempty" gorm:"<-:create;index"`
+	// AverageRating and RatingCount are maintained by the review writes
+	AverageRating float64 `json:"average_rating" gorm:"<-:false;not null;default:0"`
+	RatingCount   int64   `json:"rating_count" gorm:"<-:false;not null;default:0"`
+	// DescriptionGenerated marks a description written by the enrichment
+	// pipeline and approved by a reviewer. Saving the book without it, as a
+	// person editing the description would, clears it.
+	DescriptionGenerated bool `json:"description_generated" gorm:"not null;default:false"`
 }
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// BookAffinity links a book to one that the same users favorited, from the
+// periodically rebuilt co-favorite graph. Score is the cosine similarity of
+// the two books' sets of fans, so books favorited by everyone do not relate
+// to everything.
+type BookAffinity struct {
+	BookID        uint      `gorm:"primarykey;autoIncrement:false" json:"book_id"`
+	RelatedBookID uint      `gorm:"primarykey;autoIncrement:false" json:"related_book_id"`
+	CoFavorites   int       `json:"co_favorites"`
+	Score         float64   `json:"score"`
+	ComputedAt    time.Time `json:"computed_at"`
+}
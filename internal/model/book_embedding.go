@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// BookEmbedding is the vector embedding of a book's title and description
+// used by semantic search. It is recomputed when the book changes after
+// UpdatedAt or when the embedding model changes.
+type BookEmbedding struct {
+	BookID    uint      `gorm:"primarykey"`
+	Model     string    `gorm:"size:100;not null"`
+	Vector    []float32 `gorm:"serializer:json;type:mediumtext"`
+	UpdatedAt time.Time
+}
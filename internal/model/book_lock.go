@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// BookLock marks a book as being edited. Locks are advisory and expire
+// unless the holder keeps sending heartbeats.
+type BookLock struct {
+	BookID     uint      `gorm:"primarykey;autoIncrement:false" json:"book_id"`
+	HolderID   uint      `json:"holder_id"`
+	HolderName string    `gorm:"size:100" json:"holder_name,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `gorm:"index" json:"expires_at"`
+}
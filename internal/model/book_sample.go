@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// SampleFormat is the kind of file a book sample is stored as
+type SampleFormat string
+
+const (
+	SampleText SampleFormat = "text"
+	SamplePDF  SampleFormat = "pdf"
+)
+
+// ContentType is the media type the sample is served with
+func (f SampleFormat) ContentType() string {
+	if f == SamplePDF {
+		return "application/pdf"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Extension is the file name extension for the format
+func (f SampleFormat) Extension() string {
+	if f == SamplePDF {
+		return ".pdf"
+	}
+	return ".txt"
+}
+
+// SampleAccess decides who may read a book sample. It is set per sample and
+// does not depend on how the full book may be obtained.
+type SampleAccess string
+
+const (
+	// SamplePublic samples are served to anyone, including anonymous
+	// callers in public read-only mode
+	SamplePublic SampleAccess = "public"
+	// SampleHidden samples are kept but not served, e.g. while staff
+	// review them or rights are unclear
+	SampleHidden SampleAccess = "hidden"
+)
+
+func (a SampleAccess) Valid() bool {
+	return a == SamplePublic || a == SampleHidden
+}
+
+// BookSample is a first-chapter excerpt of a book. A book has at most one;
+// Book.HasSample mirrors whether a public one exists.
+type BookSample struct {
+	BookID    uint         `gorm:"primarykey" json:"book_id"`
+	Format    SampleFormat `gorm:"size:8;not null" json:"format"`
+	Access    SampleAccess `gorm:"size:16;not null" json:"access"`
+	Filename  string       `gorm:"size:255" json:"filename,omitempty"`
+	Size      int          `json:"size"`
+	Content   []byte       `gorm:"type:mediumblob" json:"-"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// BookView records the last time a user opened a book's detail page. Each
+// user keeps one row per book, capped to their most recent views.
+type BookView struct {
+	ID       uint      `gorm:"primarykey" json:"id"`
+	UserID   uint      `json:"user_id" gorm:"uniqueIndex:idx_book_view_user_book;index:idx_book_view_user_time,priority:1"`
+	BookID   uint      `json:"book_id" gorm:"uniqueIndex:idx_book_view_user_book"`
+	ViewedAt time.Time `json:"viewed_at" gorm:"index:idx_book_view_user_time,priority:2"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Category groups books by genre or subject. Names are unique ignoring
+// case, so "Fantasy" and "fantasy" are the same category; each book points
+// at its category through Book.CategoryID.
+type Category struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entities whose writes are recorded as change events
+const (
+	EntityBook     = "book"
+	EntityFavorite = "favorite"
+)
+
+// ChangeOp is the kind of write a change event records
+type ChangeOp string
+
+const (
+	ChangeOpCreate ChangeOp = "create"
+	ChangeOpUpdate ChangeOp = "update"
+	ChangeOpDelete ChangeOp = "delete"
+)
+
+// ChangeEvent is one entry of the change data capture log. It is written in
+// the same transaction as the change it describes, and Seq orders the log
+// for consumers tailing it.
+type ChangeEvent struct {
+	Seq       uint64          `gorm:"primaryKey;autoIncrement" json:"seq"`
+	Entity    string          `gorm:"size:32;index:idx_change_events_entity,priority:1" json:"entity"`
+	EntityID  uint            `gorm:"index:idx_change_events_entity,priority:2" json:"entity_id"`
+	Op        ChangeOp        `gorm:"size:16" json:"op"`
+	Payload   json.RawMessage `gorm:"type:json" json:"payload"`
+	CreatedAt time.Time       `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// ChangeRequestStatus is where a proposed edit is in review
+type ChangeRequestStatus string
+
+const (
+	ChangePending  ChangeRequestStatus = "pending"
+	ChangeApproved ChangeRequestStatus = "approved"
+	ChangeRejected ChangeRequestStatus = "rejected"
+)
+
+func (s ChangeRequestStatus) Valid() bool {
+	switch s {
+	case ChangePending, ChangeApproved, ChangeRejected:
+		return true
+	}
+	return false
+}
+
+// BookChanges holds the fields an edit proposes; nil fields stay as they are
+type BookChanges struct {
+	Title         *string `json:"title,omitempty"`
+	Author        *string `json:"author,omitempty"`
+	Category      *string `json:"category,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	PublishedYear *int    `json:"published_year,omitempty"`
+	Pages         *int    `json:"pages,omitempty"`
+	CoverURL      *string `json:"cover_url,omitempty"`
+}
+
+// ChangeRequest is a contributor's proposed edit to a book, applied only
+// once a reviewer approves it. The enrichment pipeline and the catalog
+// refresh queue edits here as well, with SubmittedBy 0.
+type ChangeRequest struct {
+	ID            uint                `gorm:"primarykey" json:"id"`
+	BookID        uint                `gorm:"index" json:"book_id"`
+	SubmittedBy   uint                `gorm:"index" json:"submitted_by"`
+	Changes       BookChanges         `gorm:"serializer:json;type:text" json:"changes"`
+	Comment       string              `gorm:"size:500" json:"comment,omitempty"`
+	Status        ChangeRequestStatus `gorm:"size:16;index" json:"status"`
+	ReviewedBy    *uint               `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time          `json:"reviewed_at,omitempty"`
+	ReviewComment string              `gorm:"size:500" json:"review_comment,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	// MachineGenerated requests were written by the enrichment steps named
+	// in Source, or by the catalog refresh when Source is catalog_refresh,
+	// rather than by a person. Keywords are suggested alongside
+	// the description for reviewers; books have no tags to apply them to.
+	MachineGenerated bool     `gorm:"not null;default:false;index" json:"machine_generated"`
+	Source           string   `gorm:"size:100" json:"source,omitempty"`
+	Keywords         []string `gorm:"serializer:json;type:text" json:"keywords,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// ClientBlock bars a client from the API. Client is an identifier as used
+// for rate limiting, such as "ip:203.0.113.7" or "key:<digest>". A block
+// without ExpiresAt lasts until it is removed.
+type ClientBlock struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	Client    string     `gorm:"size:128;uniqueIndex" json:"client"`
+	Reason    string     `gorm:"size:255" json:"reason"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Active reports whether the block still applies at now
+func (b ClientBlock) Active(now time.Time) bool {
+	return b.ExpiresAt == nil || now.Before(*b.ExpiresAt)
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Collection is a named reading list a user keeps, such as "To Read".
+// Names are unique per user ignoring case; books are added through
+// CollectionBook.
+type Collection struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_collections_user_name" json:"user_id"`
+	Name        string    `gorm:"size:100;not null;uniqueIndex:idx_collections_user_name" json:"name"`
+	Description string    `gorm:"size:500" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CollectionBook puts a book on a collection; CreatedAt orders the list
+type CollectionBook struct {
+	CollectionID uint        `gorm:"primaryKey" json:"collection_id"`
+	BookID       uint        `gorm:"primaryKey;index" json:"book_id"`
+	CreatedAt    time.Time   `json:"created_at"`
+	Collection   *Collection `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CopyCondition is the physical state of a copy, as last assessed by staff
+type CopyCondition string
+
+const (
+	ConditionGood CopyCondition = "good"
+	ConditionFair CopyCondition = "fair"
+	// ConditionPoor copies still circulate but are due for replacement
+	ConditionPoor CopyCondition = "poor"
+	// ConditionDamaged copies are taken out of circulation for repair
+	ConditionDamaged CopyCondition = "damaged"
+	// ConditionUnusable copies are beyond repair and are withdrawn
+	ConditionUnusable CopyCondition = "unusable"
+)
+
+func (c CopyCondition) Valid() bool {
+	switch c {
+	case ConditionGood, ConditionFair, ConditionPoor, ConditionDamaged, ConditionUnusable:
+		return true
+	}
+	return false
+}
+
+// Status is the status a copy in condition c gets: damaged copies go in for
+// repair, unusable ones are withdrawn and the rest circulate
+func (c CopyCondition) Status() CopyStatus {
+	switch c {
+	case ConditionDamaged:
+		return CopyInRepair
+	case ConditionUnusable:
+		return CopyWithdrawn
+	}
+	return CopyInCirculation
+}
+
+// CopyStatus is whether a copy can be lent
+type CopyStatus string
+
+const (
+	CopyInCirculation CopyStatus = "in_circulation"
+	// CopyInRepair copies are kept but not lent until they are back in a
+	// usable condition
+	CopyInRepair CopyStatus = "in_repair"
+	// CopyWithdrawn copies are soft-deleted
+	CopyWithdrawn CopyStatus = "withdrawn"
+)
+
+// Copy is one physical copy of a book. Loans lend a copy, so a book can be
+// lent to as many users at once as it has copies in circulation. Withdrawn
+// copies are soft-deleted so their loans and damage reports keep pointing
+// at them.
+type Copy struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	BookID    uint           `gorm:"index" json:"book_id"`
+	Condition CopyCondition  `gorm:"size:16;not null;default:good" json:"condition"`
+	Status    CopyStatus     `gorm:"size:16;not null;default:in_circulation;index" json:"status"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// DamageReport records damage found on a copy and the condition it was
+// assessed in, which the copy was put in
+type DamageReport struct {
+	ID          uint          `gorm:"primarykey" json:"id"`
+	CopyID      uint          `gorm:"not null;index" json:"copy_id"`
+	BookID      uint          `gorm:"not null;index" json:"book_id"`
+	ReportedBy  uint          `json:"reported_by"`
+	Condition   CopyCondition `gorm:"size:16;not null" json:"condition"`
+	Description string        `gorm:"type:text" json:"description,omitempty"`
+	Photos      []DamagePhoto `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"photos"`
+	CreatedAt   time.Time     `gorm:"index" json:"created_at"`
+}
+
+// DamagePhoto is a picture of the damage in a report, served from
+// /books/:id/copies/:copyId/damage-reports/:reportId/photos/:photoId
+type DamagePhoto struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	ReportID    uint      `gorm:"not null;index" json:"report_id"`
+	Filename    string    `gorm:"size:255" json:"filename,omitempty"`
+	ContentType string    `gorm:"size:64;not null" json:"content_type"`
+	Size        int       `json:"size"`
+	Content     []byte    `gorm:"type:mediumblob" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// PushProvider is the service that delivers push notifications to an app
+type PushProvider string
+
+const (
+	PushFCM  PushProvider = "fcm"
+	PushAPNs PushProvider = "apns"
+)
+
+func (p PushProvider) Valid() bool {
+	switch p {
+	case PushFCM, PushAPNs:
+		return true
+	}
+	return false
+}
+
+// Device is an app installation registered for push notifications. The
+// push token is stored encrypted, with a hash to look it up by. An empty
+// Events list receives every event.
+type Device struct {
+	ID         uint                `gorm:"primarykey" json:"id"`
+	UserID     uint                `gorm:"index" json:"user_id"`
+	Provider   PushProvider        `gorm:"size:16" json:"provider"`
+	Platform   string              `gorm:"size:16" json:"platform"`
+	Name       string              `gorm:"size:100" json:"name"`
+	AppVersion string              `gorm:"size:32" json:"app_version"`
+	Token      string              `gorm:"type:text;serializer:encrypted" json:"-"`
+	TokenHash  string              `gorm:"size:64;uniqueIndex" json:"-"`
+	Events     []NotificationEvent `gorm:"serializer:json;type:text" json:"events"`
+	CreatedAt  time.Time           `json:"created_at"`
+	LastSeenAt time.Time           `json:"last_seen_at"`
+}
+
+// Wants reports whether the device receives event
+func (d Device) Wants(event NotificationEvent) bool {
+	if len(d.Events) == 0 {
+		return true
+	}
+	for _, e := range d.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// AbuseFlag is a client the detector saw behaving abnormally. Counts are
+// those of the window that raised the flag, or a later one if higher.
+type AbuseFlag struct {
+	Client        string    `json:"client"`
+	Reason        string    `json:"reason"`
+	Requests      int       `json:"requests"`
+	DistinctBooks int       `json:"distinct_books"`
+	FlaggedAt     time.Time `json:"flagged_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+	Blocked       bool      `json:"blocked"`
+}
+
+// BlockClientRequest blocks a client, for Duration when given (e.g. "24h")
+// or until unblocked
+type BlockClientRequest struct {
+	Client   string `json:"client" binding:"required,max=128"`
+	Reason   string `json:"reason" binding:"max=255"`
+	Duration string `json:"duration"`
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"bms-go/internal/model"
+	"time"
+)
+
+type ExportedFavorite struct {
+	BookID    uint      `json:"book_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportedView struct {
+	BookID   uint      `json:"book_id"`
+	ViewedAt time.Time `json:"viewed_at"`
+}
+
+type ExportedUsage struct {
+	Period string `json:"period"`
+	Count  int64  `json:"count"`
+}
+
+// ExportedChannel is a notification channel with the address notifications
+// are sent to
+type ExportedChannel struct {
+	Channel   model.NotificationChannel `json:"channel"`
+	Target    string                    `json:"target"`
+	Events    []model.NotificationEvent `json:"events"`
+	Enabled   bool                      `json:"enabled"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+type ExportedCollection struct {
+	model.Collection
+	BookIDs []uint `json:"book_ids"`
+}
+
+// DataExport is everything stored about a user
+type DataExport struct {
+	UserID     uint      `json:"user_id"`
+	ExportedAt time.Time `json:"exported_at"`
+	// Account is omitted when the user has no account row
+	Account              *model.User                    `json:"account,omitempty"`
+	Privacy              PrivacySettingsResponse        `json:"privacy"`
+	Favorites            []ExportedFavorite             `json:"favorites"`
+	BookViews            []ExportedView                 `json:"book_views"`
+	Reviews              []model.Review                 `json:"reviews"`
+	ReadingStatuses      []model.ReadingStatus          `json:"reading_statuses"`
+	Loans                []model.Loan                   `json:"loans"`
+	Reservations         []model.Reservation            `json:"reservations"`
+	Collections          []ExportedCollection           `json:"collections"`
+	NotificationChannels []ExportedChannel              `json:"notification_channels"`
+	Devices              []model.Device                 `json:"devices"`
+	Notifications        []model.InboxNotification      `json:"notifications"`
+	RSVPs                []model.EventRSVP              `json:"rsvps"`
+	ILLRequests          []model.ILLRequest             `json:"ill_requests"`
+	Organizations        []model.OrganizationMember     `json:"organizations"`
+	Invitations          []model.OrganizationInvitation `json:"invitations"`
+	APIUsage             []ExportedUsage                `json:"api_usage"`
+	// MonthlyQuota is omitted when the user has the default quota
+	MonthlyQuota *int64                   `json:"monthly_quota,omitempty"`
+	Deletion     *AccountDeletionResponse `json:"deletion,omitempty"`
+}
+
+type AccountDeletionResponse struct {
+	ID           uint       `json:"id"`
+	UserID       uint       `json:"user_id"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	Favorites    int64      `json:"favorites"`
+	BookViews    int64      `json:"book_views"`
+}
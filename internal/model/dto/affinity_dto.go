@@ -0,0 +1,43 @@
+package dto
+
+import "bms-go/internal/model"
+
+// CoFavoriteCount is how many users favorited both books of a pair
+type CoFavoriteCount struct {
+	BookID        uint
+	RelatedBookID uint
+	Users         int
+}
+
+// Recommendation is a book with its summed affinity to a user's favorites
+type Recommendation struct {
+	BookID uint
+	Score  float64
+}
+
+// AffinityResult is the outcome of one co-favorite graph rebuild
+type AffinityResult struct {
+	// Books is how many books have related books
+	Books int `json:"books"`
+	// Links is how many book-to-book links were stored
+	Links int `json:"links"`
+}
+
+// RelatedBook is a book favorited by the same users as the requested one
+type RelatedBook struct {
+	model.Book
+	Score       float64 `json:"score"`
+	CoFavorites int     `json:"co_favorites"`
+}
+
+type RelatedBooksResponse struct {
+	BookID uint          `json:"book_id"`
+	Data   []RelatedBook `json:"data"`
+}
+
+// RecommendedBook is a book the user has not favorited that is related to
+// the ones they have
+type RecommendedBook struct {
+	model.Book
+	Score float64 `json:"score"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// ArchiveResult counts the rows one archival run moved to the archive tables
+type ArchiveResult struct {
+	Cutoff               time.Time `json:"cutoff"`
+	ChangeEvents         int64     `json:"change_events"`
+	ImpersonationActions int64     `json:"impersonation_actions"`
+	RanAt                time.Time `json:"ran_at"`
+}
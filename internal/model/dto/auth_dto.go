@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Name     string `json:"name" binding:"max=100"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// LoginRequest signs a user in. NewPassword replaces the password in the
+// same step and is required when an administrator asked for a reset.
+type LoginRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required,max=72"`
+	NewPassword string `json:"new_password" binding:"max=72"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=72"`
+	NewPassword     string `json:"new_password" binding:"required,max=72"`
+}
+
+// TokenResponse carries an access token to send as
+// "Authorization: Bearer <token>"
+type TokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserID      uint      `json:"user_id"`
+}
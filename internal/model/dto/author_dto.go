@@ -0,0 +1,29 @@
+package dto
+
+import "bms-go/internal/model"
+
+// AuthorRequest creates or renames an author
+type AuthorRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+// AuthorResponse is an author with their number of books
+type AuthorResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Books int64  `json:"books"`
+}
+
+type AuthorBookListMeta struct {
+	Count  int   `json:"count"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// AuthorBookListResponse is a page of one author's books
+type AuthorBookListResponse struct {
+	Author model.Author       `json:"author"`
+	Data   []model.Book       `json:"data"`
+	Meta   AuthorBookListMeta `json:"meta"`
+}
@@ -1,14 +1,445 @@
 package dto
 
+import (
+	"bms-go/internal/model"
+	"fmt"
+	"strconv"
+)
+
 type BookRequest struct {
-	Title    string `json:"title" binding:"required"`
-	Author   string `json:"author" binding:"required"`
-	Category string `json:"category" binding:"required"`
+	Title         string `json:"title" binding:"required"`
+	Author        string `json:"author" binding:"required"`
+	Category      string `json:"category" binding:"required"`
+	Description   string `json:"description"`
+	PublishedYear int    `json:"published_year"`
+	Pages         int    `json:"pages"`
+	CoverURL      string `json:"cover_url"`
+}
+
+// BookPatchRequest changes some of a book's fields. Fields left out, or
+// null, keep their current value. category_id takes precedence over
+// category, and authors over author, as when a whole book is written.
+type BookPatchRequest struct {
+	Title           *string              `json:"title"`
+	Author          *string              `json:"author"`
+	Authors         []model.Author       `json:"authors"`
+	Category        *string              `json:"category"`
+	CategoryID      *uint                `json:"category_id"`
+	Description     *string              `json:"description"`
+	PublishedYear   *int                 `json:"published_year"`
+	Pages           *int                 `json:"pages"`
+	CoverURL        *string              `json:"cover_url"`
+	ContentRating   *model.ContentRating `json:"content_rating"`
+	MediaType       *model.MediaType     `json:"media_type"`
+	Narrator        *string              `json:"narrator"`
+	DurationMinutes *int                 `json:"duration_minutes"`
+	Accessibility   *model.Accessibility `json:"accessibility"`
+}
+
+// Apply writes the fields set in p over book and reports whether any of
+// them changed it. A new category or author name replaces the book's
+// category or authors, and a new description is no longer the generated
+// one.
+func (p BookPatchRequest) Apply(book *model.Book) bool {
+	changed := false
+	if p.Title != nil && *p.Title != book.Title {
+		book.Title, changed = *p.Title, true
+	}
+	if p.Author != nil && *p.Author != book.Author {
+		book.Author, book.Authors, changed = *p.Author, nil, true
+	}
+	if p.Authors != nil && !sameAuthors(p.Authors, book.Authors) {
+		book.Authors, changed = p.Authors, true
+	}
+	if p.Category != nil && *p.Category != book.Category {
+		book.Category, book.CategoryID, changed = *p.Category, nil, true
+	}
+	if p.CategoryID != nil && (book.CategoryID == nil || *p.CategoryID != *book.CategoryID) {
+		book.CategoryID, changed = p.CategoryID, true
+	}
+	if p.Description != nil && (*p.Description != book.Description || book.DescriptionGenerated) {
+		book.Description, book.DescriptionGenerated, changed = *p.Description, false, true
+	}
+	if p.PublishedYear != nil && *p.PublishedYear != book.PublishedYear {
+		book.PublishedYear, changed = *p.PublishedYear, true
+	}
+	if p.Pages != nil && *p.Pages != book.Pages {
+		book.Pages, changed = *p.Pages, true
+	}
+	if p.CoverURL != nil && *p.CoverURL != book.CoverURL {
+		book.CoverURL, changed = *p.CoverURL, true
+	}
+	if p.ContentRating != nil && *p.ContentRating != book.ContentRating {
+		book.ContentRating, changed = *p.ContentRating, true
+	}
+	if p.MediaType != nil && *p.MediaType != book.MediaType {
+		book.MediaType, changed = *p.MediaType, true
+	}
+	if p.Narrator != nil && *p.Narrator != book.Narrator {
+		book.Narrator, changed = *p.Narrator, true
+	}
+	if p.DurationMinutes != nil && *p.DurationMinutes != book.DurationMinutes {
+		book.DurationMinutes, changed = *p.DurationMinutes, true
+	}
+	if p.Accessibility != nil && *p.Accessibility != book.Accessibility {
+		book.Accessibility, changed = *p.Accessibility, true
+	}
+	return changed
+}
+
+// sameAuthors reports whether sent names the authors of a book in order,
+// each by its id or, when it has none, by its name
+func sameAuthors(sent, current []model.Author) bool {
+	if len(sent) != len(current) {
+		return false
+	}
+	for i, author := range sent {
+		if author.ID != 0 && author.ID != current[i].ID {
+			return false
+		}
+		if author.ID == 0 && author.Name != current[i].Name {
+			return false
+		}
+	}
+	return true
 }
 
 type BookResponse struct {
-	ID       uint   `json:"id"`
-	Title    string `json:"title"`
-	Author   string `json:"author"`
+	ID              uint                `json:"id"`
+	Title           string              `json:"title"`
+	Author          string              `json:"author"`
+	Category        string              `json:"category"`
+	Description     string              `json:"description,omitempty"`
+	PublishedYear   int                 `json:"published_year,omitempty"`
+	Pages           int                 `json:"pages,omitempty"`
+	CoverURL        string              `json:"cover_url,omitempty"`
+	ContentRating   model.ContentRating `json:"content_rating"`
+	MediaType       model.MediaType     `json:"media_type"`
+	Narrator        string              `json:"narrator,omitempty"`
+	DurationMinutes int                 `json:"duration_minutes,omitempty"`
+	ReadingMinutes  int                 `json:"reading_minutes,omitempty"`
+	Accessibility   model.Accessibility `json:"accessibility"`
+	HasSample       bool                `json:"has_sample"`
+	AverageRating   float64             `json:"average_rating"`
+	RatingCount     int64               `json:"rating_count"`
+	// DescriptionGenerated marks a reviewed, machine generated description
+	DescriptionGenerated bool `json:"description_generated,omitempty"`
+}
+
+// BookComparison lays out the requested books side by side. IDs that do not
+// match a book are listed in MissingIDs instead of failing the request.
+type BookComparison struct {
+	Books       []BookResponse    `json:"books"`
+	MissingIDs  []uint            `json:"missing_ids"`
+	Differences []FieldDifference `json:"differences"`
+}
+
+// FieldDifference lists each compared book's value for a field whose values
+// are not all the same. Spread is max minus min for numeric fields.
+type FieldDifference struct {
+	Field  string               `json:"field"`
+	Values map[uint]interface{} `json:"values"`
+	Spread *int                 `json:"spread,omitempty"`
+}
+
+// BookSortField is a sortable book attribute accepted by the list endpoint
+type BookSortField string
+
+const (
+	SortByID         BookSortField = "id"
+	SortByTitle      BookSortField = "title"
+	SortByAuthor     BookSortField = "author"
+	SortByCategory   BookSortField = "category"
+	SortByCreatedAt  BookSortField = "created_at"
+	SortByRelevance  BookSortField = "relevance"
+	SortByPopularity BookSortField = "popularity"
+)
+
+// BookSortFields lists every accepted sort field in display order
+var BookSortFields = []BookSortField{SortByID, SortByTitle, SortByAuthor, SortByCategory, SortByCreatedAt, SortByRelevance, SortByPopularity}
+
+// Valid reports whether f is one of BookSortFields
+func (f BookSortField) Valid() bool {
+	for _, field := range BookSortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// SortOrder is the direction of a sort
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// Valid reports whether o is asc or desc
+func (o SortOrder) Valid() bool {
+	return o == SortAsc || o == SortDesc
+}
+
+// SearchField is an optional long-form field that search can be extended to
+type SearchField string
+
+const (
+	SearchFieldDescription SearchField = "description"
+	// SearchFieldReviews searches the text of the book's reviews
+	SearchFieldReviews SearchField = "reviews"
+)
+
+// SearchFields lists every field accepted by include_fields
+var SearchFields = []SearchField{SearchFieldDescription, SearchFieldReviews}
+
+// Valid reports whether f is one of SearchFields
+func (f SearchField) Valid() bool {
+	for _, field := range SearchFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchType chooses how a search matches books
+type SearchType string
+
+const (
+	// SearchKeyword matches the search words against the book fields
+	SearchKeyword SearchType = "keyword"
+	// SearchSemantic also ranks books by how close their title and
+	// description are in meaning to the search, using embeddings
+	SearchSemantic SearchType = "semantic"
+)
+
+func (t SearchType) Valid() bool {
+	return t == SearchKeyword || t == SearchSemantic
+}
+
+// BookQuery holds the validated filter, pagination and sort options for listing books
+type BookQuery struct {
+	Search string
+	// SearchVariants holds Search and its synonym rewrites, filled in by the service
+	SearchVariants []string
+	IncludeFields  []SearchField
+	// Weights tunes relevance ordering, filled in by the service from config
+	Weights RelevanceWeights
+	// SearchType is keyword unless semantic search was requested
+	SearchType SearchType
+	// Explain asks for each result's relevance score breakdown
+	Explain  bool
+	Category string
+	Author   string
+	// Tags limits results to books with every listed tag, ignoring case
+	Tags []string
+	// ContentRating limits results to books with this rating when set
+	ContentRating model.ContentRating
+	// MediaType limits results to one media type when set
+	MediaType model.MediaType
+	// Accessibility limits results to books available with every listed
+	// feature
+	Accessibility []model.AccessibilityFeature
+	// Source and ImportBatchID limit results to books added that way, or
+	// by that import, when set
+	Source        model.BookSource
+	ImportBatchID uint
+	Limit         int
+	Offset        int
+	SortBy        BookSortField
+	SortOrder     SortOrder
+	// IDs limits results to these books when set
+	IDs []uint
+	// RankingOverrides replace search weights by name for requests in a
+	// search ranking experiment variant
+	RankingOverrides map[string]string
+}
+
+// Includes reports whether field was requested through include_fields
+func (q BookQuery) Includes(field SearchField) bool {
+	for _, f := range q.IncludeFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// BookListMeta describes the page of books returned by the list endpoint
+type BookListMeta struct {
+	Count      int    `json:"count"`
+	Limit      int    `json:"limit,omitempty"`
+	Offset     int    `json:"offset"`
+	DidYouMean string `json:"did_you_mean,omitempty"`
+}
+
+type BookListResponse struct {
+	Data   []model.Book `json:"data"`
+	Meta   BookListMeta `json:"meta"`
+	Scores []BookScore  `json:"scores,omitempty"`
+}
+
+// RelevanceWeights are the multipliers applied to each relevance signal
+type RelevanceWeights struct {
+	ExactTitle    float64 `mapstructure:"exact_title"`
+	TitlePrefix   float64 `mapstructure:"title_prefix"`
+	TitleContains float64 `mapstructure:"title_contains"`
+	Author        float64 `mapstructure:"author"`
+	Description   float64 `mapstructure:"description"`
+	Reviews       float64 `mapstructure:"reviews"`
+	Category      float64 `mapstructure:"category"`
+	// Popularity is applied per favorite the book has received
+	Popularity float64 `mapstructure:"popularity"`
+	// Semantic is the share, from 0 to 1, of a semantic search's score
+	// that comes from embedding similarity; the rest is keyword relevance
+	Semantic float64 `mapstructure:"semantic"`
+}
+
+// Override returns the weights with the named ones replaced by the values
+// in params, as given by a search ranking experiment variant. Names are the
+// config keys, e.g. title_prefix.
+func (w RelevanceWeights) Override(params map[string]string) (RelevanceWeights, error) {
+	fields := map[string]*float64{
+		"exact_title":    &w.ExactTitle,
+		"title_prefix":   &w.TitlePrefix,
+		"title_contains": &w.TitleContains,
+		"author":         &w.Author,
+		"description":    &w.Description,
+		"reviews":        &w.Reviews,
+		"category":       &w.Category,
+		"popularity":     &w.Popularity,
+		"semantic":       &w.Semantic,
+	}
+	for name, raw := range params {
+		field, ok := fields[name]
+		if !ok {
+			return w, fmt.Errorf("unknown search weight %q", name)
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return w, fmt.Errorf("search weight %q must be a non-negative number", name)
+		}
+		*field = v
+	}
+	return w, nil
+}
+
+// BookScore is the relevance breakdown of one search result, returned when
+// explain is requested
+type BookScore struct {
+	BookID     uint               `json:"book_id"`
+	Total      float64            `json:"total"`
+	Components map[string]float64 `json:"components"`
+}
+
+// SemanticMatch is a book's similarity in meaning to a search, from 0 to 1
+type SemanticMatch struct {
+	BookID     uint
+	Similarity float64
+}
+
+// FacetCount is a distinct attribute value with the number of books having it
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CategoryRenameRequest moves every book of a category to another name
+type CategoryRenameRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// CategoryMergeRequest moves every book of the source categories into the
+// target category, which may already exist
+type CategoryMergeRequest struct {
+	Sources []string `json:"sources" binding:"required,min=1,max=50,dive,required"`
+	Target  string   `json:"target" binding:"required"`
+}
+
+// CategoryChangeResult reports how many books a rename or merge moved
+type CategoryChangeResult struct {
 	Category string `json:"category"`
+	Books    int64  `json:"books"`
+}
+
+// ReadingSpeed turns a page count into an estimated reading time
+type ReadingSpeed struct {
+	WordsPerPage   int `mapstructure:"words_per_page"`
+	WordsPerMinute int `mapstructure:"words_per_minute"`
+}
+
+// DefaultReadingSpeed is used until the reading section is loaded
+var DefaultReadingSpeed = ReadingSpeed{WordsPerPage: 250, WordsPerMinute: 238}
+
+// Minutes estimates how long the book takes to get through, rounded up to
+// the minute. Audiobooks use their running time; books without a page
+// count give 0.
+func (s ReadingSpeed) Minutes(book model.Book) int {
+	if book.MediaType == model.MediaAudiobook && book.DurationMinutes > 0 {
+		return book.DurationMinutes
+	}
+	if book.Pages <= 0 || s.WordsPerPage <= 0 || s.WordsPerMinute <= 0 {
+		return 0
+	}
+	words := book.Pages * s.WordsPerPage
+	return (words + s.WordsPerMinute - 1) / s.WordsPerMinute
+}
+
+// SimilarityStrategy is how "more like this" finds similar books
+type SimilarityStrategy string
+
+const (
+	// SimilarityAuto uses embeddings when semantic search is on and the
+	// book has one, and content otherwise
+	SimilarityAuto SimilarityStrategy = "auto"
+	// SimilarityContent compares category, author and the words of the
+	// title and description
+	SimilarityContent SimilarityStrategy = "content"
+	// SimilarityEmbedding compares the books' embeddings
+	SimilarityEmbedding SimilarityStrategy = "embedding"
+)
+
+func (s SimilarityStrategy) Valid() bool {
+	switch s {
+	case SimilarityAuto, SimilarityContent, SimilarityEmbedding:
+		return true
+	}
+	return false
+}
+
+// SimilarityConfig tunes "more like this". Content similarity scores each
+// candidate sharing the book's category or author with the weights below,
+// the text weight being multiplied by the overlap of their title and
+// description words.
+type SimilarityConfig struct {
+	Strategy       SimilarityStrategy `mapstructure:"strategy"`
+	Candidates     int                `mapstructure:"candidates"`
+	CategoryWeight float64            `mapstructure:"category_weight"`
+	AuthorWeight   float64            `mapstructure:"author_weight"`
+	TextWeight     float64            `mapstructure:"text_weight"`
+}
+
+// SimilarBook is a book with its similarity to the requested one
+type SimilarBook struct {
+	model.Book
+	Similarity float64 `json:"similarity"`
+}
+
+type SimilarBooksResponse struct {
+	BookID uint `json:"book_id"`
+	// Strategy is the strategy actually used, never auto
+	Strategy SimilarityStrategy `json:"strategy"`
+	Data     []SimilarBook      `json:"data"`
+}
+
+// BookBulkCreateRequest lists the books to create in one go
+type BookBulkCreateRequest struct {
+	Books []model.Book `json:"books" binding:"required,min=1,max=100"`
+}
+
+// BookBulkDeleteRequest lists the books to delete in one go
+type BookBulkDeleteRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100,dive,min=1"`
 }
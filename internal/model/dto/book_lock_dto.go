@@ -0,0 +1,6 @@
+package dto
+
+type BookLockRequest struct {
+	// HolderName is shown to other editors, e.g. "currently being edited by Ana"
+	HolderName string `json:"holder_name" binding:"max=100"`
+}
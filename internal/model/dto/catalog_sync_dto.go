@@ -0,0 +1,60 @@
+package dto
+
+// SyncDirection is which way a catalog sync copies books
+type SyncDirection string
+
+const (
+	// SyncPush copies this catalog's books to the target
+	SyncPush SyncDirection = "push"
+	// SyncPull copies the target's books into this catalog, where they are
+	// kept up to date by the catalog refresh
+	SyncPull SyncDirection = "pull"
+)
+
+type CatalogSyncRequest struct {
+	TargetURL string `json:"target_url" binding:"required,url"`
+	// Direction defaults to push
+	Direction SyncDirection `json:"direction" binding:"omitempty,oneof=push pull"`
+	// TargetAPIKey is sent as X-API-Key when the target requires one
+	TargetAPIKey string `json:"target_api_key"`
+	// DryRun defaults to true so nothing is written unless asked
+	DryRun *bool `json:"dry_run"`
+}
+
+// CatalogSyncItem is a book the sync created or updated, or would have
+type CatalogSyncItem struct {
+	SourceID uint     `json:"source_id"`
+	TargetID uint     `json:"target_id,omitempty"`
+	Title    string   `json:"title"`
+	Author   string   `json:"author"`
+	Fields   []string `json:"fields,omitempty"`
+}
+
+// CatalogSyncConflict is a book the sync left alone because pushing it
+// could lose data on the target
+type CatalogSyncConflict struct {
+	SourceID  uint   `json:"source_id"`
+	TargetIDs []uint `json:"target_ids"`
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	Reason    string `json:"reason"`
+}
+
+type CatalogSyncReport struct {
+	DryRun    bool                  `json:"dry_run"`
+	Created   []CatalogSyncItem     `json:"created"`
+	Updated   []CatalogSyncItem     `json:"updated"`
+	Conflicts []CatalogSyncConflict `json:"conflicts"`
+	Unchanged int                   `json:"unchanged"`
+	Errors    []string              `json:"errors,omitempty"`
+}
+
+// CatalogRefreshResult counts what one catalog refresh run did. Checked
+// books were fetched and compared; Updated took upstream changes and Queued
+// had conflicting ones sent for review.
+type CatalogRefreshResult struct {
+	Checked int `json:"checked"`
+	Updated int `json:"updated"`
+	Queued  int `json:"queued"`
+	Failed  int `json:"failed"`
+}
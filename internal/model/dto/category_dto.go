@@ -0,0 +1,29 @@
+package dto
+
+import "bms-go/internal/model"
+
+// CategoryRequest creates or renames a category
+type CategoryRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// CategoryResponse is a category with its number of books
+type CategoryResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Books int64  `json:"books"`
+}
+
+type CategoryBookListMeta struct {
+	Count  int   `json:"count"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// CategoryBookListResponse is a page of the books in one category
+type CategoryBookListResponse struct {
+	Category model.Category       `json:"category"`
+	Data     []model.Book         `json:"data"`
+	Meta     CategoryBookListMeta `json:"meta"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"bms-go/internal/model"
+	"time"
+)
+
+// ChangeEventPage is a slice of the change log. Pass NextSeq as after_seq
+// to read on from where this page ended.
+type ChangeEventPage struct {
+	Events  []model.ChangeEvent `json:"events"`
+	NextSeq uint64              `json:"next_seq"`
+}
+
+// ChangeEventQuery filters the change log for investigation. From is
+// inclusive and To exclusive; zero values leave that end open.
+type ChangeEventQuery struct {
+	Entity    string         `form:"entity" binding:"omitempty,oneof=book favorite"`
+	EntityID  uint           `form:"entity_id"`
+	Op        model.ChangeOp `form:"op" binding:"omitempty,oneof=create update delete"`
+	From      time.Time      `form:"from"`
+	To        time.Time      `form:"to"`
+	BeforeSeq uint64         `form:"before_seq"`
+	Limit     int            `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// ChangeEventLog is a page of matching change events, newest first. Pass
+// NextBeforeSeq as before_seq to read older events; it is 0 on the last
+// page.
+type ChangeEventLog struct {
+	Events        []model.ChangeEvent `json:"events"`
+	NextBeforeSeq uint64              `json:"next_before_seq,omitempty"`
+}
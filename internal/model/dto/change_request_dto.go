@@ -0,0 +1,32 @@
+package dto
+
+import "bms-go/internal/model"
+
+type ChangeRequestSubmission struct {
+	Changes model.BookChanges `json:"changes"`
+	Comment string            `json:"comment" binding:"max=500"`
+}
+
+type ChangeReview struct {
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+// FieldChange is one field an edit would change, with the book's value at
+// review time
+type FieldChange struct {
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+}
+
+type ChangeRequestResponse struct {
+	model.ChangeRequest
+	// Diff is computed against the book as it is now
+	Diff []FieldChange `json:"diff"`
+}
+
+// EnrichmentResult is the outcome of one enrichment run
+type EnrichmentResult struct {
+	// Queued is how many machine generated descriptions were queued
+	Queued int `json:"queued"`
+}
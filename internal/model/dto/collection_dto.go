@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// CollectionRequest names a collection when it is created or changed
+type CollectionRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// CollectionBookRequest adds a book to a collection
+type CollectionBookRequest struct {
+	BookID uint `json:"book_id" binding:"required"`
+}
+
+// CollectionResponse is a collection with its number of books
+type CollectionResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Books       int64     `json:"books"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CollectionDetailResponse is a collection with its books in the order
+// they were added
+type CollectionDetailResponse struct {
+	CollectionResponse
+	Items []CollectionItemResponse `json:"items"`
+}
+
+type CollectionItemResponse struct {
+	AddedAt time.Time    `json:"added_at"`
+	Book    BookResponse `json:"book"`
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"bms-go/internal/model"
+	"time"
+)
+
+// StockRequest sets how many copies of a book the library holds
+type StockRequest struct {
+	Copies *int `json:"copies" binding:"required,min=0,max=1000"`
+}
+
+// StockResponse counts a book's copies and how many of them are out on
+// loan or in for repair
+type StockResponse struct {
+	BookID    uint `json:"book_id"`
+	Copies    int  `json:"copies"`
+	Available int  `json:"available"`
+	OnLoan    int  `json:"on_loan"`
+	InRepair  int  `json:"in_repair"`
+}
+
+// CopyResponse is one copy of a book with the loan it is out on, if any
+type CopyResponse struct {
+	ID        uint                `json:"id"`
+	Condition model.CopyCondition `json:"condition"`
+	Status    model.CopyStatus    `json:"status"`
+	LoanID    *uint               `json:"loan_id,omitempty"`
+	DueAt     *time.Time          `json:"due_at,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// CopyConditionRequest records a copy's condition after it was assessed,
+// such as once it has been repaired
+type CopyConditionRequest struct {
+	Condition model.CopyCondition `json:"condition" binding:"required"`
+}
+
+// DamageReportRequest reports damage found on a copy. Photos are uploaded
+// alongside as multipart files named photos.
+type DamageReportRequest struct {
+	Condition   model.CopyCondition `json:"condition" form:"condition" binding:"required"`
+	Description string              `json:"description" form:"description" binding:"max=5000"`
+}
+
+// DamagePhotoUpload is a photo sent with a damage report
+type DamagePhotoUpload struct {
+	Filename string
+	Content  []byte
+}
+
+// DamageReportResponse is a stored damage report and the copy it left
+type DamageReportResponse struct {
+	Report model.DamageReport `json:"report"`
+	Copy   model.Copy         `json:"copy"`
+}
+
+// ReplacementCandidate is a copy that needs replacing: one in poor
+// condition, in for repair or withdrawn as unusable. LastReportedAt is when
+// damage was last reported on it, and BookCopies how many copies the book
+// has left.
+type ReplacementCandidate struct {
+	CopyID         uint                `json:"copy_id"`
+	BookID         uint                `json:"book_id"`
+	Title          string              `json:"title"`
+	Condition      model.CopyCondition `json:"condition"`
+	Status         model.CopyStatus    `json:"status"`
+	LastReportedAt *time.Time          `json:"last_reported_at,omitempty"`
+	BookCopies     int                 `json:"book_copies"`
+}
+
+// AvailabilityRequest lists the books whose availability is wanted
+type AvailabilityRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100,dive,min=1"`
+}
+
+// BookAvailability is whether a book can be borrowed now and, if not, when
+// and behind how many others. Available counts the copies neither out on
+// loan nor held for a reservation.
+type BookAvailability struct {
+	BookID      uint       `json:"book_id"`
+	Copies      int        `json:"copies"`
+	Available   int        `json:"available"`
+	NextDueAt   *time.Time `json:"next_due_at,omitempty"`
+	QueueLength int        `json:"queue_length"`
+}
+
+// AvailabilityResponse lists the availability of the requested books in
+// the requested order. IDs that do not match a book are listed in
+// MissingIDs instead of failing the request.
+type AvailabilityResponse struct {
+	Books      []BookAvailability `json:"books"`
+	MissingIDs []uint             `json:"missing_ids"`
+}
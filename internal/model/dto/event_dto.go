@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"bms-go/internal/model"
+	"time"
+)
+
+type EventRequest struct {
+	Title       string    `json:"title" binding:"required,max=200"`
+	Description string    `json:"description"`
+	Room        string    `json:"room" binding:"required,max=100"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	Capacity    int       `json:"capacity" binding:"min=0"`
+	BookID      *uint     `json:"book_id"`
+}
+
+// EventQuery filters the public event list. From defaults to now, so only
+// events that have not ended are listed.
+type EventQuery struct {
+	From   time.Time `form:"from"`
+	BookID uint      `form:"book_id"`
+	Room   string    `form:"room"`
+	Limit  int       `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// EventResponse adds attendance to an event. SpotsLeft is omitted for
+// events without a capacity limit.
+type EventResponse struct {
+	model.Event
+	Attending  int64  `json:"attending"`
+	Waitlisted int64  `json:"waitlisted"`
+	SpotsLeft  *int64 `json:"spots_left,omitempty"`
+}
+
+// MyEventResponse is an event the user has an RSVP for
+type MyEventResponse struct {
+	EventResponse
+	RSVPStatus model.RSVPStatus `json:"rsvp_status"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "bms-go/internal/model"
+
+type ExperimentRequest struct {
+	Key         string                    `json:"key" binding:"required,max=64"`
+	Description string                    `json:"description" binding:"max=500"`
+	Variants    []model.ExperimentVariant `json:"variants" binding:"required,min=2"`
+	Enabled     bool                      `json:"enabled"`
+}
+
+// ExperimentAssignment is the variant a caller was bucketed into
+type ExperimentAssignment struct {
+	Experiment string            `json:"experiment"`
+	Variant    string            `json:"variant"`
+	Params     map[string]string `json:"params,omitempty"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+// ExportFormat is a file format the book export endpoint can produce
+type ExportFormat string
+
+const (
+	ExportPDF  ExportFormat = "pdf"
+	ExportXLSX ExportFormat = "xlsx"
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+)
+
+// ExportFormats lists every accepted export format in display order
+var ExportFormats = []ExportFormat{ExportPDF, ExportXLSX, ExportCSV, ExportJSON}
+
+// Valid reports whether f is one of ExportFormats
+func (f ExportFormat) Valid() bool {
+	for _, format := range ExportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Streamed reports whether f is written to the client as the books are
+// read, rather than rendered whole first
+func (f ExportFormat) Streamed() bool {
+	return f == ExportCSV || f == ExportJSON
+}
+
+// ExportOptions controls how an exported book list is rendered
+type ExportOptions struct {
+	Title  string
+	Covers bool
+}
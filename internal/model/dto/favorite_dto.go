@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type FavoriteRequest struct {
 	BookID uint `json:"book_id" binding:"required"`
 }
@@ -10,3 +12,16 @@ type FavoriteResponse struct {
 	BookID uint          `json:"book_id"`
 	Book   *BookResponse `json:"book,omitempty"` 
 }
+
+// GuestFavoriteResponse is a favorite kept by a visitor who has not signed in
+type GuestFavoriteResponse struct {
+	BookID  uint          `json:"book_id"`
+	AddedAt time.Time     `json:"added_at"`
+	Book    *BookResponse `json:"book,omitempty"`
+}
+
+// ClaimFavoritesResponse reports how many guest favorites were moved to the
+// account; books already among its favorites are not counted
+type ClaimFavoritesResponse struct {
+	Claimed int `json:"claimed"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "bms-go/internal/model"
+
+type ILLRequestSubmission struct {
+	Title  string `json:"title" binding:"required,max=255"`
+	Author string `json:"author" binding:"max=255"`
+	Notes  string `json:"notes" binding:"max=500"`
+}
+
+// ILLStatusUpdate moves a request on. ExternalLibrary records the lending
+// library and is kept when empty.
+type ILLStatusUpdate struct {
+	Status          model.ILLStatus `json:"status" binding:"required"`
+	Note            string          `json:"note" binding:"max=500"`
+	ExternalLibrary string          `json:"external_library" binding:"max=200"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type ImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+type ImpersonationResponse struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	IssuedBy  string     `json:"issued_by"`
+	Reason    string     `json:"reason"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// Token is only returned when the session is started
+	Token string `json:"token,omitempty"`
+}
@@ -0,0 +1,108 @@
+package dto
+
+type BookImportRequest struct {
+	Books []BookRequest `json:"books" binding:"required,min=1,max=1000"`
+}
+
+// ImportAction is what an import did, or would do, with one row
+type ImportAction string
+
+const (
+	ImportCreate ImportAction = "create"
+	ImportUpdate ImportAction = "update"
+	ImportSkip   ImportAction = "skip"
+)
+
+// DuplicatePolicy decides what an import does with a row that matches an
+// existing book or an earlier row
+type DuplicatePolicy string
+
+const (
+	// DuplicateSkip leaves the existing book alone
+	DuplicateSkip DuplicatePolicy = "skip"
+	// DuplicateUpdate copies the row's non-empty fields onto the existing book
+	DuplicateUpdate DuplicatePolicy = "update"
+	// DuplicateCreate adds the row as another book regardless
+	DuplicateCreate DuplicatePolicy = "create"
+)
+
+func (p DuplicatePolicy) Valid() bool {
+	switch p {
+	case DuplicateSkip, DuplicateUpdate, DuplicateCreate:
+		return true
+	}
+	return false
+}
+
+type ImportRowResult struct {
+	// Row is the 1-based position of the row in the import
+	Row    int          `json:"row"`
+	Action ImportAction `json:"action"`
+	Reason string       `json:"reason"`
+	BookID uint         `json:"book_id,omitempty"`
+	// Fields lists what an update changed
+	Fields []string `json:"fields,omitempty"`
+	Title  string   `json:"title"`
+	Author string   `json:"author"`
+}
+
+type ImportReport struct {
+	// BatchID identifies the import for filtering and rollback; dry runs
+	// have none
+	BatchID     uint              `json:"batch_id,omitempty"`
+	DryRun      bool              `json:"dry_run"`
+	OnDuplicate DuplicatePolicy   `json:"on_duplicate"`
+	Created     int               `json:"created"`
+	Updated     int               `json:"updated"`
+	Skipped     int               `json:"skipped"`
+	Rows        []ImportRowResult `json:"rows"`
+}
+
+// ImportOrigin is who ran an import and what it was read from, recorded
+// on its batch
+type ImportOrigin struct {
+	// UserID is 0 when the import was not run by a signed-in user
+	UserID uint
+	// Format is json for a request body, otherwise the file format
+	Format   string
+	Filename string
+}
+
+// ImportRollbackSkip is a book a rollback left alone, and why
+type ImportRollbackSkip struct {
+	BookID uint   `json:"book_id"`
+	Reason string `json:"reason"`
+}
+
+// ImportRollbackReport says what rolling back an import batch undid
+type ImportRollbackReport struct {
+	BatchID  uint                 `json:"batch_id"`
+	Deleted  int                  `json:"deleted"`
+	Restored int                  `json:"restored"`
+	Skipped  []ImportRollbackSkip `json:"skipped"`
+}
+
+// ImportFileFormat is a spreadsheet format accepted as an import upload
+type ImportFileFormat string
+
+const (
+	ImportCSV  ImportFileFormat = "csv"
+	ImportXLSX ImportFileFormat = "xlsx"
+)
+
+func (f ImportFileFormat) Valid() bool {
+	switch f {
+	case ImportCSV, ImportXLSX:
+		return true
+	}
+	return false
+}
+
+// ImportFileOptions says where the book rows are in an uploaded file
+type ImportFileOptions struct {
+	// Sheet is the XLSX worksheet to read, the first one when empty
+	Sheet string
+	// Columns maps a book field to the header it has in the file, for
+	// files whose headers are not the field names
+	Columns map[string]string
+}
@@ -0,0 +1,8 @@
+package dto
+
+type ShortLinkResponse struct {
+	Code     string       `json:"code"`
+	ShortURL string       `json:"short_url"`
+	URL      string       `json:"url"`
+	Book     BookResponse `json:"book"`
+}
@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"bms-go/internal/model"
+	"time"
+)
+
+// LoanStatus filters loans by where they are
+type LoanStatus string
+
+const (
+	// LoanActive loans are still out, overdue or not
+	LoanActive   LoanStatus = "active"
+	LoanOverdue  LoanStatus = "overdue"
+	LoanReturned LoanStatus = "returned"
+)
+
+func (s LoanStatus) Valid() bool {
+	return s == LoanActive || s == LoanOverdue || s == LoanReturned
+}
+
+// KioskCheckoutRequest is a self-checkout: the book scanned at a kiosk and
+// the library card of the patron borrowing it
+type KioskCheckoutRequest struct {
+	CardNumber string `json:"card_number" binding:"required,max=32"`
+	BookID     uint   `json:"book_id" binding:"required,min=1"`
+}
+
+// LoanQuery filters a list of loans. A zero UserID or BookID matches any.
+type LoanQuery struct {
+	UserID uint
+	BookID uint
+	Status LoanStatus
+	Limit  int
+	Offset int
+}
+
+type LoanListMeta struct {
+	Count  int   `json:"count"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+type LoanListResponse struct {
+	Data []model.Loan `json:"data"`
+	Meta LoanListMeta `json:"meta"`
+}
+
+// LoanHistoryQuery filters a patron's loan history. From and To are days;
+// loans borrowed on either day are included, and a zero one leaves that
+// end open.
+type LoanHistoryQuery struct {
+	Format ExportFormat `form:"format" binding:"omitempty,oneof=csv json"`
+	From   time.Time    `form:"from" time_format:"2006-01-02"`
+	To     time.Time    `form:"to" time_format:"2006-01-02"`
+}
+
+// LoanHistoryEntry is one loan in a patron's history
+type LoanHistoryEntry struct {
+	LoanID     uint       `json:"loan_id"`
+	BookID     uint       `json:"book_id"`
+	Title      string     `json:"title"`
+	Author     string     `json:"author"`
+	BorrowedAt time.Time  `json:"borrowed_at"`
+	DueAt      time.Time  `json:"due_at"`
+	ReturnedAt *time.Time `json:"returned_at,omitempty"`
+}
+
+// ReturnedOnTime reports whether the loan came back by its due date
+func (e LoanHistoryEntry) ReturnedOnTime() bool {
+	return e.ReturnedAt != nil && !e.ReturnedAt.After(e.DueAt)
+}
+
+// LoanHistoryTotals sums up a loan history. BooksBorrowed counts distinct
+// books; OnTimeRate is the share of returned loans that came back by their
+// due date, 0 when none were returned.
+type LoanHistoryTotals struct {
+	Loans          int     `json:"loans"`
+	BooksBorrowed  int     `json:"books_borrowed"`
+	Returned       int     `json:"returned"`
+	ReturnedOnTime int     `json:"returned_on_time"`
+	OnTimeRate     float64 `json:"on_time_rate"`
+}
+
+// LoanHistoryResponse is a patron's loans, newest first, with their totals.
+// HistoryLimited is set when the patron opted out of keeping their loan
+// history, so older returned loans are no longer on record.
+type LoanHistoryResponse struct {
+	HistoryLimited bool               `json:"history_limited"`
+	Totals         LoanHistoryTotals  `json:"totals"`
+	Loans          []LoanHistoryEntry `json:"loans"`
+}
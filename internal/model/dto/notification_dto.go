@@ -0,0 +1,46 @@
+package dto
+
+import "bms-go/internal/model"
+
+// NotificationPreferenceRequest sets up a channel for the user. Target is
+// required when the channel is first set up and kept when left empty later.
+type NotificationPreferenceRequest struct {
+	Target  string                    `json:"target"`
+	Events  []model.NotificationEvent `json:"events"`
+	Enabled *bool                     `json:"enabled"`
+}
+
+// NotificationTemplate is a text/template pair for one event. Templates see
+// the event's data, and Body may be empty.
+type NotificationTemplate struct {
+	Title string `mapstructure:"title"`
+	Body  string `mapstructure:"body"`
+}
+
+type WebPushKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// DeviceRequest registers an app installation for push notifications.
+// Registering a token again updates its device, moving it to the caller if
+// another account had it.
+type DeviceRequest struct {
+	Provider   model.PushProvider        `json:"provider" binding:"required"`
+	Token      string                    `json:"token" binding:"required"`
+	Platform   string                    `json:"platform" binding:"required,oneof=ios android"`
+	Name       string                    `json:"name" binding:"max=100"`
+	AppVersion string                    `json:"app_version" binding:"max=32"`
+	Events     []model.NotificationEvent `json:"events"`
+}
+
+// InboxPage is a page of the user's inbox, newest first. Pass NextBefore as
+// before to read older entries; it is 0 on the last page.
+type InboxPage struct {
+	Data       []model.InboxNotification `json:"data"`
+	Unread     int64                     `json:"unread"`
+	NextBefore uint                      `json:"next_before,omitempty"`
+}
+
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
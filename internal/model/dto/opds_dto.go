@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const (
+	OPDSNavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	OPDSAcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	OpenSearchType      = "application/opensearchdescription+xml"
+)
+
+// OPDSFeed is an OPDS 1.2 catalog feed, serialized as Atom
+type OPDSFeed struct {
+	XMLName   xml.Name    `xml:"feed"`
+	Xmlns     string      `xml:"xmlns,attr"`
+	XmlnsOPDS string      `xml:"xmlns:opds,attr"`
+	XmlnsDC   string      `xml:"xmlns:dc,attr"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   time.Time   `xml:"updated"`
+	Links     []OPDSLink  `xml:"link"`
+	Entries   []OPDSEntry `xml:"entry"`
+}
+
+type OPDSLink struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type OPDSEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    time.Time      `xml:"updated"`
+	Authors    []OPDSAuthor   `xml:"author,omitempty"`
+	Categories []OPDSCategory `xml:"category,omitempty"`
+	Issued     string         `xml:"dc:issued,omitempty"`
+	Content    *OPDSContent   `xml:"content,omitempty"`
+	Links      []OPDSLink     `xml:"link"`
+}
+
+type OPDSAuthor struct {
+	Name string `xml:"name"`
+}
+
+type OPDSCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type OPDSContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// OpenSearchDescription tells OPDS clients how to search the catalog
+type OpenSearchDescription struct {
+	XMLName     xml.Name      `xml:"OpenSearchDescription"`
+	Xmlns       string        `xml:"xmlns,attr"`
+	ShortName   string        `xml:"ShortName"`
+	Description string        `xml:"Description"`
+	URL         OpenSearchURL `xml:"Url"`
+}
+
+type OpenSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
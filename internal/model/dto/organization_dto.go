@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"bms-go/internal/model"
+	"time"
+)
+
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type OrganizationResponse struct {
+	ID      uint           `json:"id"`
+	Name    string         `json:"name"`
+	Role    model.OrgRole  `json:"role,omitempty"`
+	Members []OrgMemberDTO `json:"members,omitempty"`
+}
+
+type OrgMemberDTO struct {
+	UserID   uint          `json:"user_id"`
+	Role     model.OrgRole `json:"role"`
+	JoinedAt time.Time     `json:"joined_at"`
+}
+
+type InvitationRequest struct {
+	UserID uint          `json:"user_id" binding:"required"`
+	Role   model.OrgRole `json:"role" binding:"required"`
+}
+
+type MemberRoleRequest struct {
+	Role model.OrgRole `json:"role" binding:"required"`
+}
+
+type OrgFavoriteRequest struct {
+	BookID uint `json:"book_id" binding:"required"`
+}
+
+type OrgFavoriteResponse struct {
+	ID      uint          `json:"id"`
+	BookID  uint          `json:"book_id"`
+	AddedBy uint          `json:"added_by"`
+	AddedAt time.Time     `json:"added_at"`
+	Book    *BookResponse `json:"book,omitempty"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+type PartnerRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type PartnerResponse struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	KeyID  string `json:"key_id"`
+	Active bool   `json:"active"`
+	// Secret is only returned when it is created or rotated
+	Secret string `json:"secret,omitempty"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// PrivacySettingsRequest updates the settings that are present and leaves
+// the others unchanged
+type PrivacySettingsRequest struct {
+	TrackRecentlyViewed *bool `json:"track_recently_viewed"`
+	PublicProfile       *bool `json:"public_profile"`
+	ShowFavorites       *bool `json:"show_favorites"`
+	AllowAnalytics      *bool `json:"allow_analytics"`
+	MarketingEmails     *bool `json:"marketing_emails"`
+	ForgetLoanHistory   *bool `json:"forget_loan_history"`
+}
+
+type PrivacySettingsResponse struct {
+	TrackRecentlyViewed bool `json:"track_recently_viewed"`
+	PublicProfile       bool `json:"public_profile"`
+	ShowFavorites       bool `json:"show_favorites"`
+	AllowAnalytics      bool `json:"allow_analytics"`
+	MarketingEmails     bool `json:"marketing_emails"`
+	ForgetLoanHistory   bool `json:"forget_loan_history"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type QuotaRequest struct {
+	// MonthlyLimit of 0 means unlimited
+	MonthlyLimit *int64 `json:"monthly_limit" binding:"required,min=0"`
+}
+
+type QuotaResponse struct {
+	Subject      string `json:"subject"`
+	MonthlyLimit int64  `json:"monthly_limit"`
+}
+
+type UsageResponse struct {
+	Subject string `json:"subject"`
+	Period  string `json:"period"`
+	Used    int64  `json:"used"`
+	// Limit of 0 means unlimited
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// ReadingLogRow is one book read, from a reading log file. Rating is 0 when
+// the row has none.
+type ReadingLogRow struct {
+	// Line is the row's line in the file, counting the header
+	Line       int
+	ISBN       string
+	Title      string
+	Author     string
+	FinishedAt *time.Time
+	Rating     int
+	Notes      string
+}
+
+// ReadingLogUnmatched is a reading log row that was not imported, and why
+type ReadingLogUnmatched struct {
+	Line   int    `json:"line"`
+	ISBN   string `json:"isbn,omitempty"`
+	Title  string `json:"title"`
+	Author string `json:"author,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ReadingLogImportReport says what importing a reading log did. Imported
+// counts the books marked as read and Reviewed the ratings added as
+// reviews; RatingsKept counts ratings of books the user had already
+// reviewed, whose review was left as it was.
+type ReadingLogImportReport struct {
+	DryRun      bool                  `json:"dry_run"`
+	Rows        int                   `json:"rows"`
+	Imported    int                   `json:"imported"`
+	Reviewed    int                   `json:"reviewed"`
+	RatingsKept int                   `json:"ratings_kept"`
+	Unmatched   []ReadingLogUnmatched `json:"unmatched"`
+}
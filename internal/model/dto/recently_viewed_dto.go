@@ -0,0 +1,8 @@
+package dto
+
+import "time"
+
+type RecentlyViewedResponse struct {
+	Book     BookResponse `json:"book"`
+	ViewedAt time.Time    `json:"viewed_at"`
+}
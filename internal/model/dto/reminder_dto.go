@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// ReminderSettingsRequest updates the settings that are present and leaves
+// the others unchanged. An empty days_before goes back to the server's days.
+type ReminderSettingsRequest struct {
+	Enabled    *bool `json:"enabled"`
+	DaysBefore []int `json:"days_before" binding:"omitempty,max=5,dive,min=1,max=30"`
+}
+
+type ReminderSettingsResponse struct {
+	Enabled    bool  `json:"enabled"`
+	DaysBefore []int `json:"days_before"`
+}
+
+// DueLoan is a loan still out, with the title of its book
+type DueLoan struct {
+	LoanID     uint
+	UserID     uint
+	BookID     uint
+	Title      string
+	BorrowedAt time.Time
+	DueAt      time.Time
+}
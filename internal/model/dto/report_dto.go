@@ -0,0 +1,10 @@
+package dto
+
+import "bms-go/internal/model"
+
+type ReportScheduleRequest struct {
+	Name      string                `json:"name" binding:"required,max=100"`
+	Kind      model.ReportKind      `json:"kind" binding:"required"`
+	Frequency model.ReportFrequency `json:"frequency" binding:"required"`
+	Active    *bool                 `json:"active"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "bms-go/internal/model"
+
+// ReservationQuery filters a list of reservations. A zero UserID or BookID,
+// or an empty Status, matches any.
+type ReservationQuery struct {
+	UserID uint
+	BookID uint
+	Status model.ReservationStatus
+	Limit  int
+	Offset int
+}
+
+type ReservationListMeta struct {
+	Count  int   `json:"count"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+type ReservationListResponse struct {
+	Data []model.Reservation `json:"data"`
+	Meta ReservationListMeta `json:"meta"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type PurgeCandidate struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// RetentionPreview lists what the next purge would remove without removing it
+type RetentionPreview struct {
+	Cutoff    time.Time        `json:"cutoff"`
+	Books     []PurgeCandidate `json:"books"`
+	Favorites int64            `json:"favorites"`
+	BookViews int64            `json:"book_views"`
+}
+
+type PurgeResult struct {
+	Cutoff    time.Time `json:"cutoff"`
+	Books     int64     `json:"books"`
+	Favorites int64     `json:"favorites"`
+	BookViews int64     `json:"book_views"`
+	RanAt     time.Time `json:"ran_at"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "bms-go/internal/model"
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+type ReviewListMeta struct {
+	Count  int   `json:"count"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+type ReviewListResponse struct {
+	Data []model.Review `json:"data"`
+	Meta ReviewListMeta `json:"meta"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import "encoding/xml"
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapIndex lists the sitemap pages of a catalog too large for one file
+type SitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	Xmlns    string         `xml:"xmlns,attr"`
+	Sitemaps []SitemapEntry `xml:"sitemap"`
+}
+
+type SitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet is a single sitemap page
+type URLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func NewSitemapIndex() *SitemapIndex {
+	return &SitemapIndex{Xmlns: sitemapNamespace}
+}
+
+func NewURLSet() *URLSet {
+	return &URLSet{Xmlns: sitemapNamespace}
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// CatalogStats is a snapshot of the catalog's business figures, counted by
+// the periodic stats collector
+type CatalogStats struct {
+	BooksByMediaType      map[string]int64 `json:"books_by_media_type"`
+	Favorites             int64            `json:"favorites"`
+	Users                 int64            `json:"users"`
+	ILLRequestsByStatus   map[string]int64 `json:"ill_requests_by_status"`
+	RSVPsByStatus         map[string]int64 `json:"rsvps_by_status"`
+	PendingChangeRequests int64            `json:"pending_change_requests"`
+	RefreshedAt           time.Time        `json:"refreshed_at"`
+	RefreshDuration       time.Duration    `json:"refresh_duration"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type SynonymRequest struct {
+	Term    string `json:"term" binding:"required,max=100"`
+	Synonym string `json:"synonym" binding:"required,max=100"`
+}
+
+type SynonymResponse struct {
+	ID      uint   `json:"id"`
+	Term    string `json:"term"`
+	Synonym string `json:"synonym"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// TagRequest puts a tag on a book by name, creating the tag when new
+type TagRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// TagResponse is a tag with its number of books
+type TagResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Books int64  `json:"books"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// TaskStatus is where a background task is in its run
+type TaskStatus string
+
+const (
+	TaskRunning   TaskStatus = "running"
+	TaskSucceeded TaskStatus = "succeeded"
+	TaskFailed    TaskStatus = "failed"
+)
+
+// Task reports the progress of a background admin task. Done and Total
+// count the units of the current step.
+type Task struct {
+	ID         uint64     `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     TaskStatus `json:"status"`
+	Step       string     `json:"step"`
+	Done       int        `json:"done"`
+	Total      int        `json:"total"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"bms-go/internal/model"
+	"time"
+)
+
+// UserRequest creates or replaces an account's details
+type UserRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Name  string `json:"name" binding:"max=100"`
+}
+
+type UserRoleRequest struct {
+	Role model.UserRole `json:"role" binding:"required,oneof=reader librarian admin"`
+}
+
+// CardRequest issues a library card number to a user, or takes their card
+// away when empty
+type CardRequest struct {
+	CardNumber string `json:"card_number" binding:"max=32"`
+}
+
+type DisableUserRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// UserQuery filters the admin user list. Search matches a whole email, or
+// part of a name or card number.
+type UserQuery struct {
+	Search string
+	Status model.UserStatus
+	Limit  int
+	Offset int
+}
+
+type UserListMeta struct {
+	Count  int   `json:"count"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+type UserListResponse struct {
+	Data []model.User `json:"data"`
+	Meta UserListMeta `json:"meta"`
+}
+
+// UserActivity counts what is stored about a user across the catalog
+type UserActivity struct {
+	Favorites     int64 `json:"favorites"`
+	BookViews     int64 `json:"book_views"`
+	EventRSVPs    int64 `json:"event_rsvps"`
+	ILLRequests   int64 `json:"ill_requests"`
+	Organizations int64 `json:"organizations"`
+	Devices       int64 `json:"devices"`
+	Reviews       int64 `json:"reviews"`
+}
+
+// UserSummary is an account with an overview of its activity, for support
+type UserSummary struct {
+	model.User
+	Activity        UserActivity             `json:"activity"`
+	PendingDeletion *AccountDeletionResponse `json:"pending_deletion,omitempty"`
+}
+
+// UserProfile is what other users see of an account with a public profile
+type UserProfile struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccessRule restricts the requests whose route starts with Prefix, and
+// whose method is one of Methods (any method when empty), to users with one
+// of Roles
+type AccessRule struct {
+	Prefix  string           `mapstructure:"prefix"`
+	Methods []string         `mapstructure:"methods"`
+	Roles   []model.UserRole `mapstructure:"roles"`
+}
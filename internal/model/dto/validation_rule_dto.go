@@ -0,0 +1,24 @@
+package dto
+
+import "bms-go/internal/model"
+
+// ValidationRuleRequest creates or replaces a validation rule. Pattern is
+// required for pattern rules and Min or Max for range rules. Rules are
+// enabled unless Enabled is false.
+type ValidationRuleRequest struct {
+	Kind      model.RuleKind  `json:"kind" binding:"required,oneof=pattern required range"`
+	Field     string          `json:"field" binding:"required"`
+	MediaType model.MediaType `json:"media_type" binding:"omitempty,oneof=print ebook audiobook"`
+	Pattern   string          `json:"pattern" binding:"max=255"`
+	Min       *int            `json:"min"`
+	Max       *int            `json:"max"`
+	Message   string          `json:"message" binding:"max=255"`
+	Enabled   *bool           `json:"enabled"`
+}
+
+// RuleViolation is a validation rule a book does not meet
+type RuleViolation struct {
+	RuleID  uint   `json:"rule_id"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
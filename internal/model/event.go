@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Event is a library event such as an author talk or reading session, held
+// in one of the library's rooms. A Capacity of 0 means no limit.
+type Event struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Title       string    `gorm:"size:200" json:"title"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Room        string    `gorm:"size:100;index:idx_event_room_time,priority:1" json:"room"`
+	StartsAt    time.Time `gorm:"index;index:idx_event_room_time,priority:2" json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Capacity    int       `json:"capacity"`
+	BookID      *uint     `gorm:"index" json:"book_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RSVPStatus is whether an attendee has a seat
+type RSVPStatus string
+
+const (
+	RSVPConfirmed  RSVPStatus = "confirmed"
+	RSVPWaitlisted RSVPStatus = "waitlisted"
+)
+
+// EventRSVP is a user's place at an event. Waitlisted users move up in
+// CreatedAt order as seats free up.
+type EventRSVP struct {
+	EventID   uint       `gorm:"primarykey;autoIncrement:false" json:"event_id"`
+	UserID    uint       `gorm:"primarykey;autoIncrement:false;index" json:"user_id"`
+	Status    RSVPStatus `gorm:"size:16;index" json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Experiment keys the application knows how to vary
+const (
+	// ExperimentSearchRanking varies relevance ranking. Variant params
+	// override the configured search weights by name, e.g. popularity=0.5.
+	ExperimentSearchRanking = "search_ranking"
+	// ExperimentRecommendations varies "more like this". The strategy param
+	// picks the similarity strategy used when a request does not name one.
+	ExperimentRecommendations = "recommendations"
+)
+
+// ExperimentVariant is one arm of an experiment. Weight is its share of
+// subjects relative to the other variants.
+type ExperimentVariant struct {
+	Name   string            `json:"name"`
+	Weight int               `json:"weight"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Experiment splits callers between variants. Each caller is bucketed by a
+// hash of the experiment key and who they are, so they see the same variant
+// on every request while the experiment is unchanged.
+type Experiment struct {
+	ID          uint                `gorm:"primarykey" json:"id"`
+	Key         string              `gorm:"size:64;uniqueIndex" json:"key"`
+	Description string              `gorm:"size:500" json:"description,omitempty"`
+	Variants    []ExperimentVariant `gorm:"serializer:json;type:text" json:"variants"`
+	Enabled     bool                `json:"enabled"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// GuestFavorite is a favorite kept by a visitor who has not signed in,
+// keyed by their guest session until it is claimed by an account or
+// expires
+type GuestFavorite struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	SessionID string    `gorm:"size:80;uniqueIndex:idx_guest_favorite_session_book" json:"-"`
+	BookID    uint      `gorm:"uniqueIndex:idx_guest_favorite_session_book" json:"book_id"`
+	CreatedAt time.Time `gorm:"index" json:"added_at"`
+}
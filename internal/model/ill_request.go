@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// ILLStatus is where an inter-library loan request is
+type ILLStatus string
+
+const (
+	ILLRequested ILLStatus = "requested"
+	ILLOrdered   ILLStatus = "ordered"
+	ILLReceived  ILLStatus = "received"
+	ILLReturned  ILLStatus = "returned"
+	ILLCancelled ILLStatus = "cancelled"
+)
+
+func (s ILLStatus) Valid() bool {
+	switch s {
+	case ILLRequested, ILLOrdered, ILLReceived, ILLReturned, ILLCancelled:
+		return true
+	}
+	return false
+}
+
+// CanMoveTo reports whether a request in s may change to next. Requests move
+// forward one step at a time and can be cancelled until the book arrives.
+func (s ILLStatus) CanMoveTo(next ILLStatus) bool {
+	switch s {
+	case ILLRequested:
+		return next == ILLOrdered || next == ILLCancelled
+	case ILLOrdered:
+		return next == ILLReceived || next == ILLCancelled
+	case ILLReceived:
+		return next == ILLReturned
+	}
+	return false
+}
+
+// ILLRequest is a patron's request to borrow a book the library does not
+// hold from another library. ExternalLibrary is filled in by staff once
+// they know who will lend it.
+type ILLRequest struct {
+	ID              uint              `gorm:"primarykey" json:"id"`
+	UserID          uint              `gorm:"index" json:"user_id"`
+	Title           string            `gorm:"size:255" json:"title"`
+	Author          string            `gorm:"size:255" json:"author,omitempty"`
+	Notes           string            `gorm:"size:500" json:"notes,omitempty"`
+	ExternalLibrary string            `gorm:"size:200" json:"external_library,omitempty"`
+	Status          ILLStatus         `gorm:"size:16;index" json:"status"`
+	History         []ILLStatusChange `gorm:"foreignKey:RequestID" json:"history,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// ILLStatusChange is one step in a request's timeline. ChangedBy is the
+// staff member, or the patron for their own requests and cancellations.
+type ILLStatusChange struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	RequestID uint      `gorm:"index" json:"-"`
+	Status    ILLStatus `gorm:"size:16" json:"status"`
+	Note      string    `gorm:"size:500" json:"note,omitempty"`
+	ChangedBy uint      `json:"changed_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// ImpersonationSession lets a support operator act as a user. Only a hash of
+// the token is stored.
+type ImpersonationSession struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	TokenHash string     `gorm:"size:64;uniqueIndex" json:"-"`
+	UserID    uint       `gorm:"index" json:"user_id"`
+	IssuedBy  string     `gorm:"size:64" json:"issued_by"`
+	Reason    string     `gorm:"size:255" json:"reason"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the session can still be used at now
+func (s ImpersonationSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// ImpersonationAction is the audit record of one request made while
+// impersonating
+type ImpersonationAction struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	SessionID uint      `gorm:"index" json:"session_id"`
+	Method    string    `gorm:"size:10" json:"method"`
+	Path      string    `gorm:"size:2048" json:"path"`
+	Status    int       `json:"status"`
+	ClientIP  string    `gorm:"size:45" json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ImportBatch is one import that wrote to the catalog, kept so the books it
+// added can be traced back to it and the whole import rolled back. Dry runs
+// are not recorded.
+type ImportBatch struct {
+	ID     uint `gorm:"primarykey" json:"id"`
+	UserID uint `gorm:"index" json:"user_id"`
+	// Format is json for imports sent as a request body, otherwise the
+	// uploaded file's format
+	Format       string     `gorm:"size:8" json:"format"`
+	Filename     string     `gorm:"size:255" json:"filename,omitempty"`
+	OnDuplicate  string     `gorm:"size:16" json:"on_duplicate"`
+	Created      int        `json:"created"`
+	Updated      int        `json:"updated"`
+	Skipped      int        `json:"skipped"`
+	RolledBackAt *time.Time `json:"rolled_back_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+}
+
+// ImportBatchItem is a book an import batch created or updated. Previous
+// holds an updated book as it was before the import, so a rollback can
+// restore it; it is empty for created books.
+type ImportBatchItem struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	BatchID   uint            `gorm:"index" json:"batch_id"`
+	BookID    uint            `gorm:"index" json:"book_id"`
+	Previous  json.RawMessage `gorm:"type:json" json:"-"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// InboxNotification is an entry in a user's in-app notification history.
+// Every notification lands in the inbox, whatever channels the user set up.
+type InboxNotification struct {
+	ID        uint              `gorm:"primarykey;index:idx_inbox_user_id,priority:2" json:"id"`
+	UserID    uint              `gorm:"index:idx_inbox_user_id,priority:1;index:idx_inbox_user_read,priority:1" json:"-"`
+	Event     NotificationEvent `gorm:"size:32" json:"event"`
+	Title     string            `gorm:"size:255" json:"title"`
+	Body      string            `gorm:"type:text" json:"body"`
+	URL       string            `gorm:"size:2048" json:"url,omitempty"`
+	ReadAt    *time.Time        `gorm:"index:idx_inbox_user_read,priority:2" json:"read_at"`
+	CreatedAt time.Time         `gorm:"index" json:"created_at"`
+}
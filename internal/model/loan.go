@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Loan is a user borrowing a copy of a book. The copy is out until
+// ReturnedAt is set; a loan still out after DueAt is overdue.
+type Loan struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	BookID     uint       `gorm:"index" json:"book_id"`
+	CopyID     uint       `gorm:"index" json:"copy_id"`
+	UserID     uint       `gorm:"index" json:"user_id"`
+	BorrowedAt time.Time  `json:"borrowed_at"`
+	DueAt      time.Time  `json:"due_at"`
+	ReturnedAt *time.Time `gorm:"index" json:"returned_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Overdue reports whether the loan is still out after it was due at now
+func (l Loan) Overdue(now time.Time) bool {
+	return l.ReturnedAt == nil && now.After(l.DueAt)
+}
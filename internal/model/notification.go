@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// NotificationChannel is a way of reaching a user outside the app
+type NotificationChannel string
+
+const (
+	ChannelSlack    NotificationChannel = "slack"
+	ChannelTelegram NotificationChannel = "telegram"
+	ChannelWebPush  NotificationChannel = "webpush"
+)
+
+func (c NotificationChannel) Valid() bool {
+	switch c {
+	case ChannelSlack, ChannelTelegram, ChannelWebPush:
+		return true
+	}
+	return false
+}
+
+// NotificationEvent is what a notification is about. Values are used as
+// config keys for message templates.
+type NotificationEvent string
+
+const (
+	// EventChangeRequestReviewed tells a contributor their edit was
+	// approved or rejected
+	EventChangeRequestReviewed NotificationEvent = "change_request_reviewed"
+	// EventRSVPPromoted tells a waitlisted user they got a seat at an event
+	EventRSVPPromoted NotificationEvent = "rsvp_promoted"
+	// EventILLStatusChanged tells a patron their inter-library loan request
+	// moved on
+	EventILLStatusChanged NotificationEvent = "ill_status_changed"
+	// EventLoanDueSoon reminds a borrower that a loan is coming due
+	EventLoanDueSoon NotificationEvent = "loan_due_soon"
+)
+
+func (e NotificationEvent) Valid() bool {
+	switch e {
+	case EventChangeRequestReviewed, EventRSVPPromoted, EventILLStatusChanged, EventLoanDueSoon:
+		return true
+	}
+	return false
+}
+
+// NotificationPreference is a user's setup for one channel. Target is where
+// the channel delivers: a Slack webhook URL, a Telegram chat id or a Web
+// Push subscription. An empty Events list receives every event.
+type NotificationPreference struct {
+	UserID    uint                `gorm:"primarykey;autoIncrement:false" json:"user_id"`
+	Channel   NotificationChannel `gorm:"primarykey;size:16" json:"channel"`
+	Target    string              `gorm:"type:text;serializer:encrypted" json:"-"`
+	Events    []NotificationEvent `gorm:"serializer:json;type:text" json:"events"`
+	Enabled   bool                `json:"enabled"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// Wants reports whether the preference receives event
+func (p NotificationPreference) Wants(event NotificationEvent) bool {
+	if !p.Enabled {
+		return false
+	}
+	if len(p.Events) == 0 {
+		return true
+	}
+	for _, e := range p.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrgRole is a member's role inside an organization
+type OrgRole string
+
+const (
+	OrgRoleOwner  OrgRole = "owner"
+	OrgRoleAdmin  OrgRole = "admin"
+	OrgRoleMember OrgRole = "member"
+)
+
+func (r OrgRole) Valid() bool {
+	switch r {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember:
+		return true
+	}
+	return false
+}
+
+// AtLeast reports whether r carries every permission of min
+func (r OrgRole) AtLeast(min OrgRole) bool {
+	rank := map[OrgRole]int{OrgRoleMember: 1, OrgRoleAdmin: 2, OrgRoleOwner: 3}
+	return rank[r] >= rank[min]
+}
+
+// Organization is a group of users sharing a team library
+type Organization struct {
+	gorm.Model
+	Name string `json:"name" gorm:"size:100"`
+}
+
+type OrganizationMember struct {
+	OrganizationID uint      `gorm:"primarykey" json:"organization_id"`
+	UserID         uint      `gorm:"primarykey;index" json:"user_id"`
+	Role           OrgRole   `gorm:"size:16" json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OrganizationInvitation offers a user membership with a role until they
+// accept or decline it
+type OrganizationInvitation struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	OrganizationID uint       `gorm:"index" json:"organization_id"`
+	UserID         uint       `gorm:"index" json:"user_id"`
+	Role           OrgRole    `gorm:"size:16" json:"role"`
+	InvitedBy      uint       `json:"invited_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	DeclinedAt     *time.Time `json:"declined_at,omitempty"`
+}
+
+// OrganizationFavorite is a book in an organization's shared favorites
+type OrganizationFavorite struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	OrganizationID uint      `gorm:"uniqueIndex:idx_org_favorite" json:"organization_id"`
+	BookID         uint      `gorm:"uniqueIndex:idx_org_favorite" json:"book_id"`
+	AddedBy        uint      `json:"added_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "gorm.io/gorm"
+
+// Partner is a server-to-server integration that authenticates by signing
+// requests with a shared secret
+type Partner struct {
+	gorm.Model
+	Name   string `json:"name" gorm:"size:100"`
+	KeyID  string `json:"key_id" gorm:"size:32;uniqueIndex"`
+	Secret string `json:"-" gorm:"size:255;serializer:encrypted"`
+	Active bool   `json:"active"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// PrivacySetting holds a user's privacy and consent choices. Users without a
+// stored row get DefaultPrivacySetting.
+type PrivacySetting struct {
+	UserID              uint      `gorm:"primarykey" json:"user_id"`
+	TrackRecentlyViewed bool      `json:"track_recently_viewed"`
+	PublicProfile       bool      `json:"public_profile"`
+	ShowFavorites       bool      `json:"show_favorites"`
+	AllowAnalytics      bool      `json:"allow_analytics"`
+	MarketingEmails     bool      `json:"marketing_emails"`
+	ForgetLoanHistory   bool      `json:"forget_loan_history"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// DefaultPrivacySetting returns the settings used until a user changes them.
+// Anything that exposes the user to others or to marketing is opt-in.
+func DefaultPrivacySetting(userID uint) PrivacySetting {
+	return PrivacySetting{
+		UserID:              userID,
+		TrackRecentlyViewed: true,
+	}
+}
+
+// FavoritesVisible reports whether other users may see this user's favorites
+func (s PrivacySetting) FavoritesVisible() bool {
+	return s.PublicProfile && s.ShowFavorites
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// ReadingState is where a user is with a book
+type ReadingState string
+
+const (
+	ReadingWantToRead ReadingState = "want_to_read"
+	ReadingInProgress ReadingState = "reading"
+	ReadingFinished   ReadingState = "read"
+)
+
+func (s ReadingState) Valid() bool {
+	switch s {
+	case ReadingWantToRead, ReadingInProgress, ReadingFinished:
+		return true
+	}
+	return false
+}
+
+// ReadingStatus is a user's personal record of a book: whether they want to
+// read it, are reading it or have read it, when they finished it and their
+// private notes. A user has at most one status per book.
+type ReadingStatus struct {
+	ID         uint         `gorm:"primarykey" json:"id"`
+	UserID     uint         `gorm:"not null;uniqueIndex:idx_reading_statuses_user_book" json:"user_id"`
+	BookID     uint         `gorm:"not null;uniqueIndex:idx_reading_statuses_user_book;index" json:"book_id"`
+	Status     ReadingState `gorm:"size:16;not null" json:"status"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Notes      string       `gorm:"type:text" json:"notes,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
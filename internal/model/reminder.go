@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ReminderSetting is when a user is reminded of loans coming due, as days
+// before the due date. Users without a stored row get the configured days.
+type ReminderSetting struct {
+	UserID     uint      `gorm:"primarykey;autoIncrement:false" json:"user_id"`
+	Enabled    bool      `json:"enabled"`
+	DaysBefore []int     `gorm:"serializer:json;type:text" json:"days_before"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// LoanReminder records a reminder sent for a loan, so each is sent once
+// however often the reminder job runs
+type LoanReminder struct {
+	LoanID     uint `gorm:"primarykey;autoIncrement:false"`
+	DaysBefore int  `gorm:"primarykey;autoIncrement:false"`
+	SentAt     time.Time
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// ReportKind is what a scheduled report contains
+type ReportKind string
+
+const (
+	// ReportNewBooks lists books added during the period
+	ReportNewBooks ReportKind = "new_books"
+	// ReportCategorySummary counts the catalog's books per category
+	ReportCategorySummary ReportKind = "category_summary"
+)
+
+func (k ReportKind) Valid() bool {
+	switch k {
+	case ReportNewBooks, ReportCategorySummary:
+		return true
+	}
+	return false
+}
+
+// ReportFrequency is how often a schedule produces a report
+type ReportFrequency string
+
+const (
+	ReportDaily   ReportFrequency = "daily"
+	ReportWeekly  ReportFrequency = "weekly"
+	ReportMonthly ReportFrequency = "monthly"
+)
+
+func (f ReportFrequency) Valid() bool {
+	switch f {
+	case ReportDaily, ReportWeekly, ReportMonthly:
+		return true
+	}
+	return false
+}
+
+// Next returns when a schedule that ran at t runs again
+func (f ReportFrequency) Next(t time.Time) time.Time {
+	switch f {
+	case ReportDaily:
+		return t.AddDate(0, 0, 1)
+	case ReportMonthly:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 7)
+	}
+}
+
+// ReportSchedule produces a report of Kind every Frequency
+type ReportSchedule struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	Name      string          `gorm:"size:100" json:"name"`
+	Kind      ReportKind      `gorm:"size:32" json:"kind"`
+	Frequency ReportFrequency `gorm:"size:16" json:"frequency"`
+	Active    bool            `json:"active"`
+	LastRunAt *time.Time      `json:"last_run_at,omitempty"`
+	NextRunAt time.Time       `gorm:"index" json:"next_run_at"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ReportRun is one generated report, kept so it can be downloaded later
+type ReportRun struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	ScheduleID  uint       `gorm:"index" json:"schedule_id"`
+	Kind        ReportKind `gorm:"size:32" json:"kind"`
+	PeriodStart time.Time  `json:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end"`
+	ContentType string     `gorm:"size:100" json:"content_type"`
+	Rows        int        `json:"rows"`
+	Content     []byte     `gorm:"type:mediumblob" json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type ReservationStatus string
+
+const (
+	// ReservationWaiting reservations are queued for the next free copy
+	ReservationWaiting ReservationStatus = "waiting"
+	// ReservationReady reservations have a copy held for them until ExpiresAt
+	ReservationReady     ReservationStatus = "ready"
+	ReservationFulfilled ReservationStatus = "fulfilled"
+	ReservationCancelled ReservationStatus = "cancelled"
+	// ReservationExpired reservations were not borrowed while the copy was held
+	ReservationExpired ReservationStatus = "expired"
+)
+
+func (s ReservationStatus) Valid() bool {
+	switch s {
+	case ReservationWaiting, ReservationReady, ReservationFulfilled, ReservationCancelled, ReservationExpired:
+		return true
+	}
+	return false
+}
+
+// Reservation is a user queueing for a book whose copies are all out on
+// loan. Reservations are served in the order they were made: when a copy
+// comes back the oldest waiting one becomes ready and the copy is held for
+// that user until ExpiresAt.
+type Reservation struct {
+	ID     uint              `gorm:"primarykey" json:"id"`
+	BookID uint              `gorm:"index" json:"book_id"`
+	UserID uint              `gorm:"index" json:"user_id"`
+	Status ReservationStatus `gorm:"size:20;index" json:"status"`
+	// Position is the reservation's place in the book's queue while waiting,
+	// 1 being next
+	Position  int        `gorm:"->;-:migration" json:"position,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
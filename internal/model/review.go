@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Review is a user's 1-5 rating of a book with optional text. A user reviews a
+// book at most once; Book.AverageRating and Book.RatingCount summarise a
+// book's reviews.
+type Review struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	BookID    uint      `gorm:"not null;uniqueIndex:idx_reviews_book_user" json:"book_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_reviews_book_user;index" json:"user_id"`
+	Rating    int       `gorm:"not null" json:"rating"`
+	Body      string    `gorm:"type:text" json:"body,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "gorm.io/gorm"
+
+// Synonym links two search terms that should match each other, e.g. "sci-fi"
+// and "science fiction". The pair applies in both directions.
+type Synonym struct {
+	gorm.Model
+	Term    string `json:"term" gorm:"size:100;uniqueIndex:idx_synonym_pair"`
+	Synonym string `json:"synonym" gorm:"size:100;uniqueIndex:idx_synonym_pair"`
+}
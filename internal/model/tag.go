@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Tag labels books across categories, such as a genre or a theme. Names
+// are unique ignoring case; a book carries any number of tags through
+// BookTag.
+type Tag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookTag puts a tag on a book
+type BookTag struct {
+	BookID    uint      `gorm:"primaryKey" json:"book_id"`
+	TagID     uint      `gorm:"primaryKey;index" json:"tag_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Tag       *Tag      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// UpstreamRecord links a book pulled from another deployment to its record
+// there. Snapshot holds the upstream catalog fields as last fetched, so a
+// refresh can tell fields changed upstream from fields edited here.
+type UpstreamRecord struct {
+	BookID    uint        `gorm:"primarykey" json:"book_id"`
+	BaseURL   string      `gorm:"size:255" json:"base_url"`
+	RemoteID  uint        `json:"remote_id"`
+	Snapshot  BookChanges `gorm:"serializer:json;type:text" json:"snapshot"`
+	FetchedAt time.Time   `gorm:"index" json:"fetched_at"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// UserStatus is whether an account may be used
+type UserStatus string
+
+const (
+	UserActive   UserStatus = "active"
+	UserDisabled UserStatus = "disabled"
+)
+
+func (s UserStatus) Valid() bool {
+	switch s {
+	case UserActive, UserDisabled:
+		return true
+	}
+	return false
+}
+
+// UserRole decides which write endpoints a user may call. Readers only
+// manage their own data; librarians also maintain the catalog; admins may
+// do everything.
+type UserRole string
+
+const (
+	RoleReader    UserRole = "reader"
+	RoleLibrarian UserRole = "librarian"
+	RoleAdmin     UserRole = "admin"
+)
+
+func (r UserRole) Valid() bool {
+	switch r {
+	case RoleReader, RoleLibrarian, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// User is an account. Personal data such as favorites and views reference
+// it by user_id. PasswordResetRequired makes the user choose a new password
+// the next time they sign in. Email is encrypted at rest and looked up, and
+// kept unique, through its blind index EmailHash, which the repository
+// computes whenever it writes the user.
+type User struct {
+	ID                    uint       `gorm:"primarykey" json:"id"`
+	Email                 string     `gorm:"size:512;serializer:encrypted" json:"email"`
+	EmailHash             string     `gorm:"size:64;uniqueIndex" json:"-"`
+	Name                  string     `gorm:"size:100" json:"name"`
+	Status                UserStatus `gorm:"size:16;index;default:active" json:"status"`
+	DisabledReason        string     `gorm:"size:255" json:"disabled_reason,omitempty"`
+	DisabledAt            *time.Time `json:"disabled_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+
+	// PasswordHash is empty for accounts created by an administrator until
+	// the user sets a password
+	PasswordHash string `gorm:"size:60" json:"-"`
+
+	Role UserRole `gorm:"size:16;default:reader" json:"role"`
+
+	// CardNumber is the number on the user's library card, if they have one
+	CardNumber *string `gorm:"size:19;uniqueIndex" json:"card_number,omitempty"`
+}
+
+// NormalizeCardNumber drops the spaces and hyphens card numbers are often
+// printed or typed with
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+}
+
+// ValidCardNumber reports whether number, once normalized, is 8 to 19
+// digits ending in a Luhn check digit, so mistyped and misread numbers are
+// caught before they are looked up
+func ValidCardNumber(number string) bool {
+	if len(number) < 8 || len(number) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		// Every second digit from the right, starting left of the check
+		// digit, is doubled
+		if (len(number)-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// RuleKind is what a validation rule checks
+type RuleKind string
+
+const (
+	// RulePattern requires a text field to match a regular expression.
+	// Empty values are left to RuleRequired.
+	RulePattern RuleKind = "pattern"
+	// RuleRequired requires a field to be non-empty, or non-zero for numbers
+	RuleRequired RuleKind = "required"
+	// RuleRange requires a numeric field to lie between Min and Max, either
+	// of which may be left open. Zero values, meaning unknown, are skipped.
+	RuleRange RuleKind = "range"
+)
+
+func (k RuleKind) Valid() bool {
+	switch k {
+	case RulePattern, RuleRequired, RuleRange:
+		return true
+	}
+	return false
+}
+
+// ValidationRule is a cataloging standard books must meet when they are
+// created, updated or imported. Rules with a MediaType only apply to books
+// of that media type.
+type ValidationRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Kind      RuleKind  `gorm:"size:16" json:"kind"`
+	Field     string    `gorm:"size:32" json:"field"`
+	MediaType MediaType `gorm:"size:16" json:"media_type,omitempty"`
+	Pattern   string    `gorm:"size:255" json:"pattern,omitempty"`
+	Min       *int      `json:"min,omitempty"`
+	Max       *int      `json:"max,omitempty"`
+	// Message replaces the generated explanation shown when the rule fails
+	Message   string    `gorm:"size:255" json:"message,omitempty"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"bms-go/config"
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidBlockDuration = apperror.New("INVALID_BLOCK_DURATION", "duration must be a positive duration such as 30m or 24h")
+
+// abuseFlagsRaised counts clients flagged by the detector, and
+// blockedRequests the requests refused from blocked clients, exposed
+// through expvar at /debug/vars
+var (
+	abuseFlagsRaised = expvar.NewInt("abuse_flags_raised")
+	blockedRequests  = expvar.NewInt("abuse_blocked_requests")
+)
+
+type clientActivity struct {
+	requests int
+	books    map[uint]struct{}
+}
+
+// AbuseService flags clients whose request pattern looks like abuse, such
+// as scraping the catalog one book at a time, and refuses clients that an
+// administrator blocked. Activity is tracked in memory per instance; blocks
+// are stored and reloaded so every instance enforces them.
+type AbuseService struct {
+	repo *repository.ClientBlockRepository
+	cfg  config.AbuseConfig
+
+	mu       sync.Mutex
+	start    time.Time
+	activity map[string]*clientActivity
+	flags    map[string]*dto.AbuseFlag
+	blocks   map[string]model.ClientBlock
+}
+
+func NewAbuseService(repo *repository.ClientBlockRepository, cfg config.AbuseConfig) *AbuseService {
+	return &AbuseService{
+		repo:     repo,
+		cfg:      cfg,
+		activity: make(map[string]*clientActivity),
+		flags:    make(map[string]*dto.AbuseFlag),
+		blocks:   make(map[string]model.ClientBlock),
+	}
+}
+
+// Observe records a request by client; bookID is the book whose detail page
+// was requested, or 0
+func (s *AbuseService) Observe(client string, bookID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if start := now.Truncate(s.cfg.Window); !start.Equal(s.start) {
+		s.start = start
+		s.activity = make(map[string]*clientActivity)
+	}
+	a := s.activity[client]
+	if a == nil {
+		a = &clientActivity{books: make(map[uint]struct{})}
+		s.activity[client] = a
+	}
+	a.requests++
+	// Stop collecting ids once over the threshold so memory stays bounded
+	if bookID != 0 && len(a.books) <= s.cfg.MaxDistinctBooks {
+		a.books[bookID] = struct{}{}
+	}
+
+	var reason string
+	switch {
+	case s.cfg.MaxDistinctBooks > 0 && len(a.books) > s.cfg.MaxDistinctBooks:
+		reason = fmt.Sprintf("opened more than %d books in %s", s.cfg.MaxDistinctBooks, s.cfg.Window)
+	case s.cfg.MaxRequests > 0 && a.requests > s.cfg.MaxRequests:
+		reason = fmt.Sprintf("made more than %d requests in %s", s.cfg.MaxRequests, s.cfg.Window)
+	default:
+		if flag, ok := s.flags[client]; ok {
+			flag.LastSeenAt = now
+		}
+		return
+	}
+
+	flag, ok := s.flags[client]
+	if !ok {
+		flag = &dto.AbuseFlag{Client: client, Reason: reason, FlaggedAt: now}
+		s.flags[client] = flag
+		abuseFlagsRaised.Add(1)
+		log.Printf("Abuse detector flagged %s: %s", client, reason)
+	}
+	flag.LastSeenAt = now
+	if a.requests > flag.Requests {
+		flag.Requests = a.requests
+	}
+	if len(a.books) > flag.DistinctBooks {
+		flag.DistinctBooks = len(a.books)
+	}
+}
+
+// IsBlocked reports whether client is blocked, counting the refusal
+func (s *AbuseService) IsBlocked(client string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	block, ok := s.blocks[client]
+	if !ok || !block.Active(time.Now()) {
+		return false
+	}
+	blockedRequests.Add(1)
+	return true
+}
+
+// GetFlags returns the flagged clients, most recently active first
+func (s *AbuseService) GetFlags() []dto.AbuseFlag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	flags := make([]dto.AbuseFlag, 0, len(s.flags))
+	for _, f := range s.flags {
+		flag := *f
+		if block, ok := s.blocks[f.Client]; ok && block.Active(now) {
+			flag.Blocked = true
+		}
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].LastSeenAt.After(flags[j].LastSeenAt) })
+	return flags
+}
+
+// ClearFlag forgets a flag, e.g. once the client was found to be legitimate
+func (s *AbuseService) ClearFlag(client string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.flags[client]
+	delete(s.flags, client)
+	return ok
+}
+
+func (s *AbuseService) GetBlocks() ([]model.ClientBlock, error) {
+	return s.repo.FindAll()
+}
+
+// Block refuses the client's requests, for the requested duration or until
+// unblocked
+func (s *AbuseService) Block(req dto.BlockClientRequest) (*model.ClientBlock, error) {
+	block := model.ClientBlock{Client: req.Client, Reason: req.Reason, CreatedAt: time.Now()}
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			return nil, ErrInvalidBlockDuration
+		}
+		expires := block.CreatedAt.Add(d)
+		block.ExpiresAt = &expires
+	}
+	if err := s.repo.Save(&block); err != nil {
+		return nil, err
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.blocks[req.Client]
+	return &stored, nil
+}
+
+func (s *AbuseService) Unblock(id uint) error {
+	deleted, err := s.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return gorm.ErrRecordNotFound
+	}
+	return s.Reload()
+}
+
+// Reload replaces the in-memory blocks with the stored ones
+func (s *AbuseService) Reload() error {
+	blocks, err := s.repo.FindAll()
+	if err != nil {
+		return err
+	}
+	byClient := make(map[string]model.ClientBlock, len(blocks))
+	for _, b := range blocks {
+		byClient[b.Client] = b
+	}
+
+	s.mu.Lock()
+	s.blocks = byClient
+	s.mu.Unlock()
+	return nil
+}
+
+// Run reloads blocks and drops stale flags every interval until ctx is
+// cancelled
+func (s *AbuseService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(); err != nil {
+				log.Printf("Failed to reload client blocks: %v", err)
+			}
+			s.pruneFlags()
+		}
+	}
+}
+
+func (s *AbuseService) pruneFlags() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := time.Now().Add(-s.cfg.FlagTTL)
+	for client, f := range s.flags {
+		if f.LastSeenAt.Before(cutoff) {
+			delete(s.flags, client)
+		}
+	}
+}
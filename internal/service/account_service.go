@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"log"
+	"time"
+)
+
+var (
+	ErrDeletionPending   = apperror.New("DELETION_PENDING", "account deletion already requested")
+	ErrNoPendingDeletion = apperror.New("NO_PENDING_DELETION", "no pending account deletion")
+)
+
+// AccountService exports a user's personal data and erases it on request.
+// Erasure happens once the grace period has passed, so a user can still
+// cancel a deletion they regret.
+type AccountService struct {
+	repo    *repository.AccountRepository
+	privacy *repository.PrivacyRepository
+	grace   time.Duration
+}
+
+func NewAccountService(repo *repository.AccountRepository, privacy *repository.PrivacyRepository, grace time.Duration) *AccountService {
+	return &AccountService{repo: repo, privacy: privacy, grace: grace}
+}
+
+// ExportData collects everything stored about the user
+func (s *AccountService) ExportData(userID uint) (*dto.DataExport, error) {
+	setting, err := s.privacy.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := s.repo.FindFavorites(userID)
+	if err != nil {
+		return nil, err
+	}
+	views, err := s.repo.FindViews(userID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.repo.FindUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.FindRecords(userID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.repo.FindPendingDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &dto.DataExport{
+		UserID:               userID,
+		ExportedAt:           time.Now(),
+		Account:              records.Account,
+		Privacy:              toPrivacySettingsResponse(*setting),
+		Favorites:            make([]dto.ExportedFavorite, len(favorites)),
+		BookViews:            make([]dto.ExportedView, len(views)),
+		Reviews:              records.Reviews,
+		ReadingStatuses:      records.ReadingStatuses,
+		Loans:                records.Loans,
+		Reservations:         records.Reservations,
+		Collections:          make([]dto.ExportedCollection, len(records.Collections)),
+		NotificationChannels: make([]dto.ExportedChannel, len(records.NotificationChannels)),
+		Devices:              records.Devices,
+		Notifications:        records.Notifications,
+		RSVPs:                records.RSVPs,
+		ILLRequests:          records.ILLRequests,
+		Organizations:        records.Memberships,
+		Invitations:          records.Invitations,
+		APIUsage:             make([]dto.ExportedUsage, len(usage)),
+	}
+	for i, f := range favorites {
+		export.Favorites[i] = dto.ExportedFavorite{BookID: f.BookID, CreatedAt: f.CreatedAt}
+	}
+	for i, v := range views {
+		export.BookViews[i] = dto.ExportedView{BookID: v.BookID, ViewedAt: v.ViewedAt}
+	}
+	for i, u := range usage {
+		export.APIUsage[i] = dto.ExportedUsage{Period: u.Period, Count: u.Count}
+	}
+	books := make(map[uint][]uint)
+	for _, b := range records.CollectionBooks {
+		books[b.CollectionID] = append(books[b.CollectionID], b.BookID)
+	}
+	for i, c := range records.Collections {
+		export.Collections[i] = dto.ExportedCollection{Collection: c, BookIDs: books[c.ID]}
+		if export.Collections[i].BookIDs == nil {
+			export.Collections[i].BookIDs = []uint{}
+		}
+	}
+	for i, p := range records.NotificationChannels {
+		export.NotificationChannels[i] = dto.ExportedChannel{
+			Channel:   p.Channel,
+			Target:    p.Target,
+			Events:    p.Events,
+			Enabled:   p.Enabled,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+		}
+	}
+	if records.Quota != nil {
+		export.MonthlyQuota = &records.Quota.MonthlyLimit
+	}
+	if pending != nil {
+		resp := toAccountDeletionResponse(*pending)
+		export.Deletion = &resp
+	}
+	return export, nil
+}
+
+// RequestDeletion schedules the user's data for erasure after the grace period
+func (s *AccountService) RequestDeletion(userID uint) (*dto.AccountDeletionResponse, error) {
+	pending, err := s.repo.FindPendingDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, ErrDeletionPending
+	}
+
+	now := time.Now()
+	deletion := model.AccountDeletion{
+		UserID:       userID,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(s.grace),
+	}
+	if err := s.repo.CreateDeletion(&deletion); err != nil {
+		return nil, err
+	}
+
+	resp := toAccountDeletionResponse(deletion)
+	return &resp, nil
+}
+
+// CancelDeletion withdraws a deletion that has not been carried out yet
+func (s *AccountService) CancelDeletion(userID uint) (*dto.AccountDeletionResponse, error) {
+	pending, err := s.repo.FindPendingDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		return nil, ErrNoPendingDeletion
+	}
+
+	now := time.Now()
+	pending.CancelledAt = &now
+	if err := s.repo.SaveDeletion(pending); err != nil {
+		return nil, err
+	}
+
+	resp := toAccountDeletionResponse(*pending)
+	return &resp, nil
+}
+
+// ProcessDueDeletions erases the data of every user whose grace period has
+// ended and returns how many accounts were erased
+func (s *AccountService) ProcessDueDeletions() (int, error) {
+	due, err := s.repo.FindDueDeletions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range due {
+		if err := s.repo.EraseUser(&due[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
+// GetDeletions returns the deletion audit trail, newest first
+func (s *AccountService) GetDeletions() ([]dto.AccountDeletionResponse, error) {
+	deletions, err := s.repo.FindDeletions()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AccountDeletionResponse, len(deletions))
+	for i, d := range deletions {
+		responses[i] = toAccountDeletionResponse(d)
+	}
+	return responses, nil
+}
+
+// Run processes due deletions every interval until ctx is cancelled
+func (s *AccountService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			erased, err := s.ProcessDueDeletions()
+			if err != nil {
+				log.Printf("Account deletion failed: %v", err)
+			}
+			if erased > 0 {
+				log.Printf("Erased personal data of %d accounts", erased)
+			}
+		}
+	}
+}
+
+func toAccountDeletionResponse(d model.AccountDeletion) dto.AccountDeletionResponse {
+	return dto.AccountDeletionResponse{
+		ID:           d.ID,
+		UserID:       d.UserID,
+		RequestedAt:  d.RequestedAt,
+		ScheduledFor: d.ScheduledFor,
+		CancelledAt:  d.CancelledAt,
+		CompletedAt:  d.CompletedAt,
+		Favorites:    d.Favorites,
+		BookViews:    d.BookViews,
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// AffinityService maintains the co-favorite graph: for every book, the
+// books most often favorited by the same users. The graph is rebuilt
+// periodically into its own table so related books and recommendations are
+// a single indexed lookup. Only the favorites of users who allowed
+// analytics are counted.
+type AffinityService struct {
+	repo       *repository.AffinityRepository
+	bookRepo   BookReader
+	minUsers   int
+	maxRelated int
+}
+
+func NewAffinityService(repo *repository.AffinityRepository, bookRepo BookReader, minUsers, maxRelated int) *AffinityService {
+	return &AffinityService{repo: repo, bookRepo: bookRepo, minUsers: minUsers, maxRelated: maxRelated}
+}
+
+// Rebuild recomputes the whole graph from the current favorites
+func (s *AffinityService) Rebuild() (dto.AffinityResult, error) {
+	pairs, err := s.repo.CoFavorites(s.minUsers)
+	if err != nil {
+		return dto.AffinityResult{}, err
+	}
+	fans, err := s.repo.FavoriteCounts()
+	if err != nil {
+		return dto.AffinityResult{}, err
+	}
+
+	now := time.Now()
+	byBook := make(map[uint][]model.BookAffinity)
+	for _, p := range pairs {
+		score := float64(p.Users) / math.Sqrt(float64(fans[p.BookID])*float64(fans[p.RelatedBookID]))
+		byBook[p.BookID] = append(byBook[p.BookID], model.BookAffinity{
+			BookID:        p.BookID,
+			RelatedBookID: p.RelatedBookID,
+			CoFavorites:   p.Users,
+			Score:         score,
+			ComputedAt:    now,
+		})
+	}
+
+	var affinities []model.BookAffinity
+	for _, links := range byBook {
+		sort.Slice(links, func(i, j int) bool {
+			if links[i].Score != links[j].Score {
+				return links[i].Score > links[j].Score
+			}
+			return links[i].RelatedBookID < links[j].RelatedBookID
+		})
+		if len(links) > s.maxRelated {
+			links = links[:s.maxRelated]
+		}
+		affinities = append(affinities, links...)
+	}
+
+	if err := s.repo.Replace(affinities); err != nil {
+		return dto.AffinityResult{}, err
+	}
+	return dto.AffinityResult{Books: len(byBook), Links: len(affinities)}, nil
+}
+
+// Run rebuilds the graph every interval until ctx is cancelled
+func (s *AffinityService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Rebuild()
+			if err != nil {
+				log.Printf("Co-favorite graph rebuild failed: %v", err)
+				continue
+			}
+			log.Printf("Rebuilt co-favorite graph: %d books, %d links", result.Books, result.Links)
+		}
+	}
+}
+
+// GetRelatedBooks returns up to limit books favorited by the same users as
+// bookID, strongest first, optionally only those with rating
+func (s *AffinityService) GetRelatedBooks(ctx context.Context, bookID uint, rating model.ContentRating, limit int) (*dto.RelatedBooksResponse, error) {
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	affinities, err := s.repo.FindRelated(bookID, s.maxRelated)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(affinities))
+	for i, a := range affinities {
+		ids[i] = a.RelatedBookID
+	}
+	books, err := s.booksByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.RelatedBooksResponse{BookID: bookID, Data: []dto.RelatedBook{}}
+	for _, a := range affinities {
+		b, ok := books[a.RelatedBookID]
+		if !ok || (rating != "" && b.ContentRating != rating) {
+			continue
+		}
+		resp.Data = append(resp.Data, dto.RelatedBook{Book: b, Score: a.Score, CoFavorites: a.CoFavorites})
+		if len(resp.Data) == limit {
+			break
+		}
+	}
+	return resp, nil
+}
+
+// GetRecommendations returns up to limit books the user has not favorited,
+// ranked by their summed affinity with the user's favorites
+func (s *AffinityService) GetRecommendations(ctx context.Context, userID uint, rating model.ContentRating, limit int) ([]dto.RecommendedBook, error) {
+	// Leave room for books dropped by the rating filter
+	recs, err := s.repo.Recommend(userID, limit*2)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(recs))
+	for i, r := range recs {
+		ids[i] = r.BookID
+	}
+	books, err := s.booksByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	recommended := []dto.RecommendedBook{}
+	for _, r := range recs {
+		b, ok := books[r.BookID]
+		if !ok || (rating != "" && b.ContentRating != rating) {
+			continue
+		}
+		recommended = append(recommended, dto.RecommendedBook{Book: b, Score: r.Score})
+		if len(recommended) == limit {
+			break
+		}
+	}
+	return recommended, nil
+}
+
+func (s *AffinityService) booksByID(ctx context.Context, ids []uint) (map[uint]model.Book, error) {
+	byID := make(map[uint]model.Book, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+	books, err := s.bookRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range books {
+		byID[b.ID] = b
+	}
+	return byID, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"expvar"
+	"log"
+	"time"
+)
+
+// Archival counters exposed through expvar at /debug/vars
+var (
+	archivedChangeEvents         = expvar.NewInt("archive_moved_change_events")
+	archivedImpersonationActions = expvar.NewInt("archive_moved_impersonation_actions")
+	archiveRuns                  = expvar.NewInt("archive_runs")
+)
+
+// ArchiveService moves change events and impersonation audit entries older
+// than the configured age into archive tables, keeping the live tables small
+// while the history stays queryable
+type ArchiveService struct {
+	repo      *repository.ArchiveRepository
+	age       time.Duration
+	batchSize int
+}
+
+func NewArchiveService(repo *repository.ArchiveRepository, age time.Duration, batchSize int) *ArchiveService {
+	return &ArchiveService{repo: repo, age: age, batchSize: batchSize}
+}
+
+// Archive moves every row past the archive age, batchSize rows per
+// transaction so the live tables are never locked for long
+func (s *ArchiveService) Archive() (*dto.ArchiveResult, error) {
+	result := &dto.ArchiveResult{Cutoff: time.Now().Add(-s.age)}
+
+	var err error
+	result.ChangeEvents, err = s.moveAll(func() (int64, error) {
+		return s.repo.ArchiveChangeEvents(result.Cutoff, s.batchSize)
+	})
+	archivedChangeEvents.Add(result.ChangeEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	result.ImpersonationActions, err = s.moveAll(func() (int64, error) {
+		return s.repo.ArchiveImpersonationActions(result.Cutoff, s.batchSize)
+	})
+	archivedImpersonationActions.Add(result.ImpersonationActions)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveRuns.Add(1)
+	result.RanAt = time.Now()
+	return result, nil
+}
+
+// moveAll repeats a batch move until a batch comes back short
+func (s *ArchiveService) moveAll(move func() (int64, error)) (int64, error) {
+	var total int64
+	for {
+		n, err := move()
+		total += n
+		if err != nil || n == 0 || n < int64(s.batchSize) {
+			return total, err
+		}
+	}
+}
+
+func (s *ArchiveService) GetChangeEvents(entity string, entityID uint, afterSeq uint64, limit int) ([]model.ArchivedChangeEvent, error) {
+	return s.repo.FindChangeEvents(entity, entityID, afterSeq, limit)
+}
+
+func (s *ArchiveService) GetImpersonationActions(sessionID uint) ([]model.ArchivedImpersonationAction, error) {
+	return s.repo.FindImpersonationActions(sessionID)
+}
+
+// Run archives every interval until ctx is cancelled
+func (s *ArchiveService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Archive()
+			if err != nil {
+				log.Printf("Archival failed: %v", err)
+				continue
+			}
+			if result.ChangeEvents > 0 || result.ImpersonationActions > 0 {
+				log.Printf("Archived %d change events, %d impersonation actions", result.ChangeEvents, result.ImpersonationActions)
+			}
+		}
+	}
+}
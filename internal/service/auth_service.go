@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bms-go/config"
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAuthUnavailable       = apperror.New("AUTH_UNAVAILABLE", "sign-in is not configured")
+	ErrInvalidCredentials    = apperror.New("INVALID_CREDENTIALS", "invalid email or password")
+	ErrInvalidToken          = apperror.New("INVALID_TOKEN", "invalid or expired access token")
+	ErrPasswordResetRequired = apperror.New("PASSWORD_RESET_REQUIRED", "a new password is required to sign in")
+	ErrAccountDisabled       = apperror.New("ACCOUNT_DISABLED", "account is disabled")
+	ErrPasswordTooShort      = apperror.New("PASSWORD_TOO_SHORT", "password is too short")
+	ErrPasswordTooLong       = apperror.New("PASSWORD_TOO_LONG", "password must be at most 72 bytes")
+)
+
+// AuthService registers users and signs them in with passwords, issuing
+// HS256 JWT access tokens. Tokens are stateless; disabling an account takes
+// effect through the ActiveUser middleware.
+type AuthService struct {
+	users AccountRepository
+	cfg   config.AuthConfig
+}
+
+func NewAuthService(users AccountRepository, cfg config.AuthConfig) *AuthService {
+	return &AuthService{users: users, cfg: cfg}
+}
+
+// Enabled reports whether a signing secret is configured
+func (s *AuthService) Enabled() bool {
+	return s.cfg.JWTSecret != ""
+}
+
+func (s *AuthService) Register(req dto.RegisterRequest) (*dto.TokenResponse, error) {
+	if !s.Enabled() {
+		return nil, ErrAuthUnavailable
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	_, err := s.users.FindByEmail(email)
+	if err == nil {
+		return nil, ErrEmailTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hash, err := s.hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := model.User{
+		Email:        email,
+		Name:         strings.TrimSpace(req.Name),
+		Status:       model.UserActive,
+		PasswordHash: hash,
+	}
+	if err := s.users.Create(&user); err != nil {
+		return nil, err
+	}
+	return s.issue(user.ID)
+}
+
+// Login checks the user's password and issues an access token. When an
+// administrator required a password reset, the request must also carry the
+// new password.
+func (s *AuthService) Login(req dto.LoginRequest) (*dto.TokenResponse, error) {
+	if !s.Enabled() {
+		return nil, ErrAuthUnavailable
+	}
+	user, err := s.checkPassword(strings.ToLower(strings.TrimSpace(req.Email)), req.Password)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == model.UserDisabled {
+		return nil, ErrAccountDisabled
+	}
+
+	if user.PasswordResetRequired || req.NewPassword != "" {
+		if req.NewPassword == "" {
+			return nil, ErrPasswordResetRequired
+		}
+		if err := s.setPassword(user, req.NewPassword); err != nil {
+			return nil, err
+		}
+	}
+	return s.issue(user.ID)
+}
+
+// ChangePassword replaces the password of a signed-in user
+func (s *AuthService) ChangePassword(userID uint, req dto.ChangePasswordRequest) error {
+	user, err := s.users.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := s.checkPassword(user.Email, req.CurrentPassword); err != nil {
+		return err
+	}
+	return s.setPassword(user, req.NewPassword)
+}
+
+// Authenticate returns the user an access token was issued to
+func (s *AuthService) Authenticate(token string) (uint, error) {
+	if !s.Enabled() {
+		return 0, ErrInvalidToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, s.sign(parts[0]+"."+parts[1])) {
+		return 0, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return 0, ErrInvalidToken
+	}
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return 0, ErrInvalidToken
+	}
+	if claims.Issuer != s.cfg.Issuer || time.Now().Unix() >= claims.ExpiresAt {
+		return 0, ErrInvalidToken
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 0)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidToken
+	}
+	return uint(id), nil
+}
+
+// IssueToken signs an access token for userID without a password, for
+// callers that already know who the user is, such as tests
+func (s *AuthService) IssueToken(userID uint) (*dto.TokenResponse, error) {
+	if !s.Enabled() {
+		return nil, ErrAuthUnavailable
+	}
+	return s.issue(userID)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (s *AuthService) issue(userID uint) (*dto.TokenResponse, error) {
+	now := time.Now()
+	expires := now.Add(s.cfg.TokenTTL)
+
+	header, err := json.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return nil, err
+	}
+	claims, err := json.Marshal(jwtClaims{
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		Issuer:    s.cfg.Issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expires.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	token := signed + "." + base64.RawURLEncoding.EncodeToString(s.sign(signed))
+
+	return &dto.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expires.Truncate(time.Second),
+		UserID:      userID,
+	}, nil
+}
+
+func (s *AuthService) sign(input string) []byte {
+	mac := hmac.New(sha256.New, []byte(s.cfg.JWTSecret))
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// dummyPasswordHash is compared against when there is no password to
+// check, so signing in to an unknown account takes as long as to a known one
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// checkPassword returns the account with email if password matches it.
+// Unknown emails and accounts without a password fail the same way as a
+// wrong password, and after the same bcrypt work, so response timing does
+// not tell which accounts exist.
+func (s *AuthService) checkPassword(email, password string) (*model.User, error) {
+	user, err := s.users.FindByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err != nil || user.PasswordHash == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func (s *AuthService) setPassword(user *model.User, password string) error {
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hash
+	user.PasswordResetRequired = false
+	return s.users.Save(user)
+}
+
+func (s *AuthService) hashPassword(password string) (string, error) {
+	if len([]rune(password)) < s.cfg.MinPasswordLength {
+		return "", fmt.Errorf("%w: at least %d characters", ErrPasswordTooShort, s.cfg.MinPasswordLength)
+	}
+	if len(password) > 72 {
+		return "", ErrPasswordTooLong
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
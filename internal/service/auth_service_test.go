@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bms-go/config"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testJWTSecret = "test-secret"
+
+func newTestAuth(t *testing.T, users ...*model.User) *AuthService {
+	t.Helper()
+	return NewAuthService(&fakeAccounts{users: users}, config.AuthConfig{
+		JWTSecret: testJWTSecret,
+		Issuer:    "bms-go",
+		TokenTTL:  time.Hour,
+	})
+}
+
+func testUser(t *testing.T, id uint, email, password string) *model.User {
+	t.Helper()
+	user := &model.User{Email: email, Status: model.UserActive}
+	user.ID = id
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user.PasswordHash = string(hash)
+	}
+	return user
+}
+
+func TestAuthServiceLogin(t *testing.T) {
+	s := newTestAuth(t,
+		testUser(t, 1, "ada@example.com", "correct horse"),
+		// Accounts created by an admin or a partner have no password yet
+		testUser(t, 2, "grace@example.com", ""),
+	)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantUser uint
+		wantErr  error
+	}{
+		{"right password", "ada@example.com", "correct horse", 1, nil},
+		{"email in another case", " Ada@Example.com", "correct horse", 1, nil},
+		{"wrong password", "ada@example.com", "battery staple", 0, ErrInvalidCredentials},
+		{"unknown email", "nobody@example.com", "correct horse", 0, ErrInvalidCredentials},
+		{"account without a password", "grace@example.com", "", 0, ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := s.Login(dto.LoginRequest{Email: tt.email, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && token.UserID != tt.wantUser {
+				t.Errorf("signed in as %d, want %d", token.UserID, tt.wantUser)
+			}
+		})
+	}
+}
+
+// signTestToken builds an HS256-signed token from any header and claims, so
+// tests can present tokens the service would never issue
+func signTestToken(t *testing.T, secret string, header, claims interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	signed := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthServiceAuthenticate(t *testing.T) {
+	s := newTestAuth(t)
+	issued, err := s.IssueToken(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	hs256 := jwtHeader{Alg: "HS256", Typ: "JWT"}
+	valid := jwtClaims{Subject: "7", Issuer: "bms-go", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+	with := func(change func(*jwtClaims)) jwtClaims {
+		c := valid
+		change(&c)
+		return c
+	}
+	unsigned := func(header jwtHeader) string {
+		raw, _ := json.Marshal(header)
+		claims, _ := json.Marshal(valid)
+		return base64.RawURLEncoding.EncodeToString(raw) + "." + base64.RawURLEncoding.EncodeToString(claims) + "."
+	}
+	tamper := func(token string) string {
+		// Change the first signature character; the last one carries padding
+		// bits a change may not reach
+		i := strings.LastIndex(token, ".") + 1
+		swap := "A"
+		if token[i] == 'A' {
+			swap = "B"
+		}
+		return token[:i] + swap + token[i+1:]
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		wantID uint
+	}{
+		{"issued token", issued.AccessToken, 7},
+		{"hand-built valid token", signTestToken(t, testJWTSecret, hs256, valid), 7},
+		{"bad signature", tamper(issued.AccessToken), 0},
+		{"signed with another secret", signTestToken(t, "other-secret", hs256, valid), 0},
+		{"alg HS512", signTestToken(t, testJWTSecret, jwtHeader{Alg: "HS512", Typ: "JWT"}, valid), 0},
+		{"alg RS256", signTestToken(t, testJWTSecret, jwtHeader{Alg: "RS256", Typ: "JWT"}, valid), 0},
+		{"alg none without a signature", unsigned(jwtHeader{Alg: "none", Typ: "JWT"}), 0},
+		{"alg none with a signature", signTestToken(t, testJWTSecret, jwtHeader{Alg: "none", Typ: "JWT"}, valid), 0},
+		{"expired", signTestToken(t, testJWTSecret, hs256, with(func(c *jwtClaims) { c.ExpiresAt = now.Add(-time.Second).Unix() })), 0},
+		{"expiring now", signTestToken(t, testJWTSecret, hs256, with(func(c *jwtClaims) { c.ExpiresAt = now.Unix() })), 0},
+		{"no expiry", signTestToken(t, testJWTSecret, hs256, with(func(c *jwtClaims) { c.ExpiresAt = 0 })), 0},
+		{"wrong issuer", signTestToken(t, testJWTSecret, hs256, with(func(c *jwtClaims) { c.Issuer = "someone-else" })), 0},
+		{"no subject", signTestToken(t, testJWTSecret, hs256, with(func(c *jwtClaims) { c.Subject = "" })), 0},
+		{"subject zero", signTestToken(t, testJWTSecret, hs256, with(func(c *jwtClaims) { c.Subject = "0" })), 0},
+		{"two segments", "a.b", 0},
+		{"empty", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := s.Authenticate(tt.token)
+			if tt.wantID == 0 {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Authenticate = %d, %v; want %v", id, err, ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil || id != tt.wantID {
+				t.Errorf("Authenticate = %d, %v; want %d", id, err, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestAuthServiceAuthenticateWithoutSecret(t *testing.T) {
+	token := signTestToken(t, "", jwtHeader{Alg: "HS256", Typ: "JWT"}, jwtClaims{Subject: "1", Issuer: "bms-go", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	s := NewAuthService(&fakeAccounts{}, config.AuthConfig{Issuer: "bms-go", TokenTTL: time.Hour})
+	if _, err := s.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token signed with an empty secret: error = %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAuthorNotFound = apperror.New("AUTHOR_NOT_FOUND", "author not found")
+	ErrAuthorExists   = apperror.New("AUTHOR_EXISTS", "an author with this name already exists")
+	ErrAuthorInUse    = apperror.New("AUTHOR_IN_USE", "author still has books")
+	ErrBlankAuthor    = apperror.New("BLANK_AUTHOR", "author name must not be blank")
+)
+
+// AuthorService manages the authors books are written by. Every change to
+// an author's books drops the cached book data through
+// BookService.BooksChanged.
+type AuthorService struct {
+	repo     *repository.AuthorRepository
+	bookRepo BookReader
+	books    *BookService
+}
+
+func NewAuthorService(repo *repository.AuthorRepository, bookRepo BookReader, books *BookService) *AuthorService {
+	return &AuthorService{repo: repo, bookRepo: bookRepo, books: books}
+}
+
+// GetAuthors lists every author by name with their number of books
+func (s *AuthorService) GetAuthors() ([]dto.AuthorResponse, error) {
+	authors, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if authors == nil {
+		authors = []dto.AuthorResponse{}
+	}
+	return authors, nil
+}
+
+func (s *AuthorService) GetAuthor(id uint) (*model.Author, error) {
+	return s.find(id)
+}
+
+// GetBooks returns a page of author id's books, by title, only those with
+// rating when it is set
+func (s *AuthorService) GetBooks(ctx context.Context, id uint, rating model.ContentRating, limit, offset int) (*dto.AuthorBookListResponse, error) {
+	author, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	books, total, err := s.bookRepo.FindByAuthor(ctx, id, rating, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AuthorBookListResponse{
+		Author: *author,
+		Data:   books,
+		Meta: dto.AuthorBookListMeta{
+			Count:  len(books),
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}, nil
+}
+
+func (s *AuthorService) CreateAuthor(req dto.AuthorRequest) (*model.Author, error) {
+	name, err := s.availableName(req.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	author := model.Author{Name: name}
+	if err := s.repo.Create(&author); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// UpdateAuthor renames author id, relabelling their books
+func (s *AuthorService) UpdateAuthor(id uint, req dto.AuthorRequest) (*model.Author, error) {
+	author, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.availableName(req.Name, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.Rename(author, name); err != nil {
+		return nil, err
+	}
+	s.books.BooksChanged()
+	return author, nil
+}
+
+// DeleteAuthor deletes author id, who must not have any books left
+func (s *AuthorService) DeleteAuthor(id uint) error {
+	if _, err := s.find(id); err != nil {
+		return err
+	}
+	count, err := s.repo.CountBooks(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAuthorInUse
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.books.BooksChanged()
+	return nil
+}
+
+func (s *AuthorService) find(id uint) (*model.Author, error) {
+	author, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAuthorNotFound
+	}
+	return author, err
+}
+
+// availableName trims name and checks that no author other than id is
+// already called that, ignoring case
+func (s *AuthorService) availableName(name string, id uint) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrBlankAuthor
+	}
+	existing, err := s.repo.FindByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return name, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if existing.ID != id {
+		return "", ErrAuthorExists
+	}
+	return name, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"time"
+)
+
+var (
+	ErrBookLocked  = apperror.New("BOOK_LOCKED", "book is being edited by someone else")
+	ErrLockNotHeld = apperror.New("LOCK_NOT_HELD", "you do not hold the edit lock on this book")
+)
+
+// BookLockService hands out short-lived edit locks so two editors of one
+// book notice each other. The holder keeps a lock alive with heartbeats;
+// it lapses ttl after the last one.
+type BookLockService struct {
+	repo *repository.BookLockRepository
+	ttl  time.Duration
+}
+
+func NewBookLockService(repo *repository.BookLockRepository, ttl time.Duration) *BookLockService {
+	return &BookLockService{repo: repo, ttl: ttl}
+}
+
+// Acquire locks bookID for userID. When someone else holds it, their lock is
+// returned together with ErrBookLocked.
+func (s *BookLockService) Acquire(bookID, userID uint, holderName string) (*model.BookLock, error) {
+	now := time.Now()
+	lock := &model.BookLock{
+		BookID:     bookID,
+		HolderID:   userID,
+		HolderName: holderName,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(s.ttl),
+	}
+	acquired, err := s.repo.Acquire(lock)
+	if err != nil {
+		return nil, err
+	}
+	if acquired {
+		return lock, nil
+	}
+
+	current, err := s.repo.Find(bookID)
+	if err != nil {
+		return nil, err
+	}
+	return current, ErrBookLocked
+}
+
+// Heartbeat keeps userID's lock on bookID alive
+func (s *BookLockService) Heartbeat(bookID, userID uint) (*model.BookLock, error) {
+	extended, err := s.repo.Extend(bookID, userID, time.Now().Add(s.ttl))
+	if err != nil {
+		return nil, err
+	}
+	if !extended {
+		return nil, ErrLockNotHeld
+	}
+	return s.repo.Find(bookID)
+}
+
+func (s *BookLockService) Release(bookID, userID uint) error {
+	return s.repo.Release(bookID, userID)
+}
+
+// Current returns the unexpired lock on bookID, or nil
+func (s *BookLockService) Current(bookID uint) (*model.BookLock, error) {
+	lock, err := s.repo.Find(bookID)
+	if err != nil || lock == nil || time.Now().After(lock.ExpiresAt) {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// CheckEditable fails with ErrBookLocked when someone other than userID
+// holds an unexpired lock on bookID
+func (s *BookLockService) CheckEditable(bookID, userID uint) (*model.BookLock, error) {
+	lock, err := s.Current(bookID)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil && lock.HolderID != userID {
+		return lock, ErrBookLocked
+	}
+	return nil, nil
+}
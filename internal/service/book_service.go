@@ -1,34 +1,594 @@
 package service
 
 import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/cache"
 	"bms-go/internal/infra/repository"
 	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownCategory is returned for a book filed under a category id
+	// that does not exist
+	ErrUnknownCategory = apperror.New("UNKNOWN_CATEGORY", "category does not exist")
+	// ErrUnknownAuthor is returned for a book listing an author id that
+	// does not exist
+	ErrUnknownAuthor = apperror.New("UNKNOWN_AUTHOR", "author does not exist")
+	// ErrBookNotFound is returned for a book in a bulk delete that does
+	// not exist
+	ErrBookNotFound = apperror.New(apperror.CodeBookNotFound, "book not found")
+	// ErrBookHasLoans is returned for deleting a book while copies of it
+	// are still out on loan
+	ErrBookHasLoans = apperror.New("BOOK_HAS_LOANS", "book has copies out on loan; check them in first")
 )
 
 type BookService struct {
-	repo *repository.BookRepository
+	repo         BookRepository
+	categoryRepo *repository.CategoryRepository
+	authorRepo   *repository.AuthorRepository
+	synonyms     *SynonymService
+	rules        *ValidationRuleService
+	vocabulary   *Vocabulary
+	categories   *FacetList
+	authors      *FacetList
+	weights      dto.RelevanceWeights
+	responses    *cache.ResponseCache
+	// semantic is nil when semantic search is not configured
+	semantic *SemanticIndex
+}
+
+func NewBookService(repo BookRepository, categoryRepo *repository.CategoryRepository, authorRepo *repository.AuthorRepository, synonyms *SynonymService, rules *ValidationRuleService, weights dto.RelevanceWeights, responses *cache.ResponseCache, semantic *SemanticIndex) *BookService {
+	return &BookService{
+		repo:         repo,
+		categoryRepo: categoryRepo,
+		authorRepo:   authorRepo,
+		synonyms:     synonyms,
+		rules:        rules,
+		vocabulary:   NewVocabulary(repo.FindTitlesAndAuthors),
+		categories:   NewFacetList(repo.CountByCategory),
+		authors:      NewFacetList(repo.CountByAuthor),
+		weights:      weights,
+		responses:    responses,
+		semantic:     semantic,
+	}
 }
 
-func NewBookService(repo *repository.BookRepository) *BookService {
-	return &BookService{repo: repo}
+// prepareQuery fills in the search variants and relevance weights of query
+func (s *BookService) prepareQuery(query dto.BookQuery) (dto.BookQuery, error) {
+	if query.Search != "" {
+		variants, err := s.synonyms.Expand(query.Search)
+		if err != nil {
+			return query, err
+		}
+		query.SearchVariants = variants
+	}
+
+	query.Weights = s.weights
+	if len(query.RankingOverrides) > 0 {
+		weights, err := s.weights.Override(query.RankingOverrides)
+		if err != nil {
+			return query, err
+		}
+		query.Weights = weights
+	}
+	return query, nil
 }
 
-func (s *BookService) GetBooks(search, category string) ([]model.Book, error) {
-	return s.repo.FindAll(search, category)
+// GetBooks lists books matching query. A search without results carries a
+// spelling suggestion in the response metadata when one can be found.
+func (s *BookService) GetBooks(ctx context.Context, query dto.BookQuery) (*dto.BookListResponse, error) {
+	query, err := s.prepareQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	var books []model.Book
+	var scores []dto.BookScore
+	if query.SearchType == dto.SearchSemantic {
+		books, scores, err = s.findSemantic(ctx, query)
+	} else {
+		books, scores, err = s.repo.FindAll(ctx, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.BookListResponse{
+		Data:   books,
+		Scores: scores,
+		Meta: dto.BookListMeta{
+			Count:  len(books),
+			Limit:  query.Limit,
+			Offset: query.Offset,
+		},
+	}
+
+	if len(books) == 0 && query.Search != "" && query.Offset == 0 {
+		suggestion, err := s.vocabulary.Suggest(ctx, query.Search)
+		if err != nil {
+			return nil, err
+		}
+		resp.Meta.DidYouMean = suggestion
+	}
+	return resp, nil
 }
 
-func (s *BookService) GetBookByID(id uint) (*model.Book, error) {
-	return s.repo.FindByID(id)
+// GetBooksInPages passes every book matching query, from its offset on, to
+// fn pageSize books at a time in the query's order, so an unbounded list
+// never has to be held in memory whole. Semantic search is not supported.
+func (s *BookService) GetBooksInPages(ctx context.Context, query dto.BookQuery, pageSize int, fn func([]model.Book) error) error {
+	query, err := s.prepareQuery(query)
+	if err != nil {
+		return err
+	}
+	for offset := query.Offset; ; offset += pageSize {
+		page := query
+		page.Limit, page.Offset = pageSize, offset
+		books, _, err := s.repo.FindAll(ctx, page)
+		if err != nil {
+			return err
+		}
+		if len(books) > 0 {
+			if err := fn(books); err != nil {
+				return err
+			}
+		}
+		if len(books) < pageSize {
+			return nil
+		}
+	}
 }
 
-func (s *BookService) CreateBook(book *model.Book) error {
-	return s.repo.Create(book)
+// findSemantic ranks the books nearest in meaning to query.Search together
+// with the best keyword matches. Each gets a blend of its embedding
+// similarity and its keyword relevance relative to the best keyword match,
+// weighted by the semantic weight; the blended list is then paged.
+func (s *BookService) findSemantic(ctx context.Context, query dto.BookQuery) ([]model.Book, []dto.BookScore, error) {
+	if s.semantic == nil {
+		return nil, nil, ErrSemanticSearchDisabled
+	}
+	candidates := s.semantic.Candidates()
+
+	matches, similarity, err := s.semantic.Similarities(ctx, query.Search)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyword := query
+	keyword.SortBy, keyword.SortOrder = dto.SortByRelevance, dto.SortDesc
+	keyword.Limit, keyword.Offset, keyword.Explain = candidates, 0, true
+	keywordBooks, keywordScores, err := s.repo.FindAll(ctx, keyword)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nearBooks []model.Book
+	if len(matches) > 0 {
+		near := query
+		near.Search, near.SearchVariants = "", nil
+		near.IDs = make([]uint, len(matches))
+		for i, m := range matches {
+			near.IDs[i] = m.BookID
+		}
+		near.SortBy, near.SortOrder = dto.SortByID, dto.SortAsc
+		near.Limit, near.Offset = 0, 0
+		if nearBooks, _, err = s.repo.FindAll(ctx, near); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var best float64
+	relevance := make(map[uint]float64, len(keywordScores))
+	for _, score := range keywordScores {
+		relevance[score.BookID] = score.Total
+		best = max(best, score.Total)
+	}
+
+	type blended struct {
+		book  model.Book
+		score dto.BookScore
+	}
+	w := query.Weights.Semantic
+	seen := make(map[uint]bool)
+	var results []blended
+	for _, book := range append(keywordBooks, nearBooks...) {
+		if seen[book.ID] {
+			continue
+		}
+		seen[book.ID] = true
+
+		var keywordShare float64
+		if best > 0 {
+			keywordShare = (1 - w) * relevance[book.ID] / best
+		}
+		semanticShare := w * similarity(book.ID)
+		results = append(results, blended{book: book, score: dto.BookScore{
+			BookID:     book.ID,
+			Total:      semanticShare + keywordShare,
+			Components: map[string]float64{"semantic": semanticShare, "keyword": keywordShare},
+		}})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].score.Total, results[j].score.Total
+		if a == b {
+			return results[i].book.ID < results[j].book.ID
+		}
+		if query.SortOrder == dto.SortAsc {
+			return a < b
+		}
+		return a > b
+	})
+
+	results = results[min(query.Offset, len(results)):]
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	books := make([]model.Book, len(results))
+	var scores []dto.BookScore
+	for i, r := range results {
+		books[i] = r.book
+		if query.Explain {
+			scores = append(scores, r.score)
+		}
+	}
+	return books, scores, nil
 }
 
-func (s *BookService) UpdateBook(book *model.Book) error {
-	return s.repo.Update(book)
+// StreamBooks passes every book matching query's filters to fn in id order,
+// batchSize at a time. Returning an error from fn, or ctx being done, stops
+// the stream.
+func (s *BookService) StreamBooks(ctx context.Context, query dto.BookQuery, batchSize int, fn func([]model.Book) error) error {
+	if query.Search != "" {
+		variants, err := s.synonyms.Expand(query.Search)
+		if err != nil {
+			return err
+		}
+		query.SearchVariants = variants
+	}
+	return s.repo.FindInBatches(ctx, query, batchSize, fn)
+}
+
+func (s *BookService) GetBookByID(ctx context.Context, id uint) (*model.Book, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *BookService) GetRandomBook(ctx context.Context, category string, rating model.ContentRating) (*model.Book, error) {
+	return s.repo.FindRandom(ctx, category, rating)
+}
+
+// CompareBooks returns the books in ids in the requested order, noting ids
+// without a book, and the fields whose values differ between them
+func (s *BookService) CompareBooks(ctx context.Context, ids []uint) (*dto.BookComparison, error) {
+	books, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]model.Book, len(books))
+	for _, b := range books {
+		byID[b.ID] = b
+	}
+
+	comparison := &dto.BookComparison{
+		Books:       []dto.BookResponse{},
+		MissingIDs:  []uint{},
+		Differences: []dto.FieldDifference{},
+	}
+	var found []model.Book
+	for _, id := range ids {
+		book, ok := byID[id]
+		if !ok {
+			comparison.MissingIDs = append(comparison.MissingIDs, id)
+			continue
+		}
+		found = append(found, book)
+		comparison.Books = append(comparison.Books, toBookResponse(book))
+	}
+
+	if len(found) < 2 {
+		return comparison, nil
+	}
+
+	textFields := []struct {
+		name  string
+		value func(model.Book) string
+	}{
+		{"author", func(b model.Book) string { return b.Author }},
+		{"category", func(b model.Book) string { return b.Category }},
+	}
+	for _, f := range textFields {
+		values := make(map[uint]interface{}, len(found))
+		distinct := make(map[string]bool)
+		for _, b := range found {
+			values[b.ID] = f.value(b)
+			distinct[f.value(b)] = true
+		}
+		if len(distinct) > 1 {
+			comparison.Differences = append(comparison.Differences, dto.FieldDifference{Field: f.name, Values: values})
+		}
+	}
+
+	numericFields := []struct {
+		name  string
+		value func(model.Book) int
+	}{
+		{"published_year", func(b model.Book) int { return b.PublishedYear }},
+		{"pages", func(b model.Book) int { return b.Pages }},
+	}
+	for _, f := range numericFields {
+		values := make(map[uint]interface{}, len(found))
+		lo, hi := f.value(found[0]), f.value(found[0])
+		for _, b := range found {
+			v := f.value(b)
+			values[b.ID] = v
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if lo != hi {
+			spread := hi - lo
+			comparison.Differences = append(comparison.Differences, dto.FieldDifference{Field: f.name, Values: values, Spread: &spread})
+		}
+	}
+
+	return comparison, nil
+}
+
+// CheckRules checks book against the configured validation rules; see
+// ValidationRuleService.Check
+func (s *BookService) CheckRules(book model.Book) error {
+	return s.rules.Check(book)
+}
+
+// resolveCategory fills in the name of a book filed by category id alone,
+// so validation rules see it. A book filed by name gets its category, new
+// or existing, when it is written.
+func (s *BookService) resolveCategory(book *model.Book) error {
+	if strings.TrimSpace(book.Category) != "" || book.CategoryID == nil {
+		return nil
+	}
+	category, err := s.categoryRepo.FindByID(*book.CategoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUnknownCategory
+	}
+	if err != nil {
+		return err
+	}
+	book.Category = category.Name
+	return nil
+}
+
+// resolveAuthors fills in the names of authors listed by id alone and sets
+// Author to match a book's listed authors, so validation rules see them
+func (s *BookService) resolveAuthors(book *model.Book) error {
+	var ids []uint
+	for _, author := range book.Authors {
+		if author.ID != 0 {
+			ids = append(ids, author.ID)
+		}
+	}
+	if len(ids) > 0 {
+		found, err := s.authorRepo.FindByIDs(ids)
+		if err != nil {
+			return err
+		}
+		names := make(map[uint]string, len(found))
+		for _, author := range found {
+			names[author.ID] = author.Name
+		}
+		for i, author := range book.Authors {
+			name, ok := names[author.ID]
+			if author.ID != 0 && !ok {
+				return ErrUnknownAuthor
+			}
+			if ok {
+				book.Authors[i].Name = name
+			}
+		}
+	}
+
+	names := make([]string, 0, len(book.Authors))
+	for _, author := range book.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 && strings.TrimSpace(book.Author) == "" {
+		book.Author = strings.Join(names, ", ")
+	}
+	return nil
+}
+
+func (s *BookService) CreateBook(ctx context.Context, book *model.Book) error {
+	if err := s.resolveCategory(book); err != nil {
+		return err
+	}
+	if err := s.resolveAuthors(book); err != nil {
+		return err
+	}
+	if err := s.rules.Check(*book); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, book); err != nil {
+		return err
+	}
+	s.BooksChanged()
+	return nil
+}
+
+// CreateBooks creates books in one transaction, each checked as CreateBook
+// checks it. Books that fail are left out; errs[i] is why books[i] failed,
+// or nil when it was created.
+func (s *BookService) CreateBooks(ctx context.Context, books []model.Book) ([]error, error) {
+	errs := make([]error, len(books))
+	var valid []model.Book
+	var positions []int
+	for i := range books {
+		book := &books[i]
+		err := s.resolveCategory(book)
+		if err == nil {
+			err = s.resolveAuthors(book)
+		}
+		if err == nil {
+			err = s.rules.Check(*book)
+		}
+		var violation *RuleViolationError
+		switch {
+		case err == nil:
+			valid = append(valid, *book)
+			positions = append(positions, i)
+		case errors.Is(err, ErrUnknownCategory), errors.Is(err, ErrUnknownAuthor), errors.As(err, &violation):
+			errs[i] = err
+		default:
+			return nil, err
+		}
+	}
+	if len(valid) == 0 {
+		return errs, nil
+	}
+
+	created, err := s.repo.CreateMany(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range positions {
+		books[i] = valid[j]
+		errs[i] = created[j]
+	}
+	s.BooksChanged()
+	return errs, nil
+}
+
+func (s *BookService) UpdateBook(ctx context.Context, book *model.Book) error {
+	if err := s.resolveCategory(book); err != nil {
+		return err
+	}
+	if err := s.resolveAuthors(book); err != nil {
+		return err
+	}
+	if err := s.rules.Check(*book); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, book); err != nil {
+		return err
+	}
+	s.BooksChanged()
+	return nil
+}
+
+// BooksChanged drops cached search data and book responses. It is called
+// after every book write, including writes made outside BookService.
+func (s *BookService) BooksChanged() {
+	s.vocabulary.Invalidate()
+	s.categories.Invalidate()
+	s.authors.Invalidate()
+	s.responses.Invalidate(cache.TagBooks)
+}
+
+// WarmSearch rebuilds the spelling vocabulary used for search suggestions
+func (s *BookService) WarmSearch(ctx context.Context) error {
+	return s.vocabulary.Rebuild(ctx)
+}
+
+// WarmSemantic embeds the books whose embeddings are missing or outdated.
+// It does nothing when semantic search is off.
+func (s *BookService) WarmSemantic(ctx context.Context) error {
+	if s.semantic == nil {
+		return nil
+	}
+	_, err := s.semantic.Refresh(ctx)
+	return err
+}
+
+// WarmFacets rebuilds the cached category and author lists
+func (s *BookService) WarmFacets(ctx context.Context) error {
+	if err := s.categories.Rebuild(ctx); err != nil {
+		return err
+	}
+	return s.authors.Rebuild(ctx)
+}
+
+// GetCategories lists every category with its number of books
+func (s *BookService) GetCategories(ctx context.Context) ([]dto.FacetCount, error) {
+	return s.categories.Get(ctx)
+}
+
+// GetAuthors lists every author with their number of books
+func (s *BookService) GetAuthors(ctx context.Context) ([]dto.FacetCount, error) {
+	return s.authors.Get(ctx)
+}
+
+// DeleteBook deletes book id. Books with copies out on loan are kept, with
+// ErrBookHasLoans, until those loans are returned.
+func (s *BookService) DeleteBook(ctx context.Context, id uint) error {
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, repository.ErrBookHasLoans) {
+		return ErrBookHasLoans
+	}
+	if err != nil {
+		return err
+	}
+	s.BooksChanged()
+	return nil
+}
+
+// DeleteBooks deletes books ids in one transaction. errs[i] is why ids[i]
+// was not deleted, ErrBookNotFound when there is no such book,
+// ErrBookHasLoans when copies of it are out on loan, or nil.
+func (s *BookService) DeleteBooks(ctx context.Context, ids []uint) ([]error, error) {
+	errs, err := s.repo.DeleteMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	deleted := false
+	for i, failed := range errs {
+		switch {
+		case errors.Is(failed, gorm.ErrRecordNotFound):
+			errs[i] = ErrBookNotFound
+		case errors.Is(failed, repository.ErrBookHasLoans):
+			errs[i] = ErrBookHasLoans
+		}
+		deleted = deleted || failed == nil
+	}
+	if deleted {
+		s.BooksChanged()
+	}
+	return errs, nil
+}
+
+// readingSpeed estimates BookResponse.ReadingMinutes
+var readingSpeed = dto.DefaultReadingSpeed
+
+// SetReadingSpeed replaces the speed used for reading time estimates. It is
+// meant to be called once at startup.
+func SetReadingSpeed(speed dto.ReadingSpeed) {
+	readingSpeed = speed
 }
 
-func (s *BookService) DeleteBook(id uint) error {
-	return s.repo.Delete(id)
+func toBookResponse(book model.Book) dto.BookResponse {
+	return dto.BookResponse{
+		ID:                   book.ID,
+		Title:                book.Title,
+		Author:               book.Author,
+		Category:             book.Category,
+		Description:          book.Description,
+		PublishedYear:        book.PublishedYear,
+		Pages:                book.Pages,
+		CoverURL:             book.CoverURL,
+		ContentRating:        book.ContentRating,
+		MediaType:            book.MediaType,
+		Narrator:             book.Narrator,
+		DurationMinutes:      book.DurationMinutes,
+		ReadingMinutes:       readingSpeed.Minutes(book),
+		Accessibility:        book.Accessibility,
+		HasSample:            book.HasSample,
+		AverageRating:        book.AverageRating,
+		RatingCount:          book.RatingCount,
+		DescriptionGenerated: book.DescriptionGenerated,
+	}
 }
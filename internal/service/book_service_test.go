@@ -0,0 +1,56 @@
+package service
+
+import (
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBookServiceDeleteBookWithLoans(t *testing.T) {
+	store := &fakeBookStore{
+		fakeBooks: newFakeBooks(newBook(1, "Dune", "Frank Herbert", 1965)),
+		onLoan:    map[uint]bool{1: true},
+	}
+	s := NewBookService(store, nil, nil, nil, nil, dto.RelevanceWeights{}, nil, nil)
+
+	if err := s.DeleteBook(context.Background(), 1); !errors.Is(err, ErrBookHasLoans) {
+		t.Fatalf("DeleteBook with a copy on loan error = %v, want %v", err, ErrBookHasLoans)
+	}
+	if _, ok := store.books[1]; !ok {
+		t.Error("book with a copy on loan was deleted")
+	}
+
+	store.onLoan[1] = false
+	if err := s.DeleteBook(context.Background(), 1); err != nil {
+		t.Fatalf("DeleteBook once returned error = %v", err)
+	}
+	if _, ok := store.books[1]; ok {
+		t.Error("book was not deleted once its copies were returned")
+	}
+}
+
+func TestBookServiceDeleteBooks(t *testing.T) {
+	store := &fakeBookStore{
+		fakeBooks: newFakeBooks(
+			newBook(1, "Dune", "Frank Herbert", 1965),
+			newBook(2, "Emma", "Jane Austen", 1815),
+		),
+		onLoan: map[uint]bool{2: true},
+	}
+	s := NewBookService(store, nil, nil, nil, nil, dto.RelevanceWeights{}, nil, nil)
+
+	errs, err := s.DeleteBooks(context.Background(), []uint{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []error{nil, ErrBookHasLoans, ErrBookNotFound}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Errorf("errs[%d] = %v, want %v", i, errs[i], want[i])
+		}
+	}
+	if _, ok := store.books[2]; !ok {
+		t.Error("book with a copy on loan was deleted")
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bookColumn is one column of a spreadsheet-style book table. Imports look
+// columns up by name and exports use the same names as headers, so an export
+// can be imported again without a column mapping.
+type bookColumn struct {
+	name     string
+	numeric  bool
+	required bool
+	get      func(model.Book) interface{}
+	set      func(*dto.BookRequest, string) error
+}
+
+var bookColumns = []bookColumn{
+	{name: "title", required: true,
+		get: func(b model.Book) interface{} { return b.Title },
+		set: func(r *dto.BookRequest, v string) error { r.Title = v; return nil }},
+	{name: "author", required: true,
+		get: func(b model.Book) interface{} { return b.Author },
+		set: func(r *dto.BookRequest, v string) error { r.Author = v; return nil }},
+	{name: "category", required: true,
+		get: func(b model.Book) interface{} { return b.Category },
+		set: func(r *dto.BookRequest, v string) error { r.Category = v; return nil }},
+	{name: "description",
+		get: func(b model.Book) interface{} { return b.Description },
+		set: func(r *dto.BookRequest, v string) error { r.Description = v; return nil }},
+	{name: "published_year", numeric: true,
+		get: func(b model.Book) interface{} { return b.PublishedYear },
+		set: func(r *dto.BookRequest, v string) error { return parseTableInt(v, &r.PublishedYear) }},
+	{name: "pages", numeric: true,
+		get: func(b model.Book) interface{} { return b.Pages },
+		set: func(r *dto.BookRequest, v string) error { return parseTableInt(v, &r.Pages) }},
+	{name: "cover_url",
+		get: func(b model.Book) interface{} { return b.CoverURL },
+		set: func(r *dto.BookRequest, v string) error { r.CoverURL = v; return nil }},
+}
+
+// BookColumnNames lists the column names accepted by imports and written by
+// exports
+func BookColumnNames() []string {
+	names := make([]string, len(bookColumns))
+	for i, col := range bookColumns {
+		names[i] = col.name
+	}
+	return names
+}
+
+// parseTableInt reads a whole number cell. Spreadsheets often store numbers
+// as floats, so "1999.0" is accepted; empty cells leave dst at zero.
+func parseTableInt(v string, dst *int) error {
+	if v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f != float64(int(f)) {
+		return fmt.Errorf("%q is not a whole number", v)
+	}
+	*dst = int(f)
+	return nil
+}
+
+// mapBookRows turns a table whose first row is the header into book rows.
+// mapping renames columns: it maps a book column name to the header used in
+// the file. Headers are matched case-insensitively and blank rows are dropped.
+func mapBookRows(records [][]string, mapping map[string]string) ([]dto.BookRequest, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the file is empty")
+	}
+
+	positions := make(map[string]int, len(records[0]))
+	for i, header := range records[0] {
+		header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if _, ok := positions[header]; !ok {
+			positions[header] = i
+		}
+	}
+
+	index := make([]int, len(bookColumns))
+	for i, col := range bookColumns {
+		header := col.name
+		if mapped, ok := mapping[col.name]; ok {
+			header = mapped
+		}
+		pos, ok := positions[strings.ToLower(strings.TrimSpace(header))]
+		if !ok {
+			if col.required {
+				return nil, fmt.Errorf("missing required column %q", header)
+			}
+			pos = -1
+		}
+		index[i] = pos
+	}
+
+	rows := make([]dto.BookRequest, 0, len(records)-1)
+	for n, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		var row dto.BookRequest
+		for i, col := range bookColumns {
+			if index[i] < 0 || index[i] >= len(record) {
+				continue
+			}
+			if err := col.set(&row, strings.TrimSpace(record[index[i]])); err != nil {
+				// n+2 accounts for the header and 1-based line numbers
+				return nil, fmt.Errorf("line %d, %s: %w", n+2, col.name, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bms-go/internal/infra/remote"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// catalogRefreshSource names the catalog refresh as the source of the
+// change requests it queues
+const catalogRefreshSource = "catalog_refresh"
+
+var (
+	// catalogRefreshApplied, catalogRefreshQueued and catalogRefreshFailed
+	// count books updated, conflicts queued for review and failed fetches,
+	// exposed through expvar at /debug/vars
+	catalogRefreshApplied = expvar.NewInt("catalog_refresh_applied")
+	catalogRefreshQueued  = expvar.NewInt("catalog_refresh_queued")
+	catalogRefreshFailed  = expvar.NewInt("catalog_refresh_failed")
+)
+
+// refreshField is a catalog field kept in step with upstream: how to read
+// it from a book and how to propose a book's value as a change
+type refreshField struct {
+	name   string
+	get    func(model.Book) interface{}
+	change func(*model.BookChanges, model.Book)
+}
+
+var refreshFields = []refreshField{
+	{"title", func(b model.Book) interface{} { return b.Title }, func(c *model.BookChanges, b model.Book) { c.Title = &b.Title }},
+	{"author", func(b model.Book) interface{} { return b.Author }, func(c *model.BookChanges, b model.Book) { c.Author = &b.Author }},
+	{"category", func(b model.Book) interface{} { return b.Category }, func(c *model.BookChanges, b model.Book) { c.Category = &b.Category }},
+	{"description", func(b model.Book) interface{} { return b.Description }, func(c *model.BookChanges, b model.Book) { c.Description = &b.Description }},
+	{"published_year", func(b model.Book) interface{} { return b.PublishedYear }, func(c *model.BookChanges, b model.Book) { c.PublishedYear = &b.PublishedYear }},
+	{"pages", func(b model.Book) interface{} { return b.Pages }, func(c *model.BookChanges, b model.Book) { c.Pages = &b.Pages }},
+	{"cover_url", func(b model.Book) interface{} { return b.CoverURL }, func(c *model.BookChanges, b model.Book) { c.CoverURL = &b.CoverURL }},
+}
+
+// catalogSnapshot records every refreshed field of book
+func catalogSnapshot(book model.Book) model.BookChanges {
+	var snapshot model.BookChanges
+	for _, f := range refreshFields {
+		f.change(&snapshot, book)
+	}
+	return snapshot
+}
+
+// CatalogRefreshService keeps books pulled from other deployments up to
+// date. Each book's upstream record is fetched again and compared with the
+// snapshot taken when it was last fetched: fields changed upstream but not
+// here are applied, and fields changed on both sides are queued as a
+// machine generated change request for a reviewer to decide.
+type CatalogRefreshService struct {
+	upstreams      *repository.UpstreamRepository
+	bookRepo       BookReader
+	books          *BookService
+	changeRequests *repository.ChangeRequestRepository
+	client         *httpclient.Client
+	apiKey         string
+	batchSize      int
+}
+
+func NewCatalogRefreshService(upstreams *repository.UpstreamRepository, bookRepo BookReader, books *BookService, changeRequests *repository.ChangeRequestRepository, client *httpclient.Client, apiKey string, batchSize int) *CatalogRefreshService {
+	return &CatalogRefreshService{
+		upstreams:      upstreams,
+		bookRepo:       bookRepo,
+		books:          books,
+		changeRequests: changeRequests,
+		client:         client,
+		apiKey:         apiKey,
+		batchSize:      batchSize,
+	}
+}
+
+// Refresh checks up to one batch of upstream books, least recently fetched
+// first. A book whose fetch fails is tried again once the others have had
+// their turn.
+func (s *CatalogRefreshService) Refresh(ctx context.Context) (*dto.CatalogRefreshResult, error) {
+	records, err := s.upstreams.FindStale(s.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.CatalogRefreshResult{}
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		applied, queued, err := s.refresh(ctx, &record)
+		if err != nil {
+			catalogRefreshFailed.Add(1)
+			log.Printf("Catalog refresh failed for book %d: %v", record.BookID, err)
+			result.Failed++
+			record.FetchedAt = time.Now()
+			if err := s.upstreams.Save(&record); err != nil {
+				return result, err
+			}
+			continue
+		}
+		result.Checked++
+		if applied {
+			catalogRefreshApplied.Add(1)
+			result.Updated++
+		}
+		if queued {
+			catalogRefreshQueued.Add(1)
+			result.Queued++
+		}
+	}
+	return result, nil
+}
+
+// refresh fetches record's book upstream and merges it into the local book.
+// It reports whether the local book was updated and whether conflicting
+// changes were queued for review.
+func (s *CatalogRefreshService) refresh(ctx context.Context, record *model.UpstreamRecord) (applied, queued bool, err error) {
+	upstream, err := remote.NewCatalog(record.BaseURL, s.apiKey, s.client).WithContext(ctx).Book(record.RemoteID)
+	if err != nil {
+		return false, false, err
+	}
+	local, err := s.bookRepo.FindByID(ctx, record.BookID)
+	if err != nil {
+		return false, false, err
+	}
+	var base model.Book
+	applyBookChanges(&base, record.Snapshot)
+
+	var clean, conflicting []refreshField
+	for _, f := range refreshFields {
+		theirs, ours, was := f.get(*upstream), f.get(*local), f.get(base)
+		switch {
+		case theirs == was || theirs == ours:
+			// Unchanged upstream, or already the same here
+		case ours == was:
+			clean = append(clean, f)
+		default:
+			conflicting = append(conflicting, f)
+		}
+	}
+
+	if len(clean) > 0 {
+		updated := *local
+		var changes model.BookChanges
+		for _, f := range clean {
+			f.change(&changes, *upstream)
+		}
+		applyBookChanges(&updated, changes)
+		err := s.books.UpdateBook(ctx, &updated)
+		var violation *RuleViolationError
+		switch {
+		case errors.As(err, &violation):
+			// Leave changes the validation rules reject to a reviewer too
+			conflicting = append(conflicting, clean...)
+		case err != nil:
+			return false, false, err
+		default:
+			applied = true
+		}
+	}
+
+	if len(conflicting) > 0 {
+		cr := model.ChangeRequest{
+			BookID:           record.BookID,
+			Status:           model.ChangePending,
+			MachineGenerated: true,
+			Source:           catalogRefreshSource,
+		}
+		names := make([]string, len(conflicting))
+		for i, f := range conflicting {
+			f.change(&cr.Changes, *upstream)
+			names[i] = f.name
+		}
+		cr.Comment = fmt.Sprintf("Changed at %s/books/%d since the last refresh: %s", record.BaseURL, record.RemoteID, strings.Join(names, ", "))
+		if err := s.changeRequests.Create(&cr); err != nil {
+			return applied, false, err
+		}
+		queued = true
+	}
+
+	record.Snapshot = catalogSnapshot(*upstream)
+	record.FetchedAt = time.Now()
+	return applied, queued, s.upstreams.Save(record)
+}
+
+// Run refreshes a batch every interval until ctx is cancelled
+func (s *CatalogRefreshService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Refresh(ctx)
+			if err != nil {
+				log.Printf("Catalog refresh failed: %v", err)
+				continue
+			}
+			if result.Updated > 0 || result.Queued > 0 {
+				log.Printf("Catalog refresh updated %d books and queued %d for review", result.Updated, result.Queued)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model/dto"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenMetricsContentType is the content type of WriteOpenMetrics' output
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+var ErrStatsNotReady = apperror.New("STATS_NOT_READY", "catalog statistics have not been collected yet")
+
+// CatalogStatsService collects the catalog's business figures on a schedule
+// and keeps the last snapshot in memory, so scrapers read gauges without
+// touching the database.
+type CatalogStatsService struct {
+	repo *repository.StatsRepository
+
+	mu    sync.RWMutex
+	stats *dto.CatalogStats
+}
+
+func NewCatalogStatsService(repo *repository.StatsRepository) *CatalogStatsService {
+	return &CatalogStatsService{repo: repo}
+}
+
+// Refresh counts a new snapshot and replaces the cached one. A failed count
+// keeps the previous snapshot.
+func (s *CatalogStatsService) Refresh() error {
+	start := time.Now()
+	stats, err := s.repo.CountCatalog()
+	if err != nil {
+		return err
+	}
+	stats.RefreshedAt = start
+	stats.RefreshDuration = time.Since(start)
+
+	s.mu.Lock()
+	s.stats = stats
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the snapshot immediately and then every interval until ctx
+// is cancelled
+func (s *CatalogStatsService) Run(ctx context.Context, interval time.Duration) {
+	if err := s.Refresh(); err != nil {
+		log.Printf("Catalog statistics refresh failed: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(); err != nil {
+				log.Printf("Catalog statistics refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// GetStats returns the last collected snapshot
+func (s *CatalogStatsService) GetStats() (*dto.CatalogStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.stats == nil {
+		return nil, ErrStatsNotReady
+	}
+	return s.stats, nil
+}
+
+// WriteOpenMetrics writes the last snapshot as OpenMetrics gauges
+func (s *CatalogStatsService) WriteOpenMetrics(w io.Writer) error {
+	stats, err := s.GetStats()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	writeGauges(&b, "bms_books", "Books in the catalog by media type.", "media_type", stats.BooksByMediaType)
+	writeGauge(&b, "bms_favorites", "Favorites across all users.", float64(stats.Favorites))
+	writeGauge(&b, "bms_users", "Registered users.", float64(stats.Users))
+	writeGauges(&b, "bms_ill_requests", "Inter-library loan requests by status.", "status", stats.ILLRequestsByStatus)
+	writeGauges(&b, "bms_event_rsvps", "Event RSVPs by status.", "status", stats.RSVPsByStatus)
+	writeGauge(&b, "bms_change_requests_pending", "Change requests awaiting review.", float64(stats.PendingChangeRequests))
+	writeGauge(&b, "bms_catalog_stats_refreshed_timestamp_seconds", "When the catalog statistics were collected.", float64(stats.RefreshedAt.UnixNano())/1e9)
+	writeGauge(&b, "bms_catalog_stats_refresh_duration_seconds", "How long collecting the catalog statistics took.", stats.RefreshDuration.Seconds())
+	b.WriteString("# EOF\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeGauge(b *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(b, "# TYPE %s gauge\n# HELP %s %s\n%s %s\n", name, name, help, name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// writeGauges writes one sample per key, in key order so scrapes are stable
+func writeGauges(b *strings.Builder, name, help, label string, values map[string]int64) {
+	fmt.Fprintf(b, "# TYPE %s gauge\n# HELP %s %s\n", name, name, help)
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s{%s=%q} %d\n", name, label, k, values[k])
+	}
+}
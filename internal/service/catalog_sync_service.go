@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// syncPageSize is how many books are read from a catalog per page
+const syncPageSize = 100
+
+// Catalog is one side of a catalog sync: this instance's database or another
+// deployment reached over its API
+type Catalog interface {
+	// BaseURL is where other deployments reach the catalog, empty for this
+	// instance's own
+	BaseURL() string
+	Books() ([]model.Book, error)
+	// CreateBook adds book. upstream, when not nil, is the record the book
+	// was copied from in another deployment.
+	CreateBook(book *model.Book, upstream *model.UpstreamRecord) error
+	UpdateBook(book *model.Book) error
+}
+
+// LocalCatalog is this instance's catalog. Writes go through BookService so
+// search caches stay in step.
+type LocalCatalog struct {
+	repo      BookReader
+	upstreams *repository.UpstreamRepository
+	books     *BookService
+	ctx       context.Context
+}
+
+func NewLocalCatalog(repo BookReader, upstreams *repository.UpstreamRepository, books *BookService) *LocalCatalog {
+	return &LocalCatalog{repo: repo, upstreams: upstreams, books: books, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the catalog whose reads and writes are
+// bound to ctx, so they end with the request they are made for
+func (l *LocalCatalog) WithContext(ctx context.Context) *LocalCatalog {
+	bound := *l
+	bound.ctx = ctx
+	return &bound
+}
+
+func (l *LocalCatalog) BaseURL() string { return "" }
+
+func (l *LocalCatalog) Books() ([]model.Book, error) {
+	var all []model.Book
+	for offset := 0; ; offset += syncPageSize {
+		page, err := l.repo.FindPageByID(l.ctx, offset, syncPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < syncPageSize {
+			return all, nil
+		}
+	}
+}
+
+// CreateBook adds book. A book copied from another deployment is marked as
+// coming from upstream, which is recorded so the book can be refreshed.
+func (l *LocalCatalog) CreateBook(book *model.Book, upstream *model.UpstreamRecord) error {
+	if upstream == nil {
+		return l.books.CreateBook(l.ctx, book)
+	}
+	book.Source = model.SourceUpstream
+	if err := l.books.CreateBook(l.ctx, book); err != nil {
+		return err
+	}
+	upstream.BookID = book.ID
+	upstream.Snapshot = catalogSnapshot(*book)
+	upstream.FetchedAt = time.Now()
+	return l.upstreams.Create(upstream)
+}
+
+func (l *LocalCatalog) UpdateBook(book *model.Book) error { return l.books.UpdateBook(l.ctx, book) }
+
+// SyncCatalogs pushes books missing from or outdated in target. Books are
+// matched by normalized title and author since the catalog has no ISBN or
+// other shared identifier. Books created in this instance's catalog from
+// another deployment's are recorded as upstream books. A target record edited after the source record,
+// or several target records sharing one key, is reported as a conflict and
+// left untouched. With dryRun nothing is written.
+func SyncCatalogs(source, target Catalog, dryRun bool) (*dto.CatalogSyncReport, error) {
+	sourceBooks, err := source.Books()
+	if err != nil {
+		return nil, fmt.Errorf("read source catalog: %w", err)
+	}
+	targetBooks, err := target.Books()
+	if err != nil {
+		return nil, fmt.Errorf("read target catalog: %w", err)
+	}
+
+	byKey := make(map[string][]model.Book, len(targetBooks))
+	for _, b := range targetBooks {
+		key := catalogKey(b)
+		byKey[key] = append(byKey[key], b)
+	}
+
+	report := &dto.CatalogSyncReport{
+		DryRun:    dryRun,
+		Created:   []dto.CatalogSyncItem{},
+		Updated:   []dto.CatalogSyncItem{},
+		Conflicts: []dto.CatalogSyncConflict{},
+	}
+	for _, src := range sourceBooks {
+		matches := byKey[catalogKey(src)]
+
+		switch {
+		case len(matches) == 0:
+			book := copyBookContent(src)
+			var upstream *model.UpstreamRecord
+			if source.BaseURL() != "" {
+				upstream = &model.UpstreamRecord{BaseURL: source.BaseURL(), RemoteID: src.ID}
+			}
+			if !dryRun {
+				if err := target.CreateBook(&book, upstream); err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("create %q: %v", src.Title, err))
+					continue
+				}
+			}
+			report.Created = append(report.Created, dto.CatalogSyncItem{
+				SourceID: src.ID, TargetID: book.ID, Title: src.Title, Author: src.Author,
+			})
+
+		case len(matches) > 1:
+			ids := make([]uint, len(matches))
+			for i, m := range matches {
+				ids[i] = m.ID
+			}
+			report.Conflicts = append(report.Conflicts, dto.CatalogSyncConflict{
+				SourceID: src.ID, TargetIDs: ids, Title: src.Title, Author: src.Author,
+				Reason: "several target books share this title and author",
+			})
+
+		default:
+			dst := matches[0]
+			fields := changedBookFields(src, dst)
+			if len(fields) == 0 {
+				report.Unchanged++
+				continue
+			}
+			if dst.UpdatedAt.After(src.UpdatedAt) {
+				report.Conflicts = append(report.Conflicts, dto.CatalogSyncConflict{
+					SourceID: src.ID, TargetIDs: []uint{dst.ID}, Title: src.Title, Author: src.Author,
+					Reason: "target was changed after source: " + strings.Join(fields, ", "),
+				})
+				continue
+			}
+
+			book := copyBookContent(src)
+			book.ID = dst.ID
+			book.CreatedAt = dst.CreatedAt
+			if !dryRun {
+				if err := target.UpdateBook(&book); err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("update %q: %v", src.Title, err))
+					continue
+				}
+			}
+			report.Updated = append(report.Updated, dto.CatalogSyncItem{
+				SourceID: src.ID, TargetID: dst.ID, Title: src.Title, Author: src.Author, Fields: fields,
+			})
+		}
+	}
+	return report, nil
+}
+
+func catalogKey(b model.Book) string {
+	return strings.ToLower(strings.Join(strings.Fields(b.Title), " ")) + "\x00" +
+		strings.ToLower(strings.Join(strings.Fields(b.Author), " "))
+}
+
+// copyBookContent returns b's catalog fields without its identity, so it can
+// be written to another instance
+func copyBookContent(b model.Book) model.Book {
+	book := model.Book{
+		Title:                b.Title,
+		Author:               b.Author,
+		Category:             b.Category,
+		Description:          b.Description,
+		PublishedYear:        b.PublishedYear,
+		Pages:                b.Pages,
+		CoverURL:             b.CoverURL,
+		ContentRating:        b.ContentRating,
+		MediaType:            b.MediaType,
+		Narrator:             b.Narrator,
+		DurationMinutes:      b.DurationMinutes,
+		Accessibility:        b.Accessibility,
+		DescriptionGenerated: b.DescriptionGenerated,
+	}
+	// Deployments from before media types send none
+	if book.MediaType == "" {
+		book.MediaType = model.MediaPrint
+	}
+	return book
+}
+
+// changedBookFields lists the catalog fields that differ between a and b
+func changedBookFields(a, b model.Book) []string {
+	var fields []string
+	if a.Title != b.Title {
+		fields = append(fields, "title")
+	}
+	if a.Author != b.Author {
+		fields = append(fields, "author")
+	}
+	if a.Category != b.Category {
+		fields = append(fields, "category")
+	}
+	if a.Description != b.Description {
+		fields = append(fields, "description")
+	}
+	if a.DescriptionGenerated != b.DescriptionGenerated {
+		fields = append(fields, "description_generated")
+	}
+	if a.PublishedYear != b.PublishedYear {
+		fields = append(fields, "published_year")
+	}
+	if a.Pages != b.Pages {
+		fields = append(fields, "pages")
+	}
+	if a.CoverURL != b.CoverURL {
+		fields = append(fields, "cover_url")
+	}
+	if a.ContentRating != b.ContentRating {
+		fields = append(fields, "content_rating")
+	}
+	if a.MediaType != b.MediaType && a.MediaType != "" {
+		fields = append(fields, "media_type")
+	}
+	if a.Narrator != b.Narrator {
+		fields = append(fields, "narrator")
+	}
+	if a.DurationMinutes != b.DurationMinutes {
+		fields = append(fields, "duration_minutes")
+	}
+	if a.Accessibility != b.Accessibility {
+		fields = append(fields, "accessibility")
+	}
+	return fields
+}
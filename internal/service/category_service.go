@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound = apperror.New("CATEGORY_NOT_FOUND", "category not found")
+	ErrCategoryExists   = apperror.New("CATEGORY_EXISTS", "a category with this name already exists")
+	ErrCategoryInUse    = apperror.New("CATEGORY_IN_USE", "category still has books")
+	ErrBlankCategory    = apperror.New("BLANK_CATEGORY", "category must not be blank")
+	ErrSameCategory     = apperror.New("SAME_CATEGORY", "target category must differ from the categories moved")
+)
+
+// CategoryService manages the categories books are filed under. Every
+// change to a category's books drops the cached book data through
+// BookService.BooksChanged.
+type CategoryService struct {
+	repo     *repository.CategoryRepository
+	bookRepo BookReader
+	books    *BookService
+}
+
+func NewCategoryService(repo *repository.CategoryRepository, bookRepo BookReader, books *BookService) *CategoryService {
+	return &CategoryService{repo: repo, bookRepo: bookRepo, books: books}
+}
+
+// GetCategories lists every category by name with its number of books
+func (s *CategoryService) GetCategories() ([]dto.CategoryResponse, error) {
+	categories, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		categories = []dto.CategoryResponse{}
+	}
+	return categories, nil
+}
+
+func (s *CategoryService) GetCategory(id uint) (*model.Category, error) {
+	return s.find(id)
+}
+
+// GetBooks returns a page of the books in category id, by title, only
+// those with rating when it is set
+func (s *CategoryService) GetBooks(ctx context.Context, id uint, rating model.ContentRating, limit, offset int) (*dto.CategoryBookListResponse, error) {
+	category, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	books, total, err := s.bookRepo.FindByCategory(ctx, id, rating, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CategoryBookListResponse{
+		Category: *category,
+		Data:     books,
+		Meta: dto.CategoryBookListMeta{
+			Count:  len(books),
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}, nil
+}
+
+func (s *CategoryService) CreateCategory(req dto.CategoryRequest) (*model.Category, error) {
+	name, err := s.availableName(req.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	category := model.Category{Name: name}
+	if err := s.repo.Create(&category); err != nil {
+		return nil, err
+	}
+	s.books.BooksChanged()
+	return &category, nil
+}
+
+// UpdateCategory renames category id, relabelling its books
+func (s *CategoryService) UpdateCategory(id uint, req dto.CategoryRequest) (*model.Category, error) {
+	category, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.availableName(req.Name, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.Rename(category, name); err != nil {
+		return nil, err
+	}
+	s.books.BooksChanged()
+	return category, nil
+}
+
+// DeleteCategory deletes category id, which must not have any books left
+func (s *CategoryService) DeleteCategory(id uint) error {
+	if _, err := s.find(id); err != nil {
+		return err
+	}
+	count, err := s.repo.CountBooks(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryInUse
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.books.BooksChanged()
+	return nil
+}
+
+// RenameCategory renames the category called from to to. When another
+// category is already called to, from is merged into it instead.
+func (s *CategoryService) RenameCategory(req dto.CategoryRenameRequest) (*dto.CategoryChangeResult, error) {
+	to := strings.TrimSpace(req.To)
+	if to == "" {
+		return nil, ErrBlankCategory
+	}
+	if req.From == to {
+		return nil, ErrSameCategory
+	}
+	from, err := s.repo.FindByName(req.From)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByName(to)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var moved int64
+	if existing != nil && existing.ID != from.ID {
+		moved, err = s.repo.Merge([]model.Category{*from}, existing)
+	} else {
+		moved, err = s.repo.Rename(from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.books.BooksChanged()
+	return &dto.CategoryChangeResult{Category: to, Books: moved}, nil
+}
+
+// MergeCategories moves every book of the source categories into the
+// target category, creating it when it does not exist, and deletes the
+// sources
+func (s *CategoryService) MergeCategories(req dto.CategoryMergeRequest) (*dto.CategoryChangeResult, error) {
+	to := strings.TrimSpace(req.Target)
+	if to == "" {
+		return nil, ErrBlankCategory
+	}
+	for _, category := range req.Sources {
+		if category == to {
+			return nil, ErrSameCategory
+		}
+	}
+	found, err := s.repo.FindByNames(req.Sources)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, ErrCategoryNotFound
+	}
+
+	target, err := s.repo.FindByName(to)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		target, err = &model.Category{Name: to}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// A source spelled differently from the target may still be it
+	sources := make([]model.Category, 0, len(found))
+	for _, source := range found {
+		if source.ID != target.ID {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, ErrSameCategory
+	}
+
+	moved, err := s.repo.Merge(sources, target)
+	if err != nil {
+		return nil, err
+	}
+	s.books.BooksChanged()
+	return &dto.CategoryChangeResult{Category: target.Name, Books: moved}, nil
+}
+
+func (s *CategoryService) find(id uint) (*model.Category, error) {
+	category, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCategoryNotFound
+	}
+	return category, err
+}
+
+// availableName trims name and checks that no category other than id is
+// already called that, ignoring case
+func (s *CategoryService) availableName(name string, id uint) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrBlankCategory
+	}
+	existing, err := s.repo.FindByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return name, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if existing.ID != id {
+		return "", ErrCategoryExists
+	}
+	return name, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model/dto"
+	"time"
+)
+
+var ErrInvalidEventRange = apperror.New("INVALID_EVENT_RANGE", "from must be before to")
+
+// ChangeEventService serves the change data capture log to consumers that
+// tail it, such as the data warehouse loader, and to administrators
+// searching it
+type ChangeEventService struct {
+	repo   *repository.ChangeEventRepository
+	settle time.Duration
+}
+
+func NewChangeEventService(repo *repository.ChangeEventRepository, settle time.Duration) *ChangeEventService {
+	return &ChangeEventService{repo: repo, settle: settle}
+}
+
+// GetEvents returns up to limit events after afterSeq, leaving out events
+// younger than the settle delay so a consumer never moves past a sequence
+// number whose transaction has not committed yet
+func (s *ChangeEventService) GetEvents(afterSeq uint64, limit int) (*dto.ChangeEventPage, error) {
+	events, err := s.repo.FindAfter(afterSeq, time.Now().Add(-s.settle), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &dto.ChangeEventPage{Events: events, NextSeq: afterSeq}
+	if len(events) > 0 {
+		page.NextSeq = events[len(events)-1].Seq
+	}
+	return page, nil
+}
+
+// SearchEvents returns the events matching query, newest first. Unlike
+// GetEvents it includes events that have not settled yet.
+func (s *ChangeEventService) SearchEvents(query dto.ChangeEventQuery) (*dto.ChangeEventLog, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, ErrInvalidEventRange
+	}
+	events, err := s.repo.FindMatching(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &dto.ChangeEventLog{Events: events}
+	if len(events) == query.Limit {
+		page.NextBeforeSeq = events[len(events)-1].Seq
+	}
+	return page, nil
+}
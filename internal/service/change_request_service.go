@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"fmt"
+)
+
+var (
+	ErrNoChanges           = apperror.New("NO_CHANGES", "the edit does not change anything")
+	ErrChangeRequestClosed = apperror.New("CHANGE_REQUEST_CLOSED", "change request has already been reviewed")
+)
+
+// ChangeRequestService queues contributors' book edits for review. Approved
+// edits are applied together with the approval; the request keeps who
+// proposed and who approved the change. Contributors are notified of the
+// outcome.
+type ChangeRequestService struct {
+	repo          *repository.ChangeRequestRepository
+	bookRepo      BookReader
+	books         *BookService
+	notifications *NotificationService
+}
+
+func NewChangeRequestService(repo *repository.ChangeRequestRepository, bookRepo BookReader, books *BookService, notifications *NotificationService) *ChangeRequestService {
+	return &ChangeRequestService{repo: repo, bookRepo: bookRepo, books: books, notifications: notifications}
+}
+
+// Submit records userID's proposed edit of bookID as pending
+func (s *ChangeRequestService) Submit(ctx context.Context, bookID, userID uint, req dto.ChangeRequestSubmission) (*dto.ChangeRequestResponse, error) {
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	diff := diffBookChanges(*book, req.Changes)
+	if len(diff) == 0 {
+		return nil, ErrNoChanges
+	}
+	edited := *book
+	applyBookChanges(&edited, req.Changes)
+	if err := s.books.CheckRules(edited); err != nil {
+		return nil, err
+	}
+
+	cr := model.ChangeRequest{
+		BookID:      bookID,
+		SubmittedBy: userID,
+		Changes:     req.Changes,
+		Comment:     req.Comment,
+		Status:      model.ChangePending,
+	}
+	if err := s.repo.Create(&cr); err != nil {
+		return nil, err
+	}
+	return &dto.ChangeRequestResponse{ChangeRequest: cr, Diff: diff}, nil
+}
+
+// GetChangeRequests lists change requests with their diffs against the
+// current books
+func (s *ChangeRequestService) GetChangeRequests(ctx context.Context, status model.ChangeRequestStatus, submittedBy uint, machineGenerated *bool) ([]dto.ChangeRequestResponse, error) {
+	crs, err := s.repo.FindAll(status, submittedBy, machineGenerated)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ChangeRequestResponse, 0, len(crs))
+	for _, cr := range crs {
+		resp, err := s.withDiff(ctx, cr)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+	return responses, nil
+}
+
+func (s *ChangeRequestService) GetChangeRequest(ctx context.Context, id uint) (*dto.ChangeRequestResponse, error) {
+	cr, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withDiff(ctx, *cr)
+}
+
+// Approve applies the proposed edit and closes the request as reviewerID.
+// An edit that would leave the book breaking the validation rules is
+// refused.
+func (s *ChangeRequestService) Approve(ctx context.Context, id, reviewerID uint, review dto.ChangeReview) (*dto.ChangeRequestResponse, error) {
+	cr, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	// The book may have changed since the edit was proposed, or the rules
+	// since it was checked
+	if cr.Status == model.ChangePending {
+		book, err := s.bookRepo.FindByID(ctx, cr.BookID)
+		if err != nil {
+			return nil, err
+		}
+		applyBookChanges(book, cr.Changes)
+		if err := s.books.CheckRules(*book); err != nil {
+			return nil, err
+		}
+	}
+
+	reviewed, err := s.repo.Review(cr, model.ChangeApproved, reviewerID, review.Comment, func(book *model.Book) {
+		applyBookChanges(book, cr.Changes)
+		if cr.MachineGenerated && cr.Changes.Description != nil {
+			book.DescriptionGenerated = true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		return nil, ErrChangeRequestClosed
+	}
+
+	s.books.BooksChanged()
+	s.notifyReviewed(ctx, *cr)
+	return s.withDiff(ctx, *cr)
+}
+
+// Reject closes the request without touching the book
+func (s *ChangeRequestService) Reject(ctx context.Context, id, reviewerID uint, review dto.ChangeReview) (*dto.ChangeRequestResponse, error) {
+	cr, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewed, err := s.repo.Review(cr, model.ChangeRejected, reviewerID, review.Comment, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		return nil, ErrChangeRequestClosed
+	}
+	s.notifyReviewed(ctx, *cr)
+	return s.withDiff(ctx, *cr)
+}
+
+// notifyReviewed tells the contributor how their request was decided.
+// Machine generated requests have no contributor to tell.
+func (s *ChangeRequestService) notifyReviewed(ctx context.Context, cr model.ChangeRequest) {
+	if cr.MachineGenerated {
+		return
+	}
+	title := fmt.Sprintf("book #%d", cr.BookID)
+	if book, err := s.bookRepo.FindByID(ctx, cr.BookID); err == nil {
+		title = book.Title
+	}
+	s.notifications.Notify(cr.SubmittedBy, model.EventChangeRequestReviewed, ChangeRequestNotice{
+		BookTitle: title,
+		Status:    cr.Status,
+		Comment:   cr.ReviewComment,
+	}, "/me/change-requests")
+}
+
+func (s *ChangeRequestService) withDiff(ctx context.Context, cr model.ChangeRequest) (*dto.ChangeRequestResponse, error) {
+	resp := &dto.ChangeRequestResponse{ChangeRequest: cr, Diff: []dto.FieldChange{}}
+	book, err := s.bookRepo.FindByID(ctx, cr.BookID)
+	if err != nil {
+		// The book may have been deleted since; the request is still listed
+		return resp, nil
+	}
+	if diff := diffBookChanges(*book, cr.Changes); diff != nil {
+		resp.Diff = diff
+	}
+	return resp, nil
+}
+
+// diffBookChanges lists the proposed values that differ from book
+func diffBookChanges(book model.Book, c model.BookChanges) []dto.FieldChange {
+	var diff []dto.FieldChange
+	addString := func(field, current string, proposed *string) {
+		if proposed != nil && *proposed != current {
+			diff = append(diff, dto.FieldChange{Field: field, Current: current, Proposed: *proposed})
+		}
+	}
+	addInt := func(field string, current int, proposed *int) {
+		if proposed != nil && *proposed != current {
+			diff = append(diff, dto.FieldChange{Field: field, Current: current, Proposed: *proposed})
+		}
+	}
+
+	addString("title", book.Title, c.Title)
+	addString("author", book.Author, c.Author)
+	addString("category", book.Category, c.Category)
+	addString("description", book.Description, c.Description)
+	addInt("published_year", book.PublishedYear, c.PublishedYear)
+	addInt("pages", book.Pages, c.Pages)
+	addString("cover_url", book.CoverURL, c.CoverURL)
+	return diff
+}
+
+func applyBookChanges(book *model.Book, c model.BookChanges) {
+	if c.Title != nil {
+		book.Title = *c.Title
+	}
+	if c.Author != nil {
+		book.Author = *c.Author
+	}
+	if c.Category != nil {
+		book.Category = *c.Category
+	}
+	if c.Description != nil {
+		book.Description = *c.Description
+		book.DescriptionGenerated = false
+	}
+	if c.PublishedYear != nil {
+		book.PublishedYear = *c.PublishedYear
+	}
+	if c.Pages != nil {
+		book.Pages = *c.Pages
+	}
+	if c.CoverURL != nil {
+		book.CoverURL = *c.CoverURL
+	}
+}
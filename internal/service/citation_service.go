@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CitationFormat is a supported citation style
+type CitationFormat string
+
+const (
+	CitationBibTeX CitationFormat = "bibtex"
+	CitationRIS    CitationFormat = "ris"
+	CitationAPA    CitationFormat = "apa"
+	CitationMLA    CitationFormat = "mla"
+)
+
+var ErrUnsupportedCitationFormat = apperror.New("UNSUPPORTED_CITATION_FORMAT", "unsupported citation format")
+
+// ContentType is the MIME type a citation in this format is served as
+func (f CitationFormat) ContentType() string {
+	switch f {
+	case CitationBibTeX:
+		return "application/x-bibtex; charset=utf-8"
+	case CitationRIS:
+		return "application/x-research-info-systems; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+type CitationService struct {
+	bookRepo BookReader
+	favRepo  FavoriteRepository
+}
+
+func NewCitationService(bookRepo BookReader, favRepo FavoriteRepository) *CitationService {
+	return &CitationService{bookRepo: bookRepo, favRepo: favRepo}
+}
+
+// CiteBook renders the citation of a single book
+func (s *CitationService) CiteBook(ctx context.Context, id uint, format CitationFormat) (string, error) {
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return formatCitation(*book, format)
+}
+
+// CiteFavorites renders the citations of every book in the user's favorites,
+// separated by blank lines
+func (s *CitationService) CiteFavorites(ctx context.Context, userID uint, format CitationFormat) (string, error) {
+	favs, err := s.favRepo.FindAll(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]uint, len(favs))
+	for i, f := range favs {
+		ids[i] = f.BookID
+	}
+	books, err := s.bookRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return "", err
+	}
+
+	citations := make([]string, 0, len(books))
+	for _, book := range books {
+		citation, err := formatCitation(book, format)
+		if err != nil {
+			return "", err
+		}
+		citations = append(citations, citation)
+	}
+	return strings.Join(citations, "\n"), nil
+}
+
+func formatCitation(book model.Book, format CitationFormat) (string, error) {
+	switch format {
+	case CitationBibTeX:
+		return bibtexCitation(book), nil
+	case CitationRIS:
+		return risCitation(book), nil
+	case CitationAPA:
+		return apaCitation(book), nil
+	case CitationMLA:
+		return mlaCitation(book), nil
+	}
+	return "", ErrUnsupportedCitationFormat
+}
+
+func bibtexCitation(book model.Book) string {
+	given, family := splitAuthor(book.Author)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@book{%s,\n", bibtexKey(family, book))
+	fmt.Fprintf(&b, "  author = {%s},\n", bibtexEscape(strings.TrimSuffix(family+", "+given, ", ")))
+	fmt.Fprintf(&b, "  title = {%s}", bibtexEscape(book.Title))
+	if book.PublishedYear > 0 {
+		fmt.Fprintf(&b, ",\n  year = {%d}", book.PublishedYear)
+	}
+	b.WriteString("\n}\n")
+	return b.String()
+}
+
+func risCitation(book model.Book) string {
+	given, family := splitAuthor(book.Author)
+
+	var b strings.Builder
+	b.WriteString("TY  - BOOK\n")
+	fmt.Fprintf(&b, "AU  - %s\n", strings.TrimSuffix(family+", "+given, ", "))
+	fmt.Fprintf(&b, "TI  - %s\n", book.Title)
+	if book.PublishedYear > 0 {
+		fmt.Fprintf(&b, "PY  - %d\n", book.PublishedYear)
+	}
+	b.WriteString("ER  - \n")
+	return b.String()
+}
+
+// apaCitation follows APA 7: Family, I. I. (Year). Title.
+func apaCitation(book model.Book) string {
+	given, family := splitAuthor(book.Author)
+
+	author := family
+	if initials := initials(given); initials != "" {
+		author += ", " + initials
+	}
+	return fmt.Sprintf("%s (%s). %s.\n", author, yearOrND(book.PublishedYear), strings.TrimSuffix(book.Title, "."))
+}
+
+// mlaCitation follows MLA 9: Family, Given. Title. Year.
+func mlaCitation(book model.Book) string {
+	given, family := splitAuthor(book.Author)
+
+	author := family
+	if given != "" {
+		author += ", " + given
+	}
+	citation := strings.TrimSuffix(author, ".") + ". " + strings.TrimSuffix(book.Title, ".") + "."
+	if book.PublishedYear > 0 {
+		citation += " " + strconv.Itoa(book.PublishedYear) + "."
+	}
+	return citation + "\n"
+}
+
+// splitAuthor separates a "Given Family" name, taking the last word as the
+// family name. Names already written as "Family, Given" are kept as is.
+func splitAuthor(author string) (given, family string) {
+	author = strings.TrimSpace(author)
+	if family, given, ok := strings.Cut(author, ","); ok {
+		return strings.TrimSpace(given), strings.TrimSpace(family)
+	}
+	words := strings.Fields(author)
+	if len(words) == 0 {
+		return "", ""
+	}
+	return strings.Join(words[:len(words)-1], " "), words[len(words)-1]
+}
+
+func initials(given string) string {
+	var parts []string
+	for _, w := range strings.Fields(given) {
+		r := []rune(w)
+		parts = append(parts, string(unicode.ToUpper(r[0]))+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+func yearOrND(year int) string {
+	if year <= 0 {
+		return "n.d."
+	}
+	return strconv.Itoa(year)
+}
+
+func bibtexKey(family string, book model.Book) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, family)
+	if key == "" {
+		key = "book"
+	}
+	if book.PublishedYear > 0 {
+		return key + strconv.Itoa(book.PublishedYear)
+	}
+	return key + strconv.FormatUint(uint64(book.ID), 10)
+}
+
+func bibtexEscape(s string) string {
+	return strings.NewReplacer(`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`, "&", `\&`, "%", `\%`, "$", `\$`, "#", `\#`, "_", `\_`).Replace(s)
+}
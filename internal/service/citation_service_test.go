@@ -0,0 +1,72 @@
+package service
+
+import (
+	"bms-go/internal/model"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestCitationServiceCiteBook(t *testing.T) {
+	books := newFakeBooks(
+		newBook(1, "The Left Hand of Darkness", "Ursula K. Le Guin", 1969),
+		newBook(2, "Beowulf.", "Anonymous", 0),
+		newBook(3, "100% & More_", "Knuth, Donald E.", 1984),
+	)
+	s := NewCitationService(books, &fakeFavorites{})
+
+	tests := []struct {
+		name    string
+		id      uint
+		format  CitationFormat
+		want    string
+		wantErr error
+	}{
+		{"bibtex", 1, CitationBibTeX, "@book{guin1969,\n  author = {Guin, Ursula K. Le},\n  title = {The Left Hand of Darkness},\n  year = {1969}\n}\n", nil},
+		{"bibtex without a year keys on the id", 2, CitationBibTeX, "@book{anonymous2,\n  author = {Anonymous},\n  title = {Beowulf.}\n}\n", nil},
+		{"bibtex escapes special characters", 3, CitationBibTeX, "@book{knuth1984,\n  author = {Knuth, Donald E.},\n  title = {100\\% \\& More\\_},\n  year = {1984}\n}\n", nil},
+		{"ris", 1, CitationRIS, "TY  - BOOK\nAU  - Guin, Ursula K. Le\nTI  - The Left Hand of Darkness\nPY  - 1969\nER  - \n", nil},
+		{"apa", 1, CitationAPA, "Guin, U. K. L. (1969). The Left Hand of Darkness.\n", nil},
+		{"apa without a year", 2, CitationAPA, "Anonymous (n.d.). Beowulf.\n", nil},
+		{"mla", 3, CitationMLA, "Knuth, Donald E. 100% & More_. 1984.\n", nil},
+		{"unsupported format", 1, CitationFormat("chicago"), "", ErrUnsupportedCitationFormat},
+		{"missing book", 9, CitationAPA, "", gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.CiteBook(context.Background(), tt.id, tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CiteBook(%d, %s) error = %v, want %v", tt.id, tt.format, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CiteBook(%d, %s) = %q, want %q", tt.id, tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCitationServiceCiteFavorites(t *testing.T) {
+	books := newFakeBooks(
+		newBook(1, "Dune", "Frank Herbert", 1965),
+		newBook(2, "Emma", "Jane Austen", 1815),
+	)
+	favorites := &fakeFavorites{favorites: map[uint][]model.Favorite{
+		// Book 7 was deleted after it was favorited
+		1: {{UserID: 1, BookID: 2}, {UserID: 1, BookID: 7}, {UserID: 1, BookID: 1}},
+	}}
+	s := NewCitationService(books, favorites)
+
+	got, err := s.CiteFavorites(context.Background(), 1, CitationMLA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Herbert, Frank. Dune. 1965.\n\nAusten, Jane. Emma. 1815.\n"; got != want {
+		t.Errorf("CiteFavorites = %q, want %q", got, want)
+	}
+
+	if got, err := s.CiteFavorites(context.Background(), 2, CitationMLA); err != nil || got != "" {
+		t.Errorf("CiteFavorites without favorites = %q, %v; want nothing", got, err)
+	}
+}
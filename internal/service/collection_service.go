@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCollectionNotFound     = apperror.New("COLLECTION_NOT_FOUND", "collection not found")
+	ErrCollectionBookNotFound = apperror.New(apperror.CodeBookNotFound, "book not found")
+	ErrNotInCollection        = apperror.New("NOT_IN_COLLECTION", "book is not in this collection")
+	ErrCollectionExists       = apperror.New("COLLECTION_EXISTS", "a collection with this name already exists")
+	ErrBlankCollectionName    = apperror.New("BLANK_COLLECTION_NAME", "collection name must not be blank")
+)
+
+// CollectionService keeps users' reading lists. Collections belong to the
+// user who made them; other users' collections are reported as not found.
+type CollectionService struct {
+	repo     *repository.CollectionRepository
+	bookRepo BookReader
+}
+
+func NewCollectionService(repo *repository.CollectionRepository, bookRepo BookReader) *CollectionService {
+	return &CollectionService{repo: repo, bookRepo: bookRepo}
+}
+
+// GetCollections lists userID's collections by name
+func (s *CollectionService) GetCollections(userID uint) ([]dto.CollectionResponse, error) {
+	collections, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if collections == nil {
+		collections = []dto.CollectionResponse{}
+	}
+	return collections, nil
+}
+
+// GetCollection returns userID's collection id with its books
+func (s *CollectionService) GetCollection(ctx context.Context, userID, id uint) (*dto.CollectionDetailResponse, error) {
+	if _, err := s.findOwned(userID, id); err != nil {
+		return nil, err
+	}
+	summary, err := s.repo.Summary(id)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.FindBooks(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.BookID
+	}
+	books, err := s.bookRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+
+	detail := dto.CollectionDetailResponse{CollectionResponse: *summary, Items: []dto.CollectionItemResponse{}}
+	for _, entry := range entries {
+		// Deleted books drop out of the list
+		book, ok := byID[entry.BookID]
+		if !ok {
+			continue
+		}
+		detail.Items = append(detail.Items, dto.CollectionItemResponse{AddedAt: entry.CreatedAt, Book: toBookResponse(book)})
+	}
+	return &detail, nil
+}
+
+// CreateCollection starts an empty collection for userID
+func (s *CollectionService) CreateCollection(userID uint, req dto.CollectionRequest) (*dto.CollectionResponse, error) {
+	collection := model.Collection{UserID: userID}
+	if err := s.apply(&collection, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(&collection); err != nil {
+		return nil, err
+	}
+	return s.repo.Summary(collection.ID)
+}
+
+// UpdateCollection renames userID's collection id or changes its
+// description
+func (s *CollectionService) UpdateCollection(userID, id uint, req dto.CollectionRequest) (*dto.CollectionResponse, error) {
+	collection, err := s.findOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(collection, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(collection); err != nil {
+		return nil, err
+	}
+	return s.repo.Summary(id)
+}
+
+// DeleteCollection removes userID's collection id. The books stay in the
+// catalog.
+func (s *CollectionService) DeleteCollection(userID, id uint) error {
+	if _, err := s.findOwned(userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// AddBook puts book bookID on userID's collection id. Adding a book the
+// collection already has changes nothing.
+func (s *CollectionService) AddBook(ctx context.Context, userID, id, bookID uint) (*dto.CollectionResponse, error) {
+	if _, err := s.findOwned(userID, id); err != nil {
+		return nil, err
+	}
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionBookNotFound
+		}
+		return nil, err
+	}
+	if err := s.repo.AddBook(id, bookID); err != nil {
+		return nil, err
+	}
+	return s.repo.Summary(id)
+}
+
+// RemoveBook takes book bookID off userID's collection id
+func (s *CollectionService) RemoveBook(userID, id, bookID uint) error {
+	if _, err := s.findOwned(userID, id); err != nil {
+		return err
+	}
+	if err := s.repo.RemoveBook(id, bookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotInCollection
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *CollectionService) findOwned(userID, id uint) (*model.Collection, error) {
+	collection, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && collection.UserID != userID {
+		return nil, ErrCollectionNotFound
+	}
+	return collection, err
+}
+
+// apply applies req to collection, checking the name is not blank and not
+// taken by another of the user's collections
+func (s *CollectionService) apply(collection *model.Collection, req dto.CollectionRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrBlankCollectionName
+	}
+	taken, err := s.repo.NameTaken(collection.UserID, name, collection.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrCollectionExists
+	}
+	collection.Name = name
+	collection.Description = strings.TrimSpace(req.Description)
+	return nil
+}
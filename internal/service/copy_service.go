@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCopyBookNotFound = apperror.New(apperror.CodeBookNotFound, "book not found")
+	ErrCopyNotFound     = apperror.New("COPY_NOT_FOUND", "copy not found")
+	ErrCopyOnLoan       = apperror.New("COPY_ON_LOAN", "copy is out on loan")
+	ErrStockOnLoan      = apperror.New("STOCK_ON_LOAN", "too many copies are out on loan to reduce the stock that far")
+	ErrInvalidCondition = apperror.New("INVALID_CONDITION", "condition must be one of good, fair, poor, damaged, unusable")
+	ErrInvalidPhoto     = apperror.New("INVALID_DAMAGE_PHOTO", "photos must be JPEG, PNG or WebP images")
+	ErrTooManyPhotos    = apperror.New("TOO_MANY_DAMAGE_PHOTOS", fmt.Sprintf("at most %d photos can be attached to a damage report", MaxDamagePhotos))
+)
+
+// MaxDamagePhotos is the most photos a damage report can have
+const MaxDamagePhotos = 5
+
+// damagePhotoTypes are the accepted photo content types, as sniffed from
+// the photo itself
+var damagePhotoTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/webp": true}
+
+// PhotoTooLargeError reports a damage photo over the configured size limit
+type PhotoTooLargeError struct {
+	MaxSize int64
+}
+
+func (e *PhotoTooLargeError) Error() string {
+	return fmt.Sprintf("photos must not be larger than %d bytes", e.MaxSize)
+}
+
+// CopyService keeps the stock of physical copies of each book and their
+// condition. Changing either changes the book's availability, so it drops
+// the cached book data through BookService.BooksChanged. Copies reported
+// damaged are kept out of circulation until repaired, and unusable ones are
+// withdrawn.
+type CopyService struct {
+	repo         *repository.CopyRepository
+	books        *BookService
+	photoMaxSize int64
+}
+
+func NewCopyService(repo *repository.CopyRepository, books *BookService, photoMaxSize int64) *CopyService {
+	return &CopyService{repo: repo, books: books, photoMaxSize: photoMaxSize}
+}
+
+// PhotoMaxSize is the largest damage photo accepted, in bytes
+func (s *CopyService) PhotoMaxSize() int64 {
+	return s.photoMaxSize
+}
+
+// GetCopies lists book id's copies with the loans they are out on
+func (s *CopyService) GetCopies(id uint) ([]dto.CopyResponse, error) {
+	if _, err := s.GetStock(id); err != nil {
+		return nil, err
+	}
+	copies, err := s.repo.FindByBook(id)
+	if err != nil {
+		return nil, err
+	}
+	if copies == nil {
+		copies = []dto.CopyResponse{}
+	}
+	return copies, nil
+}
+
+func (s *CopyService) GetStock(id uint) (*dto.StockResponse, error) {
+	stock, err := s.repo.Stock(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCopyBookNotFound
+	}
+	return stock, err
+}
+
+// GetAvailability returns the availability of the books in ids in the
+// requested order, noting ids without a book
+func (s *CopyService) GetAvailability(ids []uint) (*dto.AvailabilityResponse, error) {
+	var distinct []uint
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	found, err := s.repo.Availability(distinct, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]dto.BookAvailability, len(found))
+	for _, availability := range found {
+		byID[availability.BookID] = availability
+	}
+
+	resp := &dto.AvailabilityResponse{Books: []dto.BookAvailability{}, MissingIDs: []uint{}}
+	for _, id := range distinct {
+		availability, ok := byID[id]
+		if !ok {
+			resp.MissingIDs = append(resp.MissingIDs, id)
+			continue
+		}
+		resp.Books = append(resp.Books, availability)
+	}
+	return resp, nil
+}
+
+// SetStock adds or withdraws copies of book id until it has req.Copies.
+// Copies out on loan are never withdrawn.
+func (s *CopyService) SetStock(id uint, req dto.StockRequest) (*dto.StockResponse, error) {
+	stock, ok, err := s.repo.SetStock(id, *req.Copies)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCopyBookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrStockOnLoan
+	}
+	s.books.BooksChanged()
+	return stock, nil
+}
+
+// WithdrawCopy removes copy copyID of book id from stock, unless it is out
+// on loan
+func (s *CopyService) WithdrawCopy(id, copyID uint) error {
+	if _, err := s.GetStock(id); err != nil {
+		return err
+	}
+	withdrawn, err := s.repo.Withdraw(id, copyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCopyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !withdrawn {
+		return ErrCopyOnLoan
+	}
+	s.books.BooksChanged()
+	return nil
+}
+
+// SetCondition records the condition copy copyID of book id was assessed
+// in, such as after a repair, and moves it in or out of circulation to
+// match
+func (s *CopyService) SetCondition(id, copyID uint, condition model.CopyCondition) (*model.Copy, error) {
+	if !condition.Valid() {
+		return nil, ErrInvalidCondition
+	}
+	return s.setCondition(id, copyID, condition, nil)
+}
+
+// ReportDamage stores a damage report with photos on copy copyID of book id
+// by userID, and puts the copy in the condition it was assessed in
+func (s *CopyService) ReportDamage(id, copyID, userID uint, req dto.DamageReportRequest, photos []dto.DamagePhotoUpload) (*dto.DamageReportResponse, error) {
+	if !req.Condition.Valid() {
+		return nil, ErrInvalidCondition
+	}
+	if len(photos) > MaxDamagePhotos {
+		return nil, ErrTooManyPhotos
+	}
+	report := model.DamageReport{
+		ReportedBy:  userID,
+		Condition:   req.Condition,
+		Description: req.Description,
+		Photos:      make([]model.DamagePhoto, len(photos)),
+	}
+	for i, photo := range photos {
+		if int64(len(photo.Content)) > s.photoMaxSize {
+			return nil, &PhotoTooLargeError{MaxSize: s.photoMaxSize}
+		}
+		contentType := http.DetectContentType(photo.Content)
+		if !damagePhotoTypes[contentType] {
+			return nil, ErrInvalidPhoto
+		}
+		report.Photos[i] = model.DamagePhoto{
+			Filename:    filepath.Base(photo.Filename),
+			ContentType: contentType,
+			Size:        len(photo.Content),
+			Content:     photo.Content,
+		}
+	}
+
+	updated, err := s.setCondition(id, copyID, req.Condition, &report)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DamageReportResponse{Report: report, Copy: *updated}, nil
+}
+
+func (s *CopyService) setCondition(id, copyID uint, condition model.CopyCondition, report *model.DamageReport) (*model.Copy, error) {
+	if _, err := s.GetStock(id); err != nil {
+		return nil, err
+	}
+	updated, ok, err := s.repo.SetCondition(id, copyID, condition, report)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCopyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrCopyOnLoan
+	}
+	s.books.BooksChanged()
+	return updated, nil
+}
+
+// GetDamageReports lists the damage reported on copy copyID of book id,
+// newest first, including on copies since withdrawn
+func (s *CopyService) GetDamageReports(id, copyID uint) ([]model.DamageReport, error) {
+	reports, err := s.repo.FindDamageReports(id, copyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCopyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []model.DamageReport{}
+	}
+	return reports, nil
+}
+
+// GetDamagePhoto returns a photo of a damage report with its content
+func (s *CopyService) GetDamagePhoto(id, copyID, reportID, photoID uint) (*model.DamagePhoto, error) {
+	return s.repo.FindDamagePhoto(id, copyID, reportID, photoID)
+}
+
+// GetReplacements lists the copies that need replacing: those in poor
+// condition or in for repair, and those withdrawn as unusable since since,
+// or ever when it is zero
+func (s *CopyService) GetReplacements(since time.Time) ([]dto.ReplacementCandidate, error) {
+	candidates, err := s.repo.ReplacementCandidates(since)
+	if err != nil {
+		return nil, err
+	}
+	if candidates == nil {
+		candidates = []dto.ReplacementCandidate{}
+	}
+	return candidates, nil
+}
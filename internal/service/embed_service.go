@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	embedWidth  = 400
+	embedHeight = 180
+
+	// EmbedCacheAge is how long, in seconds, embeds may be cached by clients
+	EmbedCacheAge = 3600
+)
+
+var ErrUnknownEmbedURL = apperror.New("UNKNOWN_EMBED_URL", "url does not point to a book")
+
+var embedTemplate = template.Must(template.New("embed").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Book.Title}}</title>
+<link rel="alternate" type="application/json+oembed" href="{{.OEmbedURL}}" title="{{.Book.Title}}">
+<style>
+body{margin:0;font-family:sans-serif}
+.bms-book{display:flex;gap:12px;padding:12px;border:1px solid #ddd;border-radius:6px;max-width:{{.Width}}px;box-sizing:border-box}
+.bms-book img{width:96px;height:144px;object-fit:cover}
+.bms-book h3{margin:0 0 4px;font-size:16px}
+.bms-book p{margin:0 0 8px;color:#555;font-size:14px}
+</style>
+</head>
+<body>
+<div class="bms-book">
+{{if .Book.CoverURL}}<img src="{{.Book.CoverURL}}" alt="Cover of {{.Book.Title}}">{{end}}
+<div>
+<h3>{{.Book.Title}}</h3>
+<p>{{.Book.Author}}</p>
+<a href="{{.BookURL}}" target="_blank" rel="noopener">View in catalog</a>
+</div>
+</div>
+</body>
+</html>
+`))
+
+// EmbedService renders books as HTML widgets that other sites can embed
+type EmbedService struct {
+	bookRepo BookReader
+	links    *LinkService
+	baseURL  string
+}
+
+func NewEmbedService(bookRepo BookReader, links *LinkService, baseURL string) *EmbedService {
+	return &EmbedService{bookRepo: bookRepo, links: links, baseURL: baseURL}
+}
+
+// RenderBook returns the widget HTML for a book and when the book last changed
+func (s *EmbedService) RenderBook(ctx context.Context, id uint) ([]byte, time.Time, error) {
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var buf bytes.Buffer
+	err = embedTemplate.Execute(&buf, struct {
+		Book      model.Book
+		BookURL   string
+		OEmbedURL string
+		Width     int
+	}{
+		Book:      *book,
+		BookURL:   s.links.BookURL(book.ID),
+		OEmbedURL: s.baseURL + "/oembed?url=" + url.QueryEscape(s.links.BookURL(book.ID)),
+		Width:     embedWidth,
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return buf.Bytes(), book.UpdatedAt, nil
+}
+
+// OEmbed describes how to embed the book at bookURL, shrinking the widget to
+// fit maxWidth and maxHeight when they are set
+func (s *EmbedService) OEmbed(ctx context.Context, bookURL string, maxWidth, maxHeight int) (*dto.OEmbedResponse, error) {
+	id, ok := s.links.ParseBookURL(bookURL)
+	if !ok {
+		return nil, ErrUnknownEmbedURL
+	}
+
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := embedWidth, embedHeight
+	if maxWidth > 0 && maxWidth < width {
+		width = maxWidth
+	}
+	if maxHeight > 0 && maxHeight < height {
+		height = maxHeight
+	}
+
+	src := s.baseURL + "/embed/books/" + strconv.FormatUint(uint64(book.ID), 10)
+	return &dto.OEmbedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		Title:        book.Title,
+		AuthorName:   book.Author,
+		ProviderName: "Book Management System",
+		ProviderURL:  s.baseURL,
+		CacheAge:     EmbedCacheAge,
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0" scrolling="no" title="%s"></iframe>`,
+			src, width, height, template.HTMLEscapeString(book.Title)),
+		Width:        width,
+		Height:       height,
+		ThumbnailURL: book.CoverURL,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/enrich"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"context"
+	"expvar"
+	"log"
+	"strings"
+	"time"
+)
+
+// ErrEnrichmentDisabled is returned when no enrichment step is configured
+var ErrEnrichmentDisabled = apperror.New("ENRICHMENT_DISABLED", "enrichment is not configured")
+
+var (
+	// enrichmentSuggested and enrichmentFailed count queued suggestions and
+	// failed enrichment steps, exposed through expvar at /debug/vars
+	enrichmentSuggested = expvar.NewInt("enrichment_suggested")
+	enrichmentFailed    = expvar.NewInt("enrichment_failed")
+)
+
+// EnrichmentService fills in books that have no description. Each book is
+// passed through the configured steps in order; the first summary becomes
+// the proposed description and the keywords of every step are collected.
+// Nothing is written to the book directly: the suggestion is queued as a
+// machine generated change request and applied once a reviewer approves it.
+type EnrichmentService struct {
+	steps          []enrich.Enricher
+	bookRepo       BookReader
+	changeRequests *repository.ChangeRequestRepository
+	batchSize      int
+}
+
+func NewEnrichmentService(steps []enrich.Enricher, bookRepo BookReader, changeRequests *repository.ChangeRequestRepository, batchSize int) *EnrichmentService {
+	return &EnrichmentService{steps: steps, bookRepo: bookRepo, changeRequests: changeRequests, batchSize: batchSize}
+}
+
+// Enrich queues suggestions for up to one batch of books missing a
+// description and returns how many were queued. Books every step failed or
+// had nothing for are tried again on the next run.
+func (s *EnrichmentService) Enrich(ctx context.Context) (int, error) {
+	if len(s.steps) == 0 {
+		return 0, ErrEnrichmentDisabled
+	}
+	books, err := s.bookRepo.FindMissingDescriptions(ctx, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, book := range books {
+		if ctx.Err() != nil {
+			return queued, ctx.Err()
+		}
+		cr, ok := s.suggest(ctx, book)
+		if !ok {
+			continue
+		}
+		if err := s.changeRequests.Create(cr); err != nil {
+			return queued, err
+		}
+		enrichmentSuggested.Add(1)
+		queued++
+	}
+	return queued, nil
+}
+
+// suggest runs book through every step and builds the change request for
+// the result. It reports false when no step produced a summary.
+func (s *EnrichmentService) suggest(ctx context.Context, book model.Book) (*model.ChangeRequest, bool) {
+	var summary string
+	var sources, keywords []string
+	seen := make(map[string]bool)
+	for _, step := range s.steps {
+		suggestion, err := step.Enrich(ctx, book)
+		if err != nil {
+			enrichmentFailed.Add(1)
+			log.Printf("Enrichment step %s failed for book %d: %v", step.Name(), book.ID, err)
+			continue
+		}
+		if summary == "" && suggestion.Summary != "" {
+			summary = suggestion.Summary
+		}
+		if suggestion.Summary != "" || len(suggestion.Keywords) > 0 {
+			sources = append(sources, step.Name())
+		}
+		for _, k := range suggestion.Keywords {
+			if key := strings.ToLower(k); !seen[key] {
+				seen[key] = true
+				keywords = append(keywords, k)
+			}
+		}
+	}
+	if summary == "" {
+		return nil, false
+	}
+
+	return &model.ChangeRequest{
+		BookID:           book.ID,
+		Changes:          model.BookChanges{Description: &summary},
+		Status:           model.ChangePending,
+		MachineGenerated: true,
+		Source:           strings.Join(sources, ","),
+		Keywords:         keywords,
+	}, true
+}
+
+// Run enriches a batch every interval until ctx is cancelled
+func (s *EnrichmentService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			queued, err := s.Enrich(ctx)
+			if err != nil {
+				log.Printf("Enrichment failed: %v", err)
+				continue
+			}
+			if queued > 0 {
+				log.Printf("Queued %d machine generated descriptions for review", queued)
+			}
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultEventLimit = 50
+
+var (
+	ErrEventEndsBeforeStart = apperror.New("EVENT_ENDS_BEFORE_START", "ends_at must be after starts_at")
+	ErrRoomBooked           = apperror.New("ROOM_BOOKED", "the room is booked for another event at that time")
+	ErrEventBookNotFound    = apperror.New(apperror.CodeBookNotFound, "book not found")
+	ErrEventEnded           = apperror.New("EVENT_ENDED", "the event has already ended")
+)
+
+// EventService runs library events: staff schedule them in rooms, patrons
+// RSVP, and once an event is full further RSVPs join a waitlist that moves
+// up as seats free
+type EventService struct {
+	repo          *repository.EventRepository
+	bookRepo      BookReader
+	notifications *NotificationService
+}
+
+func NewEventService(repo *repository.EventRepository, bookRepo BookReader, notifications *NotificationService) *EventService {
+	return &EventService{repo: repo, bookRepo: bookRepo, notifications: notifications}
+}
+
+func (s *EventService) GetEvents(query dto.EventQuery) ([]dto.EventResponse, error) {
+	from := query.From
+	if from.IsZero() {
+		from = time.Now()
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultEventLimit
+	}
+
+	events, err := s.repo.FindEvents(from, query.BookID, query.Room, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.withAttendance(events)
+}
+
+func (s *EventService) GetEvent(id uint) (*dto.EventResponse, error) {
+	event, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.withAttendance([]model.Event{*event})
+	if err != nil {
+		return nil, err
+	}
+	return &resp[0], nil
+}
+
+// GetMyEvents lists the events userID has an RSVP for
+func (s *EventService) GetMyEvents(userID uint) ([]dto.MyEventResponse, error) {
+	events, rsvps, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	withAttendance, err := s.withAttendance(events)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make(map[uint]model.RSVPStatus, len(rsvps))
+	for _, rsvp := range rsvps {
+		status[rsvp.EventID] = rsvp.Status
+	}
+	resp := make([]dto.MyEventResponse, len(withAttendance))
+	for i, event := range withAttendance {
+		resp[i] = dto.MyEventResponse{EventResponse: event, RSVPStatus: status[event.ID]}
+	}
+	return resp, nil
+}
+
+func (s *EventService) CreateEvent(ctx context.Context, req dto.EventRequest) (*dto.EventResponse, error) {
+	event := model.Event{}
+	if err := s.apply(ctx, &event, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(&event); err != nil {
+		return nil, err
+	}
+	return s.GetEvent(event.ID)
+}
+
+// UpdateEvent changes the event. Raising the capacity seats waitlisted
+// users, who are notified; lowering it keeps everyone already confirmed.
+func (s *EventService) UpdateEvent(ctx context.Context, id uint, req dto.EventRequest) (*dto.EventResponse, error) {
+	event, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, event, req); err != nil {
+		return nil, err
+	}
+	promoted, err := s.repo.Update(event)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyPromoted(*event, promoted)
+	return s.GetEvent(id)
+}
+
+func (s *EventService) DeleteEvent(id uint) error {
+	return s.repo.Delete(id)
+}
+
+func (s *EventService) GetRSVPs(eventID uint) ([]model.EventRSVP, error) {
+	if _, err := s.repo.FindByID(eventID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindRSVPs(eventID)
+}
+
+// RSVP reserves a seat for userID, or a waitlist place when the event is full
+func (s *EventService) RSVP(eventID, userID uint) (*model.EventRSVP, error) {
+	event, err := s.repo.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.EndsAt.After(time.Now()) {
+		return nil, ErrEventEnded
+	}
+	return s.repo.RSVP(eventID, userID)
+}
+
+// CancelRSVP gives up userID's seat or waitlist place. A freed seat goes to
+// the first waitlisted user, who is notified.
+func (s *EventService) CancelRSVP(eventID, userID uint) error {
+	event, err := s.repo.FindByID(eventID)
+	if err != nil {
+		return err
+	}
+	promoted, err := s.repo.CancelRSVP(eventID, userID)
+	if err != nil {
+		return err
+	}
+	s.notifyPromoted(*event, promoted)
+	return nil
+}
+
+// apply validates req and copies it onto event
+func (s *EventService) apply(ctx context.Context, event *model.Event, req dto.EventRequest) error {
+	if !req.EndsAt.After(req.StartsAt) {
+		return ErrEventEndsBeforeStart
+	}
+	if req.BookID != nil {
+		if _, err := s.bookRepo.FindByID(ctx, *req.BookID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEventBookNotFound
+		} else if err != nil {
+			return err
+		}
+	}
+	taken, err := s.repo.RoomTaken(req.Room, req.StartsAt, req.EndsAt, event.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrRoomBooked
+	}
+
+	event.Title = req.Title
+	event.Description = req.Description
+	event.Room = req.Room
+	event.StartsAt = req.StartsAt
+	event.EndsAt = req.EndsAt
+	event.Capacity = req.Capacity
+	event.BookID = req.BookID
+	return nil
+}
+
+func (s *EventService) withAttendance(events []model.Event) ([]dto.EventResponse, error) {
+	ids := make([]uint, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	counts, err := s.repo.CountRSVPs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.EventResponse, len(events))
+	for i, event := range events {
+		c := counts[event.ID]
+		resp[i] = dto.EventResponse{Event: event, Attending: c.Confirmed, Waitlisted: c.Waitlisted}
+		if event.Capacity > 0 {
+			left := int64(event.Capacity) - c.Confirmed
+			if left < 0 {
+				left = 0
+			}
+			resp[i].SpotsLeft = &left
+		}
+	}
+	return resp, nil
+}
+
+func (s *EventService) notifyPromoted(event model.Event, promoted []model.EventRSVP) {
+	for _, rsvp := range promoted {
+		s.notifications.Notify(rsvp.UserID, model.EventRSVPPromoted, RSVPNotice{
+			EventTitle: event.Title,
+			Room:       event.Room,
+			StartsAt:   event.StartsAt.Format("Mon 2 Jan 2006 15:04"),
+		}, "/me/events")
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"expvar"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+var (
+	ErrInvalidExperiment  = apperror.New("INVALID_EXPERIMENT", "invalid experiment")
+	ErrExperimentKeyTaken = apperror.New("EXPERIMENT_KEY_TAKEN", "an experiment with this key already exists")
+)
+
+// experimentExposures counts requests per experiment variant, keyed
+// "experiment.variant", exposed through expvar at /debug/vars
+var experimentExposures = expvar.NewMap("experiment_exposures")
+
+// ExperimentService manages A/B experiments and buckets callers into their
+// variants. The running experiments are cached until one is changed.
+type ExperimentService struct {
+	repo *repository.ExperimentRepository
+
+	mu     sync.RWMutex
+	active []model.Experiment
+	loaded bool
+}
+
+func NewExperimentService(repo *repository.ExperimentRepository) *ExperimentService {
+	return &ExperimentService{repo: repo}
+}
+
+func (s *ExperimentService) running() ([]model.Experiment, error) {
+	s.mu.RLock()
+	active, loaded := s.active, s.loaded
+	s.mu.RUnlock()
+	if loaded {
+		return active, nil
+	}
+
+	active, err := s.repo.FindEnabled()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.active, s.loaded = active, true
+	s.mu.Unlock()
+	return active, nil
+}
+
+func (s *ExperimentService) invalidate() {
+	s.mu.Lock()
+	s.active, s.loaded = nil, false
+	s.mu.Unlock()
+}
+
+// Assign buckets subject into a variant of every running experiment and
+// counts the exposure. The same subject always lands in the same variant
+// while an experiment's variants are unchanged.
+func (s *ExperimentService) Assign(subject string) ([]dto.ExperimentAssignment, error) {
+	active, err := s.running()
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]dto.ExperimentAssignment, 0, len(active))
+	for _, e := range active {
+		v := bucket(e, subject)
+		experimentExposures.Add(e.Key+"."+v.Name, 1)
+		assignments = append(assignments, dto.ExperimentAssignment{Experiment: e.Key, Variant: v.Name, Params: v.Params})
+	}
+	return assignments, nil
+}
+
+// bucket picks subject's variant of e by hashing the experiment key with
+// the subject onto the variants' weights
+func bucket(e model.Experiment, subject string) model.ExperimentVariant {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	h := fnv.New64a()
+	h.Write([]byte(e.Key + ":" + subject))
+	point := int(h.Sum64() % uint64(total))
+	for _, v := range e.Variants {
+		if point < v.Weight {
+			return v
+		}
+		point -= v.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+func (s *ExperimentService) GetExperiments() ([]model.Experiment, error) {
+	return s.repo.FindAll()
+}
+
+func (s *ExperimentService) GetExperiment(id uint) (*model.Experiment, error) {
+	return s.repo.FindByID(id)
+}
+
+func (s *ExperimentService) CreateExperiment(req dto.ExperimentRequest) (*model.Experiment, error) {
+	if err := s.validate(0, req); err != nil {
+		return nil, err
+	}
+	e := model.Experiment{Key: req.Key, Description: req.Description, Variants: req.Variants, Enabled: req.Enabled}
+	if err := s.repo.Create(&e); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return &e, nil
+}
+
+// UpdateExperiment replaces the experiment. Changing the variants or their
+// weights moves some subjects to another variant.
+func (s *ExperimentService) UpdateExperiment(id uint, req dto.ExperimentRequest) (*model.Experiment, error) {
+	e, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(id, req); err != nil {
+		return nil, err
+	}
+	e.Key, e.Description, e.Variants, e.Enabled = req.Key, req.Description, req.Variants, req.Enabled
+	if err := s.repo.Update(e); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return e, nil
+}
+
+func (s *ExperimentService) DeleteExperiment(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// validate checks the variants, the params of the experiments the
+// application knows, and that no other experiment than id has the key
+func (s *ExperimentService) validate(id uint, req dto.ExperimentRequest) error {
+	names := make(map[string]bool)
+	for _, v := range req.Variants {
+		if v.Name == "" {
+			return fmt.Errorf("%w: every variant needs a name", ErrInvalidExperiment)
+		}
+		if names[v.Name] {
+			return fmt.Errorf("%w: variant %q is listed twice", ErrInvalidExperiment, v.Name)
+		}
+		names[v.Name] = true
+		if v.Weight <= 0 {
+			return fmt.Errorf("%w: variant %q needs a positive weight", ErrInvalidExperiment, v.Name)
+		}
+
+		switch req.Key {
+		case model.ExperimentSearchRanking:
+			if _, err := (dto.RelevanceWeights{}).Override(v.Params); err != nil {
+				return fmt.Errorf("%w: variant %q: %v", ErrInvalidExperiment, v.Name, err)
+			}
+		case model.ExperimentRecommendations:
+			for name, value := range v.Params {
+				if name != "strategy" {
+					return fmt.Errorf("%w: variant %q: unknown param %q", ErrInvalidExperiment, v.Name, name)
+				}
+				if !dto.SimilarityStrategy(value).Valid() {
+					return fmt.Errorf("%w: variant %q: strategy must be one of auto, content, embedding", ErrInvalidExperiment, v.Name)
+				}
+			}
+		}
+	}
+
+	existing, err := s.repo.FindAll()
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		if e.Key == req.Key && e.ID != id {
+			return ErrExperimentKeyTaken
+		}
+	}
+	return nil
+}
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"bms-go/internal/infra/httpclient"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	coverFetchTimeout = 5 * time.Second
+	maxCoverBytes     = 2 << 20
+
+	pdfMargin      = 15.0
+	pdfLineHeight  = 5.0
+	pdfCoverWidth  = 12.0
+	pdfCoverHeight = 18.0
+
+	xlsxSheet       = "Books"
+	xlsxMaxColWidth = 60
+
+	// exportBatchSize is how many books a streamed export reads at a time
+	exportBatchSize = 500
+)
+
+// pdfColumn is one column of the book table in an exported PDF. Widths are in
+// millimetres and add up to the printable width of an A4 page.
+type pdfColumn struct {
+	header string
+	width  float64
+	value  func(model.Book) string
+}
+
+var pdfColumns = []pdfColumn{
+	{header: "Title", width: 70, value: func(b model.Book) string { return b.Title }},
+	{header: "Author", width: 50, value: func(b model.Book) string { return b.Author }},
+	{header: "Category", width: 35, value: func(b model.Book) string { return b.Category }},
+	{header: "Year", width: 15, value: func(b model.Book) string {
+		if b.PublishedYear == 0 {
+			return ""
+		}
+		return strconv.Itoa(b.PublishedYear)
+	}},
+}
+
+// ExportService renders book lists as printable documents such as shelf
+// lists and handouts, and writes them out as data files for backups and
+// offline analysis
+type ExportService struct {
+	bookRepo BookReader
+	client   *httpclient.Client
+}
+
+func NewExportService(bookRepo BookReader, client *httpclient.Client) *ExportService {
+	return &ExportService{
+		bookRepo: bookRepo,
+		client:   client,
+	}
+}
+
+// ExportBooks renders the books matching query in the given format. Cover
+// downloads stop when ctx is done.
+func (s *ExportService) ExportBooks(ctx context.Context, query dto.BookQuery, format dto.ExportFormat, opts dto.ExportOptions) ([]byte, error) {
+	books, _, err := s.bookRepo.FindAll(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case dto.ExportPDF:
+		return s.renderPDF(ctx, books, opts)
+	case dto.ExportXLSX:
+		return renderXLSX(books)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// WriteBooks writes the books matching query to w as CSV or JSON, calling
+// flush after each batch so the client receives the export while it is
+// read. With a limit only that page is written; otherwise every matching
+// book is, in id order. CSV uses the import column names as its header, so
+// the file can be imported again; JSON is an array of books.
+func (s *ExportService) WriteBooks(ctx context.Context, query dto.BookQuery, format dto.ExportFormat, w io.Writer, flush func()) error {
+	var write func([]model.Book) error
+	var finish func() error
+	switch format {
+	case dto.ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(BookColumnNames()); err != nil {
+			return err
+		}
+		write = func(books []model.Book) error {
+			for _, book := range books {
+				if err := cw.Write(csvRecord(book)); err != nil {
+					return err
+				}
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+		finish = func() error { return nil }
+	case dto.ExportJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		sep := ""
+		write = func(books []model.Book) error {
+			for _, book := range books {
+				data, err := json.Marshal(book)
+				if err != nil {
+					return err
+				}
+				if _, err := io.WriteString(w, sep); err != nil {
+					return err
+				}
+				if _, err := w.Write(data); err != nil {
+					return err
+				}
+				sep = ","
+			}
+			return nil
+		}
+		finish = func() error {
+			_, err := io.WriteString(w, "]\n")
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	batch := func(books []model.Book) error {
+		// Stop reading once the client has gone away
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := write(books); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	}
+	if query.Limit > 0 {
+		books, _, err := s.bookRepo.FindAll(ctx, query)
+		if err != nil {
+			return err
+		}
+		if err := batch(books); err != nil {
+			return err
+		}
+	} else if err := s.bookRepo.FindInBatches(ctx, query, exportBatchSize, batch); err != nil {
+		return err
+	}
+	return finish()
+}
+
+// csvRecord is book's row in a CSV export. Unknown years and page counts
+// are left blank rather than 0, as in spreadsheets.
+func csvRecord(book model.Book) []string {
+	record := make([]string, len(bookColumns))
+	for i, col := range bookColumns {
+		value := col.get(book)
+		if col.numeric && value == 0 {
+			continue
+		}
+		record[i] = fmt.Sprint(value)
+	}
+	return record
+}
+
+// renderPDF lays the books out as a table on A4 pages, repeating the header
+// row on every page and numbering pages in the footer. Cover thumbnails are
+// drawn in an extra leading column when requested; covers that cannot be
+// fetched are left blank rather than failing the export.
+func (s *ExportService) renderPDF(ctx context.Context, books []model.Book, opts dto.ExportOptions) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	title := opts.Title
+	if title == "" {
+		title = "Book catalog"
+	}
+	pdf.SetTitle(title, true)
+
+	columns := pdfColumns
+	if opts.Covers {
+		// Make room for the thumbnails by narrowing the title column
+		columns = append([]pdfColumn(nil), pdfColumns...)
+		columns[0].width -= pdfCoverWidth + 2
+	}
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		if opts.Covers {
+			pdf.CellFormat(pdfCoverWidth+2, 7, "", "1", 0, "", true, 0, "")
+		}
+		for _, col := range columns {
+			pdf.CellFormat(col.width, 7, col.header, "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() == 1 {
+			pdf.SetFont("Helvetica", "B", 16)
+			pdf.CellFormat(0, 10, tr(title), "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.SetTextColor(100, 100, 100)
+			summary := fmt.Sprintf("%d books - generated %s", len(books), time.Now().Format("2 January 2006"))
+			pdf.CellFormat(0, 6, summary, "", 1, "L", false, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+			pdf.Ln(2)
+		}
+		header()
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 3)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+	_, pageHeight := pdf.GetPageSize()
+	for _, book := range books {
+		lines := make([][]string, len(columns))
+		rowLines := 1
+		for i, col := range columns {
+			lines[i] = splitCell(pdf, tr(col.value(book)), col.width-2)
+			if len(lines[i]) > rowLines {
+				rowLines = len(lines[i])
+			}
+		}
+		rowHeight := float64(rowLines)*pdfLineHeight + 1
+		if opts.Covers && rowHeight < pdfCoverHeight+2 {
+			rowHeight = pdfCoverHeight + 2
+		}
+		if pdf.GetY()+rowHeight > pageHeight-pdfMargin {
+			pdf.AddPage()
+		}
+
+		x, y := pdf.GetXY()
+		if opts.Covers {
+			pdf.Rect(x, y, pdfCoverWidth+2, rowHeight, "D")
+			if name := s.registerCover(ctx, pdf, book); name != "" {
+				pdf.ImageOptions(name, x+1, y+1, pdfCoverWidth, pdfCoverHeight, false, gofpdf.ImageOptions{}, 0, "")
+			}
+			x += pdfCoverWidth + 2
+		}
+		for i, col := range columns {
+			pdf.Rect(x, y, col.width, rowHeight, "D")
+			for j, line := range lines[i] {
+				pdf.SetXY(x+1, y+0.5+float64(j)*pdfLineHeight)
+				pdf.CellFormat(col.width-2, pdfLineHeight, line, "", 0, "L", false, 0, "")
+			}
+			x += col.width
+		}
+		pdf.SetXY(pdfMargin, y+rowHeight)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// splitCell wraps text to fit width, always returning at least one line
+func splitCell(pdf *gofpdf.Fpdf, text string, width float64) []string {
+	if text == "" {
+		return []string{""}
+	}
+	var lines []string
+	for _, line := range pdf.SplitLines([]byte(text), width) {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// registerCover downloads the book's cover and registers it with the document,
+// returning the image name or "" when there is no usable cover
+func (s *ExportService) registerCover(ctx context.Context, pdf *gofpdf.Fpdf, book model.Book) string {
+	if book.CoverURL == "" {
+		return ""
+	}
+	name := "cover-" + strconv.FormatUint(uint64(book.ID), 10)
+	if info := pdf.GetImageInfo(name); info != nil {
+		return name
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, coverFetchTimeout)
+	defer cancel()
+	resp, err := s.client.Get(ctx, book.CoverURL)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverBytes))
+	if err != nil {
+		return ""
+	}
+
+	var imageType string
+	switch http.DetectContentType(body) {
+	case "image/jpeg":
+		imageType = "JPG"
+	case "image/png":
+		imageType = "PNG"
+	case "image/gif":
+		imageType = "GIF"
+	default:
+		return ""
+	}
+
+	pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(body))
+	if !pdf.Ok() {
+		// A corrupt image sets the document error; drop it and carry on
+		// without the thumbnail
+		pdf.ClearError()
+		return ""
+	}
+	return name
+}
+
+// renderXLSX writes the books to a single worksheet using the import column
+// names as headers, so the file can be edited and imported again. Numeric
+// columns are stored as numbers, the header row is bold and frozen, and
+// columns are sized to their longest value.
+func renderXLSX(books []model.Book) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxSheet); err != nil {
+		return nil, err
+	}
+
+	widths := make([]int, len(bookColumns))
+	header := make([]interface{}, len(bookColumns))
+	for i, col := range bookColumns {
+		header[i] = col.name
+		widths[i] = len(col.name)
+	}
+	if err := f.SetSheetRow(xlsxSheet, "A1", &header); err != nil {
+		return nil, err
+	}
+
+	for n, book := range books {
+		row := make([]interface{}, len(bookColumns))
+		for i, col := range bookColumns {
+			value := col.get(book)
+			if col.numeric && value == 0 {
+				// Leave unknown years and page counts blank rather than 0
+				value = nil
+			}
+			row[i] = value
+			if w := utf8.RuneCountInString(fmt.Sprint(value)); value != nil && w > widths[i] {
+				widths[i] = w
+			}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, n+2)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.SetSheetRow(xlsxSheet, cell, &row); err != nil {
+			return nil, err
+		}
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	last, err := excelize.ColumnNumberToName(len(bookColumns))
+	if err != nil {
+		return nil, err
+	}
+	if err := f.SetCellStyle(xlsxSheet, "A1", last+"1", bold); err != nil {
+		return nil, err
+	}
+	for i, w := range widths {
+		name, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		if w > xlsxMaxColWidth {
+			w = xlsxMaxColWidth
+		}
+		if err := f.SetColWidth(xlsxSheet, name, name, float64(w+2)); err != nil {
+			return nil, err
+		}
+	}
+	err = f.SetPanes(xlsxSheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
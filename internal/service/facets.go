@@ -0,0 +1,66 @@
+package service
+
+import (
+	"bms-go/internal/model/dto"
+	"context"
+	"sync"
+)
+
+// FacetList is a lazily computed list of attribute values with their book
+// counts, such as the categories. It is kept until Invalidate, so the
+// GROUP BY behind it runs once per change to the books rather than once per
+// request.
+type FacetList struct {
+	load func(ctx context.Context) ([]dto.FacetCount, error)
+
+	mu         sync.RWMutex
+	counts     []dto.FacetCount
+	generation uint64
+}
+
+// NewFacetList returns a lazily built facet list over what load returns
+func NewFacetList(load func(ctx context.Context) ([]dto.FacetCount, error)) *FacetList {
+	return &FacetList{load: load}
+}
+
+// Invalidate drops the cached counts so the next Get reloads them
+func (f *FacetList) Invalidate() {
+	f.mu.Lock()
+	f.counts = nil
+	f.generation++
+	f.mu.Unlock()
+}
+
+// Get returns the cached counts, loading them first when needed
+func (f *FacetList) Get(ctx context.Context) ([]dto.FacetCount, error) {
+	f.mu.RLock()
+	counts, generation := f.counts, f.generation
+	f.mu.RUnlock()
+	if counts != nil {
+		return counts, nil
+	}
+
+	counts, err := f.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if counts == nil {
+		counts = []dto.FacetCount{}
+	}
+
+	// Keep the result only if no write invalidated the list while it was
+	// loading; otherwise it may already be out of date
+	f.mu.Lock()
+	if f.generation == generation {
+		f.counts = counts
+	}
+	f.mu.Unlock()
+	return counts, nil
+}
+
+// Rebuild reloads the counts now instead of on the next Get
+func (f *FacetList) Rebuild(ctx context.Context) error {
+	f.Invalidate()
+	_, err := f.Get(ctx)
+	return err
+}
@@ -1,78 +1,146 @@
 package service
 
 import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/cache"
 	"bms-go/internal/infra/repository"
 	"bms-go/internal/model"
 	"bms-go/internal/model/dto"
+	"context"
+	"expvar"
+	"log"
+	"time"
 )
 
+// ErrFavoritesPrivate is returned when a user's favorites are not shared
+var ErrFavoritesPrivate = apperror.New("FAVORITES_PRIVATE", "favorites are private")
+
+// favoriteCountsFixed counts books whose favorite_count had drifted, exposed
+// through expvar at /debug/vars
+var favoriteCountsFixed = expvar.NewInt("favorite_counts_fixed")
+
+// FavoriteBooks is what FavoriteService needs of the book repository
+type FavoriteBooks interface {
+	FindByID(ctx context.Context, id uint) (*model.Book, error)
+	ReconcileFavoriteCounts(ctx context.Context) (int64, error)
+}
+
 type FavoriteService struct {
-	repo     *repository.FavoriteRepository
-	bookRepo *repository.BookRepository
+	repo        FavoriteRepository
+	bookRepo    FavoriteBooks
+	privacyRepo *repository.PrivacyRepository
+	responses   *cache.ResponseCache
 }
 
-func NewFavoriteService(repo *repository.FavoriteRepository, bookRepo *repository.BookRepository) *FavoriteService {
-	return &FavoriteService{repo: repo, bookRepo: bookRepo}
+func NewFavoriteService(repo FavoriteRepository, bookRepo FavoriteBooks, privacyRepo *repository.PrivacyRepository, responses *cache.ResponseCache) *FavoriteService {
+	return &FavoriteService{repo: repo, bookRepo: bookRepo, privacyRepo: privacyRepo, responses: responses}
 }
 
-func (s *FavoriteService) GetFavorites(userID uint) ([]dto.FavoriteResponse, error) {
-	favs, err := s.repo.FindAll(userID)
+func (s *FavoriteService) GetFavorites(ctx context.Context, userID uint) ([]dto.FavoriteResponse, error) {
+	favs, err := s.repo.FindAll(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	var responses []dto.FavoriteResponse
 	for _, f := range favs {
-		book, err := s.bookRepo.FindByID(f.BookID)
+		book, err := s.bookRepo.FindByID(ctx, f.BookID)
 		if err != nil {
 			continue
 		}
+		bookResp := toBookResponse(*book)
 
 		responses = append(responses, dto.FavoriteResponse{
 			ID:     f.ID,
 			UserID: f.UserID,
 			BookID: f.BookID,
-			Book: &dto.BookResponse{
-				ID:       book.ID,
-				Title:    book.Title,
-				Author:   book.Author,
-				Category: book.Category,
-			},
+			Book:   &bookResp,
 		})
 	}
 
 	return responses, nil
 }
 
-func (s *FavoriteService) AddFavorite(userID uint, req dto.FavoriteRequest) (*dto.FavoriteResponse, error) {
+// GetPublicFavorites returns another user's favorites when that user has a
+// public profile and chose to show their favorites on it
+func (s *FavoriteService) GetPublicFavorites(ctx context.Context, userID uint) ([]dto.FavoriteResponse, error) {
+	setting, err := s.privacyRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !setting.FavoritesVisible() {
+		return nil, ErrFavoritesPrivate
+	}
+	return s.GetFavorites(ctx, userID)
+}
+
+func (s *FavoriteService) AddFavorite(ctx context.Context, userID uint, req dto.FavoriteRequest) (*dto.FavoriteResponse, error) {
 	fav := model.Favorite{
 		UserID: userID,
 		BookID: req.BookID,
 	}
 
-	if err := s.repo.Create(&fav); err != nil {
+	if err := s.repo.Create(ctx, &fav); err != nil {
 		return nil, err
 	}
+	// Favorite counts feed the popularity part of search relevance
+	s.responses.Invalidate(cache.TagFavorites)
 
-	book, err := s.bookRepo.FindByID(req.BookID)
+	book, err := s.bookRepo.FindByID(ctx, req.BookID)
 	if err != nil {
 		return nil, err
 	}
+	bookResp := toBookResponse(*book)
 
 	return &dto.FavoriteResponse{
 		ID:     fav.ID,
 		UserID: userID,
 		BookID: req.BookID,
-		Book: &dto.BookResponse{
-			ID:       book.ID,
-			Title:    book.Title,
-			Author:   book.Author,
-			Category: book.Category,
-		},
+		Book:   &bookResp,
 	}, nil
 }
 
 // RemoveFavorite deletes a favorite entry
-func (s *FavoriteService) RemoveFavorite(userID, favoriteID uint) error {
-	return s.repo.Delete(userID, favoriteID)
+func (s *FavoriteService) RemoveFavorite(ctx context.Context, userID, favoriteID uint) error {
+	if err := s.repo.Delete(ctx, userID, favoriteID); err != nil {
+		return err
+	}
+	s.responses.Invalidate(cache.TagFavorites)
+	return nil
+}
+
+// ReconcileCounts fixes books whose stored favorite count no longer matches
+// their favorites and returns how many were fixed
+func (s *FavoriteService) ReconcileCounts(ctx context.Context) (int64, error) {
+	fixed, err := s.bookRepo.ReconcileFavoriteCounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	favoriteCountsFixed.Add(fixed)
+	if fixed > 0 {
+		s.responses.Invalidate(cache.TagFavorites)
+	}
+	return fixed, nil
+}
+
+// Run reconciles favorite counts every interval until ctx is cancelled
+func (s *FavoriteService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fixed, err := s.ReconcileCounts(ctx)
+			if err != nil {
+				log.Printf("Favorite count reconciliation failed: %v", err)
+				continue
+			}
+			if fixed > 0 {
+				log.Printf("Favorite count reconciliation fixed %d books", fixed)
+			}
+		}
+	}
 }
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrGuestFavoritesFull is returned when a guest session already holds the
+// most favorites a guest may keep
+var ErrGuestFavoritesFull = apperror.New("GUEST_FAVORITES_FULL", "guest favorites are full; sign in to keep more")
+
+// guestFavoritesClaimed counts guest favorites moved into accounts, exposed
+// through expvar at /debug/vars
+var guestFavoritesClaimed = expvar.NewInt("guest_favorites_claimed")
+
+// GuestFavoriteService keeps favorites for visitors who have not signed in,
+// until they claim them into an account or the session expires
+type GuestFavoriteService struct {
+	repo         *repository.GuestFavoriteRepository
+	favRepo      FavoriteRepository
+	bookRepo     BookReader
+	responses    *cache.ResponseCache
+	maxFavorites int
+	ttl          time.Duration
+}
+
+func NewGuestFavoriteService(repo *repository.GuestFavoriteRepository, favRepo FavoriteRepository, bookRepo BookReader, responses *cache.ResponseCache, maxFavorites int, ttl time.Duration) *GuestFavoriteService {
+	return &GuestFavoriteService{repo: repo, favRepo: favRepo, bookRepo: bookRepo, responses: responses, maxFavorites: maxFavorites, ttl: ttl}
+}
+
+func (s *GuestFavoriteService) GetFavorites(ctx context.Context, sessionID string) ([]dto.GuestFavoriteResponse, error) {
+	favs, err := s.repo.FindAll(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.GuestFavoriteResponse{}
+	for _, f := range favs {
+		book, err := s.bookRepo.FindByID(ctx, f.BookID)
+		if err != nil {
+			continue
+		}
+		bookResp := toBookResponse(*book)
+		responses = append(responses, dto.GuestFavoriteResponse{
+			BookID:  f.BookID,
+			AddedAt: f.CreatedAt,
+			Book:    &bookResp,
+		})
+	}
+	return responses, nil
+}
+
+// AddFavorite adds a book to a guest session. Adding a book twice is not an
+// error.
+func (s *GuestFavoriteService) AddFavorite(ctx context.Context, sessionID string, req dto.FavoriteRequest) (*dto.GuestFavoriteResponse, error) {
+	book, err := s.bookRepo.FindByID(ctx, req.BookID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.Count(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.maxFavorites > 0 && count >= int64(s.maxFavorites) {
+		return nil, ErrGuestFavoritesFull
+	}
+
+	fav := model.GuestFavorite{SessionID: sessionID, BookID: req.BookID}
+	if err := s.repo.Create(&fav); err != nil {
+		return nil, err
+	}
+	bookResp := toBookResponse(*book)
+	return &dto.GuestFavoriteResponse{
+		BookID:  fav.BookID,
+		AddedAt: fav.CreatedAt,
+		Book:    &bookResp,
+	}, nil
+}
+
+func (s *GuestFavoriteService) RemoveFavorite(sessionID string, bookID uint) error {
+	return s.repo.Delete(sessionID, bookID)
+}
+
+// Claim moves a guest session's favorites to a user's favorites and ends
+// the session's list. Books the user already favorited are skipped, so
+// claiming twice is harmless.
+func (s *GuestFavoriteService) Claim(ctx context.Context, sessionID string, userID uint) (*dto.ClaimFavoritesResponse, error) {
+	guestFavs, err := s.repo.FindAll(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.favRepo.FindAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[uint]bool, len(existing))
+	for _, f := range existing {
+		owned[f.BookID] = true
+	}
+
+	claimed := 0
+	for _, g := range guestFavs {
+		if owned[g.BookID] {
+			continue
+		}
+		if err := s.favRepo.Create(ctx, &model.Favorite{UserID: userID, BookID: g.BookID}); err != nil {
+			return nil, fmt.Errorf("claim book %d: %w", g.BookID, err)
+		}
+		owned[g.BookID] = true
+		claimed++
+	}
+	if claimed > 0 {
+		// Favorite counts feed the popularity part of search relevance
+		s.responses.Invalidate(cache.TagFavorites)
+		guestFavoritesClaimed.Add(int64(claimed))
+	}
+
+	if err := s.repo.DeleteSession(sessionID); err != nil {
+		return nil, err
+	}
+	return &dto.ClaimFavoritesResponse{Claimed: claimed}, nil
+}
+
+// PurgeExpired drops the favorites of guest sessions idle for longer than
+// the session lifetime and returns how many were dropped
+func (s *GuestFavoriteService) PurgeExpired() (int64, error) {
+	return s.repo.DeleteStale(time.Now().Add(-s.ttl))
+}
+
+// Run purges expired guest favorites every interval until ctx is cancelled
+func (s *GuestFavoriteService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeExpired()
+			if err != nil {
+				log.Printf("Guest favorite cleanup failed: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Guest favorite cleanup removed %d favorites", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidILLStatus    = apperror.New("INVALID_ILL_STATUS", "status must be one of requested, ordered, received, returned, cancelled")
+	ErrILLStatusTransition = apperror.New("ILL_STATUS_TRANSITION", "the request cannot move to that status")
+)
+
+// ILLService tracks inter-library loan requests. Patrons ask for books the
+// library does not hold; staff record each step as the book is ordered,
+// arrives and goes back, and the patron is notified of every step.
+type ILLService struct {
+	repo          *repository.ILLRepository
+	notifications *NotificationService
+}
+
+func NewILLService(repo *repository.ILLRepository, notifications *NotificationService) *ILLService {
+	return &ILLService{repo: repo, notifications: notifications}
+}
+
+func (s *ILLService) Submit(userID uint, sub dto.ILLRequestSubmission) (*model.ILLRequest, error) {
+	req := model.ILLRequest{
+		UserID: userID,
+		Title:  sub.Title,
+		Author: sub.Author,
+		Notes:  sub.Notes,
+		Status: model.ILLRequested,
+		History: []model.ILLStatusChange{
+			{Status: model.ILLRequested, ChangedBy: userID},
+		},
+	}
+	if err := s.repo.Create(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (s *ILLService) GetRequests(status model.ILLStatus, userID uint) ([]model.ILLRequest, error) {
+	if status != "" && !status.Valid() {
+		return nil, ErrInvalidILLStatus
+	}
+	return s.repo.FindAll(status, userID)
+}
+
+// GetRequest returns a request. A non-zero userID limits it to that user's
+// own requests; others are reported as not found.
+func (s *ILLService) GetRequest(id, userID uint) (*model.ILLRequest, error) {
+	req, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 && req.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return req, nil
+}
+
+// Cancel withdraws userID's own request while the book has not arrived
+func (s *ILLService) Cancel(id, userID uint) (*model.ILLRequest, error) {
+	req, err := s.GetRequest(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.changeStatus(req, model.ILLStatusChange{Status: model.ILLCancelled, ChangedBy: userID}, "", false)
+}
+
+// UpdateStatus records the next step of a request as staffID
+func (s *ILLService) UpdateStatus(id, staffID uint, update dto.ILLStatusUpdate) (*model.ILLRequest, error) {
+	if !update.Status.Valid() {
+		return nil, ErrInvalidILLStatus
+	}
+	req, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	change := model.ILLStatusChange{Status: update.Status, Note: update.Note, ChangedBy: staffID}
+	return s.changeStatus(req, change, update.ExternalLibrary, true)
+}
+
+func (s *ILLService) changeStatus(req *model.ILLRequest, change model.ILLStatusChange, library string, notify bool) (*model.ILLRequest, error) {
+	changed, err := s.repo.ChangeStatus(req, change, library)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: %s to %s", ErrILLStatusTransition, req.Status, change.Status)
+	}
+
+	updated, err := s.repo.FindByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if notify {
+		s.notifications.Notify(updated.UserID, model.EventILLStatusChanged, ILLNotice{
+			Title:   updated.Title,
+			Status:  updated.Status,
+			Library: updated.ExternalLibrary,
+			Note:    change.Note,
+		}, fmt.Sprintf("/me/ill-requests/%d", updated.ID))
+	}
+	return updated, nil
+}
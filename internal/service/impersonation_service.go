@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImpersonationTokenPrefix marks impersonation tokens so they are never
+// mistaken for a user's own credentials
+const ImpersonationTokenPrefix = "imp_"
+
+var (
+	ErrInvalidImpersonation   = apperror.New("INVALID_IMPERSONATION", "invalid or revoked impersonation token")
+	ErrImpersonationForbidden = apperror.New("IMPERSONATION_FORBIDDEN", "only admins can impersonate users")
+	ErrImpersonationTarget    = apperror.New("IMPERSONATION_TARGET_NOT_FOUND", "user to impersonate not found")
+)
+
+// ImpersonationUsers looks up the admins and users taking part in
+// impersonation. UserService implements it.
+type ImpersonationUsers interface {
+	GetRole(id uint) (model.UserRole, error)
+	// GetAccount returns gorm.ErrRecordNotFound for users without an
+	// account
+	GetAccount(id uint) (*model.User, error)
+}
+
+// ImpersonationService lets support staff act as a user for a limited time.
+// Every request made under a session is kept as an audit record.
+type ImpersonationService struct {
+	repo  ImpersonationRepository
+	users ImpersonationUsers
+	ttl   time.Duration
+}
+
+func NewImpersonationService(repo ImpersonationRepository, users ImpersonationUsers, ttl time.Duration) *ImpersonationService {
+	return &ImpersonationService{repo: repo, users: users, ttl: ttl}
+}
+
+// Start opens a session acting as userID on behalf of adminID. Only admins
+// may impersonate, and only users with an account can be impersonated.
+func (s *ImpersonationService) Start(adminID, userID uint, req dto.ImpersonationRequest) (*dto.ImpersonationResponse, error) {
+	role, err := s.users.GetRole(adminID)
+	if err != nil {
+		return nil, err
+	}
+	if role != model.RoleAdmin {
+		return nil, ErrImpersonationForbidden
+	}
+	if _, err := s.users.GetAccount(userID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrImpersonationTarget
+	} else if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	token := ImpersonationTokenPrefix + secret
+
+	now := time.Now()
+	session := model.ImpersonationSession{
+		TokenHash: hashImpersonationToken(token),
+		UserID:    userID,
+		IssuedBy:  "user:" + strconv.FormatUint(uint64(adminID), 10),
+		Reason:    req.Reason,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.repo.Create(&session); err != nil {
+		return nil, err
+	}
+
+	resp := toImpersonationResponse(session)
+	resp.Token = token
+	return &resp, nil
+}
+
+// Authenticate returns the active session token belongs to
+func (s *ImpersonationService) Authenticate(token string) (*model.ImpersonationSession, error) {
+	if !strings.HasPrefix(token, ImpersonationTokenPrefix) {
+		return nil, ErrInvalidImpersonation
+	}
+
+	session, err := s.repo.FindByTokenHash(hashImpersonationToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidImpersonation
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !session.Active(time.Now()) {
+		return nil, ErrInvalidImpersonation
+	}
+	return session, nil
+}
+
+// RecordAction appends a request made under sessionID from clientIP to the
+// audit log
+func (s *ImpersonationService) RecordAction(sessionID uint, method, path string, status int, clientIP string) error {
+	return s.repo.CreateAction(&model.ImpersonationAction{
+		SessionID: sessionID,
+		Method:    method,
+		Path:      path,
+		Status:    status,
+		ClientIP:  clientIP,
+	})
+}
+
+// Revoke ends a session immediately; its token stops working on the next
+// request
+func (s *ImpersonationService) Revoke(id uint) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return err
+	}
+	return s.repo.Revoke(id)
+}
+
+func (s *ImpersonationService) GetSessions() ([]dto.ImpersonationResponse, error) {
+	sessions, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ImpersonationResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, toImpersonationResponse(session))
+	}
+	return responses, nil
+}
+
+func (s *ImpersonationService) GetActions(sessionID uint) ([]model.ImpersonationAction, error) {
+	if _, err := s.repo.FindByID(sessionID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindActions(sessionID)
+}
+
+func hashImpersonationToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+func toImpersonationResponse(s model.ImpersonationSession) dto.ImpersonationResponse {
+	return dto.ImpersonationResponse{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		IssuedBy:  s.IssuedBy,
+		Reason:    s.Reason,
+		CreatedAt: s.CreatedAt,
+		ExpiresAt: s.ExpiresAt,
+		RevokedAt: s.RevokedAt,
+	}
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// MaxImportRows is the most rows a single import accepts
+const MaxImportRows = 1000
+
+var (
+	ErrInvalidImportFile = apperror.New("INVALID_IMPORT_FILE", "invalid import file")
+	ErrImportRolledBack  = apperror.New("IMPORT_ROLLED_BACK", "import batch has already been rolled back")
+)
+
+// ImportService adds batches of books to the catalog. Rows that match an
+// existing book or an earlier row by title and author are handled according
+// to the duplicate policy. Every import path goes through ImportBooks so they
+// all report and deduplicate the same way. Imports that write are recorded
+// as batches so they can be rolled back.
+type ImportService struct {
+	repo    BookReader
+	batches *repository.ImportBatchRepository
+	books   *BookService
+}
+
+func NewImportService(repo BookReader, batches *repository.ImportBatchRepository, books *BookService) *ImportService {
+	return &ImportService{repo: repo, batches: batches, books: books}
+}
+
+// importMatch is the book a row key already refers to, and where it came
+// from for the report
+type importMatch struct {
+	book *model.Book
+	row  int
+}
+
+func (m importMatch) describe() string {
+	if m.row > 0 {
+		return fmt.Sprintf("row %d", m.row)
+	}
+	return fmt.Sprintf("existing book %d", m.book.ID)
+}
+
+// ParseImportFile reads book rows from an uploaded CSV or XLSX file whose
+// first row is the header. The rows can then be passed to ImportBooks.
+func (s *ImportService) ParseImportFile(r io.Reader, format dto.ImportFileFormat, opts dto.ImportFileOptions) ([]dto.BookRequest, error) {
+	var records [][]string
+	var err error
+	switch format {
+	case dto.ImportCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		records, err = reader.ReadAll()
+	case dto.ImportXLSX:
+		records, err = readSheet(r, opts.Sheet)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	rows, err := mapBookRows(records, opts.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no book rows", ErrInvalidImportFile)
+	}
+	if len(rows) > MaxImportRows {
+		return nil, fmt.Errorf("%w: %d rows, at most %d are allowed", ErrInvalidImportFile, len(rows), MaxImportRows)
+	}
+	return rows, nil
+}
+
+// readSheet returns the cell values of the named worksheet, or of the first
+// one when sheet is empty
+func readSheet(r io.Reader, sheet string) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	return f.GetRows(sheet)
+}
+
+// ImportBooks decides for each row whether it is created, updated or
+// skipped and why. With dryRun the decisions are reported without writing;
+// otherwise the import is recorded as a batch described by origin, and the
+// books it creates are marked as coming from it.
+func (s *ImportService) ImportBooks(ctx context.Context, rows []dto.BookRequest, policy dto.DuplicatePolicy, dryRun bool, origin dto.ImportOrigin) (*dto.ImportReport, error) {
+	titles := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if title := strings.Join(strings.Fields(row.Title), " "); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	existing, err := s.repo.FindByTitles(ctx, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[string]importMatch, len(existing)+len(rows))
+	for i := range existing {
+		key := catalogKey(existing[i])
+		if _, ok := matches[key]; !ok {
+			matches[key] = importMatch{book: &existing[i]}
+		}
+	}
+
+	report := &dto.ImportReport{DryRun: dryRun, OnDuplicate: policy, Rows: make([]dto.ImportRowResult, 0, len(rows))}
+	var batch model.ImportBatch
+	if !dryRun {
+		batch = model.ImportBatch{
+			UserID:      origin.UserID,
+			Format:      origin.Format,
+			Filename:    origin.Filename,
+			OnDuplicate: string(policy),
+		}
+		if err := s.batches.Create(&batch); err != nil {
+			return nil, err
+		}
+		report.BatchID = batch.ID
+	}
+	// updated holds the existing books the batch has already recorded as
+	// updated, so a rollback restores them to their state before the first
+	// row that changed them
+	updated := make(map[uint]bool)
+	for i, row := range rows {
+		result := dto.ImportRowResult{Row: i + 1, Title: row.Title, Author: row.Author}
+		book := model.Book{
+			Title:         strings.TrimSpace(row.Title),
+			Author:        strings.TrimSpace(row.Author),
+			Category:      strings.TrimSpace(row.Category),
+			Description:   row.Description,
+			PublishedYear: row.PublishedYear,
+			Pages:         row.Pages,
+			CoverURL:      row.CoverURL,
+		}
+		key := catalogKey(book)
+		match, duplicate := matches[key]
+
+		switch {
+		case book.Title == "" || book.Author == "" || book.Category == "":
+			result.Action = dto.ImportSkip
+			result.Reason = "title, author and category are required"
+
+		case !duplicate:
+			result.Action = dto.ImportCreate
+			result.Reason = "new book"
+
+		case policy == dto.DuplicateCreate:
+			result.Action = dto.ImportCreate
+			result.Reason = "duplicate of " + match.describe() + ", created anyway"
+
+		case policy == dto.DuplicateUpdate:
+			merged := *match.book
+			result.Fields = mergeNonEmpty(&merged, book)
+			result.BookID = merged.ID
+			if len(result.Fields) == 0 {
+				result.Action = dto.ImportSkip
+				result.Reason = "duplicate of " + match.describe() + ", nothing to update"
+				break
+			}
+			violates, err := s.violatesRules(merged, &result)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			if violates {
+				break
+			}
+			result.Action = dto.ImportUpdate
+			result.Reason = "duplicate of " + match.describe() + ", merged non-empty fields"
+			if !dryRun && merged.ID != 0 {
+				if err := s.books.UpdateBook(ctx, &merged); err != nil {
+					return nil, fmt.Errorf("row %d: %w", i+1, err)
+				}
+				// Books created by this batch are removed whole on rollback
+				if match.row == 0 && !updated[merged.ID] {
+					if err := s.recordItem(batch.ID, merged.ID, match.book); err != nil {
+						return nil, fmt.Errorf("row %d: %w", i+1, err)
+					}
+					updated[merged.ID] = true
+				}
+			}
+			*match.book = merged
+
+		default:
+			result.Action = dto.ImportSkip
+			result.BookID = match.book.ID
+			result.Reason = "duplicate of " + match.describe()
+		}
+		if result.Action == dto.ImportCreate {
+			if _, err := s.violatesRules(book, &result); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+		}
+
+		switch result.Action {
+		case dto.ImportCreate:
+			if !dryRun {
+				book.Source = model.SourceImport
+				book.ImportBatchID = &batch.ID
+				if err := s.books.CreateBook(ctx, &book); err != nil {
+					return nil, fmt.Errorf("row %d: %w", i+1, err)
+				}
+				result.BookID = book.ID
+				if err := s.recordItem(batch.ID, book.ID, nil); err != nil {
+					return nil, fmt.Errorf("row %d: %w", i+1, err)
+				}
+			}
+			if !duplicate {
+				matches[key] = importMatch{book: &book, row: i + 1}
+			}
+			report.Created++
+		case dto.ImportUpdate:
+			report.Updated++
+		default:
+			report.Skipped++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if !dryRun {
+		batch.Created, batch.Updated, batch.Skipped = report.Created, report.Updated, report.Skipped
+		if err := s.batches.Save(&batch); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// recordItem notes that batch wrote bookID. previous is the book before an
+// update and nil for a book the batch created.
+func (s *ImportService) recordItem(batchID, bookID uint, previous *model.Book) error {
+	item := model.ImportBatchItem{BatchID: batchID, BookID: bookID}
+	if previous != nil {
+		data, err := json.Marshal(previous)
+		if err != nil {
+			return err
+		}
+		item.Previous = data
+	}
+	return s.batches.AddItem(&item)
+}
+
+// GetBatches lists recorded imports, newest first
+func (s *ImportService) GetBatches() ([]model.ImportBatch, error) {
+	return s.batches.FindAll()
+}
+
+func (s *ImportService) GetBatch(id uint) (*model.ImportBatch, error) {
+	return s.batches.FindByID(id)
+}
+
+// RollbackBatch undoes import batch id: the books it created are deleted and
+// the books it updated get back their earlier fields. Books edited since the
+// import, or whose earlier fields break the current validation rules, are
+// left alone and reported, so a rollback never loses later work.
+func (s *ImportService) RollbackBatch(ctx context.Context, id uint) (*dto.ImportRollbackReport, error) {
+	batch, err := s.batches.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.RolledBackAt != nil {
+		return nil, ErrImportRolledBack
+	}
+	items, err := s.batches.FindItems(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ImportRollbackReport{BatchID: id, Skipped: []dto.ImportRollbackSkip{}}
+	skip := func(bookID uint, reason string) {
+		report.Skipped = append(report.Skipped, dto.ImportRollbackSkip{BookID: bookID, Reason: reason})
+	}
+	for _, item := range items {
+		book, err := s.repo.FindByID(ctx, item.BookID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			skip(item.BookID, "book no longer exists")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if book.UpdatedAt.After(item.CreatedAt) {
+			skip(item.BookID, "book was changed after the import")
+			continue
+		}
+
+		if len(item.Previous) == 0 {
+			if err := s.books.DeleteBook(ctx, item.BookID); err != nil {
+				return nil, err
+			}
+			report.Deleted++
+			continue
+		}
+		var previous model.Book
+		if err := json.Unmarshal(item.Previous, &previous); err != nil {
+			return nil, fmt.Errorf("book %d: %w", item.BookID, err)
+		}
+		err = s.books.UpdateBook(ctx, &previous)
+		var violation *RuleViolationError
+		if errors.As(err, &violation) {
+			skip(item.BookID, err.Error())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Restored++
+	}
+
+	now := time.Now()
+	batch.RolledBackAt = &now
+	if err := s.batches.Save(batch); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// violatesRules checks book against the validation rules and, when it
+// breaks any, marks the row skipped with the reasons
+func (s *ImportService) violatesRules(book model.Book, result *dto.ImportRowResult) (bool, error) {
+	err := s.books.CheckRules(book)
+	var violation *RuleViolationError
+	if !errors.As(err, &violation) {
+		return false, err
+	}
+	result.Action = dto.ImportSkip
+	result.Reason = err.Error()
+	return true, nil
+}
+
+// mergeNonEmpty copies src's non-empty catalog fields onto dst and returns
+// the names of the fields that changed
+func mergeNonEmpty(dst *model.Book, src model.Book) []string {
+	var fields []string
+	setString := func(name string, to *string, from string) {
+		if from != "" && *to != from {
+			*to = from
+			fields = append(fields, name)
+		}
+	}
+	setInt := func(name string, to *int, from int) {
+		if from != 0 && *to != from {
+			*to = from
+			fields = append(fields, name)
+		}
+	}
+
+	setString("title", &dst.Title, src.Title)
+	setString("author", &dst.Author, src.Author)
+	setString("category", &dst.Category, src.Category)
+	setString("description", &dst.Description, src.Description)
+	setInt("published_year", &dst.PublishedYear, src.PublishedYear)
+	setInt("pages", &dst.Pages, src.Pages)
+	setString("cover_url", &dst.CoverURL, src.CoverURL)
+	return fields
+}
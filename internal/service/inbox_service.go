@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bms-go/internal/infra/notify"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// inboxPurged counts inbox entries removed for being older than the
+// retention period, exposed through expvar at /debug/vars
+var inboxPurged = expvar.NewInt("inbox_purged")
+
+// InboxService keeps each user's notification history for in-app clients,
+// independent of the channels notifications are delivered through
+type InboxService struct {
+	repo      *repository.InboxRepository
+	retention time.Duration
+}
+
+// NewInboxService keeps notifications for retention; 0 keeps them forever
+func NewInboxService(repo *repository.InboxRepository, retention time.Duration) *InboxService {
+	return &InboxService{repo: repo, retention: retention}
+}
+
+// Add stores a rendered notification in the user's inbox
+func (s *InboxService) Add(userID uint, event model.NotificationEvent, msg notify.Message) error {
+	return s.repo.Create(&model.InboxNotification{
+		UserID: userID,
+		Event:  event,
+		Title:  msg.Title,
+		Body:   msg.Body,
+		URL:    msg.URL,
+	})
+}
+
+// GetPage returns up to limit notifications older than before, newest
+// first, with the user's unread count
+func (s *InboxService) GetPage(userID, before uint, unreadOnly bool, limit int) (*dto.InboxPage, error) {
+	notifications, err := s.repo.FindPage(userID, before, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	page := &dto.InboxPage{Data: notifications, Unread: unread}
+	if len(notifications) == limit {
+		page.NextBefore = notifications[len(notifications)-1].ID
+	}
+	return page, nil
+}
+
+func (s *InboxService) UnreadCount(userID uint) (int64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+func (s *InboxService) MarkRead(userID, id uint) error {
+	found, err := s.repo.MarkRead(userID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks the whole inbox read and returns how many
+// notifications were unread
+func (s *InboxService) MarkAllRead(userID uint) (int64, error) {
+	return s.repo.MarkAllRead(userID, time.Now())
+}
+
+func (s *InboxService) Delete(userID, id uint) error {
+	deleted, err := s.repo.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge removes notifications older than the retention period and returns
+// how many were removed
+func (s *InboxService) Purge() (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	purged, err := s.repo.DeleteBefore(time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+	inboxPurged.Add(purged)
+	return purged, nil
+}
+
+// Run purges old notifications every interval until ctx is cancelled
+func (s *InboxService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.Purge()
+			if err != nil {
+				log.Printf("Inbox cleanup failed: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Inbox cleanup removed %d notifications", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model/dto"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const shortCodeAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+var ErrInvalidShortCode = apperror.New("INVALID_SHORT_CODE", "invalid short code")
+
+// LinkService builds short links and QR codes that lead to book detail pages
+type LinkService struct {
+	bookRepo BookReader
+	baseURL  string
+}
+
+func NewLinkService(bookRepo BookReader, baseURL string) *LinkService {
+	return &LinkService{bookRepo: bookRepo, baseURL: baseURL}
+}
+
+// Resolve looks up the book behind a short code
+func (s *LinkService) Resolve(ctx context.Context, code string) (*dto.ShortLinkResponse, error) {
+	id, ok := decodeShortCode(code)
+	if !ok {
+		return nil, ErrInvalidShortCode
+	}
+
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ShortLinkResponse{
+		Code:     code,
+		ShortURL: s.shortURL(book.ID),
+		URL:      s.BookURL(book.ID),
+		Book:     toBookResponse(*book),
+	}, nil
+}
+
+// QRCode renders a PNG QR code of the book's short link for shelf labels
+func (s *LinkService) QRCode(ctx context.Context, bookID uint, size int) ([]byte, error) {
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(s.shortURL(bookID), qrcode.Medium, size)
+}
+
+// BookURL is the canonical address of a book's detail page
+func (s *LinkService) BookURL(bookID uint) string {
+	return s.baseURL + "/books/" + strconv.FormatUint(uint64(bookID), 10)
+}
+
+// ParseBookURL extracts the book id from a canonical book URL
+func (s *LinkService) ParseBookURL(rawURL string) (uint, bool) {
+	rest, ok := strings.CutPrefix(rawURL, s.baseURL+"/books/")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimSuffix(rest, "/"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (s *LinkService) shortURL(bookID uint) string {
+	return s.baseURL + "/r/" + encodeShortCode(bookID)
+}
+
+// encodeShortCode writes id in base 62 so labels stay short
+func encodeShortCode(id uint) string {
+	if id == 0 {
+		return string(shortCodeAlphabet[0])
+	}
+	var b []byte
+	for n := uint64(id); n > 0; n /= 62 {
+		b = append([]byte{shortCodeAlphabet[n%62]}, b...)
+	}
+	return string(b)
+}
+
+func decodeShortCode(code string) (uint, bool) {
+	if code == "" || len(code) > 6 {
+		return 0, false
+	}
+	var n uint64
+	for _, r := range code {
+		i := strings.IndexRune(shortCodeAlphabet, r)
+		if i < 0 {
+			return 0, false
+		}
+		n = n*62 + uint64(i)
+	}
+	if n == 0 || n > uint64(^uint32(0)) {
+		return 0, false
+	}
+	return uint(n), true
+}
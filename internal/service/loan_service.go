@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLoanNotFound     = apperror.New("LOAN_NOT_FOUND", "loan not found")
+	ErrLoanBookNotFound = apperror.New(apperror.CodeBookNotFound, "book not found")
+	ErrBookOnLoan       = apperror.New("BOOK_ON_LOAN", "every copy of the book is out on loan or held for a reservation")
+	ErrLoanReturned     = apperror.New("LOAN_RETURNED", "loan has already been returned")
+	ErrInvalidLoanRange = apperror.New("INVALID_LOAN_RANGE", "from must not be after to")
+)
+
+// Patrons finds the users loans are for. UserService implements it.
+type Patrons interface {
+	GetRole(id uint) (model.UserRole, error)
+	FindByCard(number string) (*model.User, error)
+}
+
+// StockListener is told when books' stock changes. BookService implements
+// it by dropping its cached book data.
+type StockListener interface {
+	BooksChanged()
+}
+
+// LoanService lends copies of books to users. Lending and returning a copy
+// change the book's stock, so both tell the StockListener, which is the
+// BookService in production. A returned copy is held for the next
+// reservation of the book for hold. Patrons who opted out of keeping their
+// loan history have their returned loans deleted after forgetAfter.
+type LoanService struct {
+	repo        LoanRepository
+	users       Patrons
+	books       StockListener
+	privacy     *repository.PrivacyRepository
+	period      time.Duration
+	hold        time.Duration
+	forgetAfter time.Duration
+}
+
+func NewLoanService(repo LoanRepository, users Patrons, books StockListener, privacy *repository.PrivacyRepository, period, hold, forgetAfter time.Duration) *LoanService {
+	return &LoanService{repo: repo, users: users, books: books, privacy: privacy, period: period, hold: hold, forgetAfter: forgetAfter}
+}
+
+// GetLoans returns a page of the loans matching query, newest first
+func (s *LoanService) GetLoans(ctx context.Context, query dto.LoanQuery) (*dto.LoanListResponse, error) {
+	loans, total, err := s.repo.FindAll(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoanListResponse{
+		Data: loans,
+		Meta: dto.LoanListMeta{
+			Count:  len(loans),
+			Total:  total,
+			Limit:  query.Limit,
+			Offset: query.Offset,
+		},
+	}, nil
+}
+
+// Borrow lends book bookID to userID for the loan period, fulfilling
+// userID's reservation of it
+func (s *LoanService) Borrow(ctx context.Context, userID, bookID uint) (*model.Loan, error) {
+	now := time.Now()
+	loan := model.Loan{
+		BookID:     bookID,
+		UserID:     userID,
+		BorrowedAt: now,
+		DueAt:      now.Add(s.period),
+	}
+	borrowed, err := s.repo.Borrow(ctx, &loan, s.hold)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLoanBookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !borrowed {
+		return nil, ErrBookOnLoan
+	}
+	s.books.BooksChanged()
+	return &loan, nil
+}
+
+// CheckoutByCard lends book bookID to the holder of library card number,
+// as Borrow does, for self-checkout kiosks that identify patrons by their
+// card. Disabled accounts cannot borrow.
+func (s *LoanService) CheckoutByCard(ctx context.Context, number string, bookID uint) (*model.Loan, error) {
+	user, err := s.users.FindByCard(number)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == model.UserDisabled {
+		return nil, ErrAccountDisabled
+	}
+	return s.Borrow(ctx, user.ID, bookID)
+}
+
+// Return closes loan id on behalf of userID. Borrowers may return their own
+// loans; librarians and admins check in anyone's.
+func (s *LoanService) Return(ctx context.Context, id, userID uint) (*model.Loan, error) {
+	loan, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLoanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if loan.UserID != userID {
+		role, err := s.users.GetRole(userID)
+		if err != nil {
+			return nil, err
+		}
+		// Other users' loans are not revealed to readers
+		if role != model.RoleLibrarian && role != model.RoleAdmin {
+			return nil, ErrLoanNotFound
+		}
+	}
+
+	returned, err := s.repo.Return(ctx, id, time.Now(), s.hold, loan)
+	if err != nil {
+		return nil, err
+	}
+	if !returned {
+		return nil, ErrLoanReturned
+	}
+	s.books.BooksChanged()
+	return loan, nil
+}
+
+// GetHistory returns userID's loans borrowed within query's days, newest
+// first, with how many books they borrowed and how often they returned them
+// on time
+func (s *LoanService) GetHistory(ctx context.Context, userID uint, query dto.LoanHistoryQuery) (*dto.LoanHistoryResponse, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		return nil, ErrInvalidLoanRange
+	}
+	to := query.To
+	if !to.IsZero() {
+		// To is a day, and loans borrowed on it count
+		to = to.AddDate(0, 0, 1)
+	}
+	entries, err := s.repo.History(ctx, userID, query.From, to)
+	if err != nil {
+		return nil, err
+	}
+	setting, err := s.privacy.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &dto.LoanHistoryResponse{
+		HistoryLimited: setting.ForgetLoanHistory,
+		Loans:          entries,
+	}
+	if history.Loans == nil {
+		history.Loans = []dto.LoanHistoryEntry{}
+	}
+	books := make(map[uint]bool)
+	for _, entry := range history.Loans {
+		history.Totals.Loans++
+		books[entry.BookID] = true
+		if entry.ReturnedAt != nil {
+			history.Totals.Returned++
+		}
+		if entry.ReturnedOnTime() {
+			history.Totals.ReturnedOnTime++
+		}
+	}
+	history.Totals.BooksBorrowed = len(books)
+	if history.Totals.Returned > 0 {
+		history.Totals.OnTimeRate = float64(history.Totals.ReturnedOnTime) / float64(history.Totals.Returned)
+	}
+	return history, nil
+}
+
+// LoanHistoryCSV renders a loan history as CSV, one row per loan
+func LoanHistoryCSV(history *dto.LoanHistoryResponse) ([]byte, error) {
+	rows := make([][]string, len(history.Loans))
+	for i, entry := range history.Loans {
+		returned, onTime := "", ""
+		if entry.ReturnedAt != nil {
+			returned = entry.ReturnedAt.Format(time.RFC3339)
+			onTime = strconv.FormatBool(entry.ReturnedOnTime())
+		}
+		rows[i] = []string{
+			strconv.FormatUint(uint64(entry.LoanID), 10),
+			strconv.FormatUint(uint64(entry.BookID), 10),
+			entry.Title,
+			entry.Author,
+			entry.BorrowedAt.Format(time.RFC3339),
+			entry.DueAt.Format(time.RFC3339),
+			returned,
+			onTime,
+		}
+	}
+	return renderCSV([]string{"loan_id", "book_id", "title", "author", "borrowed_at", "due_at", "returned_at", "returned_on_time"}, rows)
+}
+
+// ForgetHistory deletes the returned loans of patrons who opted out of
+// keeping their loan history once they are older than forgetAfter
+func (s *LoanService) ForgetHistory(ctx context.Context) (int64, error) {
+	return s.repo.ForgetReturned(ctx, time.Now().Add(-s.forgetAfter))
+}
+
+// Run forgets opted-out loan history every interval until ctx is cancelled
+func (s *LoanService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			forgotten, err := s.ForgetHistory(ctx)
+			if err != nil {
+				log.Printf("Loan history cleanup failed: %v", err)
+				continue
+			}
+			if forgotten > 0 {
+				log.Printf("Loan history cleanup: deleted %d returned loans", forgotten)
+			}
+		}
+	}
+}
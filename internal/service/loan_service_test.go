@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bms-go/internal/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeLoans keeps loans in memory. Only the methods Return uses are
+// implemented.
+type fakeLoans struct {
+	LoanRepository
+	loans    map[uint]model.Loan
+	returned []uint
+}
+
+func (f *fakeLoans) FindByID(ctx context.Context, id uint) (*model.Loan, error) {
+	loan, ok := f.loans[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &loan, nil
+}
+
+func (f *fakeLoans) Return(ctx context.Context, id uint, now time.Time, _ time.Duration, loan *model.Loan) (bool, error) {
+	stored := f.loans[id]
+	if stored.ReturnedAt != nil {
+		*loan = stored
+		return false, nil
+	}
+	stored.ReturnedAt = &now
+	f.loans[id] = stored
+	f.returned = append(f.returned, id)
+	*loan = stored
+	return true, nil
+}
+
+// fakePatrons serves roles from memory; users it does not know are readers
+type fakePatrons struct {
+	Patrons
+	roles map[uint]model.UserRole
+	err   error
+}
+
+func (f fakePatrons) GetRole(id uint) (model.UserRole, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if role, ok := f.roles[id]; ok {
+		return role, nil
+	}
+	return model.RoleReader, nil
+}
+
+type countingListener struct{ changes int }
+
+func (l *countingListener) BooksChanged() { l.changes++ }
+
+func TestLoanServiceReturnOwnership(t *testing.T) {
+	const (
+		borrower  = 1
+		otherUser = 2
+		librarian = 3
+		admin     = 4
+	)
+	roles := map[uint]model.UserRole{librarian: model.RoleLibrarian, admin: model.RoleAdmin}
+	lookupFailed := errors.New("role lookup failed")
+	returnedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		loan      uint
+		user      uint
+		roleErr   error
+		wantErr   error
+		checkedIn bool
+	}{
+		{"borrower returns their own loan", 10, borrower, nil, nil, true},
+		{"another reader cannot see the loan", 10, otherUser, nil, ErrLoanNotFound, false},
+		{"librarian checks in anyone's loan", 10, librarian, nil, nil, true},
+		{"admin checks in anyone's loan", 10, admin, nil, nil, true},
+		{"borrower skips the role lookup", 10, borrower, lookupFailed, nil, true},
+		{"role lookup failure for another user", 10, otherUser, lookupFailed, lookupFailed, false},
+		{"missing loan", 99, borrower, nil, ErrLoanNotFound, false},
+		{"loan already returned", 11, borrower, nil, ErrLoanReturned, false},
+		{"another reader cannot tell a returned loan exists", 11, otherUser, nil, ErrLoanNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans := &fakeLoans{loans: map[uint]model.Loan{
+				10: {ID: 10, BookID: 5, UserID: borrower},
+				11: {ID: 11, BookID: 5, UserID: borrower, ReturnedAt: &returnedAt},
+			}}
+			books := &countingListener{}
+			s := NewLoanService(loans, fakePatrons{roles: roles, err: tt.roleErr}, books, nil, 0, 0, 0)
+
+			loan, err := s.Return(context.Background(), tt.loan, tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Return(%d, %d) error = %v, want %v", tt.loan, tt.user, err, tt.wantErr)
+			}
+			if got := len(loans.returned) > 0; got != tt.checkedIn {
+				t.Fatalf("loan checked in = %v, want %v", got, tt.checkedIn)
+			}
+			if !tt.checkedIn {
+				if books.changes != 0 {
+					t.Errorf("stock reported changed %d times without a return", books.changes)
+				}
+				return
+			}
+			if loan == nil || loan.ReturnedAt == nil {
+				t.Fatalf("Return(%d, %d) = %+v, want a returned loan", tt.loan, tt.user, loan)
+			}
+			if books.changes != 1 {
+				t.Errorf("stock reported changed %d times, want 1", books.changes)
+			}
+		})
+	}
+}
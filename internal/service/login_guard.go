@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bms-go/config"
+	"log"
+	"sync"
+	"time"
+)
+
+// LockoutNotifier is told when an account gets locked out, so the owner can
+// be warned, e.g. by email
+type LockoutNotifier interface {
+	NotifyLockout(account string, until time.Time) error
+}
+
+// CaptchaVerifier checks a CAPTCHA token sent by a client that has failed
+// too often
+type CaptchaVerifier interface {
+	VerifyCaptcha(token, remoteIP string) (bool, error)
+}
+
+// LogNotifier reports lockouts in the server log. It is used until a real
+// notification channel is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) NotifyLockout(account string, until time.Time) error {
+	log.Printf("Authentication for %s locked until %s after repeated failures", account, until.Format(time.RFC3339))
+	return nil
+}
+
+// loginGuardSweepInterval is how often expired failure records are dropped
+const loginGuardSweepInterval = time.Minute
+
+type failureRecord struct {
+	failures    int
+	firstFailed time.Time
+	lockedUntil time.Time
+}
+
+// LoginGuard tracks failed authentication attempts per account and per
+// client IP. After too many failures the key is locked out for a period
+// that doubles with every further failure. Expired records are swept as
+// failures come in, and at most cfg.MaxTracked are kept, so failures with
+// made-up accounts cannot grow it without bound.
+type LoginGuard struct {
+	cfg      config.LockoutConfig
+	notifier LockoutNotifier
+	captcha  CaptchaVerifier
+
+	mu        sync.Mutex
+	records   map[string]*failureRecord
+	nextSweep time.Time
+}
+
+// NewLoginGuard returns a guard that reports lockouts to notifier. captcha
+// may be nil, in which case no CAPTCHA is ever required.
+func NewLoginGuard(cfg config.LockoutConfig, notifier LockoutNotifier, captcha CaptchaVerifier) *LoginGuard {
+	return &LoginGuard{cfg: cfg, notifier: notifier, captcha: captcha, records: make(map[string]*failureRecord)}
+}
+
+// LockedUntil reports when the latest lockout among keys ends, or the zero
+// time when none of them is locked
+func (g *LoginGuard) LockedUntil(keys ...string) time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	var until time.Time
+	for _, key := range keys {
+		if rec, ok := g.records[key]; ok && rec.lockedUntil.After(now) && rec.lockedUntil.After(until) {
+			until = rec.lockedUntil
+		}
+	}
+	return until
+}
+
+// CaptchaRequired reports whether any of keys has failed often enough that
+// the next attempt must carry a CAPTCHA token
+func (g *LoginGuard) CaptchaRequired(keys ...string) bool {
+	if g.captcha == nil || g.cfg.CaptchaAfter <= 0 {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		if rec, ok := g.records[key]; ok && !g.expired(rec, now) && rec.failures >= g.cfg.CaptchaAfter {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *LoginGuard) VerifyCaptcha(token, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	return g.captcha.VerifyCaptcha(token, remoteIP)
+}
+
+// RecordFailure counts a failed attempt against account and ip. Only the
+// account owner is notified; an IP lockout is merely logged.
+func (g *LoginGuard) RecordFailure(account, ip string) {
+	if until, locked := g.fail(account); locked && account != "" {
+		if err := g.notifier.NotifyLockout(account, until); err != nil {
+			log.Printf("Failed to send lockout notification for %s: %v", account, err)
+		}
+	}
+	if until, locked := g.fail(ip); locked {
+		log.Printf("Authentication from %s locked until %s after repeated failures", ip, until.Format(time.RFC3339))
+	}
+}
+
+// RecordSuccess forgets the account's failures. The IP's failures are kept
+// so one valid credential cannot be used to keep guessing others.
+func (g *LoginGuard) RecordSuccess(account string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.records, account)
+}
+
+// fail counts one failure for key and reports whether it started a lockout
+func (g *LoginGuard) fail(key string) (time.Time, bool) {
+	if key == "" {
+		return time.Time{}, false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	rec, ok := g.records[key]
+	if !ok && !g.makeRoom(now) {
+		// Locks already in place are kept rather than evicted to make room
+		return time.Time{}, false
+	}
+	if !ok || g.expired(rec, now) {
+		rec = &failureRecord{firstFailed: now}
+		g.records[key] = rec
+	}
+	rec.failures++
+
+	if rec.failures < g.cfg.MaxAttempts {
+		return time.Time{}, false
+	}
+
+	lockout := g.cfg.BaseLockout << (rec.failures - g.cfg.MaxAttempts)
+	if lockout <= 0 || lockout > g.cfg.MaxLockout {
+		lockout = g.cfg.MaxLockout
+	}
+	rec.lockedUntil = now.Add(lockout)
+	return rec.lockedUntil, true
+}
+
+// makeRoom drops expired records when a sweep is due and reports whether
+// another record fits. The caller holds g.mu.
+func (g *LoginGuard) makeRoom(now time.Time) bool {
+	if !now.Before(g.nextSweep) {
+		for key, rec := range g.records {
+			if g.expired(rec, now) {
+				delete(g.records, key)
+			}
+		}
+		g.nextSweep = now.Add(loginGuardSweepInterval)
+	}
+	return g.cfg.MaxTracked <= 0 || len(g.records) < g.cfg.MaxTracked
+}
+
+// expired reports whether rec's failures are old enough to be forgotten
+func (g *LoginGuard) expired(rec *failureRecord, now time.Time) bool {
+	return now.After(rec.lockedUntil) && now.Sub(rec.firstFailed) > g.cfg.Window
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bms-go/config"
+	"testing"
+	"time"
+)
+
+type recordingLockouts struct{ accounts []string }
+
+func (n *recordingLockouts) NotifyLockout(account string, _ time.Time) error {
+	n.accounts = append(n.accounts, account)
+	return nil
+}
+
+type acceptingCaptcha struct{}
+
+func (acceptingCaptcha) VerifyCaptcha(token, _ string) (bool, error) { return token == "solved", nil }
+
+var testLockout = config.LockoutConfig{
+	MaxAttempts:  3,
+	Window:       15 * time.Minute,
+	BaseLockout:  time.Minute,
+	MaxLockout:   5 * time.Minute,
+	CaptchaAfter: 2,
+}
+
+func newTestGuard(cfg config.LockoutConfig) (*LoginGuard, *recordingLockouts) {
+	notifier := &recordingLockouts{}
+	return NewLoginGuard(cfg, notifier, acceptingCaptcha{}), notifier
+}
+
+// lockedFor is how much longer keys stay locked, rounded up to the minute
+func lockedFor(g *LoginGuard, keys ...string) time.Duration {
+	until := g.LockedUntil(keys...)
+	if until.IsZero() {
+		return 0
+	}
+	return time.Until(until).Round(time.Minute)
+}
+
+func TestLoginGuardLocksOutAtThreshold(t *testing.T) {
+	g, notifier := newTestGuard(testLockout)
+
+	for i := 1; i < testLockout.MaxAttempts; i++ {
+		g.RecordFailure("email:a@example.com", "ip:192.0.2.1")
+		if locked := lockedFor(g, "email:a@example.com"); locked != 0 {
+			t.Fatalf("locked for %s after %d failures, want not locked", locked, i)
+		}
+	}
+
+	// Every failure past the threshold doubles the lockout up to the cap
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		g.RecordFailure("email:a@example.com", "ip:192.0.2.1")
+		if got := lockedFor(g, "email:a@example.com"); got != want {
+			t.Fatalf("account locked for %s, want %s", got, want)
+		}
+		if got := lockedFor(g, "ip:192.0.2.1"); got != want {
+			t.Fatalf("IP locked for %s, want %s", got, want)
+		}
+	}
+	if len(notifier.accounts) != 5 || notifier.accounts[0] != "email:a@example.com" {
+		t.Errorf("lockout notifications = %v, want one per lockout of the account", notifier.accounts)
+	}
+
+	if locked := lockedFor(g, "email:b@example.com", "ip:192.0.2.2"); locked != 0 {
+		t.Errorf("other account and IP locked for %s", locked)
+	}
+	// Either key being locked is enough
+	if locked := lockedFor(g, "email:b@example.com", "ip:192.0.2.1"); locked == 0 {
+		t.Error("another account from the locked IP is not locked")
+	}
+}
+
+func TestLoginGuardCountsAccountsAndIPsApart(t *testing.T) {
+	g, notifier := newTestGuard(testLockout)
+
+	// One IP guessing a different account each time
+	for _, account := range []string{"email:a@example.com", "email:b@example.com", "email:c@example.com"} {
+		g.RecordFailure(account, "ip:192.0.2.1")
+	}
+	if lockedFor(g, "ip:192.0.2.1") == 0 {
+		t.Error("IP is not locked after failing for several accounts")
+	}
+	if locked := lockedFor(g, "email:a@example.com"); locked != 0 {
+		t.Errorf("account failed once is locked for %s", locked)
+	}
+	if len(notifier.accounts) != 0 {
+		t.Errorf("account owners notified of an IP lockout: %v", notifier.accounts)
+	}
+}
+
+func TestLoginGuardReset(t *testing.T) {
+	t.Run("success forgets the account's failures", func(t *testing.T) {
+		g, _ := newTestGuard(testLockout)
+		for i := 0; i < testLockout.MaxAttempts; i++ {
+			g.RecordFailure("email:a@example.com", "ip:192.0.2.1")
+		}
+		g.RecordSuccess("email:a@example.com")
+
+		if locked := lockedFor(g, "email:a@example.com"); locked != 0 {
+			t.Fatalf("account locked for %s after a success, want not locked", locked)
+		}
+		if lockedFor(g, "ip:192.0.2.1") == 0 {
+			t.Fatal("IP lockout was lifted by a success")
+		}
+
+		// The count starts over
+		for i := 1; i < testLockout.MaxAttempts; i++ {
+			g.RecordFailure("email:a@example.com", "ip:192.0.2.9")
+		}
+		if locked := lockedFor(g, "email:a@example.com"); locked != 0 {
+			t.Fatalf("account locked for %s before reaching the threshold again", locked)
+		}
+	})
+
+	t.Run("failures outside the window are forgotten", func(t *testing.T) {
+		g, _ := newTestGuard(testLockout)
+		for i := 1; i < testLockout.MaxAttempts; i++ {
+			g.RecordFailure("email:a@example.com", "")
+		}
+		g.records["email:a@example.com"].firstFailed = time.Now().Add(-testLockout.Window - time.Second)
+
+		g.RecordFailure("email:a@example.com", "")
+		if locked := lockedFor(g, "email:a@example.com"); locked != 0 {
+			t.Fatalf("locked for %s counting failures from outside the window", locked)
+		}
+		if failures := g.records["email:a@example.com"].failures; failures != 1 {
+			t.Fatalf("failures = %d, want the count started over at 1", failures)
+		}
+	})
+
+	t.Run("an expired lockout starts over at the base", func(t *testing.T) {
+		g, _ := newTestGuard(testLockout)
+		for i := 0; i < testLockout.MaxAttempts+2; i++ {
+			g.RecordFailure("email:a@example.com", "")
+		}
+		rec := g.records["email:a@example.com"]
+		rec.lockedUntil = time.Now().Add(-time.Second)
+		rec.firstFailed = time.Now().Add(-testLockout.Window - time.Second)
+
+		if locked := lockedFor(g, "email:a@example.com"); locked != 0 {
+			t.Fatalf("locked for %s after the lockout ended", locked)
+		}
+		for i := 0; i < testLockout.MaxAttempts; i++ {
+			g.RecordFailure("email:a@example.com", "")
+		}
+		if got := lockedFor(g, "email:a@example.com"); got != testLockout.BaseLockout {
+			t.Fatalf("locked for %s, want the base lockout %s", got, testLockout.BaseLockout)
+		}
+	})
+}
+
+func TestLoginGuardCaptcha(t *testing.T) {
+	g, _ := newTestGuard(testLockout)
+	g.RecordFailure("email:a@example.com", "ip:192.0.2.1")
+	if g.CaptchaRequired("email:a@example.com", "ip:192.0.2.1") {
+		t.Fatal("CAPTCHA required after one failure")
+	}
+	g.RecordFailure("email:a@example.com", "ip:192.0.2.1")
+	if !g.CaptchaRequired("email:b@example.com", "ip:192.0.2.1") {
+		t.Fatal("CAPTCHA not required for the IP after two failures")
+	}
+	if ok, _ := g.VerifyCaptcha("", "192.0.2.1"); ok {
+		t.Error("empty CAPTCHA token accepted")
+	}
+	if ok, _ := g.VerifyCaptcha("solved", "192.0.2.1"); !ok {
+		t.Error("solved CAPTCHA rejected")
+	}
+
+	g.captcha = nil
+	if g.CaptchaRequired("email:a@example.com") {
+		t.Error("CAPTCHA required without a verifier")
+	}
+}
+
+func TestLoginGuardMaxTracked(t *testing.T) {
+	cfg := testLockout
+	cfg.MaxTracked = 2
+	g, _ := newTestGuard(cfg)
+
+	for i := 0; i < cfg.MaxAttempts; i++ {
+		g.RecordFailure("email:a@example.com", "ip:192.0.2.1")
+	}
+	for i := 0; i < cfg.MaxAttempts; i++ {
+		g.RecordFailure("email:b@example.com", "ip:192.0.2.2")
+	}
+	if len(g.records) != 2 {
+		t.Fatalf("tracking %d keys, want at most 2", len(g.records))
+	}
+	if lockedFor(g, "email:a@example.com") == 0 || lockedFor(g, "ip:192.0.2.1") == 0 {
+		t.Fatal("locks in place were evicted for new keys")
+	}
+	if locked := lockedFor(g, "email:b@example.com"); locked != 0 {
+		t.Fatalf("untracked account locked for %s", locked)
+	}
+}
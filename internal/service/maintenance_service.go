@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bms-go/internal/model/dto"
+	"context"
+)
+
+// Maintenance task kinds
+const (
+	TaskWarmCaches    = "cache_warm"
+	TaskReindexSearch = "search_reindex"
+)
+
+// MaintenanceService starts the admin tasks run after large imports or
+// deploys: warming the in-process caches and rebuilding the search indexes
+type MaintenanceService struct {
+	tasks    *TaskService
+	books    *BookService
+	bookRepo BookIndexer
+	sitemaps *SitemapService
+}
+
+func NewMaintenanceService(tasks *TaskService, books *BookService, bookRepo BookIndexer, sitemaps *SitemapService) *MaintenanceService {
+	return &MaintenanceService{tasks: tasks, books: books, bookRepo: bookRepo, sitemaps: sitemaps}
+}
+
+// WarmCaches rebuilds the spelling vocabulary, the category and author
+// lists and every sitemap document in the background
+func (s *MaintenanceService) WarmCaches() (dto.Task, error) {
+	return s.tasks.Start(TaskWarmCaches, func(progress TaskProgress) error {
+		// The task outlives the request that started it
+		ctx := context.Background()
+		progress("vocabulary", 0, 1)
+		if err := s.books.WarmSearch(ctx); err != nil {
+			return err
+		}
+		progress("vocabulary", 1, 1)
+
+		progress("facets", 0, 1)
+		if err := s.books.WarmFacets(ctx); err != nil {
+			return err
+		}
+		progress("facets", 1, 1)
+
+		return s.sitemaps.Warm(ctx, func(done, total int) {
+			progress("sitemap", done, total)
+		})
+	})
+}
+
+// ReindexSearch rebuilds the full-text indexes, then the spelling vocabulary
+// built from the same titles and authors, in the background. When semantic
+// search is on, missing and outdated embeddings are computed as well.
+func (s *MaintenanceService) ReindexSearch() (dto.Task, error) {
+	return s.tasks.Start(TaskReindexSearch, func(progress TaskProgress) error {
+		ctx := context.Background()
+		err := s.bookRepo.RebuildSearchIndex(ctx, func(done, total int) {
+			progress("search_index", done, total)
+		})
+		if err != nil {
+			return err
+		}
+
+		progress("vocabulary", 0, 1)
+		if err := s.books.WarmSearch(ctx); err != nil {
+			return err
+		}
+		progress("vocabulary", 1, 1)
+
+		progress("embeddings", 0, 1)
+		if err := s.books.WarmSemantic(ctx); err != nil {
+			return err
+		}
+		progress("embeddings", 1, 1)
+		return nil
+	})
+}
+
+func (s *MaintenanceService) GetTask(id uint64) (dto.Task, error) {
+	return s.tasks.Get(id)
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/notify"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const notificationSendTimeout = 30 * time.Second
+
+var (
+	ErrInvalidChannel           = apperror.New("INVALID_CHANNEL", "channel must be one of slack, telegram, webpush")
+	ErrChannelUnavailable       = apperror.New("CHANNEL_UNAVAILABLE", "channel is not configured on this server")
+	ErrInvalidNotificationEvent = apperror.New("INVALID_NOTIFICATION_EVENT", "events must be from change_request_reviewed, rsvp_promoted, ill_status_changed, loan_due_soon")
+	ErrNotificationTargetNeeded = apperror.New("NOTIFICATION_TARGET_NEEDED", "target is required")
+	ErrInvalidPushProvider      = apperror.New("INVALID_PUSH_PROVIDER", "provider must be one of fcm, apns")
+)
+
+// notificationsSent and notificationsFailed count deliveries per channel,
+// and pushSent and pushFailed count deliveries to devices per provider;
+// pushTokensRemoved counts devices dropped because their provider no longer
+// knows the token
+var (
+	notificationsSent   = expvar.NewMap("notifications_sent")
+	notificationsFailed = expvar.NewMap("notifications_failed")
+	pushSent            = expvar.NewMap("push_sent")
+	pushFailed          = expvar.NewMap("push_failed")
+	pushTokensRemoved   = expvar.NewInt("push_tokens_removed")
+)
+
+// defaultNotificationTemplates are used for events without a configured
+// override
+var defaultNotificationTemplates = map[model.NotificationEvent]dto.NotificationTemplate{
+	model.EventChangeRequestReviewed: {
+		Title: "Your edit to {{.BookTitle}} was {{.Status}}",
+		Body:  "{{if .Comment}}Reviewer note: {{.Comment}}{{end}}",
+	},
+	model.EventRSVPPromoted: {
+		Title: "You have a seat at {{.EventTitle}}",
+		Body:  "A seat opened up for {{.EventTitle}} in {{.Room}} on {{.StartsAt}}.",
+	},
+	model.EventILLStatusChanged: {
+		Title: "Your request for {{.Title}} is {{.Status}}",
+		Body:  "{{if .Library}}Lending library: {{.Library}}\n{{end}}{{.Note}}",
+	},
+	model.EventLoanDueSoon: {
+		Title: "{{.Title}} is due back on {{.DueOn}}",
+		Body:  "Return it by then to avoid it becoming overdue.{{if .UnsubscribeURL}}\nStop these reminders: {{.UnsubscribeURL}}{{end}}",
+	},
+}
+
+type notificationTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// ChangeRequestNotice is the template data of change_request_reviewed
+type ChangeRequestNotice struct {
+	BookTitle string
+	Status    model.ChangeRequestStatus
+	Comment   string
+}
+
+// RSVPNotice is the template data of rsvp_promoted
+type RSVPNotice struct {
+	EventTitle string
+	Room       string
+	StartsAt   string
+}
+
+// ILLNotice is the template data of ill_status_changed
+type ILLNotice struct {
+	Title   string
+	Status  model.ILLStatus
+	Library string
+	Note    string
+}
+
+// LoanDueNotice is the template data of loan_due_soon. UnsubscribeURL
+// turns the reminders off without signing in; it is empty when the server
+// cannot sign such links.
+type LoanDueNotice struct {
+	Title          string
+	DueOn          string
+	DaysLeft       int
+	UnsubscribeURL string
+}
+
+// NotificationService stores users' channel preferences and devices and
+// routes events to the channels and devices that want them
+type NotificationService struct {
+	repo      *repository.NotificationRepository
+	devices   *repository.DeviceRepository
+	inbox     *InboxService
+	channels  map[model.NotificationChannel]notify.Channel
+	push      map[model.PushProvider]notify.Channel
+	templates map[model.NotificationEvent]notificationTemplate
+	baseURL   string
+}
+
+// NewNotificationService uses the given channels and push providers,
+// leaving out ones the server is not configured for. Every notification is
+// also kept in inbox. overrides replaces built-in templates by event name.
+func NewNotificationService(repo *repository.NotificationRepository, devices *repository.DeviceRepository, inbox *InboxService, channels map[model.NotificationChannel]notify.Channel, push map[model.PushProvider]notify.Channel, overrides map[string]dto.NotificationTemplate, baseURL string) (*NotificationService, error) {
+	templates := make(map[model.NotificationEvent]notificationTemplate)
+	for event, tmpl := range defaultNotificationTemplates {
+		if override, ok := overrides[string(event)]; ok {
+			tmpl = override
+		}
+		title, err := template.New(string(event)).Parse(tmpl.Title)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", event, err)
+		}
+		body, err := template.New(string(event)).Parse(tmpl.Body)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", event, err)
+		}
+		templates[event] = notificationTemplate{title: title, body: body}
+	}
+	for name := range overrides {
+		if !model.NotificationEvent(name).Valid() {
+			return nil, fmt.Errorf("template for unknown event %q", name)
+		}
+	}
+
+	return &NotificationService{
+		repo:      repo,
+		devices:   devices,
+		inbox:     inbox,
+		channels:  channels,
+		push:      push,
+		templates: templates,
+		baseURL:   baseURL,
+	}, nil
+}
+
+// WebPushKey is the VAPID public key browsers need to subscribe
+func (s *NotificationService) WebPushKey() (string, error) {
+	push, ok := s.channels[model.ChannelWebPush].(*notify.WebPush)
+	if !ok {
+		return "", ErrChannelUnavailable
+	}
+	return push.PublicKey(), nil
+}
+
+func (s *NotificationService) GetPreferences(userID uint) ([]model.NotificationPreference, error) {
+	return s.repo.FindByUser(userID)
+}
+
+// SetPreference sets up or updates the user's channel. Fields left out of
+// req keep their stored values.
+func (s *NotificationService) SetPreference(userID uint, channel model.NotificationChannel, req dto.NotificationPreferenceRequest) (*model.NotificationPreference, error) {
+	ch, err := s.channel(channel)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range req.Events {
+		if !event.Valid() {
+			return nil, ErrInvalidNotificationEvent
+		}
+	}
+
+	pref, err := s.repo.Find(userID, channel)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		pref = &model.NotificationPreference{UserID: userID, Channel: channel, Enabled: true}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if req.Target != "" {
+		if err := ch.Validate(req.Target); err != nil {
+			return nil, err
+		}
+		pref.Target = req.Target
+	}
+	if pref.Target == "" {
+		return nil, ErrNotificationTargetNeeded
+	}
+	if req.Events != nil {
+		pref.Events = req.Events
+	}
+	if req.Enabled != nil {
+		pref.Enabled = *req.Enabled
+	}
+	if err := s.repo.Save(pref); err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+func (s *NotificationService) DeletePreference(userID uint, channel model.NotificationChannel) error {
+	if !channel.Valid() {
+		return ErrInvalidChannel
+	}
+	deleted, err := s.repo.Delete(userID, channel)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *NotificationService) GetDevices(userID uint) ([]model.Device, error) {
+	return s.devices.FindByUser(userID)
+}
+
+// RegisterDevice adds the app installation holding req.Token to the user's
+// devices. Apps register on every start, so a known token only has its
+// details refreshed, and moves to the user if another account had it.
+func (s *NotificationService) RegisterDevice(userID uint, req dto.DeviceRequest) (*model.Device, error) {
+	provider, err := s.pushProvider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.Validate(req.Token); err != nil {
+		return nil, err
+	}
+	for _, event := range req.Events {
+		if !event.Valid() {
+			return nil, ErrInvalidNotificationEvent
+		}
+	}
+
+	hash := hashPushToken(req.Provider, req.Token)
+	device, err := s.devices.FindByTokenHash(hash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		device = &model.Device{Provider: req.Provider, Token: req.Token, TokenHash: hash}
+	} else if err != nil {
+		return nil, err
+	}
+	device.UserID = userID
+	device.Platform = req.Platform
+	device.Name = req.Name
+	device.AppVersion = req.AppVersion
+	device.Events = req.Events
+	device.LastSeenAt = time.Now()
+	if err := s.devices.Save(device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *NotificationService) UnregisterDevice(userID, id uint) error {
+	deleted, err := s.devices.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SendTest delivers a test message through the user's channel right away so
+// they can check the setup. The send ends with ctx, the request asking for it.
+func (s *NotificationService) SendTest(ctx context.Context, userID uint, channel model.NotificationChannel) error {
+	ch, err := s.channel(channel)
+	if err != nil {
+		return err
+	}
+	pref, err := s.repo.Find(userID, channel)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	defer cancel()
+	return ch.Send(ctx, pref.Target, notify.Message{
+		Title: "Test notification",
+		Body:  "Notifications from the book catalog will arrive here.",
+		URL:   s.baseURL,
+	})
+}
+
+// Notify renders event with data, keeps it in the user's inbox and sends it
+// in the background to each of the user's channels and devices that wants
+// it. path is appended to the base URL as the message link. Failures are
+// logged, not returned.
+func (s *NotificationService) Notify(userID uint, event model.NotificationEvent, data interface{}, path string) {
+	tmpl, ok := s.templates[event]
+	if !ok {
+		log.Printf("notifications: no template for %s", event)
+		return
+	}
+	msg, err := tmpl.render(data)
+	if err != nil {
+		log.Printf("notifications: rendering %s: %v", event, err)
+		return
+	}
+	if path != "" {
+		msg.URL = s.baseURL + path
+	}
+	if err := s.inbox.Add(userID, event, msg); err != nil {
+		log.Printf("notifications: adding %s to inbox of user %d: %v", event, userID, err)
+	}
+
+	prefs, err := s.repo.FindByUser(userID)
+	if err != nil {
+		log.Printf("notifications: loading preferences of user %d: %v", userID, err)
+		return
+	}
+	for _, pref := range prefs {
+		ch, ok := s.channels[pref.Channel]
+		if !ok || !pref.Wants(event) {
+			continue
+		}
+		go s.deliver(ch, pref, msg)
+	}
+
+	devices, err := s.devices.FindByUser(userID)
+	if err != nil {
+		log.Printf("notifications: loading devices of user %d: %v", userID, err)
+		return
+	}
+	for _, device := range devices {
+		provider, ok := s.push[device.Provider]
+		if !ok || !device.Wants(event) {
+			continue
+		}
+		go s.deliverPush(provider, device, msg)
+	}
+}
+
+func (s *NotificationService) deliver(ch notify.Channel, pref model.NotificationPreference, msg notify.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout)
+	defer cancel()
+	if err := ch.Send(ctx, pref.Target, msg); err != nil {
+		notificationsFailed.Add(string(pref.Channel), 1)
+		log.Printf("notifications: %s to user %d: %v", pref.Channel, pref.UserID, err)
+		return
+	}
+	notificationsSent.Add(string(pref.Channel), 1)
+}
+
+// deliverPush sends to a device, forgetting it when the provider reports
+// that the app was uninstalled or the token replaced
+func (s *NotificationService) deliverPush(provider notify.Channel, device model.Device, msg notify.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout)
+	defer cancel()
+	err := provider.Send(ctx, device.Token, msg)
+	if err == nil {
+		pushSent.Add(string(device.Provider), 1)
+		return
+	}
+	pushFailed.Add(string(device.Provider), 1)
+	if !errors.Is(err, notify.ErrTargetGone) {
+		log.Printf("notifications: %s to device %d of user %d: %v", device.Provider, device.ID, device.UserID, err)
+		return
+	}
+	if err := s.devices.DeleteByID(device.ID); err != nil {
+		log.Printf("notifications: removing stale device %d: %v", device.ID, err)
+		return
+	}
+	pushTokensRemoved.Add(1)
+}
+
+func (s *NotificationService) pushProvider(provider model.PushProvider) (notify.Channel, error) {
+	if !provider.Valid() {
+		return nil, ErrInvalidPushProvider
+	}
+	p, ok := s.push[provider]
+	if !ok {
+		return nil, ErrChannelUnavailable
+	}
+	return p, nil
+}
+
+// hashPushToken is how a device is looked up without decrypting every
+// stored token
+func hashPushToken(provider model.PushProvider, token string) string {
+	digest := sha256.Sum256([]byte(string(provider) + ":" + token))
+	return hex.EncodeToString(digest[:])
+}
+
+func (s *NotificationService) channel(channel model.NotificationChannel) (notify.Channel, error) {
+	if !channel.Valid() {
+		return nil, ErrInvalidChannel
+	}
+	ch, ok := s.channels[channel]
+	if !ok {
+		return nil, ErrChannelUnavailable
+	}
+	return ch, nil
+}
+
+func (t notificationTemplate) render(data interface{}) (notify.Message, error) {
+	var title, body bytes.Buffer
+	if err := t.title.Execute(&title, data); err != nil {
+		return notify.Message{}, err
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return notify.Message{}, err
+	}
+	return notify.Message{
+		Title: strings.TrimSpace(title.String()),
+		Body:  strings.TrimSpace(body.String()),
+	}, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const opdsPageSize = 50
+
+// OPDSFilter narrows an OPDS acquisition feed
+type OPDSFilter struct {
+	Category string
+	Author   string
+	Search   string
+	Page     int
+}
+
+// OPDSService builds OPDS 1.2 catalog feeds so e-reader apps can browse books
+type OPDSService struct {
+	bookRepo BookReader
+	books    *BookService
+	baseURL  string
+}
+
+func NewOPDSService(bookRepo BookReader, books *BookService, baseURL string) *OPDSService {
+	return &OPDSService{bookRepo: bookRepo, books: books, baseURL: baseURL}
+}
+
+// Root is the start feed linking to the category, author and full listings
+func (s *OPDSService) Root() *dto.OPDSFeed {
+	feed := s.newFeed("root", "Book Management System", "/opds", dto.OPDSNavigationType)
+	feed.Entries = []dto.OPDSEntry{
+		s.navigationEntry("all", "All books", "/opds/books", dto.OPDSAcquisitionType),
+		s.navigationEntry("categories", "By category", "/opds/categories", dto.OPDSNavigationType),
+		s.navigationEntry("authors", "By author", "/opds/authors", dto.OPDSNavigationType),
+	}
+	return feed
+}
+
+// Categories lists every category as a link to its books
+func (s *OPDSService) Categories(ctx context.Context) (*dto.OPDSFeed, error) {
+	counts, err := s.books.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.facetFeed("categories", "Categories", "/opds/categories", "category", counts), nil
+}
+
+// Authors lists every author as a link to their books
+func (s *OPDSService) Authors(ctx context.Context) (*dto.OPDSFeed, error) {
+	counts, err := s.books.GetAuthors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.facetFeed("authors", "Authors", "/opds/authors", "author", counts), nil
+}
+
+// Books is a paginated acquisition feed of the books matching filter
+func (s *OPDSService) Books(filter OPDSFilter) (*dto.OPDSFeed, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	query := dto.BookQuery{
+		Search:    filter.Search,
+		Category:  filter.Category,
+		Author:    filter.Author,
+		Limit:     opdsPageSize + 1,
+		Offset:    (filter.Page - 1) * opdsPageSize,
+		SortBy:    dto.SortByTitle,
+		SortOrder: dto.SortAsc,
+	}
+	books, _, err := s.bookRepo.FindAll(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNext := len(books) > opdsPageSize
+	if hasNext {
+		books = books[:opdsPageSize]
+	}
+
+	title := "All books"
+	switch {
+	case filter.Search != "":
+		title = "Search: " + filter.Search
+	case filter.Category != "":
+		title = "Category: " + filter.Category
+	case filter.Author != "":
+		title = "Author: " + filter.Author
+	}
+
+	feed := s.newFeed("books", title, s.booksPath(filter), dto.OPDSAcquisitionType)
+	if filter.Page > 1 {
+		prev := filter
+		prev.Page--
+		feed.Links = append(feed.Links, dto.OPDSLink{Rel: "previous", Href: s.baseURL + s.booksPath(prev), Type: dto.OPDSAcquisitionType})
+	}
+	if hasNext {
+		next := filter
+		next.Page++
+		feed.Links = append(feed.Links, dto.OPDSLink{Rel: "next", Href: s.baseURL + s.booksPath(next), Type: dto.OPDSAcquisitionType})
+	}
+
+	for _, book := range books {
+		feed.Entries = append(feed.Entries, s.bookEntry(book))
+	}
+	return feed, nil
+}
+
+// SearchDescription is the OpenSearch document referenced by every feed
+func (s *OPDSService) SearchDescription() *dto.OpenSearchDescription {
+	return &dto.OpenSearchDescription{
+		Xmlns:       "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:   "BMS",
+		Description: "Search the book catalog",
+		URL: dto.OpenSearchURL{
+			Type:     dto.OPDSAcquisitionType,
+			Template: s.baseURL + "/opds/books?q={searchTerms}",
+		},
+	}
+}
+
+func (s *OPDSService) newFeed(id, title, path, kind string) *dto.OPDSFeed {
+	return &dto.OPDSFeed{
+		Xmlns:     "http://www.w3.org/2005/Atom",
+		XmlnsOPDS: "http://opds-spec.org/2010/catalog",
+		XmlnsDC:   "http://purl.org/dc/terms/",
+		ID:        "urn:bms-go:opds:" + id,
+		Title:     title,
+		Updated:   time.Now().UTC(),
+		Links: []dto.OPDSLink{
+			{Rel: "self", Href: s.baseURL + path, Type: kind},
+			{Rel: "start", Href: s.baseURL + "/opds", Type: dto.OPDSNavigationType},
+			{Rel: "search", Href: s.baseURL + "/opds/opensearch.xml", Type: dto.OpenSearchType},
+		},
+	}
+}
+
+func (s *OPDSService) navigationEntry(id, title, path, kind string) dto.OPDSEntry {
+	return dto.OPDSEntry{
+		ID:      "urn:bms-go:opds:" + id,
+		Title:   title,
+		Updated: time.Now().UTC(),
+		Links:   []dto.OPDSLink{{Rel: "subsection", Href: s.baseURL + path, Type: kind}},
+	}
+}
+
+func (s *OPDSService) facetFeed(id, title, path, param string, counts []dto.FacetCount) *dto.OPDSFeed {
+	feed := s.newFeed(id, title, path, dto.OPDSNavigationType)
+	for _, c := range counts {
+		entry := s.navigationEntry(id+":"+url.QueryEscape(c.Value), c.Value, "/opds/books?"+param+"="+url.QueryEscape(c.Value), dto.OPDSAcquisitionType)
+		entry.Content = &dto.OPDSContent{Type: "text", Body: strconv.FormatInt(c.Count, 10) + " books"}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// bookEntry describes one book. Books have no attached files yet, so entries
+// only link to the book's details rather than an acquisition.
+func (s *OPDSService) bookEntry(book model.Book) dto.OPDSEntry {
+	id := strconv.FormatUint(uint64(book.ID), 10)
+	entry := dto.OPDSEntry{
+		ID:      "urn:bms-go:book:" + id,
+		Title:   book.Title,
+		Updated: book.UpdatedAt.UTC(),
+		Links: []dto.OPDSLink{
+			{Rel: "alternate", Href: s.baseURL + "/books/" + id, Type: "application/json"},
+		},
+	}
+	if book.Author != "" {
+		entry.Authors = []dto.OPDSAuthor{{Name: book.Author}}
+	}
+	if book.Category != "" {
+		entry.Categories = []dto.OPDSCategory{{Term: book.Category, Label: book.Category}}
+	}
+	if book.PublishedYear > 0 {
+		entry.Issued = strconv.Itoa(book.PublishedYear)
+	}
+	if book.Description != "" {
+		entry.Content = &dto.OPDSContent{Type: "text", Body: book.Description}
+	}
+	return entry
+}
+
+func (s *OPDSService) booksPath(filter OPDSFilter) string {
+	values := url.Values{}
+	if filter.Search != "" {
+		values.Set("q", filter.Search)
+	}
+	if filter.Category != "" {
+		values.Set("category", filter.Category)
+	}
+	if filter.Author != "" {
+		values.Set("author", filter.Author)
+	}
+	if filter.Page > 1 {
+		values.Set("page", strconv.Itoa(filter.Page))
+	}
+	if len(values) == 0 {
+		return "/opds/books"
+	}
+	return "/opds/books?" + values.Encode()
+}
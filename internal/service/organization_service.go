@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"time"
+)
+
+// invitationTTL is how long an invitation can be accepted
+const invitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrNotOrgMember      = apperror.New("ORGANIZATION_NOT_FOUND", "organization not found")
+	ErrOrgForbidden      = apperror.New("ORG_FORBIDDEN", "your role in this organization does not allow that")
+	ErrInvalidOrgRole    = apperror.New("INVALID_ORG_ROLE", "role must be owner, admin or member")
+	ErrAlreadyOrgMember  = apperror.New("ALREADY_ORG_MEMBER", "user is already a member")
+	ErrLastOrgOwner      = apperror.New("LAST_ORG_OWNER", "an organization needs at least one owner")
+	ErrInvitationClosed  = apperror.New("INVITATION_CLOSED", "invitation is no longer open")
+	ErrInvitationNotOurs = apperror.New("INVITATION_NOT_FOUND", "invitation not found")
+)
+
+// OrganizationService manages teams that share a library of favorite books.
+// Members can read and add to the team library, admins also manage
+// members, and owners can additionally appoint owners and delete the team.
+type OrganizationService struct {
+	repo     *repository.OrganizationRepository
+	bookRepo BookReader
+}
+
+func NewOrganizationService(repo *repository.OrganizationRepository, bookRepo BookReader) *OrganizationService {
+	return &OrganizationService{repo: repo, bookRepo: bookRepo}
+}
+
+func (s *OrganizationService) CreateOrganization(userID uint, req dto.OrganizationRequest) (*dto.OrganizationResponse, error) {
+	org := model.Organization{Name: req.Name}
+	if err := s.repo.Create(&org, userID); err != nil {
+		return nil, err
+	}
+	return &dto.OrganizationResponse{ID: org.ID, Name: org.Name, Role: model.OrgRoleOwner}, nil
+}
+
+func (s *OrganizationService) GetOrganizations(userID uint) ([]dto.OrganizationResponse, error) {
+	orgs, roles, err := s.repo.FindByMember(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrganizationResponse, len(orgs))
+	for i, org := range orgs {
+		responses[i] = dto.OrganizationResponse{ID: org.ID, Name: org.Name, Role: roles[i]}
+	}
+	return responses, nil
+}
+
+// GetOrganization returns the organization with its members, visible to
+// members only
+func (s *OrganizationService) GetOrganization(userID, orgID uint) (*dto.OrganizationResponse, error) {
+	member, err := s.authorize(userID, orgID, model.OrgRoleMember)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.repo.FindByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.FindMembers(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.OrganizationResponse{ID: org.ID, Name: org.Name, Role: member.Role}
+	for _, m := range members {
+		resp.Members = append(resp.Members, dto.OrgMemberDTO{UserID: m.UserID, Role: m.Role, JoinedAt: m.CreatedAt})
+	}
+	return resp, nil
+}
+
+func (s *OrganizationService) DeleteOrganization(userID, orgID uint) error {
+	if _, err := s.authorize(userID, orgID, model.OrgRoleOwner); err != nil {
+		return err
+	}
+	return s.repo.Delete(orgID)
+}
+
+// Invite offers req.UserID membership. Only owners may invite owners.
+func (s *OrganizationService) Invite(userID, orgID uint, req dto.InvitationRequest) (*model.OrganizationInvitation, error) {
+	if !req.Role.Valid() {
+		return nil, ErrInvalidOrgRole
+	}
+	inviter, err := s.authorize(userID, orgID, model.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !inviter.Role.AtLeast(req.Role) {
+		return nil, ErrOrgForbidden
+	}
+
+	existing, err := s.repo.FindMember(orgID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyOrgMember
+	}
+
+	now := time.Now()
+	inv := model.OrganizationInvitation{
+		OrganizationID: orgID,
+		UserID:         req.UserID,
+		Role:           req.Role,
+		InvitedBy:      userID,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(invitationTTL),
+	}
+	if err := s.repo.CreateInvitation(&inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (s *OrganizationService) GetInvitations(userID uint) ([]model.OrganizationInvitation, error) {
+	return s.repo.FindOpenInvitations(userID)
+}
+
+// RespondToInvitation accepts or declines one of the user's open invitations
+func (s *OrganizationService) RespondToInvitation(userID, invitationID uint, accept bool) error {
+	inv, err := s.repo.FindInvitation(invitationID)
+	if err != nil {
+		return err
+	}
+	if inv.UserID != userID {
+		return ErrInvitationNotOurs
+	}
+	if inv.AcceptedAt != nil || inv.DeclinedAt != nil || time.Now().After(inv.ExpiresAt) {
+		return ErrInvitationClosed
+	}
+
+	if accept {
+		return s.repo.AcceptInvitation(inv)
+	}
+	now := time.Now()
+	inv.DeclinedAt = &now
+	return s.repo.SaveInvitation(inv)
+}
+
+// ChangeRole sets a member's role. Admins manage members, owners manage
+// everyone, and the last owner cannot be demoted.
+func (s *OrganizationService) ChangeRole(userID, orgID, memberID uint, role model.OrgRole) error {
+	if !role.Valid() {
+		return ErrInvalidOrgRole
+	}
+	actor, err := s.authorize(userID, orgID, model.OrgRoleAdmin)
+	if err != nil {
+		return err
+	}
+	target, err := s.repo.FindMember(orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotOrgMember
+	}
+	if !actor.Role.AtLeast(role) || !actor.Role.AtLeast(target.Role) {
+		return ErrOrgForbidden
+	}
+	if role != model.OrgRoleOwner {
+		if err := s.keepAnOwner(orgID, target); err != nil {
+			return err
+		}
+	}
+
+	target.Role = role
+	return s.repo.SaveMember(target)
+}
+
+// RemoveMember removes memberID from the organization. Any member may leave
+// on their own.
+func (s *OrganizationService) RemoveMember(userID, orgID, memberID uint) error {
+	minRole := model.OrgRoleAdmin
+	if userID == memberID {
+		minRole = model.OrgRoleMember
+	}
+	actor, err := s.authorize(userID, orgID, minRole)
+	if err != nil {
+		return err
+	}
+	target, err := s.repo.FindMember(orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotOrgMember
+	}
+	if userID != memberID && !actor.Role.AtLeast(target.Role) {
+		return ErrOrgForbidden
+	}
+	if err := s.keepAnOwner(orgID, target); err != nil {
+		return err
+	}
+	return s.repo.DeleteMember(orgID, memberID)
+}
+
+func (s *OrganizationService) GetFavorites(ctx context.Context, userID, orgID uint) ([]dto.OrgFavoriteResponse, error) {
+	if _, err := s.authorize(userID, orgID, model.OrgRoleMember); err != nil {
+		return nil, err
+	}
+	favs, err := s.repo.FindFavorites(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrgFavoriteResponse, 0, len(favs))
+	for _, f := range favs {
+		book, err := s.bookRepo.FindByID(ctx, f.BookID)
+		if err != nil {
+			continue
+		}
+		bookResp := toBookResponse(*book)
+		responses = append(responses, dto.OrgFavoriteResponse{
+			ID:      f.ID,
+			BookID:  f.BookID,
+			AddedBy: f.AddedBy,
+			AddedAt: f.CreatedAt,
+			Book:    &bookResp,
+		})
+	}
+	return responses, nil
+}
+
+func (s *OrganizationService) AddFavorite(ctx context.Context, userID, orgID uint, req dto.OrgFavoriteRequest) (*dto.OrgFavoriteResponse, error) {
+	if _, err := s.authorize(userID, orgID, model.OrgRoleMember); err != nil {
+		return nil, err
+	}
+	book, err := s.bookRepo.FindByID(ctx, req.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	fav := model.OrganizationFavorite{OrganizationID: orgID, BookID: req.BookID, AddedBy: userID}
+	if err := s.repo.CreateFavorite(&fav); err != nil {
+		return nil, err
+	}
+
+	bookResp := toBookResponse(*book)
+	return &dto.OrgFavoriteResponse{
+		ID:      fav.ID,
+		BookID:  fav.BookID,
+		AddedBy: fav.AddedBy,
+		AddedAt: fav.CreatedAt,
+		Book:    &bookResp,
+	}, nil
+}
+
+func (s *OrganizationService) RemoveFavorite(userID, orgID, favoriteID uint) error {
+	if _, err := s.authorize(userID, orgID, model.OrgRoleMember); err != nil {
+		return err
+	}
+	return s.repo.DeleteFavorite(orgID, favoriteID)
+}
+
+// authorize returns userID's membership of orgID when it has at least
+// minRole. Non-members get ErrNotOrgMember so organizations they cannot see
+// look like they do not exist.
+func (s *OrganizationService) authorize(userID, orgID uint, minRole model.OrgRole) (*model.OrganizationMember, error) {
+	member, err := s.repo.FindMember(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotOrgMember
+	}
+	if !member.Role.AtLeast(minRole) {
+		return nil, ErrOrgForbidden
+	}
+	return member, nil
+}
+
+// keepAnOwner fails when target is the organization's only owner
+func (s *OrganizationService) keepAnOwner(orgID uint, target *model.OrganizationMember) error {
+	if target.Role != model.OrgRoleOwner {
+		return nil
+	}
+	owners, err := s.repo.CountOwners(orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOrgOwner
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownPartner    = apperror.New("UNKNOWN_PARTNER", "unknown partner")
+	ErrInvalidSignature  = apperror.New("INVALID_SIGNATURE", "invalid request signature")
+	ErrStaleSignature    = apperror.New("STALE_SIGNATURE", "request timestamp outside the allowed window")
+	ErrReplayedSignature = apperror.New("REPLAYED_SIGNATURE", "request nonce already used")
+)
+
+// SignedRequest is what a partner signs. The signature is the hex HMAC-SHA256
+// of StringToSign under the partner's secret.
+type SignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	URI       string
+	Body      []byte
+}
+
+// StringToSign joins the method, request URI, timestamp, nonce and body
+// digest with newlines
+func (r SignedRequest) StringToSign() string {
+	bodyDigest := sha256.Sum256(r.Body)
+	return r.Method + "\n" + r.URI + "\n" + r.Timestamp + "\n" + r.Nonce + "\n" + hex.EncodeToString(bodyDigest[:])
+}
+
+// PartnerService manages partner secrets and verifies their signed requests
+type PartnerService struct {
+	repo      *repository.PartnerRepository
+	tolerance time.Duration
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// NewPartnerService accepts signatures whose timestamp is within tolerance of
+// the server clock. Nonces are remembered for twice that long.
+func NewPartnerService(repo *repository.PartnerRepository, tolerance time.Duration) *PartnerService {
+	return &PartnerService{repo: repo, tolerance: tolerance, nonces: make(map[string]time.Time)}
+}
+
+// Verify checks req's signature, freshness and nonce, returning the partner
+// that signed it
+func (s *PartnerService) Verify(req SignedRequest) (*model.Partner, error) {
+	partner, err := s.repo.FindByKeyID(req.KeyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnknownPartner
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !partner.Active {
+		return nil, ErrUnknownPartner
+	}
+
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrStaleSignature
+	}
+	signedAt := time.Unix(unix, 0)
+	if d := time.Since(signedAt); d > s.tolerance || d < -s.tolerance {
+		return nil, ErrStaleSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(partner.Secret))
+	mac.Write([]byte(req.StringToSign()))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if req.Nonce == "" || !hmac.Equal([]byte(expected), []byte(req.Signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	if !s.rememberNonce(partner.KeyID + ":" + req.Nonce) {
+		return nil, ErrReplayedSignature
+	}
+	return partner, nil
+}
+
+// rememberNonce records a nonce and reports false if it was already seen.
+// Expired nonces are swept on the way, since their timestamps would be
+// rejected as stale anyway.
+func (s *PartnerService) rememberNonce(nonce string) bool {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for n, expires := range s.nonces {
+		if now.After(expires) {
+			delete(s.nonces, n)
+		}
+	}
+	if _, seen := s.nonces[nonce]; seen {
+		return false
+	}
+	s.nonces[nonce] = now.Add(2 * s.tolerance)
+	return true
+}
+
+func (s *PartnerService) GetPartners() ([]dto.PartnerResponse, error) {
+	partners, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PartnerResponse, 0, len(partners))
+	for _, p := range partners {
+		responses = append(responses, toPartnerResponse(p, false))
+	}
+	return responses, nil
+}
+
+// CreatePartner registers a partner with a fresh key id and secret. The
+// secret is only ever returned here and by RotateSecret.
+func (s *PartnerService) CreatePartner(req dto.PartnerRequest) (*dto.PartnerResponse, error) {
+	keyID, err := randomHex(12)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	partner := model.Partner{Name: req.Name, KeyID: keyID, Secret: secret, Active: true}
+	if err := s.repo.Create(&partner); err != nil {
+		return nil, err
+	}
+
+	resp := toPartnerResponse(partner, true)
+	return &resp, nil
+}
+
+func (s *PartnerService) RotateSecret(id uint) (*dto.PartnerResponse, error) {
+	partner, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	partner.Secret = secret
+	if err := s.repo.Update(partner); err != nil {
+		return nil, err
+	}
+
+	resp := toPartnerResponse(*partner, true)
+	return &resp, nil
+}
+
+func (s *PartnerService) DeletePartner(id uint) error {
+	return s.repo.Delete(id)
+}
+
+func toPartnerResponse(p model.Partner, withSecret bool) dto.PartnerResponse {
+	resp := dto.PartnerResponse{ID: p.ID, Name: p.Name, KeyID: p.KeyID, Active: p.Active}
+	if withSecret {
+		resp.Secret = p.Secret
+	}
+	return resp
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+)
+
+type PrivacyService struct {
+	repo     *repository.PrivacyRepository
+	viewRepo *repository.BookViewRepository
+}
+
+func NewPrivacyService(repo *repository.PrivacyRepository, viewRepo *repository.BookViewRepository) *PrivacyService {
+	return &PrivacyService{repo: repo, viewRepo: viewRepo}
+}
+
+func (s *PrivacyService) GetSettings(userID uint) (*dto.PrivacySettingsResponse, error) {
+	setting, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toPrivacySettingsResponse(*setting)
+	return &resp, nil
+}
+
+// UpdateSettings stores the user's choices. Turning off recently viewed
+// tracking also forgets the views recorded so far.
+func (s *PrivacyService) UpdateSettings(userID uint, req dto.PrivacySettingsRequest) (*dto.PrivacySettingsResponse, error) {
+	setting, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TrackRecentlyViewed != nil {
+		setting.TrackRecentlyViewed = *req.TrackRecentlyViewed
+	}
+	if req.PublicProfile != nil {
+		setting.PublicProfile = *req.PublicProfile
+	}
+	if req.ShowFavorites != nil {
+		setting.ShowFavorites = *req.ShowFavorites
+	}
+	if req.AllowAnalytics != nil {
+		setting.AllowAnalytics = *req.AllowAnalytics
+	}
+	if req.MarketingEmails != nil {
+		setting.MarketingEmails = *req.MarketingEmails
+	}
+	if req.ForgetLoanHistory != nil {
+		setting.ForgetLoanHistory = *req.ForgetLoanHistory
+	}
+	if err := s.repo.Save(setting); err != nil {
+		return nil, err
+	}
+
+	if !setting.TrackRecentlyViewed {
+		if err := s.viewRepo.DeleteByUser(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := toPrivacySettingsResponse(*setting)
+	return &resp, nil
+}
+
+func toPrivacySettingsResponse(s model.PrivacySetting) dto.PrivacySettingsResponse {
+	return dto.PrivacySettingsResponse{
+		TrackRecentlyViewed: s.TrackRecentlyViewed,
+		PublicProfile:       s.PublicProfile,
+		ShowFavorites:       s.ShowFavorites,
+		AllowAnalytics:      s.AllowAnalytics,
+		MarketingEmails:     s.MarketingEmails,
+		ForgetLoanHistory:   s.ForgetLoanHistory,
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"time"
+)
+
+// QuotaService enforces monthly request allowances per API key or user.
+// Subjects without an override get the default limit; 0 means unlimited.
+type QuotaService struct {
+	repo         *repository.QuotaRepository
+	defaultLimit int64
+}
+
+func NewQuotaService(repo *repository.QuotaRepository, defaultLimit int64) *QuotaService {
+	return &QuotaService{repo: repo, defaultLimit: defaultLimit}
+}
+
+// Consume counts one request for subject and reports whether it was within
+// the subject's monthly quota. Rejected requests are not counted.
+func (s *QuotaService) Consume(subject string) (*dto.UsageResponse, bool, error) {
+	usage, err := s.GetUsage(subject)
+	if err != nil {
+		return nil, false, err
+	}
+	if usage.Limit > 0 && usage.Used >= usage.Limit {
+		return usage, false, nil
+	}
+
+	if err := s.repo.IncrementUsage(subject, usage.Period); err != nil {
+		return nil, false, err
+	}
+	usage.Used++
+	if usage.Limit > 0 {
+		usage.Remaining--
+	}
+	return usage, true, nil
+}
+
+// GetUsage reports how much of the current month's quota subject has used
+func (s *QuotaService) GetUsage(subject string) (*dto.UsageResponse, error) {
+	limit, err := s.limitFor(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	period := now.Format("2006-01")
+	used, err := s.repo.FindUsage(subject, period)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &dto.UsageResponse{
+		Subject:  subject,
+		Period:   period,
+		Used:     used,
+		Limit:    limit,
+		ResetsAt: time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if limit > 0 {
+		usage.Remaining = max(limit-used, 0)
+	}
+	return usage, nil
+}
+
+func (s *QuotaService) GetQuotas() ([]dto.QuotaResponse, error) {
+	quotas, err := s.repo.FindAllQuotas()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.QuotaResponse, 0, len(quotas))
+	for _, q := range quotas {
+		responses = append(responses, dto.QuotaResponse{Subject: q.Subject, MonthlyLimit: q.MonthlyLimit})
+	}
+	return responses, nil
+}
+
+func (s *QuotaService) SetQuota(subject string, req dto.QuotaRequest) (*dto.QuotaResponse, error) {
+	quota := model.APIQuota{Subject: subject, MonthlyLimit: *req.MonthlyLimit}
+	if err := s.repo.SaveQuota(&quota); err != nil {
+		return nil, err
+	}
+	return &dto.QuotaResponse{Subject: quota.Subject, MonthlyLimit: quota.MonthlyLimit}, nil
+}
+
+func (s *QuotaService) limitFor(subject string) (int64, error) {
+	quota, err := s.repo.FindQuota(subject)
+	if err != nil {
+		return 0, err
+	}
+	if quota == nil {
+		return s.defaultLimit, nil
+	}
+	return quota.MonthlyLimit, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// RateLimitStatus is a client's standing in the current rate limit window
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	Exceeded  bool
+}
+
+// RateCounter counts one more request by client in the window starting at
+// start and returns the client's count in it
+type RateCounter interface {
+	Add(ctx context.Context, client string, start time.Time, window time.Duration) (int, error)
+}
+
+// RateLimiter counts requests per client in fixed windows shared by all
+// clients
+type RateLimiter struct {
+	limit   int
+	window  time.Duration
+	counter RateCounter
+}
+
+// NewRateLimiter returns a limiter counting in memory, so each instance
+// limits on its own
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return NewSharedRateLimiter(limit, window, &memoryRateCounter{counts: make(map[string]int)})
+}
+
+// NewSharedRateLimiter returns a limiter keeping its counts in counter, so
+// instances sharing it enforce one limit between them
+func NewSharedRateLimiter(limit int, window time.Duration, counter RateCounter) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, counter: counter}
+}
+
+// Take counts one request by client. Requests over the limit are counted
+// too, so a client hammering the API stays over it. When the count cannot
+// be taken the request is let through.
+func (l *RateLimiter) Take(ctx context.Context, client string) RateLimitStatus {
+	start := time.Now().Truncate(l.window)
+	used, err := l.counter.Add(ctx, client, start, l.window)
+	if err != nil {
+		log.Printf("rate limit: counting %s: %v", client, err)
+		used = 0
+	}
+
+	remaining := l.limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return RateLimitStatus{
+		Limit:     l.limit,
+		Remaining: remaining,
+		Reset:     start.Add(l.window),
+		Exceeded:  used > l.limit,
+	}
+}
+
+// Policy describes the limit as in the RateLimit-Policy header
+func (l *RateLimiter) Policy() (limit int, window time.Duration) {
+	return l.limit, l.window
+}
+
+// memoryRateCounter keeps the counts of the current window only
+type memoryRateCounter struct {
+	mu     sync.Mutex
+	start  time.Time
+	counts map[string]int
+}
+
+func (c *memoryRateCounter) Add(_ context.Context, client string, start time.Time, _ time.Duration) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !start.Equal(c.start) {
+		c.start = start
+		c.counts = make(map[string]int)
+	}
+	c.counts[client]++
+	return c.counts[client], nil
+}
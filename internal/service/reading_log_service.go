@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxReadingLogRows is the most rows a single reading log import accepts
+const MaxReadingLogRows = 5000
+
+var (
+	ErrInvalidReadingLog   = apperror.New("INVALID_READING_LOG", "invalid reading log file")
+	ErrInvalidReadingState = apperror.New("INVALID_READING_STATE", "status must be one of want_to_read, reading, read")
+)
+
+// readingLogDateLayouts are the accepted date_finished formats. Exports of
+// other reading trackers commonly use slashes.
+var readingLogDateLayouts = []string{"2006-01-02", "2006/01/02"}
+
+// ReadingLogService keeps users' personal reading statuses. A reading log
+// exported from elsewhere can be imported: each row is matched to a
+// catalog book and marked as read, and its rating is added as the user's
+// review of the book.
+type ReadingLogService struct {
+	repo      *repository.ReadingStatusRepository
+	reviews   *repository.ReviewRepository
+	bookRepo  BookReader
+	responses *cache.ResponseCache
+}
+
+func NewReadingLogService(repo *repository.ReadingStatusRepository, reviews *repository.ReviewRepository, bookRepo BookReader, responses *cache.ResponseCache) *ReadingLogService {
+	return &ReadingLogService{repo: repo, reviews: reviews, bookRepo: bookRepo, responses: responses}
+}
+
+// GetStatuses returns userID's reading statuses, optionally only those with
+// status
+func (s *ReadingLogService) GetStatuses(userID uint, status model.ReadingState) ([]model.ReadingStatus, error) {
+	if status != "" && !status.Valid() {
+		return nil, ErrInvalidReadingState
+	}
+	return s.repo.FindByUser(userID, status)
+}
+
+// ParseReadingLog reads reading log rows from a CSV file whose first row is
+// the header. Columns are found by name: title or isbn is required, and
+// author, date_finished, rating (1-5, 0 or empty for none) and notes are
+// optional.
+func ParseReadingLog(r io.Reader) ([]dto.ReadingLogRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReadingLog, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidReadingLog)
+	}
+
+	positions := make(map[string]int, len(records[0]))
+	for i, header := range records[0] {
+		header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if _, ok := positions[header]; !ok {
+			positions[header] = i
+		}
+	}
+	_, hasTitle := positions["title"]
+	_, hasISBN := positions["isbn"]
+	if !hasTitle && !hasISBN {
+		return nil, fmt.Errorf("%w: missing title or isbn column", ErrInvalidReadingLog)
+	}
+	field := func(record []string, name string) string {
+		pos, ok := positions[name]
+		if !ok || pos >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[pos])
+	}
+
+	rows := make([]dto.ReadingLogRow, 0, len(records)-1)
+	for n, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		// n+2 accounts for the header and 1-based line numbers
+		row := dto.ReadingLogRow{
+			Line:   n + 2,
+			ISBN:   field(record, "isbn"),
+			Title:  field(record, "title"),
+			Author: field(record, "author"),
+			Notes:  field(record, "notes"),
+		}
+		if raw := field(record, "date_finished"); raw != "" {
+			finished, err := parseReadingLogDate(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d, date_finished: %v", ErrInvalidReadingLog, row.Line, err)
+			}
+			row.FinishedAt = &finished
+		}
+		if raw := field(record, "rating"); raw != "" {
+			rating, err := strconv.Atoi(raw)
+			if err != nil || rating < 0 || rating > 5 {
+				return nil, fmt.Errorf("%w: line %d, rating: must be a whole number from 0 to 5", ErrInvalidReadingLog, row.Line)
+			}
+			row.Rating = rating
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidReadingLog)
+	}
+	if len(rows) > MaxReadingLogRows {
+		return nil, fmt.Errorf("%w: %d rows, at most %d are allowed", ErrInvalidReadingLog, len(rows), MaxReadingLogRows)
+	}
+	return rows, nil
+}
+
+func parseReadingLogDate(raw string) (time.Time, error) {
+	for _, layout := range readingLogDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date", raw)
+}
+
+// ImportReadingLog marks the books of rows as read by userID, replacing
+// their earlier status of those books, and adds each rating as their review
+// unless they already reviewed the book. Rows are matched by title, and by
+// author when the row has one; the catalog has no ISBNs, so rows with only
+// an ISBN cannot be matched. When a book appears in several rows the last
+// one wins. Rows that match no book, or more than one, are reported as
+// unmatched. With dryRun nothing is written.
+func (s *ReadingLogService) ImportReadingLog(ctx context.Context, userID uint, rows []dto.ReadingLogRow, dryRun bool) (*dto.ReadingLogImportReport, error) {
+	titles := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if title := normalizeTerm(row.Title); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	books, err := s.bookRepo.FindByTitles(ctx, titles)
+	if err != nil {
+		return nil, err
+	}
+	byTitle := make(map[string][]model.Book, len(books))
+	for _, book := range books {
+		key := normalizeTerm(book.Title)
+		byTitle[key] = append(byTitle[key], book)
+	}
+
+	report := &dto.ReadingLogImportReport{DryRun: dryRun, Rows: len(rows), Unmatched: []dto.ReadingLogUnmatched{}}
+	statuses := make([]model.ReadingStatus, 0, len(rows))
+	// position is where each matched book's status is in statuses, so a
+	// later row for the same book replaces it
+	position := make(map[uint]int)
+	ratings := make(map[uint]int)
+	var rated []uint
+	for _, row := range rows {
+		book, reason := matchReadingLogRow(row, byTitle)
+		if book == nil {
+			report.Unmatched = append(report.Unmatched, dto.ReadingLogUnmatched{
+				Line:   row.Line,
+				ISBN:   row.ISBN,
+				Title:  row.Title,
+				Author: row.Author,
+				Reason: reason,
+			})
+			continue
+		}
+
+		status := model.ReadingStatus{
+			UserID:     userID,
+			BookID:     book.ID,
+			Status:     model.ReadingFinished,
+			FinishedAt: row.FinishedAt,
+			Notes:      row.Notes,
+		}
+		if i, ok := position[book.ID]; ok {
+			statuses[i] = status
+		} else {
+			position[book.ID] = len(statuses)
+			statuses = append(statuses, status)
+		}
+		if row.Rating > 0 {
+			if _, ok := ratings[book.ID]; !ok {
+				rated = append(rated, book.ID)
+			}
+			ratings[book.ID] = row.Rating
+		}
+	}
+	report.Imported = len(statuses)
+
+	reviewed, err := s.reviews.FindReviewedBooks(userID, rated)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range reviewed {
+		delete(ratings, id)
+		report.RatingsKept++
+	}
+	reviews := make([]model.Review, 0, len(ratings))
+	for _, id := range rated {
+		if rating, ok := ratings[id]; ok {
+			reviews = append(reviews, model.Review{BookID: id, UserID: userID, Rating: rating})
+		}
+	}
+	report.Reviewed = len(reviews)
+
+	if dryRun {
+		return report, nil
+	}
+	if err := s.repo.Import(statuses, reviews); err != nil {
+		return nil, err
+	}
+	if len(reviews) > 0 {
+		// Book responses carry the rating
+		s.responses.Invalidate(cache.TagBooks)
+	}
+	return report, nil
+}
+
+// matchReadingLogRow returns the one book in byTitle that row refers to, or
+// why there is none
+func matchReadingLogRow(row dto.ReadingLogRow, byTitle map[string][]model.Book) (*model.Book, string) {
+	title := normalizeTerm(row.Title)
+	if title == "" {
+		if row.ISBN != "" {
+			return nil, "books are matched by title and the catalog has no ISBNs; add the book's title"
+		}
+		return nil, "title is required"
+	}
+	candidates := byTitle[title]
+	if row.Author != "" {
+		author := normalizeTerm(row.Author)
+		var byAuthor []model.Book
+		for _, book := range candidates {
+			if bookHasAuthor(book, author) {
+				byAuthor = append(byAuthor, book)
+			}
+		}
+		candidates = byAuthor
+	}
+
+	switch {
+	case len(candidates) == 1:
+		return &candidates[0], ""
+	case len(candidates) == 0 && row.Author != "":
+		return nil, "no book with this title by this author"
+	case len(candidates) == 0:
+		return nil, "no book with this title"
+	case row.Author != "":
+		return nil, fmt.Sprintf("%d books with this title by this author", len(candidates))
+	default:
+		return nil, fmt.Sprintf("%d books with this title; add the author to pick one", len(candidates))
+	}
+}
+
+// bookHasAuthor reports whether author, normalized by normalizeTerm, is the book's author line or one of its authors
+func bookHasAuthor(book model.Book, author string) bool {
+	if normalizeTerm(book.Author) == author {
+		return true
+	}
+	for _, a := range book.Authors {
+		if normalizeTerm(a.Name) == author {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model/dto"
+)
+
+type RecentlyViewedService struct {
+	repo        *repository.BookViewRepository
+	privacyRepo *repository.PrivacyRepository
+	limit       int
+}
+
+// NewRecentlyViewedService keeps up to limit views per user
+func NewRecentlyViewedService(repo *repository.BookViewRepository, privacyRepo *repository.PrivacyRepository, limit int) *RecentlyViewedService {
+	return &RecentlyViewedService{repo: repo, privacyRepo: privacyRepo, limit: limit}
+}
+
+// RecordView remembers that userID opened bookID, unless the user has turned
+// tracking off
+func (s *RecentlyViewedService) RecordView(userID, bookID uint) error {
+	setting, err := s.privacyRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if !setting.TrackRecentlyViewed {
+		return nil
+	}
+	return s.repo.Record(userID, bookID, s.limit)
+}
+
+func (s *RecentlyViewedService) GetRecentlyViewed(userID uint) ([]dto.RecentlyViewedResponse, error) {
+	books, err := s.repo.FindRecent(userID, s.limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.RecentlyViewedResponse, 0, len(books))
+	for _, b := range books {
+		responses = append(responses, dto.RecentlyViewedResponse{
+			Book:     toBookResponse(b.Book),
+			ViewedAt: b.ViewedAt,
+		})
+	}
+	return responses, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bms-go/config"
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidUnsubscribeLink = apperror.New("INVALID_UNSUBSCRIBE_LINK", "unsubscribe link is invalid")
+
+// Notifier delivers notifications to a user's channels and devices.
+// NotificationService implements it.
+type Notifier interface {
+	Notify(userID uint, event model.NotificationEvent, data interface{}, path string)
+}
+
+// ReminderService reminds borrowers of loans coming due, on the days before
+// the due date each user chose or on the configured days. Reminders go out
+// as loan_due_soon notifications and carry a signed link that turns them
+// off without signing in.
+type ReminderService struct {
+	repo     ReminderRepository
+	notifier Notifier
+	cfg      config.ReminderConfig
+	baseURL  string
+}
+
+func NewReminderService(repo ReminderRepository, notifier Notifier, cfg config.ReminderConfig, baseURL string) *ReminderService {
+	return &ReminderService{repo: repo, notifier: notifier, cfg: cfg, baseURL: baseURL}
+}
+
+func (s *ReminderService) GetSettings(userID uint) (*dto.ReminderSettingsResponse, error) {
+	setting, err := s.setting(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.response(setting), nil
+}
+
+func (s *ReminderService) UpdateSettings(userID uint, req dto.ReminderSettingsRequest) (*dto.ReminderSettingsResponse, error) {
+	setting, err := s.setting(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Enabled != nil {
+		setting.Enabled = *req.Enabled
+	}
+	if req.DaysBefore != nil {
+		setting.DaysBefore = normalizeDays(req.DaysBefore)
+	}
+	if err := s.repo.SaveSetting(setting); err != nil {
+		return nil, err
+	}
+	return s.response(setting), nil
+}
+
+// Unsubscribe turns off the reminders of the user a link from a reminder
+// was made for
+func (s *ReminderService) Unsubscribe(userID uint, token string) error {
+	want, ok := s.unsubscribeToken(userID)
+	if !ok || !hmac.Equal([]byte(token), []byte(want)) {
+		return ErrInvalidUnsubscribeLink
+	}
+	setting, err := s.setting(userID)
+	if err != nil {
+		return err
+	}
+	setting.Enabled = false
+	return s.repo.SaveSetting(setting)
+}
+
+// SendDue sends the reminders due at now and returns how many were sent. A
+// loan gets the reminder for the fewest days before its due date that are
+// not less than the days it has left, so a run that was missed is made up
+// for by the next one, and each reminder is sent once. Reminders whose day
+// came before the loan was borrowed are not sent.
+func (s *ReminderService) SendDue(now time.Time) (int, error) {
+	loans, err := s.repo.FindDue(now, now.AddDate(0, 0, config.MaxReminderDays))
+	if err != nil {
+		return 0, err
+	}
+
+	settings := make(map[uint]*model.ReminderSetting)
+	sent := 0
+	for _, loan := range loans {
+		setting, ok := settings[loan.UserID]
+		if !ok {
+			if setting, err = s.setting(loan.UserID); err != nil {
+				return sent, err
+			}
+			settings[loan.UserID] = setting
+		}
+		if !setting.Enabled {
+			continue
+		}
+		daysLeft := int((loan.DueAt.Sub(now) + 24*time.Hour - 1) / (24 * time.Hour))
+		days, ok := reminderDay(s.days(setting), daysLeft)
+		if !ok || loan.DueAt.AddDate(0, 0, -days).Before(loan.BorrowedAt) {
+			continue
+		}
+		marked, err := s.repo.MarkSent(loan.LoanID, days, now)
+		if err != nil {
+			return sent, err
+		}
+		if !marked {
+			continue
+		}
+		s.notifier.Notify(loan.UserID, model.EventLoanDueSoon, LoanDueNotice{
+			Title:          loan.Title,
+			DueOn:          loan.DueAt.Format("Monday, 2 January"),
+			DaysLeft:       daysLeft,
+			UnsubscribeURL: s.unsubscribeURL(loan.UserID),
+		}, fmt.Sprintf("/books/%d", loan.BookID))
+		sent++
+	}
+	return sent, nil
+}
+
+// Run sends the reminders that are due every interval until ctx is
+// cancelled, and forgets the ones sent for loans that were returned
+func (s *ReminderService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := s.SendDue(time.Now())
+			if err != nil {
+				log.Printf("Loan reminders failed: %v", err)
+			} else if sent > 0 {
+				log.Printf("Loan reminders: sent %d", sent)
+			}
+			if _, err := s.repo.ForgetSent(); err != nil {
+				log.Printf("Loan reminder cleanup failed: %v", err)
+			}
+		}
+	}
+}
+
+// setting returns the user's stored setting, or reminders on the
+// configured days when they never changed it
+func (s *ReminderService) setting(userID uint) (*model.ReminderSetting, error) {
+	setting, err := s.repo.FindSetting(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.ReminderSetting{UserID: userID, Enabled: true}, nil
+	}
+	return setting, err
+}
+
+// days are the days before the due date setting's user is reminded on
+func (s *ReminderService) days(setting *model.ReminderSetting) []int {
+	if len(setting.DaysBefore) > 0 {
+		return setting.DaysBefore
+	}
+	return s.cfg.DaysBefore
+}
+
+func (s *ReminderService) response(setting *model.ReminderSetting) *dto.ReminderSettingsResponse {
+	return &dto.ReminderSettingsResponse{
+		Enabled:    setting.Enabled,
+		DaysBefore: normalizeDays(s.days(setting)),
+	}
+}
+
+func (s *ReminderService) unsubscribeURL(userID uint) string {
+	token, ok := s.unsubscribeToken(userID)
+	if !ok {
+		return ""
+	}
+	query := url.Values{"user": {strconv.FormatUint(uint64(userID), 10)}, "token": {token}}
+	return s.baseURL + "/reminders/unsubscribe?" + query.Encode()
+}
+
+// unsubscribeToken signs userID for an unsubscribe link; ok is false when
+// no secret is configured
+func (s *ReminderService) unsubscribeToken(userID uint) (string, bool) {
+	if s.cfg.Secret == "" {
+		return "", false
+	}
+	mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	mac.Write([]byte("reminders/unsubscribe:" + strconv.FormatUint(uint64(userID), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), true
+}
+
+// reminderDay returns the fewest of days that is at least daysLeft
+func reminderDay(days []int, daysLeft int) (int, bool) {
+	best, ok := 0, false
+	for _, d := range days {
+		if d >= daysLeft && (!ok || d < best) {
+			best, ok = d, true
+		}
+	}
+	return best, ok
+}
+
+// normalizeDays sorts days, latest reminder first, and drops duplicates
+func normalizeDays(days []int) []int {
+	sorted := slices.Clone(days)
+	slices.SortFunc(sorted, func(a, b int) int { return b - a })
+	return slices.Compact(sorted)
+}
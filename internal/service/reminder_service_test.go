@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bms-go/config"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeReminders keeps reminder settings and sent reminders in memory.
+// FindDue returns every loan in due order, like the GORM repository's
+// query, as the tests list them.
+type fakeReminders struct {
+	settings map[uint]model.ReminderSetting
+	loans    []dto.DueLoan
+	sent     map[[2]int]bool
+}
+
+func (f *fakeReminders) FindSetting(userID uint) (*model.ReminderSetting, error) {
+	setting, ok := f.settings[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &setting, nil
+}
+
+func (f *fakeReminders) SaveSetting(setting *model.ReminderSetting) error {
+	f.settings[setting.UserID] = *setting
+	return nil
+}
+
+func (f *fakeReminders) FindDue(from, until time.Time) ([]dto.DueLoan, error) {
+	var due []dto.DueLoan
+	for _, loan := range f.loans {
+		if !loan.DueAt.Before(from) && loan.DueAt.Before(until) {
+			due = append(due, loan)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeReminders) MarkSent(loanID uint, daysBefore int, _ time.Time) (bool, error) {
+	key := [2]int{int(loanID), daysBefore}
+	if f.sent[key] {
+		return false, nil
+	}
+	f.sent[key] = true
+	return true, nil
+}
+
+func (f *fakeReminders) ForgetSent() (int64, error) { return 0, nil }
+
+type sentNotice struct {
+	userID uint
+	notice LoanDueNotice
+	path   string
+}
+
+type recordingNotifier struct{ sent []sentNotice }
+
+func (n *recordingNotifier) Notify(userID uint, event model.NotificationEvent, data interface{}, path string) {
+	if event != model.EventLoanDueSoon {
+		panic("unexpected event " + event)
+	}
+	n.sent = append(n.sent, sentNotice{userID: userID, notice: data.(LoanDueNotice), path: path})
+}
+
+var reminderNow = time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+
+// dueLoan is a loan of book id to user, lent for the usual 14 days, that
+// is due after left
+func dueLoan(id, user uint, left time.Duration) dto.DueLoan {
+	due := reminderNow.Add(left)
+	return dto.DueLoan{LoanID: id, UserID: user, BookID: id, Title: "Book " + strconv.Itoa(int(id)), BorrowedAt: due.AddDate(0, 0, -14), DueAt: due}
+}
+
+func newTestReminders(settings map[uint]model.ReminderSetting, loans ...dto.DueLoan) (*ReminderService, *fakeReminders, *recordingNotifier) {
+	if settings == nil {
+		settings = make(map[uint]model.ReminderSetting)
+	}
+	repo := &fakeReminders{settings: settings, loans: loans, sent: make(map[[2]int]bool)}
+	notifier := &recordingNotifier{}
+	cfg := config.ReminderConfig{DaysBefore: []int{3, 1}, Secret: "unsubscribe-secret"}
+	return NewReminderService(repo, notifier, cfg, "https://library.example"), repo, notifier
+}
+
+func TestReminderServiceSendDue(t *testing.T) {
+	day := 24 * time.Hour
+	borrowedToday := dueLoan(7, 1, 2*day)
+	borrowedToday.BorrowedAt = reminderNow
+
+	tests := []struct {
+		name     string
+		settings map[uint]model.ReminderSetting
+		loan     dto.DueLoan
+		wantDays int // 0 when no reminder is due
+	}{
+		{"three days left", nil, dueLoan(1, 1, 3*day), 3},
+		{"a missed run is made up for", nil, dueLoan(1, 1, 2*day), 3},
+		{"part of a day counts as a day", nil, dueLoan(1, 1, 2*day+time.Hour), 3},
+		{"due tomorrow", nil, dueLoan(1, 1, day), 1},
+		{"due in hours", nil, dueLoan(1, 1, 5*time.Hour), 1},
+		{"not yet", nil, dueLoan(1, 1, 3*day+time.Hour), 0},
+		{"overdue loans are not reminded of", nil, dueLoan(1, 1, -time.Hour), 0},
+		{"user's own days", map[uint]model.ReminderSetting{1: {UserID: 1, Enabled: true, DaysBefore: []int{7}}}, dueLoan(1, 1, 6*day), 7},
+		{"user's own days replace the defaults", map[uint]model.ReminderSetting{1: {UserID: 1, Enabled: true, DaysBefore: []int{7}}}, dueLoan(1, 1, day), 7},
+		{"empty days fall back to the defaults", map[uint]model.ReminderSetting{1: {UserID: 1, Enabled: true, DaysBefore: []int{}}}, dueLoan(1, 1, day), 1},
+		{"turned off", map[uint]model.ReminderSetting{1: {UserID: 1, Enabled: false}}, dueLoan(1, 1, day), 0},
+		{"reminder day before the loan was borrowed", nil, borrowedToday, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, notifier := newTestReminders(tt.settings, tt.loan)
+			sent, err := s.SendDue(reminderNow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantDays == 0 {
+				if sent != 0 || len(notifier.sent) != 0 {
+					t.Fatalf("sent %d reminders, want none", sent)
+				}
+				return
+			}
+			if sent != 1 || len(notifier.sent) != 1 {
+				t.Fatalf("sent %d reminders, want 1", sent)
+			}
+			if !repo.sent[[2]int{int(tt.loan.LoanID), tt.wantDays}] {
+				t.Errorf("reminders marked sent = %v, want the %d-day one", repo.sent, tt.wantDays)
+			}
+			got := notifier.sent[0]
+			if got.userID != tt.loan.UserID || got.notice.Title != tt.loan.Title || got.path != "/books/1" {
+				t.Errorf("sent %+v", got)
+			}
+
+			// The next run sends nothing new
+			if sent, err := s.SendDue(reminderNow.Add(time.Hour)); err != nil || sent != 0 {
+				t.Errorf("second run sent %d, %v; want 0", sent, err)
+			}
+		})
+	}
+}
+
+func TestReminderServiceSendsEachReminderOnce(t *testing.T) {
+	s, _, notifier := newTestReminders(nil, dueLoan(1, 1, 5*24*time.Hour))
+
+	var days []int
+	for run := 0; run < 7; run++ {
+		if _, err := s.SendDue(reminderNow.AddDate(0, 0, run)); err != nil {
+			t.Fatal(err)
+		}
+		days = append(days, len(notifier.sent))
+	}
+	// Reminded 3 days before, on day 2, and 1 day before, on day 4
+	want := []int{0, 0, 1, 1, 2, 2, 2}
+	for i := range want {
+		if days[i] != want[i] {
+			t.Fatalf("reminders sent by day = %v, want %v", days, want)
+		}
+	}
+}
+
+func TestReminderServiceUnsubscribe(t *testing.T) {
+	s, repo, notifier := newTestReminders(nil, dueLoan(1, 1, 24*time.Hour), dueLoan(2, 2, 24*time.Hour))
+	if _, err := s.SendDue(reminderNow); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 2 {
+		t.Fatalf("sent %d reminders, want 2", len(notifier.sent))
+	}
+	link, err := url.Parse(notifier.sent[0].notice.UnsubscribeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Host != "library.example" || link.Path != "/reminders/unsubscribe" || link.Query().Get("user") != "1" {
+		t.Fatalf("unsubscribe link = %s", link)
+	}
+	token := link.Query().Get("token")
+	otherToken, _ := url.Parse(notifier.sent[1].notice.UnsubscribeURL)
+
+	for _, tt := range []struct {
+		name   string
+		userID uint
+		token  string
+	}{
+		{"no token", 1, ""},
+		{"tampered token", 1, token[:len(token)-2] + "AA"},
+		{"another user's token", 1, otherToken.Query().Get("token")},
+		{"token for another user", 2, token},
+	} {
+		if err := s.Unsubscribe(tt.userID, tt.token); !errors.Is(err, ErrInvalidUnsubscribeLink) {
+			t.Errorf("%s: Unsubscribe = %v, want ErrInvalidUnsubscribeLink", tt.name, err)
+		}
+	}
+	if len(repo.settings) != 0 {
+		t.Fatalf("rejected links changed settings: %v", repo.settings)
+	}
+
+	if err := s.Unsubscribe(1, token); err != nil {
+		t.Fatal(err)
+	}
+	if setting := repo.settings[1]; setting.Enabled {
+		t.Fatalf("setting after unsubscribing = %+v, want disabled", setting)
+	}
+	settings, err := s.GetSettings(1)
+	if err != nil || settings.Enabled {
+		t.Fatalf("GetSettings = %+v, %v; want disabled", settings, err)
+	}
+
+	// Reminders for the user stop; the other user still gets theirs
+	repo.loans = []dto.DueLoan{dueLoan(3, 1, 24*time.Hour), dueLoan(4, 2, 24*time.Hour)}
+	notifier.sent = nil
+	if _, err := s.SendDue(reminderNow); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].userID != 2 {
+		t.Fatalf("reminders after unsubscribing = %+v, want only user 2's", notifier.sent)
+	}
+}
+
+func TestReminderServiceWithoutSecret(t *testing.T) {
+	s, _, notifier := newTestReminders(nil, dueLoan(1, 1, 24*time.Hour))
+	s.cfg.Secret = ""
+	if _, err := s.SendDue(reminderNow); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].notice.UnsubscribeURL != "" {
+		t.Fatalf("sent %+v, want one reminder without a link", notifier.sent)
+	}
+	if err := s.Unsubscribe(1, ""); !errors.Is(err, ErrInvalidUnsubscribeLink) {
+		t.Fatalf("Unsubscribe = %v, want ErrInvalidUnsubscribeLink", err)
+	}
+}
+
+func TestReminderServiceUpdateSettings(t *testing.T) {
+	s, _, _ := newTestReminders(nil)
+	settings, err := s.GetSettings(1)
+	if err != nil || !settings.Enabled || len(settings.DaysBefore) != 2 || settings.DaysBefore[0] != 3 {
+		t.Fatalf("default settings = %+v, %v", settings, err)
+	}
+
+	settings, err = s.UpdateSettings(1, dto.ReminderSettingsRequest{DaysBefore: []int{1, 7, 1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !settings.Enabled || len(settings.DaysBefore) != 3 || settings.DaysBefore[0] != 7 || settings.DaysBefore[2] != 1 {
+		t.Fatalf("settings = %+v, want enabled with days 7, 2, 1", settings)
+	}
+
+	settings, err = s.UpdateSettings(1, dto.ReminderSettingsRequest{DaysBefore: []int{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings.DaysBefore) != 2 || settings.DaysBefore[0] != 3 {
+		t.Fatalf("settings = %+v, want the default days back", settings)
+	}
+}
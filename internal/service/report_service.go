@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"log"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidReportKind      = apperror.New("INVALID_REPORT_KIND", "kind must be one of new_books, category_summary")
+	ErrInvalidReportFrequency = apperror.New("INVALID_REPORT_FREQUENCY", "frequency must be one of daily, weekly, monthly")
+)
+
+// ReportService manages report schedules and generates the reports that are
+// due. Generated reports are stored as CSV and listed per schedule.
+type ReportService struct {
+	repo     *repository.ReportRepository
+	bookRepo BookReader
+}
+
+func NewReportService(repo *repository.ReportRepository, bookRepo BookReader) *ReportService {
+	return &ReportService{repo: repo, bookRepo: bookRepo}
+}
+
+func (s *ReportService) GetSchedules() ([]model.ReportSchedule, error) {
+	return s.repo.FindSchedules()
+}
+
+// CreateSchedule adds a schedule whose first report covers the period
+// starting now
+func (s *ReportService) CreateSchedule(req dto.ReportScheduleRequest) (*model.ReportSchedule, error) {
+	if err := validateReportSchedule(req); err != nil {
+		return nil, err
+	}
+	schedule := model.ReportSchedule{
+		Name:      req.Name,
+		Kind:      req.Kind,
+		Frequency: req.Frequency,
+		Active:    req.Active == nil || *req.Active,
+		NextRunAt: req.Frequency.Next(time.Now()),
+	}
+	if err := s.repo.SaveSchedule(&schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (s *ReportService) UpdateSchedule(id uint, req dto.ReportScheduleRequest) (*model.ReportSchedule, error) {
+	if err := validateReportSchedule(req); err != nil {
+		return nil, err
+	}
+	schedule, err := s.repo.FindSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Name = req.Name
+	schedule.Kind = req.Kind
+	if schedule.Frequency != req.Frequency {
+		schedule.Frequency = req.Frequency
+		last := schedule.CreatedAt
+		if schedule.LastRunAt != nil {
+			last = *schedule.LastRunAt
+		}
+		schedule.NextRunAt = req.Frequency.Next(last)
+	}
+	if req.Active != nil {
+		schedule.Active = *req.Active
+	}
+	if err := s.repo.SaveSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *ReportService) DeleteSchedule(id uint) error {
+	return s.repo.DeleteSchedule(id)
+}
+
+func (s *ReportService) GetRuns(scheduleID uint) ([]model.ReportRun, error) {
+	if _, err := s.repo.FindSchedule(scheduleID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindRuns(scheduleID)
+}
+
+func (s *ReportService) GetRun(id uint) (*model.ReportRun, error) {
+	return s.repo.FindRun(id)
+}
+
+// RunNow generates the schedule's report for the period since its last run
+// without waiting for the next scheduled time
+func (s *ReportService) RunNow(ctx context.Context, id uint) (*model.ReportRun, error) {
+	schedule, err := s.repo.FindSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.generate(ctx, schedule, time.Now())
+}
+
+// RunDue generates every report that is due and returns how many were made
+func (s *ReportService) RunDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := s.repo.FindDueSchedules(now)
+	if err != nil {
+		return 0, err
+	}
+	for i := range due {
+		if _, err := s.generate(ctx, &due[i], now); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
+// Run generates due reports every interval until ctx is cancelled
+func (s *ReportService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			generated, err := s.RunDue(ctx)
+			if err != nil {
+				log.Printf("Scheduled report failed: %v", err)
+			}
+			if generated > 0 {
+				log.Printf("Generated %d scheduled reports", generated)
+			}
+		}
+	}
+}
+
+func (s *ReportService) generate(ctx context.Context, schedule *model.ReportSchedule, now time.Time) (*model.ReportRun, error) {
+	start := schedule.CreatedAt
+	if schedule.LastRunAt != nil {
+		start = *schedule.LastRunAt
+	}
+
+	header, rows, err := s.reportRows(ctx, schedule.Kind, start, now)
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderCSV(header, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &model.ReportRun{
+		ScheduleID:  schedule.ID,
+		Kind:        schedule.Kind,
+		PeriodStart: start,
+		PeriodEnd:   now,
+		ContentType: "text/csv; charset=utf-8",
+		Rows:        len(rows),
+		Content:     content,
+	}
+	schedule.LastRunAt = &now
+	schedule.NextRunAt = schedule.Frequency.Next(now)
+	if err := s.repo.SaveRun(run, schedule); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *ReportService) reportRows(ctx context.Context, kind model.ReportKind, from, to time.Time) ([]string, [][]string, error) {
+	switch kind {
+	case model.ReportNewBooks:
+		books, err := s.repo.FindBooksCreatedBetween(from, to)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows := make([][]string, len(books))
+		for i, b := range books {
+			rows[i] = []string{
+				strconv.FormatUint(uint64(b.ID), 10), b.Title, b.Author, b.Category,
+				b.CreatedAt.Format(time.RFC3339),
+			}
+		}
+		return []string{"id", "title", "author", "category", "added_at"}, rows, nil
+
+	case model.ReportCategorySummary:
+		counts, err := s.bookRepo.CountByCategory(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows := make([][]string, len(counts))
+		for i, c := range counts {
+			rows[i] = []string{c.Value, strconv.FormatInt(c.Count, 10)}
+		}
+		return []string{"category", "books"}, rows, nil
+	}
+	return nil, nil, ErrInvalidReportKind
+}
+
+func validateReportSchedule(req dto.ReportScheduleRequest) error {
+	if !req.Kind.Valid() {
+		return ErrInvalidReportKind
+	}
+	if !req.Frequency.Valid() {
+		return ErrInvalidReportFrequency
+	}
+	return nil
+}
+
+func renderCSV(header []string, rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
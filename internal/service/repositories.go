@@ -0,0 +1,150 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"time"
+)
+
+// BookReader reads the catalog's books. repository.BookRepository
+// implements it, and the other book interfaces, with GORM; services take
+// the narrowest interface they need so they can be tested against a fake or
+// run on another store.
+type BookReader interface {
+	// FindAll lists books matching params. Relevance-ordered searches also
+	// return each book's score breakdown when params.Explain is set.
+	FindAll(ctx context.Context, params dto.BookQuery) ([]model.Book, []dto.BookScore, error)
+	// FindInBatches passes the books matching params' filters to fn in id
+	// order, batchSize books at a time, until ctx is done
+	FindInBatches(ctx context.Context, params dto.BookQuery, batchSize int, fn func([]model.Book) error) error
+	FindByID(ctx context.Context, id uint) (*model.Book, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]model.Book, error)
+	FindByTitles(ctx context.Context, titles []string) ([]model.Book, error)
+	FindByCategory(ctx context.Context, id uint, rating model.ContentRating, limit, offset int) ([]model.Book, int64, error)
+	FindByAuthor(ctx context.Context, id uint, rating model.ContentRating, limit, offset int) ([]model.Book, int64, error)
+	FindPageByID(ctx context.Context, offset, limit int) ([]model.Book, error)
+	FindRandom(ctx context.Context, category string, rating model.ContentRating) (*model.Book, error)
+	FindMissingDescriptions(ctx context.Context, limit int) ([]model.Book, error)
+	FindSimilarCandidates(ctx context.Context, book model.Book, rating model.ContentRating, limit int) ([]model.Book, error)
+	FindTitlesAndAuthors(ctx context.Context) ([]string, error)
+	Count(ctx context.Context) (int64, error)
+	CountByCategory(ctx context.Context) ([]dto.FacetCount, error)
+	CountByAuthor(ctx context.Context) ([]dto.FacetCount, error)
+}
+
+// BookWriter adds, changes and removes books
+type BookWriter interface {
+	Create(ctx context.Context, book *model.Book) error
+	// CreateMany creates books in one transaction; errs[i] is why books[i]
+	// failed, or nil
+	CreateMany(ctx context.Context, books []model.Book) (errs []error, err error)
+	Update(ctx context.Context, book *model.Book) error
+	// Delete deletes book id. Deleting a book that does not exist is not an
+	// error; deleting one with copies out on loan fails with
+	// repository.ErrBookHasLoans.
+	Delete(ctx context.Context, id uint) error
+	// DeleteMany deletes books ids in one transaction; errs[i] is why ids[i]
+	// failed, or nil. A book that does not exist fails with
+	// gorm.ErrRecordNotFound, one with copies out on loan with
+	// repository.ErrBookHasLoans.
+	DeleteMany(ctx context.Context, ids []uint) (errs []error, err error)
+}
+
+// BookIndexer keeps the data derived from books in step with them: their
+// favorite counts and the search index
+type BookIndexer interface {
+	ReconcileFavoriteCounts(ctx context.Context) (int64, error)
+	RebuildSearchIndex(ctx context.Context, progress func(done, total int)) error
+}
+
+// BookRepository is the whole book store, for BookService, which owns the
+// catalog
+type BookRepository interface {
+	BookReader
+	BookWriter
+	BookIndexer
+}
+
+// FavoriteRepository stores the books users marked as favorite.
+// repository.FavoriteRepository implements it with GORM.
+type FavoriteRepository interface {
+	FindAll(ctx context.Context, userID uint) ([]model.Favorite, error)
+	Create(ctx context.Context, fav *model.Favorite) error
+	Delete(ctx context.Context, userID, favoriteID uint) error
+}
+
+// LoanRepository stores loans and moves copies in and out of stock as they
+// are lent and returned. repository.LoanRepository implements it with GORM.
+type LoanRepository interface {
+	FindAll(ctx context.Context, query dto.LoanQuery) ([]model.Loan, int64, error)
+	FindByID(ctx context.Context, id uint) (*model.Loan, error)
+	History(ctx context.Context, userID uint, from, to time.Time) ([]dto.LoanHistoryEntry, error)
+	// Borrow stores loan on a free copy of its book; false is returned when
+	// there is none
+	Borrow(ctx context.Context, loan *model.Loan, hold time.Duration) (bool, error)
+	// Return closes loan id and fills in loan; false is returned when it was
+	// already returned
+	Return(ctx context.Context, id uint, now time.Time, hold time.Duration, loan *model.Loan) (bool, error)
+	ForgetReturned(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// AccountRepository stores the accounts users sign in to.
+// repository.UserRepository implements it with GORM.
+type AccountRepository interface {
+	FindByID(id uint) (*model.User, error)
+	// FindByEmail finds the account with email, returning
+	// gorm.ErrRecordNotFound when there is none
+	FindByEmail(email string) (*model.User, error)
+	Create(user *model.User) error
+	Save(user *model.User) error
+}
+
+// ValidationRuleRepository stores the rules books are checked against.
+// repository.ValidationRuleRepository implements it with GORM.
+type ValidationRuleRepository interface {
+	FindAll() ([]model.ValidationRule, error)
+	FindByID(id uint) (*model.ValidationRule, error)
+	Create(rule *model.ValidationRule) error
+	Update(rule *model.ValidationRule) error
+	Delete(id uint) error
+}
+
+// ImpersonationRepository stores impersonation sessions and the requests
+// made under them. repository.ImpersonationRepository implements it with
+// GORM.
+type ImpersonationRepository interface {
+	Create(session *model.ImpersonationSession) error
+	FindByID(id uint) (*model.ImpersonationSession, error)
+	FindByTokenHash(hash string) (*model.ImpersonationSession, error)
+	FindAll() ([]model.ImpersonationSession, error)
+	Revoke(id uint) error
+	CreateAction(action *model.ImpersonationAction) error
+	FindActions(sessionID uint) ([]model.ImpersonationAction, error)
+}
+
+// ReminderRepository stores users' due-date reminder settings and which
+// reminders were sent. repository.ReminderRepository implements it with GORM.
+type ReminderRepository interface {
+	// FindSetting returns gorm.ErrRecordNotFound for users who never
+	// changed their settings
+	FindSetting(userID uint) (*model.ReminderSetting, error)
+	SaveSetting(setting *model.ReminderSetting) error
+	// FindDue lists the loans still out that are due from from until
+	// before until, soonest first
+	FindDue(from, until time.Time) ([]dto.DueLoan, error)
+	// MarkSent records a reminder, reporting false when it was already sent
+	MarkSent(loanID uint, daysBefore int, at time.Time) (bool, error)
+	ForgetSent() (int64, error)
+}
+
+var (
+	_ AccountRepository        = (*repository.UserRepository)(nil)
+	_ BookRepository           = (*repository.BookRepository)(nil)
+	_ FavoriteRepository       = (*repository.FavoriteRepository)(nil)
+	_ ImpersonationRepository  = (*repository.ImpersonationRepository)(nil)
+	_ LoanRepository           = (*repository.LoanRepository)(nil)
+	_ ReminderRepository       = (*repository.ReminderRepository)(nil)
+	_ ValidationRuleRepository = (*repository.ValidationRuleRepository)(nil)
+)
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReservationNotFound     = apperror.New("RESERVATION_NOT_FOUND", "reservation not found")
+	ErrReservationBookNotFound = apperror.New(apperror.CodeBookNotFound, "book not found")
+	ErrAlreadyReserved         = apperror.New("ALREADY_RESERVED", "book is already reserved")
+	ErrBookAvailable           = apperror.New("BOOK_AVAILABLE", "a copy of the book is free to borrow")
+	ErrReservationClosed       = apperror.New("RESERVATION_CLOSED", "reservation is no longer active")
+)
+
+// ReservationService queues users for books whose copies are all out on
+// loan. Queues are first come, first served: each copy that comes back is
+// held for the oldest waiting reservation for hold.
+type ReservationService struct {
+	repo  *repository.ReservationRepository
+	users *UserService
+	hold  time.Duration
+}
+
+func NewReservationService(repo *repository.ReservationRepository, users *UserService, hold time.Duration) *ReservationService {
+	return &ReservationService{repo: repo, users: users, hold: hold}
+}
+
+// GetReservations returns a page of the reservations matching query, newest
+// first
+func (s *ReservationService) GetReservations(query dto.ReservationQuery) (*dto.ReservationListResponse, error) {
+	reservations, total, err := s.repo.FindAll(query)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReservationListResponse{
+		Data: reservations,
+		Meta: dto.ReservationListMeta{
+			Count:  len(reservations),
+			Total:  total,
+			Limit:  query.Limit,
+			Offset: query.Offset,
+		},
+	}, nil
+}
+
+// Reserve queues userID for book bookID. Books with a free copy are
+// borrowed rather than reserved.
+func (s *ReservationService) Reserve(userID, bookID uint) (*model.Reservation, error) {
+	_, err := s.repo.FindActive(userID, bookID)
+	if err == nil {
+		return nil, ErrAlreadyReserved
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	reservation := model.Reservation{BookID: bookID, UserID: userID}
+	reserved, err := s.repo.Reserve(&reservation, s.hold)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReservationBookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, ErrBookAvailable
+	}
+	return &reservation, nil
+}
+
+// Cancel gives up reservation id on behalf of userID. Users cancel their own
+// reservations; librarians and admins cancel anyone's.
+func (s *ReservationService) Cancel(id, userID uint) (*model.Reservation, error) {
+	reservation, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reservation.UserID != userID {
+		role, err := s.users.GetRole(userID)
+		if err != nil {
+			return nil, err
+		}
+		// Other users' reservations are not revealed to readers
+		if role != model.RoleLibrarian && role != model.RoleAdmin {
+			return nil, ErrReservationNotFound
+		}
+	}
+
+	cancelled, err := s.repo.Cancel(id, s.hold, reservation)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, ErrReservationClosed
+	}
+	reservation.Position = 0
+	return reservation, nil
+}
+
+// Settle releases holds that have run out and offers copies added to stock
+// to the books' queues
+func (s *ReservationService) Settle() (int, error) {
+	return s.repo.Settle(time.Now(), s.hold)
+}
+
+// Run settles the reservation queues every interval until ctx is cancelled
+func (s *ReservationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Settle(); err != nil {
+				log.Printf("Reservation queue update failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model/dto"
+	"context"
+	"expvar"
+	"log"
+	"time"
+)
+
+// Purge counters exposed through expvar at /debug/vars
+var (
+	purgedBooks     = expvar.NewInt("retention_purged_books")
+	purgedFavorites = expvar.NewInt("retention_purged_favorites")
+	purgedBookViews = expvar.NewInt("retention_purged_book_views")
+	purgeRuns       = expvar.NewInt("retention_purge_runs")
+)
+
+// RetentionService permanently removes books that have been soft-deleted for
+// longer than the retention period, together with their dependents
+type RetentionService struct {
+	repo   *repository.RetentionRepository
+	period time.Duration
+}
+
+func NewRetentionService(repo *repository.RetentionRepository, period time.Duration) *RetentionService {
+	return &RetentionService{repo: repo, period: period}
+}
+
+// Preview reports what Purge would remove right now
+func (s *RetentionService) Preview() (*dto.RetentionPreview, error) {
+	cutoff := time.Now().Add(-s.period)
+	books, err := s.repo.FindBooksDeletedBefore(cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(books))
+	preview := &dto.RetentionPreview{Cutoff: cutoff, Books: make([]dto.PurgeCandidate, len(books))}
+	for i, b := range books {
+		ids[i] = b.ID
+		preview.Books[i] = dto.PurgeCandidate{ID: b.ID, Title: b.Title, DeletedAt: b.DeletedAt.Time}
+	}
+
+	counts, err := s.repo.CountDependents(ids)
+	if err != nil {
+		return nil, err
+	}
+	preview.Favorites = counts.Favorites
+	preview.BookViews = counts.BookViews
+	return preview, nil
+}
+
+// Purge hard-deletes books past the retention period and their dependents
+func (s *RetentionService) Purge() (*dto.PurgeResult, error) {
+	cutoff := time.Now().Add(-s.period)
+	books, err := s.repo.FindBooksDeletedBefore(cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(books))
+	for i, b := range books {
+		ids[i] = b.ID
+	}
+
+	counts, err := s.repo.PurgeBooks(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	purgeRuns.Add(1)
+	purgedBooks.Add(counts.Books)
+	purgedFavorites.Add(counts.Favorites)
+	purgedBookViews.Add(counts.BookViews)
+
+	return &dto.PurgeResult{
+		Cutoff:    cutoff,
+		Books:     counts.Books,
+		Favorites: counts.Favorites,
+		BookViews: counts.BookViews,
+		RanAt:     time.Now(),
+	}, nil
+}
+
+// Run purges every interval until ctx is cancelled
+func (s *RetentionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Purge()
+			if err != nil {
+				log.Printf("Retention purge failed: %v", err)
+				continue
+			}
+			if result.Books > 0 {
+				log.Printf("Retention purge removed %d books, %d favorites, %d book views", result.Books, result.Favorites, result.BookViews)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+)
+
+// ErrAlreadyReviewed is returned when a user reviews a book a second time
+var ErrAlreadyReviewed = apperror.New("ALREADY_REVIEWED", "book already reviewed")
+
+type ReviewService struct {
+	repo      *repository.ReviewRepository
+	bookRepo  BookReader
+	responses *cache.ResponseCache
+}
+
+func NewReviewService(repo *repository.ReviewRepository, bookRepo BookReader, responses *cache.ResponseCache) *ReviewService {
+	return &ReviewService{repo: repo, bookRepo: bookRepo, responses: responses}
+}
+
+// GetReviews returns a page of bookID's reviews, newest first
+func (s *ReviewService) GetReviews(ctx context.Context, bookID uint, limit, offset int) (*dto.ReviewListResponse, error) {
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	reviews, total, err := s.repo.FindByBook(bookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReviewListResponse{
+		Data: reviews,
+		Meta: dto.ReviewListMeta{
+			Count:  len(reviews),
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}, nil
+}
+
+// AddReview stores userID's review of bookID and updates the book's rating
+func (s *ReviewService) AddReview(ctx context.Context, bookID, userID uint, req dto.ReviewRequest) (*model.Review, error) {
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	exists, err := s.repo.Exists(bookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAlreadyReviewed
+	}
+
+	review := model.Review{
+		BookID: bookID,
+		UserID: userID,
+		Rating: req.Rating,
+		Body:   req.Body,
+	}
+	if err := s.repo.Create(&review); err != nil {
+		return nil, err
+	}
+	// Book responses carry the rating
+	s.responses.Invalidate(cache.TagBooks)
+	return &review, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bytes"
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidSample       = apperror.New("INVALID_SAMPLE", "sample must be a PDF or UTF-8 text")
+	ErrEmptySample         = apperror.New("EMPTY_SAMPLE", "sample is empty")
+	ErrInvalidSampleAccess = apperror.New("INVALID_SAMPLE_ACCESS", "access must be one of public, hidden")
+)
+
+// SampleTooLargeError reports a sample over the configured size limit
+type SampleTooLargeError struct {
+	MaxSize int64
+}
+
+func (e *SampleTooLargeError) Error() string {
+	return fmt.Sprintf("sample must not be larger than %d bytes", e.MaxSize)
+}
+
+// SampleService stores first-chapter excerpts of books. Samples are
+// readable by anyone once public, whatever access the full book has.
+type SampleService struct {
+	repo      *repository.BookSampleRepository
+	bookRepo  BookReader
+	responses *cache.ResponseCache
+	maxSize   int64
+}
+
+func NewSampleService(repo *repository.BookSampleRepository, bookRepo BookReader, responses *cache.ResponseCache, maxSize int64) *SampleService {
+	return &SampleService{repo: repo, bookRepo: bookRepo, responses: responses, maxSize: maxSize}
+}
+
+// MaxSize is the largest sample accepted, in bytes
+func (s *SampleService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// GetPublicSample returns the book's sample unless it is hidden or the book
+// has been deleted, in which case it is reported as not found
+func (s *SampleService) GetPublicSample(ctx context.Context, bookID uint) (*model.BookSample, error) {
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	sample, err := s.repo.Find(bookID)
+	if err != nil {
+		return nil, err
+	}
+	if sample.Access != model.SamplePublic {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return sample, nil
+}
+
+// GetSample returns the book's sample whatever its access
+func (s *SampleService) GetSample(bookID uint) (*model.BookSample, error) {
+	return s.repo.Find(bookID)
+}
+
+// SetSample stores content as the book's sample, replacing any earlier one.
+// The format is detected from the content; access defaults to public.
+func (s *SampleService) SetSample(ctx context.Context, bookID uint, filename string, content []byte, access model.SampleAccess) (*model.BookSample, error) {
+	if access == "" {
+		access = model.SamplePublic
+	}
+	if !access.Valid() {
+		return nil, ErrInvalidSampleAccess
+	}
+	if len(content) == 0 {
+		return nil, ErrEmptySample
+	}
+	if int64(len(content)) > s.maxSize {
+		return nil, &SampleTooLargeError{MaxSize: s.maxSize}
+	}
+
+	var format model.SampleFormat
+	switch {
+	case bytes.HasPrefix(content, []byte("%PDF-")):
+		format = model.SamplePDF
+	case utf8.Valid(content):
+		format = model.SampleText
+	default:
+		return nil, ErrInvalidSample
+	}
+
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	sample := model.BookSample{
+		BookID:   bookID,
+		Format:   format,
+		Access:   access,
+		Filename: filename,
+		Size:     len(content),
+		Content:  content,
+	}
+	if err := s.repo.Save(&sample); err != nil {
+		return nil, err
+	}
+	// Book listings show whether a sample is available
+	s.responses.Invalidate(cache.TagBooks)
+	return &sample, nil
+}
+
+func (s *SampleService) DeleteSample(bookID uint) error {
+	if err := s.repo.Delete(bookID); err != nil {
+		return err
+	}
+	s.responses.Invalidate(cache.TagBooks)
+	return nil
+}
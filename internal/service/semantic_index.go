@@ -0,0 +1,213 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/embed"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSemanticSearchDisabled is returned for semantic searches when no
+// embedding provider is configured
+var ErrSemanticSearchDisabled = apperror.New("SEMANTIC_SEARCH_DISABLED", "semantic search is not configured")
+
+// SemanticIndex keeps the embeddings of every book in memory and finds the
+// books closest in meaning to a search. Embeddings are stored in the
+// database so they survive restarts; Refresh computes the missing and
+// outdated ones. Similarity is a brute-force scan, which is fast enough for
+// a library catalog.
+type SemanticIndex struct {
+	provider   embed.Provider
+	repo       *repository.BookEmbeddingRepository
+	batchSize  int
+	candidates int
+
+	mu      sync.RWMutex
+	vectors map[uint][]float32
+}
+
+// NewSemanticIndex returns an index over provider's embeddings. Searches
+// consider the candidates nearest books; Refresh embeds batchSize books per
+// provider call.
+func NewSemanticIndex(provider embed.Provider, repo *repository.BookEmbeddingRepository, batchSize, candidates int) *SemanticIndex {
+	return &SemanticIndex{provider: provider, repo: repo, batchSize: batchSize, candidates: candidates}
+}
+
+// Candidates is how many nearest books a search considers
+func (s *SemanticIndex) Candidates() int {
+	return s.candidates
+}
+
+// embeddingText is what a book's embedding is computed from
+func embeddingText(book model.Book) string {
+	if book.Description == "" {
+		return book.Title
+	}
+	return book.Title + "\n\n" + book.Description
+}
+
+// load reads the stored embeddings the first time the index is used
+func (s *SemanticIndex) load() (map[uint][]float32, error) {
+	s.mu.RLock()
+	vectors := s.vectors
+	s.mu.RUnlock()
+	if vectors != nil {
+		return vectors, nil
+	}
+
+	stored, err := s.repo.FindAll(s.provider.Model())
+	if err != nil {
+		return nil, err
+	}
+	vectors = make(map[uint][]float32, len(stored))
+	for _, e := range stored {
+		vectors[e.BookID] = e.Vector
+	}
+
+	s.mu.Lock()
+	if s.vectors == nil {
+		s.vectors = vectors
+	}
+	vectors = s.vectors
+	s.mu.Unlock()
+	return vectors, nil
+}
+
+// Refresh embeds every book whose embedding is missing or out of date and
+// returns how many were embedded
+func (s *SemanticIndex) Refresh(ctx context.Context) (int, error) {
+	if _, err := s.load(); err != nil {
+		return 0, err
+	}
+
+	embedded := 0
+	var afterID uint
+	for {
+		books, err := s.repo.FindStale(s.provider.Model(), afterID, s.batchSize)
+		if err != nil || len(books) == 0 {
+			return embedded, err
+		}
+
+		texts := make([]string, len(books))
+		for i, b := range books {
+			texts[i] = embeddingText(b)
+		}
+		vectors, err := s.provider.Embed(ctx, texts)
+		if err != nil {
+			return embedded, err
+		}
+
+		embeddings := make([]model.BookEmbedding, len(books))
+		for i, b := range books {
+			embed.Normalize(vectors[i])
+			embeddings[i] = model.BookEmbedding{BookID: b.ID, Model: s.provider.Model(), Vector: vectors[i]}
+		}
+		if err := s.repo.Save(embeddings); err != nil {
+			return embedded, err
+		}
+
+		// Copy on write so searches running now keep a consistent map
+		s.mu.Lock()
+		next := make(map[uint][]float32, len(s.vectors)+len(embeddings))
+		for id, v := range s.vectors {
+			next[id] = v
+		}
+		for _, e := range embeddings {
+			next[e.BookID] = e.Vector
+		}
+		s.vectors = next
+		s.mu.Unlock()
+
+		embedded += len(books)
+		afterID = books[len(books)-1].ID
+	}
+}
+
+// Run refreshes the index every interval until ctx is cancelled
+func (s *SemanticIndex) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			embedded, err := s.Refresh(ctx)
+			if err != nil {
+				log.Printf("Embedding refresh failed: %v", err)
+			}
+			if embedded > 0 {
+				log.Printf("Embedded %d books for semantic search", embedded)
+			}
+		}
+	}
+}
+
+// SimilarTo returns the books nearest to bookID's embedding, nearest first,
+// up to the candidate count. It reports false when the book has no
+// embedding yet.
+func (s *SemanticIndex) SimilarTo(bookID uint) ([]dto.SemanticMatch, bool, error) {
+	vectors, err := s.load()
+	if err != nil {
+		return nil, false, err
+	}
+	vector, ok := vectors[bookID]
+	if !ok {
+		return nil, false, nil
+	}
+
+	matches := make([]dto.SemanticMatch, 0, len(vectors))
+	for id, v := range vectors {
+		if id != bookID {
+			matches = append(matches, dto.SemanticMatch{BookID: id, Similarity: max(0, float64(embed.Dot(vector, v)))})
+		}
+	}
+	return nearest(matches, s.candidates), true, nil
+}
+
+// nearest sorts matches by similarity, breaking ties by id, and keeps the
+// first n
+func nearest(matches []dto.SemanticMatch, n int) []dto.SemanticMatch {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].BookID < matches[j].BookID
+	})
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+// Similarities embeds text and returns its cosine similarity, clamped to
+// 0..1, with each of the nearest candidates books, plus a lookup for the
+// similarity of any other book that has an embedding
+func (s *SemanticIndex) Similarities(ctx context.Context, text string) ([]dto.SemanticMatch, func(bookID uint) float64, error) {
+	vectors, err := s.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err := s.provider.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, nil, err
+	}
+	embed.Normalize(query[0])
+
+	similarity := func(bookID uint) float64 {
+		return max(0, float64(embed.Dot(query[0], vectors[bookID])))
+	}
+
+	matches := make([]dto.SemanticMatch, 0, len(vectors))
+	for id := range vectors {
+		matches = append(matches, dto.SemanticMatch{BookID: id, Similarity: similarity(id)})
+	}
+	return nearest(matches, s.candidates), similarity, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"sort"
+)
+
+// ErrBookNotEmbedded is returned for embedding similarity when the book has
+// not been embedded yet
+var ErrBookNotEmbedded = apperror.New("BOOK_NOT_EMBEDDED", "the book has no embedding yet")
+
+// similarStopWords are too common to say anything about a book's content
+var similarStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true,
+	"this": true, "that": true, "are": true, "was": true, "his": true,
+	"her": true, "its": true, "into": true, "their": true, "they": true,
+	"you": true, "your": true, "yang": true, "dan": true, "dengan": true,
+	"untuk": true, "dari": true, "ini": true, "itu": true,
+}
+
+// SimilarBooksService finds books like a given one from their content:
+// shared category and author and overlapping words, or the distance between
+// their embeddings when semantic search is configured. It does not look at
+// who favorited what.
+type SimilarBooksService struct {
+	bookRepo BookReader
+	// semantic is nil when semantic search is not configured
+	semantic *SemanticIndex
+	config   dto.SimilarityConfig
+}
+
+func NewSimilarBooksService(bookRepo BookReader, semantic *SemanticIndex, config dto.SimilarityConfig) *SimilarBooksService {
+	return &SimilarBooksService{bookRepo: bookRepo, semantic: semantic, config: config}
+}
+
+// GetSimilarBooks returns up to limit books most like bookID, most similar
+// first. An empty strategy uses the configured one; rating, when set, limits
+// the results to that content rating.
+func (s *SimilarBooksService) GetSimilarBooks(ctx context.Context, bookID uint, strategy dto.SimilarityStrategy, rating model.ContentRating, limit int) (*dto.SimilarBooksResponse, error) {
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if strategy == "" {
+		strategy = s.config.Strategy
+	}
+
+	if strategy != dto.SimilarityContent {
+		if s.semantic == nil {
+			if strategy == dto.SimilarityEmbedding {
+				return nil, ErrSemanticSearchDisabled
+			}
+		} else {
+			matches, ok, err := s.semantic.SimilarTo(book.ID)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				similar, err := s.byEmbedding(ctx, matches, rating, limit)
+				if err != nil {
+					return nil, err
+				}
+				return &dto.SimilarBooksResponse{BookID: book.ID, Strategy: dto.SimilarityEmbedding, Data: similar}, nil
+			}
+			if strategy == dto.SimilarityEmbedding {
+				return nil, ErrBookNotEmbedded
+			}
+		}
+	}
+
+	similar, err := s.byContent(ctx, *book, rating, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SimilarBooksResponse{BookID: book.ID, Strategy: dto.SimilarityContent, Data: similar}, nil
+}
+
+// byEmbedding loads the matched books in match order, skipping deleted ones
+// and those with another rating
+func (s *SimilarBooksService) byEmbedding(ctx context.Context, matches []dto.SemanticMatch, rating model.ContentRating, limit int) ([]dto.SimilarBook, error) {
+	ids := make([]uint, len(matches))
+	for i, m := range matches {
+		ids[i] = m.BookID
+	}
+	books, err := s.bookRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Book, len(books))
+	for _, b := range books {
+		byID[b.ID] = b
+	}
+
+	similar := []dto.SimilarBook{}
+	for _, m := range matches {
+		b, ok := byID[m.BookID]
+		if !ok || (rating != "" && b.ContentRating != rating) {
+			continue
+		}
+		similar = append(similar, dto.SimilarBook{Book: b, Similarity: m.Similarity})
+		if len(similar) == limit {
+			break
+		}
+	}
+	return similar, nil
+}
+
+// byContent scores the books sharing book's category or author. The score
+// is the weighted share of category, author and word overlap, from 0 to 1.
+func (s *SimilarBooksService) byContent(ctx context.Context, book model.Book, rating model.ContentRating, limit int) ([]dto.SimilarBook, error) {
+	candidates, err := s.bookRepo.FindSimilarCandidates(ctx, book, rating, s.config.Candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.config
+	total := c.CategoryWeight + c.AuthorWeight + c.TextWeight
+	words := contentWords(book)
+
+	similar := make([]dto.SimilarBook, 0, len(candidates))
+	for _, candidate := range candidates {
+		var score float64
+		if candidate.Category == book.Category {
+			score += c.CategoryWeight
+		}
+		if candidate.Author == book.Author {
+			score += c.AuthorWeight
+		}
+		score += c.TextWeight * jaccard(words, contentWords(candidate))
+		if total > 0 {
+			score /= total
+		}
+		similar = append(similar, dto.SimilarBook{Book: candidate, Similarity: score})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Similarity > similar[j].Similarity
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// contentWords is the set of meaningful words in a book's title and
+// description
+func contentWords(book model.Book) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range tokenize(book.Title + " " + book.Description) {
+		if len(w) >= 3 && !similarStopWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// jaccard is the share of words in either set that are in both
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model/dto"
+	"context"
+	"encoding/xml"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrSitemapPageNotFound = apperror.New("SITEMAP_PAGE_NOT_FOUND", "sitemap page not found")
+
+type cachedSitemap struct {
+	body    []byte
+	expires time.Time
+}
+
+// SitemapService renders the sitemap index and its pages of book detail URLs.
+// Documents are built on first request and reused until the TTL runs out.
+type SitemapService struct {
+	bookRepo BookReader
+	links    *LinkService
+	baseURL  string
+	pageSize int
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSitemap
+}
+
+func NewSitemapService(bookRepo BookReader, links *LinkService, baseURL string, pageSize int, ttl time.Duration) *SitemapService {
+	return &SitemapService{
+		bookRepo: bookRepo,
+		links:    links,
+		baseURL:  baseURL,
+		pageSize: pageSize,
+		ttl:      ttl,
+		cache:    make(map[string]cachedSitemap),
+	}
+}
+
+// Index returns the sitemap index XML listing one sitemap per page of books
+func (s *SitemapService) Index(ctx context.Context) ([]byte, error) {
+	return s.cached("index", func() (interface{}, error) {
+		count, err := s.bookRepo.Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		index := dto.NewSitemapIndex()
+		for page := 1; page <= s.pageCount(count); page++ {
+			index.Sitemaps = append(index.Sitemaps, dto.SitemapEntry{Loc: s.pageURL(page)})
+		}
+		return index, nil
+	})
+}
+
+// Page returns the sitemap XML for one page of books, numbered from 1
+func (s *SitemapService) Page(ctx context.Context, page int) ([]byte, error) {
+	if page < 1 {
+		return nil, ErrSitemapPageNotFound
+	}
+
+	return s.cached("page:"+strconv.Itoa(page), func() (interface{}, error) {
+		books, err := s.bookRepo.FindPageByID(ctx, (page-1)*s.pageSize, s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(books) == 0 && page > 1 {
+			return nil, ErrSitemapPageNotFound
+		}
+
+		set := dto.NewURLSet()
+		for _, book := range books {
+			set.URLs = append(set.URLs, dto.SitemapURL{
+				Loc:     s.links.BookURL(book.ID),
+				LastMod: book.UpdatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		return set, nil
+	})
+}
+
+// Warm drops the cached documents and rebuilds the index and every page, so
+// crawlers are served from cache straight away. progress is called after
+// each page.
+func (s *SitemapService) Warm(ctx context.Context, progress func(done, total int)) error {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedSitemap)
+	s.mu.Unlock()
+
+	count, err := s.bookRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Index(ctx); err != nil {
+		return err
+	}
+
+	pages := s.pageCount(count)
+	for page := 1; page <= pages; page++ {
+		if _, err := s.Page(ctx, page); err != nil {
+			return err
+		}
+		progress(page, pages)
+	}
+	return nil
+}
+
+// pageCount is how many sitemap pages count books fill, at least one
+func (s *SitemapService) pageCount(count int64) int {
+	pages := int((count + int64(s.pageSize) - 1) / int64(s.pageSize))
+	if pages == 0 {
+		pages = 1
+	}
+	return pages
+}
+
+func (s *SitemapService) cached(key string, build func() (interface{}, error)) ([]byte, error) {
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.body, nil
+	}
+
+	doc, err := build()
+	if err != nil {
+		return nil, err
+	}
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	body = append([]byte(xml.Header), body...)
+
+	s.mu.Lock()
+	s.cache[key] = cachedSitemap{body: body, expires: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+	return body, nil
+}
+
+func (s *SitemapService) pageURL(page int) string {
+	return s.baseURL + "/sitemaps/books/" + strconv.Itoa(page) + ".xml"
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// minSuggestionWordLength skips correcting words too short for edit distance
+// to say anything useful
+const minSuggestionWordLength = 3
+
+// Vocabulary is the set of lowercase words appearing in book titles and
+// authors, used to suggest corrections for searches with no results
+type Vocabulary struct {
+	load func(ctx context.Context) ([]string, error)
+
+	mu    sync.RWMutex
+	words []string
+}
+
+// NewVocabulary returns a lazily built vocabulary over the texts load returns
+func NewVocabulary(load func(ctx context.Context) ([]string, error)) *Vocabulary {
+	return &Vocabulary{load: load}
+}
+
+// Invalidate drops the cached words so the next Suggest rebuilds them
+func (v *Vocabulary) Invalidate() {
+	v.mu.Lock()
+	v.words = nil
+	v.mu.Unlock()
+}
+
+// Rebuild reloads the words now instead of on the next Suggest
+func (v *Vocabulary) Rebuild(ctx context.Context) error {
+	v.Invalidate()
+	_, err := v.get(ctx)
+	return err
+}
+
+// Suggest corrects each word of search to its closest vocabulary word. It
+// returns "" when every word is already known or nothing is close enough.
+func (v *Vocabulary) Suggest(ctx context.Context, search string) (string, error) {
+	words, err := v.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	terms := tokenize(search)
+	changed := false
+	for i, term := range terms {
+		if len([]rune(term)) < minSuggestionWordLength || containsSorted(words, term) {
+			continue
+		}
+		if best := closestWord(words, term); best != "" {
+			terms[i] = best
+			changed = true
+		}
+	}
+
+	if !changed {
+		return "", nil
+	}
+	return strings.Join(terms, " "), nil
+}
+
+func (v *Vocabulary) get(ctx context.Context) ([]string, error) {
+	v.mu.RLock()
+	words := v.words
+	v.mu.RUnlock()
+	if words != nil {
+		return words, nil
+	}
+
+	texts, err := v.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	words = []string{}
+	for _, text := range texts {
+		for _, w := range tokenize(text) {
+			if !seen[w] {
+				seen[w] = true
+				words = append(words, w)
+			}
+		}
+	}
+	sort.Strings(words)
+
+	v.mu.Lock()
+	v.words = words
+	v.mu.Unlock()
+	return words, nil
+}
+
+// closestWord returns the vocabulary word with the smallest edit distance to
+// term, allowing one edit per four characters and at most two
+func closestWord(words []string, term string) string {
+	maxDistance := len([]rune(term)) / 4
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	if maxDistance > 2 {
+		maxDistance = 2
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, w := range words {
+		if d := editDistance(term, w); d < bestDistance {
+			best, bestDistance = w, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func containsSorted(words []string, w string) bool {
+	i := sort.SearchStrings(words, w)
+	return i < len(words) && words[i] == w
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// FuzzSuggest checks that a suggestion keeps the search's word count and
+// only ever swaps in vocabulary words
+func FuzzSuggest(f *testing.F) {
+	vocabulary := NewVocabulary(func(context.Context) ([]string, error) {
+		return []string{"The Hobbit", "J.R.R. Tolkien", "Dune", "Frank Herbert", "Pride and Prejudice", "Neuromancer"}, nil
+	})
+	known := make(map[string]bool)
+	words, err := vocabulary.get(context.Background())
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, w := range words {
+		known[w] = true
+	}
+
+	for _, search := range []string{"hobit", "tolkein dnue", "", "ab", "100%", "🐉 drgaons", "x\x00y", "\xff\xfe", strings.Repeat("neuromancr ", 100)} {
+		f.Add(search)
+	}
+	f.Fuzz(func(t *testing.T, search string) {
+		suggestion, err := vocabulary.Suggest(context.Background(), search)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if suggestion == "" {
+			return
+		}
+		terms, suggested := tokenize(search), strings.Split(suggestion, " ")
+		if len(suggested) != len(terms) {
+			t.Fatalf("Suggest(%q) = %q: %d words for %d", search, suggestion, len(suggested), len(terms))
+		}
+		for i, w := range suggested {
+			if w != terms[i] && !known[w] {
+				t.Fatalf("Suggest(%q) = %q: %q is not in the vocabulary", search, suggestion, w)
+			}
+		}
+	})
+}
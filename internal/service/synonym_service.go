@@ -0,0 +1,153 @@
+package service
+
+import (
+	"bms-go/internal/infra/cache"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"strings"
+	"sync"
+)
+
+// maxSearchVariants caps how many synonym rewrites a single search expands to
+const maxSearchVariants = 10
+
+type SynonymService struct {
+	repo      *repository.SynonymRepository
+	responses *cache.ResponseCache
+
+	mu         sync.RWMutex
+	dictionary map[string][]string
+	generation uint64
+}
+
+func NewSynonymService(repo *repository.SynonymRepository, responses *cache.ResponseCache) *SynonymService {
+	return &SynonymService{repo: repo, responses: responses}
+}
+
+func (s *SynonymService) GetSynonyms() ([]dto.SynonymResponse, error) {
+	synonyms, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SynonymResponse, 0, len(synonyms))
+	for _, syn := range synonyms {
+		responses = append(responses, toSynonymResponse(syn))
+	}
+	return responses, nil
+}
+
+func (s *SynonymService) CreateSynonym(req dto.SynonymRequest) (*dto.SynonymResponse, error) {
+	synonym := model.Synonym{
+		Term:    normalizeTerm(req.Term),
+		Synonym: normalizeTerm(req.Synonym),
+	}
+	if err := s.repo.Create(&synonym); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	resp := toSynonymResponse(synonym)
+	return &resp, nil
+}
+
+func (s *SynonymService) UpdateSynonym(id uint, req dto.SynonymRequest) (*dto.SynonymResponse, error) {
+	synonym, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	synonym.Term = normalizeTerm(req.Term)
+	synonym.Synonym = normalizeTerm(req.Synonym)
+	if err := s.repo.Update(synonym); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	resp := toSynonymResponse(*synonym)
+	return &resp, nil
+}
+
+func (s *SynonymService) DeleteSynonym(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Expand returns search followed by every rewrite of it where a known term is
+// replaced by one of its synonyms
+func (s *SynonymService) Expand(search string) ([]string, error) {
+	dictionary, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	variants := []string{search}
+	padded := " " + normalizeTerm(search) + " "
+	for term, synonyms := range dictionary {
+		if !strings.Contains(padded, " "+term+" ") {
+			continue
+		}
+		for _, syn := range synonyms {
+			if len(variants) == maxSearchVariants {
+				return variants, nil
+			}
+			variants = append(variants, strings.TrimSpace(strings.Replace(padded, " "+term+" ", " "+syn+" ", 1)))
+		}
+	}
+	return variants, nil
+}
+
+// load returns the cached dictionary, reading it from the repository after
+// the cache has been invalidated
+func (s *SynonymService) load() (map[string][]string, error) {
+	s.mu.RLock()
+	dictionary, generation := s.dictionary, s.generation
+	s.mu.RUnlock()
+	if dictionary != nil {
+		return dictionary, nil
+	}
+
+	synonyms, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	dictionary = make(map[string][]string, len(synonyms)*2)
+	for _, syn := range synonyms {
+		dictionary[syn.Term] = append(dictionary[syn.Term], syn.Synonym)
+		dictionary[syn.Synonym] = append(dictionary[syn.Synonym], syn.Term)
+	}
+
+	// Skip caching if the synonyms changed while this copy was being read
+	s.mu.Lock()
+	if s.generation == generation {
+		s.dictionary = dictionary
+	}
+	s.mu.Unlock()
+	return dictionary, nil
+}
+
+func (s *SynonymService) invalidate() {
+	s.mu.Lock()
+	s.dictionary = nil
+	s.generation++
+	s.mu.Unlock()
+	// Synonyms change which books a search matches
+	s.responses.Invalidate(cache.TagBooks)
+}
+
+func normalizeTerm(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
+
+func toSynonymResponse(syn model.Synonym) dto.SynonymResponse {
+	return dto.SynonymResponse{
+		ID:      syn.ID,
+		Term:    syn.Term,
+		Synonym: syn.Synonym,
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagBookNotFound = apperror.New(apperror.CodeBookNotFound, "book not found")
+	ErrTagNotOnBook    = apperror.New("TAG_NOT_ON_BOOK", "book does not have this tag")
+	ErrBlankTag        = apperror.New("BLANK_TAG", "tag name must not be blank")
+)
+
+// TagService puts tags on books and takes them off. Every change drops the
+// cached book data through BookService.BooksChanged.
+type TagService struct {
+	repo     *repository.TagRepository
+	bookRepo BookReader
+	books    *BookService
+}
+
+func NewTagService(repo *repository.TagRepository, bookRepo BookReader, books *BookService) *TagService {
+	return &TagService{repo: repo, bookRepo: bookRepo, books: books}
+}
+
+// GetTags lists every tag by name with its number of books
+func (s *TagService) GetTags() ([]dto.TagResponse, error) {
+	tags, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []dto.TagResponse{}
+	}
+	return tags, nil
+}
+
+// AddTag puts the tag called req.Name on book id, creating the tag when no
+// tag has that name ignoring case, and returns the book's tags. Adding a
+// tag the book already has changes nothing.
+func (s *TagService) AddTag(ctx context.Context, bookID uint, req dto.TagRequest) ([]model.Tag, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrBlankTag
+	}
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Add(book, name); err != nil {
+		return nil, err
+	}
+	s.books.BooksChanged()
+	return book.Tags, nil
+}
+
+// RemoveTag takes tag id off book bookID
+func (s *TagService) RemoveTag(ctx context.Context, bookID, id uint) error {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Remove(book, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTagNotOnBook
+		}
+		return err
+	}
+	s.books.BooksChanged()
+	return nil
+}
+
+func (s *TagService) findBook(ctx context.Context, id uint) (*model.Book, error) {
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagBookNotFound
+	}
+	return book, err
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model/dto"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxFinishedTasks is how many finished tasks are kept for status lookups
+const maxFinishedTasks = 50
+
+var (
+	ErrTaskNotFound = apperror.New("TASK_NOT_FOUND", "task not found")
+	ErrTaskRunning  = apperror.New("TASK_RUNNING", "a task of this kind is already running")
+)
+
+// TaskProgress lets a running task report which step it is on and how far
+// through that step it is
+type TaskProgress func(step string, done, total int)
+
+// TaskService runs admin tasks in the background and keeps their progress
+// in memory so callers can poll it. Only one task of each kind runs at a
+// time; tasks do not survive a restart.
+type TaskService struct {
+	mu     sync.Mutex
+	nextID uint64
+	tasks  map[uint64]*dto.Task
+}
+
+func NewTaskService() *TaskService {
+	return &TaskService{tasks: make(map[uint64]*dto.Task)}
+}
+
+// Start runs fn in the background as a task of kind and returns its initial
+// state, or ErrTaskRunning with the running task when one of that kind is
+// still going
+func (s *TaskService) Start(kind string, fn func(progress TaskProgress) error) (dto.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if t.Kind == kind && t.Status == dto.TaskRunning {
+			return *t, ErrTaskRunning
+		}
+	}
+
+	s.nextID++
+	task := &dto.Task{ID: s.nextID, Kind: kind, Status: dto.TaskRunning, StartedAt: time.Now()}
+	s.tasks[task.ID] = task
+	s.prune()
+
+	go func() {
+		err := fn(func(step string, done, total int) {
+			s.mu.Lock()
+			task.Step, task.Done, task.Total = step, done, total
+			s.mu.Unlock()
+		})
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		now := time.Now()
+		task.FinishedAt = &now
+		task.Status = dto.TaskSucceeded
+		if err != nil {
+			task.Status = dto.TaskFailed
+			task.Error = err.Error()
+		}
+	}()
+	return *task, nil
+}
+
+func (s *TaskService) Get(id uint64) (dto.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[id]
+	if !ok {
+		return dto.Task{}, ErrTaskNotFound
+	}
+	return *task, nil
+}
+
+// prune drops the oldest finished tasks beyond maxFinishedTasks. The caller
+// holds s.mu.
+func (s *TaskService) prune() {
+	var finished []uint64
+	for id, t := range s.tasks {
+		if t.Status != dto.TaskRunning {
+			finished = append(finished, id)
+		}
+	}
+	if len(finished) <= maxFinishedTasks {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i] < finished[j] })
+	for _, id := range finished[:len(finished)-maxFinishedTasks] {
+		delete(s.tasks, id)
+	}
+}
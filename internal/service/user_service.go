@@ -0,0 +1,321 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/infra/repository"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailTaken        = apperror.New("EMAIL_TAKEN", "email is already used by another account")
+	ErrInvalidUserStatus = apperror.New("INVALID_USER_STATUS", "status must be one of active, disabled")
+	ErrProfilePrivate    = apperror.New("PROFILE_PRIVATE", "profile is private")
+	ErrInvalidCardNumber = apperror.New("INVALID_CARD_NUMBER", "card number must be 8 to 19 digits ending in a valid check digit")
+	ErrCardTaken         = apperror.New("CARD_TAKEN", "card number is already issued to another account")
+	ErrCardNotFound      = apperror.New("CARD_NOT_FOUND", "no account has this card number")
+)
+
+// UserService manages accounts, for administrators and for users keeping
+// their own details up to date
+type UserService struct {
+	repo        *repository.UserRepository
+	accounts    *repository.AccountRepository
+	privacyRepo *repository.PrivacyRepository
+}
+
+func NewUserService(repo *repository.UserRepository, accounts *repository.AccountRepository, privacyRepo *repository.PrivacyRepository) *UserService {
+	return &UserService{repo: repo, accounts: accounts, privacyRepo: privacyRepo}
+}
+
+func (s *UserService) GetUsers(query dto.UserQuery) (*dto.UserListResponse, error) {
+	if query.Status != "" && !query.Status.Valid() {
+		return nil, ErrInvalidUserStatus
+	}
+	users, total, err := s.repo.FindAll(query)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UserListResponse{
+		Data: users,
+		Meta: dto.UserListMeta{Count: len(users), Total: total, Limit: query.Limit, Offset: query.Offset},
+	}, nil
+}
+
+// GetUser returns the account with an overview of what is stored about it
+func (s *UserService) GetUser(id uint) (*dto.UserSummary, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.repo.CountActivity(id)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.accounts.FindPendingDeletion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &dto.UserSummary{User: *user, Activity: *activity}
+	if pending != nil {
+		resp := toAccountDeletionResponse(*pending)
+		summary.PendingDeletion = &resp
+	}
+	return summary, nil
+}
+
+// GetProfile returns the public part of a user's account. Users who did not
+// make their profile public are reported as ErrProfilePrivate, the same as
+// users that do not exist.
+func (s *UserService) GetProfile(id uint) (*dto.UserProfile, error) {
+	setting, err := s.privacyRepo.FindByUserID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !setting.PublicProfile {
+		return nil, ErrProfilePrivate
+	}
+	user, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProfilePrivate
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UserProfile{ID: user.ID, Name: user.Name, CreatedAt: user.CreatedAt}, nil
+}
+
+// GetAccount returns the user's own account
+func (s *UserService) GetAccount(id uint) (*model.User, error) {
+	return s.repo.FindByID(id)
+}
+
+func (s *UserService) CreateUser(req dto.UserRequest) (*model.User, error) {
+	user := model.User{Status: model.UserActive}
+	if err := s.apply(&user, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserService) UpdateUser(id uint, req dto.UserRequest) (*model.User, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(user, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DisableUser stops the account from being used until it is enabled again.
+// Its data is kept.
+func (s *UserService) DisableUser(id uint, reason string) (*model.User, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != model.UserDisabled {
+		now := time.Now()
+		user.Status = model.UserDisabled
+		user.DisabledAt = &now
+	}
+	user.DisabledReason = reason
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) EnableUser(id uint) (*model.User, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.Status = model.UserActive
+	user.DisabledAt = nil
+	user.DisabledReason = ""
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RequirePasswordReset makes the user choose a new password the next time
+// they sign in
+func (s *UserService) RequirePasswordReset(id uint) (*model.User, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordResetRequired = true
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser erases the user's personal data right away, without the grace
+// period of a self-service deletion, and removes the account. The erasure
+// is recorded in the account deletion audit trail.
+func (s *UserService) DeleteUser(id uint) (*dto.AccountDeletionResponse, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	deletion, err := s.accounts.FindPendingDeletion(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if deletion == nil {
+		deletion = &model.AccountDeletion{UserID: id, RequestedAt: now}
+	}
+	deletion.ScheduledFor = now
+	if err := s.accounts.EraseUser(deletion); err != nil {
+		return nil, err
+	}
+	resp := toAccountDeletionResponse(*deletion)
+	return &resp, nil
+}
+
+// IsDisabled reports whether the user's account is disabled. Users without
+// an account row are not.
+func (s *UserService) IsDisabled(id uint) (bool, error) {
+	user, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.Status == model.UserDisabled, nil
+}
+
+// GetRole returns the user's role. Users without an account row are
+// readers.
+func (s *UserService) GetRole(id uint) (model.UserRole, error) {
+	user, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.RoleReader, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if user.Role == "" {
+		return model.RoleReader, nil
+	}
+	return user.Role, nil
+}
+
+func (s *UserService) SetRole(id uint, role model.UserRole) (*model.User, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetCard issues library card number to user id, replacing any card they
+// had. An empty number takes their card away.
+func (s *UserService) SetCard(id uint, number string) (*model.User, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	number = model.NormalizeCardNumber(number)
+	if number == "" {
+		user.CardNumber = nil
+	} else {
+		if !model.ValidCardNumber(number) {
+			return nil, ErrInvalidCardNumber
+		}
+		existing, err := s.repo.FindByCardNumber(number)
+		if err == nil && existing.ID != user.ID {
+			return nil, ErrCardTaken
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		user.CardNumber = &number
+	}
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// FindByCard returns the account library card number is issued to
+func (s *UserService) FindByCard(number string) (*model.User, error) {
+	number = model.NormalizeCardNumber(number)
+	if !model.ValidCardNumber(number) {
+		return nil, ErrInvalidCardNumber
+	}
+	user, err := s.repo.FindByCardNumber(number)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCardNotFound
+	}
+	return user, err
+}
+
+// GetPatronByCard returns the account library card number is issued to,
+// with the same overview as GetUser
+func (s *UserService) GetPatronByCard(number string) (*dto.UserSummary, error) {
+	user, err := s.FindByCard(number)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUser(user.ID)
+}
+
+// PromoteAdmins makes the accounts with the given emails admins. Emails
+// without an account are skipped.
+func (s *UserService) PromoteAdmins(emails []string) error {
+	for _, email := range emails {
+		user, err := s.repo.FindByEmail(strings.ToLower(strings.TrimSpace(email)))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if user.Role == model.RoleAdmin {
+			continue
+		}
+		user.Role = model.RoleAdmin
+		if err := s.repo.Save(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *UserService) apply(user *model.User, req dto.UserRequest) error {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	existing, err := s.repo.FindByEmail(email)
+	if err == nil && existing.ID != user.ID {
+		return ErrEmailTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	user.Email = email
+	user.Name = strings.TrimSpace(req.Name)
+	return nil
+}
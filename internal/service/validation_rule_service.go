@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bms-go/internal/apperror"
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownRuleField = apperror.New("UNKNOWN_RULE_FIELD", "field is not a validated book field")
+	ErrInvalidRule      = apperror.New("INVALID_RULE", "invalid validation rule")
+)
+
+// RuleViolationError reports the validation rules a book does not meet
+type RuleViolationError struct {
+	Violations []dto.RuleViolation
+}
+
+func (e *RuleViolationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Field + " " + v.Message
+	}
+	return "book violates validation rules: " + strings.Join(messages, "; ")
+}
+
+// ruleTextFields and ruleNumberFields are the book fields rules can check
+var (
+	ruleTextFields = map[string]func(model.Book) string{
+		"title":       func(b model.Book) string { return b.Title },
+		"author":      func(b model.Book) string { return b.Author },
+		"category":    func(b model.Book) string { return b.Category },
+		"description": func(b model.Book) string { return b.Description },
+		"cover_url":   func(b model.Book) string { return b.CoverURL },
+		"narrator":    func(b model.Book) string { return b.Narrator },
+	}
+	ruleNumberFields = map[string]func(model.Book) int{
+		"published_year":   func(b model.Book) int { return b.PublishedYear },
+		"pages":            func(b model.Book) int { return b.Pages },
+		"duration_minutes": func(b model.Book) int { return b.DurationMinutes },
+	}
+)
+
+// compiledRule is an enabled rule ready to be evaluated
+type compiledRule struct {
+	model.ValidationRule
+	pattern *regexp.Regexp
+}
+
+// ValidationRuleService keeps the cataloging rules administrators configure
+// and checks books against them. Enabled rules are cached until a rule
+// changes.
+type ValidationRuleService struct {
+	repo ValidationRuleRepository
+
+	mu         sync.RWMutex
+	rules      []compiledRule
+	loaded     bool
+	generation uint64
+}
+
+func NewValidationRuleService(repo ValidationRuleRepository) *ValidationRuleService {
+	return &ValidationRuleService{repo: repo}
+}
+
+func (s *ValidationRuleService) GetRules() ([]model.ValidationRule, error) {
+	return s.repo.FindAll()
+}
+
+func (s *ValidationRuleService) CreateRule(req dto.ValidationRuleRequest) (*model.ValidationRule, error) {
+	var rule model.ValidationRule
+	if err := applyRuleRequest(&rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(&rule); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return &rule, nil
+}
+
+func (s *ValidationRuleService) UpdateRule(id uint, req dto.ValidationRuleRequest) (*model.ValidationRule, error) {
+	rule, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(rule); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return rule, nil
+}
+
+func (s *ValidationRuleService) DeleteRule(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Check evaluates the enabled rules against book. It returns a
+// *RuleViolationError listing every rule the book breaks, or nil when it
+// meets them all.
+func (s *ValidationRuleService) Check(book model.Book) error {
+	rules, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	mediaType := book.MediaType
+	if mediaType == "" {
+		mediaType = model.MediaPrint
+	}
+	var violations []dto.RuleViolation
+	for _, rule := range rules {
+		if rule.MediaType != "" && rule.MediaType != mediaType {
+			continue
+		}
+		if message, ok := rule.evaluate(book); !ok {
+			if rule.Message != "" {
+				message = rule.Message
+			}
+			violations = append(violations, dto.RuleViolation{RuleID: rule.ID, Field: rule.Field, Message: message})
+		}
+	}
+	if len(violations) > 0 {
+		return &RuleViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// evaluate reports whether book meets the rule and, when it does not, why
+func (r compiledRule) evaluate(book model.Book) (string, bool) {
+	text, isText := ruleTextFields[r.Field]
+	number := ruleNumberFields[r.Field]
+
+	switch r.Kind {
+	case model.RuleRequired:
+		if isText {
+			return "is required", strings.TrimSpace(text(book)) != ""
+		}
+		return "is required", number(book) != 0
+
+	case model.RulePattern:
+		value := text(book)
+		return "must match " + r.Pattern, value == "" || r.pattern.MatchString(value)
+
+	case model.RuleRange:
+		value := number(book)
+		if value == 0 {
+			return "", true
+		}
+		if r.Min != nil && value < *r.Min {
+			return fmt.Sprintf("must be at least %d", *r.Min), false
+		}
+		if r.Max != nil && value > *r.Max {
+			return fmt.Sprintf("must be at most %d", *r.Max), false
+		}
+	}
+	return "", true
+}
+
+// load returns the cached enabled rules, reading them from the repository
+// after the cache has been invalidated
+func (s *ValidationRuleService) load() ([]compiledRule, error) {
+	s.mu.RLock()
+	rules, loaded, generation := s.rules, s.loaded, s.generation
+	s.mu.RUnlock()
+	if loaded {
+		return rules, nil
+	}
+
+	stored, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	rules = nil
+	for _, rule := range stored {
+		if !rule.Enabled {
+			continue
+		}
+		compiled := compiledRule{ValidationRule: rule}
+		if rule.Kind == model.RulePattern {
+			// Patterns are checked when saved, so this only fails for rows
+			// edited in the database directly
+			if compiled.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+				return nil, fmt.Errorf("validation rule %d: %w", rule.ID, err)
+			}
+		}
+		rules = append(rules, compiled)
+	}
+
+	// Skip caching if the rules changed while this copy was being read
+	s.mu.Lock()
+	if s.generation == generation {
+		s.rules, s.loaded = rules, true
+	}
+	s.mu.Unlock()
+	return rules, nil
+}
+
+func (s *ValidationRuleService) invalidate() {
+	s.mu.Lock()
+	s.rules, s.loaded = nil, false
+	s.generation++
+	s.mu.Unlock()
+}
+
+// applyRuleRequest checks that req describes a rule that can be evaluated
+// and copies it onto rule
+func applyRuleRequest(rule *model.ValidationRule, req dto.ValidationRuleRequest) error {
+	_, isText := ruleTextFields[req.Field]
+	_, isNumber := ruleNumberFields[req.Field]
+	if !isText && !isNumber {
+		return fmt.Errorf("%w: must be one of %s", ErrUnknownRuleField, strings.Join(ruleFieldNames(), ", "))
+	}
+
+	switch req.Kind {
+	case model.RulePattern:
+		if !isText {
+			return fmt.Errorf("%w: pattern rules apply to text fields", ErrInvalidRule)
+		}
+		if req.Pattern == "" {
+			return fmt.Errorf("%w: pattern is required", ErrInvalidRule)
+		}
+		if _, err := regexp.Compile(req.Pattern); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	case model.RuleRange:
+		if !isNumber {
+			return fmt.Errorf("%w: range rules apply to numeric fields", ErrInvalidRule)
+		}
+		if req.Min == nil && req.Max == nil {
+			return fmt.Errorf("%w: min or max is required", ErrInvalidRule)
+		}
+		if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+			return fmt.Errorf("%w: min must not be greater than max", ErrInvalidRule)
+		}
+	}
+
+	rule.Kind = req.Kind
+	rule.Field = req.Field
+	rule.MediaType = req.MediaType
+	rule.Pattern, rule.Min, rule.Max = "", nil, nil
+	switch req.Kind {
+	case model.RulePattern:
+		rule.Pattern = req.Pattern
+	case model.RuleRange:
+		rule.Min, rule.Max = req.Min, req.Max
+	}
+	rule.Message = req.Message
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	return nil
+}
+
+func ruleFieldNames() []string {
+	names := make([]string, 0, len(ruleTextFields)+len(ruleNumberFields))
+	for name := range ruleTextFields {
+		names = append(names, name)
+	}
+	for name := range ruleNumberFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,18 @@
+package testutil
+
+import "bms-go/internal/model"
+
+// Catalog is a small fixed catalog covering the filters of the book list:
+// several categories and authors, each content rating, each media type and
+// an imported book. IDs are left unset.
+func Catalog() []model.Book {
+	return []model.Book{
+		Book(1).Title("The Hobbit").Author("J.R.R. Tolkien").Category("Fantasy").PublishedYear(1937).Pages(310).Build(),
+		Book(2).Title("The Fellowship of the Ring").Author("J.R.R. Tolkien").Category("Fantasy").PublishedYear(1954).Pages(423).ContentRating(model.RatingTeen).Build(),
+		Book(3).Title("Dune").Author("Frank Herbert").Category("Science Fiction").PublishedYear(1965).Pages(412).ContentRating(model.RatingTeen).Build(),
+		Book(4).Title("Neuromancer").Author("William Gibson").Category("Science Fiction").PublishedYear(1984).Pages(271).ContentRating(model.RatingAdult).Build(),
+		Book(5).Title("A Brief History of Time").Author("Stephen Hawking").Category("Science").PublishedYear(1988).Pages(256).Build(),
+		Book(6).Title("Dune").Author("Frank Herbert").Category("Science Fiction").PublishedYear(2007).Pages(0).Audiobook("Scott Brick", 1263).ContentRating(model.RatingTeen).Build(),
+		Book(7).Title("Pride and Prejudice").Author("Jane Austen").Category("Classics").PublishedYear(1813).Pages(279).Imported(1).Build(),
+	}
+}
@@ -0,0 +1,120 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// UpdateGoldenEnv names the environment variable that makes AssertGolden
+// record responses instead of checking them:
+//
+//	UPDATE_GOLDEN=1 go test ./internal/infra/handler/...
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// maskedValue replaces the values of masked keys in golden files, except
+// timestamps, which become Epoch so the files still read as examples
+const maskedValue = "<masked>"
+
+// goldenResponse is what a golden file holds: one recorded response, in a
+// form that can be shown as an example of the endpoint
+type goldenResponse struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body"`
+}
+
+// GoldenPath is where AssertGolden keeps the response called name, under
+// testdata/golden of the package being tested
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", "golden", name+".json")
+}
+
+// AssertGolden checks the response status and JSON body against the golden
+// file called name, failing when they differ. The values of the keys in
+// mask are replaced at any depth before comparing, for fields such as
+// timestamps that change from run to run. With UPDATE_GOLDEN set the file
+// is rewritten from the response instead.
+func AssertGolden(tb testing.TB, rec *httptest.ResponseRecorder, name string, mask ...string) {
+	tb.Helper()
+	got, err := goldenJSON(rec, mask)
+	if err != nil {
+		tb.Fatalf("golden %s: %v; body %s", name, err, rec.Body.String())
+	}
+
+	path := GoldenPath(name)
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatalf("golden %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			tb.Fatalf("golden %s: %v", name, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		tb.Fatalf("golden %s: %s is missing; run the test with %s=1 to record it", name, path, UpdateGoldenEnv)
+	}
+	if err != nil {
+		tb.Fatalf("golden %s: %v", name, err)
+	}
+	if !bytes.Equal(got, want) {
+		tb.Fatalf("golden %s: response no longer matches %s; rerun with %s=1 if the change is intended\ngot:\n%s\nwant:\n%s", name, path, UpdateGoldenEnv, got, want)
+	}
+}
+
+// goldenJSON renders the response as a golden file: indented, with sorted
+// keys and masked values
+func goldenJSON(rec *httptest.ResponseRecorder, mask []string) ([]byte, error) {
+	var body interface{}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			return nil, err
+		}
+	}
+	masked := make(map[string]bool, len(mask))
+	for _, key := range mask {
+		masked[key] = true
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(goldenResponse{Status: rec.Code, Body: maskKeys(body, masked)}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func maskKeys(v interface{}, masked map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if masked[key] {
+				v[key] = maskValue(value)
+			} else {
+				v[key] = maskKeys(value, masked)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = maskKeys(value, masked)
+		}
+	}
+	return v
+}
+
+func maskValue(v interface{}) interface{} {
+	if s, ok := v.(string); ok {
+		if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return Epoch.Format(time.RFC3339)
+		}
+	}
+	return maskedValue
+}
@@ -0,0 +1,145 @@
+package testutil
+
+import (
+	"bms-go/internal/infra/handler"
+	"bms-go/internal/infra/middleware"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteRegistrar is implemented by every handler
+type RouteRegistrar interface {
+	RegisterRoutes(routes handler.Routes)
+}
+
+// Router returns an engine serving the routes of handlers. Requests with a
+// token issued by auth act as its user on both route groups; auth may be
+// nil when no test signs in.
+func Router(auth *service.AuthService, handlers ...RouteRegistrar) *gin.Engine {
+	r := newEngine(auth)
+	register(handler.Routes{Public: r.Group(""), Private: r.Group("")}, handlers)
+	return r
+}
+
+// GuardedRouter returns an engine like Router's whose route groups enforce
+// rules as in production, looking up roles in users
+func GuardedRouter(auth *service.AuthService, users middleware.RoleLookup, rules []dto.AccessRule, handlers ...RouteRegistrar) *gin.Engine {
+	r := newEngine(auth)
+	register(handler.Routes{
+		Public:  r.Group("", middleware.RequireRoles(users, rules)),
+		Private: r.Group("", middleware.RequirePrivateRoles(users, rules)),
+	}, handlers)
+	return r
+}
+
+func newEngine(auth *service.AuthService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if auth != nil {
+		r.Use(middleware.Users(auth))
+	}
+	return r
+}
+
+func register(routes handler.Routes, handlers []RouteRegistrar) {
+	for _, h := range handlers {
+		h.RegisterRoutes(routes)
+	}
+}
+
+// NewRequest builds a request with body encoded as JSON, or without a body
+// when body is nil
+func NewRequest(tb testing.TB, method, path string, body interface{}) *http.Request {
+	tb.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			tb.Fatalf("encode %s %s body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+// AuthenticatedRequest builds a request like NewRequest, signed in as
+// userID with an access token from auth
+func AuthenticatedRequest(tb testing.TB, auth *service.AuthService, userID uint, method, path string, body interface{}) *http.Request {
+	tb.Helper()
+	token, err := auth.IssueToken(userID)
+	if err != nil {
+		tb.Fatalf("issue token for user %d: %v", userID, err)
+	}
+	req := NewRequest(tb, method, path, body)
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return req
+}
+
+// WithAPIKey sends req with an API key
+func WithAPIKey(req *http.Request, key string) *http.Request {
+	req.Header.Set(middleware.APIKeyHeader, key)
+	return req
+}
+
+// Serve runs req through h and returns the recorded response
+func Serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// DecodeJSON decodes the response body into v
+func DecodeJSON(tb testing.TB, rec *httptest.ResponseRecorder, v interface{}) {
+	tb.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		tb.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+}
+
+// AssertJSON checks the response status and that its body is the same
+// JSON as want, ignoring formatting and key order. want may be a JSON
+// string or any value that encodes to JSON.
+func AssertJSON(tb testing.TB, rec *httptest.ResponseRecorder, status int, want interface{}) {
+	tb.Helper()
+	if rec.Code != status {
+		tb.Fatalf("status = %d, want %d; body %s", rec.Code, status, rec.Body.String())
+	}
+
+	var wantJSON []byte
+	switch w := want.(type) {
+	case string:
+		wantJSON = []byte(w)
+	case []byte:
+		wantJSON = w
+	default:
+		data, err := json.Marshal(want)
+		if err != nil {
+			tb.Fatalf("encode expected body: %v", err)
+		}
+		wantJSON = data
+	}
+
+	var got, expected interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		tb.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	if err := json.Unmarshal(wantJSON, &expected); err != nil {
+		tb.Fatalf("decode expected body %q: %v", wantJSON, err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		tb.Fatalf("body = %s, want %s", rec.Body.String(), wantJSON)
+	}
+}
@@ -0,0 +1,158 @@
+package testutil
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"bms-go/internal/service"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryBooks is an in-memory service.BookRepository for handler tests. It
+// lists books in id order, ignoring filters and sort order, and writes them
+// as the GORM repository does: with ids, one copy unless Copies says
+// otherwise, and category and author ids by name. Timestamps are Epoch.
+// Methods no test needs yet panic through the embedded nil interface.
+type MemoryBooks struct {
+	service.BookRepository
+	books      []model.Book
+	categories map[string]uint
+	authors    map[string]uint
+}
+
+// NewMemoryBooks stores books, numbering those without an id
+func NewMemoryBooks(books ...model.Book) *MemoryBooks {
+	m := &MemoryBooks{categories: make(map[string]uint), authors: make(map[string]uint)}
+	for i := range books {
+		if err := m.Create(context.Background(), &books[i]); err != nil {
+			panic(err)
+		}
+	}
+	return m
+}
+
+func (m *MemoryBooks) FindAll(ctx context.Context, params dto.BookQuery) ([]model.Book, []dto.BookScore, error) {
+	books := m.books[min(params.Offset, len(m.books)):]
+	if params.Limit > 0 {
+		books = books[:min(params.Limit, len(books))]
+	}
+	return append([]model.Book{}, books...), nil, nil
+}
+
+func (m *MemoryBooks) FindByID(ctx context.Context, id uint) (*model.Book, error) {
+	for _, book := range m.books {
+		if book.ID == id {
+			return &book, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryBooks) FindTitlesAndAuthors(ctx context.Context) ([]string, error) {
+	var words []string
+	for _, book := range m.books {
+		words = append(words, book.Title, book.Author)
+	}
+	return words, nil
+}
+
+func (m *MemoryBooks) Create(ctx context.Context, book *model.Book) error {
+	if book.ID == 0 {
+		book.ID = uint(len(m.books) + 1)
+	}
+	book.CreatedAt, book.UpdatedAt = Epoch, Epoch
+	if book.Category != "" && book.CategoryID == nil {
+		id := nextID(m.categories, book.Category)
+		book.CategoryID = &id
+	}
+	for i, author := range book.Authors {
+		if author.ID == 0 {
+			book.Authors[i].ID = nextID(m.authors, author.Name)
+		}
+		book.Authors[i].CreatedAt, book.Authors[i].UpdatedAt = Epoch, Epoch
+	}
+	if book.Copies <= 0 {
+		book.Copies = 1
+	}
+	book.AvailableCopies, book.Available = book.Copies, true
+	if book.Tags == nil {
+		book.Tags = []model.Tag{}
+	}
+	m.books = append(m.books, *book)
+	return nil
+}
+
+// lend moves one copy of book id out, reporting false when none is free
+func (m *MemoryBooks) lend(id uint) (bool, error) {
+	for i := range m.books {
+		if m.books[i].ID != id {
+			continue
+		}
+		if m.books[i].AvailableCopies == 0 {
+			return false, nil
+		}
+		m.books[i].AvailableCopies--
+		m.books[i].Available = m.books[i].AvailableCopies > 0
+		return true, nil
+	}
+	return false, gorm.ErrRecordNotFound
+}
+
+// nextID returns the id of name in ids, adding it when it is new
+func nextID(ids map[string]uint, name string) uint {
+	if id, ok := ids[name]; ok {
+		return id
+	}
+	ids[name] = uint(len(ids) + 1)
+	return ids[name]
+}
+
+// MemoryLoans is an in-memory service.LoanRepository lending the copies of
+// books. Loans are listed newest first. Methods no test needs yet panic
+// through the embedded nil interface.
+type MemoryLoans struct {
+	service.LoanRepository
+	books *MemoryBooks
+	loans []model.Loan
+}
+
+func NewMemoryLoans(books *MemoryBooks) *MemoryLoans {
+	return &MemoryLoans{books: books}
+}
+
+func (m *MemoryLoans) FindAll(ctx context.Context, query dto.LoanQuery) ([]model.Loan, int64, error) {
+	var matched []model.Loan
+	for i := len(m.loans) - 1; i >= 0; i-- {
+		if loan := m.loans[i]; query.UserID == 0 || loan.UserID == query.UserID {
+			matched = append(matched, loan)
+		}
+	}
+	page := matched[min(query.Offset, len(matched)):]
+	if query.Limit > 0 {
+		page = page[:min(query.Limit, len(page))]
+	}
+	return append([]model.Loan{}, page...), int64(len(matched)), nil
+}
+
+func (m *MemoryLoans) Borrow(ctx context.Context, loan *model.Loan, hold time.Duration) (bool, error) {
+	lent, err := m.books.lend(loan.BookID)
+	if err != nil || !lent {
+		return false, err
+	}
+	loan.ID = uint(len(m.loans) + 1)
+	loan.CopyID = loan.ID
+	loan.CreatedAt, loan.UpdatedAt = loan.BorrowedAt, loan.BorrowedAt
+	m.loans = append(m.loans, *loan)
+	return true, nil
+}
+
+// NoValidationRules is a service.ValidationRuleRepository without rules
+type NoValidationRules struct {
+	service.ValidationRuleRepository
+}
+
+func (NoValidationRules) FindAll() ([]model.ValidationRule, error) {
+	return nil, nil
+}
@@ -0,0 +1,35 @@
+package testutil
+
+import (
+	"bms-go/internal/model"
+	"bms-go/internal/model/dto"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// ShippedRules returns the rbac.rules of config/config.yaml, so tests check
+// the access the server is deployed with
+func ShippedRules(tb testing.TB) []dto.AccessRule {
+	tb.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(filepath.Dir(file), "..", "..", "config", "config.yaml"))
+	if err := v.ReadInConfig(); err != nil {
+		tb.Fatalf("read config: %v", err)
+	}
+	var rules []dto.AccessRule
+	if err := v.UnmarshalKey("rbac.rules", &rules); err != nil {
+		tb.Fatalf("read rbac.rules: %v", err)
+	}
+	return rules
+}
+
+// Roles looks up user roles in memory
+type Roles map[uint]model.UserRole
+
+func (r Roles) GetRole(id uint) (model.UserRole, error) {
+	return r[id], nil
+}
@@ -1,13 +1,17 @@
 package util
 
 import (
+	"bms-go/config"
+	"bms-go/internal/infra/repository"
 	"bms-go/internal/model"
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // requiredKeys berisi daftar key yang wajib diisi
@@ -19,19 +23,11 @@ var requiredKeys = []string{
 	"database.name",
 }
 
+// InitDB connects to the database and migrates it. The configuration must
+// already be loaded with config.Load. MySQL is the only supported database:
+// book search relies on its FULLTEXT indexes, and high-volume tables are
+// kept small by the archive job rather than by partitioning.
 func InitDB() *gorm.DB {
-	// Setup Viper
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
-
-	if err := viper.ReadInConfig(); err != nil {
-		log.Printf("Config file not found, attempting to load from environment variables: %v", err)
-	}
-
-	viper.AutomaticEnv()
-
 	missingKeys := []string{}
 	for _, key := range requiredKeys {
 		if !viper.IsSet(key) || viper.GetString(key) == "" {
@@ -53,15 +49,119 @@ func InitDB() *gorm.DB {
 		user, pass, host, port, name,
 	)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(config.DatabaseLogLevel()),
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect to MySQL: %v", err)
 	}
 
-	if err := db.AutoMigrate(&model.Book{}, &model.Favorite{}); err != nil {
+	// Counts start at 0 when the column is added, so fill them in once
+	backfillFavoriteCounts := !db.Migrator().HasColumn(&model.Book{}, "FavoriteCount")
+	// Books predating categories only carry the category name
+	backfillCategories := !db.Migrator().HasTable(&model.Category{})
+	backfillAuthors := !db.Migrator().HasTable(&model.Author{})
+	// Books predating copies are held once
+	backfillCopies := !db.Migrator().HasTable(&model.Copy{})
+	// Copies withdrawn before copy statuses start out in circulation
+	backfillCopyStatus := !backfillCopies && !db.Migrator().HasColumn(&model.Copy{}, "Status")
+
+	if err := repository.EncryptUserEmails(db); err != nil {
+		log.Fatalf("Failed to encrypt user emails: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&model.Category{},
+		&model.Author{},
+		&model.Book{},
+		&model.BookAuthor{},
+		&model.Tag{},
+		&model.BookTag{},
+		&model.Copy{},
+		&model.DamageReport{},
+		&model.DamagePhoto{},
+		&model.Loan{},
+		&model.LoanReminder{},
+		&model.Reservation{},
+		&model.Favorite{},
+		&model.Collection{},
+		&model.CollectionBook{},
+		&model.Synonym{},
+		&model.BookView{},
+		&model.PrivacySetting{},
+		&model.ReminderSetting{},
+		&model.APIQuota{},
+		&model.APIUsage{},
+		&model.Partner{},
+		&model.AccountDeletion{},
+		&model.ImpersonationSession{},
+		&model.ImpersonationAction{},
+		&model.Organization{},
+		&model.OrganizationMember{},
+		&model.OrganizationInvitation{},
+		&model.OrganizationFavorite{},
+		&model.BookLock{},
+		&model.ChangeRequest{},
+		&model.ReportSchedule{},
+		&model.ReportRun{},
+		&model.ChangeEvent{},
+		&model.ArchivedChangeEvent{},
+		&model.ArchivedImpersonationAction{},
+		&model.NotificationPreference{},
+		&model.Event{},
+		&model.EventRSVP{},
+		&model.ILLRequest{},
+		&model.ILLStatusChange{},
+		&model.BookSample{},
+		&model.BookEmbedding{},
+		&model.BookAffinity{},
+		&model.Experiment{},
+		&model.GuestFavorite{},
+		&model.Device{},
+		&model.InboxNotification{},
+		&model.User{},
+		&model.ClientBlock{},
+		&model.ValidationRule{},
+		&model.Review{},
+		&model.ReadingStatus{},
+		&model.ImportBatch{},
+		&model.ImportBatchItem{},
+		&model.UpstreamRecord{},
+	); err != nil {
 		log.Fatalf("Failed to migrate models: %v", err)
 	}
 
+	if err := repository.EnsureBookSearchIndex(db); err != nil {
+		log.Fatalf("Failed to create search index: %v", err)
+	}
+
+	if backfillFavoriteCounts {
+		if _, err := repository.NewBookRepository(db).ReconcileFavoriteCounts(context.Background()); err != nil {
+			log.Fatalf("Failed to backfill favorite counts: %v", err)
+		}
+	}
+
+	if backfillCategories {
+		if err := repository.BackfillCategories(db); err != nil {
+			log.Fatalf("Failed to backfill categories: %v", err)
+		}
+	}
+	if backfillAuthors {
+		if err := repository.BackfillAuthors(db); err != nil {
+			log.Fatalf("Failed to backfill authors: %v", err)
+		}
+	}
+	if backfillCopies {
+		if err := repository.BackfillCopies(db); err != nil {
+			log.Fatalf("Failed to backfill copies: %v", err)
+		}
+	}
+	if backfillCopyStatus {
+		if err := repository.BackfillCopyStatus(db); err != nil {
+			log.Fatalf("Failed to backfill copy statuses: %v", err)
+		}
+	}
+
 	log.Printf("Connected to MySQL [%s:%s] successfully!", host, name)
 	return db
 }
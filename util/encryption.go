@@ -0,0 +1,41 @@
+package util
+
+import (
+	"bms-go/config"
+	"bms-go/internal/infra/encryption"
+	"log"
+)
+
+// InitEncryption registers the serializer of encrypted columns and loads
+// their keys and the blind index key. Without keys, sensitive columns are
+// stored in plaintext.
+func InitEncryption() {
+	encryption.RegisterSerializer()
+
+	current, keys, ok, err := config.EncryptionKeys()
+	if err != nil {
+		log.Fatalf("Invalid encryption keys: %v", err)
+	}
+	indexKey, indexed, err := config.BlindIndexKey()
+	if err != nil {
+		log.Fatalf("Invalid blind index key: %v", err)
+	}
+	if indexed {
+		encryption.SetBlindIndexKey(indexKey)
+	}
+	if !ok {
+		log.Println("ENCRYPTION_KEYS not set, sensitive columns are stored unencrypted")
+		return
+	}
+	// An unkeyed index of an encrypted column would let anyone holding a
+	// guess confirm it
+	if !indexed {
+		log.Fatalf("BLIND_INDEX_KEY must be set along with ENCRYPTION_KEYS")
+	}
+
+	keyring, err := encryption.NewKeyring(current, keys)
+	if err != nil {
+		log.Fatalf("Invalid encryption keys: %v", err)
+	}
+	encryption.SetKeyring(keyring)
+}